@@ -19,7 +19,7 @@ func main() {
 		&models.Post{},
 		&models.Comment{},
 		&models.PostLike{},
-		&models.Mate{},
+		&models.MateRelation{},
 		&models.Chat{},
 		&models.Message{},
 		&models.ChatParticipant{},
@@ -37,6 +37,29 @@ func main() {
 		&models.UserTrainingPreferences{},
 		&models.AITrainingSession{},
 		&models.VoiceSettings{},
+		&models.TrainingProgressReport{},
+		&models.PlanTemplateRating{},
+		&models.WeeklyTrainingPlanRevision{},
+		&models.ExerciseGroup{},
+		&models.WorkoutSetLog{},
+		&models.ExerciseSubstitution{},
+		&models.EquipmentProfile{},
+		&models.Injury{},
+		&models.MuscleVolumeTarget{},
+		&models.SplitTemplate{},
+		&models.Food{},
+		&models.Meal{},
+		&models.MealItem{},
+		&models.BodyMeasurement{},
+		&models.CardioActivity{},
+		&models.ChatInvite{},
+		&models.MessageReaction{},
+		&models.MessageEdit{},
+		&models.MessageDeletion{},
+		&models.MessageSearchDocument{},
+		&models.Gym{},
+		&models.PrivacySettings{},
+		&models.UserMute{},
 	)
 
 	if err != nil {
@@ -0,0 +1,46 @@
+package config
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+	"gymates-backend/models"
+)
+
+// lastReadBackfillMigration BackfillLastRead 在 data_migrations 表中的标记
+const lastReadBackfillMigration = "backfill_chat_last_read"
+
+// dataMigration 已执行过的一次性数据迁移
+type dataMigration struct {
+	Name      string `gorm:"primaryKey;size:100"`
+	CreatedAt time.Time
+}
+
+// BackfillLastRead 为已读位置还是0的聊天参与者按旧版的 is_read 标记推算已读位置
+//
+// 已读位置取第一条他人发来的未读消息之前的最后一条消息；没有未读消息时为聊天中的最新一条。
+// 只在第一次执行时处理，之后加入聊天、已读位置为0的参与者不受旧版标记影响。
+func BackfillLastRead(db *gorm.DB) error {
+	if err := db.AutoMigrate(&dataMigration{}); err != nil {
+		return err
+	}
+	return db.Transaction(func(tx *gorm.DB) error {
+		var done int64
+		if err := tx.Model(&dataMigration{}).Where("name = ?", lastReadBackfillMigration).Count(&done).Error; err != nil || done > 0 {
+			return err
+		}
+
+		readUpTo := tx.Model(&models.Message{}).
+			Select("COALESCE(MAX(messages.id), 0)").
+			Where("messages.chat_id = chat_participants.chat_id").
+			Where("NOT EXISTS (?)", tx.Table("messages AS unread").Select("1").
+				Where("unread.chat_id = messages.chat_id AND unread.id <= messages.id").
+				Where("unread.sender_id <> chat_participants.user_id AND unread.is_read = ?", false))
+		if err := tx.Model(&models.ChatParticipant{}).
+			Where("last_read_message_id = ?", 0).
+			Update("last_read_message_id", readUpTo).Error; err != nil {
+			return err
+		}
+		return tx.Create(&dataMigration{Name: lastReadBackfillMigration}).Error
+	})
+}
@@ -3,8 +3,10 @@ package config
 import (
 	"fmt"
 	"log"
+	"net/url"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"gymates-backend/models"
@@ -28,6 +30,7 @@ type DatabaseConfig struct {
 	Name     string
 	SSLMode  string
 	Path     string // SQLite专用
+	TimeZone string // 数据库连接时区，业务日期按用户时区计算
 }
 
 // AppConfig 应用配置
@@ -50,6 +53,7 @@ func GetDatabaseConfig() *DatabaseConfig {
 		Name:     getEnv("DB_NAME", "gymates"),
 		SSLMode:  getEnv("DB_SSLMODE", "disable"),
 		Path:     getEnv("DB_PATH", "gymates.db"),
+		TimeZone: getEnv("DB_TIMEZONE", "UTC"),
 	}
 }
 
@@ -89,6 +93,26 @@ func InitDB() error {
 		return fmt.Errorf("failed to migrate database: %w", err)
 	}
 
+	// 合并旧版的单向搭子记录
+	if err := MigrateLegacyMates(DB); err != nil {
+		return fmt.Errorf("failed to migrate mates: %w", err)
+	}
+
+	// 根据旧版的已读标记初始化聊天参与者的已读位置
+	if err := BackfillLastRead(DB); err != nil {
+		return fmt.Errorf("failed to backfill chat read positions: %w", err)
+	}
+
+	// 初始化标准动作库
+	if err := SeedExerciseLibrary(DB); err != nil {
+		return fmt.Errorf("failed to seed exercise library: %w", err)
+	}
+
+	// 初始化常见食物
+	if err := SeedFoods(DB); err != nil {
+		return fmt.Errorf("failed to seed foods: %w", err)
+	}
+
 	// 初始化模拟数据
 	if GetAppConfig().MockData {
 		initMockData()
@@ -100,8 +124,8 @@ func InitDB() error {
 
 // initMySQL 初始化MySQL连接
 func initMySQL(config *DatabaseConfig) (*gorm.DB, error) {
-	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local",
-		config.User, config.Password, config.Host, config.Port, config.Name)
+	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=%s",
+		config.User, config.Password, config.Host, config.Port, config.Name, url.QueryEscape(config.TimeZone))
 
 	return gorm.Open(mysql.Open(dsn), &gorm.Config{
 		Logger: logger.Default.LogMode(logger.Info),
@@ -110,8 +134,8 @@ func initMySQL(config *DatabaseConfig) (*gorm.DB, error) {
 
 // initPostgreSQL 初始化PostgreSQL连接
 func initPostgreSQL(config *DatabaseConfig) (*gorm.DB, error) {
-	dsn := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%s sslmode=%s TimeZone=Asia/Shanghai",
-		config.Host, config.User, config.Password, config.Name, config.Port, config.SSLMode)
+	dsn := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%s sslmode=%s TimeZone=%s",
+		config.Host, config.User, config.Password, config.Name, config.Port, config.SSLMode, config.TimeZone)
 
 	return gorm.Open(postgres.Open(dsn), &gorm.Config{
 		Logger: logger.Default.LogMode(logger.Info),
@@ -135,7 +159,7 @@ func autoMigrate() error {
 		&models.Post{},
 		&models.Comment{},
 		&models.PostLike{},
-		&models.Mate{},
+		&models.MateRelation{},
 		&models.Chat{},
 		&models.Message{},
 		&models.ChatParticipant{},
@@ -147,6 +171,41 @@ func autoMigrate() error {
 		&models.ProfileDetail{},     // 添加用户资料详情
 		&models.ChatDetail{},        // 添加聊天详情
 		&models.AchievementDetail{}, // 添加成就详情
+		// 一周训练计划相关表
+		&models.WeeklyTrainingPlan{},
+		&models.TrainingDay{},
+		&models.TrainingPart{},
+		// 动作库相关表
+		&models.ExerciseLibrary{},
+		&models.TrainingMode{},
+		&models.UserTrainingHistory{},
+		// AI训练相关表
+		&models.UserTrainingPreferences{},
+		&models.AITrainingSession{},
+		&models.VoiceSettings{},
+		&models.TrainingProgressReport{},
+		&models.PlanTemplateRating{},
+		&models.WeeklyTrainingPlanRevision{},
+		&models.ExerciseGroup{},
+		&models.WorkoutSetLog{},
+		&models.ExerciseSubstitution{},
+		&models.EquipmentProfile{},
+		&models.Injury{},
+		&models.MuscleVolumeTarget{},
+		&models.SplitTemplate{},
+		&models.Food{},
+		&models.Meal{},
+		&models.MealItem{},
+		&models.BodyMeasurement{},
+		&models.CardioActivity{},
+		&models.ChatInvite{},
+		&models.MessageReaction{},
+		&models.MessageEdit{},
+		&models.MessageDeletion{},
+		&models.MessageSearchDocument{},
+		&models.Gym{},
+		&models.PrivacySettings{},
+		&models.UserMute{},
 	)
 }
 
@@ -175,6 +234,7 @@ func initMockData() {
 			Age:        25,
 			Height:     175.5,
 			Weight:     70.0,
+			Gender:     "male",
 			Goal:       "增肌",
 			Experience: "3年",
 		},
@@ -189,6 +249,7 @@ func initMockData() {
 			Age:        28,
 			Height:     165.0,
 			Weight:     55.0,
+			Gender:     "female",
 			Goal:       "塑形",
 			Experience: "5年",
 		},
@@ -203,6 +264,7 @@ func initMockData() {
 			Age:        30,
 			Height:     180.0,
 			Weight:     75.0,
+			Gender:     "male",
 			Goal:       "减脂",
 			Experience: "2年",
 		},
@@ -388,11 +450,31 @@ func GetJWTExpiration() time.Duration {
 	return 24 * time.Hour
 }
 
+// GetMessageRecallWindow 获取消息撤回时限，MESSAGE_RECALL_MINUTES 为0时不限制
+func GetMessageRecallWindow() time.Duration {
+	minutes := getEnv("MESSAGE_RECALL_MINUTES", "2")
+	if parsed, err := strconv.Atoi(minutes); err == nil && parsed >= 0 {
+		return time.Duration(parsed) * time.Minute
+	}
+	return 2 * time.Minute
+}
+
 // IsProduction 判断是否为生产环境
 func IsProduction() bool {
 	return getEnv("GIN_MODE", "debug") == "release"
 }
 
+// GetWebSocketOrigins 获取允许建立 WebSocket 连接的页面源（逗号分隔），为空时只允许同源
+func GetWebSocketOrigins() []string {
+	var origins []string
+	for _, origin := range strings.Split(getEnv("WS_ALLOWED_ORIGINS", ""), ",") {
+		if origin = strings.TrimSpace(origin); origin != "" {
+			origins = append(origins, strings.TrimRight(origin, "/"))
+		}
+	}
+	return origins
+}
+
 // GetCORSOrigins 获取CORS允许的源
 func GetCORSOrigins() []string {
 	origins := getEnv("CORS_ORIGINS", "*")
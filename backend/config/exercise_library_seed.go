@@ -0,0 +1,325 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"gymates-backend/models"
+
+	"gorm.io/gorm"
+)
+
+// exerciseSeed 动作库种子数据
+type exerciseSeed struct {
+	Name         string
+	NameEN       string
+	Part         string
+	Level        string
+	Type         string // compound/isolation/cardio
+	Equipment    string
+	Pattern      string
+	Primary      []string
+	Secondary    []string
+	Aliases      []string
+	Description  string
+	Instructions string
+}
+
+// exerciseLibrarySeeds 标准动作库，Name 为唯一的标准名称，其他叫法放在 Aliases 中
+var exerciseLibrarySeeds = []exerciseSeed{
+	// 胸部
+	{"俯卧撑", "Push-Up", "chest", "beginner", "compound", "bodyweight", "horizontal_push", []string{"chest"}, []string{"triceps", "front_delts", "abs"}, []string{"Push Up", "Pushup"}, "经典的上肢训练动作", "保持身体挺直，双手与肩同宽"},
+	{"平板卧推", "Barbell Bench Press", "chest", "intermediate", "compound", "barbell", "horizontal_push", []string{"chest"}, []string{"triceps", "front_delts"}, []string{"杠铃卧推", "卧推", "Bench Press"}, "经典胸部训练动作", "平躺在卧推凳上，双手握杠铃"},
+	{"上斜卧推", "Incline Barbell Bench Press", "chest", "intermediate", "compound", "barbell", "horizontal_push", []string{"chest", "front_delts"}, []string{"triceps"}, []string{"上斜杠铃卧推", "Incline Bench Press"}, "上胸部训练", "上斜角度30-45度"},
+	{"下斜卧推", "Decline Barbell Bench Press", "chest", "intermediate", "compound", "barbell", "horizontal_push", []string{"chest"}, []string{"triceps"}, []string{"下斜杠铃卧推", "Decline Bench Press"}, "下胸部训练", "下斜角度15-30度"},
+	{"哑铃卧推", "Dumbbell Bench Press", "chest", "intermediate", "compound", "dumbbell", "horizontal_push", []string{"chest"}, []string{"triceps", "front_delts"}, []string{"Dumbbell Press"}, "胸部力量训练", "双手持哑铃卧推"},
+	{"哑铃飞鸟", "Dumbbell Fly", "chest", "intermediate", "isolation", "dumbbell", "isolation", []string{"chest"}, []string{"front_delts"}, []string{"飞鸟", "Dumbbell Flyes"}, "胸部拉伸动作", "双臂展开呈弧形"},
+	{"上斜哑铃飞鸟", "Incline Dumbbell Fly", "chest", "intermediate", "isolation", "dumbbell", "isolation", []string{"chest", "front_delts"}, nil, nil, "上胸塑形", "上斜角度哑铃飞鸟"},
+	{"下斜哑铃飞鸟", "Decline Dumbbell Fly", "chest", "intermediate", "isolation", "dumbbell", "isolation", []string{"chest"}, nil, nil, "下胸塑形", "下斜角度哑铃飞鸟"},
+	{"绳索夹胸", "Cable Crossover", "chest", "intermediate", "isolation", "cable", "isolation", []string{"chest"}, []string{"front_delts"}, []string{"龙门架夹胸", "Cable Fly"}, "胸部塑形", "双臂向中间夹紧"},
+	{"双杠臂屈伸", "Chest Dip", "chest", "advanced", "compound", "dip_bars", "vertical_push", []string{"chest", "triceps"}, []string{"front_delts"}, []string{"双杠撑体", "Dips"}, "胸部自重训练", "身体前倾，重点训练胸部"},
+	{"宽距俯卧撑", "Wide Push-Up", "chest", "intermediate", "compound", "bodyweight", "horizontal_push", []string{"chest"}, []string{"front_delts", "triceps"}, nil, "胸部宽度训练", "双手距离比肩宽"},
+	{"窄距俯卧撑", "Close-Grip Push-Up", "chest", "intermediate", "compound", "bodyweight", "horizontal_push", []string{"triceps", "chest"}, []string{"front_delts"}, []string{"钻石俯卧撑", "Diamond Push-Up"}, "胸部厚度训练", "双手距离比肩窄"},
+	{"上斜俯卧撑", "Feet-Elevated Push-Up", "chest", "beginner", "compound", "bodyweight", "horizontal_push", []string{"chest", "front_delts"}, []string{"triceps"}, nil, "上胸自重训练", "脚部抬高俯卧撑"},
+	{"下斜俯卧撑", "Hands-Elevated Push-Up", "chest", "intermediate", "compound", "bodyweight", "horizontal_push", []string{"chest"}, []string{"triceps"}, nil, "下胸自重训练", "手部抬高俯卧撑"},
+	{"单臂俯卧撑", "One-Arm Push-Up", "chest", "advanced", "compound", "bodyweight", "horizontal_push", []string{"chest", "triceps"}, []string{"abs", "obliques"}, nil, "胸部单侧训练", "单臂俯卧撑"},
+
+	// 背部
+	{"引体向上", "Pull-Up", "back", "intermediate", "compound", "pull_up_bar", "vertical_pull", []string{"lats"}, []string{"biceps", "upper_back"}, []string{"正手引体", "Pull Up"}, "背部训练经典动作", "双手正握单杠，身体垂直上拉"},
+	{"宽握引体向上", "Wide-Grip Pull-Up", "back", "intermediate", "compound", "pull_up_bar", "vertical_pull", []string{"lats"}, []string{"upper_back", "biceps"}, nil, "背部宽度训练", "宽握引体向上"},
+	{"窄握引体向上", "Close-Grip Pull-Up", "back", "intermediate", "compound", "pull_up_bar", "vertical_pull", []string{"lats", "biceps"}, []string{"upper_back"}, nil, "背部厚度训练", "窄握引体向上"},
+	{"对握引体向上", "Neutral-Grip Pull-Up", "back", "intermediate", "compound", "pull_up_bar", "vertical_pull", []string{"lats"}, []string{"biceps", "upper_back"}, nil, "背部训练", "对握引体向上"},
+	{"反手引体", "Chin-Up", "back", "intermediate", "compound", "pull_up_bar", "vertical_pull", []string{"lats", "biceps"}, []string{"upper_back"}, []string{"反手引体向上", "Chin Up"}, "背部训练", "反手握法引体向上"},
+	{"高位下拉", "Lat Pulldown", "back", "intermediate", "compound", "machine", "vertical_pull", []string{"lats"}, []string{"biceps", "upper_back"}, []string{"坐姿下拉", "Pulldown"}, "背部宽度训练", "下拉至胸部，背部收缩"},
+	{"直臂下拉", "Straight-Arm Pulldown", "back", "intermediate", "isolation", "cable", "isolation", []string{"lats"}, []string{"rear_delts"}, []string{"绳索直臂下拉"}, "背部宽度训练", "直臂下拉动作"},
+	{"杠铃划船", "Barbell Row", "back", "intermediate", "compound", "barbell", "horizontal_pull", []string{"upper_back", "lats"}, []string{"biceps", "rear_delts", "lower_back"}, []string{"俯身划船", "Bent-Over Row"}, "背部厚度训练", "俯身划船，背部发力"},
+	{"哑铃划船", "Dumbbell Row", "back", "intermediate", "compound", "dumbbell", "horizontal_pull", []string{"lats", "upper_back"}, []string{"biceps", "rear_delts"}, []string{"单臂哑铃划船", "One-Arm Dumbbell Row"}, "单侧背部训练", "单臂哑铃划船"},
+	{"T杠划船", "T-Bar Row", "back", "intermediate", "compound", "barbell", "horizontal_pull", []string{"upper_back", "lats"}, []string{"biceps", "rear_delts", "lower_back"}, nil, "背部厚度训练", "T杠划船动作"},
+	{"海豹划船", "Seal Row", "back", "intermediate", "compound", "barbell", "horizontal_pull", []string{"upper_back", "lats"}, []string{"rear_delts", "biceps"}, nil, "背部训练", "海豹划船动作"},
+	{"坐姿绳索划船", "Seated Cable Row", "back", "intermediate", "compound", "cable", "horizontal_pull", []string{"upper_back", "lats"}, []string{"biceps", "rear_delts"}, []string{"坐姿划船"}, "背部中下部训练", "坐姿划船，背部后缩"},
+	{"单臂绳索划船", "Single-Arm Cable Row", "back", "intermediate", "compound", "cable", "horizontal_pull", []string{"lats", "upper_back"}, []string{"biceps"}, nil, "背部单侧训练", "单臂绳索划船"},
+	{"反向飞鸟", "Reverse Dumbbell Fly", "back", "intermediate", "isolation", "dumbbell", "isolation", []string{"rear_delts", "upper_back"}, []string{"traps"}, nil, "后三角肌训练", "反向飞鸟动作"},
+	{"面拉", "Face Pull", "back", "intermediate", "isolation", "cable", "horizontal_pull", []string{"rear_delts", "upper_back"}, []string{"traps"}, nil, "后三角肌训练", "面拉动作"},
+	{"硬拉", "Deadlift", "back", "advanced", "compound", "barbell", "hinge", []string{"hamstrings", "glutes", "lower_back"}, []string{"traps", "forearms", "quads", "upper_back"}, []string{"传统硬拉", "Conventional Deadlift"}, "全身复合动作", "全身硬拉动作"},
+	{"杠铃耸肩", "Barbell Shrug", "back", "intermediate", "isolation", "barbell", "isolation", []string{"traps"}, []string{"forearms"}, nil, "斜方肌训练", "杠铃耸肩"},
+	{"哑铃耸肩", "Dumbbell Shrug", "back", "intermediate", "isolation", "dumbbell", "isolation", []string{"traps"}, []string{"forearms"}, nil, "斜方肌训练", "哑铃耸肩"},
+
+	// 腿部
+	{"深蹲", "Back Squat", "legs", "intermediate", "compound", "barbell", "squat", []string{"quads", "glutes"}, []string{"hamstrings", "adductors", "lower_back", "abs"}, []string{"杠铃深蹲", "后蹲", "Squat"}, "经典的下肢训练动作", "双脚与肩同宽，下蹲至大腿平行地面"},
+	{"前蹲", "Front Squat", "legs", "advanced", "compound", "barbell", "squat", []string{"quads"}, []string{"glutes", "abs", "upper_back"}, nil, "腿部前侧训练", "杠铃置于胸前"},
+	{"相扑深蹲", "Sumo Squat", "legs", "intermediate", "compound", "barbell", "squat", []string{"quads", "adductors", "glutes"}, []string{"hamstrings"}, nil, "腿部内侧训练", "双脚宽距深蹲"},
+	{"腿举", "Leg Press", "legs", "intermediate", "compound", "machine", "squat", []string{"quads", "glutes"}, []string{"hamstrings"}, []string{"倒蹬"}, "腿部力量训练", "腿部推举动作"},
+	{"哈克深蹲", "Hack Squat", "legs", "intermediate", "compound", "machine", "squat", []string{"quads"}, []string{"glutes"}, nil, "腿部力量训练", "哈克深蹲"},
+	{"墙蹲", "Wall Sit", "legs", "beginner", "isolation", "bodyweight", "squat", []string{"quads"}, []string{"glutes"}, nil, "腿部耐力训练", "靠墙深蹲"},
+	{"单腿深蹲", "Pistol Squat", "legs", "advanced", "compound", "bodyweight", "squat", []string{"quads", "glutes"}, []string{"abs"}, []string{"手枪深蹲", "Single-Leg Squat"}, "单腿力量训练", "单腿深蹲"},
+	{"保加利亚分腿蹲", "Bulgarian Split Squat", "legs", "intermediate", "compound", "dumbbell", "lunge", []string{"quads", "glutes"}, []string{"hamstrings", "adductors"}, nil, "单腿训练", "单腿分腿蹲"},
+	{"弓步蹲", "Dumbbell Lunge", "legs", "intermediate", "compound", "dumbbell", "lunge", []string{"quads", "glutes"}, []string{"hamstrings", "adductors"}, []string{"箭步蹲", "Lunge"}, "腿部功能性训练", "弓步蹲动作"},
+	{"侧弓步", "Lateral Lunge", "legs", "intermediate", "compound", "bodyweight", "lunge", []string{"adductors", "quads", "glutes"}, nil, nil, "腿部侧向训练", "侧向弓步"},
+	{"罗马尼亚硬拉", "Romanian Deadlift", "legs", "intermediate", "compound", "barbell", "hinge", []string{"hamstrings", "glutes"}, []string{"lower_back"}, []string{"直腿硬拉", "RDL"}, "腿部后侧训练", "罗马尼亚硬拉"},
+	{"壶铃摆荡", "Kettlebell Swing", "legs", "intermediate", "compound", "kettlebell", "hinge", []string{"glutes", "hamstrings"}, []string{"lower_back", "abs"}, nil, "臀腿爆发力训练", "髋部发力将壶铃摆至胸前"},
+	{"腿弯举", "Leg Curl", "legs", "intermediate", "isolation", "machine", "isolation", []string{"hamstrings"}, []string{"calves"}, nil, "腿部后侧训练", "腿部弯举"},
+	{"腿屈伸", "Leg Extension", "legs", "intermediate", "isolation", "machine", "isolation", []string{"quads"}, nil, nil, "腿部前侧训练", "腿部屈伸"},
+	{"提踵", "Standing Calf Raise", "legs", "beginner", "isolation", "barbell", "isolation", []string{"calves"}, nil, []string{"站姿提踵", "Calf Raise"}, "小腿训练", "小腿提踵动作"},
+	{"单腿提踵", "Single-Leg Calf Raise", "legs", "intermediate", "isolation", "bodyweight", "isolation", []string{"calves"}, nil, nil, "小腿单侧训练", "单腿提踵"},
+	{"坐姿提踵", "Seated Calf Raise", "legs", "beginner", "isolation", "machine", "isolation", []string{"calves"}, nil, nil, "小腿训练", "坐姿提踵"},
+	{"跳箱", "Box Jump", "legs", "advanced", "cardio", "box", "plyometric", []string{"quads", "glutes"}, []string{"calves"}, nil, "腿部爆发力训练", "跳箱训练"},
+	{"波比跳", "Burpee", "legs", "intermediate", "cardio", "bodyweight", "plyometric", []string{"quads", "chest"}, []string{"glutes", "triceps", "abs"}, []string{"Burpees"}, "全身有氧训练", "下蹲、俯卧撑、起跳连贯完成"},
+	{"开合跳", "Jumping Jack", "legs", "beginner", "cardio", "bodyweight", "plyometric", []string{"calves"}, []string{"glutes", "side_delts"}, nil, "全身有氧热身", "双脚开合跳跃，双手同步上举"},
+
+	// 肩部
+	{"肩推", "Dumbbell Shoulder Press", "shoulders", "intermediate", "compound", "dumbbell", "vertical_push", []string{"front_delts", "side_delts"}, []string{"triceps"}, []string{"哑铃推举", "哑铃肩推"}, "肩部力量训练", "双手持哑铃，从肩部推举至头顶"},
+	{"杠铃推举", "Overhead Press", "shoulders", "intermediate", "compound", "barbell", "vertical_push", []string{"front_delts", "side_delts"}, []string{"triceps", "traps", "abs"}, []string{"站姿推举", "实力推", "OHP", "Military Press"}, "肩部力量训练", "杠铃推举"},
+	{"阿诺德推举", "Arnold Press", "shoulders", "advanced", "compound", "dumbbell", "vertical_push", []string{"front_delts", "side_delts"}, []string{"triceps"}, nil, "肩部复合训练", "阿诺德推举"},
+	{"侧平举", "Lateral Raise", "shoulders", "intermediate", "isolation", "dumbbell", "isolation", []string{"side_delts"}, []string{"traps"}, []string{"哑铃侧平举"}, "肩部宽度训练", "侧平举动作"},
+	{"绳索侧平举", "Cable Lateral Raise", "shoulders", "intermediate", "isolation", "cable", "isolation", []string{"side_delts"}, nil, nil, "肩部塑形", "绳索侧平举"},
+	{"前平举", "Front Raise", "shoulders", "intermediate", "isolation", "dumbbell", "isolation", []string{"front_delts"}, nil, []string{"哑铃前平举"}, "肩部前束训练", "前平举动作"},
+	{"杠铃前平举", "Barbell Front Raise", "shoulders", "intermediate", "isolation", "barbell", "isolation", []string{"front_delts"}, nil, nil, "肩部前束训练", "杠铃前平举"},
+	{"绳索前平举", "Cable Front Raise", "shoulders", "intermediate", "isolation", "cable", "isolation", []string{"front_delts"}, nil, nil, "肩部前束训练", "绳索前平举"},
+	{"俯身侧平举", "Bent-Over Lateral Raise", "shoulders", "intermediate", "isolation", "dumbbell", "isolation", []string{"rear_delts"}, []string{"upper_back"}, nil, "肩部后束训练", "俯身侧平举"},
+	{"绳索后平举", "Cable Reverse Fly", "shoulders", "intermediate", "isolation", "cable", "isolation", []string{"rear_delts"}, []string{"upper_back"}, nil, "肩部后束训练", "绳索后平举"},
+
+	// 手臂
+	{"二头弯举", "Dumbbell Curl", "arms", "beginner", "isolation", "dumbbell", "isolation", []string{"biceps"}, []string{"forearms"}, []string{"哑铃弯举", "Bicep Curl"}, "二头肌训练", "二头弯举动作"},
+	{"单臂哑铃弯举", "Single-Arm Dumbbell Curl", "arms", "intermediate", "isolation", "dumbbell", "isolation", []string{"biceps"}, nil, nil, "二头肌单侧训练", "单臂哑铃弯举"},
+	{"杠铃弯举", "Barbell Curl", "arms", "intermediate", "isolation", "barbell", "isolation", []string{"biceps"}, []string{"forearms"}, nil, "二头肌力量训练", "杠铃弯举"},
+	{"绳索弯举", "Cable Curl", "arms", "intermediate", "isolation", "cable", "isolation", []string{"biceps"}, nil, nil, "二头肌训练", "绳索弯举"},
+	{"集中弯举", "Concentration Curl", "arms", "intermediate", "isolation", "dumbbell", "isolation", []string{"biceps"}, nil, nil, "二头肌训练", "集中弯举"},
+	{"锤式弯举", "Hammer Curl", "arms", "intermediate", "isolation", "dumbbell", "isolation", []string{"biceps", "forearms"}, nil, nil, "二头肌训练", "锤式弯举"},
+	{"绳索锤式弯举", "Cable Hammer Curl", "arms", "intermediate", "isolation", "cable", "isolation", []string{"biceps", "forearms"}, nil, nil, "二头肌训练", "绳索锤式弯举"},
+	{"反向弯举", "Reverse Curl", "arms", "intermediate", "isolation", "barbell", "isolation", []string{"forearms", "biceps"}, nil, nil, "前臂训练", "反向弯举"},
+	{"杠铃腕弯举", "Barbell Wrist Curl", "arms", "beginner", "isolation", "barbell", "isolation", []string{"forearms"}, nil, nil, "前臂训练", "杠铃腕弯举"},
+	{"三头屈伸", "Dumbbell Triceps Extension", "arms", "intermediate", "isolation", "dumbbell", "isolation", []string{"triceps"}, nil, nil, "三头肌训练", "三头屈伸动作"},
+	{"过顶臂屈伸", "Overhead Triceps Extension", "arms", "intermediate", "isolation", "dumbbell", "isolation", []string{"triceps"}, nil, nil, "三头肌训练", "过顶臂屈伸"},
+	{"绳索下压", "Triceps Pushdown", "arms", "intermediate", "isolation", "cable", "isolation", []string{"triceps"}, nil, []string{"三头下压"}, "三头肌训练", "绳索下压"},
+	{"单臂绳索下压", "Single-Arm Cable Pushdown", "arms", "intermediate", "isolation", "cable", "isolation", []string{"triceps"}, nil, nil, "三头肌单侧训练", "单臂绳索下压"},
+	{"三头臂屈伸", "Bench Dip", "arms", "intermediate", "compound", "bodyweight", "vertical_push", []string{"triceps"}, []string{"chest", "front_delts"}, []string{"凳上臂屈伸"}, "三头肌训练", "三头臂屈伸"},
+	{"窄握卧推", "Close-Grip Bench Press", "arms", "intermediate", "compound", "barbell", "horizontal_push", []string{"triceps", "chest"}, []string{"front_delts"}, nil, "三头肌训练", "窄握卧推"},
+
+	// 核心
+	{"平板支撑", "Plank", "core", "beginner", "isolation", "bodyweight", "core", []string{"abs"}, []string{"obliques", "front_delts"}, nil, "核心力量训练", "保持身体成一条直线，核心收紧"},
+	{"侧平板支撑", "Side Plank", "core", "intermediate", "isolation", "bodyweight", "core", []string{"obliques"}, []string{"abs", "glutes"}, []string{"侧支撑"}, "核心侧向训练", "侧平板支撑"},
+	{"死虫式", "Dead Bug", "core", "beginner", "isolation", "bodyweight", "core", []string{"abs"}, []string{"obliques"}, nil, "核心稳定", "死虫式动作"},
+	{"鸟狗式", "Bird Dog", "core", "beginner", "isolation", "bodyweight", "core", []string{"lower_back", "abs"}, []string{"glutes"}, nil, "核心稳定", "鸟狗式"},
+	{"超人式", "Superman", "core", "beginner", "isolation", "bodyweight", "core", []string{"lower_back"}, []string{"glutes"}, nil, "背部核心训练", "超人式"},
+	{"卷腹", "Crunch", "core", "beginner", "isolation", "bodyweight", "core", []string{"abs"}, nil, nil, "腹肌训练", "卷腹动作"},
+	{"仰卧起坐", "Sit-Up", "core", "beginner", "isolation", "bodyweight", "core", []string{"abs"}, nil, nil, "腹肌训练", "仰卧起坐"},
+	{"反向卷腹", "Reverse Crunch", "core", "intermediate", "isolation", "bodyweight", "core", []string{"abs"}, nil, nil, "下腹训练", "反向卷腹"},
+	{"V字卷腹", "V-Up", "core", "intermediate", "isolation", "bodyweight", "core", []string{"abs"}, []string{"obliques"}, nil, "腹肌训练", "V字卷腹"},
+	{"自行车卷腹", "Bicycle Crunch", "core", "intermediate", "isolation", "bodyweight", "core", []string{"abs", "obliques"}, nil, nil, "腹肌训练", "自行车卷腹"},
+	{"侧卷腹", "Side Crunch", "core", "intermediate", "isolation", "bodyweight", "core", []string{"obliques"}, nil, nil, "腹肌侧向训练", "侧卷腹"},
+	{"俄罗斯转体", "Russian Twist", "core", "intermediate", "isolation", "bodyweight", "core", []string{"obliques"}, []string{"abs"}, nil, "腹肌训练", "俄罗斯转体"},
+	{"悬垂举腿", "Hanging Leg Raise", "core", "advanced", "isolation", "pull_up_bar", "core", []string{"abs"}, []string{"obliques", "forearms"}, nil, "腹肌训练", "悬垂举腿"},
+	{"龙旗", "Dragon Flag", "core", "advanced", "isolation", "bodyweight", "core", []string{"abs"}, []string{"obliques", "lats"}, nil, "腹肌训练", "龙旗动作"},
+	{"登山者", "Mountain Climber", "core", "intermediate", "cardio", "bodyweight", "core", []string{"abs"}, []string{"front_delts", "quads"}, nil, "全身有氧", "登山者动作"},
+	{"农夫行走", "Farmer's Walk", "core", "intermediate", "compound", "dumbbell", "carry", []string{"forearms", "traps"}, []string{"abs", "obliques", "glutes"}, nil, "全身训练", "农夫行走"},
+	{"土耳其起立", "Turkish Get-Up", "core", "advanced", "compound", "kettlebell", "core", []string{"abs", "obliques", "front_delts"}, []string{"glutes", "quads"}, nil, "全身训练", "土耳其起立"},
+}
+
+// minSubstitutionSimilarity 替换组内动作的最低相似度
+const minSubstitutionSimilarity = 0.1
+
+// exerciseSubstitutionGroups 可相互替换的动作组，组内两两建立替换关系
+var exerciseSubstitutionGroups = [][]string{
+	{"平板卧推", "哑铃卧推", "俯卧撑", "宽距俯卧撑"},
+	{"上斜卧推", "上斜俯卧撑", "上斜哑铃飞鸟"},
+	{"下斜卧推", "下斜俯卧撑", "双杠臂屈伸", "下斜哑铃飞鸟"},
+	{"哑铃飞鸟", "绳索夹胸"},
+	{"引体向上", "宽握引体向上", "对握引体向上", "反手引体", "高位下拉"},
+	{"窄握引体向上", "反手引体"},
+	{"直臂下拉", "高位下拉"},
+	{"杠铃划船", "哑铃划船", "T杠划船", "海豹划船", "坐姿绳索划船", "单臂绳索划船"},
+	{"反向飞鸟", "面拉", "俯身侧平举", "绳索后平举"},
+	{"杠铃耸肩", "哑铃耸肩", "农夫行走"},
+	{"深蹲", "前蹲", "相扑深蹲", "腿举", "哈克深蹲", "保加利亚分腿蹲"},
+	{"保加利亚分腿蹲", "弓步蹲", "单腿深蹲", "侧弓步"},
+	{"硬拉", "罗马尼亚硬拉", "壶铃摆荡"},
+	{"罗马尼亚硬拉", "腿弯举"},
+	{"腿屈伸", "哈克深蹲", "墙蹲"},
+	{"提踵", "单腿提踵", "坐姿提踵"},
+	{"肩推", "杠铃推举", "阿诺德推举"},
+	{"侧平举", "绳索侧平举"},
+	{"前平举", "杠铃前平举", "绳索前平举"},
+	{"二头弯举", "单臂哑铃弯举", "杠铃弯举", "绳索弯举", "集中弯举"},
+	{"锤式弯举", "绳索锤式弯举", "反向弯举"},
+	{"三头屈伸", "过顶臂屈伸", "绳索下压", "单臂绳索下压", "三头臂屈伸", "窄握卧推", "窄距俯卧撑"},
+	{"平板支撑", "死虫式", "鸟狗式"},
+	{"卷腹", "仰卧起坐", "反向卷腹", "V字卷腹", "自行车卷腹"},
+	{"悬垂举腿", "反向卷腹", "龙旗"},
+	{"俄罗斯转体", "侧卷腹", "侧平板支撑", "自行车卷腹"},
+	{"登山者", "波比跳", "开合跳", "跳箱"},
+}
+
+// SeedExerciseLibrary 写入标准动作库和替换关系，并为已有的计划动作关联动作库
+//
+// 已存在的同名条目不会被覆盖，可重复执行。
+func SeedExerciseLibrary(db *gorm.DB) error {
+	var existing []models.ExerciseLibrary
+	if err := db.Find(&existing).Error; err != nil {
+		return err
+	}
+	byName := make(map[string]models.ExerciseLibrary, len(existing))
+	for _, entry := range existing {
+		byName[entry.Name] = entry
+	}
+
+	for _, seed := range exerciseLibrarySeeds {
+		if entry, ok := byName[seed.Name]; ok {
+			// 补全早期种子没有的 MET
+			if entry.MET == 0 {
+				entry.MET = seedMET(seed)
+				if err := db.Model(&entry).Update("met", entry.MET).Error; err != nil {
+					return fmt.Errorf("failed to update MET of %s: %w", seed.Name, err)
+				}
+				byName[seed.Name] = entry
+			}
+			continue
+		}
+		entry := models.ExerciseLibrary{
+			Name:             seed.Name,
+			NameEN:           seed.NameEN,
+			Part:             seed.Part,
+			Level:            seed.Level,
+			Type:             seed.Type,
+			Equipment:        seed.Equipment,
+			MovementPattern:  seed.Pattern,
+			MET:              seedMET(seed),
+			PrimaryMuscles:   models.EncodeStringList(seed.Primary),
+			SecondaryMuscles: models.EncodeStringList(seed.Secondary),
+			MuscleGroups:     seed.Part,
+			Aliases:          models.EncodeStringList(seed.Aliases),
+			Tags:             "[]",
+			Description:      seed.Description,
+			Instructions:     seed.Instructions,
+		}
+		if err := db.Create(&entry).Error; err != nil {
+			return fmt.Errorf("failed to seed exercise %s: %w", seed.Name, err)
+		}
+		byName[entry.Name] = entry
+	}
+
+	if err := seedExerciseSubstitutions(db, byName); err != nil {
+		return err
+	}
+	return linkPlanExercisesToLibrary(db, byName)
+}
+
+// exerciseMETOverrides 与动作类型默认值差别较大的动作 MET
+var exerciseMETOverrides = map[string]float64{
+	"俯卧撑":  3.8, // 中等强度自重
+	"引体向上": 3.8,
+	"开合跳":  7.7,
+	"平板支撑": 3.0,
+}
+
+// seedMET 动作的 MET，没有单独指定时按动作类型取默认值
+func seedMET(seed exerciseSeed) float64 {
+	if met, ok := exerciseMETOverrides[seed.Name]; ok {
+		return met
+	}
+	return models.METForType(seed.Type)
+}
+
+// seedExerciseSubstitutions 为替换组内的动作两两建立替换关系
+func seedExerciseSubstitutions(db *gorm.DB, byName map[string]models.ExerciseLibrary) error {
+	var edges []models.ExerciseSubstitution
+	if err := db.Find(&edges).Error; err != nil {
+		return err
+	}
+	exists := make(map[[2]uint]bool, len(edges))
+	for _, edge := range edges {
+		exists[[2]uint{edge.ExerciseID, edge.SubstituteID}] = true
+	}
+
+	for _, group := range exerciseSubstitutionGroups {
+		for _, fromName := range group {
+			from, ok := byName[fromName]
+			if !ok {
+				return fmt.Errorf("unknown exercise in substitution group: %s", fromName)
+			}
+			for _, toName := range group {
+				to, ok := byName[toName]
+				if !ok {
+					return fmt.Errorf("unknown exercise in substitution group: %s", toName)
+				}
+				key := [2]uint{from.ID, to.ID}
+				if from.ID == to.ID || exists[key] {
+					continue
+				}
+				// 同组动作已人工确认可替换，相似度至少为 minSubstitutionSimilarity
+				similarity := models.ExerciseSimilarity(from, to)
+				if similarity < minSubstitutionSimilarity {
+					similarity = minSubstitutionSimilarity
+				}
+				edge := models.ExerciseSubstitution{
+					ExerciseID:   from.ID,
+					SubstituteID: to.ID,
+					Similarity:   similarity,
+				}
+				if err := db.Create(&edge).Error; err != nil {
+					return err
+				}
+				exists[key] = true
+			}
+		}
+	}
+	return nil
+}
+
+// linkPlanExercisesToLibrary 按名称或别名为尚未关联的计划动作补充动作库ID
+func linkPlanExercisesToLibrary(db *gorm.DB, byName map[string]models.ExerciseLibrary) error {
+	var names []string
+	if err := db.Model(&models.Exercise{}).
+		Where("exercise_library_id IS NULL").
+		Distinct().Pluck("name", &names).Error; err != nil {
+		return err
+	}
+	if len(names) == 0 {
+		return nil
+	}
+
+	// 只接受名称或别名完全匹配（忽略大小写和首尾空格）
+	ids := make(map[string]uint)
+	for _, entry := range byName {
+		for _, alias := range append([]string{entry.NameEN}, entry.AliasList()...) {
+			if key := strings.ToLower(strings.TrimSpace(alias)); key != "" {
+				ids[key] = entry.ID
+			}
+		}
+	}
+	for _, entry := range byName {
+		ids[strings.ToLower(strings.TrimSpace(entry.Name))] = entry.ID
+	}
+
+	for _, name := range names {
+		id, ok := ids[strings.ToLower(strings.TrimSpace(name))]
+		if !ok {
+			continue
+		}
+		if err := db.Model(&models.Exercise{}).
+			Where("exercise_library_id IS NULL AND name = ?", name).
+			Update("exercise_library_id", id).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
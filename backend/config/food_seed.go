@@ -0,0 +1,61 @@
+package config
+
+import (
+	"fmt"
+
+	"gymates-backend/models"
+
+	"gorm.io/gorm"
+)
+
+// foodSeeds 常见食物，营养数值为每份含量
+var foodSeeds = []models.Food{
+	// 主食
+	{Name: "米饭", ServingSize: 100, ServingUnit: "g", Calories: 116, Protein: 2.6, Carbs: 25.9, Fat: 0.3},
+	{Name: "全麦面包", ServingSize: 1, ServingUnit: "片", Calories: 80, Protein: 4, Carbs: 14, Fat: 1.1},
+	{Name: "燕麦片", ServingSize: 40, ServingUnit: "g", Calories: 150, Protein: 5.4, Carbs: 26.4, Fat: 2.7},
+	{Name: "红薯", ServingSize: 100, ServingUnit: "g", Calories: 86, Protein: 1.6, Carbs: 20.1, Fat: 0.1},
+	{Name: "玉米", ServingSize: 1, ServingUnit: "根", Calories: 112, Protein: 4, Carbs: 22.8, Fat: 1.2},
+	{Name: "面条", ServingSize: 100, ServingUnit: "g", Calories: 110, Protein: 3.9, Carbs: 22.8, Fat: 0.4},
+
+	// 蛋白质
+	{Name: "鸡胸肉", ServingSize: 100, ServingUnit: "g", Calories: 133, Protein: 24.6, Carbs: 0, Fat: 3.6},
+	{Name: "鸡蛋", ServingSize: 1, ServingUnit: "个", Calories: 72, Protein: 6.3, Carbs: 0.4, Fat: 4.8},
+	{Name: "牛肉（瘦）", ServingSize: 100, ServingUnit: "g", Calories: 106, Protein: 20.2, Carbs: 1.2, Fat: 2.3},
+	{Name: "三文鱼", ServingSize: 100, ServingUnit: "g", Calories: 208, Protein: 20.4, Carbs: 0, Fat: 13.4},
+	{Name: "虾仁", ServingSize: 100, ServingUnit: "g", Calories: 93, Protein: 18.6, Carbs: 0, Fat: 1.5},
+	{Name: "豆腐", ServingSize: 100, ServingUnit: "g", Calories: 82, Protein: 8.1, Carbs: 4.2, Fat: 3.7},
+	{Name: "乳清蛋白粉", ServingSize: 30, ServingUnit: "g", Calories: 120, Protein: 24, Carbs: 3, Fat: 1.5},
+	{Name: "牛奶", ServingSize: 250, ServingUnit: "ml", Calories: 163, Protein: 8, Carbs: 12, Fat: 9},
+	{Name: "希腊酸奶", ServingSize: 150, ServingUnit: "g", Calories: 146, Protein: 15, Carbs: 6, Fat: 7},
+
+	// 蔬果与坚果
+	{Name: "西兰花", ServingSize: 100, ServingUnit: "g", Calories: 36, Protein: 4.1, Carbs: 4.3, Fat: 0.6},
+	{Name: "香蕉", ServingSize: 1, ServingUnit: "根", Calories: 105, Protein: 1.3, Carbs: 27, Fat: 0.4},
+	{Name: "苹果", ServingSize: 1, ServingUnit: "个", Calories: 95, Protein: 0.5, Carbs: 25, Fat: 0.3},
+	{Name: "牛油果", ServingSize: 100, ServingUnit: "g", Calories: 160, Protein: 2, Carbs: 8.5, Fat: 14.7},
+	{Name: "杏仁", ServingSize: 28, ServingUnit: "g", Calories: 164, Protein: 6, Carbs: 6.1, Fat: 14.2},
+}
+
+// SeedFoods 写入常见食物，已存在的同名内置食物不会重复写入，可重复执行
+func SeedFoods(db *gorm.DB) error {
+	var existing []models.Food
+	if err := db.Where("user_id IS NULL").Find(&existing).Error; err != nil {
+		return err
+	}
+	byName := make(map[string]bool, len(existing))
+	for _, food := range existing {
+		byName[food.Name] = true
+	}
+
+	for _, seed := range foodSeeds {
+		if byName[seed.Name] {
+			continue
+		}
+		food := seed
+		if err := db.Create(&food).Error; err != nil {
+			return fmt.Errorf("failed to seed food %s: %w", seed.Name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package config
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+	"gymates-backend/models"
+)
+
+// legacyMate 旧版 mates 表的一行，接受请求后每对用户各有一行
+type legacyMate struct {
+	UserID    uint
+	MateID    uint
+	Status    string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// MigrateLegacyMates 把旧版 mates 表中的单向记录合并为 mate_relations，只在新表为空时执行
+//
+// 任一方向 accepted 或双方互相 pending 即为搭子；否则取最近的一条 pending/rejected 作为请求或拒绝记录。
+func MigrateLegacyMates(db *gorm.DB) error {
+	if !db.Migrator().HasTable("mates") {
+		return nil
+	}
+	var existing int64
+	if err := db.Model(&models.MateRelation{}).Count(&existing).Error; err != nil || existing > 0 {
+		return err
+	}
+
+	var rows []legacyMate
+	if err := db.Table("mates").Where("deleted_at IS NULL").Order("updated_at").Find(&rows).Error; err != nil {
+		return err
+	}
+
+	relations := make(map[[2]uint]*models.MateRelation)
+	var order [][2]uint
+	for _, row := range rows {
+		if row.UserID == row.MateID {
+			continue
+		}
+		low, high := models.MatePair(row.UserID, row.MateID)
+		key := [2]uint{low, high}
+		relation, ok := relations[key]
+		if !ok {
+			relation = &models.MateRelation{UserLowID: low, UserHighID: high, CreatedAt: row.CreatedAt}
+			relations[key] = relation
+			order = append(order, key)
+		}
+		if relation.Status == models.MateStatusAccepted {
+			continue
+		}
+
+		requestedAt, updatedAt := row.CreatedAt, row.UpdatedAt
+		if row.Status == "pending" && relation.Status == models.MateStatusPending && relation.RequesterID != row.UserID {
+			// 双方互相发送过请求，和现在的规则一样视为同意
+			relation.Status = models.MateStatusAccepted
+			relation.ActorID = row.UserID
+			relation.RespondedAt = &updatedAt
+			relation.UpdatedAt = updatedAt
+			continue
+		}
+		relation.RequesterID = row.UserID
+		relation.ActorID = row.UserID
+		relation.RequestedAt = &requestedAt
+		relation.UpdatedAt = updatedAt
+		switch row.Status {
+		case "accepted":
+			relation.Status = models.MateStatusAccepted
+			relation.ActorID = row.MateID
+			relation.RespondedAt = &updatedAt
+		case "rejected":
+			relation.Status = models.MateStatusDeclined
+			relation.ActorID = row.MateID
+			relation.RespondedAt = &updatedAt
+		default:
+			relation.Status = models.MateStatusPending
+		}
+	}
+
+	if len(order) == 0 {
+		return nil
+	}
+	list := make([]models.MateRelation, len(order))
+	for i, key := range order {
+		list[i] = *relations[key]
+	}
+	return db.CreateInBatches(list, 100).Error
+}
@@ -5,6 +5,8 @@ import (
 	"strconv"
 	"time"
 
+	"gymates-backend/config"
+	"gymates-backend/models"
 	"gymates-backend/services"
 
 	"github.com/gin-gonic/gin"
@@ -32,7 +34,6 @@ type CoachChatResponse struct {
 
 // TrainingProgressRequest 训练进度请求
 type TrainingProgressRequest struct {
-	UserID             int    `json:"user_id" binding:"required"`
 	PlanID             string `json:"plan_id"`
 	CompletedAt        string `json:"completed_at"`
 	ExercisesCompleted int    `json:"exercises_completed"`
@@ -106,7 +107,7 @@ func (c *AICoachController) CoachChat(ctx *gin.Context) {
 	messages := []services.ChatMessage{
 		{
 			Role:    "system",
-			Content: c.buildSystemPrompt(req.Context),
+			Content: c.buildSystemPrompt(uint(req.UserID), req.Context),
 		},
 		{
 			Role:    "user",
@@ -146,8 +147,18 @@ func (c *AICoachController) CoachChat(ctx *gin.Context) {
 	})
 }
 
-// TrainingProgress 训练进度上报接口
+// TrainingProgress 训练进度上报接口，记录到当前登录用户
 func (c *AICoachController) TrainingProgress(ctx *gin.Context) {
+	currentUser, exists := ctx.Get("user")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"message": "用户未认证",
+			"error":   "User not authenticated",
+		})
+		return
+	}
+
 	var req TrainingProgressRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{
@@ -158,22 +169,49 @@ func (c *AICoachController) TrainingProgress(ctx *gin.Context) {
 		return
 	}
 
+	var user models.User
+	if err := config.DB.First(&user, currentUser.(*models.User).ID).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"success": false,
+			"message": "用户不存在",
+			"error":   "User not found",
+		})
+		return
+	}
+	loc := user.TimeLocation()
+
 	// 解析完成时间
 	completedAt, err := time.Parse(time.RFC3339, req.CompletedAt)
 	if err != nil {
 		completedAt = time.Now()
 	}
 
-	// 使用completedAt变量（避免未使用错误）
-	_ = completedAt
+	// 保存进度上报记录，训练日期按用户时区计算
+	report := models.TrainingProgressReport{
+		UserID:             user.ID,
+		PlanID:             req.PlanID,
+		CompletedAt:        completedAt,
+		LocalDate:          models.LocalDate(completedAt, loc),
+		ExercisesCompleted: req.ExercisesCompleted,
+		Duration:           req.Duration,
+		CaloriesBurned:     req.CaloriesBurned,
+		Notes:              req.Notes,
+	}
+	if err := config.DB.Create(&report).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"message": "保存训练进度失败",
+			"error":   err.Error(),
+		})
+		return
+	}
 
-	// 这里应该保存到数据库，暂时返回模拟数据
-	progressID := "progress_" + strconv.FormatInt(time.Now().Unix(), 10)
+	progressID := "progress_" + strconv.FormatUint(uint64(report.ID), 10)
 
-	// 计算用户等级和连续训练天数（模拟）
-	totalWorkouts := 15 // 从数据库获取
-	streak := 7         // 从数据库获取
-	level := (totalWorkouts / 10) + 1
+	// 计算累计训练次数、连续训练天数和用户等级
+	totalWorkouts := countTotalWorkouts(user.ID)
+	streak := calculateStreak(getWorkoutTimes(user.ID), time.Now(), loc)
+	level := calculateLevel(totalWorkouts)
 
 	resp := TrainingProgressResponse{
 		Success: true,
@@ -314,8 +352,8 @@ func (c *AICoachController) SwitchProvider(ctx *gin.Context) {
 	})
 }
 
-// buildSystemPrompt 构建系统提示词
-func (c *AICoachController) buildSystemPrompt(context map[string]interface{}) string {
+// buildSystemPrompt 构建系统提示词（包含用户生效中的伤病限制和今日饮食）
+func (c *AICoachController) buildSystemPrompt(userID uint, context map[string]interface{}) string {
 	systemPrompt := `你是一位专业的AI健身教练，具备以下特点：
 
 1. 专业知识：
@@ -341,6 +379,18 @@ func (c *AICoachController) buildSystemPrompt(context map[string]interface{}) st
 
 请用中文回复，语气要专业而友好。`
 
+	if injuries := userInjuryFilter(config.DB, userID).PromptSection(); injuries != "" {
+		systemPrompt += "\n\n" + injuries
+	}
+
+	var user models.User
+	if err := config.DB.First(&user, userID).Error; err == nil {
+		today := models.LocalDate(time.Now(), user.TimeLocation())
+		if nutrition := services.NutritionPromptSection(dailyNutritionSummary(config.DB, &user, today)); nutrition != "" {
+			systemPrompt += "\n\n" + nutrition
+		}
+	}
+
 	// 根据上下文调整提示词
 	if context != nil {
 		if trainingPlan, ok := context["training_plan"]; ok {
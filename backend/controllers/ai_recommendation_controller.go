@@ -1,7 +1,6 @@
 package controllers
 
 import (
-	"math/rand"
 	"net/http"
 	"strconv"
 	"time"
@@ -9,6 +8,8 @@ import (
 	"github.com/gin-gonic/gin"
 	"gymates-backend/config"
 	"gymates-backend/models"
+	"gymates-backend/services"
+	"gymates-backend/services/recommendation"
 )
 
 // AIRecommendationController AI推荐控制器
@@ -20,17 +21,18 @@ func NewAIRecommendationController() *AIRecommendationController {
 }
 
 // GetAIRecommendation 获取AI推荐训练
-// GET /api/training/ai/recommend?user_id={uid}&day={Monday}
+// GET /api/training/ai/recommend?user_id={uid}&day={Monday}&seed={seed}
+// day 为空时按用户时区取当天；传入响应中的 seed 可复现同一推荐
 func (aic *AIRecommendationController) GetAIRecommendation(c *gin.Context) {
 	userIDStr := c.Query("user_id")
 	day := c.Query("day")
 	muscleGroup := c.Query("muscle_group") // 可选指定肌群
 
-	if userIDStr == "" || day == "" {
+	if userIDStr == "" {
 		c.JSON(http.StatusBadRequest, models.ErrorResponse{
 			Success: false,
-			Message: "用户ID和训练日不能为空",
-			Error:   "user_id and day are required",
+			Message: "用户ID不能为空",
+			Error:   "user_id is required",
 			Code:    http.StatusBadRequest,
 		})
 		return
@@ -47,244 +49,128 @@ func (aic *AIRecommendationController) GetAIRecommendation(c *gin.Context) {
 		return
 	}
 
-	// 获取用户训练模式
-	var trainingMode models.TrainingMode
-	if err := config.DB.Where("user_id = ? AND is_active = ?", uint(userID), true).
-		First(&trainingMode).Error; err != nil {
-		// 如果没有设置训练模式，使用默认的三分化
-		trainingMode = models.TrainingMode{
-			UserID:    uint(userID),
-			Mode:      "三分化",
-			TrainDays: 3,
-			Target:    "增肌",
-			Level:     "中级",
-			IsActive:  true,
-		}
+	seed, err := recommendationSeed(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Message: "无效的随机种子",
+			Error:   err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	if day == "" {
+		day = time.Now().In(loadUserLocation(uint(userID))).Weekday().String()
+	}
+
+	// 获取用户训练模式及一周安排
+	trainingMode := userTrainingMode(config.DB, uint(userID))
+	_, schedule := resolveTrainingSchedule(config.DB, trainingMode)
+	scheduleDay, ok := services.ScheduleDay(schedule, day)
+	if !ok {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Message: "无效的训练日",
+			Error:   "day must be Monday...Sunday",
+			Code:    http.StatusBadRequest,
+		})
+		return
 	}
 
-	// 获取用户训练历史
+	// 获取用户最近7天练过的动作
 	var recentHistory []models.UserTrainingHistory
 	config.DB.Where("user_id = ? AND completed_at > ?", uint(userID), time.Now().AddDate(0, 0, -7)).
 		Order("completed_at DESC").
 		Limit(20).
 		Find(&recentHistory)
+	recent := map[uint]bool{}
+	for _, history := range recentHistory {
+		recent[history.ExerciseID] = true
+	}
+
+	// 根据分化安排确定目标肌群，指定肌群时以指定为准
+	targetMuscleGroups := scheduleDay.Parts
+	if muscleGroup != "" {
+		targetMuscleGroups = []string{muscleGroup}
+	}
 
-	// 根据训练模式和训练日确定目标肌群
-	targetMuscleGroups := aic.getTargetMuscleGroups(day, trainingMode.Mode, muscleGroup)
+	// 按肌肉恢复度和每周训练量调整：优先安排已恢复且训练量不足的部位和动作
+	muscleRecovery, _ := loadMuscleRecovery(config.DB, uint(userID), time.Now())
+	if muscleGroup == "" && !scheduleDay.RestDay {
+		targetMuscleGroups = rankTargetParts(targetMuscleGroups, muscleRecovery)
+	}
 
 	// 生成推荐
-	recommendation := models.AIRecommendationResponse{
+	result := models.AIRecommendationResponse{
 		UserID: uint(userID),
 		Day:    day,
 		Parts:  []models.RecommendedPart{},
 		Mode:   trainingMode.Mode,
 		Target: trainingMode.Target,
+		Session: scheduleDay.Session,
+		RestDay: scheduleDay.RestDay && muscleGroup == "",
+		Seed:    seed,
+		PriorityMuscles: priorityMuscles(targetMuscleGroups, muscleRecovery),
 	}
 
-	// 为每个目标肌群生成推荐动作
-	for _, muscleGroup := range targetMuscleGroups {
-		part := models.RecommendedPart{
-			PartName:  getPartName(muscleGroup),
-			Exercises: []models.RecommendedExercise{},
-		}
-
-		// 从动作库中筛选动作
-		var exercises []models.ExerciseLibrary
-		query := config.DB.Where("part = ?", muscleGroup)
-		
-		// 根据用户等级筛选
-		if trainingMode.Level == "初级" {
-			query = query.Where("level IN (?)", []string{"beginner", "intermediate"})
-		} else if trainingMode.Level == "高级" {
-			query = query.Where("level IN (?)", []string{"intermediate", "advanced"})
-		}
-
-		// 避免重复最近训练的动作
-		if len(recentHistory) > 0 {
-			var recentExerciseIDs []uint
-			for _, history := range recentHistory {
-				if history.MuscleGroup == muscleGroup {
-					recentExerciseIDs = append(recentExerciseIDs, history.ExerciseID)
-				}
-			}
-			if len(recentExerciseIDs) > 0 {
-				query = query.Where("id NOT IN (?)", recentExerciseIDs)
-			}
-		}
-
-		// 随机选择5-7个动作
-		limit := 7
-		if trainingMode.Target == "减脂" {
-			limit = 5 // 减脂训练动作少一些
-		}
-		
-		query.Order("RANDOM()").Limit(limit).Find(&exercises)
+	store := recommendation.NewGormStore(config.DB)
+	request := recommendation.Request{
+		Level:         trainingMode.Level,
+		Priorities:    services.MusclePriorities(muscleRecovery),
+		Recent:        recent,
+		ExcludeRecent: true, // 避免重复最近训练的动作
+		Injuries:      userInjuryFilter(config.DB, uint(userID)), // 排除或降权与用户伤病相冲突的动作
+		Prescribe:     recommendation.TargetPrescriber(trainingMode.Target, trainingMode.Level, recommendation.HistoryWeight(store, uint(userID))),
+	}
+	request.Filter.Levels = recommendation.LevelsFor(trainingMode.Level)
+	// 只推荐用户当前器械配置下可完成的动作
+	if profile, ok := activeEquipmentProfile(uint(userID)); ok {
+		result.EquipmentProfile = profile.Name
+		request.Equipment = profile.Name
+		request.Filter.Equipment = profile.EquipmentList()
+	}
+	// 每个部位推荐5-7个动作，减脂训练动作少一些
+	request.Limit = 7
+	if trainingMode.Target == "减脂" {
+		request.Limit = 5
+	}
 
-		// 转换为推荐动作
-		for _, exercise := range exercises {
-			recommendedExercise := models.RecommendedExercise{
-				Name:        exercise.Name,
-				Sets:        aic.generateSets(trainingMode.Target, trainingMode.Level),
-				Reps:        aic.generateReps(trainingMode.Target, trainingMode.Level),
-				Weight:      aic.generateWeight(uint(userID), exercise.Name),
-				RestSeconds: aic.generateRestTime(trainingMode.Target, trainingMode.Level),
-				Part:        exercise.Part,
-				Description: exercise.Description,
-				VideoURL:    "",
-				Notes:       "",
-			}
-			part.Exercises = append(part.Exercises, recommendedExercise)
+	engine := recommendation.NewEngine(store, recommendation.NewRand(seed))
+	for _, part := range targetMuscleGroups {
+		request.Filter.Parts = []string{part}
+		selected, err := engine.Recommend(request)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+				Success: false,
+				Message: "生成推荐失败",
+				Error:   err.Error(),
+				Code:    http.StatusInternalServerError,
+			})
+			return
 		}
-
-		if len(part.Exercises) > 0 {
-			recommendation.Parts = append(recommendation.Parts, part)
+		result.Restrictions = append(result.Restrictions, selected.Restrictions...)
+		if len(selected.Exercises) > 0 {
+			result.Parts = append(result.Parts, models.RecommendedPart{
+				PartName:  getPartName(part),
+				Exercises: selected.Exercises,
+			})
 		}
 	}
 
 	c.JSON(http.StatusOK, models.SuccessResponse{
 		Success: true,
 		Message: "AI推荐生成成功",
-		Data:    recommendation,
+		Data:    result,
 	})
 }
 
-// 根据训练模式和训练日确定目标肌群
-func (aic *AIRecommendationController) getTargetMuscleGroups(day, mode, specifiedMuscleGroup string) []string {
-	if specifiedMuscleGroup != "" {
-		return []string{specifiedMuscleGroup}
-	}
-
-	switch mode {
-	case "三分化":
-		return aic.getThreeDaySplitMuscleGroups(day)
-	case "五分化":
-		return aic.getFiveDaySplitMuscleGroups(day)
-	case "推拉腿":
-		return aic.getPushPullLegMuscleGroups(day)
-	default:
-		// 默认三分化
-		return aic.getThreeDaySplitMuscleGroups(day)
-	}
-}
-
-// 三分化训练肌群分配
-func (aic *AIRecommendationController) getThreeDaySplitMuscleGroups(day string) []string {
-	switch day {
-	case "Monday", "Thursday":
-		return []string{"chest", "shoulders", "arms"} // 推日
-	case "Tuesday", "Friday":
-		return []string{"back", "arms"} // 拉日
-	case "Wednesday", "Saturday":
-		return []string{"legs", "core"} // 腿日
-	default:
-		return []string{"core"} // 周日核心训练
-	}
-}
-
-// 五分化训练肌群分配
-func (aic *AIRecommendationController) getFiveDaySplitMuscleGroups(day string) []string {
-	switch day {
-	case "Monday":
-		return []string{"chest"}
-	case "Tuesday":
-		return []string{"back"}
-	case "Wednesday":
-		return []string{"legs"}
-	case "Thursday":
-		return []string{"shoulders"}
-	case "Friday":
-		return []string{"arms"}
-	case "Saturday":
-		return []string{"core"}
-	default:
-		return []string{"core"} // 周日核心训练
-	}
-}
-
-// 推拉腿训练肌群分配
-func (aic *AIRecommendationController) getPushPullLegMuscleGroups(day string) []string {
-	switch day {
-	case "Monday", "Thursday":
-		return []string{"chest", "shoulders", "arms"} // 推日
-	case "Tuesday", "Friday":
-		return []string{"back", "arms"} // 拉日
-	case "Wednesday", "Saturday":
-		return []string{"legs", "core"} // 腿日
-	default:
-		return []string{"core"} // 周日核心训练
-	}
-}
-
-// 生成组数
-func (aic *AIRecommendationController) generateSets(target, level string) int {
-	switch target {
-	case "增肌":
-		if level == "初级" {
-			return 3
-		} else if level == "中级" {
-			return 4
-		} else {
-			return 5
-		}
-	case "减脂":
-		return 3
-	default: // 综合
-		return 4
-	}
-}
-
-// 生成次数
-func (aic *AIRecommendationController) generateReps(target, level string) int {
-	switch target {
-	case "增肌":
-		if level == "初级" {
-			return 10 + rand.Intn(3) // 10-12
-		} else if level == "中级" {
-			return 8 + rand.Intn(5) // 8-12
-		} else {
-			return 6 + rand.Intn(7) // 6-12
-		}
-	case "减脂":
-		return 12 + rand.Intn(6) // 12-17
-	default: // 综合
-		return 10 + rand.Intn(5) // 10-14
-	}
-}
-
-// 生成重量（基于历史数据）
-func (aic *AIRecommendationController) generateWeight(userID uint, exerciseName string) float64 {
-	var history models.UserTrainingHistory
-	if err := config.DB.Where("user_id = ? AND exercise_id IN (SELECT id FROM exercise_libraries WHERE name = ?)", userID, exerciseName).
-		Order("completed_at DESC").
-		First(&history).Error; err == nil {
-		// 基于历史重量，增减5%
-		variation := 0.05
-		if rand.Float64() < 0.5 {
-			variation = -variation
-		}
-		return history.Weight * (1 + variation)
-	}
-	
-	// 如果没有历史数据，使用默认重量
-	return 20.0 + rand.Float64()*40.0 // 20-60kg
-}
-
-// 生成休息时间
-func (aic *AIRecommendationController) generateRestTime(target, level string) int {
-	switch target {
-	case "增肌":
-		if level == "初级" {
-			return 60 + rand.Intn(30) // 60-90秒
-		} else if level == "中级" {
-			return 90 + rand.Intn(30) // 90-120秒
-		} else {
-			return 120 + rand.Intn(60) // 120-180秒
-		}
-	case "减脂":
-		return 30 + rand.Intn(30) // 30-60秒
-	default: // 综合
-		return 60 + rand.Intn(60) // 60-120秒
+// recommendationSeed 读取 seed 查询参数，未传入时生成新种子
+func recommendationSeed(c *gin.Context) (int64, error) {
+	if value := c.Query("seed"); value != "" {
+		return strconv.ParseInt(value, 10, 64)
 	}
+	return recommendation.NewSeed(), nil
 }
 
 // 获取部位名称
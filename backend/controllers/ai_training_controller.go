@@ -2,7 +2,6 @@ package controllers
 
 import (
 	"fmt"
-	"math/rand"
 	"net/http"
 	"strconv"
 	"time"
@@ -10,6 +9,8 @@ import (
 	"github.com/gin-gonic/gin"
 	"gymates-backend/config"
 	"gymates-backend/models"
+	"gymates-backend/services"
+	"gymates-backend/services/recommendation"
 )
 
 // AITrainingController AI训练控制器
@@ -21,7 +22,7 @@ func NewAITrainingController() *AITrainingController {
 }
 
 // GetAIRecommendation 获取AI推荐训练计划
-// GET /api/training/ai/recommend?user_id={uid}
+// GET /api/training/ai/training?user_id={uid}&seed={seed}
 func (aic *AITrainingController) GetAIRecommendation(c *gin.Context) {
 	userIDStr := c.Query("user_id")
 	if userIDStr == "" {
@@ -45,6 +46,17 @@ func (aic *AITrainingController) GetAIRecommendation(c *gin.Context) {
 		return
 	}
 
+	seed, err := recommendationSeed(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Message: "无效的随机种子",
+			Error:   err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
 	// 获取用户训练偏好
 	var preferences models.UserTrainingPreferences
 	if err := config.DB.Where("user_id = ?", uint(userID)).First(&preferences).Error; err != nil {
@@ -71,7 +83,16 @@ func (aic *AITrainingController) GetAIRecommendation(c *gin.Context) {
 	completionRate := aic.calculateCompletionRate(uint(userID))
 
 	// 生成AI推荐
-	recommendation := aic.generateAIRecommendation(preferences, recentHistory, completionRate)
+	recommendation, err := aic.generateAIRecommendation(preferences, recentHistory, completionRate, seed)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Success: false,
+			Message: "生成推荐失败",
+			Error:   err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
 
 	c.JSON(http.StatusOK, models.SuccessResponse{
 		Success: true,
@@ -102,7 +123,7 @@ func (aic *AITrainingController) SaveTrainingPreferences(c *gin.Context) {
 		}
 	}
 
-	// 更新偏好
+	// 更新偏好；该接口不需要登录，这里的体重只保存在偏好中，不写入体重记录
 	preferences.Goal = req.Goal
 	preferences.Frequency = req.Frequency
 	preferences.PreferredParts = req.PreferredParts
@@ -211,21 +232,46 @@ func (aic *AITrainingController) SaveTrainingSession(c *gin.Context) {
 }
 
 // 生成AI推荐训练计划
-func (aic *AITrainingController) generateAIRecommendation(preferences models.UserTrainingPreferences, history []models.UserTrainingHistory, completionRate float64) models.AITrainingRecommendation {
+func (aic *AITrainingController) generateAIRecommendation(preferences models.UserTrainingPreferences, history []models.UserTrainingHistory, completionRate float64, seed int64) (models.AITrainingRecommendation, error) {
+	// 优先安排已恢复且本周训练量不足的肌肉
+	muscleRecovery, _ := loadMuscleRecovery(config.DB, preferences.UserID, time.Now())
+
+	recent := map[uint]bool{}
+	for _, item := range history {
+		recent[item.ExerciseID] = true
+	}
+	request := recommendation.Request{
+		Level:      preferences.Experience,
+		Priorities: services.MusclePriorities(muscleRecovery),
+		Recent:     recent,
+		Injuries:   userInjuryFilter(config.DB, preferences.UserID), // 排除或降权与用户伤病相冲突的动作
+		Prescribe:  recommendation.GoalPrescriber(preferences.Goal, completionRate, preferences.CurrentWeight),
+	}
+	if profile, ok := activeEquipmentProfile(preferences.UserID); ok {
+		request.Equipment = profile.Name
+		request.Filter.Equipment = profile.EquipmentList()
+	}
+
 	// 根据目标确定训练类型
-	var exercises []models.RecommendedExercise
 	var trainingType string
-
 	switch preferences.Goal {
 	case "增肌":
 		trainingType = "力量训练"
-		exercises = aic.generateMuscleBuildingExercises(preferences, completionRate)
+		request.Filter.Levels = []string{"beginner", "intermediate", "advanced"}
+		request.Limit = 8
 	case "减脂":
 		trainingType = "有氧训练"
-		exercises = aic.generateFatLossExercises(preferences, completionRate)
+		request.Filter.Types = []string{"compound", "cardio"}
+		request.Limit = 6
 	default:
 		trainingType = "综合训练"
-		exercises = aic.generateMaintenanceExercises(preferences, completionRate)
+		request.Limit = 7
+	}
+
+	engine := recommendation.NewEngine(recommendation.NewGormStore(config.DB), recommendation.NewRand(seed))
+	selected, err := engine.Recommend(request)
+	if err != nil {
+		return models.AITrainingRecommendation{}, err
 	}
 
 	// 生成训练概览
@@ -241,118 +287,11 @@ func (aic *AITrainingController) generateAIRecommendation(preferences models.Use
 	return models.AITrainingRecommendation{
 		UserID:    preferences.UserID,
 		Overview:  overview,
-		Exercises: exercises,
+		Exercises: selected.Exercises,
 		Generated: time.Now(),
-	}
-}
-
-// 生成增肌训练动作
-func (aic *AITrainingController) generateMuscleBuildingExercises(preferences models.UserTrainingPreferences, completionRate float64) []models.RecommendedExercise {
-	var exercises []models.RecommendedExercise
-	
-	// 根据完成率调整强度
-	intensityMultiplier := 1.0
-	if completionRate > 0.8 {
-		intensityMultiplier = 1.1 // 增加强度
-	} else if completionRate < 0.5 {
-		intensityMultiplier = 0.9 // 降低强度
-	}
-
-	// 获取动作库
-	var exerciseLibrary []models.ExerciseLibrary
-	config.DB.Where("level IN (?)", []string{"beginner", "intermediate", "advanced"}).
-		Order("RANDOM()").
-		Limit(8).
-		Find(&exerciseLibrary)
-
-	for _, exercise := range exerciseLibrary {
-		recommendedExercise := models.RecommendedExercise{
-			Name:        exercise.Name,
-			Sets:        int(float64(3+rand.Intn(2)) * intensityMultiplier), // 3-5组
-			Reps:        8 + rand.Intn(5), // 8-12次
-			Weight:      aic.calculateWeight(exercise.Name, preferences.CurrentWeight),
-			RestSeconds: 90 + rand.Intn(30), // 90-120秒
-			Part:        exercise.Part,
-			Description: exercise.Description,
-			VideoURL:    fmt.Sprintf("https://cdn.gymates.com/videos/%s.mp4", exercise.Name),
-			Notes:       "",
-		}
-		exercises = append(exercises, recommendedExercise)
-	}
-
-	return exercises
-}
-
-// 生成减脂训练动作
-func (aic *AITrainingController) generateFatLossExercises(preferences models.UserTrainingPreferences, completionRate float64) []models.RecommendedExercise {
-	var exercises []models.RecommendedExercise
-
-	// 减脂训练：高次数、短休息
-	var exerciseLibrary []models.ExerciseLibrary
-	config.DB.Where("type IN (?)", []string{"compound", "cardio"}).
-		Order("RANDOM()").
-		Limit(6).
-		Find(&exerciseLibrary)
-
-	for _, exercise := range exerciseLibrary {
-		recommendedExercise := models.RecommendedExercise{
-			Name:        exercise.Name,
-			Sets:        3,
-			Reps:        15 + rand.Intn(10), // 15-25次
-			Weight:      aic.calculateWeight(exercise.Name, preferences.CurrentWeight) * 0.7, // 较轻重量
-			RestSeconds: 30 + rand.Intn(15), // 30-45秒
-			Part:        exercise.Part,
-			Description: exercise.Description,
-			VideoURL:    fmt.Sprintf("https://cdn.gymates.com/videos/%s.mp4", exercise.Name),
-			Notes:       "减脂训练：保持高心率",
-		}
-		exercises = append(exercises, recommendedExercise)
-	}
-
-	return exercises
-}
-
-// 生成维持训练动作
-func (aic *AITrainingController) generateMaintenanceExercises(preferences models.UserTrainingPreferences, completionRate float64) []models.RecommendedExercise {
-	var exercises []models.RecommendedExercise
-
-	var exerciseLibrary []models.ExerciseLibrary
-	config.DB.Order("RANDOM()").Limit(7).Find(&exerciseLibrary)
-
-	for _, exercise := range exerciseLibrary {
-		recommendedExercise := models.RecommendedExercise{
-			Name:        exercise.Name,
-			Sets:        3,
-			Reps:        10 + rand.Intn(5), // 10-15次
-			Weight:      aic.calculateWeight(exercise.Name, preferences.CurrentWeight),
-			RestSeconds: 60 + rand.Intn(30), // 60-90秒
-			Part:        exercise.Part,
-			Description: exercise.Description,
-			VideoURL:    fmt.Sprintf("https://cdn.gymates.com/videos/%s.mp4", exercise.Name),
-			Notes:       "维持训练：保持当前水平",
-		}
-		exercises = append(exercises, recommendedExercise)
-	}
-
-	return exercises
-}
-
-// 计算建议重量
-func (aic *AITrainingController) calculateWeight(exerciseName string, userWeight float64) float64 {
-	// 根据动作类型和用户体重计算建议重量
-	baseWeight := userWeight * 0.6 // 基础重量为体重的60%
-	
-	// 根据动作类型调整
-	switch exerciseName {
-	case "Bench Press", "Squat":
-		return baseWeight * 1.2
-	case "Deadlift":
-		return baseWeight * 1.5
-	case "Pull-up", "Push-up":
-		return 0 // 自重训练
-	default:
-		return baseWeight * 0.8
-	}
+		Restrictions: selected.Restrictions,
+		Seed:         seed,
+	}, nil
 }
 
 // 计算完成率
@@ -378,21 +317,30 @@ func (aic *AITrainingController) getLastTrainingDate(userID uint) *time.Time {
 	return &session.CreatedAt
 }
 
-// 获取周进度
+// 获取周进度（按用户时区，从本周一零点开始统计）
 func (aic *AITrainingController) getWeeklyProgress(userID uint) int {
 	var count int64
-	weekAgo := time.Now().AddDate(0, 0, -7)
-	config.DB.Model(&models.WorkoutSession{}).Where("user_id = ? AND created_at > ?", userID, weekAgo).Count(&count)
+	weekStart := models.StartOfWeek(time.Now(), loadUserLocation(userID))
+	config.DB.Model(&models.WorkoutSession{}).Where("user_id = ? AND created_at >= ?", userID, weekStart).Count(&count)
 	return int(count)
 }
 
 // 生成AI回复
 func (aic *AITrainingController) generateAIResponse(message string, userID uint) string {
+	// 提到身体部位时按伤病规则给出建议，用户已登记该部位伤病时按其严重程度回复
+	if reply, ok := aic.generateInjuryResponse(message, userID); ok {
+		return reply
+	}
+
+	// 问到饮食时结合今日摄入和营养目标回复
+	if reply, ok := aic.generateNutritionResponse(message, userID); ok {
+		return reply
+	}
+
 	// 简单的关键词匹配回复（实际项目中可集成LLM）
 	responses := map[string]string{
 		"呼吸": "训练时要注意呼吸节奏：用力时呼气，放松时吸气。这样可以提供更好的力量输出。",
 		"疼痛": "如果感到疼痛，建议立即停止训练。疼痛是身体的警告信号，不要强行训练。",
-		"肩膀": "肩膀疼痛时避免肩部参与较多的动作，如卧推。可以改为下肢训练或肩部拉伸。",
 		"深蹲": "深蹲时腰疼通常是姿势问题：保持背部挺直，膝盖与脚尖方向一致，重心在脚跟。",
 		"卧推": "卧推时肩胛骨要收紧，保持稳定。下放时控制速度，推起时爆发用力。",
 		"减脂": "减脂需要控制饮食和增加有氧运动。建议力量训练+有氧训练结合。",
@@ -410,6 +358,29 @@ func (aic *AITrainingController) generateAIResponse(message string, userID uint)
 	return "我理解您的问题。建议您根据个人情况调整训练强度，如有不适请咨询专业教练。"
 }
 
+// 根据消息中提到的身体部位生成伤病相关回复
+func (aic *AITrainingController) generateInjuryResponse(message string, userID uint) (string, bool) {
+	severities := map[string]string{}
+	for _, injury := range activeInjuries(config.DB, userID) {
+		severities[injury.BodyRegion] = injury.Severity
+	}
+
+	for _, rule := range models.InjuryRules {
+		for _, keyword := range rule.Keywords {
+			if !contains(message, keyword) {
+				continue
+			}
+			if severity, ok := severities[rule.Region]; ok {
+				return fmt.Sprintf("你登记了%s%s伤病，推荐训练已自动处理：%s如疼痛加重请停止训练并就医。",
+					rule.Name, models.InjurySeverityNames[severity], services.InjuryAdvice(rule, severity)), true
+			}
+			return fmt.Sprintf("%s不适时：%s建议在伤病记录中登记，推荐训练会自动避开相关动作。",
+				rule.Name, services.InjuryAdvice(rule, models.InjurySeverityModerate)), true
+		}
+	}
+	return "", false
+}
+
 // 获取动作对应的肌群
 func (aic *AITrainingController) getMuscleGroupFromExercise(exerciseName string) string {
 	// 简单的动作名称到肌群映射
@@ -433,4 +404,42 @@ func contains(s, substr string) bool {
 		(len(s) > len(substr) && (s[:len(substr)] == substr || 
 		s[len(s)-len(substr):] == substr || 
 		contains(s[1:], substr))))
-}
\ No newline at end of file
+}
+
+// generateNutritionResponse 消息涉及饮食时，按用户今日摄入和营养目标回复
+func (aic *AITrainingController) generateNutritionResponse(message string, userID uint) (string, bool) {
+	asked := false
+	for _, keyword := range []string{"饮食", "吃", "热量", "卡路里", "蛋白质"} {
+		if contains(message, keyword) {
+			asked = true
+			break
+		}
+	}
+	if !asked {
+		return "", false
+	}
+
+	var user models.User
+	if err := config.DB.First(&user, userID).Error; err != nil {
+		return "", false
+	}
+	summary := dailyNutritionSummary(config.DB, &user, models.LocalDate(time.Now(), user.TimeLocation()))
+	if summary.Target == nil {
+		return "完善身高、体重和年龄后，我可以为你计算每日热量和蛋白质目标，再结合饮食记录给出建议。", true
+	}
+
+	reply := fmt.Sprintf("你今天的目标是%.0fkcal、蛋白质%.0fg。", summary.Target.Calories, summary.Target.Protein)
+	if len(summary.Meals) == 0 {
+		return reply + "今天还没有记录饮食，记得记录每一餐，方便我帮你把控摄入。", true
+	}
+	reply += fmt.Sprintf("目前已摄入%.0fkcal、蛋白质%.0fg，", summary.Intake.Calories, summary.Intake.Protein)
+	switch {
+	case summary.Remaining.Calories < 0:
+		reply += fmt.Sprintf("热量已超出%.0fkcal，接下来以蔬菜和优质蛋白为主。", -summary.Remaining.Calories)
+	case summary.Remaining.Protein > 0:
+		reply += fmt.Sprintf("还剩%.0fkcal，蛋白质还差%.0fg，可以安排鸡胸肉、鸡蛋或蛋白粉。", summary.Remaining.Calories, summary.Remaining.Protein)
+	default:
+		reply += fmt.Sprintf("蛋白质已达标，还剩%.0fkcal，按计划吃完剩下的餐次即可。", summary.Remaining.Calories)
+	}
+	return reply, true
+}
@@ -3,12 +3,15 @@ package controllers
 import (
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"golang.org/x/crypto/bcrypt"
 	"gymates-backend/config"
 	"gymates-backend/middleware"
 	"gymates-backend/models"
+	"gymates-backend/services/geo"
+	"gymates-backend/services/matching"
 )
 
 // AuthController 认证控制器
@@ -219,12 +222,51 @@ func (ac *AuthController) UpdateProfile(c *gin.Context) {
 	if req.Weight > 0 {
 		updates["weight"] = req.Weight
 	}
+	if req.Gender != "" {
+		updates["gender"] = req.Gender
+	}
 	if req.Goal != "" {
 		updates["goal"] = req.Goal
 	}
 	if req.Experience != "" {
 		updates["experience"] = req.Experience
 	}
+	if req.Timezone != "" {
+		if !models.IsValidTimezone(req.Timezone) {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Success: false,
+				Message: "无效的时区",
+				Error:   "Invalid timezone: " + req.Timezone,
+				Code:    http.StatusBadRequest,
+			})
+			return
+		}
+		updates["timezone"] = req.Timezone
+	}
+	if (req.Latitude == nil) != (req.Longitude == nil) {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Message: "经纬度需要同时提供",
+			Error:   "latitude and longitude must be set together",
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+	if req.Latitude != nil {
+		// 只保存粗化后的坐标
+		lat, lon, hash := geo.CoarsenUser(*req.Latitude, *req.Longitude)
+		updates["latitude"] = lat
+		updates["longitude"] = lon
+		updates["geohash"] = hash
+	}
+	if req.HomeGym != nil {
+		// 手动填写的健身房会解除绑定，绑定见 GymController.JoinGym
+		updates["home_gym"] = strings.TrimSpace(*req.HomeGym)
+		updates["gym_id"] = nil
+	}
+	if req.TrainingTimes != nil {
+		updates["training_times"] = strings.Join(matching.NormalizeSlots(req.TrainingTimes), ",")
+	}
 
 	if err := config.DB.Model(currentUser).Updates(updates).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
@@ -236,6 +278,19 @@ func (ac *AuthController) UpdateProfile(c *gin.Context) {
 		return
 	}
 
+	// 资料中填写的体重同时记为今天的身体测量
+	if req.Weight > 0 {
+		if err := recordBodyWeight(config.DB, currentUser.ID, req.Weight); err != nil {
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+				Success: false,
+				Message: "记录体重失败",
+				Error:   err.Error(),
+				Code:    http.StatusInternalServerError,
+			})
+			return
+		}
+	}
+
 	// 重新获取用户信息
 	if err := config.DB.First(currentUser, currentUser.ID).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
@@ -288,9 +343,26 @@ func (ac *AuthController) GetUserProfile(c *gin.Context) {
 		return
 	}
 
+	// 有拉黑关系时对方的资料不可见
+	viewer := viewerID(c)
+	if viewer != 0 && viewer != user.ID && isBlockedBetween(viewer, user.ID) {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Success: false,
+			Message: "用户不存在",
+			Error:   "User not found",
+			Code:    http.StatusNotFound,
+		})
+		return
+	}
+
 	// 不返回敏感信息
 	user.Password = ""
 
+	// 按资料可见范围只返回昵称和头像
+	if !visibleTo(loadPrivacySettings(user.ID).ProfileVisibility, user.ID, viewer) {
+		user = restrictProfile(user)
+	}
+
 	c.JSON(http.StatusOK, models.SuccessResponse{
 		Success: true,
 		Message: "获取用户资料成功",
@@ -298,6 +370,82 @@ func (ac *AuthController) GetUserProfile(c *gin.Context) {
 	})
 }
 
+// GetPrivacySettings 获取自己的隐私设置
+func (ac *AuthController) GetPrivacySettings(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Success: false,
+			Message: "用户未认证",
+			Error:   "User not authenticated",
+			Code:    http.StatusUnauthorized,
+		})
+		return
+	}
+
+	currentUser := user.(*models.User)
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "获取隐私设置成功",
+		Data:    loadPrivacySettings(currentUser.ID),
+	})
+}
+
+// UpdatePrivacySettings 更新隐私设置
+func (ac *AuthController) UpdatePrivacySettings(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Success: false,
+			Message: "用户未认证",
+			Error:   "User not authenticated",
+			Code:    http.StatusUnauthorized,
+		})
+		return
+	}
+
+	currentUser := user.(*models.User)
+
+	var req models.UpdatePrivacyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Message: "请求参数错误",
+			Error:   err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	settings := loadPrivacySettings(currentUser.ID)
+	if req.ProfileVisibility != nil {
+		settings.ProfileVisibility = *req.ProfileVisibility
+	}
+	if req.MessagePermission != nil {
+		settings.MessagePermission = *req.MessagePermission
+	}
+	if req.WorkoutVisibility != nil {
+		settings.WorkoutVisibility = *req.WorkoutVisibility
+	}
+
+	if err := config.DB.Save(&settings).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Success: false,
+			Message: "更新隐私设置失败",
+			Error:   err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "更新隐私设置成功",
+		Data:    settings,
+	})
+}
+
 // GetUserStats 获取用户统计
 func (ac *AuthController) GetUserStats(c *gin.Context) {
 	user, exists := c.Get("user")
@@ -316,6 +464,7 @@ func (ac *AuthController) GetUserStats(c *gin.Context) {
 	var stats struct {
 		TotalPosts       int64 `json:"total_posts"`
 		TotalWorkouts    int64 `json:"total_workouts"`
+		TotalCalories    int64 `json:"total_calories"`
 		TotalMates       int64 `json:"total_mates"`
 		TotalAchievements int64 `json:"total_achievements"`
 		TotalLikes       int64 `json:"total_likes"`
@@ -325,11 +474,22 @@ func (ac *AuthController) GetUserStats(c *gin.Context) {
 	// 统计用户帖子数
 	config.DB.Model(&models.Post{}).Where("user_id = ?", currentUser.ID).Count(&stats.TotalPosts)
 
-	// 统计用户训练会话数
+	// 统计用户训练会话数（含有氧运动）
+	var cardioActivities int64
 	config.DB.Model(&models.WorkoutSession{}).Where("user_id = ?", currentUser.ID).Count(&stats.TotalWorkouts)
+	config.DB.Model(&models.CardioActivity{}).Where("user_id = ?", currentUser.ID).Count(&cardioActivities)
+	stats.TotalWorkouts += cardioActivities
+
+	// 统计已完成训练和有氧运动的总消耗
+	var cardioCalories int64
+	config.DB.Model(&models.WorkoutSession{}).Where("user_id = ? AND status = ?", currentUser.ID, "completed").
+		Select("COALESCE(SUM(total_calories), 0)").Scan(&stats.TotalCalories)
+	config.DB.Model(&models.CardioActivity{}).Where("user_id = ?", currentUser.ID).
+		Select("COALESCE(SUM(calories), 0)").Scan(&cardioCalories)
+	stats.TotalCalories += cardioCalories
 
 	// 统计用户搭子数
-	config.DB.Model(&models.Mate{}).Where("user_id = ? AND status = ?", currentUser.ID, "accepted").Count(&stats.TotalMates)
+	stats.TotalMates = countMates(currentUser.ID)
 
 	// 统计用户成就数
 	config.DB.Model(&models.Achievement{}).Where("user_id = ?", currentUser.ID).Count(&stats.TotalAchievements)
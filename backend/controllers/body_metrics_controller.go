@@ -0,0 +1,355 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"gymates-backend/config"
+	"gymates-backend/models"
+	"gymates-backend/services"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// BodyMetricsController 身体指标控制器
+type BodyMetricsController struct{}
+
+// NewBodyMetricsController 创建身体指标控制器
+func NewBodyMetricsController() *BodyMetricsController {
+	return &BodyMetricsController{}
+}
+
+// GetMeasurements 获取日期范围内的身体测量记录
+// GET /api/body/measurements?from=2024-01-01&to=2024-03-31，默认最近90天
+func (bmc *BodyMetricsController) GetMeasurements(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Success: false,
+			Message: "用户未认证",
+			Error:   "User not authenticated",
+			Code:    http.StatusUnauthorized,
+		})
+		return
+	}
+	currentUser := user.(*models.User)
+
+	to, ok := nutritionDate(c, currentUser, c.Query("to"))
+	if !ok {
+		return
+	}
+	from := c.Query("from")
+	if from == "" {
+		end, _ := time.Parse(models.DateLayout, to)
+		from = end.AddDate(0, 0, -89).Format(models.DateLayout)
+	} else if from, ok = nutritionDate(c, currentUser, from); !ok {
+		return
+	}
+
+	measurements := []models.BodyMeasurement{}
+	if err := config.DB.Where("user_id = ? AND date >= ? AND date <= ?", currentUser.ID, from, to).
+		Order("date DESC").Find(&measurements).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Success: false,
+			Message: "获取身体测量记录失败",
+			Error:   err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "获取身体测量记录成功",
+		Data:    measurements,
+	})
+}
+
+// SaveMeasurement 记录身体测量，同一天已有记录时只覆盖本次提交的指标
+// POST /api/body/measurements
+func (bmc *BodyMetricsController) SaveMeasurement(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Success: false,
+			Message: "用户未认证",
+			Error:   "User not authenticated",
+			Code:    http.StatusUnauthorized,
+		})
+		return
+	}
+
+	var req models.SaveBodyMeasurementRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Message: "请求参数错误",
+			Error:   err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+	currentUser := user.(*models.User)
+
+	date, ok := nutritionDate(c, currentUser, req.Date)
+	if !ok {
+		return
+	}
+	if req.Weight == nil && req.BodyFat == nil && req.Chest == nil && req.Waist == nil &&
+		req.Hips == nil && req.Arm == nil && req.Thigh == nil && len(req.PhotoURLs) == 0 {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Message: "至少需要记录一项指标或照片",
+			Error:   "No measurement provided",
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	var measurement models.BodyMeasurement
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ? AND date = ?", currentUser.ID, date).First(&measurement).Error; err != nil {
+			measurement = models.BodyMeasurement{UserID: currentUser.ID, Date: date, PhotoURLs: "[]"}
+		}
+		mergeBodyMeasurement(&measurement, req)
+		if err := tx.Save(&measurement).Error; err != nil {
+			return err
+		}
+		if req.Weight != nil {
+			return syncCurrentWeight(tx, currentUser.ID)
+		}
+		return nil
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Success: false,
+			Message: "保存身体测量记录失败",
+			Error:   err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "保存身体测量记录成功",
+		Data:    measurement,
+	})
+}
+
+// DeleteMeasurement 删除身体测量记录
+// DELETE /api/body/measurements/:id
+func (bmc *BodyMetricsController) DeleteMeasurement(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Success: false,
+			Message: "用户未认证",
+			Error:   "User not authenticated",
+			Code:    http.StatusUnauthorized,
+		})
+		return
+	}
+	currentUser := user.(*models.User)
+
+	measurementID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Message: "无效的测量记录ID",
+			Error:   err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	var measurement models.BodyMeasurement
+	if err := config.DB.Where("id = ? AND user_id = ?", uint(measurementID), currentUser.ID).First(&measurement).Error; err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Success: false,
+			Message: "测量记录不存在或无权限",
+			Error:   "Measurement not found or no permission",
+			Code:    http.StatusNotFound,
+		})
+		return
+	}
+
+	// 硬删除，否则同一天无法再次记录（唯一索引）
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Delete(&measurement).Error; err != nil {
+			return err
+		}
+		if measurement.Weight != nil {
+			return syncCurrentWeight(tx, currentUser.ID)
+		}
+		return nil
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Success: false,
+			Message: "删除测量记录失败",
+			Error:   err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "删除测量记录成功",
+	})
+}
+
+// GetTrend 获取身体指标趋势，体重指标同时返回目标体重预测
+// GET /api/body/trend?metric=weight&days=90&target=65
+func (bmc *BodyMetricsController) GetTrend(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Success: false,
+			Message: "用户未认证",
+			Error:   "User not authenticated",
+			Code:    http.StatusUnauthorized,
+		})
+		return
+	}
+	currentUser := user.(*models.User)
+
+	metric := c.DefaultQuery("metric", models.MetricWeight)
+	name, ok := models.BodyMetricNames[metric]
+	if !ok {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Message: "无效的身体指标",
+			Error:   "Unknown metric: " + metric,
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	days, err := strconv.Atoi(c.DefaultQuery("days", "90"))
+	if err != nil || days < 7 || days > 365 {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Message: "天数应在7到365之间",
+			Error:   "Invalid days: " + c.Query("days"),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	var target float64
+	if raw := c.Query("target"); raw != "" {
+		target, err = strconv.ParseFloat(raw, 64)
+		if err != nil || target <= 0 {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Success: false,
+				Message: "无效的目标值",
+				Error:   "Invalid target: " + raw,
+				Code:    http.StatusBadRequest,
+			})
+			return
+		}
+	} else if metric == models.MetricWeight {
+		var preferences models.UserTrainingPreferences
+		if config.DB.Where("user_id = ?", currentUser.ID).First(&preferences).Error == nil {
+			target = preferences.TargetWeight
+		}
+	}
+
+	now := time.Now()
+	today := models.LocalDate(now, currentUser.TimeLocation())
+	end, _ := time.Parse(models.DateLayout, today)
+	from := end.AddDate(0, 0, -(days - 1)).Format(models.DateLayout)
+
+	var measurements []models.BodyMeasurement
+	config.DB.Where("user_id = ? AND date >= ? AND date <= ?", currentUser.ID, from, today).
+		Order("date").Find(&measurements)
+
+	series := make([]services.DatedValue, 0, len(measurements))
+	for _, measurement := range measurements {
+		if value, ok := measurement.Metric(metric); ok {
+			series = append(series, services.DatedValue{Date: measurement.Date, Value: value})
+		}
+	}
+
+	response := models.BodyTrendResponse{
+		Metric: metric,
+		Name:   name,
+		Points: services.SmoothTrend(series),
+	}
+	if count := len(response.Points); count > 0 {
+		latest := response.Points[count-1]
+		response.Latest = &latest.Value
+		response.Trend = &latest.Smoothed
+		response.RatePerWeek, _ = services.TrendRatePerWeek(response.Points)
+	}
+	if target > 0 {
+		projection := services.ProjectGoal(response.Points, target, end)
+		response.Projection = &projection
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "获取身体指标趋势成功",
+		Data:    response,
+	})
+}
+
+// mergeBodyMeasurement 把请求中提交的指标写入记录，未提交的保持不变
+func mergeBodyMeasurement(measurement *models.BodyMeasurement, req models.SaveBodyMeasurementRequest) {
+	fields := []struct {
+		value  *float64
+		target **float64
+	}{
+		{req.Weight, &measurement.Weight},
+		{req.BodyFat, &measurement.BodyFat},
+		{req.Chest, &measurement.Chest},
+		{req.Waist, &measurement.Waist},
+		{req.Hips, &measurement.Hips},
+		{req.Arm, &measurement.Arm},
+		{req.Thigh, &measurement.Thigh},
+	}
+	for _, field := range fields {
+		if field.value != nil {
+			*field.target = field.value
+		}
+	}
+	if len(req.PhotoURLs) > 0 {
+		measurement.PhotoURLs = models.EncodeStringList(append(measurement.PhotoList(), req.PhotoURLs...))
+	}
+	if req.Notes != "" {
+		measurement.Notes = req.Notes
+	}
+}
+
+// recordBodyWeight 把资料中填写的体重记为今天的测量记录
+func recordBodyWeight(db *gorm.DB, userID uint, weight float64) error {
+	today := models.LocalDate(time.Now(), loadUserLocation(userID))
+	return db.Transaction(func(tx *gorm.DB) error {
+		var measurement models.BodyMeasurement
+		if err := tx.Where("user_id = ? AND date = ?", userID, today).First(&measurement).Error; err != nil {
+			measurement = models.BodyMeasurement{UserID: userID, Date: today, PhotoURLs: "[]"}
+		}
+		measurement.Weight = &weight
+		if err := tx.Save(&measurement).Error; err != nil {
+			return err
+		}
+		return syncCurrentWeight(tx, userID)
+	})
+}
+
+// syncCurrentWeight 用最近一次体重记录同步用户资料和训练偏好中的当前体重
+func syncCurrentWeight(tx *gorm.DB, userID uint) error {
+	var latest models.BodyMeasurement
+	if err := tx.Where("user_id = ? AND weight IS NOT NULL", userID).Order("date DESC").First(&latest).Error; err != nil {
+		return nil
+	}
+	if err := tx.Model(&models.User{}).Where("id = ?", userID).Update("weight", *latest.Weight).Error; err != nil {
+		return err
+	}
+	return tx.Model(&models.UserTrainingPreferences{}).Where("user_id = ?", userID).
+		Update("current_weight", *latest.Weight).Error
+}
@@ -0,0 +1,166 @@
+package controllers
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"gymates-backend/config"
+	"gymates-backend/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestBodyMetrics 测试身体测量记录、趋势接口以及当前体重同步
+func TestBodyMetrics(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	setupTestDB(t)
+
+	user := models.User{Name: "体测用户", Email: "body@gymates.com", Password: "x", Weight: 85, Timezone: "Asia/Shanghai"}
+	require.NoError(t, config.DB.Create(&user).Error)
+	other := models.User{Name: "体测其他用户", Email: "body-other@gymates.com", Password: "x"}
+	require.NoError(t, config.DB.Create(&other).Error)
+	require.NoError(t, config.DB.Create(&models.UserTrainingPreferences{
+		UserID: user.ID, Goal: "减脂", CurrentWeight: 85, TargetWeight: 70,
+	}).Error)
+
+	controller := NewBodyMetricsController()
+	router := gin.New()
+	routes := func(prefix string, u *models.User) {
+		router.GET(prefix+"/measurements", withTestUser(u), controller.GetMeasurements)
+		router.POST(prefix+"/measurements", withTestUser(u), controller.SaveMeasurement)
+		router.DELETE(prefix+"/measurements/:id", withTestUser(u), controller.DeleteMeasurement)
+		router.GET(prefix+"/trend", withTestUser(u), controller.GetTrend)
+	}
+	routes("/api/body", &user)
+	routes("/other/body", &other)
+	router.PUT("/api/auth/profile", withTestUser(&user), NewAuthController().UpdateProfile)
+
+	weight := func(v float64) *float64 { return &v }
+	currentWeights := func() (float64, float64) {
+		var u models.User
+		var preferences models.UserTrainingPreferences
+		require.NoError(t, config.DB.First(&u, user.ID).Error)
+		require.NoError(t, config.DB.Where("user_id = ?", user.ID).First(&preferences).Error)
+		return u.Weight, preferences.CurrentWeight
+	}
+
+	end, _ := time.Parse(models.DateLayout, models.LocalDate(time.Now(), user.TimeLocation()))
+	daysAgo := func(n int) string { return end.AddDate(0, 0, -n).Format(models.DateLayout) }
+
+	t.Run("参数校验", func(t *testing.T) {
+		tests := []struct {
+			name string
+			req  models.SaveBodyMeasurementRequest
+		}{
+			{name: "没有任何指标", req: models.SaveBodyMeasurementRequest{Notes: "只写备注"}},
+			{name: "体脂率超出范围", req: models.SaveBodyMeasurementRequest{BodyFat: weight(120)}},
+			{name: "照片地址无效", req: models.SaveBodyMeasurementRequest{PhotoURLs: []string{"not-a-url"}}},
+			{name: "日期格式错误", req: models.SaveBodyMeasurementRequest{Date: "2025/03/01", Weight: weight(80)}},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				assert.Equal(t, http.StatusBadRequest, sendJSON(t, router, "POST", "/api/body/measurements", tt.req, nil))
+			})
+		}
+	})
+
+	// 两周内每两天称重一次，每次下降0.3kg
+	for i := 0; i <= 7; i++ {
+		require.Equal(t, http.StatusOK, sendJSON(t, router, "POST", "/api/body/measurements", models.SaveBodyMeasurementRequest{
+			Date: daysAgo(14 - 2*i), Weight: weight(84 - 0.3*float64(i)),
+		}, nil))
+	}
+
+	t.Run("最新体重同步到资料和训练偏好", func(t *testing.T) {
+		userWeight, preferenceWeight := currentWeights()
+		assert.InDelta(t, 81.9, userWeight, 0.001)
+		assert.InDelta(t, 81.9, preferenceWeight, 0.001)
+
+		// 补录更早的体重不影响当前体重
+		require.Equal(t, http.StatusOK, sendJSON(t, router, "POST", "/api/body/measurements", models.SaveBodyMeasurementRequest{
+			Date: daysAgo(30), Weight: weight(86),
+		}, nil))
+		userWeight, _ = currentWeights()
+		assert.InDelta(t, 81.9, userWeight, 0.001)
+	})
+
+	t.Run("同一天只覆盖提交的指标", func(t *testing.T) {
+		var measurement models.BodyMeasurement
+		require.Equal(t, http.StatusOK, sendJSON(t, router, "POST", "/api/body/measurements", models.SaveBodyMeasurementRequest{
+			Date: daysAgo(0), Waist: weight(82), PhotoURLs: []string{"https://example.com/front.jpg"},
+		}, &measurement))
+		require.NotNil(t, measurement.Weight)
+		assert.InDelta(t, 81.9, *measurement.Weight, 0.001)
+		assert.Equal(t, 82.0, *measurement.Waist)
+		assert.Equal(t, []string{"https://example.com/front.jpg"}, measurement.PhotoList())
+
+		var measurements []models.BodyMeasurement
+		require.Equal(t, http.StatusOK, sendJSON(t, router, "GET", "/api/body/measurements", nil, &measurements))
+		assert.Len(t, measurements, 9)
+		assert.Equal(t, daysAgo(0), measurements[0].Date)
+	})
+
+	t.Run("体重趋势和目标预测", func(t *testing.T) {
+		var trend models.BodyTrendResponse
+		require.Equal(t, http.StatusOK, sendJSON(t, router, "GET", "/api/body/trend?days=28", nil, &trend))
+		assert.Equal(t, "体重", trend.Name)
+		assert.Len(t, trend.Points, 8) // 30天前的记录不在范围内
+		require.NotNil(t, trend.Latest)
+		assert.InDelta(t, 81.9, *trend.Latest, 0.001)
+		assert.Less(t, trend.RatePerWeek, 0.0)
+		require.NotNil(t, trend.Projection)
+		assert.Equal(t, 70.0, trend.Projection.TargetWeight)
+		assert.Equal(t, models.ProjectionOnTrack, trend.Projection.Status)
+		assert.NotEmpty(t, trend.Projection.ProjectedDate)
+
+		var gain models.BodyTrendResponse
+		require.Equal(t, http.StatusOK, sendJSON(t, router, "GET", "/api/body/trend?days=28&target=90", nil, &gain))
+		assert.Equal(t, models.ProjectionWrongDirection, gain.Projection.Status)
+
+		var waist models.BodyTrendResponse
+		require.Equal(t, http.StatusOK, sendJSON(t, router, "GET", "/api/body/trend?metric=waist", nil, &waist))
+		assert.Len(t, waist.Points, 1)
+		assert.Nil(t, waist.Projection)
+
+		assert.Equal(t, http.StatusBadRequest, sendJSON(t, router, "GET", "/api/body/trend?metric=neck", nil, nil))
+		assert.Equal(t, http.StatusBadRequest, sendJSON(t, router, "GET", "/api/body/trend?days=3", nil, nil))
+		assert.Equal(t, http.StatusBadRequest, sendJSON(t, router, "GET", "/api/body/trend?target=abc", nil, nil))
+	})
+
+	t.Run("删除最新记录后回退当前体重", func(t *testing.T) {
+		var measurements []models.BodyMeasurement
+		require.Equal(t, http.StatusOK, sendJSON(t, router, "GET", "/api/body/measurements", nil, &measurements))
+		latest := measurements[0]
+
+		assert.Equal(t, http.StatusNotFound, sendJSON(t, router, "DELETE", "/other/body/measurements/"+uintToString(latest.ID), nil, nil))
+		require.Equal(t, http.StatusOK, sendJSON(t, router, "DELETE", "/api/body/measurements/"+uintToString(latest.ID), nil, nil))
+		userWeight, preferenceWeight := currentWeights()
+		assert.InDelta(t, 82.2, userWeight, 0.001)
+		assert.InDelta(t, 82.2, preferenceWeight, 0.001)
+	})
+
+	t.Run("更新资料中的体重记为今天的测量", func(t *testing.T) {
+		require.Equal(t, http.StatusOK, sendJSON(t, router, "PUT", "/api/auth/profile", models.UpdateProfileRequest{Weight: 81.5}, nil))
+		var measurements []models.BodyMeasurement
+		require.Equal(t, http.StatusOK, sendJSON(t, router, "GET", "/api/body/measurements?from="+daysAgo(0), nil, &measurements))
+		require.Len(t, measurements, 1)
+		assert.Equal(t, 81.5, *measurements[0].Weight)
+		_, preferenceWeight := currentWeights()
+		assert.Equal(t, 81.5, preferenceWeight)
+	})
+
+	t.Run("不需要登录的训练偏好接口不写入体重记录", func(t *testing.T) {
+		router.POST("/api/training/ai/preferences", NewAITrainingController().SaveTrainingPreferences)
+		require.Equal(t, http.StatusOK, sendJSON(t, router, "POST", "/api/training/ai/preferences", models.SavePreferencesRequest{
+			UserID: user.ID, Goal: "减脂", Frequency: 3, CurrentWeight: 60,
+		}, nil))
+		userWeight, _ := currentWeights()
+		assert.Equal(t, 81.5, userWeight)
+		var count int64
+		config.DB.Model(&models.BodyMeasurement{}).Where("user_id = ? AND weight = ?", user.ID, 60).Count(&count)
+		assert.Zero(t, count)
+	})
+}
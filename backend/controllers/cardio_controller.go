@@ -0,0 +1,522 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"gymates-backend/config"
+	"gymates-backend/models"
+	"gymates-backend/services"
+	"gymates-backend/services/track"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// maxTrackImportSize 轨迹文件大小上限
+const maxTrackImportSize = 20 << 20
+
+// CardioController 有氧运动控制器
+type CardioController struct{}
+
+// NewCardioController 创建有氧运动控制器
+func NewCardioController() *CardioController {
+	return &CardioController{}
+}
+
+// GetActivities 获取有氧运动记录
+// GET /api/cardio/activities?type=run&page=1&limit=20
+func (cc *CardioController) GetActivities(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Success: false,
+			Message: "用户未认证",
+			Error:   "User not authenticated",
+			Code:    http.StatusUnauthorized,
+		})
+		return
+	}
+	currentUser := user.(*models.User)
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	query := config.DB.Model(&models.CardioActivity{}).Where("user_id = ?", currentUser.ID)
+	if activityType := c.Query("type"); activityType != "" {
+		query = query.Where("type = ?", activityType)
+	}
+
+	var total int64
+	query.Count(&total)
+
+	activities := []models.CardioActivity{}
+	if err := query.Order("start_time DESC").Offset((page - 1) * limit).Limit(limit).Find(&activities).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Success: false,
+			Message: "获取有氧运动记录失败",
+			Error:   err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "获取有氧运动记录成功",
+		Data: models.CardioActivitiesResponse{
+			Activities: activities,
+			Pagination: models.Pagination{
+				Page:       page,
+				Limit:      limit,
+				Total:      total,
+				TotalPages: int((total + int64(limit) - 1) / int64(limit)),
+				HasMore:    int64(page*limit) < total,
+			},
+		},
+	})
+}
+
+// CreateActivity 手动记录有氧运动
+// POST /api/cardio/activities
+func (cc *CardioController) CreateActivity(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Success: false,
+			Message: "用户未认证",
+			Error:   "User not authenticated",
+			Code:    http.StatusUnauthorized,
+		})
+		return
+	}
+
+	var req models.CreateCardioActivityRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Message: "请求参数错误",
+			Error:   err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+	currentUser := user.(*models.User)
+
+	activity := models.CardioActivity{
+		UserID:          currentUser.ID,
+		Type:            req.Type,
+		Name:            req.Name,
+		StartTime:       time.Now(),
+		DurationSeconds: req.DurationSeconds,
+		Distance:        req.Distance,
+		AvgHeartRate:    req.AvgHeartRate,
+		MaxHeartRate:    req.MaxHeartRate,
+		ElevationGain:   req.ElevationGain,
+		Source:          models.CardioSourceManual,
+		Notes:           req.Notes,
+	}
+	if req.StartTime != nil {
+		activity.StartTime = *req.StartTime
+	}
+	setActivityPace(&activity)
+	// 没有轨迹时按平均配速估算最佳成绩
+	setBestEfforts(&activity, func(meters float64) (float64, bool) {
+		return services.EvenPaceEffort(float64(activity.DurationSeconds), activity.Distance, meters)
+	})
+	setActivityCalories(&activity, userBodyWeight(config.DB, currentUser.ID), req.Calories)
+
+	if err := config.DB.Create(&activity).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Success: false,
+			Message: "记录有氧运动失败",
+			Error:   err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.SuccessResponse{
+		Success: true,
+		Message: "记录有氧运动成功",
+		Data:    cardioActivityDetail(activity),
+	})
+}
+
+// ImportActivity 导入 GPX/TCX/FIT 轨迹文件
+// POST /api/cardio/activities/import?format=gpx|tcx|fit&type=run&name=
+//
+// 文件通过 multipart 的 file 字段或请求体上传，未指定格式时按扩展名或内容判断；
+// 未指定类型时取文件中的运动类型。同一开始时间的活动不能重复导入。
+func (cc *CardioController) ImportActivity(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Success: false,
+			Message: "用户未认证",
+			Error:   "User not authenticated",
+			Code:    http.StatusUnauthorized,
+		})
+		return
+	}
+	currentUser := user.(*models.User)
+
+	data, format, err := readImportPayload(c, maxTrackImportSize)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Message: "读取轨迹文件失败",
+			Error:   err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	parsed, err := track.Parse(data, format)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Message: "解析轨迹文件失败",
+			Error:   err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	activityType := c.DefaultQuery("type", parsed.Sport)
+	if _, ok := models.CardioTypeNames[activityType]; !ok {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Message: "无法确定运动类型，请指定 type",
+			Error:   "Unknown activity type: " + activityType,
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	var duplicates int64
+	config.DB.Model(&models.CardioActivity{}).
+		Where("user_id = ? AND start_time = ? AND type = ?", currentUser.ID, parsed.StartTime(), activityType).
+		Count(&duplicates)
+	if duplicates > 0 {
+		c.JSON(http.StatusConflict, models.ErrorResponse{
+			Success: false,
+			Message: "该活动已导入",
+			Error:   "Activity already imported",
+			Code:    http.StatusConflict,
+		})
+		return
+	}
+
+	activity, err := activityFromTrack(parsed, activityType, currentUser)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Message: "轨迹过长，无法导入",
+			Error:   err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+	activity.Name = c.DefaultQuery("name", activity.Name)
+	var deviceCalories *int
+	if parsed.Calories > 0 {
+		deviceCalories = &parsed.Calories
+	}
+	setActivityCalories(&activity, userBodyWeight(config.DB, currentUser.ID), deviceCalories)
+
+	if err := config.DB.Create(&activity).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Success: false,
+			Message: "导入有氧运动失败",
+			Error:   err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.SuccessResponse{
+		Success: true,
+		Message: "导入有氧运动成功",
+		Data:    cardioActivityDetail(activity),
+	})
+}
+
+// GetActivity 获取有氧运动详情（含分段和心率区间）
+// GET /api/cardio/activities/:id
+func (cc *CardioController) GetActivity(c *gin.Context) {
+	activity, ok := userCardioActivity(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "获取有氧运动详情成功",
+		Data:    cardioActivityDetail(activity),
+	})
+}
+
+// DeleteActivity 删除有氧运动记录
+// DELETE /api/cardio/activities/:id
+func (cc *CardioController) DeleteActivity(c *gin.Context) {
+	activity, ok := userCardioActivity(c)
+	if !ok {
+		return
+	}
+
+	if err := config.DB.Delete(&activity).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Success: false,
+			Message: "删除有氧运动记录失败",
+			Error:   err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "删除有氧运动记录成功",
+	})
+}
+
+// GetSummary 有氧运动汇总，包括跑步的最佳5公里和10公里成绩
+// GET /api/cardio/summary?type=run
+func (cc *CardioController) GetSummary(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Success: false,
+			Message: "用户未认证",
+			Error:   "User not authenticated",
+			Code:    http.StatusUnauthorized,
+		})
+		return
+	}
+	currentUser := user.(*models.User)
+
+	activityType := c.Query("type")
+	if _, ok := models.CardioTypeNames[activityType]; activityType != "" && !ok {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Message: "无效的运动类型",
+			Error:   "Unknown activity type: " + activityType,
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	query := config.DB.Where("user_id = ?", currentUser.ID)
+	if activityType != "" {
+		query = query.Where("type = ?", activityType)
+	}
+	var activities []models.CardioActivity
+	if err := query.Order("start_time").Find(&activities).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Success: false,
+			Message: "获取有氧运动汇总失败",
+			Error:   err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	summary := models.CardioSummary{Type: activityType, Activities: len(activities)}
+	for _, activity := range activities {
+		summary.TotalDistance += activity.Distance
+		summary.TotalSeconds += activity.DurationSeconds
+		summary.TotalCalories += activity.Calories
+		summary.LongestDistance = math.Max(summary.LongestDistance, activity.Distance)
+	}
+	summary.Best5K = bestEffort(activities, models.Distance5K, func(a models.CardioActivity) *int { return a.Best5K })
+	summary.Best10K = bestEffort(activities, models.Distance10K, func(a models.CardioActivity) *int { return a.Best10K })
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "获取有氧运动汇总成功",
+		Data:    summary,
+	})
+}
+
+// userCardioActivity 获取路径中当前用户的有氧运动记录，失败时已写入响应
+func userCardioActivity(c *gin.Context) (models.CardioActivity, bool) {
+	var activity models.CardioActivity
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Success: false,
+			Message: "用户未认证",
+			Error:   "User not authenticated",
+			Code:    http.StatusUnauthorized,
+		})
+		return activity, false
+	}
+	currentUser := user.(*models.User)
+
+	activityID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Message: "无效的有氧运动ID",
+			Error:   err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return activity, false
+	}
+
+	if err := config.DB.Where("id = ? AND user_id = ?", uint(activityID), currentUser.ID).First(&activity).Error; err != nil {
+		status, message := http.StatusInternalServerError, "获取有氧运动记录失败"
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			status, message = http.StatusNotFound, "有氧运动记录不存在"
+		}
+		c.JSON(status, models.ErrorResponse{
+			Success: false,
+			Message: message,
+			Error:   err.Error(),
+			Code:    status,
+		})
+		return activity, false
+	}
+	return activity, true
+}
+
+// activityFromTrack 由解析后的轨迹计算距离、配速、分段、心率区间和最佳成绩，分段过多时返回错误
+func activityFromTrack(parsed track.Track, activityType string, user *models.User) (models.CardioActivity, error) {
+	activity := models.CardioActivity{
+		UserID:          user.ID,
+		Type:            activityType,
+		Name:            parsed.Name,
+		StartTime:       parsed.StartTime(),
+		DurationSeconds: int(parsed.Duration().Seconds()),
+		Distance:        math.Round(parsed.Distance()*10) / 10,
+		ElevationGain:   parsed.ElevationGain(),
+		Source:          parsed.Format,
+		TrackPoints:     len(parsed.Points),
+	}
+	if activity.Name == "" {
+		activity.Name = models.CardioTypeNames[activityType]
+	}
+	activity.AvgHeartRate, activity.MaxHeartRate = parsed.HeartRate()
+	setActivityPace(&activity)
+
+	splits, err := parsed.Splits(1000)
+	if err != nil {
+		return activity, err
+	}
+	encoded, _ := json.Marshal(splits)
+	activity.Splits = string(encoded)
+	if zones := parsed.HeartRateZones(services.MaxHeartRate(user.Age)); zones != nil {
+		encoded, _ = json.Marshal(zones)
+		activity.HeartRateZones = string(encoded)
+	}
+	setBestEfforts(&activity, parsed.BestEffort)
+	return activity, nil
+}
+
+// setActivityPace 按距离和用时计算平均配速和速度
+func setActivityPace(activity *models.CardioActivity) {
+	activity.AvgPace = services.Pace(float64(activity.DurationSeconds), activity.Distance)
+	activity.AvgSpeed = services.SpeedKmh(float64(activity.DurationSeconds), activity.Distance)
+}
+
+// setBestEfforts 计算跑步的最佳5公里和10公里成绩
+func setBestEfforts(activity *models.CardioActivity, effort func(meters float64) (float64, bool)) {
+	if activity.Type != models.CardioRun {
+		return
+	}
+	if seconds, ok := effort(models.Distance5K); ok {
+		value := int(seconds)
+		activity.Best5K = &value
+	}
+	if seconds, ok := effort(models.Distance10K); ok {
+		value := int(seconds)
+		activity.Best10K = &value
+	}
+}
+
+// setActivityCalories 按 MET 估算消耗，设备提供了消耗时以设备数值为准
+func setActivityCalories(activity *models.CardioActivity, bodyWeight float64, deviceCalories *int) {
+	met := services.CardioMET(activity.Type, activity.AvgSpeed)
+	estimate := services.EstimateSessionEnergy(services.SessionEnergyInput{
+		BodyWeight:  bodyWeight,
+		Minutes:     float64(activity.DurationSeconds) / 60,
+		FallbackMET: met,
+	})
+	activity.EstimatedCalories = estimate.EstimatedCalories
+	activity.Calories = estimate.Calories
+	activity.CaloriesSource = models.CaloriesSourceEstimated
+	if deviceCalories != nil {
+		activity.Calories = *deviceCalories
+		activity.CaloriesSource = models.CaloriesSourceWearable
+	}
+}
+
+// bestEffort 从活动中找出某个距离的最佳成绩
+func bestEffort(activities []models.CardioActivity, meters int, seconds func(models.CardioActivity) *int) *models.BestEffort {
+	var best *models.BestEffort
+	for _, activity := range activities {
+		value := seconds(activity)
+		if value == nil || (best != nil && *value >= best.Seconds) {
+			continue
+		}
+		best = &models.BestEffort{
+			Distance:   meters,
+			Seconds:    *value,
+			Pace:       services.Pace(float64(*value), float64(meters)),
+			ActivityID: activity.ID,
+			StartTime:  activity.StartTime,
+		}
+	}
+	return best
+}
+
+// cardioActivityDetail 附带解码后的分段和心率区间
+func cardioActivityDetail(activity models.CardioActivity) models.CardioActivityDetail {
+	return models.CardioActivityDetail{
+		CardioActivity: activity,
+		SplitList:      activity.SplitList(),
+		ZoneList:       activity.ZoneList(),
+	}
+}
+
+// mergeWorkoutHistory 将按时间倒序的训练会话和有氧运动合并为一个倒序列表
+func mergeWorkoutHistory(sessions []models.WorkoutSession, activities []models.CardioActivity) []models.WorkoutHistoryItem {
+	items := make([]models.WorkoutHistoryItem, 0, len(sessions)+len(activities))
+	i, j := 0, 0
+	for i < len(sessions) || j < len(activities) {
+		if j >= len(activities) || (i < len(sessions) && !sessions[i].CreatedAt.Before(activities[j].StartTime)) {
+			session := sessions[i]
+			items = append(items, models.WorkoutHistoryItem{
+				Kind:     models.HistoryKindSession,
+				Time:     session.CreatedAt,
+				Session:  &session,
+				Calories: session.TotalCalories,
+				Title:    session.TrainingPlan.Name,
+			})
+			i++
+			continue
+		}
+		activity := activities[j]
+		items = append(items, models.WorkoutHistoryItem{
+			Kind:     models.HistoryKindCardio,
+			Time:     activity.StartTime,
+			Activity: &activity,
+			Calories: activity.Calories,
+			Distance: activity.Distance,
+			Title:    activity.Name,
+		})
+		j++
+	}
+	return items
+}
@@ -0,0 +1,213 @@
+package controllers
+
+import (
+	"bytes"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"gymates-backend/config"
+	"gymates-backend/models"
+	"gymates-backend/services/track/tracktest"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var cardioTestStart = time.Date(2024, 5, 1, 6, 0, 0, 0, time.UTC)
+
+// TestCardioActivities 测试有氧运动的记录、导入、汇总以及合并到训练历史
+func TestCardioActivities(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	setupTestDB(t)
+
+	user := models.User{Name: "有氧测试", Email: "cardio@gymates.com", Password: "x", Age: 30, Weight: 70}
+	require.NoError(t, config.DB.Create(&user).Error)
+	other := models.User{Name: "有氧其他用户", Email: "cardio-other@gymates.com", Password: "x"}
+	require.NoError(t, config.DB.Create(&other).Error)
+
+	controller := NewCardioController()
+	router := gin.New()
+	group := router.Group("/api/cardio", withTestUser(&user))
+	group.GET("/activities", controller.GetActivities)
+	group.POST("/activities", controller.CreateActivity)
+	group.POST("/activities/import", controller.ImportActivity)
+	group.GET("/activities/:id", controller.GetActivity)
+	group.DELETE("/activities/:id", controller.DeleteActivity)
+	group.GET("/summary", controller.GetSummary)
+	router.GET("/other/cardio/activities/:id", withTestUser(&other), controller.GetActivity)
+	router.GET("/api/training/history", withTestUser(&user), NewTrainingController().GetWorkoutHistory)
+	router.GET("/api/profile/stats", withTestUser(&user), NewAuthController().GetUserStats)
+
+	upload := func(path, filename string, content []byte, data interface{}) int {
+		var body bytes.Buffer
+		writer := multipart.NewWriter(&body)
+		part, _ := writer.CreateFormFile("file", filename)
+		part.Write(content)
+		writer.Close()
+		req, _ := http.NewRequest("POST", path, &body)
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		decodeData(t, w, data)
+		return w.Code
+	}
+	raw := func(path string, content []byte, data interface{}) int {
+		req, _ := http.NewRequest("POST", path, bytes.NewReader(content))
+		req.Header.Set("Content-Type", "application/octet-stream")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		decodeData(t, w, data)
+		return w.Code
+	}
+
+	var imported models.CardioActivityDetail
+	t.Run("导入TCX", func(t *testing.T) {
+		require.Equal(t, http.StatusCreated, upload("/api/cardio/activities/import", "morning.tcx", []byte(tracktest.IntervalRunTCX(cardioTestStart, 800)), &imported))
+		assert.Equal(t, models.CardioRun, imported.Type)
+		assert.Equal(t, "晨跑间歇", imported.Name)
+		assert.Equal(t, models.CardioSourceTCX, imported.Source)
+		assert.Equal(t, 4000, imported.DurationSeconds)
+		assert.Equal(t, 13000.0, imported.Distance)
+		assert.Equal(t, 308.0, imported.AvgPace)
+		assert.Equal(t, 11.7, imported.AvgSpeed)
+		assert.Equal(t, 401, imported.TrackPoints)
+		require.NotNil(t, imported.Best5K)
+		require.NotNil(t, imported.Best10K)
+		assert.Equal(t, 1250, *imported.Best5K)
+		assert.Equal(t, 2800, *imported.Best10K)
+		assert.Equal(t, 800, imported.Calories)
+		assert.Equal(t, models.CaloriesSourceWearable, imported.CaloriesSource)
+		// MET 11.7 × 70kg × 66.7分钟
+		assert.Equal(t, 910, imported.EstimatedCalories)
+		assert.Len(t, imported.SplitList, 13)
+		require.Len(t, imported.ZoneList, 5)
+		assert.Equal(t, 2000.0, imported.ZoneList[3].Seconds)
+
+		assert.Equal(t, http.StatusConflict, upload("/api/cardio/activities/import", "morning.tcx", []byte(tracktest.IntervalRunTCX(cardioTestStart, 800)), nil))
+	})
+
+	t.Run("分段过多的轨迹返回400", func(t *testing.T) {
+		// 4小时1100公里，速度没有超过上限，但分段数超过上限
+		start := cardioTestStart.AddDate(0, 0, -1)
+		tcx := fmt.Sprintf(`<TrainingCenterDatabase><Activities><Activity Sport="Biking"><Lap><Track>`+
+			`<Trackpoint><Time>%s</Time><DistanceMeters>0</DistanceMeters></Trackpoint>`+
+			`<Trackpoint><Time>%s</Time><DistanceMeters>1100000</DistanceMeters></Trackpoint>`+
+			`</Track></Lap></Activity></Activities></TrainingCenterDatabase>`,
+			start.Format(time.RFC3339), start.Add(4*time.Hour).Format(time.RFC3339))
+		assert.Equal(t, http.StatusBadRequest, upload("/api/cardio/activities/import", "long.tcx", []byte(tcx), nil))
+	})
+
+	t.Run("导入GPX和FIT", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, raw("/api/cardio/activities/import", []byte(tracktest.NorthboundGPX(cardioTestStart.Add(time.Hour), "", 11)), nil))
+
+		var walk models.CardioActivityDetail
+		require.Equal(t, http.StatusCreated, raw("/api/cardio/activities/import?type=run&name=散步", []byte(tracktest.NorthboundGPX(cardioTestStart.Add(time.Hour), "", 11)), &walk))
+		assert.Equal(t, "散步", walk.Name)
+		assert.Equal(t, models.CardioSourceGPX, walk.Source)
+		assert.Equal(t, 1000.8, walk.Distance)
+		assert.Nil(t, walk.Best5K)
+		assert.Equal(t, models.CaloriesSourceEstimated, walk.CaloriesSource)
+		assert.Equal(t, walk.EstimatedCalories, walk.Calories)
+
+		var ride models.CardioActivityDetail
+		require.Equal(t, http.StatusCreated, upload("/api/cardio/activities/import", "ride.fit", tracktest.CyclingFIT(cardioTestStart.AddDate(0, 0, 1), 61), &ride))
+		assert.Equal(t, models.CardioCycle, ride.Type)
+		assert.Equal(t, "骑行", ride.Name)
+		assert.Equal(t, 36.0, ride.AvgSpeed)
+		assert.Equal(t, 321, ride.Calories)
+		assert.Nil(t, ride.Best5K)
+
+		assert.Equal(t, http.StatusBadRequest, raw("/api/cardio/activities/import?format=tcx", []byte("not a track"), nil))
+	})
+
+	var manual models.CardioActivityDetail
+	t.Run("手动记录", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, sendJSON(t, router, "POST", "/api/cardio/activities", gin.H{"type": "ski", "duration_seconds": 600}, nil))
+
+		start := cardioTestStart.AddDate(0, 0, 2)
+		require.Equal(t, http.StatusCreated, sendJSON(t, router, "POST", "/api/cardio/activities", models.CreateCardioActivityRequest{
+			Type: models.CardioRun, StartTime: &start, DurationSeconds: 1500, Distance: 5000, AvgHeartRate: 155,
+		}, &manual))
+		assert.Equal(t, models.CardioSourceManual, manual.Source)
+		assert.Equal(t, 300.0, manual.AvgPace)
+		require.NotNil(t, manual.Best5K)
+		assert.Equal(t, 1500, *manual.Best5K)
+		assert.Nil(t, manual.Best10K)
+		// MET 12 × 70kg × 25分钟
+		assert.Equal(t, 350, manual.Calories)
+		assert.Empty(t, manual.SplitList)
+
+		assert.Equal(t, http.StatusNotFound, sendJSON(t, router, "GET", "/other/cardio/activities/"+uintToString(manual.ID), nil, nil))
+	})
+
+	t.Run("列表和汇总", func(t *testing.T) {
+		var list models.CardioActivitiesResponse
+		require.Equal(t, http.StatusOK, sendJSON(t, router, "GET", "/api/cardio/activities?type=run", nil, &list))
+		require.Len(t, list.Activities, 3)
+		assert.Equal(t, manual.ID, list.Activities[0].ID)
+		assert.Equal(t, int64(3), list.Pagination.Total)
+
+		var summary models.CardioSummary
+		require.Equal(t, http.StatusOK, sendJSON(t, router, "GET", "/api/cardio/summary?type=run", nil, &summary))
+		assert.Equal(t, 3, summary.Activities)
+		assert.Equal(t, 19000.8, summary.TotalDistance)
+		assert.Equal(t, 13000.0, summary.LongestDistance)
+		require.NotNil(t, summary.Best5K)
+		assert.Equal(t, 1250, summary.Best5K.Seconds)
+		assert.Equal(t, 250.0, summary.Best5K.Pace)
+		assert.Equal(t, imported.ID, summary.Best5K.ActivityID)
+		require.NotNil(t, summary.Best10K)
+		assert.Equal(t, 2800, summary.Best10K.Seconds)
+
+		var all models.CardioSummary
+		require.Equal(t, http.StatusOK, sendJSON(t, router, "GET", "/api/cardio/summary", nil, &all))
+		assert.Equal(t, 4, all.Activities)
+		assert.Equal(t, http.StatusBadRequest, sendJSON(t, router, "GET", "/api/cardio/summary?type=ski", nil, nil))
+	})
+
+	t.Run("合并到训练历史和统计", func(t *testing.T) {
+		session := models.WorkoutSession{UserID: user.ID, TrainingPlanID: 1, StartTime: time.Now(), Status: "completed", TotalCalories: 200}
+		require.NoError(t, config.DB.Create(&session).Error)
+
+		var history models.WorkoutSessionsResponse
+		require.Equal(t, http.StatusOK, sendJSON(t, router, "GET", "/api/training/history?page=1&limit=2", nil, &history))
+		assert.Equal(t, int64(5), history.Pagination.Total)
+		require.Len(t, history.Items, 2)
+		assert.Equal(t, models.HistoryKindSession, history.Items[0].Kind)
+		assert.Equal(t, 200, history.Items[0].Calories)
+		assert.Equal(t, models.HistoryKindCardio, history.Items[1].Kind)
+		assert.Equal(t, manual.ID, history.Items[1].Activity.ID)
+		assert.Len(t, history.Sessions, 1)
+
+		require.Equal(t, http.StatusOK, sendJSON(t, router, "GET", "/api/training/history?page=3&limit=2", nil, &history))
+		require.Len(t, history.Items, 1)
+		assert.Equal(t, imported.ID, history.Items[0].Activity.ID)
+		assert.Empty(t, history.Sessions)
+
+		var stats struct {
+			TotalWorkouts int64 `json:"total_workouts"`
+			TotalCalories int   `json:"total_calories"`
+		}
+		require.Equal(t, http.StatusOK, sendJSON(t, router, "GET", "/api/profile/stats", nil, &stats))
+		assert.Equal(t, int64(5), stats.TotalWorkouts)
+		var activities []models.CardioActivity
+		require.NoError(t, config.DB.Where("user_id = ?", user.ID).Find(&activities).Error)
+		expected := 200
+		for _, activity := range activities {
+			expected += activity.Calories
+		}
+		assert.Equal(t, expected, stats.TotalCalories)
+	})
+
+	t.Run("删除", func(t *testing.T) {
+		path := "/api/cardio/activities/" + uintToString(manual.ID)
+		assert.Equal(t, http.StatusOK, sendJSON(t, router, "DELETE", path, nil, nil))
+		assert.Equal(t, http.StatusNotFound, sendJSON(t, router, "GET", path, nil, nil))
+		assert.Equal(t, http.StatusBadRequest, sendJSON(t, router, "GET", "/api/cardio/activities/abc", nil, nil))
+	})
+}
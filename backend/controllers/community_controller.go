@@ -27,7 +27,7 @@ func (cc *CommunityController) GetPosts(c *gin.Context) {
 	var posts []models.Post
 	var total int64
 
-	query := config.DB.Model(&models.Post{}).Where("is_public = ?", true)
+	query := config.DB.Model(&models.Post{}).Where("is_public = ?", true).Scopes(visiblePosts(viewerID(c)))
 
 	// 根据类型过滤
 	if typeFilter != "" {
@@ -83,7 +83,7 @@ func (cc *CommunityController) GetPost(c *gin.Context) {
 	}
 
 	var post models.Post
-	if err := config.DB.Preload("User").First(&post, uint(postID)).Error; err != nil {
+	if err := config.DB.Preload("User").First(&post, uint(postID)).Error; err != nil || !postVisibleTo(post, viewerID(c)) {
 		c.JSON(http.StatusNotFound, models.ErrorResponse{
 			Success: false,
 			Message: "帖子不存在",
@@ -327,15 +327,31 @@ func (cc *CommunityController) GetComments(c *gin.Context) {
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
 
+	// 拉黑或按隐私设置看不到的帖子，评论也看不到
+	viewer := viewerID(c)
+	var post models.Post
+	if err := config.DB.First(&post, uint(postID)).Error; err == nil && !postVisibleTo(post, viewer) {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Success: false,
+			Message: "帖子不存在",
+			Error:   "Post not found",
+			Code:    http.StatusNotFound,
+		})
+		return
+	}
+
 	var comments []models.Comment
 	var total int64
 
+	// 不显示拉黑、静音用户的评论
+	query := config.DB.Model(&models.Comment{}).Where("post_id = ? AND user_id NOT IN ?", uint(postID), hiddenUserIDs(viewer))
+
 	// 获取总数
-	config.DB.Model(&models.Comment{}).Where("post_id = ?", uint(postID)).Count(&total)
+	query.Count(&total)
 
 	// 分页查询
 	offset := (page - 1) * limit
-	if err := config.DB.Where("post_id = ?", uint(postID)).
+	if err := query.
 		Preload("User").
 		Offset(offset).Limit(limit).Order("created_at ASC").Find(&comments).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
@@ -415,6 +431,21 @@ func (cc *CommunityController) CreateComment(c *gin.Context) {
 		return
 	}
 
+	// 和帖子作者之间有拉黑关系时不能评论
+	if isBlockedBetween(currentUser.ID, post.UserID) {
+		writePrivacyError(c, "无法评论该帖子", errUserBlocked)
+		return
+	}
+	if !postVisibleTo(post, currentUser.ID) {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Success: false,
+			Message: "帖子不存在",
+			Error:   "Post not found",
+			Code:    http.StatusNotFound,
+		})
+		return
+	}
+
 	// 创建评论
 	comment := models.Comment{
 		PostID:  uint(postID),
@@ -465,7 +496,8 @@ func (cc *CommunityController) SearchPosts(c *gin.Context) {
 	var total int64
 
 	// 搜索帖子
-	searchQuery := config.DB.Model(&models.Post{}).Where("is_public = ? AND content LIKE ?", true, "%"+query+"%")
+	searchQuery := config.DB.Model(&models.Post{}).Where("is_public = ? AND content LIKE ?", true, "%"+query+"%").
+		Scopes(visiblePosts(viewerID(c)))
 
 	// 获取总数
 	searchQuery.Count(&total)
@@ -0,0 +1,445 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"gymates-backend/config"
+	"gymates-backend/models"
+	"gymates-backend/services"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// EquipmentProfileController 器械配置控制器
+type EquipmentProfileController struct{}
+
+// NewEquipmentProfileController 创建器械配置控制器
+func NewEquipmentProfileController() *EquipmentProfileController {
+	return &EquipmentProfileController{}
+}
+
+// GetProfiles 获取当前用户的器械配置
+// GET /api/training/equipment-profiles
+func (epc *EquipmentProfileController) GetProfiles(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Success: false,
+			Message: "用户未认证",
+			Error:   "User not authenticated",
+			Code:    http.StatusUnauthorized,
+		})
+		return
+	}
+	currentUser := user.(*models.User)
+
+	var profiles []models.EquipmentProfile
+	if err := config.DB.Where("user_id = ?", currentUser.ID).
+		Order("is_active DESC, created_at ASC").Find(&profiles).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Success: false,
+			Message: "获取器械配置失败",
+			Error:   err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "获取器械配置成功",
+		Data:    profiles,
+	})
+}
+
+// CreateProfile 创建器械配置，用户的第一个配置自动生效
+// POST /api/training/equipment-profiles
+func (epc *EquipmentProfileController) CreateProfile(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Success: false,
+			Message: "用户未认证",
+			Error:   "User not authenticated",
+			Code:    http.StatusUnauthorized,
+		})
+		return
+	}
+
+	req, ok := bindEquipmentProfileRequest(c)
+	if !ok {
+		return
+	}
+	currentUser := user.(*models.User)
+
+	profile := models.EquipmentProfile{
+		UserID:    currentUser.ID,
+		Name:      req.Name,
+		Preset:    req.Preset,
+		Equipment: models.EncodeStringList(models.ResolveEquipment(req.Preset, req.Equipment)),
+	}
+
+	var response models.ActivateEquipmentProfileResponse
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&models.EquipmentProfile{}).Where("user_id = ?", currentUser.ID).Count(&count).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(&profile).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			response.Profile = profile
+			response.Substituted = []models.PlanExerciseSubstitution{}
+			response.Unavailable = []models.PlanExerciseSubstitution{}
+			return nil
+		}
+
+		var err error
+		response, err = activateEquipmentProfile(tx, currentUser.ID, profile)
+		return err
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Success: false,
+			Message: "创建器械配置失败",
+			Error:   err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.SuccessResponse{
+		Success: true,
+		Message: "创建器械配置成功",
+		Data:    response,
+	})
+}
+
+// UpdateProfile 更新器械配置，若为生效中的配置会重新检查计划动作
+// PUT /api/training/equipment-profiles/:id
+func (epc *EquipmentProfileController) UpdateProfile(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Success: false,
+			Message: "用户未认证",
+			Error:   "User not authenticated",
+			Code:    http.StatusUnauthorized,
+		})
+		return
+	}
+	currentUser := user.(*models.User)
+
+	profile, ok := findOwnedEquipmentProfile(c, currentUser.ID)
+	if !ok {
+		return
+	}
+	req, ok := bindEquipmentProfileRequest(c)
+	if !ok {
+		return
+	}
+
+	var response models.ActivateEquipmentProfileResponse
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&profile).Updates(map[string]interface{}{
+			"name":      req.Name,
+			"preset":    req.Preset,
+			"equipment": models.EncodeStringList(models.ResolveEquipment(req.Preset, req.Equipment)),
+		}).Error; err != nil {
+			return err
+		}
+		if err := tx.First(&profile, profile.ID).Error; err != nil {
+			return err
+		}
+		if !profile.IsActive {
+			response.Profile = profile
+			response.Substituted = []models.PlanExerciseSubstitution{}
+			response.Unavailable = []models.PlanExerciseSubstitution{}
+			return nil
+		}
+
+		var err error
+		response, err = activateEquipmentProfile(tx, currentUser.ID, profile)
+		return err
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Success: false,
+			Message: "更新器械配置失败",
+			Error:   err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "更新器械配置成功",
+		Data:    response,
+	})
+}
+
+// DeleteProfile 删除器械配置
+// DELETE /api/training/equipment-profiles/:id
+func (epc *EquipmentProfileController) DeleteProfile(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Success: false,
+			Message: "用户未认证",
+			Error:   "User not authenticated",
+			Code:    http.StatusUnauthorized,
+		})
+		return
+	}
+	currentUser := user.(*models.User)
+
+	profile, ok := findOwnedEquipmentProfile(c, currentUser.ID)
+	if !ok {
+		return
+	}
+
+	if err := config.DB.Delete(&profile).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Success: false,
+			Message: "删除器械配置失败",
+			Error:   err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "删除器械配置成功",
+	})
+}
+
+// ActivateProfile 切换到指定的器械配置，并将计划中不可用器械的动作替换为可用的相似动作
+// POST /api/training/equipment-profiles/:id/activate
+func (epc *EquipmentProfileController) ActivateProfile(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Success: false,
+			Message: "用户未认证",
+			Error:   "User not authenticated",
+			Code:    http.StatusUnauthorized,
+		})
+		return
+	}
+	currentUser := user.(*models.User)
+
+	profile, ok := findOwnedEquipmentProfile(c, currentUser.ID)
+	if !ok {
+		return
+	}
+
+	var response models.ActivateEquipmentProfileResponse
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		response, err = activateEquipmentProfile(tx, currentUser.ID, profile)
+		return err
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Success: false,
+			Message: "切换器械配置失败",
+			Error:   err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "切换器械配置成功",
+		Data:    response,
+	})
+}
+
+// bindEquipmentProfileRequest 解析并校验器械配置请求，失败时已写入响应
+func bindEquipmentProfileRequest(c *gin.Context) (models.SaveEquipmentProfileRequest, bool) {
+	var req models.SaveEquipmentProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Message: "请求参数错误",
+			Error:   err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return req, false
+	}
+
+	var err error
+	if req.Preset == models.EquipmentPresetCustom && len(req.Equipment) == 0 {
+		err = errors.New("equipment is required for custom preset")
+	}
+	for _, equipment := range req.Equipment {
+		if !models.IsKnownEquipment(equipment) {
+			err = fmt.Errorf("unknown equipment: %s", equipment)
+			break
+		}
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Message: "器械列表无效",
+			Error:   err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return req, false
+	}
+	return req, true
+}
+
+// findOwnedEquipmentProfile 查找当前用户的器械配置，失败时已写入响应
+func findOwnedEquipmentProfile(c *gin.Context, userID uint) (models.EquipmentProfile, bool) {
+	var profile models.EquipmentProfile
+	profileID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Message: "无效的器械配置ID",
+			Error:   err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return profile, false
+	}
+
+	if err := config.DB.Where("id = ? AND user_id = ?", uint(profileID), userID).First(&profile).Error; err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Success: false,
+			Message: "器械配置不存在或无权限",
+			Error:   "Equipment profile not found or no permission",
+			Code:    http.StatusNotFound,
+		})
+		return profile, false
+	}
+	return profile, true
+}
+
+// activeEquipmentProfile 获取用户生效中的器械配置，没有配置时返回 false
+func activeEquipmentProfile(userID uint) (models.EquipmentProfile, bool) {
+	var profile models.EquipmentProfile
+	if err := config.DB.Where("user_id = ? AND is_active = ?", userID, true).First(&profile).Error; err != nil {
+		return profile, false
+	}
+	return profile, true
+}
+
+// activateEquipmentProfile 将配置设为生效，并替换用户生效中的计划里不可用器械的动作
+func activateEquipmentProfile(tx *gorm.DB, userID uint, profile models.EquipmentProfile) (models.ActivateEquipmentProfileResponse, error) {
+	response := models.ActivateEquipmentProfileResponse{
+		Substituted: []models.PlanExerciseSubstitution{},
+		Unavailable: []models.PlanExerciseSubstitution{},
+	}
+
+	if err := tx.Model(&models.EquipmentProfile{}).
+		Where("user_id = ? AND id <> ?", userID, profile.ID).
+		Update("is_active", false).Error; err != nil {
+		return response, err
+	}
+	if err := tx.Model(&profile).Update("is_active", true).Error; err != nil {
+		return response, err
+	}
+	profile.IsActive = true
+	response.Profile = profile
+
+	library, err := newExerciseLibraryResolver(tx)
+	if err != nil {
+		return response, err
+	}
+	injuries := userInjuryFilter(tx, userID)
+
+	var plans []models.WeeklyTrainingPlan
+	if err := tx.Where("user_id = ? AND is_active = ?", userID, true).
+		Preload("Days.Parts.Exercises").Find(&plans).Error; err != nil {
+		return response, err
+	}
+
+	for _, plan := range plans {
+		changed := false
+		for _, day := range plan.Days {
+			for _, part := range day.Parts {
+				for _, exercise := range part.Exercises {
+					if exercise.ExerciseLibraryID == nil {
+						continue
+					}
+					current, ok := library.entries[*exercise.ExerciseLibraryID]
+					if !ok || profile.Allows(current.Equipment) {
+						continue
+					}
+
+					result := models.PlanExerciseSubstitution{
+						PlanID:        plan.ID,
+						ExerciseID:    exercise.ID,
+						FromLibraryID: current.ID,
+						From:          current.Name,
+					}
+					substitute, ok, err := bestSubstitute(tx, library, current.ID, profile, injuries)
+					if err != nil {
+						return response, err
+					}
+					if !ok {
+						result.Reason = fmt.Sprintf("没有适合%s的替换动作", profile.Name)
+						response.Unavailable = append(response.Unavailable, result)
+						continue
+					}
+
+					if !changed {
+						if err := ensureBaselineRevision(tx, plan.ID, userID); err != nil {
+							return response, err
+						}
+					}
+					if err := tx.Model(&exercise).Updates(map[string]interface{}{
+						"exercise_library_id": substitute.SubstituteID,
+						"name":                substitute.Substitute.Name,
+						"equipment":           substitute.Substitute.Equipment,
+						"description":         substitute.Substitute.Description,
+						"instructions":        substitute.Substitute.Instructions,
+					}).Error; err != nil {
+						return response, err
+					}
+					result.ToLibraryID = substitute.SubstituteID
+					result.To = substitute.Substitute.Name
+					result.Similarity = substitute.Similarity
+					response.Substituted = append(response.Substituted, result)
+					changed = true
+				}
+			}
+		}
+
+		if changed {
+			if err := savePlanRevision(tx, plan.ID, userID, "equipment profile: "+profile.Name); err != nil {
+				return response, err
+			}
+		}
+	}
+	return response, nil
+}
+
+// bestSubstitute 在替换关系中找相似度最高、器械可用且不与伤病冲突的动作
+func bestSubstitute(tx *gorm.DB, library *exerciseLibraryResolver, exerciseID uint, profile models.EquipmentProfile, injuries *services.InjuryFilter) (models.ExerciseSubstitution, bool, error) {
+	var substitutions []models.ExerciseSubstitution
+	if err := tx.Where("exercise_id = ?", exerciseID).
+		Order("similarity DESC, substitute_id ASC").Find(&substitutions).Error; err != nil {
+		return models.ExerciseSubstitution{}, false, err
+	}
+	for _, substitution := range substitutions {
+		entry, ok := library.entries[substitution.SubstituteID]
+		if !ok || !profile.Allows(entry.Equipment) {
+			continue
+		}
+		if restriction, restricted := injuries.Assess(entry); !restricted || restriction.Action != models.RestrictionExclude {
+			substitution.Substitute = entry
+			return substitution, true, nil
+		}
+	}
+	return models.ExerciseSubstitution{}, false, nil
+}
@@ -0,0 +1,166 @@
+package controllers
+
+import (
+	"net/http"
+	"testing"
+
+	"gymates-backend/config"
+	"gymates-backend/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestEquipmentProfile 测试器械配置、按器械过滤推荐以及切换配置时自动替换计划动作
+func TestEquipmentProfile(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	setupTestDB(t)
+
+	var user models.User
+	require.NoError(t, config.DB.First(&user, 1).Error)
+
+	controller := NewEquipmentProfileController()
+	weeklyController := NewWeeklyTrainingPlanController()
+	router := gin.New()
+	router.POST("/api/training/weekly-plans", withTestUser(&user), weeklyController.CreateWeeklyTrainingPlan)
+	router.GET("/api/training/equipment-profiles", withTestUser(&user), controller.GetProfiles)
+	router.POST("/api/training/equipment-profiles", withTestUser(&user), controller.CreateProfile)
+	router.PUT("/api/training/equipment-profiles/:id", withTestUser(&user), controller.UpdateProfile)
+	router.POST("/api/training/equipment-profiles/:id/activate", withTestUser(&user), controller.ActivateProfile)
+	router.GET("/api/training/ai/recommend", NewAIRecommendationController().GetAIRecommendation)
+	router.GET("/api/training/ai/training", NewAITrainingController().GetAIRecommendation)
+
+	var created struct {
+		Plan models.WeeklyTrainingPlan `json:"plan"`
+	}
+	require.Equal(t, http.StatusCreated, sendJSON(t, router, "POST", "/api/training/weekly-plans", models.CreateWeeklyTrainingPlanRequest{
+		Name: "全身力量",
+		Days: []models.CreateTrainingDayRequest{{
+			DayOfWeek: 1,
+			DayName:   "Monday",
+			Parts: []models.CreateTrainingPartRequest{{
+				MuscleGroup:     "full_body",
+				MuscleGroupName: "全身",
+				Exercises:       groupExercises("深蹲", "平板卧推", "硬拉", "俯卧撑"),
+			}},
+		}},
+	}, &created))
+	planID := created.Plan.ID
+
+	library := map[uint]models.ExerciseLibrary{}
+	var entries []models.ExerciseLibrary
+	require.NoError(t, config.DB.Find(&entries).Error)
+	for _, entry := range entries {
+		library[entry.ID] = entry
+	}
+
+	planSubstitutions := func(items []models.PlanExerciseSubstitution) map[string]models.PlanExerciseSubstitution {
+		result := map[string]models.PlanExerciseSubstitution{}
+		for _, item := range items {
+			if item.PlanID == planID {
+				result[item.From] = item
+			}
+		}
+		return result
+	}
+
+	t.Run("参数校验", func(t *testing.T) {
+		tests := []struct {
+			name    string
+			request models.SaveEquipmentProfileRequest
+		}{
+			{name: "未知预设", request: models.SaveEquipmentProfileRequest{Name: "x", Preset: "garage"}},
+			{name: "自定义配置缺少器械", request: models.SaveEquipmentProfileRequest{Name: "x", Preset: models.EquipmentPresetCustom}},
+			{name: "未知器械", request: models.SaveEquipmentProfileRequest{Name: "x", Preset: models.EquipmentPresetCustom, Equipment: []string{"rowing_machine"}}},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				assert.Equal(t, http.StatusBadRequest, sendJSON(t, router, "POST", "/api/training/equipment-profiles", tt.request, nil))
+			})
+		}
+	})
+
+	var hotel models.ActivateEquipmentProfileResponse
+	t.Run("第一个配置自动生效并替换动作", func(t *testing.T) {
+		require.Equal(t, http.StatusCreated, sendJSON(t, router, "POST", "/api/training/equipment-profiles", models.SaveEquipmentProfileRequest{
+			Name: "酒店", Preset: models.EquipmentPresetHotel,
+		}, &hotel))
+		assert.True(t, hotel.Profile.IsActive)
+
+		substituted := planSubstitutions(hotel.Substituted)
+		require.Contains(t, substituted, "平板卧推")
+		assert.Equal(t, "哑铃卧推", substituted["平板卧推"].To)
+		require.Contains(t, substituted, "深蹲")
+		assert.True(t, hotel.Profile.Allows(library[substituted["深蹲"].ToLibraryID].Equipment))
+		assert.NotContains(t, substituted, "俯卧撑")
+
+		// 硬拉的替换动作都需要杠铃或壶铃，保留原动作
+		unavailable := planSubstitutions(hotel.Unavailable)
+		require.Contains(t, unavailable, "硬拉")
+		assert.NotEmpty(t, unavailable["硬拉"].Reason)
+
+		var exercises []models.Exercise
+		require.NoError(t, config.DB.Where("training_plan_id = ?", planID).Find(&exercises).Error)
+		names := []string{}
+		for _, exercise := range exercises {
+			names = append(names, exercise.Name)
+		}
+		assert.ElementsMatch(t, []string{substituted["深蹲"].To, "哑铃卧推", "硬拉", "俯卧撑"}, names)
+
+		var revision models.WeeklyTrainingPlanRevision
+		require.NoError(t, config.DB.Where("weekly_training_plan_id = ?", planID).Order("version DESC").First(&revision).Error)
+		assert.Equal(t, "equipment profile: 酒店", revision.Summary)
+	})
+
+	t.Run("推荐只包含可用器械的动作", func(t *testing.T) {
+		var recommendation models.AIRecommendationResponse
+		require.Equal(t, http.StatusOK, sendJSON(t, router, "GET", "/api/training/ai/recommend?user_id=1&day=Monday", nil, &recommendation))
+		assert.Equal(t, "酒店", recommendation.EquipmentProfile)
+		for _, part := range recommendation.Parts {
+			for _, exercise := range part.Exercises {
+				assert.True(t, hotel.Profile.Allows(library[exercise.ExerciseID].Equipment), exercise.Name)
+			}
+		}
+
+		var training models.AITrainingRecommendation
+		require.Equal(t, http.StatusOK, sendJSON(t, router, "GET", "/api/training/ai/training?user_id=1", nil, &training))
+		require.NotEmpty(t, training.Exercises)
+		for _, exercise := range training.Exercises {
+			assert.True(t, hotel.Profile.Allows(library[exercise.ExerciseID].Equipment), exercise.Name)
+		}
+	})
+
+	t.Run("新建配置不自动生效，切换后按新器械替换", func(t *testing.T) {
+		var home models.ActivateEquipmentProfileResponse
+		require.Equal(t, http.StatusCreated, sendJSON(t, router, "POST", "/api/training/equipment-profiles", models.SaveEquipmentProfileRequest{
+			Name: "家里", Preset: models.EquipmentPresetCustom, Equipment: []string{models.EquipmentKettlebell},
+		}, &home))
+		assert.False(t, home.Profile.IsActive)
+		assert.ElementsMatch(t, []string{models.EquipmentBodyweight, models.EquipmentKettlebell}, home.Profile.EquipmentList())
+
+		var activated models.ActivateEquipmentProfileResponse
+		require.Equal(t, http.StatusOK, sendJSON(t, router, "POST", "/api/training/equipment-profiles/"+uintToString(home.Profile.ID)+"/activate", nil, &activated))
+		substituted := planSubstitutions(activated.Substituted)
+		require.Contains(t, substituted, "硬拉")
+		assert.Equal(t, "壶铃摆荡", substituted["硬拉"].To)
+		require.Contains(t, substituted, "哑铃卧推")
+		assert.Equal(t, models.EquipmentBodyweight, library[substituted["哑铃卧推"].ToLibraryID].Equipment)
+
+		var profiles []models.EquipmentProfile
+		require.Equal(t, http.StatusOK, sendJSON(t, router, "GET", "/api/training/equipment-profiles", nil, &profiles))
+		require.Len(t, profiles, 2)
+		assert.Equal(t, home.Profile.ID, profiles[0].ID)
+		assert.True(t, profiles[0].IsActive)
+		assert.False(t, profiles[1].IsActive)
+	})
+
+	t.Run("更新非生效配置不替换动作", func(t *testing.T) {
+		var updated models.ActivateEquipmentProfileResponse
+		require.Equal(t, http.StatusOK, sendJSON(t, router, "PUT", "/api/training/equipment-profiles/"+uintToString(hotel.Profile.ID), models.SaveEquipmentProfileRequest{
+			Name: "商业健身房", Preset: models.EquipmentPresetFullGym,
+		}, &updated))
+		assert.Equal(t, "商业健身房", updated.Profile.Name)
+		assert.Empty(t, updated.Substituted)
+	})
+}
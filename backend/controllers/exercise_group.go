@@ -0,0 +1,78 @@
+package controllers
+
+import (
+	"gorm.io/gorm"
+	"gymates-backend/models"
+)
+
+// validateExerciseGroups 校验训练部位下的所有动作组
+func validateExerciseGroups(groups []models.CreateExerciseGroupRequest) error {
+	for _, group := range groups {
+		if err := group.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// createExerciseGroups 创建训练部位下的动作组及组内动作
+func createExerciseGroups(tx *gorm.DB, library *exerciseLibraryResolver, planID, partID uint, groups []models.CreateExerciseGroupRequest) error {
+	for _, groupReq := range groups {
+		rounds := groupReq.Rounds
+		if rounds == 0 && groupReq.BlockType != models.BlockTypeAMRAP {
+			rounds = 1
+		}
+		timeCap := groupReq.TimeCapSeconds
+		if timeCap == 0 && groupReq.BlockType == models.BlockTypeEMOM {
+			timeCap = rounds * groupReq.WorkSeconds
+		}
+
+		group := models.ExerciseGroup{
+			TrainingPartID:   partID,
+			BlockType:        groupReq.BlockType,
+			Name:             groupReq.Name,
+			Rounds:           rounds,
+			WorkSeconds:      groupReq.WorkSeconds,
+			RestSeconds:      groupReq.RestSeconds,
+			RoundRestSeconds: groupReq.RoundRestSeconds,
+			TimeCapSeconds:   timeCap,
+			Order:            groupReq.Order,
+		}
+		if err := tx.Create(&group).Error; err != nil {
+			return err
+		}
+		// AMRAP 的轮数由实际完成情况决定，gorm 默认值会写入1
+		if rounds == 0 {
+			if err := tx.Model(&group).Update("rounds", 0).Error; err != nil {
+				return err
+			}
+		}
+
+		for _, exerciseReq := range groupReq.Exercises {
+			exercise := models.Exercise{
+				TrainingPlanID:  planID,
+				TrainingPartID:  &partID,
+				ExerciseGroupID: &group.ID,
+				Name:            exerciseReq.Name,
+				Description:     exerciseReq.Description,
+				MuscleGroup:     exerciseReq.MuscleGroup,
+				Sets:            exerciseReq.Sets,
+				Reps:            exerciseReq.Reps,
+				Weight:          exerciseReq.Weight,
+				Duration:        exerciseReq.Duration,
+				RestTime:        exerciseReq.RestTime,
+				RestSeconds:     exerciseReq.RestSeconds,
+				Instructions:    exerciseReq.Instructions,
+				ImageURL:        exerciseReq.ImageURL,
+				VideoURL:        exerciseReq.VideoURL,
+				Notes:           exerciseReq.Notes,
+				Order:           exerciseReq.Order,
+			}
+			library.apply(&exercise, exerciseReq.ExerciseLibraryID)
+			if err := tx.Create(&exercise).Error; err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
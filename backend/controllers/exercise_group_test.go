@@ -0,0 +1,252 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"gymates-backend/config"
+	"gymates-backend/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func groupExercises(names ...string) []models.CreateExerciseRequest {
+	exercises := make([]models.CreateExerciseRequest, 0, len(names))
+	for i, name := range names {
+		exercises = append(exercises, models.CreateExerciseRequest{Name: name, Sets: 1, Reps: 10, Order: i + 1})
+	}
+	return exercises
+}
+
+// TestValidateExerciseGroup 测试动作组校验
+func TestValidateExerciseGroup(t *testing.T) {
+	tests := []struct {
+		name    string
+		group   models.CreateExerciseGroupRequest
+		wantErr bool
+	}{
+		{
+			name:  "超级组两个动作",
+			group: models.CreateExerciseGroupRequest{BlockType: models.BlockTypeSuperset, Rounds: 3, Exercises: groupExercises("卧推", "划船")},
+		},
+		{
+			name:    "超级组三个动作",
+			group:   models.CreateExerciseGroupRequest{BlockType: models.BlockTypeSuperset, Exercises: groupExercises("卧推", "划船", "深蹲")},
+			wantErr: true,
+		},
+		{
+			name:    "巨型组动作不足",
+			group:   models.CreateExerciseGroupRequest{BlockType: models.BlockTypeGiantSet, Exercises: groupExercises("卧推", "划船")},
+			wantErr: true,
+		},
+		{
+			name:  "EMOM",
+			group: models.CreateExerciseGroupRequest{BlockType: models.BlockTypeEMOM, Rounds: 10, WorkSeconds: 60, Exercises: groupExercises("壶铃摆荡")},
+		},
+		{
+			name:    "EMOM缺少间隔",
+			group:   models.CreateExerciseGroupRequest{BlockType: models.BlockTypeEMOM, Rounds: 10, Exercises: groupExercises("壶铃摆荡")},
+			wantErr: true,
+		},
+		{
+			name:    "AMRAP缺少时间上限",
+			group:   models.CreateExerciseGroupRequest{BlockType: models.BlockTypeAMRAP, Exercises: groupExercises("波比跳")},
+			wantErr: true,
+		},
+		{
+			name:    "未知类型",
+			group:   models.CreateExerciseGroupRequest{BlockType: "tabata", Exercises: groupExercises("波比跳")},
+			wantErr: true,
+		},
+		{
+			name:    "负数轮次",
+			group:   models.CreateExerciseGroupRequest{BlockType: models.BlockTypeCircuit, Rounds: -1, Exercises: groupExercises("卧推", "划船")},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.group.Validate()
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+// TestExerciseGroupsInPlan 测试创建带动作组的计划、今日训练输出及组记录
+func TestExerciseGroupsInPlan(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	setupTestDB(t)
+
+	var user models.User
+	require.NoError(t, config.DB.First(&user, 3).Error)
+	today := models.ISOWeekday(time.Now().In(user.TimeLocation()))
+
+	controller := NewWeeklyTrainingPlanController()
+	trainingController := NewTrainingController()
+	router := gin.New()
+	router.POST("/api/training/weekly-plans", withTestUser(&user), controller.CreateWeeklyTrainingPlan)
+	router.GET("/api/training/today", withTestUser(&user), controller.GetTodayTraining)
+	router.POST("/api/training/sessions/:id/sets", withTestUser(&user), trainingController.LogWorkoutSet)
+
+	request := models.CreateWeeklyTrainingPlanRequest{
+		Name: "混合训练",
+		Days: []models.CreateTrainingDayRequest{
+			{
+				DayOfWeek: today,
+				DayName:   "Today",
+				Parts: []models.CreateTrainingPartRequest{
+					{
+						MuscleGroup:     "full_body",
+						MuscleGroupName: "全身",
+						Exercises:       groupExercises("深蹲"),
+						Groups: []models.CreateExerciseGroupRequest{
+							{BlockType: models.BlockTypeSuperset, Rounds: 3, RoundRestSeconds: 90, Exercises: groupExercises("卧推", "划船"), Order: 1},
+							{BlockType: models.BlockTypeAMRAP, TimeCapSeconds: 600, Exercises: groupExercises("波比跳", "引体向上"), Order: 2},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	t.Run("无效动作组返回400", func(t *testing.T) {
+		invalid := request
+		invalid.Days = []models.CreateTrainingDayRequest{request.Days[0]}
+		invalid.Days[0].Parts = []models.CreateTrainingPartRequest{request.Days[0].Parts[0]}
+		invalid.Days[0].Parts[0].Groups = []models.CreateExerciseGroupRequest{{BlockType: models.BlockTypeSuperset, Exercises: groupExercises("卧推")}}
+
+		body, _ := json.Marshal(invalid)
+		req, _ := http.NewRequest("POST", "/api/training/weekly-plans", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	body, _ := json.Marshal(request)
+	req, _ := http.NewRequest("POST", "/api/training/weekly-plans", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	var day models.TrainingDay
+	t.Run("今日训练包含动作组", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/api/training/today", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var response struct {
+			Data models.TrainingDay `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		day = response.Data
+		require.Len(t, day.Parts, 1)
+		require.Len(t, day.Parts[0].Groups, 2)
+		assert.Len(t, day.Parts[0].Exercises, 5)
+
+		groups := map[string]models.ExerciseGroup{}
+		for _, group := range day.Parts[0].Groups {
+			groups[group.BlockType] = group
+		}
+		assert.Equal(t, 3, groups[models.BlockTypeSuperset].Rounds)
+		assert.Len(t, groups[models.BlockTypeSuperset].Exercises, 2)
+		assert.Equal(t, 0, groups[models.BlockTypeAMRAP].Rounds)
+		assert.Equal(t, 600, groups[models.BlockTypeAMRAP].TimeCapSeconds)
+	})
+
+	t.Run("按轮次记录组", func(t *testing.T) {
+		require.NotEmpty(t, day.Parts)
+		session := models.WorkoutSession{UserID: user.ID, TrainingPlanID: 1, StartTime: time.Now(), Status: "ongoing"}
+		require.NoError(t, config.DB.Create(&session).Error)
+
+		exerciseIDs := map[string]uint{}
+		for _, exercise := range day.Parts[0].Exercises {
+			exerciseIDs[exercise.Name] = exercise.ID
+		}
+
+		logSet := func(set models.LogWorkoutSetRequest) int {
+			body, _ := json.Marshal(set)
+			req, _ := http.NewRequest("POST", "/api/training/sessions/"+uintToString(session.ID)+"/sets", bytes.NewBuffer(body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			return w.Code
+		}
+
+		// 创建时使用的别名“卧推”已统一为动作库标准名称
+		assert.Equal(t, http.StatusCreated, logSet(models.LogWorkoutSetRequest{ExerciseID: exerciseIDs["平板卧推"], Round: 3, Reps: 10, Weight: 60}))
+		// 超级组只有3轮
+		assert.Equal(t, http.StatusBadRequest, logSet(models.LogWorkoutSetRequest{ExerciseID: exerciseIDs["平板卧推"], Round: 4, Reps: 10}))
+		// AMRAP 不限制轮数
+		assert.Equal(t, http.StatusCreated, logSet(models.LogWorkoutSetRequest{ExerciseID: exerciseIDs["波比跳"], Round: 7, Reps: 10}))
+		// 常规动作需要组号
+		assert.Equal(t, http.StatusBadRequest, logSet(models.LogWorkoutSetRequest{ExerciseID: exerciseIDs["深蹲"], Reps: 10}))
+		assert.Equal(t, http.StatusCreated, logSet(models.LogWorkoutSetRequest{ExerciseID: exerciseIDs["深蹲"], SetNumber: 1, Reps: 10}))
+
+		var logs []models.WorkoutSetLog
+		config.DB.Where("workout_session_id = ?", session.ID).Find(&logs)
+		require.Len(t, logs, 3)
+		assert.NotNil(t, logs[0].ExerciseGroupID)
+		assert.Equal(t, 3, logs[0].SetNumber)
+	})
+
+	t.Run("只能记录本次计划或自己计划里的动作", func(t *testing.T) {
+		var other models.User
+		require.NoError(t, config.DB.Where("id <> ?", user.ID).First(&other).Error)
+
+		legacy := models.TrainingPlan{UserID: other.ID, Name: "公开计划", Duration: 45, CaloriesBurned: 200, IsPublic: true}
+		require.NoError(t, config.DB.Create(&legacy).Error)
+		legacyExercise := models.Exercise{TrainingPlanID: legacy.ID, Name: "硬拉", Sets: 3, Reps: 5}
+		require.NoError(t, config.DB.Create(&legacyExercise).Error)
+		// 旧计划和一周训练计划的动作共用 training_plan_id，用完删除，避免出现在ID相同的一周训练计划里
+		t.Cleanup(func() { config.DB.Unscoped().Delete(&legacyExercise) })
+
+		foreign := createTestWeeklyPlan(t, other.ID, true)
+		var foreignExercise models.Exercise
+		require.NoError(t, config.DB.Where("training_plan_id = ? AND training_part_id IS NOT NULL", foreign.ID).
+			First(&foreignExercise).Error)
+
+		session := models.WorkoutSession{UserID: user.ID, TrainingPlanID: legacy.ID, StartTime: time.Now(), Status: "ongoing"}
+		require.NoError(t, config.DB.Create(&session).Error)
+		logSet := func(exerciseID uint) int {
+			body, _ := json.Marshal(models.LogWorkoutSetRequest{ExerciseID: exerciseID, SetNumber: 1, Reps: 5})
+			req, _ := http.NewRequest("POST", "/api/training/sessions/"+uintToString(session.ID)+"/sets", bytes.NewBuffer(body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			return w.Code
+		}
+
+		assert.Equal(t, http.StatusCreated, logSet(legacyExercise.ID))
+		assert.Equal(t, http.StatusNotFound, logSet(foreignExercise.ID))
+		assert.Equal(t, http.StatusNotFound, logSet(999999))
+	})
+
+	t.Run("快照回滚保留动作组", func(t *testing.T) {
+		var plan models.WeeklyTrainingPlan
+		require.NoError(t, config.DB.Where("user_id = ? AND name = ?", user.ID, "混合训练").
+			Preload("Days.Parts.Exercises").Preload("Days.Parts.Groups.Exercises").First(&plan).Error)
+		snapshot := buildPlanSnapshot(plan)
+		require.NoError(t, replacePlanDays(config.DB, plan.ID, snapshotToTrainingDays(snapshot)))
+
+		var restored models.WeeklyTrainingPlan
+		require.NoError(t, config.DB.Preload("Days.Parts.Exercises").Preload("Days.Parts.Groups.Exercises").
+			First(&restored, plan.ID).Error)
+		assert.Empty(t, diffPlanSnapshots(snapshot, buildPlanSnapshot(restored)).Exercises)
+		require.Len(t, restored.Days[0].Parts[0].Groups, 2)
+		assert.Len(t, restored.Days[0].Parts[0].Groups[0].Exercises, 2)
+	})
+}
@@ -0,0 +1,319 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gymates-backend/config"
+	"gymates-backend/models"
+	"gymates-backend/services"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// ExerciseLibraryController 标准动作库控制器
+type ExerciseLibraryController struct{}
+
+// NewExerciseLibraryController 创建动作库控制器
+func NewExerciseLibraryController() *ExerciseLibraryController {
+	return &ExerciseLibraryController{}
+}
+
+// GetExercise 获取动作库条目详情
+// GET /api/training/exercises/:id
+func (elc *ExerciseLibraryController) GetExercise(c *gin.Context) {
+	entry, ok := findLibraryExercise(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "获取动作详情成功",
+		Data:    newExerciseLibraryDetail(entry),
+	})
+}
+
+// GetSubstitutions 获取动作的可替换动作，按相似度从高到低排列
+// GET /api/training/exercises/:id/substitutions?equipment=dumbbell
+func (elc *ExerciseLibraryController) GetSubstitutions(c *gin.Context) {
+	entry, ok := findLibraryExercise(c)
+	if !ok {
+		return
+	}
+
+	var substitutions []models.ExerciseSubstitution
+	if err := config.DB.Where("exercise_id = ?", entry.ID).
+		Preload("Substitute").Find(&substitutions).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Success: false,
+			Message: "获取替换动作失败",
+			Error:   err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	// 可按器械过滤，便于在器械不足时挑选替代动作
+	equipment := c.Query("equipment")
+	result := make([]models.ExerciseSubstitution, 0, len(substitutions))
+	for _, substitution := range substitutions {
+		if substitution.Substitute.ID == 0 {
+			continue
+		}
+		if equipment != "" && substitution.Substitute.Equipment != equipment {
+			continue
+		}
+		result = append(result, substitution)
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].Similarity != result[j].Similarity {
+			return result[i].Similarity > result[j].Similarity
+		}
+		return result[i].SubstituteID < result[j].SubstituteID
+	})
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "获取替换动作成功",
+		Data:    result,
+	})
+}
+
+// GetMuscles 获取肌肉分类
+// GET /api/training/muscles
+func (elc *ExerciseLibraryController) GetMuscles(c *gin.Context) {
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "获取肌肉分类成功",
+		Data:    models.Muscles,
+	})
+}
+
+// findLibraryExercise 按路径参数查找动作库条目，失败时已写入响应
+func findLibraryExercise(c *gin.Context) (models.ExerciseLibrary, bool) {
+	var entry models.ExerciseLibrary
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Message: "无效的动作ID",
+			Error:   err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return entry, false
+	}
+
+	if err := config.DB.First(&entry, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Success: false,
+			Message: "动作不存在",
+			Error:   err.Error(),
+			Code:    http.StatusNotFound,
+		})
+		return entry, false
+	}
+	return entry, true
+}
+
+// newExerciseLibraryDetail 组装动作详情（解析肌肉和别名）
+func newExerciseLibraryDetail(entry models.ExerciseLibrary) models.ExerciseLibraryDetail {
+	detail := models.ExerciseLibraryDetail{
+		ExerciseLibrary: entry,
+		AliasList:       entry.AliasList(),
+	}
+	for _, key := range entry.PrimaryMuscleList() {
+		if muscle, ok := models.LookupMuscle(key); ok {
+			detail.Primary = append(detail.Primary, muscle)
+		}
+	}
+	for _, key := range entry.SecondaryMuscleList() {
+		if muscle, ok := models.LookupMuscle(key); ok {
+			detail.Secondary = append(detail.Secondary, muscle)
+		}
+	}
+	return detail
+}
+
+// queryExerciseLibrary 按查询参数分页查询动作库
+//
+// 支持 q（名称/英文名/别名）、muscle_group（部位或肌肉）、muscle、pattern、difficulty、equipment。
+func queryExerciseLibrary(c *gin.Context, defaultLimit int) (models.ExerciseLibraryResponse, error) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultLimit)))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = defaultLimit
+	}
+
+	dbQuery := config.DB.Model(&models.ExerciseLibrary{})
+
+	if query := c.Query("q"); query != "" {
+		like := "%" + query + "%"
+		dbQuery = dbQuery.Where("name LIKE ? OR name_en LIKE ? OR aliases LIKE ?", like, like, like)
+	}
+
+	// muscle_group 兼容部位（chest/back...）和具体肌肉键
+	if muscleGroup := c.Query("muscle_group"); muscleGroup != "" {
+		dbQuery = dbQuery.Where("part = ? OR primary_muscles LIKE ?", muscleGroup, `%"`+muscleGroup+`"%`)
+	}
+	if muscle := c.Query("muscle"); muscle != "" {
+		dbQuery = dbQuery.Where("primary_muscles LIKE ? OR secondary_muscles LIKE ?", `%"`+muscle+`"%`, `%"`+muscle+`"%`)
+	}
+	if pattern := c.Query("pattern"); pattern != "" {
+		dbQuery = dbQuery.Where("movement_pattern = ?", pattern)
+	}
+	if difficulty := c.Query("difficulty"); difficulty != "" {
+		dbQuery = dbQuery.Where("level = ?", difficulty)
+	}
+	if equipment := c.Query("equipment"); equipment != "" {
+		dbQuery = dbQuery.Where("equipment = ?", equipment)
+	}
+
+	var total int64
+	if err := dbQuery.Count(&total).Error; err != nil {
+		return models.ExerciseLibraryResponse{}, err
+	}
+
+	var exercises []models.ExerciseLibrary
+	offset := (page - 1) * limit
+	if err := dbQuery.Offset(offset).Limit(limit).Order("part ASC, id ASC").Find(&exercises).Error; err != nil {
+		return models.ExerciseLibraryResponse{}, err
+	}
+
+	return models.ExerciseLibraryResponse{
+		Exercises: exercises,
+		Pagination: models.Pagination{
+			Page:       page,
+			Limit:      limit,
+			Total:      total,
+			TotalPages: int((total + int64(limit) - 1) / int64(limit)),
+			HasMore:    int64(page*limit) < total,
+		},
+	}, nil
+}
+
+// exerciseLibraryResolver 将计划动作关联到标准动作库
+type exerciseLibraryResolver struct {
+	entries map[uint]models.ExerciseLibrary
+	matcher *services.ExerciseMatcher
+}
+
+// newExerciseLibraryResolver 加载动作库，供一次请求内多次使用
+func newExerciseLibraryResolver(db *gorm.DB) (*exerciseLibraryResolver, error) {
+	var library []models.ExerciseLibrary
+	if err := db.Find(&library).Error; err != nil {
+		return nil, err
+	}
+	resolver := &exerciseLibraryResolver{
+		entries: make(map[uint]models.ExerciseLibrary, len(library)),
+		matcher: services.NewExerciseMatcher(library),
+	}
+	for _, entry := range library {
+		resolver.entries[entry.ID] = entry
+	}
+	return resolver, nil
+}
+
+// check 校验动作引用：指定的动作库ID必须存在，未指定时必须填写名称
+func (r *exerciseLibraryResolver) check(refs []exerciseRef) error {
+	for _, ref := range refs {
+		if ref.libraryID == nil {
+			if strings.TrimSpace(ref.name) == "" {
+				return fmt.Errorf("exercise name or exercise_library_id is required")
+			}
+			continue
+		}
+		if _, ok := r.entries[*ref.libraryID]; !ok {
+			return fmt.Errorf("exercise library entry %d not found", *ref.libraryID)
+		}
+	}
+	return nil
+}
+
+// apply 关联动作库
+//
+// 指定了动作库ID时以动作库的标准名称为准，并补全未填写的部位、器械等信息；
+// 否则按名称或别名完全匹配，匹配不到的保留自定义名称。
+func (r *exerciseLibraryResolver) apply(exercise *models.Exercise, libraryID *uint) {
+	var entry models.ExerciseLibrary
+	if libraryID != nil {
+		found, ok := r.entries[*libraryID]
+		if !ok {
+			return
+		}
+		entry = found
+	} else {
+		match, ok := r.matcher.Match(exercise.Name)
+		if !ok || match.MatchedBy == "fuzzy" {
+			return
+		}
+		entry = match.Entry
+	}
+
+	id := entry.ID
+	exercise.ExerciseLibraryID = &id
+	exercise.Name = entry.Name
+	if exercise.MuscleGroup == "" {
+		exercise.MuscleGroup = entry.Part
+	}
+	if exercise.Equipment == "" {
+		exercise.Equipment = entry.Equipment
+	}
+	if exercise.Difficulty == "" {
+		exercise.Difficulty = entry.Level
+	}
+	if exercise.Description == "" {
+		exercise.Description = entry.Description
+	}
+	if exercise.Instructions == "" {
+		exercise.Instructions = entry.Instructions
+	}
+}
+
+// lookup 按ID获取动作库条目，未关联或不存在时返回 nil
+func (r *exerciseLibraryResolver) lookup(libraryID *uint) *models.ExerciseLibrary {
+	if libraryID == nil {
+		return nil
+	}
+	entry, ok := r.entries[*libraryID]
+	if !ok {
+		return nil
+	}
+	return &entry
+}
+
+// exerciseRef 请求中的动作引用
+type exerciseRef struct {
+	name      string
+	libraryID *uint
+}
+
+// createExerciseRefs 收集训练部位请求（含动作组）中的动作引用
+func createExerciseRefs(exercises []models.CreateExerciseRequest, groups []models.CreateExerciseGroupRequest) []exerciseRef {
+	var refs []exerciseRef
+	for _, exercise := range exercises {
+		refs = append(refs, exerciseRef{exercise.Name, exercise.ExerciseLibraryID})
+	}
+	for _, group := range groups {
+		for _, exercise := range group.Exercises {
+			refs = append(refs, exerciseRef{exercise.Name, exercise.ExerciseLibraryID})
+		}
+	}
+	return refs
+}
+
+// updateExerciseRefs 收集更新请求中的动作引用
+func updateExerciseRefs(exercises []models.UpdateExerciseRequest, groups []models.CreateExerciseGroupRequest) []exerciseRef {
+	refs := createExerciseRefs(nil, groups)
+	for _, exercise := range exercises {
+		refs = append(refs, exerciseRef{exercise.Name, exercise.ExerciseLibraryID})
+	}
+	return refs
+}
@@ -0,0 +1,273 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gymates-backend/config"
+	"gymates-backend/models"
+	"gymates-backend/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestExerciseLibrarySeed 测试标准动作库种子数据的完整性
+func TestExerciseLibrarySeed(t *testing.T) {
+	setupTestDB(t)
+
+	var library []models.ExerciseLibrary
+	require.NoError(t, config.DB.Find(&library).Error)
+	require.NotEmpty(t, library)
+
+	t.Run("肌肉键都在分类中", func(t *testing.T) {
+		for _, entry := range library {
+			assert.NotEmpty(t, entry.PrimaryMuscleList(), entry.Name)
+			for _, key := range append(entry.PrimaryMuscleList(), entry.SecondaryMuscleList()...) {
+				_, ok := models.LookupMuscle(key)
+				assert.True(t, ok, "%s: unknown muscle %s", entry.Name, key)
+			}
+		}
+	})
+
+	t.Run("名称和别名不重复", func(t *testing.T) {
+		owner := map[string]string{}
+		for _, entry := range library {
+			for _, name := range append([]string{entry.Name, entry.NameEN}, entry.AliasList()...) {
+				key := services.NormalizeExerciseName(name)
+				if previous, exists := owner[key]; exists {
+					assert.Equal(t, previous, entry.Name, "%s 同时属于 %s 和 %s", name, previous, entry.Name)
+				}
+				owner[key] = entry.Name
+			}
+		}
+	})
+
+	t.Run("替换关系双向且相似度有效", func(t *testing.T) {
+		var edges []models.ExerciseSubstitution
+		require.NoError(t, config.DB.Find(&edges).Error)
+		require.NotEmpty(t, edges)
+
+		pairs := map[[2]uint]float64{}
+		for _, edge := range edges {
+			pairs[[2]uint{edge.ExerciseID, edge.SubstituteID}] = edge.Similarity
+		}
+		for pair, similarity := range pairs {
+			assert.NotEqual(t, pair[0], pair[1])
+			assert.True(t, similarity > 0 && similarity <= 1, "similarity %v", similarity)
+			reverse, ok := pairs[[2]uint{pair[1], pair[0]}]
+			assert.True(t, ok)
+			assert.Equal(t, similarity, reverse)
+		}
+	})
+
+	t.Run("重复执行不会新增数据", func(t *testing.T) {
+		var before, after, edgesBefore, edgesAfter int64
+		config.DB.Model(&models.ExerciseLibrary{}).Count(&before)
+		config.DB.Model(&models.ExerciseSubstitution{}).Count(&edgesBefore)
+		require.NoError(t, config.SeedExerciseLibrary(config.DB))
+		config.DB.Model(&models.ExerciseLibrary{}).Count(&after)
+		config.DB.Model(&models.ExerciseSubstitution{}).Count(&edgesAfter)
+		assert.Equal(t, before, after)
+		assert.Equal(t, edgesBefore, edgesAfter)
+	})
+}
+
+// TestExerciseLibraryEndpoints 测试动作库查询、详情和替换动作接口
+func TestExerciseLibraryEndpoints(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	setupTestDB(t)
+
+	var bench models.ExerciseLibrary
+	require.NoError(t, config.DB.Where("name = ?", "平板卧推").First(&bench).Error)
+
+	controller := NewExerciseLibraryController()
+	trainingController := NewTrainingController()
+	router := gin.New()
+	router.GET("/api/training/exercises", trainingController.GetAllExercises)
+	router.GET("/api/training/exercises/search", trainingController.SearchExercises)
+	router.GET("/api/training/exercises/:id", controller.GetExercise)
+	router.GET("/api/training/exercises/:id/substitutions", controller.GetSubstitutions)
+
+	get := func(path string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("GET", path, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("搜索", func(t *testing.T) {
+		tests := []struct {
+			name     string
+			query    string
+			contains string
+			check    func(models.ExerciseLibrary) bool
+		}{
+			{name: "按别名搜索", query: "q=Bench%20Press", contains: "平板卧推"},
+			{name: "按部位筛选", query: "muscle_group=legs&limit=100", contains: "深蹲", check: func(e models.ExerciseLibrary) bool { return e.Part == "legs" }},
+			{name: "按肌肉筛选", query: "muscle=rear_delts&limit=100", contains: "面拉"},
+			{name: "按动作模式和器械筛选", query: "pattern=vertical_pull&equipment=machine", contains: "高位下拉", check: func(e models.ExerciseLibrary) bool { return e.Equipment == "machine" }},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				w := get("/api/training/exercises/search?" + tt.query)
+				require.Equal(t, http.StatusOK, w.Code)
+				var response struct {
+					Data models.ExerciseLibraryResponse `json:"data"`
+				}
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+
+				names := []string{}
+				for _, exercise := range response.Data.Exercises {
+					names = append(names, exercise.Name)
+					if tt.check != nil {
+						assert.True(t, tt.check(exercise), exercise.Name)
+					}
+				}
+				assert.Contains(t, names, tt.contains)
+			})
+		}
+	})
+
+	t.Run("动作详情", func(t *testing.T) {
+		w := get("/api/training/exercises/" + uintToString(bench.ID))
+		require.Equal(t, http.StatusOK, w.Code)
+		var response struct {
+			Data models.ExerciseLibraryDetail `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, "Barbell Bench Press", response.Data.NameEN)
+		assert.Contains(t, response.Data.AliasList, "杠铃卧推")
+		require.NotEmpty(t, response.Data.Primary)
+		assert.Equal(t, "胸大肌", response.Data.Primary[0].Name)
+
+		assert.Equal(t, http.StatusNotFound, get("/api/training/exercises/99999").Code)
+		assert.Equal(t, http.StatusBadRequest, get("/api/training/exercises/abc").Code)
+	})
+
+	t.Run("替换动作按相似度排序", func(t *testing.T) {
+		w := get("/api/training/exercises/" + uintToString(bench.ID) + "/substitutions")
+		require.Equal(t, http.StatusOK, w.Code)
+		var response struct {
+			Data []models.ExerciseSubstitution `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		require.NotEmpty(t, response.Data)
+		for i := 1; i < len(response.Data); i++ {
+			assert.GreaterOrEqual(t, response.Data[i-1].Similarity, response.Data[i].Similarity)
+		}
+		assert.Equal(t, "哑铃卧推", response.Data[0].Substitute.Name)
+
+		w = get("/api/training/exercises/" + uintToString(bench.ID) + "/substitutions?equipment=bodyweight")
+		require.Equal(t, http.StatusOK, w.Code)
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		require.NotEmpty(t, response.Data)
+		for _, substitution := range response.Data {
+			assert.Equal(t, "bodyweight", substitution.Substitute.Equipment)
+		}
+	})
+}
+
+// TestPlanExerciseLibraryReference 测试按动作库ID创建计划动作
+func TestPlanExerciseLibraryReference(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	setupTestDB(t)
+
+	var user models.User
+	require.NoError(t, config.DB.First(&user, 2).Error)
+	var squat models.ExerciseLibrary
+	require.NoError(t, config.DB.Where("name = ?", "深蹲").First(&squat).Error)
+
+	controller := NewWeeklyTrainingPlanController()
+	router := gin.New()
+	router.POST("/api/training/weekly-plans", withTestUser(&user), controller.CreateWeeklyTrainingPlan)
+
+	create := func(exercises []models.CreateExerciseRequest) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(models.CreateWeeklyTrainingPlanRequest{
+			Name: "动作库引用",
+			Days: []models.CreateTrainingDayRequest{{
+				DayOfWeek: 2,
+				DayName:   "Tuesday",
+				Parts: []models.CreateTrainingPartRequest{{
+					MuscleGroup:     "legs",
+					MuscleGroupName: "腿部",
+					Exercises:       exercises,
+				}},
+			}},
+		})
+		req, _ := http.NewRequest("POST", "/api/training/weekly-plans", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	unknown := uint(99999)
+	tests := []struct {
+		name      string
+		exercises []models.CreateExerciseRequest
+		wantCode  int
+		wantNames []string
+		wantLink  []bool
+	}{
+		{
+			name: "按ID引用时使用标准名称",
+			exercises: []models.CreateExerciseRequest{
+				{ExerciseLibraryID: &squat.ID, Sets: 5, Reps: 5},
+			},
+			wantCode:  http.StatusCreated,
+			wantNames: []string{"深蹲"},
+			wantLink:  []bool{true},
+		},
+		{
+			name: "按别名自动关联，自定义动作保留原名",
+			exercises: []models.CreateExerciseRequest{
+				{Name: "箭步蹲", Sets: 3, Reps: 12, Order: 1},
+				{Name: "我的自创动作", Sets: 3, Reps: 12, Order: 2},
+			},
+			wantCode:  http.StatusCreated,
+			wantNames: []string{"弓步蹲", "我的自创动作"},
+			wantLink:  []bool{true, false},
+		},
+		{
+			name:      "动作库ID不存在",
+			exercises: []models.CreateExerciseRequest{{ExerciseLibraryID: &unknown, Sets: 3, Reps: 10}},
+			wantCode:  http.StatusBadRequest,
+		},
+		{
+			name:      "既没有名称也没有动作库ID",
+			exercises: []models.CreateExerciseRequest{{Sets: 3, Reps: 10}},
+			wantCode:  http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := create(tt.exercises)
+			require.Equal(t, tt.wantCode, w.Code, w.Body.String())
+			if tt.wantCode != http.StatusCreated {
+				return
+			}
+
+			var response struct {
+				Data struct {
+					Plan models.WeeklyTrainingPlan `json:"plan"`
+				} `json:"data"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			var exercises []models.Exercise
+			require.NoError(t, config.DB.Where("training_plan_id = ?", response.Data.Plan.ID).Order("\"order\" ASC").Find(&exercises).Error)
+			require.Len(t, exercises, len(tt.wantNames))
+			for i, exercise := range exercises {
+				assert.Equal(t, tt.wantNames[i], exercise.Name)
+				assert.Equal(t, tt.wantLink[i], exercise.ExerciseLibraryID != nil)
+			}
+			assert.Equal(t, "legs", exercises[0].MuscleGroup)
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gymates-backend/models"
+	"gymates-backend/services/geo"
+)
+
+// geohashCondition 半径范围内的 geohash 前缀条件，候选结果需要再按实际距离过滤
+//
+// 只用 LIKE 'prefix%'，SQLite、MySQL 和 Postgres 都能用上 geohash 列的普通索引。
+func geohashCondition(lat, lon, radiusKm float64) (string, []interface{}) {
+	prefixes := geo.Cover(geo.BoundingBox(lat, lon, radiusKm))
+	conditions := make([]string, len(prefixes))
+	args := make([]interface{}, len(prefixes))
+	for i, prefix := range prefixes {
+		conditions[i] = "geohash LIKE ?"
+		args[i] = prefix + "%"
+	}
+	return "(" + strings.Join(conditions, " OR ") + ")", args
+}
+
+// parseRadius 解析 radius_km 参数，超过上限时按上限处理，非法时返回400
+func parseRadius(c *gin.Context, defaultKm, maxKm float64) (float64, bool) {
+	raw := c.Query("radius_km")
+	if raw == "" {
+		return defaultKm, true
+	}
+	radius, err := strconv.ParseFloat(raw, 64)
+	if err != nil || radius <= 0 {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Message: "无效的距离范围",
+			Error:   "Invalid radius_km: " + raw,
+			Code:    http.StatusBadRequest,
+		})
+		return 0, false
+	}
+	if radius > maxKm {
+		radius = maxKm
+	}
+	return radius, true
+}
+
+// parseLimit 解析 limit 参数
+func parseLimit(c *gin.Context, defaultLimit, maxLimit int) int {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultLimit)))
+	if err != nil || limit <= 0 {
+		return defaultLimit
+	}
+	if limit > maxLimit {
+		return maxLimit
+	}
+	return limit
+}
+
+// requireUserLocation 用户没有设置位置时返回400
+func requireUserLocation(c *gin.Context, user models.User) bool {
+	if user.Latitude != nil && user.Longitude != nil {
+		return true
+	}
+	c.JSON(http.StatusBadRequest, models.ErrorResponse{
+		Success: false,
+		Message: "请先在资料中设置位置",
+		Error:   "User location is not set",
+		Code:    http.StatusBadRequest,
+	})
+	return false
+}
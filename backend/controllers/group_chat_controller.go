@@ -0,0 +1,931 @@
+package controllers
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gymates-backend/config"
+	"gymates-backend/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// chatRoleRank 角色等级，只能管理等级更低的成员
+var chatRoleRank = map[string]int{
+	models.ChatRoleOwner:  3,
+	models.ChatRoleAdmin:  2,
+	models.ChatRoleMember: 1,
+}
+
+// chatRoleNames 角色中文名
+var chatRoleNames = map[string]string{
+	models.ChatRoleOwner:  "群主",
+	models.ChatRoleAdmin:  "管理员",
+	models.ChatRoleMember: "成员",
+}
+
+// errGroupFull 群成员已满
+var errGroupFull = fmt.Errorf("group chat cannot have more than %d members", models.MaxGroupMembers)
+
+// GroupChatController 群聊控制器
+type GroupChatController struct{}
+
+// NewGroupChatController 创建群聊控制器
+func NewGroupChatController() *GroupChatController {
+	return &GroupChatController{}
+}
+
+// CreateGroup 创建群聊，创建者为群主
+// POST /api/messages/groups
+func (gc *GroupChatController) CreateGroup(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Success: false,
+			Message: "用户未认证",
+			Error:   "User not authenticated",
+			Code:    http.StatusUnauthorized,
+		})
+		return
+	}
+
+	var req models.CreateGroupChatRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Message: "请求参数错误",
+			Error:   err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+	currentUser := user.(*models.User)
+	if strings.TrimSpace(req.Name) == "" {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Message: "群名称不能为空",
+			Error:   "Name is required",
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	if err := checkCanMessageAll(currentUser.ID, req.MemberIDs); err != nil {
+		writePrivacyError(c, "创建群聊失败", err)
+		return
+	}
+
+	chat := models.Chat{Name: strings.TrimSpace(req.Name), Avatar: req.Avatar, Description: req.Description}
+	if err := createGroupChat(&chat, currentUser, req.MemberIDs); err != nil {
+		writeGroupError(c, "创建群聊失败", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.SuccessResponse{
+		Success: true,
+		Message: "创建群聊成功",
+		Data:    chat,
+	})
+}
+
+// UpdateGroup 修改群聊资料（群主和管理员）
+// PUT /api/messages/groups/:id
+func (gc *GroupChatController) UpdateGroup(c *gin.Context) {
+	currentUser, chat, membership, ok := userGroupChat(c)
+	if !ok || !requireGroupRole(c, membership, models.ChatRoleAdmin) {
+		return
+	}
+
+	var req models.UpdateGroupChatRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Message: "请求参数错误",
+			Error:   err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	updates := map[string]interface{}{}
+	name := chat.Name
+	if req.Name != nil && strings.TrimSpace(*req.Name) != "" {
+		name = strings.TrimSpace(*req.Name)
+	}
+	renamed := name != chat.Name
+	if renamed {
+		updates["name"] = name
+	}
+	if req.Avatar != nil {
+		updates["avatar"] = *req.Avatar
+	}
+	if req.Description != nil {
+		updates["description"] = *req.Description
+	}
+	if len(updates) > 0 {
+		if err := config.DB.Model(&chat).Updates(updates).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+				Success: false,
+				Message: "修改群聊资料失败",
+				Error:   err.Error(),
+				Code:    http.StatusInternalServerError,
+			})
+			return
+		}
+	}
+	if renamed {
+		postSystemMessage(chat.ID, currentUser.ID, fmt.Sprintf("%s 将群名称修改为「%s」", currentUser.Name, name))
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "修改群聊资料成功",
+		Data:    loadGroupChat(chat.ID, currentUser.ID),
+	})
+}
+
+// GetMembers 获取群成员，按角色和加入时间排序
+// GET /api/messages/groups/:id/members
+func (gc *GroupChatController) GetMembers(c *gin.Context) {
+	_, chat, _, ok := userGroupChat(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "获取群成员成功",
+		Data:    groupMembers(chat.ID),
+	})
+}
+
+// AddMembers 邀请用户加入群聊（群主和管理员）
+// POST /api/messages/groups/:id/members
+func (gc *GroupChatController) AddMembers(c *gin.Context) {
+	currentUser, chat, membership, ok := userGroupChat(c)
+	if !ok || !requireGroupRole(c, membership, models.ChatRoleAdmin) {
+		return
+	}
+
+	var req models.AddGroupMembersRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Message: "请求参数错误",
+			Error:   err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	if err := checkCanMessageAll(currentUser.ID, req.UserIDs); err != nil {
+		writePrivacyError(c, "添加群成员失败", err)
+		return
+	}
+
+	var added []models.User
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		added, err = addGroupMembers(tx, chat.ID, req.UserIDs)
+		return err
+	})
+	if err != nil {
+		writeGroupError(c, "添加群成员失败", err)
+		return
+	}
+	if len(added) > 0 {
+		postSystemMessage(chat.ID, currentUser.ID, fmt.Sprintf("%s 邀请 %s 加入了群聊", currentUser.Name, joinUserNames(added)))
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "添加群成员成功",
+		Data:    groupMembers(chat.ID),
+	})
+}
+
+// RemoveMember 将成员移出群聊，只能移出角色低于自己的成员
+// DELETE /api/messages/groups/:id/members/:userId
+func (gc *GroupChatController) RemoveMember(c *gin.Context) {
+	currentUser, chat, membership, ok := userGroupChat(c)
+	if !ok || !requireGroupRole(c, membership, models.ChatRoleAdmin) {
+		return
+	}
+	target, ok := groupMemberParam(c, chat.ID)
+	if !ok {
+		return
+	}
+	if chatRoleRank[target.Role] >= chatRoleRank[membership.Role] {
+		c.JSON(http.StatusForbidden, models.ErrorResponse{
+			Success: false,
+			Message: "只能移出角色低于自己的成员",
+			Error:   "Insufficient role",
+			Code:    http.StatusForbidden,
+		})
+		return
+	}
+
+	if err := config.DB.Where("chat_id = ? AND user_id = ?", chat.ID, target.UserID).Delete(&models.ChatParticipant{}).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Success: false,
+			Message: "移出群成员失败",
+			Error:   err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+	// 被移出的成员也会收到这条系统消息
+	postSystemMessage(chat.ID, currentUser.ID, fmt.Sprintf("%s 将 %s 移出了群聊", currentUser.Name, target.User.Name), target.UserID)
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "移出群成员成功",
+		Data:    groupMembers(chat.ID),
+	})
+}
+
+// UpdateMemberRole 设置或取消管理员（群主）
+// PUT /api/messages/groups/:id/members/:userId/role
+func (gc *GroupChatController) UpdateMemberRole(c *gin.Context) {
+	currentUser, chat, membership, ok := userGroupChat(c)
+	if !ok || !requireGroupRole(c, membership, models.ChatRoleOwner) {
+		return
+	}
+
+	var req models.UpdateMemberRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Message: "请求参数错误",
+			Error:   err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+	target, ok := groupMemberParam(c, chat.ID)
+	if !ok {
+		return
+	}
+	if target.Role == models.ChatRoleOwner {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Message: "不能修改群主的角色，请使用转让群主",
+			Error:   "Cannot change owner role",
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	if target.Role != req.Role {
+		if err := config.DB.Model(&target).Update("role", req.Role).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+				Success: false,
+				Message: "设置成员角色失败",
+				Error:   err.Error(),
+				Code:    http.StatusInternalServerError,
+			})
+			return
+		}
+		content := fmt.Sprintf("%s 将 %s 设为管理员", currentUser.Name, target.User.Name)
+		if req.Role == models.ChatRoleMember {
+			content = fmt.Sprintf("%s 取消了 %s 的管理员", currentUser.Name, target.User.Name)
+		}
+		postSystemMessage(chat.ID, currentUser.ID, content)
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "设置成员角色成功",
+		Data:    groupMembers(chat.ID),
+	})
+}
+
+// TransferOwnership 转让群主，原群主成为管理员
+// POST /api/messages/groups/:id/transfer
+func (gc *GroupChatController) TransferOwnership(c *gin.Context) {
+	currentUser, chat, membership, ok := userGroupChat(c)
+	if !ok || !requireGroupRole(c, membership, models.ChatRoleOwner) {
+		return
+	}
+
+	var req models.TransferGroupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Message: "请求参数错误",
+			Error:   err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	var target models.ChatParticipant
+	if err := config.DB.Preload("User").Where("chat_id = ? AND user_id = ?", chat.ID, req.UserID).First(&target).Error; err != nil || target.UserID == currentUser.ID {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Message: "只能转让给其他群成员",
+			Error:   "Target is not another member of the group",
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	if err := config.DB.Transaction(func(tx *gorm.DB) error {
+		return transferGroupOwner(tx, chat.ID, membership, target)
+	}); err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Success: false,
+			Message: "转让群主失败",
+			Error:   err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+	postSystemMessage(chat.ID, currentUser.ID, fmt.Sprintf("%s 将群主转让给了 %s", currentUser.Name, target.User.Name))
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "转让群主成功",
+		Data:    groupMembers(chat.ID),
+	})
+}
+
+// LeaveGroup 退出群聊
+// POST /api/messages/groups/:id/leave
+//
+// 群主退出时群主转给最早加入的管理员，没有管理员时转给最早加入的成员；最后一人退出时解散群聊。
+func (gc *GroupChatController) LeaveGroup(c *gin.Context) {
+	currentUser, chat, membership, ok := userGroupChat(c)
+	if !ok {
+		return
+	}
+
+	var successor *models.ChatParticipant
+	dissolved := false
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("chat_id = ? AND user_id = ?", chat.ID, currentUser.ID).Delete(&models.ChatParticipant{}).Error; err != nil {
+			return err
+		}
+		if membership.Role != models.ChatRoleOwner {
+			return nil
+		}
+
+		var remaining []models.ChatParticipant
+		if err := tx.Preload("User").Where("chat_id = ?", chat.ID).Find(&remaining).Error; err != nil {
+			return err
+		}
+		if len(remaining) == 0 {
+			dissolved = true
+			if err := tx.Where("chat_id = ?", chat.ID).Delete(&models.ChatInvite{}).Error; err != nil {
+				return err
+			}
+			return tx.Delete(&chat).Error
+		}
+		sortGroupParticipants(remaining)
+		successor = &remaining[0]
+		return transferGroupOwner(tx, chat.ID, models.ChatParticipant{}, *successor)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Success: false,
+			Message: "退出群聊失败",
+			Error:   err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	if !dissolved {
+		content := fmt.Sprintf("%s 退出了群聊", currentUser.Name)
+		if successor != nil {
+			content += fmt.Sprintf("，%s 成为新群主", successor.User.Name)
+		}
+		postSystemMessage(chat.ID, currentUser.ID, content)
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "退出群聊成功",
+	})
+}
+
+// CreateInvite 创建邀请链接（群主和管理员）
+// POST /api/messages/groups/:id/invites
+func (gc *GroupChatController) CreateInvite(c *gin.Context) {
+	currentUser, chat, membership, ok := userGroupChat(c)
+	if !ok || !requireGroupRole(c, membership, models.ChatRoleAdmin) {
+		return
+	}
+
+	var req models.CreateChatInviteRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Success: false,
+				Message: "请求参数错误",
+				Error:   err.Error(),
+				Code:    http.StatusBadRequest,
+			})
+			return
+		}
+	}
+	if req.ExpiresInHours == 0 {
+		req.ExpiresInHours = models.DefaultInviteExpiryHour
+	}
+
+	code, err := newInviteCode()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Success: false,
+			Message: "创建邀请链接失败",
+			Error:   err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+	invite := models.ChatInvite{
+		ChatID:    chat.ID,
+		Code:      code,
+		CreatedBy: currentUser.ID,
+		ExpiresAt: time.Now().Add(time.Duration(req.ExpiresInHours) * time.Hour),
+		MaxUses:   req.MaxUses,
+	}
+	if err := config.DB.Create(&invite).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Success: false,
+			Message: "创建邀请链接失败",
+			Error:   err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.SuccessResponse{
+		Success: true,
+		Message: "创建邀请链接成功",
+		Data:    invite,
+	})
+}
+
+// GetInvites 获取未过期的邀请链接（群主和管理员）
+// GET /api/messages/groups/:id/invites
+func (gc *GroupChatController) GetInvites(c *gin.Context) {
+	_, chat, membership, ok := userGroupChat(c)
+	if !ok || !requireGroupRole(c, membership, models.ChatRoleAdmin) {
+		return
+	}
+
+	invites := []models.ChatInvite{}
+	config.DB.Where("chat_id = ? AND expires_at > ? AND (max_uses = 0 OR uses < max_uses)", chat.ID, time.Now()).
+		Order("created_at DESC").Find(&invites)
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "获取邀请链接成功",
+		Data:    invites,
+	})
+}
+
+// RevokeInvite 撤销邀请链接（群主和管理员）
+// DELETE /api/messages/groups/:id/invites/:inviteId
+func (gc *GroupChatController) RevokeInvite(c *gin.Context) {
+	_, chat, membership, ok := userGroupChat(c)
+	if !ok || !requireGroupRole(c, membership, models.ChatRoleAdmin) {
+		return
+	}
+
+	result := config.DB.Where("id = ? AND chat_id = ?", c.Param("inviteId"), chat.ID).Delete(&models.ChatInvite{})
+	if result.Error != nil || result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Success: false,
+			Message: "邀请链接不存在",
+			Error:   "Invite not found",
+			Code:    http.StatusNotFound,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "撤销邀请链接成功",
+	})
+}
+
+// PreviewInvite 查看邀请链接对应的群聊
+// GET /api/messages/invites/:code
+func (gc *GroupChatController) PreviewInvite(c *gin.Context) {
+	invite, chat, ok := findInvite(c)
+	if !ok || !inviteUsable(c, invite) {
+		return
+	}
+
+	var members int64
+	config.DB.Model(&models.ChatParticipant{}).Where("chat_id = ?", chat.ID).Count(&members)
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "获取邀请信息成功",
+		Data: gin.H{
+			"chat_id":     chat.ID,
+			"name":        chat.Name,
+			"avatar":      chat.Avatar,
+			"description": chat.Description,
+			"members":     members,
+			"expires_at":  invite.ExpiresAt,
+		},
+	})
+}
+
+// JoinByInvite 通过邀请链接加入群聊，已在群中时直接返回群聊
+// POST /api/messages/invites/:code/join
+func (gc *GroupChatController) JoinByInvite(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Success: false,
+			Message: "用户未认证",
+			Error:   "User not authenticated",
+			Code:    http.StatusUnauthorized,
+		})
+		return
+	}
+	currentUser := user.(*models.User)
+
+	invite, chat, ok := findInvite(c)
+	if !ok {
+		return
+	}
+
+	var existing int64
+	config.DB.Model(&models.ChatParticipant{}).Where("chat_id = ? AND user_id = ?", chat.ID, currentUser.ID).Count(&existing)
+	if existing > 0 {
+		c.JSON(http.StatusOK, models.SuccessResponse{
+			Success: true,
+			Message: "已在群聊中",
+			Data:    loadGroupChat(chat.ID, currentUser.ID),
+		})
+		return
+	}
+	if !inviteUsable(c, invite) {
+		return
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		// 条件更新保证并发加入时不会超过使用次数
+		result := tx.Model(&models.ChatInvite{}).
+			Where("id = ? AND (max_uses = 0 OR uses < max_uses)", invite.ID).
+			Update("uses", gorm.Expr("uses + 1"))
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errInviteUsedUp
+		}
+		_, err := addGroupMembers(tx, chat.ID, []uint{currentUser.ID})
+		return err
+	})
+	if err != nil {
+		writeGroupError(c, "加入群聊失败", err)
+		return
+	}
+	postSystemMessage(chat.ID, currentUser.ID, fmt.Sprintf("%s 通过邀请链接加入了群聊", currentUser.Name))
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "加入群聊成功",
+		Data:    loadGroupChat(chat.ID, currentUser.ID),
+	})
+}
+
+// errInviteUsedUp 邀请链接已达到使用次数
+var errInviteUsedUp = errors.New("invite has reached its maximum uses")
+
+// errUnknownUsers 部分用户不存在
+var errUnknownUsers = errors.New("some users do not exist")
+
+// writeGroupError 将群成员相关的错误转换为响应
+func writeGroupError(c *gin.Context, message string, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, errGroupFull):
+		status, message = http.StatusConflict, "群成员已满"
+	case errors.Is(err, errInviteUsedUp):
+		status, message = http.StatusGone, "邀请链接已失效"
+	case errors.Is(err, errUnknownUsers):
+		status, message = http.StatusBadRequest, "部分用户不存在"
+	}
+	c.JSON(status, models.ErrorResponse{
+		Success: false,
+		Message: message,
+		Error:   err.Error(),
+		Code:    status,
+	})
+}
+
+// createGroupChat 创建群聊并添加成员，owner 为群主
+func createGroupChat(chat *models.Chat, owner *models.User, memberIDs []uint) error {
+	chat.Type = models.ChatTypeGroup
+	chat.OwnerID = &owner.ID
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(chat).Error; err != nil {
+			return err
+		}
+		now := time.Now()
+		if err := tx.Create(&models.ChatParticipant{
+			ChatID: chat.ID, UserID: owner.ID, Role: models.ChatRoleOwner, JoinedAt: &now,
+		}).Error; err != nil {
+			return err
+		}
+		_, err := addGroupMembers(tx, chat.ID, memberIDs)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	postSystemMessage(chat.ID, owner.ID, fmt.Sprintf("%s 创建了群聊「%s」", owner.Name, chat.Name))
+	*chat = loadGroupChat(chat.ID, owner.ID)
+	return nil
+}
+
+// addGroupMembers 添加尚未在群中的用户，返回实际加入的用户
+func addGroupMembers(tx *gorm.DB, chatID uint, userIDs []uint) ([]models.User, error) {
+	var existing []uint
+	if err := tx.Model(&models.ChatParticipant{}).Where("chat_id = ?", chatID).Pluck("user_id", &existing).Error; err != nil {
+		return nil, err
+	}
+
+	newIDs := []uint{}
+	seen := map[uint]bool{}
+	for _, id := range existing {
+		seen[id] = true
+	}
+	for _, id := range userIDs {
+		if !seen[id] {
+			seen[id] = true
+			newIDs = append(newIDs, id)
+		}
+	}
+	if len(newIDs) == 0 {
+		return nil, nil
+	}
+	if len(existing)+len(newIDs) > models.MaxGroupMembers {
+		return nil, errGroupFull
+	}
+
+	var users []models.User
+	if err := tx.Where("id IN ?", newIDs).Order("id").Find(&users).Error; err != nil {
+		return nil, err
+	}
+	if len(users) != len(newIDs) {
+		return nil, errUnknownUsers
+	}
+
+	// 新成员从加入时的最新一条消息开始计算未读
+	var latest uint
+	if err := tx.Model(&models.Message{}).Where("chat_id = ?", chatID).Select("COALESCE(MAX(id), 0)").Scan(&latest).Error; err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	for _, user := range users {
+		if err := tx.Create(&models.ChatParticipant{
+			ChatID: chatID, UserID: user.ID, Role: models.ChatRoleMember, JoinedAt: &now, LastReadMessageID: latest,
+		}).Error; err != nil {
+			return nil, err
+		}
+	}
+	return users, nil
+}
+
+// transferGroupOwner 将群主转给 target，from 为空时表示原群主已退出
+func transferGroupOwner(tx *gorm.DB, chatID uint, from, target models.ChatParticipant) error {
+	if from.UserID != 0 {
+		if err := tx.Model(&models.ChatParticipant{}).Where("chat_id = ? AND user_id = ?", chatID, from.UserID).
+			Update("role", models.ChatRoleAdmin).Error; err != nil {
+			return err
+		}
+	}
+	if err := tx.Model(&models.ChatParticipant{}).Where("chat_id = ? AND user_id = ?", chatID, target.UserID).
+		Update("role", models.ChatRoleOwner).Error; err != nil {
+		return err
+	}
+	return tx.Model(&models.Chat{}).Where("id = ?", chatID).Update("owner_id", target.UserID).Error
+}
+
+// postSystemMessage 发送成员变动等系统消息，并推送给当前成员和 extra 中的用户
+func postSystemMessage(chatID, actorID uint, content string, extra ...uint) {
+	message := models.Message{
+		ChatID:   chatID,
+		SenderID: actorID,
+		Content:  content,
+		Type:     models.MessageTypeSystem,
+	}
+	if err := config.DB.Create(&message).Error; err != nil {
+		return
+	}
+	config.DB.Model(&models.Chat{}).Where("id = ?", chatID).Update("updated_at", time.Now())
+	config.DB.Preload("Sender").First(&message, message.ID)
+
+	realtimeBroker.Publish(models.NewRealtimeEvent(models.RealtimeEventMessage, chatID, actorID, message),
+		append(chatParticipantIDs(chatID), extra...)...)
+}
+
+// userGroupChat 获取路径中的群聊和当前用户的成员身份，失败时已写入响应
+func userGroupChat(c *gin.Context) (*models.User, models.Chat, models.ChatParticipant, bool) {
+	var chat models.Chat
+	var membership models.ChatParticipant
+
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Success: false,
+			Message: "用户未认证",
+			Error:   "User not authenticated",
+			Code:    http.StatusUnauthorized,
+		})
+		return nil, chat, membership, false
+	}
+	currentUser := user.(*models.User)
+
+	chatID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Message: "无效的聊天ID",
+			Error:   err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return currentUser, chat, membership, false
+	}
+
+	if err := config.DB.Where("id = ? AND type = ?", uint(chatID), models.ChatTypeGroup).First(&chat).Error; err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Success: false,
+			Message: "群聊不存在",
+			Error:   "Group chat not found",
+			Code:    http.StatusNotFound,
+		})
+		return currentUser, chat, membership, false
+	}
+
+	if err := config.DB.Where("chat_id = ? AND user_id = ?", chat.ID, currentUser.ID).First(&membership).Error; err != nil {
+		c.JSON(http.StatusForbidden, models.ErrorResponse{
+			Success: false,
+			Message: "无权访问此聊天",
+			Error:   "Access denied",
+			Code:    http.StatusForbidden,
+		})
+		return currentUser, chat, membership, false
+	}
+	return currentUser, chat, membership, true
+}
+
+// requireGroupRole 检查成员角色不低于 role，不满足时写入 403
+func requireGroupRole(c *gin.Context, membership models.ChatParticipant, role string) bool {
+	if chatRoleRank[membership.Role] >= chatRoleRank[role] {
+		return true
+	}
+	c.JSON(http.StatusForbidden, models.ErrorResponse{
+		Success: false,
+		Message: "需要" + chatRoleNames[role] + "权限",
+		Error:   "Insufficient role",
+		Code:    http.StatusForbidden,
+	})
+	return false
+}
+
+// groupMemberParam 获取路径中 userId 对应的群成员，失败时已写入响应
+func groupMemberParam(c *gin.Context, chatID uint) (models.ChatParticipant, bool) {
+	var target models.ChatParticipant
+	userID, err := strconv.ParseUint(c.Param("userId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Message: "无效的用户ID",
+			Error:   err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return target, false
+	}
+	if err := config.DB.Preload("User").Where("chat_id = ? AND user_id = ?", chatID, uint(userID)).First(&target).Error; err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Success: false,
+			Message: "该用户不在群聊中",
+			Error:   "Member not found",
+			Code:    http.StatusNotFound,
+		})
+		return target, false
+	}
+	return target, true
+}
+
+// findInvite 获取路径中的邀请链接和群聊，失败时已写入响应
+func findInvite(c *gin.Context) (models.ChatInvite, models.Chat, bool) {
+	var invite models.ChatInvite
+	var chat models.Chat
+	if err := config.DB.Where("code = ?", c.Param("code")).First(&invite).Error; err != nil ||
+		config.DB.Where("id = ? AND type = ?", invite.ChatID, models.ChatTypeGroup).First(&chat).Error != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Success: false,
+			Message: "邀请链接不存在",
+			Error:   "Invite not found",
+			Code:    http.StatusNotFound,
+		})
+		return invite, chat, false
+	}
+	return invite, chat, true
+}
+
+// inviteUsable 检查邀请链接未过期且未用完，失败时写入 410
+func inviteUsable(c *gin.Context, invite models.ChatInvite) bool {
+	if time.Now().Before(invite.ExpiresAt) && (invite.MaxUses == 0 || invite.Uses < invite.MaxUses) {
+		return true
+	}
+	c.JSON(http.StatusGone, models.ErrorResponse{
+		Success: false,
+		Message: "邀请链接已失效",
+		Error:   "Invite has expired",
+		Code:    http.StatusGone,
+	})
+	return false
+}
+
+// groupMembers 群成员列表，群主、管理员在前，同角色按加入时间排序
+func groupMembers(chatID uint) models.GroupMembersResponse {
+	var participants []models.ChatParticipant
+	config.DB.Preload("User").Where("chat_id = ?", chatID).Find(&participants)
+	sortGroupParticipants(participants)
+
+	response := models.GroupMembersResponse{
+		ChatID:     chatID,
+		Members:    make([]models.GroupMember, 0, len(participants)),
+		Total:      len(participants),
+		MaxMembers: models.MaxGroupMembers,
+	}
+	for _, participant := range participants {
+		response.Members = append(response.Members, models.GroupMember{
+			User:     participant.User,
+			Role:     participant.Role,
+			JoinedAt: participant.JoinedAt,
+		})
+	}
+	return response
+}
+
+// sortGroupParticipants 按角色从高到低、加入时间从早到晚排序
+func sortGroupParticipants(participants []models.ChatParticipant) {
+	sort.SliceStable(participants, func(i, j int) bool {
+		a, b := participants[i], participants[j]
+		if chatRoleRank[a.Role] != chatRoleRank[b.Role] {
+			return chatRoleRank[a.Role] > chatRoleRank[b.Role]
+		}
+		if a.JoinedAt != nil && b.JoinedAt != nil && !a.JoinedAt.Equal(*b.JoinedAt) {
+			return a.JoinedAt.Before(*b.JoinedAt)
+		}
+		return a.UserID < b.UserID
+	})
+}
+
+// loadGroupChat 重新加载群聊及成员、最新消息和未读数
+func loadGroupChat(chatID, userID uint) models.Chat {
+	var chat models.Chat
+	config.DB.Preload("Participants").First(&chat, chatID)
+	chats := []models.Chat{chat}
+	attachChatState(chats, userID)
+	return chats[0]
+}
+
+// joinUserNames 用顿号连接用户名
+func joinUserNames(users []models.User) string {
+	names := make([]string, len(users))
+	for i, user := range users {
+		names[i] = user.Name
+	}
+	return strings.Join(names, "、")
+}
+
+// newInviteCode 生成随机邀请码
+func newInviteCode() (string, error) {
+	buf := make([]byte, 12)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// defaultGroupName 未命名群聊取前三位成员名
+func defaultGroupName(owner *models.User, members []models.User) string {
+	users := append([]models.User{*owner}, members...)
+	if len(users) > 3 {
+		return joinUserNames(users[:3]) + "等"
+	}
+	return joinUserNames(users)
+}
@@ -0,0 +1,190 @@
+package controllers
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"gymates-backend/config"
+	"gymates-backend/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// TestGroupChats 测试群聊的角色权限、成员管理和邀请链接
+func TestGroupChats(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	setupTestDB(t)
+
+	users := map[string]*models.User{}
+	for _, name := range []string{"owner", "admin", "member", "guest", "late"} {
+		user := &models.User{Name: "群聊" + name, Email: "group-" + name + "@gymates.com", Password: "x"}
+		require.NoError(t, config.DB.Create(user).Error)
+		users[name] = user
+	}
+
+	messages := NewMessagesController()
+	groups := NewGroupChatController()
+	router := gin.New()
+	for name, user := range users {
+		group := router.Group("/"+name, withTestUser(user))
+		group.POST("/chats", messages.CreateChat)
+		group.GET("/chats/:id/messages", messages.GetMessages)
+		group.POST("/chats/:id/messages", messages.SendMessage)
+		group.POST("/groups", groups.CreateGroup)
+		group.PUT("/groups/:id", groups.UpdateGroup)
+		group.GET("/groups/:id/members", groups.GetMembers)
+		group.POST("/groups/:id/members", groups.AddMembers)
+		group.DELETE("/groups/:id/members/:userId", groups.RemoveMember)
+		group.PUT("/groups/:id/members/:userId/role", groups.UpdateMemberRole)
+		group.POST("/groups/:id/transfer", groups.TransferOwnership)
+		group.POST("/groups/:id/leave", groups.LeaveGroup)
+		group.POST("/groups/:id/invites", groups.CreateInvite)
+		group.DELETE("/groups/:id/invites/:inviteId", groups.RevokeInvite)
+		group.GET("/invites/:code", groups.PreviewInvite)
+		group.POST("/invites/:code/join", groups.JoinByInvite)
+	}
+
+	roles := func(t *testing.T, chatID uint) map[string]string {
+		var members models.GroupMembersResponse
+		require.Equal(t, http.StatusOK, sendJSON(t, router, "GET", "/owner/groups/"+uintToString(chatID)+"/members", nil, &members))
+		result := map[string]string{}
+		for _, member := range members.Members {
+			result[member.User.Name] = member.Role
+		}
+		return result
+	}
+
+	var chat models.Chat
+	code := sendJSON(t, router, "POST", "/owner/groups", models.CreateGroupChatRequest{
+		Name:      "周末撸铁群",
+		MemberIDs: []uint{users["admin"].ID, users["member"].ID, users["admin"].ID, users["owner"].ID},
+	}, &chat)
+	require.Equal(t, http.StatusCreated, code)
+	groupPath := "/groups/" + uintToString(chat.ID)
+
+	t.Run("创建者成为群主，成员去重", func(t *testing.T) {
+		assert.Equal(t, models.ChatTypeGroup, chat.Type)
+		assert.Equal(t, users["owner"].ID, *chat.OwnerID)
+		assert.Len(t, chat.Participants, 3)
+		assert.Equal(t, map[string]string{
+			"群聊owner":  models.ChatRoleOwner,
+			"群聊admin":  models.ChatRoleMember,
+			"群聊member": models.ChatRoleMember,
+		}, roles(t, chat.ID))
+		require.NotNil(t, chat.LastMessage)
+		assert.Equal(t, models.MessageTypeSystem, chat.LastMessage.Type)
+	})
+
+	t.Run("只有群主能设置管理员", func(t *testing.T) {
+		path := groupPath + "/members/" + uintToString(users["admin"].ID) + "/role"
+		assert.Equal(t, http.StatusForbidden, sendJSON(t, router, "PUT", "/member"+path, models.UpdateMemberRoleRequest{Role: "admin"}, nil))
+		assert.Equal(t, http.StatusOK, sendJSON(t, router, "PUT", "/owner"+path, models.UpdateMemberRoleRequest{Role: "admin"}, nil))
+		assert.Equal(t, models.ChatRoleAdmin, roles(t, chat.ID)["群聊admin"])
+	})
+
+	t.Run("普通成员不能修改群资料，管理员可以", func(t *testing.T) {
+		name := "工作日撸铁群"
+		assert.Equal(t, http.StatusForbidden, sendJSON(t, router, "PUT", "/member"+groupPath, models.UpdateGroupChatRequest{Name: &name}, nil))
+		var updated models.Chat
+		require.Equal(t, http.StatusOK, sendJSON(t, router, "PUT", "/admin"+groupPath, models.UpdateGroupChatRequest{Name: &name}, &updated))
+		assert.Equal(t, name, updated.Name)
+		assert.Contains(t, updated.LastMessage.Content, name)
+	})
+
+	t.Run("管理员不能移出管理员，群主可以移出成员", func(t *testing.T) {
+		assert.Equal(t, http.StatusForbidden, sendJSON(t, router, "DELETE", "/admin"+groupPath+"/members/"+uintToString(users["owner"].ID), nil, nil))
+		assert.Equal(t, http.StatusForbidden, sendJSON(t, router, "DELETE", "/member"+groupPath+"/members/"+uintToString(users["admin"].ID), nil, nil))
+		assert.Equal(t, http.StatusOK, sendJSON(t, router, "DELETE", "/admin"+groupPath+"/members/"+uintToString(users["member"].ID), nil, nil))
+		assert.Equal(t, http.StatusForbidden, sendJSON(t, router, "GET", "/member"+groupPath+"/members", nil, nil))
+		assert.Equal(t, http.StatusOK, sendJSON(t, router, "POST", "/admin"+groupPath+"/members", models.AddGroupMembersRequest{UserIDs: []uint{users["member"].ID}}, nil))
+		assert.Equal(t, http.StatusBadRequest, sendJSON(t, router, "POST", "/admin"+groupPath+"/members", models.AddGroupMembersRequest{UserIDs: []uint{999999}}, nil))
+	})
+
+	t.Run("新成员加入前的消息不算未读", func(t *testing.T) {
+		newcomer := models.User{Name: "群聊newcomer", Email: "group-newcomer@gymates.com", Password: "x"}
+		require.NoError(t, config.DB.Create(&newcomer).Error)
+		require.NoError(t, config.DB.Transaction(func(tx *gorm.DB) error {
+			_, err := addGroupMembers(tx, chat.ID, []uint{newcomer.ID})
+			return err
+		}))
+		assert.Equal(t, int64(0), unreadCounts(newcomer.ID, []uint{chat.ID})[chat.ID])
+		require.NoError(t, config.DB.Where("chat_id = ? AND user_id = ?", chat.ID, newcomer.ID).Delete(&models.ChatParticipant{}).Error)
+	})
+
+	t.Run("用户不能发送系统消息", func(t *testing.T) {
+		code := sendJSON(t, router, "POST", "/member/chats/"+uintToString(chat.ID)+"/messages",
+			models.SendMessageRequest{ChatID: chat.ID, Content: "伪造", Type: models.MessageTypeSystem}, nil)
+		assert.Equal(t, http.StatusBadRequest, code)
+	})
+
+	t.Run("邀请链接", func(t *testing.T) {
+		var invite models.ChatInvite
+		assert.Equal(t, http.StatusForbidden, sendJSON(t, router, "POST", "/member"+groupPath+"/invites", nil, nil))
+		require.Equal(t, http.StatusCreated, sendJSON(t, router, "POST", "/admin"+groupPath+"/invites", models.CreateChatInviteRequest{MaxUses: 1}, &invite))
+		assert.Len(t, invite.Code, 24)
+		assert.WithinDuration(t, time.Now().Add(24*time.Hour), invite.ExpiresAt, time.Minute)
+
+		var preview map[string]interface{}
+		require.Equal(t, http.StatusOK, sendJSON(t, router, "GET", "/guest/invites/"+invite.Code, nil, &preview))
+		assert.Equal(t, "工作日撸铁群", preview["name"])
+		assert.EqualValues(t, 3, preview["members"])
+
+		assert.Equal(t, http.StatusOK, sendJSON(t, router, "POST", "/guest/invites/"+invite.Code+"/join", nil, nil))
+		assert.Equal(t, models.ChatRoleMember, roles(t, chat.ID)["群聊guest"])
+		// 已在群中时重复加入不消耗次数
+		assert.Equal(t, http.StatusOK, sendJSON(t, router, "POST", "/guest/invites/"+invite.Code+"/join", nil, nil))
+		assert.Equal(t, http.StatusGone, sendJSON(t, router, "POST", "/late/invites/"+invite.Code+"/join", nil, nil))
+
+		var revoked models.ChatInvite
+		require.Equal(t, http.StatusCreated, sendJSON(t, router, "POST", "/owner"+groupPath+"/invites", nil, &revoked))
+		assert.Equal(t, http.StatusOK, sendJSON(t, router, "DELETE", "/owner"+groupPath+"/invites/"+uintToString(revoked.ID), nil, nil))
+		assert.Equal(t, http.StatusNotFound, sendJSON(t, router, "POST", "/late/invites/"+revoked.Code+"/join", nil, nil))
+
+		expired := models.ChatInvite{ChatID: chat.ID, Code: "expired-" + uintToString(chat.ID), CreatedBy: users["owner"].ID, ExpiresAt: time.Now().Add(-time.Hour)}
+		require.NoError(t, config.DB.Create(&expired).Error)
+		assert.Equal(t, http.StatusGone, sendJSON(t, router, "GET", "/late/invites/"+expired.Code, nil, nil))
+	})
+
+	t.Run("转让群主后原群主成为管理员", func(t *testing.T) {
+		assert.Equal(t, http.StatusForbidden, sendJSON(t, router, "POST", "/admin"+groupPath+"/transfer", models.TransferGroupRequest{UserID: users["admin"].ID}, nil))
+		assert.Equal(t, http.StatusBadRequest, sendJSON(t, router, "POST", "/owner"+groupPath+"/transfer", models.TransferGroupRequest{UserID: users["late"].ID}, nil))
+		require.Equal(t, http.StatusOK, sendJSON(t, router, "POST", "/owner"+groupPath+"/transfer", models.TransferGroupRequest{UserID: users["member"].ID}, nil))
+		current := roles(t, chat.ID)
+		assert.Equal(t, models.ChatRoleOwner, current["群聊member"])
+		assert.Equal(t, models.ChatRoleAdmin, current["群聊owner"])
+	})
+
+	t.Run("群主退出时由最早加入的管理员接任，最后一人退出时解散", func(t *testing.T) {
+		require.Equal(t, http.StatusOK, sendJSON(t, router, "POST", "/member"+groupPath+"/leave", nil, nil))
+		var reloaded models.Chat
+		require.NoError(t, config.DB.First(&reloaded, chat.ID).Error)
+		// 原群主比 admin 更早加入
+		assert.Equal(t, users["owner"].ID, *reloaded.OwnerID)
+		assert.Equal(t, models.ChatRoleOwner, roles(t, chat.ID)["群聊owner"])
+
+		for _, name := range []string{"admin", "guest", "owner"} {
+			require.Equal(t, http.StatusOK, sendJSON(t, router, "POST", "/"+name+groupPath+"/leave", nil, nil))
+		}
+		assert.Error(t, config.DB.First(&models.Chat{}, chat.ID).Error)
+		var invites int64
+		config.DB.Model(&models.ChatInvite{}).Where("chat_id = ?", chat.ID).Count(&invites)
+		assert.Zero(t, invites)
+	})
+
+	t.Run("多位参与者创建聊天时自动创建群聊，单聊仍然去重", func(t *testing.T) {
+		var group models.Chat
+		payload := map[string][]uint{"participant_ids": {users["guest"].ID, users["late"].ID}}
+		require.Equal(t, http.StatusCreated, sendJSON(t, router, "POST", "/owner/chats", payload, &group))
+		assert.Equal(t, models.ChatTypeGroup, group.Type)
+		assert.Equal(t, "群聊owner、群聊guest、群聊late", group.Name)
+
+		direct := map[string][]uint{"participant_ids": {users["late"].ID}}
+		assert.Equal(t, http.StatusCreated, sendJSON(t, router, "POST", "/guest/chats", direct, nil))
+		assert.Equal(t, http.StatusConflict, sendJSON(t, router, "POST", "/late/chats", map[string][]uint{"participant_ids": {users["guest"].ID}}, nil))
+		assert.Equal(t, http.StatusBadRequest, sendJSON(t, router, "POST", "/late/chats", map[string][]uint{"participant_ids": {users["late"].ID}}, nil))
+	})
+}
@@ -0,0 +1,376 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gymates-backend/config"
+	"gymates-backend/models"
+	"gymates-backend/services/geo"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// GymController 健身房控制器
+type GymController struct{}
+
+// NewGymController 创建健身房控制器
+func NewGymController() *GymController {
+	return &GymController{}
+}
+
+// CreateGym 创建健身房，附近已有同名健身房时返回409
+// POST /api/gyms
+func (gc *GymController) CreateGym(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Success: false,
+			Message: "用户未认证",
+			Error:   "User not authenticated",
+			Code:    http.StatusUnauthorized,
+		})
+		return
+	}
+	currentUser := user.(*models.User)
+
+	var req models.CreateGymRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Message: "请求参数错误",
+			Error:   err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	gym := models.Gym{
+		Name:      strings.TrimSpace(req.Name),
+		Address:   strings.TrimSpace(req.Address),
+		City:      strings.TrimSpace(req.City),
+		Latitude:  *req.Latitude,
+		Longitude: *req.Longitude,
+		Geohash:   geo.Encode(*req.Latitude, *req.Longitude, geo.GymPrecision),
+		CreatedBy: currentUser.ID,
+	}
+	if gym.Name == "" {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Message: "健身房名称不能为空",
+			Error:   "Gym name is required",
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	nearby, err := gymsNear(gym.Latitude, gym.Longitude, models.GymDuplicateRadiusKm)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Success: false,
+			Message: "创建健身房失败",
+			Error:   err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+	for _, existing := range nearby {
+		if strings.EqualFold(existing.Gym.Name, gym.Name) {
+			c.JSON(http.StatusConflict, models.ErrorResponse{
+				Success: false,
+				Message: "附近已有同名健身房",
+				Error:   fmt.Sprintf("Gym already exists: %d", existing.Gym.ID),
+				Code:    http.StatusConflict,
+			})
+			return
+		}
+	}
+
+	if err := config.DB.Create(&gym).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Success: false,
+			Message: "创建健身房失败",
+			Error:   err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.SuccessResponse{
+		Success: true,
+		Message: "创建健身房成功",
+		Data:    gym,
+	})
+}
+
+// GetNearbyGyms 附近的健身房，不传坐标时以自己的位置为中心
+// GET /api/gyms/nearby?lat=&lon=&radius_km=5&limit=20
+func (gc *GymController) GetNearbyGyms(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Success: false,
+			Message: "用户未认证",
+			Error:   "User not authenticated",
+			Code:    http.StatusUnauthorized,
+		})
+		return
+	}
+	currentUser := user.(*models.User)
+
+	radius, ok := parseRadius(c, models.DefaultNearbyRadiusKm, models.MaxNearbyRadiusKm)
+	if !ok {
+		return
+	}
+	limit := parseLimit(c, 20, 50)
+
+	var lat, lon float64
+	if c.Query("lat") != "" || c.Query("lon") != "" {
+		var latErr, lonErr error
+		lat, latErr = strconv.ParseFloat(c.Query("lat"), 64)
+		lon, lonErr = strconv.ParseFloat(c.Query("lon"), 64)
+		if latErr != nil || lonErr != nil || math.Abs(lat) > 90 || math.Abs(lon) > 180 {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Success: false,
+				Message: "无效的坐标",
+				Error:   "lat and lon must be valid coordinates",
+				Code:    http.StatusBadRequest,
+			})
+			return
+		}
+	} else {
+		var me models.User
+		if err := config.DB.First(&me, currentUser.ID).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+				Success: false,
+				Message: "获取用户信息失败",
+				Error:   err.Error(),
+				Code:    http.StatusInternalServerError,
+			})
+			return
+		}
+		if !requireUserLocation(c, me) {
+			return
+		}
+		lat, lon = *me.Latitude, *me.Longitude
+	}
+
+	gyms, err := gymsNear(lat, lon, radius)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Success: false,
+			Message: "获取附近健身房失败",
+			Error:   err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+	if len(gyms) > limit {
+		gyms = gyms[:limit]
+	}
+	list := make([]*models.Gym, len(gyms))
+	for i := range gyms {
+		list[i] = &gyms[i].Gym
+	}
+	attachGymMemberCounts(list...)
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "获取附近健身房成功",
+		Data: models.NearbyGymsResponse{
+			Gyms:     gyms,
+			RadiusKm: radius,
+		},
+	})
+}
+
+// GetGym 获取健身房详情
+// GET /api/gyms/:id
+func (gc *GymController) GetGym(c *gin.Context) {
+	gym, ok := findGym(c)
+	if !ok {
+		return
+	}
+	attachGymMemberCounts(&gym)
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "获取健身房成功",
+		Data:    gym,
+	})
+}
+
+// JoinGym 把健身房设为自己常去的健身房
+// POST /api/gyms/:id/join
+func (gc *GymController) JoinGym(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Success: false,
+			Message: "用户未认证",
+			Error:   "User not authenticated",
+			Code:    http.StatusUnauthorized,
+		})
+		return
+	}
+	currentUser := user.(*models.User)
+
+	gym, ok := findGym(c)
+	if !ok {
+		return
+	}
+
+	updates := map[string]interface{}{"gym_id": gym.ID, "home_gym": gym.Name}
+	if err := config.DB.Model(&models.User{}).Where("id = ?", currentUser.ID).Updates(updates).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Success: false,
+			Message: "绑定健身房失败",
+			Error:   err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+	attachGymMemberCounts(&gym)
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "绑定健身房成功",
+		Data:    gym,
+	})
+}
+
+// LeaveGym 解除健身房绑定
+// POST /api/gyms/:id/leave
+func (gc *GymController) LeaveGym(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Success: false,
+			Message: "用户未认证",
+			Error:   "User not authenticated",
+			Code:    http.StatusUnauthorized,
+		})
+		return
+	}
+	currentUser := user.(*models.User)
+
+	gym, ok := findGym(c)
+	if !ok {
+		return
+	}
+
+	result := config.DB.Model(&models.User{}).
+		Where("id = ? AND gym_id = ?", currentUser.ID, gym.ID).
+		Updates(map[string]interface{}{"gym_id": nil, "home_gym": ""})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Success: false,
+			Message: "解除绑定失败",
+			Error:   result.Error.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Message: "你没有绑定这家健身房",
+			Error:   "Gym is not attached",
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "解除绑定成功",
+	})
+}
+
+// findGym 按路径参数获取健身房，失败时已写入响应
+func findGym(c *gin.Context) (models.Gym, bool) {
+	var gym models.Gym
+	gymID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Message: "无效的健身房ID",
+			Error:   err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return gym, false
+	}
+	if err := config.DB.First(&gym, gymID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, models.ErrorResponse{
+				Success: false,
+				Message: "健身房不存在",
+				Error:   "Gym not found",
+				Code:    http.StatusNotFound,
+			})
+			return gym, false
+		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Success: false,
+			Message: "获取健身房失败",
+			Error:   err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return gym, false
+	}
+	return gym, true
+}
+
+// gymsNear 半径范围内的健身房，按距离从近到远
+func gymsNear(lat, lon, radiusKm float64) ([]models.NearbyGym, error) {
+	condition, args := geohashCondition(lat, lon, radiusKm)
+	var gyms []models.Gym
+	if err := config.DB.Where(condition, args...).Find(&gyms).Error; err != nil {
+		return nil, err
+	}
+
+	result := []models.NearbyGym{}
+	for _, gym := range gyms {
+		km := geo.DistanceKm(lat, lon, gym.Latitude, gym.Longitude)
+		if km <= radiusKm {
+			result = append(result, models.NearbyGym{Gym: gym, DistanceKm: math.Round(km*100) / 100})
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].DistanceKm != result[j].DistanceKm {
+			return result[i].DistanceKm < result[j].DistanceKm
+		}
+		return result[i].Gym.ID < result[j].Gym.ID
+	})
+	return result, nil
+}
+
+// attachGymMemberCounts 填充绑定了健身房的用户数
+func attachGymMemberCounts(gyms ...*models.Gym) {
+	if len(gyms) == 0 {
+		return
+	}
+	ids := make([]uint, len(gyms))
+	for i, gym := range gyms {
+		ids[i] = gym.ID
+	}
+	var rows []struct {
+		GymID uint
+		Count int64
+	}
+	config.DB.Model(&models.User{}).Select("gym_id, COUNT(*) AS count").
+		Where("gym_id IN ?", ids).Group("gym_id").Scan(&rows)
+	counts := make(map[uint]int64, len(rows))
+	for _, row := range rows {
+		counts[row.GymID] = row.Count
+	}
+	for _, gym := range gyms {
+		gym.MemberCount = counts[gym.ID]
+	}
+}
@@ -0,0 +1,155 @@
+package controllers
+
+import (
+	"net/http"
+	"testing"
+
+	"gymates-backend/config"
+	"gymates-backend/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestGyms 测试健身房、附近健身房和附近的人
+func TestGyms(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	setupTestDB(t)
+
+	const lat, lon = -33.8, 151.2
+	alice := models.User{Name: "健身房Alice", Email: "gym-alice@gymates.com", Password: "x"}
+	bob := models.User{Name: "健身房Bob", Email: "gym-bob@gymates.com", Password: "x"}
+	carol := models.User{Name: "健身房Carol", Email: "gym-carol@gymates.com", Password: "x"}
+	dave := models.User{Name: "健身房Dave", Email: "gym-dave@gymates.com", Password: "x"}
+	nomad := models.User{Name: "没有位置", Email: "gym-nomad@gymates.com", Password: "x"}
+	for _, user := range []*models.User{&alice, &bob, &carol, &dave, &nomad} {
+		require.NoError(t, config.DB.Create(user).Error)
+	}
+	createMateRelation(t, alice.ID, bob.ID, models.MateStatusAccepted)
+
+	gyms := NewGymController()
+	mates := NewMatesController()
+	router := gin.New()
+	for name, user := range map[string]*models.User{"alice": &alice, "bob": &bob, "carol": &carol, "dave": &dave, "nomad": &nomad} {
+		group := router.Group("/"+name, withTestUser(user))
+		group.PUT("/profile", NewAuthController().UpdateProfile)
+		group.POST("/gyms", gyms.CreateGym)
+		group.GET("/gyms/nearby", gyms.GetNearbyGyms)
+		group.GET("/gyms/:id", gyms.GetGym)
+		group.POST("/gyms/:id/join", gyms.JoinGym)
+		group.POST("/gyms/:id/leave", gyms.LeaveGym)
+		group.GET("/mates/nearby", mates.GetNearbyMates)
+	}
+
+	createGym := func(t *testing.T, name string, dLat float64) models.Gym {
+		var gym models.Gym
+		require.Equal(t, http.StatusCreated, sendJSON(t, router, "POST", "/alice/gyms", map[string]interface{}{
+			"name": name, "city": "悉尼", "latitude": lat + dLat, "longitude": lon,
+		}, &gym))
+		return gym
+	}
+	gymNames := func(response models.NearbyGymsResponse) []string {
+		names := []string{}
+		for _, gym := range response.Gyms {
+			names = append(names, gym.Gym.Name)
+		}
+		return names
+	}
+
+	// 位置通过资料接口设置，保存时粗化到两位小数
+	for name, dLat := range map[string]float64{"alice": 0.00123, "bob": 0.01, "carol": 0.03, "dave": 0.2} {
+		require.Equal(t, http.StatusOK, sendJSON(t, router, "PUT", "/"+name+"/profile", map[string]interface{}{"latitude": lat + dLat, "longitude": lon}, nil))
+	}
+	var stored models.User
+	require.NoError(t, config.DB.First(&stored, alice.ID).Error)
+	assert.Equal(t, lat, *stored.Latitude)
+	assert.NotEmpty(t, stored.Geohash)
+
+	near := createGym(t, "海港健身", 0.004)
+	mid := createGym(t, "城北健身", 0.03)
+	far := createGym(t, "远郊健身", 0.2)
+
+	t.Run("创建健身房", func(t *testing.T) {
+		assert.Equal(t, alice.ID, near.CreatedBy)
+		assert.Equal(t, http.StatusConflict, sendJSON(t, router, "POST", "/bob/gyms", map[string]interface{}{
+			"name": "海港健身", "latitude": lat + 0.005, "longitude": lon,
+		}, nil))
+		assert.Equal(t, http.StatusBadRequest, sendJSON(t, router, "POST", "/bob/gyms", map[string]interface{}{"name": "缺少坐标"}, nil))
+		assert.Equal(t, http.StatusBadRequest, sendJSON(t, router, "POST", "/bob/gyms", map[string]interface{}{
+			"name": "  ", "latitude": lat, "longitude": lon,
+		}, nil))
+	})
+
+	t.Run("附近的健身房", func(t *testing.T) {
+		var response models.NearbyGymsResponse
+		require.Equal(t, http.StatusOK, sendJSON(t, router, "GET", "/alice/gyms/nearby", nil, &response))
+		assert.Equal(t, []string{"海港健身", "城北健身"}, gymNames(response))
+		assert.InDelta(t, 0.44, response.Gyms[0].DistanceKm, 0.02)
+
+		require.Equal(t, http.StatusOK, sendJSON(t, router, "GET", "/alice/gyms/nearby?radius_km=30", nil, &response))
+		assert.Equal(t, []string{"海港健身", "城北健身", "远郊健身"}, gymNames(response))
+
+		// 以地图上的任意点为中心
+		require.Equal(t, http.StatusOK, sendJSON(t, router, "GET", "/nomad/gyms/nearby?lat=-33.6&lon=151.2&radius_km=1", nil, &response))
+		assert.Equal(t, []string{"远郊健身"}, gymNames(response))
+		assert.Equal(t, far.ID, response.Gyms[0].Gym.ID)
+
+		assert.Equal(t, http.StatusBadRequest, sendJSON(t, router, "GET", "/nomad/gyms/nearby", nil, nil))
+		assert.Equal(t, http.StatusBadRequest, sendJSON(t, router, "GET", "/alice/gyms/nearby?lat=100&lon=0", nil, nil))
+		assert.Equal(t, http.StatusBadRequest, sendJSON(t, router, "GET", "/alice/gyms/nearby?lat=-33.6", nil, nil))
+	})
+
+	t.Run("绑定和解除健身房", func(t *testing.T) {
+		var gym models.Gym
+		require.Equal(t, http.StatusOK, sendJSON(t, router, "POST", "/alice/gyms/"+uintToString(near.ID)+"/join", nil, &gym))
+		assert.Equal(t, int64(1), gym.MemberCount)
+		require.Equal(t, http.StatusOK, sendJSON(t, router, "POST", "/bob/gyms/"+uintToString(near.ID)+"/join", nil, &gym))
+		assert.Equal(t, int64(2), gym.MemberCount)
+
+		var joined models.User
+		require.NoError(t, config.DB.First(&joined, bob.ID).Error)
+		require.NotNil(t, joined.GymID)
+		assert.Equal(t, near.ID, *joined.GymID)
+		assert.Equal(t, "海港健身", joined.HomeGym)
+
+		assert.Equal(t, http.StatusBadRequest, sendJSON(t, router, "POST", "/carol/gyms/"+uintToString(near.ID)+"/leave", nil, nil))
+		require.Equal(t, http.StatusOK, sendJSON(t, router, "POST", "/carol/gyms/"+uintToString(mid.ID)+"/join", nil, nil))
+		require.Equal(t, http.StatusOK, sendJSON(t, router, "POST", "/carol/gyms/"+uintToString(mid.ID)+"/leave", nil, nil))
+		var left models.User
+		require.NoError(t, config.DB.First(&left, carol.ID).Error)
+		assert.Nil(t, left.GymID)
+		assert.Empty(t, left.HomeGym)
+
+		require.Equal(t, http.StatusOK, sendJSON(t, router, "GET", "/carol/gyms/"+uintToString(near.ID), nil, &gym))
+		assert.Equal(t, int64(2), gym.MemberCount)
+		assert.Equal(t, http.StatusNotFound, sendJSON(t, router, "GET", "/carol/gyms/999999", nil, nil))
+		assert.Equal(t, http.StatusNotFound, sendJSON(t, router, "POST", "/carol/gyms/999999/join", nil, nil))
+	})
+
+	t.Run("附近的人", func(t *testing.T) {
+		var response models.NearbyMatesResponse
+		require.Equal(t, http.StatusOK, sendJSON(t, router, "GET", "/alice/mates/nearby", nil, &response))
+		require.Len(t, response.Mates, 2)
+		assert.Equal(t, bob.ID, response.Mates[0].User.ID)
+		assert.True(t, response.Mates[0].IsMate)
+		assert.True(t, response.Mates[0].SameGym)
+		assert.Equal(t, 1.0, response.Mates[0].DistanceKm)
+		assert.Equal(t, carol.ID, response.Mates[1].User.ID)
+		assert.False(t, response.Mates[1].IsMate)
+		assert.Equal(t, 3.0, response.Mates[1].DistanceKm)
+
+		require.Equal(t, http.StatusOK, sendJSON(t, router, "GET", "/alice/mates/nearby?radius_km=30&limit=5", nil, &response))
+		assert.Len(t, response.Mates, 3)
+
+		// 返回中没有坐标
+		var raw map[string]interface{}
+		require.Equal(t, http.StatusOK, sendJSON(t, router, "GET", "/alice/mates/nearby", nil, &raw))
+		user := raw["mates"].([]interface{})[0].(map[string]interface{})["user"].(map[string]interface{})
+		assert.NotContains(t, user, "latitude")
+		assert.NotContains(t, user, "geohash")
+
+		assert.Equal(t, http.StatusBadRequest, sendJSON(t, router, "GET", "/nomad/mates/nearby", nil, nil))
+		assert.Equal(t, http.StatusBadRequest, sendJSON(t, router, "GET", "/alice/mates/nearby?radius_km=0", nil, nil))
+	})
+}
@@ -0,0 +1,322 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"gymates-backend/config"
+	"gymates-backend/models"
+	"gymates-backend/services"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// InjuryController 伤病/身体限制控制器
+type InjuryController struct{}
+
+// NewInjuryController 创建伤病控制器
+func NewInjuryController() *InjuryController {
+	return &InjuryController{}
+}
+
+// GetInjuries 获取当前用户的伤病记录
+// GET /api/training/injuries?active=true
+func (ic *InjuryController) GetInjuries(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Success: false,
+			Message: "用户未认证",
+			Error:   "User not authenticated",
+			Code:    http.StatusUnauthorized,
+		})
+		return
+	}
+	currentUser := user.(*models.User)
+
+	var injuries []models.Injury
+	if c.Query("active") == "true" {
+		injuries = activeInjuries(config.DB, currentUser.ID)
+	} else if err := config.DB.Where("user_id = ?", currentUser.ID).
+		Order("start_date DESC, id DESC").Find(&injuries).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Success: false,
+			Message: "获取伤病记录失败",
+			Error:   err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "获取伤病记录成功",
+		Data:    injuries,
+	})
+}
+
+// CreateInjury 新增伤病记录
+// POST /api/training/injuries
+func (ic *InjuryController) CreateInjury(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Success: false,
+			Message: "用户未认证",
+			Error:   "User not authenticated",
+			Code:    http.StatusUnauthorized,
+		})
+		return
+	}
+
+	req, ok := bindInjuryRequest(c)
+	if !ok {
+		return
+	}
+	currentUser := user.(*models.User)
+
+	injury := models.Injury{
+		UserID:     currentUser.ID,
+		BodyRegion: req.BodyRegion,
+		Severity:   req.Severity,
+		StartDate:  req.StartDate,
+		EndDate:    req.EndDate,
+		Notes:      req.Notes,
+	}
+	if err := config.DB.Create(&injury).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Success: false,
+			Message: "新增伤病记录失败",
+			Error:   err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.SuccessResponse{
+		Success: true,
+		Message: "新增伤病记录成功",
+		Data:    injury,
+	})
+}
+
+// UpdateInjury 更新伤病记录（如调整严重程度或填写恢复日期）
+// PUT /api/training/injuries/:id
+func (ic *InjuryController) UpdateInjury(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Success: false,
+			Message: "用户未认证",
+			Error:   "User not authenticated",
+			Code:    http.StatusUnauthorized,
+		})
+		return
+	}
+	currentUser := user.(*models.User)
+
+	injury, ok := findOwnedInjury(c, currentUser.ID)
+	if !ok {
+		return
+	}
+	req, ok := bindInjuryRequest(c)
+	if !ok {
+		return
+	}
+
+	// 使用 map 更新，允许清空恢复日期和备注
+	if err := config.DB.Model(&injury).Updates(map[string]interface{}{
+		"body_region": req.BodyRegion,
+		"severity":    req.Severity,
+		"start_date":  req.StartDate,
+		"end_date":    req.EndDate,
+		"notes":       req.Notes,
+	}).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Success: false,
+			Message: "更新伤病记录失败",
+			Error:   err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+	if err := config.DB.First(&injury, injury.ID).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Success: false,
+			Message: "更新伤病记录失败",
+			Error:   err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "更新伤病记录成功",
+		Data:    injury,
+	})
+}
+
+// DeleteInjury 删除伤病记录
+// DELETE /api/training/injuries/:id
+func (ic *InjuryController) DeleteInjury(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Success: false,
+			Message: "用户未认证",
+			Error:   "User not authenticated",
+			Code:    http.StatusUnauthorized,
+		})
+		return
+	}
+	currentUser := user.(*models.User)
+
+	injury, ok := findOwnedInjury(c, currentUser.ID)
+	if !ok {
+		return
+	}
+
+	if err := config.DB.Delete(&injury).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Success: false,
+			Message: "删除伤病记录失败",
+			Error:   err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "删除伤病记录成功",
+	})
+}
+
+// GetRestrictions 获取当前伤病下动作库中被排除或降权的动作及原因
+// GET /api/training/injuries/restrictions
+func (ic *InjuryController) GetRestrictions(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Success: false,
+			Message: "用户未认证",
+			Error:   "User not authenticated",
+			Code:    http.StatusUnauthorized,
+		})
+		return
+	}
+	currentUser := user.(*models.User)
+
+	var library []models.ExerciseLibrary
+	if err := config.DB.Order("part ASC, id ASC").Find(&library).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Success: false,
+			Message: "获取动作限制失败",
+			Error:   err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	filter := userInjuryFilter(config.DB, currentUser.ID)
+	restrictions := []models.ExerciseRestriction{}
+	for _, entry := range library {
+		if restriction, ok := filter.Assess(entry); ok {
+			restrictions = append(restrictions, restriction)
+		}
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "获取动作限制成功",
+		Data:    restrictions,
+	})
+}
+
+// bindInjuryRequest 解析并校验伤病请求，失败时已写入响应
+func bindInjuryRequest(c *gin.Context) (models.SaveInjuryRequest, bool) {
+	var req models.SaveInjuryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Message: "请求参数错误",
+			Error:   err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return req, false
+	}
+
+	err := validateInjuryDates(req.StartDate, req.EndDate)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Message: "日期格式错误",
+			Error:   err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return req, false
+	}
+	return req, true
+}
+
+// validateInjuryDates 校验开始/恢复日期格式（YYYY-MM-DD）以及先后顺序
+func validateInjuryDates(startDate, endDate string) error {
+	if _, err := time.Parse(models.DateLayout, startDate); err != nil {
+		return errors.New("start_date must be YYYY-MM-DD")
+	}
+	if endDate == "" {
+		return nil
+	}
+	if _, err := time.Parse(models.DateLayout, endDate); err != nil {
+		return errors.New("end_date must be YYYY-MM-DD")
+	}
+	if endDate < startDate {
+		return errors.New("end_date must not be before start_date")
+	}
+	return nil
+}
+
+// findOwnedInjury 查找当前用户的伤病记录，失败时已写入响应
+func findOwnedInjury(c *gin.Context, userID uint) (models.Injury, bool) {
+	var injury models.Injury
+	injuryID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Message: "无效的伤病记录ID",
+			Error:   err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return injury, false
+	}
+
+	if err := config.DB.Where("id = ? AND user_id = ?", uint(injuryID), userID).First(&injury).Error; err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Success: false,
+			Message: "伤病记录不存在或无权限",
+			Error:   "Injury not found or no permission",
+			Code:    http.StatusNotFound,
+		})
+		return injury, false
+	}
+	return injury, true
+}
+
+// activeInjuries 获取用户今天（按用户时区）生效中的伤病
+func activeInjuries(db *gorm.DB, userID uint) []models.Injury {
+	today := models.LocalDate(time.Now(), loadUserLocation(userID))
+
+	var injuries []models.Injury
+	db.Where("user_id = ? AND start_date <= ? AND (end_date = '' OR end_date IS NULL OR end_date >= ?)", userID, today, today).
+		Order("start_date ASC, id ASC").Find(&injuries)
+	return injuries
+}
+
+// userInjuryFilter 基于用户生效中的伤病创建动作过滤器
+func userInjuryFilter(db *gorm.DB, userID uint) *services.InjuryFilter {
+	return services.NewInjuryFilter(activeInjuries(db, userID))
+}
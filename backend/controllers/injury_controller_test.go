@@ -0,0 +1,179 @@
+package controllers
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"gymates-backend/config"
+	"gymates-backend/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestInjury 测试伤病记录以及推荐、AI教练对禁忌动作的排除和降权
+func TestInjury(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	setupTestDB(t)
+
+	var user models.User
+	require.NoError(t, config.DB.First(&user, 3).Error)
+	loc := loadUserLocation(user.ID)
+	daysAgo := func(days int) string {
+		return models.LocalDate(time.Now().AddDate(0, 0, -days), loc)
+	}
+
+	controller := NewInjuryController()
+	router := gin.New()
+	router.GET("/api/training/injuries", withTestUser(&user), controller.GetInjuries)
+	router.POST("/api/training/injuries", withTestUser(&user), controller.CreateInjury)
+	router.GET("/api/training/injuries/restrictions", withTestUser(&user), controller.GetRestrictions)
+	router.PUT("/api/training/injuries/:id", withTestUser(&user), controller.UpdateInjury)
+	router.GET("/api/training/ai/recommend", NewAIRecommendationController().GetAIRecommendation)
+	router.GET("/api/training/ai/training", NewAITrainingController().GetAIRecommendation)
+
+	library := map[uint]models.ExerciseLibrary{}
+	var entries []models.ExerciseLibrary
+	require.NoError(t, config.DB.Find(&entries).Error)
+	for _, entry := range entries {
+		library[entry.ID] = entry
+	}
+	isPush := func(id uint) bool {
+		pattern := library[id].MovementPattern
+		return pattern == models.PatternHorizontalPush || pattern == models.PatternVerticalPush
+	}
+
+	t.Run("参数校验", func(t *testing.T) {
+		tests := []struct {
+			name    string
+			request models.SaveInjuryRequest
+		}{
+			{name: "未知部位", request: models.SaveInjuryRequest{BodyRegion: "tail", Severity: "mild", StartDate: daysAgo(1)}},
+			{name: "未知严重程度", request: models.SaveInjuryRequest{BodyRegion: "knee", Severity: "fatal", StartDate: daysAgo(1)}},
+			{name: "日期格式错误", request: models.SaveInjuryRequest{BodyRegion: "knee", Severity: "mild", StartDate: "2024/01/01"}},
+			{name: "恢复日期早于开始日期", request: models.SaveInjuryRequest{BodyRegion: "knee", Severity: "mild", StartDate: daysAgo(1), EndDate: daysAgo(3)}},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				assert.Equal(t, http.StatusBadRequest, sendJSON(t, router, "POST", "/api/training/injuries", tt.request, nil))
+			})
+		}
+	})
+
+	var shoulder models.Injury
+	require.Equal(t, http.StatusCreated, sendJSON(t, router, "POST", "/api/training/injuries", models.SaveInjuryRequest{
+		BodyRegion: models.BodyRegionShoulder, Severity: models.InjurySeverityModerate, StartDate: daysAgo(10), Notes: "肩袖拉伤",
+	}, &shoulder))
+	// 已恢复的伤病不再生效
+	require.Equal(t, http.StatusCreated, sendJSON(t, router, "POST", "/api/training/injuries", models.SaveInjuryRequest{
+		BodyRegion: models.BodyRegionKnee, Severity: models.InjurySeveritySevere, StartDate: daysAgo(60), EndDate: daysAgo(30),
+	}, nil))
+
+	t.Run("只返回生效中的伤病", func(t *testing.T) {
+		var all, active []models.Injury
+		require.Equal(t, http.StatusOK, sendJSON(t, router, "GET", "/api/training/injuries", nil, &all))
+		require.Equal(t, http.StatusOK, sendJSON(t, router, "GET", "/api/training/injuries?active=true", nil, &active))
+		assert.Len(t, all, 2)
+		require.Len(t, active, 1)
+		assert.Equal(t, shoulder.ID, active[0].ID)
+	})
+
+	t.Run("动作限制及原因", func(t *testing.T) {
+		var restrictions []models.ExerciseRestriction
+		require.Equal(t, http.StatusOK, sendJSON(t, router, "GET", "/api/training/injuries/restrictions", nil, &restrictions))
+		byName := map[string]models.ExerciseRestriction{}
+		for _, restriction := range restrictions {
+			byName[restriction.Name] = restriction
+			assert.Equal(t, shoulder.ID, restriction.InjuryID)
+			assert.Contains(t, restriction.Reason, "肩部中度伤病")
+		}
+
+		tests := []struct {
+			name   string
+			action string
+		}{
+			{name: "平板卧推", action: models.RestrictionExclude},
+			{name: "肩推", action: models.RestrictionExclude},
+			{name: "侧平举", action: models.RestrictionDownWeight},
+			{name: "深蹲"},
+			{name: "引体向上"},
+		}
+		for _, tt := range tests {
+			restriction, ok := byName[tt.name]
+			assert.Equal(t, tt.action != "", ok, tt.name)
+			assert.Equal(t, tt.action, restriction.Action, tt.name)
+		}
+		assert.Contains(t, byName["平板卧推"].Reason, "动作模式为水平推")
+	})
+
+	t.Run("按训练日推荐时排除禁忌动作", func(t *testing.T) {
+		var recommendation models.AIRecommendationResponse
+		require.Equal(t, http.StatusOK, sendJSON(t, router, "GET", "/api/training/ai/recommend?user_id=3&day=Monday", nil, &recommendation))
+
+		for _, part := range recommendation.Parts {
+			for _, exercise := range part.Exercises {
+				assert.False(t, isPush(exercise.ExerciseID), exercise.Name)
+			}
+		}
+		downWeighted := map[uint]bool{}
+		excluded := 0
+		for _, restriction := range recommendation.Restrictions {
+			assert.NotEmpty(t, restriction.Reason)
+			if restriction.Action == models.RestrictionExclude {
+				excluded++
+			} else {
+				downWeighted[restriction.ExerciseID] = true
+			}
+		}
+		assert.Greater(t, excluded, 0)
+		for _, part := range recommendation.Parts {
+			for _, exercise := range part.Exercises {
+				if downWeighted[exercise.ExerciseID] {
+					assert.Contains(t, exercise.Notes, "已降低强度")
+				}
+			}
+		}
+	})
+
+	t.Run("按目标推荐时排除禁忌动作", func(t *testing.T) {
+		var recommendation models.AITrainingRecommendation
+		require.Equal(t, http.StatusOK, sendJSON(t, router, "GET", "/api/training/ai/training?user_id=3", nil, &recommendation))
+		require.NotEmpty(t, recommendation.Exercises)
+		for _, exercise := range recommendation.Exercises {
+			assert.False(t, isPush(exercise.ExerciseID), exercise.Name)
+		}
+	})
+
+	t.Run("AI教练回复和系统提示词包含伤病限制", func(t *testing.T) {
+		reply := NewAITrainingController().generateAIResponse("我肩膀有点疼，今天还能练吗", user.ID)
+		assert.Contains(t, reply, "肩部中度伤病")
+		assert.Contains(t, reply, "水平推")
+
+		prompt := NewAICoachController().buildSystemPrompt(user.ID, nil)
+		assert.Contains(t, prompt, "肩部中度伤病（自"+daysAgo(10)+"起）")
+		assert.Contains(t, prompt, "肩袖拉伤")
+		assert.NotContains(t, prompt, "膝盖")
+
+		assert.NotContains(t, NewAICoachController().buildSystemPrompt(2, nil), "伤病与身体限制")
+	})
+
+	t.Run("降为轻度后只降权不排除", func(t *testing.T) {
+		var updated models.Injury
+		require.Equal(t, http.StatusOK, sendJSON(t, router, "PUT", "/api/training/injuries/"+uintToString(shoulder.ID), models.SaveInjuryRequest{
+			BodyRegion: models.BodyRegionShoulder, Severity: models.InjurySeverityMild, StartDate: shoulder.StartDate,
+		}, &updated))
+		assert.Equal(t, models.InjurySeverityMild, updated.Severity)
+		assert.Empty(t, updated.Notes)
+
+		var restrictions []models.ExerciseRestriction
+		require.Equal(t, http.StatusOK, sendJSON(t, router, "GET", "/api/training/injuries/restrictions", nil, &restrictions))
+		require.NotEmpty(t, restrictions)
+		for _, restriction := range restrictions {
+			assert.Equal(t, models.RestrictionDownWeight, restriction.Action, restriction.Name)
+		}
+		assert.True(t, strings.HasPrefix(restrictions[0].Reason, "肩部轻度伤病"))
+	})
+}
@@ -0,0 +1,152 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gymates-backend/config"
+	"gymates-backend/models"
+	"gymates-backend/services"
+)
+
+// transitionMate 在事务中锁住两人的关系行并执行操作，两人之间还没有关系时新建
+//
+// 两个请求同时为同一对用户新建关系时，唯一索引会让其中一个失败，失败的一方重试一次即可读到另一方写入的行。
+func transitionMate(actorID, otherID uint, action services.MateAction) (models.MateRelation, error) {
+	var relation models.MateRelation
+	attempt := func() error {
+		return config.DB.Transaction(func(tx *gorm.DB) error {
+			relation = models.MateRelation{}
+			low, high := models.MatePair(actorID, otherID)
+			err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+				Where("user_low_id = ? AND user_high_id = ?", low, high).
+				First(&relation).Error
+			if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+				return err
+			}
+			if err := services.TransitionMate(&relation, actorID, otherID, action, time.Now()); err != nil {
+				return err
+			}
+			return tx.Save(&relation).Error
+		})
+	}
+
+	err := attempt()
+	if err != nil && relation.ID == 0 && !isMateTransitionError(err) {
+		err = attempt()
+	}
+	return relation, err
+}
+
+// isMateTransitionError 是否为状态机拒绝的操作
+func isMateTransitionError(err error) bool {
+	for _, target := range []error{
+		services.ErrMateSelf, services.ErrMateAlreadyRequested, services.ErrMateAlreadyMates,
+		services.ErrMateBlocked, services.ErrMateRequestCooldown, services.ErrMateNoPendingRequest,
+		services.ErrMateNotMates, services.ErrMateNotBlocked, services.ErrMateUnknownAction,
+	} {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// writeMateError 把状态机错误转换为对应的HTTP状态码
+func writeMateError(c *gin.Context, message string, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, services.ErrMateSelf):
+		status, message = http.StatusBadRequest, "不能添加自己为搭子"
+	case errors.Is(err, services.ErrMateAlreadyRequested):
+		status, message = http.StatusConflict, "已经发送过搭子请求"
+	case errors.Is(err, services.ErrMateAlreadyMates):
+		status, message = http.StatusConflict, "你们已经是搭子了"
+	case errors.Is(err, services.ErrMateBlocked):
+		status, message = http.StatusForbidden, "无法与该用户建立搭子关系"
+	case errors.Is(err, services.ErrMateRequestCooldown):
+		status, message = http.StatusTooManyRequests, "对方刚拒绝了你的请求，请稍后再试"
+	case errors.Is(err, services.ErrMateNoPendingRequest):
+		status, message = http.StatusNotFound, "搭子请求不存在"
+	case errors.Is(err, services.ErrMateNotMates):
+		status, message = http.StatusNotFound, "你们还不是搭子"
+	case errors.Is(err, services.ErrMateNotBlocked):
+		status, message = http.StatusNotFound, "没有拉黑该用户"
+	}
+	c.JSON(status, models.ErrorResponse{
+		Success: false,
+		Message: message,
+		Error:   err.Error(),
+		Code:    status,
+	})
+}
+
+// mateRelationsQuery userID 参与的、处于指定状态的关系
+func mateRelationsQuery(userID uint, statuses ...string) *gorm.DB {
+	return config.DB.Model(&models.MateRelation{}).
+		Where("(user_low_id = ? OR user_high_id = ?) AND status IN ?", userID, userID, statuses)
+}
+
+// relatedUserIDs 与 userID 处于指定状态的另一方
+func relatedUserIDs(userID uint, statuses ...string) ([]uint, error) {
+	var relations []models.MateRelation
+	if err := mateRelationsQuery(userID, statuses...).
+		Select("user_low_id", "user_high_id").Find(&relations).Error; err != nil {
+		return nil, err
+	}
+	ids := make([]uint, len(relations))
+	for i, relation := range relations {
+		ids[i] = relation.Other(userID)
+	}
+	return ids, nil
+}
+
+// countMates 搭子数量
+func countMates(userID uint) int64 {
+	var count int64
+	mateRelationsQuery(userID, models.MateStatusAccepted).Count(&count)
+	return count
+}
+
+// mateRequestViews 把关系转换为请求视图，发起方为 user，接收方为 mate
+func mateRequestViews(relations []models.MateRelation) ([]models.MateRequestView, error) {
+	var ids []uint
+	for _, relation := range relations {
+		ids = append(ids, relation.UserLowID, relation.UserHighID)
+	}
+	users := make(map[uint]models.User)
+	if len(ids) > 0 {
+		var list []models.User
+		if err := config.DB.Where("id IN ?", ids).Find(&list).Error; err != nil {
+			return nil, err
+		}
+		for _, user := range list {
+			users[user.ID] = user
+		}
+	}
+
+	views := make([]models.MateRequestView, len(relations))
+	for i, relation := range relations {
+		recipient := relation.Other(relation.RequesterID)
+		view := models.MateRequestView{
+			ID:          relation.ID,
+			UserID:      relation.RequesterID,
+			User:        users[relation.RequesterID],
+			MateID:      recipient,
+			Mate:        users[recipient],
+			Status:      relation.Status,
+			RespondedAt: relation.RespondedAt,
+			CreatedAt:   relation.CreatedAt,
+			UpdatedAt:   relation.UpdatedAt,
+		}
+		if relation.RequestedAt != nil {
+			view.CreatedAt = *relation.RequestedAt
+		}
+		views[i] = view
+	}
+	return views, nil
+}
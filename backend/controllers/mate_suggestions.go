@@ -0,0 +1,278 @@
+package controllers
+
+import (
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gymates-backend/config"
+	"gymates-backend/models"
+	"gymates-backend/services/geo"
+	"gymates-backend/services/matching"
+)
+
+// 搭子推荐的候选人数上限、最低匹配分和推断训练时段参考的天数
+const (
+	maxSuggestionCandidates = 500
+	minSuggestionScore      = 10
+	slotInferenceDays       = 60
+)
+
+// GetSuggestions 按距离、目标、训练水平、训练时段和健身房推荐搭子
+func (mc *MatesController) GetSuggestions(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Success: false,
+			Message: "用户未认证",
+			Error:   "User not authenticated",
+			Code:    http.StatusUnauthorized,
+		})
+		return
+	}
+
+	currentUser := user.(*models.User)
+
+	limit := parseLimit(c, 10, 50)
+	radius, ok := parseRadius(c, models.DefaultMateRadiusKm, models.MaxMateRadiusKm)
+	if !ok {
+		return
+	}
+
+	var me models.User
+	if err := config.DB.First(&me, currentUser.ID).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Success: false,
+			Message: "获取用户信息失败",
+			Error:   err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	candidates, err := suggestionCandidates(me, radius)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Success: false,
+			Message: "获取搭子推荐失败",
+			Error:   err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	inferred := inferTrainingSlots(append([]models.User{me}, candidates...))
+	myProfile := matching.NewProfile(me, inferred[me.ID])
+
+	suggestions := make([]models.MateSuggestion, 0, len(candidates))
+	for _, candidate := range candidates {
+		match := matching.Score(myProfile, matching.NewProfile(candidate, inferred[candidate.ID]), radius)
+		if !match.InRange || match.Score < minSuggestionScore {
+			continue
+		}
+		suggestions = append(suggestions, models.MateSuggestion{
+			User:       candidate,
+			Score:      match.Score,
+			DistanceKm: match.DistanceKm,
+			Reasons:    match.Reasons,
+		})
+	}
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		a, b := suggestions[i], suggestions[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		if a.DistanceKm != nil && b.DistanceKm != nil && *a.DistanceKm != *b.DistanceKm {
+			return *a.DistanceKm < *b.DistanceKm
+		}
+		return a.User.ID < b.User.ID
+	})
+	if len(suggestions) > limit {
+		suggestions = suggestions[:limit]
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "获取搭子推荐成功",
+		Data: models.MateSuggestionsResponse{
+			Suggestions: suggestions,
+			RadiusKm:    radius,
+		},
+	})
+}
+
+// mateExclusions 不参与推荐的用户：自己，已是搭子、有待处理请求或任一方拉黑的用户，以及静音的用户
+func mateExclusions(userID uint) ([]uint, error) {
+	ids, err := relatedUserIDs(userID, models.MateStatusPending, models.MateStatusAccepted, models.MateStatusBlocked)
+	if err != nil {
+		return nil, err
+	}
+	return append(append(ids, mutedUserIDs(userID)...), userID), nil
+}
+
+// suggestionCandidates 预筛选候选人
+//
+// 有坐标时只取 geohash 覆盖范围内的用户，以及没有坐标但同城或同健身房的用户；
+// 没有坐标时按最近活跃取候选人，由评分决定排序。资料对自己不可见的用户不参与推荐。
+func suggestionCandidates(me models.User, radiusKm float64) ([]models.User, error) {
+	excluded, err := mateExclusions(me.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	query := config.DB.Where("id NOT IN ?", excluded).
+		Where("id NOT IN (?)", privacyHiddenUsers("profile_visibility", me.ID))
+	if me.Latitude != nil && me.Longitude != nil {
+		condition, args := geohashCondition(*me.Latitude, *me.Longitude, radiusKm)
+		nearby := config.DB.Where(condition, args...)
+		if me.Location != "" {
+			nearby = nearby.Or("latitude IS NULL AND location = ?", me.Location)
+		}
+		if me.HomeGym != "" {
+			nearby = nearby.Or("home_gym = ?", me.HomeGym)
+		}
+		query = query.Where(nearby)
+	}
+
+	var users []models.User
+	err = query.Order("updated_at DESC").Limit(maxSuggestionCandidates).Find(&users).Error
+	return users, err
+}
+
+// inferTrainingSlots 为没有填写训练时段的用户，按最近的训练开始时间推断常训练的时段
+func inferTrainingSlots(users []models.User) map[uint][]string {
+	locations := make(map[uint]*time.Location)
+	var ids []uint
+	for _, user := range users {
+		if len(user.TrainingSlots()) == 0 {
+			locations[user.ID] = user.TimeLocation()
+			ids = append(ids, user.ID)
+		}
+	}
+	inferred := make(map[uint][]string)
+	if len(ids) == 0 {
+		return inferred
+	}
+
+	var sessions []models.WorkoutSession
+	config.DB.Select("user_id", "start_time").
+		Where("user_id IN ? AND start_time >= ?", ids, time.Now().AddDate(0, 0, -slotInferenceDays)).
+		Find(&sessions)
+	starts := make(map[uint][]time.Time)
+	for _, session := range sessions {
+		starts[session.UserID] = append(starts[session.UserID], session.StartTime)
+	}
+	for userID, times := range starts {
+		inferred[userID] = matching.InferSlots(times, locations[userID])
+	}
+	return inferred
+}
+
+// GetNearbyMates 自己位置附近的人，距离取整公里
+// GET /api/mates/nearby?radius_km=5&limit=20
+func (mc *MatesController) GetNearbyMates(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Success: false,
+			Message: "用户未认证",
+			Error:   "User not authenticated",
+			Code:    http.StatusUnauthorized,
+		})
+		return
+	}
+
+	currentUser := user.(*models.User)
+
+	radius, ok := parseRadius(c, models.DefaultNearbyRadiusKm, models.MaxNearbyRadiusKm)
+	if !ok {
+		return
+	}
+	limit := parseLimit(c, 20, 50)
+
+	var me models.User
+	if err := config.DB.First(&me, currentUser.ID).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Success: false,
+			Message: "获取用户信息失败",
+			Error:   err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+	// 只能以自己的位置为中心搜索，避免从多个点测距推算他人位置
+	if !requireUserLocation(c, me) {
+		return
+	}
+
+	// 拉黑的用户互相不可见，静音的用户和资料对自己不可见的用户也不出现
+	var relations []models.MateRelation
+	var users []models.User
+	err := mateRelationsQuery(me.ID, models.MateStatusAccepted, models.MateStatusBlocked).Find(&relations).Error
+	isMate := make(map[uint]bool)
+	excluded := append(mutedUserIDs(me.ID), me.ID)
+	for _, relation := range relations {
+		if relation.Status == models.MateStatusBlocked {
+			excluded = append(excluded, relation.Other(me.ID))
+		} else {
+			isMate[relation.Other(me.ID)] = true
+		}
+	}
+	if err == nil {
+		condition, args := geohashCondition(*me.Latitude, *me.Longitude, radius)
+		err = config.DB.Where("id NOT IN ?", excluded).
+			Where("id NOT IN (?)", privacyHiddenUsers("profile_visibility", me.ID)).
+			Where(condition, args...).Find(&users).Error
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Success: false,
+			Message: "获取附近的人失败",
+			Error:   err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	type candidate struct {
+		models.NearbyMate
+		km float64
+	}
+	var nearby []candidate
+	for _, other := range users {
+		if other.Latitude == nil || other.Longitude == nil {
+			continue
+		}
+		km := geo.DistanceKm(*me.Latitude, *me.Longitude, *other.Latitude, *other.Longitude)
+		if km > radius {
+			continue
+		}
+		nearby = append(nearby, candidate{NearbyMate: models.NearbyMate{
+			User:       other,
+			DistanceKm: geo.ApproxKm(km),
+			IsMate:     isMate[other.ID],
+			SameGym:    me.GymID != nil && other.GymID != nil && *me.GymID == *other.GymID,
+		}, km: km})
+	}
+	sort.SliceStable(nearby, func(i, j int) bool {
+		if nearby[i].km != nearby[j].km {
+			return nearby[i].km < nearby[j].km
+		}
+		return nearby[i].User.ID < nearby[j].User.ID
+	})
+
+	mates := make([]models.NearbyMate, 0, limit)
+	for i := 0; i < len(nearby) && i < limit; i++ {
+		mates = append(mates, nearby[i].NearbyMate)
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "获取附近的人成功",
+		Data: models.NearbyMatesResponse{
+			Mates:    mates,
+			RadiusKm: radius,
+		},
+	})
+}
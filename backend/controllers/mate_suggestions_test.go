@@ -0,0 +1,223 @@
+package controllers
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"gymates-backend/config"
+	"gymates-backend/models"
+	"gymates-backend/services/geo"
+	"gymates-backend/services/matching"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestMateMatching 测试搭子匹配的画像归一和评分
+func TestMateMatching(t *testing.T) {
+	t.Run("训练水平", func(t *testing.T) {
+		tests := []struct {
+			experience string
+			level      int
+		}{
+			{"初级", 1}, {"中级", 2}, {"高级", 3}, {"健身新手", 1},
+			{"半年", 0}, {"0.5年", 1}, {"3年", 2}, {"练了5年", 3}, {"", 0},
+		}
+		for _, tt := range tests {
+			assert.Equal(t, tt.level, matching.ExperienceLevel(tt.experience), tt.experience)
+		}
+	})
+
+	t.Run("健身目标", func(t *testing.T) {
+		assert.Equal(t, "增肌", matching.GoalCategory("增肌增重"))
+		assert.Equal(t, "减脂", matching.GoalCategory("减肥"))
+		assert.Equal(t, "塑形", matching.GoalCategory("改善体态"))
+		assert.Equal(t, "耐力", matching.GoalCategory("跑马拉松"))
+		assert.Equal(t, "", matching.GoalCategory("随便练练"))
+	})
+
+	t.Run("训练时段", func(t *testing.T) {
+		assert.Equal(t, []string{"morning", "evening"}, matching.NormalizeSlots([]string{"evening", "unknown", "morning", "evening"}))
+
+		shanghai := models.LoadLocation("Asia/Shanghai")
+		at := func(day, hour int) time.Time { return time.Date(2026, 3, day, hour, 0, 0, 0, shanghai) }
+		starts := []time.Time{at(1, 19), at(2, 20), at(3, 19), at(4, 7), at(5, 12), at(6, 3)}
+		// 晚上3次；清晨、中午各1次不足2次；凌晨3点不属于任何时段
+		assert.Equal(t, []string{"evening"}, matching.InferSlots(starts, shanghai))
+		// 换成UTC后 19点变成11点
+		assert.Equal(t, []string{"morning"}, matching.InferSlots(starts, time.UTC))
+	})
+
+	t.Run("评分和理由", func(t *testing.T) {
+		lat, lon := 30.0, 120.0
+		nearLat := lat + 0.0045
+		me := matching.Profile{Latitude: &lat, Longitude: &lon, City: "杭州", Goal: "增肌", Level: 2,
+			Slots: []string{"evening", "night"}, Gym: "乐刻"}
+		other := matching.Profile{Latitude: &nearLat, Longitude: &lon, Goal: "增肌", Level: 2,
+			Slots: []string{"evening"}, Gym: "乐刻"}
+
+		match := matching.Score(me, other, 20)
+		assert.True(t, match.InRange)
+		require.NotNil(t, match.DistanceKm)
+		// 对外的距离取整公里
+		assert.Equal(t, 1.0, *match.DistanceKm)
+		// 0.3*(1-0.5/20) + 0.25 + 0.2*0.5 + 0.15 + 0.1
+		assert.InDelta(t, 89.25, match.Score, 0.1)
+		texts := []string{}
+		for _, reason := range match.Reasons {
+			texts = append(texts, reason.Text)
+		}
+		assert.Equal(t, []string{"距离不到1公里", "健身目标都是增肌", "训练水平相同（中级）", "都常在晚上训练", "在同一家健身房：乐刻"}, texts)
+
+		// 超出范围
+		farLat := lat + 0.45
+		other.Latitude = &farLat
+		match = matching.Score(me, other, 20)
+		assert.False(t, match.InRange)
+		assert.Equal(t, 50.0, *match.DistanceKm)
+
+		// 没有坐标时同城得一半距离分，相近目标和相差一级各得一半
+		stranger := matching.Profile{City: "杭州", Goal: "力量", Level: 3}
+		match = matching.Score(me, stranger, 20)
+		assert.True(t, match.InRange)
+		assert.Nil(t, match.DistanceKm)
+		assert.InDelta(t, 35, match.Score, 0.01)
+		assert.Equal(t, "同在杭州", match.Reasons[0].Text)
+		assert.Equal(t, models.MatchFactorGoal, match.Reasons[1].Factor)
+		assert.Equal(t, "健身目标相近（增肌 / 力量）", match.Reasons[1].Text)
+
+		assert.Equal(t, 0.0, matching.Score(me, matching.Profile{}, 20).Score)
+	})
+
+	t.Run("绑定的健身房优先于名称", func(t *testing.T) {
+		me := matching.Profile{GymID: 1, Gym: "乐刻"}
+		assert.Equal(t, models.MatchFactorGym, matching.Score(me, matching.Profile{GymID: 1, Gym: "乐刻（改名）"}, 20).Reasons[0].Factor)
+		assert.Empty(t, matching.Score(me, matching.Profile{GymID: 2, Gym: "乐刻"}, 20).Reasons)
+		assert.NotEmpty(t, matching.Score(me, matching.Profile{Gym: "乐刻"}, 20).Reasons)
+	})
+}
+
+// TestMateSuggestions 测试搭子推荐接口的候选人筛选和排序
+func TestMateSuggestions(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	setupTestDB(t)
+
+	lat, lon := 31.5, 118.5
+	// 和资料接口一样保存粗化后的坐标和 geohash
+	at := func(offset float64) (*float64, *float64, string) {
+		a, b, hash := geo.CoarsenUser(lat+offset, lon)
+		return &a, &b, hash
+	}
+	meLat, meLon, meHash := at(0)
+	me := models.User{Name: "匹配我", Email: "match-me@gymates.com", Password: "x", Location: "匹配测试市",
+		Goal: "增肌", Experience: "中级", TrainingTimes: "evening,night", HomeGym: "匹配健身房",
+		Latitude: meLat, Longitude: meLon, Geohash: meHash}
+	bestLat, bestLon, bestHash := at(0.01)
+	best := models.User{Name: "最佳搭子", Email: "match-best@gymates.com", Password: "x",
+		Goal: "增肌", Experience: "2年", TrainingTimes: "evening", HomeGym: "匹配健身房",
+		Latitude: bestLat, Longitude: bestLon, Geohash: bestHash}
+	nearLat, nearLon, nearHash := at(0.03)
+	near := models.User{Name: "附近的人", Email: "match-near@gymates.com", Password: "x",
+		Goal: "减脂", Experience: "高级", TrainingTimes: "morning", Latitude: nearLat, Longitude: nearLon, Geohash: nearHash}
+	farLat, farLon, farHash := at(0.45)
+	far := models.User{Name: "很远的人", Email: "match-far@gymates.com", Password: "x",
+		Goal: "增肌", Experience: "中级", Latitude: farLat, Longitude: farLon, Geohash: farHash}
+	cityOnly := models.User{Name: "同城无坐标", Email: "match-city@gymates.com", Password: "x",
+		Location: "匹配测试市", Goal: "力量训练"}
+	elsewhere := models.User{Name: "外地无坐标", Email: "match-elsewhere@gymates.com", Password: "x",
+		Location: "别的城市", Goal: "增肌", Experience: "中级"}
+	mateLat, mateLon, mateHash := at(0.01)
+	friend := models.User{Name: "已是搭子", Email: "match-friend@gymates.com", Password: "x",
+		Goal: "增肌", Latitude: mateLat, Longitude: mateLon, Geohash: mateHash}
+	pendingLat, pendingLon, pendingHash := at(0.02)
+	pending := models.User{Name: "待处理", Email: "match-pending@gymates.com", Password: "x",
+		Goal: "增肌", Latitude: pendingLat, Longitude: pendingLon, Geohash: pendingHash}
+	for _, user := range []*models.User{&me, &best, &near, &far, &cityOnly, &elsewhere, &friend, &pending} {
+		require.NoError(t, config.DB.Create(user).Error)
+	}
+	createMateRelation(t, me.ID, friend.ID, models.MateStatusAccepted)
+	createMateRelation(t, pending.ID, me.ID, models.MateStatusPending)
+	// 同城用户没填训练时段，最近常在晚上7点训练
+	day := time.Now().In(models.LoadLocation("Asia/Shanghai")).Truncate(24 * time.Hour)
+	for i := 1; i <= 3; i++ {
+		start := time.Date(day.Year(), day.Month(), day.Day()-i, 19, 0, 0, 0, models.LoadLocation("Asia/Shanghai"))
+		require.NoError(t, config.DB.Create(&models.WorkoutSession{UserID: cityOnly.ID, TrainingPlanID: 1, StartTime: start}).Error)
+	}
+
+	controller := NewMatesController()
+	router := gin.New()
+	group := router.Group("/", withTestUser(&me))
+	group.GET("/mates/suggestions", controller.GetSuggestions)
+	group.PUT("/profile", NewAuthController().UpdateProfile)
+
+	suggest := func(t *testing.T, query string) models.MateSuggestionsResponse {
+		var response models.MateSuggestionsResponse
+		require.Equal(t, http.StatusOK, sendJSON(t, router, "GET", "/mates/suggestions"+query, nil, &response))
+		return response
+	}
+	names := func(response models.MateSuggestionsResponse) []string {
+		result := []string{}
+		for _, suggestion := range response.Suggestions {
+			result = append(result, suggestion.User.Name)
+		}
+		return result
+	}
+
+	t.Run("排除搭子和待处理请求，按匹配分排序", func(t *testing.T) {
+		response := suggest(t, "")
+		assert.Equal(t, float64(models.DefaultMateRadiusKm), response.RadiusKm)
+		assert.Equal(t, []string{"最佳搭子", "同城无坐标", "附近的人"}, names(response))
+
+		top := response.Suggestions[0]
+		// 0.3*(1-1.11/20) + 0.25 + 0.2*0.5 + 0.15 + 0.1
+		assert.InDelta(t, 88.3, top.Score, 0.1)
+		require.NotNil(t, top.DistanceKm)
+		assert.Equal(t, 1.0, *top.DistanceKm)
+		assert.Equal(t, "相距约1公里", top.Reasons[0].Text)
+		assert.Equal(t, models.MatchFactorDistance, top.Reasons[0].Factor)
+
+		city := response.Suggestions[1]
+		assert.Nil(t, city.DistanceKm)
+		factors := []string{}
+		for _, reason := range city.Reasons {
+			factors = append(factors, reason.Factor)
+		}
+		// 训练时段由最近的训练记录推断
+		assert.ElementsMatch(t, []string{models.MatchFactorDistance, models.MatchFactorGoal, models.MatchFactorSchedule}, factors)
+	})
+
+	t.Run("扩大距离范围", func(t *testing.T) {
+		response := suggest(t, "?radius_km=60")
+		assert.Contains(t, names(response), "很远的人")
+
+		response = suggest(t, "?radius_km=1000&limit=1")
+		assert.Equal(t, float64(models.MaxMateRadiusKm), response.RadiusKm)
+		assert.Equal(t, []string{"最佳搭子"}, names(response))
+
+		assert.Equal(t, http.StatusBadRequest, sendJSON(t, router, "GET", "/mates/suggestions?radius_km=abc", nil, nil))
+		assert.Equal(t, http.StatusBadRequest, sendJSON(t, router, "GET", "/mates/suggestions?radius_km=-5", nil, nil))
+	})
+
+	t.Run("资料中的坐标、健身房和训练时段", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, sendJSON(t, router, "PUT", "/profile", map[string]interface{}{"latitude": 30}, nil))
+		assert.Equal(t, http.StatusBadRequest, sendJSON(t, router, "PUT", "/profile", map[string]interface{}{"latitude": 91, "longitude": 0}, nil))
+		assert.Equal(t, http.StatusBadRequest, sendJSON(t, router, "PUT", "/profile", map[string]interface{}{"training_times": []string{"midnight"}}, nil))
+
+		var updated map[string]interface{}
+		require.Equal(t, http.StatusOK, sendJSON(t, router, "PUT", "/profile", map[string]interface{}{
+			"latitude": lat, "longitude": lon + 0.01, "home_gym": " 新健身房 ", "training_times": []string{"night", "morning", "night"},
+		}, &updated))
+		assert.Equal(t, "新健身房", updated["home_gym"])
+		assert.Equal(t, "morning,night", updated["training_times"])
+		// 坐标不对外返回
+		assert.NotContains(t, updated, "latitude")
+
+		var stored models.User
+		require.NoError(t, config.DB.First(&stored, me.ID).Error)
+		require.NotNil(t, stored.Longitude)
+		assert.InDelta(t, lon+0.01, *stored.Longitude, 1e-9)
+		assert.Equal(t, geo.Encode(lat, lon+0.01, geo.UserPrecision), stored.Geohash)
+	})
+}
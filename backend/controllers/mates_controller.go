@@ -8,6 +8,7 @@ import (
 	"gorm.io/gorm"
 	"gymates-backend/config"
 	"gymates-backend/models"
+	"gymates-backend/services"
 )
 
 // MatesController 搭子控制器
@@ -41,15 +42,16 @@ func (mc *MatesController) GetMates(c *gin.Context) {
 
 	// 获取当前用户的搭子
 	query := config.DB.Table("users").
-		Joins("JOIN mates ON users.id = mates.mate_id").
-		Where("mates.user_id = ? AND mates.status = ?", currentUser.ID, "accepted")
+		Joins("JOIN mate_relations ON (mate_relations.user_low_id = users.id AND mate_relations.user_high_id = ?) OR (mate_relations.user_high_id = users.id AND mate_relations.user_low_id = ?)",
+			currentUser.ID, currentUser.ID).
+		Where("mate_relations.status = ?", models.MateStatusAccepted)
 
 	// 获取总数
 	query.Count(&total)
 
 	// 分页查询
 	offset := (page - 1) * limit
-	if err := query.Offset(offset).Limit(limit).Find(&mates).Error; err != nil {
+	if err := query.Order("mate_relations.updated_at DESC").Offset(offset).Limit(limit).Find(&mates).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
 			Success: false,
 			Message: "获取搭子列表失败",
@@ -96,16 +98,16 @@ func (mc *MatesController) GetMateRequests(c *gin.Context) {
 
 	currentUser := user.(*models.User)
 
-	var requests []models.Mate
+	var relations []models.MateRelation
 	var total int64
 
 	var query *gorm.DB
 	if requestType == "received" {
 		// 收到的请求
-		query = config.DB.Where("mate_id = ? AND status = ?", currentUser.ID, "pending")
+		query = mateRelationsQuery(currentUser.ID, models.MateStatusPending).Where("requester_id <> ?", currentUser.ID)
 	} else {
 		// 发送的请求
-		query = config.DB.Where("user_id = ? AND status = ?", currentUser.ID, "pending")
+		query = config.DB.Model(&models.MateRelation{}).Where("requester_id = ? AND status = ?", currentUser.ID, models.MateStatusPending)
 	}
 
 	// 获取总数
@@ -113,8 +115,17 @@ func (mc *MatesController) GetMateRequests(c *gin.Context) {
 
 	// 分页查询
 	offset := (page - 1) * limit
-	if err := query.Preload("User").Preload("Mate").
-		Offset(offset).Limit(limit).Order("created_at DESC").Find(&requests).Error; err != nil {
+	if err := query.Offset(offset).Limit(limit).Order("requested_at DESC").Find(&relations).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Success: false,
+			Message: "获取搭子请求失败",
+			Error:   err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+	requests, err := mateRequestViews(relations)
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
 			Success: false,
 			Message: "获取搭子请求失败",
@@ -191,117 +202,71 @@ func (mc *MatesController) SendMateRequest(c *gin.Context) {
 		return
 	}
 
-	// 检查是否已经存在关系
-	var existingMate models.Mate
-	if err := config.DB.Where("user_id = ? AND mate_id = ?", currentUser.ID, req.MateID).
-		First(&existingMate).Error; err == nil {
-		c.JSON(http.StatusConflict, models.ErrorResponse{
-			Success: false,
-			Message: "搭子关系已存在",
-			Error:   "Mate relationship already exists",
-			Code:    http.StatusConflict,
-		})
+	relation, err := transitionMate(currentUser.ID, req.MateID, services.MateActionRequest)
+	if err != nil {
+		writeMateError(c, "发送搭子请求失败", err)
 		return
 	}
-
-	// 创建搭子请求
-	mate := models.Mate{
-		UserID: currentUser.ID,
-		MateID: req.MateID,
-		Status: "pending",
-	}
-
-	if err := config.DB.Create(&mate).Error; err != nil {
+	views, err := mateRequestViews([]models.MateRelation{relation})
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
 			Success: false,
-			Message: "发送搭子请求失败",
+			Message: "获取搭子请求失败",
 			Error:   err.Error(),
 			Code:    http.StatusInternalServerError,
 		})
 		return
 	}
 
-	// 重新加载数据
-	config.DB.Preload("User").Preload("Mate").First(&mate, mate.ID)
+	// 对方也向自己发送过请求时直接成为搭子
+	if relation.Status == models.MateStatusAccepted {
+		c.JSON(http.StatusOK, models.SuccessResponse{
+			Success: true,
+			Message: "对方也向你发送了请求，你们已成为搭子",
+			Data:    views[0],
+		})
+		return
+	}
 
 	c.JSON(http.StatusCreated, models.SuccessResponse{
 		Success: true,
 		Message: "发送搭子请求成功",
-		Data:    mate,
+		Data:    views[0],
 	})
 }
 
 // AcceptMateRequest 接受搭子请求
 func (mc *MatesController) AcceptMateRequest(c *gin.Context) {
-	requestIDStr := c.Param("id")
-	requestID, err := strconv.ParseUint(requestIDStr, 10, 32)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, models.ErrorResponse{
-			Success: false,
-			Message: "无效的请求ID",
-			Error:   err.Error(),
-			Code:    http.StatusBadRequest,
-		})
-		return
-	}
-
-	user, exists := c.Get("user")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
-			Success: false,
-			Message: "用户未认证",
-			Error:   "User not authenticated",
-			Code:    http.StatusUnauthorized,
-		})
-		return
-	}
-
-	currentUser := user.(*models.User)
+	respondToMateRequest(c, services.MateActionAccept, "接受搭子请求失败", "接受搭子请求成功")
+}
 
-	// 查找搭子请求
-	var mate models.Mate
-	if err := config.DB.Where("id = ? AND mate_id = ? AND status = ?", uint(requestID), currentUser.ID, "pending").
-		First(&mate).Error; err != nil {
-		c.JSON(http.StatusNotFound, models.ErrorResponse{
-			Success: false,
-			Message: "搭子请求不存在",
-			Error:   "Mate request not found",
-			Code:    http.StatusNotFound,
-		})
-		return
-	}
+// RejectMateRequest 拒绝搭子请求
+func (mc *MatesController) RejectMateRequest(c *gin.Context) {
+	respondToMateRequest(c, services.MateActionDecline, "拒绝搭子请求失败", "拒绝搭子请求成功")
+}
 
-	// 更新状态为已接受
-	if err := config.DB.Model(&mate).Update("status", "accepted").Error; err != nil {
-		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
-			Success: false,
-			Message: "接受搭子请求失败",
-			Error:   err.Error(),
-			Code:    http.StatusInternalServerError,
-		})
-		return
-	}
+// CancelMateRequest 撤回自己发送的搭子请求
+func (mc *MatesController) CancelMateRequest(c *gin.Context) {
+	respondToMateRequest(c, services.MateActionCancel, "撤回搭子请求失败", "撤回搭子请求成功")
+}
 
-	// 创建反向关系
-	reverseMate := models.Mate{
-		UserID: currentUser.ID,
-		MateID: mate.UserID,
-		Status: "accepted",
-	}
-	config.DB.Create(&reverseMate)
+// RemoveMate 移除搭子
+func (mc *MatesController) RemoveMate(c *gin.Context) {
+	updateMateRelation(c, services.MateActionRemove, "移除搭子失败", "移除搭子成功")
+}
 
-	// 重新加载数据
-	config.DB.Preload("User").Preload("Mate").First(&mate, mate.ID)
+// BlockUser 拉黑用户，会同时解除搭子关系和待处理的请求
+func (mc *MatesController) BlockUser(c *gin.Context) {
+	updateMateRelation(c, services.MateActionBlock, "拉黑失败", "已拉黑该用户")
+}
 
-	c.JSON(http.StatusOK, models.SuccessResponse{
-		Success: true,
-		Message: "接受搭子请求成功",
-		Data:    mate,
-	})
+// UnblockUser 取消拉黑
+func (mc *MatesController) UnblockUser(c *gin.Context) {
+	updateMateRelation(c, services.MateActionUnblock, "取消拉黑失败", "已取消拉黑")
 }
 
-// RejectMateRequest 拒绝搭子请求
-func (mc *MatesController) RejectMateRequest(c *gin.Context) {
+// respondToMateRequest 按请求ID对搭子请求执行操作，只有请求的双方能看到这条请求
+func respondToMateRequest(c *gin.Context, action services.MateAction, failMessage, successMessage string) {
 	requestIDStr := c.Param("id")
 	requestID, err := strconv.ParseUint(requestIDStr, 10, 32)
 	if err != nil {
@@ -328,23 +293,23 @@ func (mc *MatesController) RejectMateRequest(c *gin.Context) {
 	currentUser := user.(*models.User)
 
 	// 查找搭子请求
-	var mate models.Mate
-	if err := config.DB.Where("id = ? AND mate_id = ? AND status = ?", uint(requestID), currentUser.ID, "pending").
-		First(&mate).Error; err != nil {
-		c.JSON(http.StatusNotFound, models.ErrorResponse{
-			Success: false,
-			Message: "搭子请求不存在",
-			Error:   "Mate request not found",
-			Code:    http.StatusNotFound,
-		})
+	var relation models.MateRelation
+	if err := mateRelationsQuery(currentUser.ID, models.MateStatusPending).
+		Where("id = ?", uint(requestID)).First(&relation).Error; err != nil {
+		writeMateError(c, failMessage, services.ErrMateNoPendingRequest)
 		return
 	}
 
-	// 更新状态为已拒绝
-	if err := config.DB.Model(&mate).Update("status", "rejected").Error; err != nil {
+	relation, err = transitionMate(currentUser.ID, relation.Other(currentUser.ID), action)
+	if err != nil {
+		writeMateError(c, failMessage, err)
+		return
+	}
+	views, err := mateRequestViews([]models.MateRelation{relation})
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
 			Success: false,
-			Message: "拒绝搭子请求失败",
+			Message: failMessage,
 			Error:   err.Error(),
 			Code:    http.StatusInternalServerError,
 		})
@@ -353,18 +318,19 @@ func (mc *MatesController) RejectMateRequest(c *gin.Context) {
 
 	c.JSON(http.StatusOK, models.SuccessResponse{
 		Success: true,
-		Message: "拒绝搭子请求成功",
+		Message: successMessage,
+		Data:    views[0],
 	})
 }
 
-// RemoveMate 移除搭子
-func (mc *MatesController) RemoveMate(c *gin.Context) {
+// updateMateRelation 按对方用户ID执行操作
+func updateMateRelation(c *gin.Context, action services.MateAction, failMessage, successMessage string) {
 	mateIDStr := c.Param("id")
 	mateID, err := strconv.ParseUint(mateIDStr, 10, 32)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, models.ErrorResponse{
 			Success: false,
-			Message: "无效的搭子ID",
+			Message: "无效的用户ID",
 			Error:   err.Error(),
 			Code:    http.StatusBadRequest,
 		})
@@ -384,25 +350,27 @@ func (mc *MatesController) RemoveMate(c *gin.Context) {
 
 	currentUser := user.(*models.User)
 
-	// 删除搭子关系（双向）
-	if err := config.DB.Where("user_id = ? AND mate_id = ?", currentUser.ID, uint(mateID)).
-		Delete(&models.Mate{}).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
-			Success: false,
-			Message: "移除搭子失败",
-			Error:   err.Error(),
-			Code:    http.StatusInternalServerError,
-		})
-		return
+	if action == services.MateActionBlock {
+		var target models.User
+		if err := config.DB.Select("id").First(&target, uint(mateID)).Error; err != nil {
+			c.JSON(http.StatusNotFound, models.ErrorResponse{
+				Success: false,
+				Message: "目标用户不存在",
+				Error:   "Target user not found",
+				Code:    http.StatusNotFound,
+			})
+			return
+		}
 	}
 
-	// 删除反向关系
-	config.DB.Where("user_id = ? AND mate_id = ?", uint(mateID), currentUser.ID).
-		Delete(&models.Mate{})
+	if _, err := transitionMate(currentUser.ID, uint(mateID), action); err != nil {
+		writeMateError(c, failMessage, err)
+		return
+	}
 
 	c.JSON(http.StatusOK, models.SuccessResponse{
 		Success: true,
-		Message: "移除搭子成功",
+		Message: successMessage,
 	})
 }
 
@@ -425,8 +393,11 @@ func (mc *MatesController) SearchMates(c *gin.Context) {
 	var users []models.User
 	var total int64
 
-	// 搜索用户
-	searchQuery := config.DB.Model(&models.User{}).Where("name LIKE ? OR bio LIKE ?", "%"+query+"%", "%"+query+"%")
+	// 搜索用户，不包含自己、拉黑和静音的用户，以及资料不对自己公开的用户
+	viewer := viewerID(c)
+	searchQuery := config.DB.Model(&models.User{}).Where("name LIKE ? OR bio LIKE ?", "%"+query+"%", "%"+query+"%").
+		Where("id <> ? AND id NOT IN ?", viewer, hiddenUserIDs(viewer)).
+		Where("id NOT IN (?)", privacyHiddenUsers("profile_visibility", viewer))
 
 	// 获取总数
 	searchQuery.Count(&total)
@@ -476,20 +447,15 @@ func (mc *MatesController) GetMateStats(c *gin.Context) {
 
 	currentUser := user.(*models.User)
 
-	var stats struct {
-		TotalMates      int64 `json:"total_mates"`
-		PendingRequests int64 `json:"pending_requests"`
-		SentRequests    int64 `json:"sent_requests"`
-	}
-
-	// 统计搭子数量
-	config.DB.Model(&models.Mate{}).Where("user_id = ? AND status = ?", currentUser.ID, "accepted").Count(&stats.TotalMates)
+	stats := models.MateStatsResponse{TotalMates: countMates(currentUser.ID)}
 
 	// 统计待处理的请求
-	config.DB.Model(&models.Mate{}).Where("mate_id = ? AND status = ?", currentUser.ID, "pending").Count(&stats.PendingRequests)
+	mateRelationsQuery(currentUser.ID, models.MateStatusPending).
+		Where("requester_id <> ?", currentUser.ID).Count(&stats.PendingRequests)
 
 	// 统计发送的请求
-	config.DB.Model(&models.Mate{}).Where("user_id = ? AND status = ?", currentUser.ID, "pending").Count(&stats.SentRequests)
+	config.DB.Model(&models.MateRelation{}).
+		Where("requester_id = ? AND status = ?", currentUser.ID, models.MateStatusPending).Count(&stats.SentRequests)
 
 	c.JSON(http.StatusOK, models.SuccessResponse{
 		Success: true,
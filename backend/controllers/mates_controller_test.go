@@ -0,0 +1,309 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"gymates-backend/config"
+	"gymates-backend/models"
+	"gymates-backend/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// createMateRelation 直接写入一条搭子关系
+func createMateRelation(t *testing.T, requesterID, otherID uint, status string) models.MateRelation {
+	t.Helper()
+	low, high := models.MatePair(requesterID, otherID)
+	now := time.Now()
+	relation := models.MateRelation{
+		UserLowID: low, UserHighID: high, RequesterID: requesterID, ActorID: requesterID,
+		Status: status, RequestedAt: &now,
+	}
+	require.NoError(t, config.DB.Create(&relation).Error)
+	return relation
+}
+
+// TestMates 测试搭子请求、回应、解除、拉黑和统计接口
+func TestMates(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	setupTestDB(t)
+
+	users := map[string]*models.User{}
+	for _, name := range []string{"amy", "ben", "cat", "dan"} {
+		user := &models.User{Name: "搭子" + name, Email: "mate-" + name + "@gymates.com", Password: "x"}
+		require.NoError(t, config.DB.Create(user).Error)
+		users[name] = user
+	}
+	amy, ben, cat, dan := users["amy"], users["ben"], users["cat"], users["dan"]
+
+	controller := NewMatesController()
+	router := gin.New()
+	for name, user := range users {
+		group := router.Group("/"+name, withTestUser(user))
+		group.GET("/mates", controller.GetMates)
+		group.GET("/mates/requests", controller.GetMateRequests)
+		group.POST("/mates/requests", controller.SendMateRequest)
+		group.POST("/mates/requests/:id/accept", controller.AcceptMateRequest)
+		group.POST("/mates/requests/:id/reject", controller.RejectMateRequest)
+		group.POST("/mates/requests/:id/cancel", controller.CancelMateRequest)
+		group.DELETE("/mates/:id", controller.RemoveMate)
+		group.POST("/mates/:id/block", controller.BlockUser)
+		group.DELETE("/mates/:id/block", controller.UnblockUser)
+		group.GET("/mates/stats", controller.GetMateStats)
+	}
+
+	request := func(t *testing.T, from string, to *models.User) (int, models.MateRequestView) {
+		var view models.MateRequestView
+		code := sendJSON(t, router, "POST", "/"+from+"/mates/requests", map[string]interface{}{"mate_id": to.ID}, &view)
+		return code, view
+	}
+	mateNames := func(t *testing.T, as string) []string {
+		var response models.MatesResponse
+		require.Equal(t, http.StatusOK, sendJSON(t, router, "GET", "/"+as+"/mates", nil, &response))
+		names := []string{}
+		for _, mate := range response.Mates {
+			names = append(names, mate.Name)
+		}
+		return names
+	}
+	requests := func(t *testing.T, as, kind string) []models.MateRequestView {
+		var views []models.MateRequestView
+		response := models.PaginationResponse{Data: &views}
+		require.Equal(t, http.StatusOK, sendJSON(t, router, "GET", "/"+as+"/mates/requests?type="+kind, nil, &response))
+		return views
+	}
+	stats := func(t *testing.T, as string) models.MateStatsResponse {
+		var response models.MateStatsResponse
+		require.Equal(t, http.StatusOK, sendJSON(t, router, "GET", "/"+as+"/mates/stats", nil, &response))
+		return response
+	}
+	relationCount := func(t *testing.T, x, y *models.User) int64 {
+		low, high := models.MatePair(x.ID, y.ID)
+		var count int64
+		config.DB.Model(&models.MateRelation{}).Where("user_low_id = ? AND user_high_id = ?", low, high).Count(&count)
+		return count
+	}
+
+	t.Run("互相发送请求直接成为搭子", func(t *testing.T) {
+		code, view := request(t, "amy", ben)
+		require.Equal(t, http.StatusCreated, code)
+		assert.Equal(t, amy.ID, view.UserID)
+		assert.Equal(t, ben.ID, view.MateID)
+		assert.Equal(t, models.MateStatusPending, view.Status)
+
+		code, _ = request(t, "amy", ben)
+		assert.Equal(t, http.StatusConflict, code)
+
+		code, view = request(t, "ben", amy)
+		require.Equal(t, http.StatusOK, code)
+		assert.Equal(t, models.MateStatusAccepted, view.Status)
+		assert.Equal(t, int64(1), relationCount(t, amy, ben))
+
+		assert.Equal(t, []string{"搭子ben"}, mateNames(t, "amy"))
+		assert.Equal(t, []string{"搭子amy"}, mateNames(t, "ben"))
+		// 双方的统计都来自同一行关系
+		assert.Equal(t, int64(1), stats(t, "amy").TotalMates)
+		assert.Equal(t, int64(1), stats(t, "ben").TotalMates)
+
+		code, _ = request(t, "ben", amy)
+		assert.Equal(t, http.StatusConflict, code)
+	})
+
+	t.Run("接受和拒绝请求", func(t *testing.T) {
+		code, sent := request(t, "amy", cat)
+		require.Equal(t, http.StatusCreated, code)
+
+		received := requests(t, "cat", "received")
+		require.Len(t, received, 1)
+		assert.Equal(t, sent.ID, received[0].ID)
+		assert.Equal(t, "搭子amy", received[0].User.Name)
+		assert.Equal(t, "搭子cat", received[0].Mate.Name)
+		assert.Len(t, requests(t, "amy", "sent"), 1)
+		assert.Empty(t, requests(t, "amy", "received"))
+		assert.Equal(t, int64(1), stats(t, "cat").PendingRequests)
+		assert.Equal(t, int64(1), stats(t, "amy").SentRequests)
+
+		path := "/mates/requests/" + uintToString(sent.ID)
+		// 发起方不能接受自己的请求，第三人看不到这条请求
+		assert.Equal(t, http.StatusNotFound, sendJSON(t, router, "POST", "/amy"+path+"/accept", nil, nil))
+		assert.Equal(t, http.StatusNotFound, sendJSON(t, router, "POST", "/dan"+path+"/reject", nil, nil))
+		assert.Equal(t, http.StatusBadRequest, sendJSON(t, router, "POST", "/cat/mates/requests/abc/accept", nil, nil))
+
+		var view models.MateRequestView
+		require.Equal(t, http.StatusOK, sendJSON(t, router, "POST", "/cat"+path+"/reject", nil, &view))
+		assert.Equal(t, models.MateStatusDeclined, view.Status)
+		assert.NotNil(t, view.RespondedAt)
+		assert.Empty(t, requests(t, "cat", "received"))
+		assert.Equal(t, http.StatusNotFound, sendJSON(t, router, "POST", "/cat"+path+"/accept", nil, nil))
+
+		// 被拒绝后冷却期内不能再发，被拒绝的请求也不能撤回
+		code, _ = request(t, "amy", cat)
+		assert.Equal(t, http.StatusTooManyRequests, code)
+		assert.Equal(t, http.StatusNotFound, sendJSON(t, router, "POST", "/amy"+path+"/cancel", nil, nil))
+
+		// 拒绝的一方可以主动发起，复用同一行关系
+		code, again := request(t, "cat", amy)
+		require.Equal(t, http.StatusCreated, code)
+		assert.Equal(t, sent.ID, again.ID)
+		require.Equal(t, http.StatusOK, sendJSON(t, router, "POST", "/amy/mates/requests/"+uintToString(again.ID)+"/accept", nil, &view))
+		assert.Equal(t, models.MateStatusAccepted, view.Status)
+		assert.Equal(t, cat.ID, view.UserID)
+		assert.ElementsMatch(t, []string{"搭子ben", "搭子cat"}, mateNames(t, "amy"))
+	})
+
+	t.Run("撤回请求", func(t *testing.T) {
+		code, sent := request(t, "amy", dan)
+		require.Equal(t, http.StatusCreated, code)
+		path := "/mates/requests/" + uintToString(sent.ID) + "/cancel"
+		assert.Equal(t, http.StatusNotFound, sendJSON(t, router, "POST", "/dan"+path, nil, nil))
+		require.Equal(t, http.StatusOK, sendJSON(t, router, "POST", "/amy"+path, nil, nil))
+		assert.Empty(t, requests(t, "dan", "received"))
+		assert.Equal(t, int64(0), stats(t, "amy").SentRequests)
+		assert.Equal(t, http.StatusNotFound, sendJSON(t, router, "POST", "/amy"+path, nil, nil))
+	})
+
+	t.Run("解除搭子", func(t *testing.T) {
+		require.Equal(t, http.StatusOK, sendJSON(t, router, "DELETE", "/ben/mates/"+uintToString(amy.ID), nil, nil))
+		assert.Equal(t, []string{"搭子cat"}, mateNames(t, "amy"))
+		assert.Empty(t, mateNames(t, "ben"))
+		assert.Equal(t, http.StatusNotFound, sendJSON(t, router, "DELETE", "/amy/mates/"+uintToString(ben.ID), nil, nil))
+		assert.Equal(t, int64(1), relationCount(t, amy, ben))
+	})
+
+	t.Run("拉黑", func(t *testing.T) {
+		require.Equal(t, http.StatusOK, sendJSON(t, router, "POST", "/cat/mates/"+uintToString(amy.ID)+"/block", nil, nil))
+		assert.Empty(t, mateNames(t, "cat"))
+		assert.Empty(t, mateNames(t, "amy"))
+
+		code, _ := request(t, "amy", cat)
+		assert.Equal(t, http.StatusForbidden, code)
+		code, _ = request(t, "cat", amy)
+		assert.Equal(t, http.StatusForbidden, code)
+		assert.Equal(t, http.StatusNotFound, sendJSON(t, router, "DELETE", "/amy/mates/"+uintToString(cat.ID)+"/block", nil, nil))
+		assert.Equal(t, http.StatusNotFound, sendJSON(t, router, "POST", "/cat/mates/999999/block", nil, nil))
+
+		// 拉黑会清掉待处理的请求
+		code, pending := request(t, "dan", cat)
+		require.Equal(t, http.StatusCreated, code)
+		require.Equal(t, http.StatusOK, sendJSON(t, router, "POST", "/cat/mates/"+uintToString(dan.ID)+"/block", nil, nil))
+		assert.Empty(t, requests(t, "cat", "received"))
+		assert.Equal(t, http.StatusNotFound, sendJSON(t, router, "POST", "/cat/mates/requests/"+uintToString(pending.ID)+"/accept", nil, nil))
+
+		require.Equal(t, http.StatusOK, sendJSON(t, router, "DELETE", "/cat/mates/"+uintToString(amy.ID)+"/block", nil, nil))
+		code, _ = request(t, "amy", cat)
+		assert.Equal(t, http.StatusCreated, code)
+	})
+
+	t.Run("参数校验", func(t *testing.T) {
+		code, _ := request(t, "amy", amy)
+		assert.Equal(t, http.StatusBadRequest, code)
+		code, _ = request(t, "amy", &models.User{ID: 999999})
+		assert.Equal(t, http.StatusNotFound, code)
+		assert.Equal(t, http.StatusBadRequest, sendJSON(t, router, "DELETE", "/amy/mates/abc", nil, nil))
+	})
+
+	t.Run("每对用户只能有一行关系", func(t *testing.T) {
+		duplicate := models.MateRelation{UserLowID: amy.ID, UserHighID: ben.ID, RequesterID: amy.ID, Status: models.MateStatusPending}
+		assert.Error(t, config.DB.Create(&duplicate).Error)
+	})
+}
+
+// TestMigrateLegacyMates 测试旧版单向搭子记录的合并
+func TestMigrateLegacyMates(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file:legacy_mates?mode=memory"), &gorm.Config{})
+	require.NoError(t, err)
+
+	type legacy struct {
+		ID        uint
+		UserID    uint
+		MateID    uint
+		Status    string
+		CreatedAt time.Time
+		UpdatedAt time.Time
+		DeletedAt gorm.DeletedAt
+	}
+	require.NoError(t, db.Table("mates").AutoMigrate(&legacy{}))
+	require.NoError(t, db.AutoMigrate(&models.MateRelation{}))
+
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	rows := []legacy{
+		{UserID: 1, MateID: 2, Status: "accepted"}, // 接受后的两行
+		{UserID: 2, MateID: 1, Status: "accepted"},
+		{UserID: 3, MateID: 1, Status: "pending"}, // 互相请求
+		{UserID: 1, MateID: 3, Status: "pending"},
+		{UserID: 4, MateID: 1, Status: "rejected"},
+		{UserID: 5, MateID: 1, Status: "pending"},
+		{UserID: 6, MateID: 1, Status: "accepted", DeletedAt: gorm.DeletedAt{Time: base, Valid: true}},
+	}
+	for i := range rows {
+		rows[i].CreatedAt = base.Add(time.Duration(i) * time.Hour)
+		rows[i].UpdatedAt = rows[i].CreatedAt
+		require.NoError(t, db.Table("mates").Create(&rows[i]).Error)
+	}
+
+	require.NoError(t, config.MigrateLegacyMates(db))
+	// 新表有数据后不再重复合并
+	require.NoError(t, config.MigrateLegacyMates(db))
+
+	var relations []models.MateRelation
+	require.NoError(t, db.Order("user_high_id").Find(&relations).Error)
+	require.Len(t, relations, 4)
+	got := map[uint]models.MateRelation{}
+	for _, relation := range relations {
+		assert.Equal(t, uint(1), relation.UserLowID)
+		got[relation.UserHighID] = relation
+	}
+	assert.Equal(t, models.MateStatusAccepted, got[2].Status)
+	assert.Equal(t, models.MateStatusAccepted, got[3].Status)
+	assert.Equal(t, models.MateStatusDeclined, got[4].Status)
+	assert.Equal(t, uint(4), got[4].RequesterID)
+	assert.Equal(t, uint(1), got[4].ActorID)
+	assert.Equal(t, models.MateStatusPending, got[5].Status)
+	assert.Equal(t, uint(5), got[5].RequesterID)
+}
+
+// TestTransitionMateConcurrentCreate 测试两人同时新建关系时，写入失败的一方重试后读到对方的关系
+func TestTransitionMateConcurrentCreate(t *testing.T) {
+	setupTestDB(t)
+
+	alice := models.User{Name: "并发Alice", Email: "mate-race-alice@gymates.com", Password: "x"}
+	bob := models.User{Name: "并发Bob", Email: "mate-race-bob@gymates.com", Password: "x"}
+	require.NoError(t, config.DB.Create(&alice).Error)
+	require.NoError(t, config.DB.Create(&bob).Error)
+
+	// 模拟 bob 的请求在 alice 查询之后、写入之前抢先创建了关系：alice 第一次写入时违反唯一索引，
+	// 事务回滚后 bob 的关系才提交（SQLite 内存库在事务进行中无法从另一个连接写入）
+	conflicted, injected := false, false
+	require.NoError(t, config.DB.Callback().Create().Before("gorm:create").Register("test:mate_race", func(db *gorm.DB) {
+		if _, ok := db.Statement.Dest.(*models.MateRelation); ok && !conflicted {
+			conflicted = true
+			db.AddError(errors.New("UNIQUE constraint failed: mate_relations.user_low_id, mate_relations.user_high_id"))
+		}
+	}))
+	defer config.DB.Callback().Create().Remove("test:mate_race")
+	require.NoError(t, config.DB.Callback().Query().Before("gorm:query").Register("test:mate_race", func(db *gorm.DB) {
+		if _, ok := db.Statement.Dest.(*models.MateRelation); ok && conflicted && !injected {
+			injected = true
+			createMateRelation(t, bob.ID, alice.ID, models.MateStatusPending)
+		}
+	}))
+	defer config.DB.Callback().Query().Remove("test:mate_race")
+
+	relation, err := transitionMate(alice.ID, bob.ID, services.MateActionRequest)
+	require.NoError(t, err)
+	assert.True(t, injected)
+	// 重试时读到 bob 的请求，alice 的请求视为同意
+	assert.Equal(t, models.MateStatusAccepted, relation.Status)
+	assert.Equal(t, bob.ID, relation.RequesterID)
+
+	var count int64
+	mateRelationsQuery(alice.ID, models.MateStatusAccepted).Count(&count)
+	assert.Equal(t, int64(1), count)
+}
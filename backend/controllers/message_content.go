@@ -0,0 +1,332 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"gymates-backend/config"
+	"gymates-backend/models"
+
+	"github.com/gin-gonic/gin/binding"
+	"gorm.io/gorm"
+)
+
+// messageContentError 消息内容校验失败，Message 为返回给用户的提示
+type messageContentError struct {
+	Message string
+	Err     error
+}
+
+func (e *messageContentError) Error() string {
+	return e.Err.Error()
+}
+
+func invalidContent(message, reason string) error {
+	return &messageContentError{Message: message, Err: errors.New(reason)}
+}
+
+// buildMessageContent 按消息类型校验请求并生成要保存的消息，分享类消息在此生成卡片快照
+func buildMessageContent(sender *models.User, chatID uint, req models.SendMessageRequest) (models.Message, error) {
+	message := models.Message{
+		ChatID:   chatID,
+		SenderID: sender.ID,
+		Content:  strings.TrimSpace(req.Content),
+		Type:     req.Type,
+	}
+	if message.Type == "" {
+		message.Type = models.MessageTypeText
+	}
+
+	var payload interface{}
+	switch message.Type {
+	case models.MessageTypeText:
+		if message.Content == "" {
+			return message, invalidContent("消息内容不能为空", "content is required for text messages")
+		}
+	case models.MessageTypeImage:
+		var image models.ImagePayload
+		if err := decodeMessagePayload(req.Payload, &image); err != nil {
+			return message, err
+		}
+		payload = image
+	case models.MessageTypeLocation:
+		var location models.LocationPayload
+		if err := decodeMessagePayload(req.Payload, &location); err != nil {
+			return message, err
+		}
+		payload = location
+	case models.MessageTypeWorkout:
+		var share models.WorkoutSharePayload
+		if err := decodeMessagePayload(req.Payload, &share); err != nil {
+			return message, err
+		}
+		card, err := workoutShareCard(sender.ID, share.SessionID)
+		if err != nil {
+			return message, err
+		}
+		payload = card
+	case models.MessageTypePlan:
+		var share models.PlanSharePayload
+		if err := decodeMessagePayload(req.Payload, &share); err != nil {
+			return message, err
+		}
+		card, err := planShareCard(sender.ID, share.PlanID)
+		if err != nil {
+			return message, err
+		}
+		payload = card
+	default:
+		return message, invalidContent("不支持的消息类型", "unsupported message type: "+message.Type)
+	}
+
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return message, err
+		}
+		message.Payload = string(data)
+	}
+
+	if req.ReplyToID != nil {
+		var count int64
+		config.DB.Model(&models.Message{}).Where("id = ? AND chat_id = ?", *req.ReplyToID, chatID).Count(&count)
+		if count == 0 {
+			return message, invalidContent("回复的消息不存在", "reply_to_id must reference a message in the same chat")
+		}
+		message.ReplyToID = req.ReplyToID
+	}
+	return message, nil
+}
+
+// decodeMessagePayload 解析并按 binding 标签校验 payload
+func decodeMessagePayload(raw json.RawMessage, target interface{}) error {
+	if len(raw) == 0 || string(raw) == "null" {
+		return invalidContent("消息内容不能为空", "payload is required for this message type")
+	}
+	if err := json.Unmarshal(raw, target); err != nil {
+		return &messageContentError{Message: "消息内容格式错误", Err: err}
+	}
+	if err := binding.Validator.ValidateStruct(target); err != nil {
+		return &messageContentError{Message: "消息内容格式错误", Err: err}
+	}
+	return nil
+}
+
+// workoutShareCard 生成训练记录卡片，只能分享自己的训练
+func workoutShareCard(userID, sessionID uint) (models.WorkoutShareCard, error) {
+	var session models.WorkoutSession
+	if err := config.DB.Preload("TrainingPlan.Exercises").Where("id = ? AND user_id = ?", sessionID, userID).
+		First(&session).Error; err != nil {
+		return models.WorkoutShareCard{}, invalidContent("训练记录不存在", "workout session not found")
+	}
+
+	card := models.WorkoutShareCard{
+		SessionID:     session.ID,
+		PlanName:      session.TrainingPlan.Name,
+		Status:        session.Status,
+		StartTime:     session.StartTime,
+		EndTime:       session.EndTime,
+		Calories:      session.TotalCalories,
+		Progress:      session.Progress,
+		ExerciseCount: len(session.TrainingPlan.Exercises),
+	}
+	if session.EndTime != nil {
+		card.DurationMinutes = int(session.EndTime.Sub(session.StartTime).Minutes())
+	}
+	return card, nil
+}
+
+// planShareCard 生成一周训练计划卡片，可以分享自己的计划或公开计划
+func planShareCard(userID, planID uint) (models.PlanShareCard, error) {
+	var plan models.WeeklyTrainingPlan
+	if err := config.DB.Preload("Days.Parts.Exercises").
+		Where("id = ? AND (user_id = ? OR is_public = ?)", planID, userID, true).
+		First(&plan).Error; err != nil {
+		return models.PlanShareCard{}, invalidContent("训练计划不存在", "training plan not found")
+	}
+
+	card := models.PlanShareCard{
+		PlanID:       plan.ID,
+		OwnerID:      plan.UserID,
+		Name:         plan.Name,
+		Description:  plan.Description,
+		IsPublic:     plan.IsPublic,
+		MuscleGroups: []string{},
+	}
+	seen := map[string]bool{}
+	for _, day := range plan.Days {
+		if !day.IsRestDay {
+			card.TrainingDays++
+		}
+		for _, part := range day.Parts {
+			card.ExerciseCount += len(part.Exercises)
+			name := part.MuscleGroupName
+			if name == "" {
+				name = part.MuscleGroup
+			}
+			if !seen[name] {
+				seen[name] = true
+				card.MuscleGroups = append(card.MuscleGroups, name)
+			}
+		}
+	}
+	return card, nil
+}
+
+// renderMessages 解析消息内容并填充回复摘要和表情回应，userID 用于计算 Reacted
+//
+// 撤回的消息内容显示为"消息已撤回"，被回复的消息已删除时不返回摘要。
+func renderMessages(messages []models.Message, userID uint) {
+	if len(messages) == 0 {
+		return
+	}
+
+	ids := make([]uint, 0, len(messages))
+	replyIDs := []uint{}
+	for i := range messages {
+		decodeMessageContent(&messages[i])
+		ids = append(ids, messages[i].ID)
+		if messages[i].ReplyToID != nil {
+			replyIDs = append(replyIDs, *messages[i].ReplyToID)
+		}
+	}
+
+	previews := map[uint]*models.MessagePreview{}
+	if len(replyIDs) > 0 {
+		var replies []models.Message
+		config.DB.Preload("Sender").Where("id IN ?", replyIDs).Find(&replies)
+		for _, reply := range replies {
+			previews[reply.ID] = messagePreview(reply)
+		}
+	}
+
+	reactions := messageReactions(ids, userID)
+	for i := range messages {
+		if messages[i].ReplyToID != nil {
+			messages[i].ReplyTo = previews[*messages[i].ReplyToID]
+		}
+		if summaries, ok := reactions[messages[i].ID]; ok {
+			messages[i].Reactions = summaries
+		}
+	}
+}
+
+// decodeMessageContent 将 Payload 解析到对应类型的字段
+func decodeMessageContent(message *models.Message) {
+	if message.Reactions == nil {
+		message.Reactions = []models.ReactionSummary{}
+	}
+	if message.RecalledAt != nil {
+		message.Content = models.RecalledMessageText
+		return
+	}
+	if message.Payload == "" {
+		return
+	}
+	data := []byte(message.Payload)
+	switch message.Type {
+	case models.MessageTypeImage:
+		message.Image = &models.ImagePayload{}
+		_ = json.Unmarshal(data, message.Image)
+	case models.MessageTypeLocation:
+		message.Location = &models.LocationPayload{}
+		_ = json.Unmarshal(data, message.Location)
+	case models.MessageTypeWorkout:
+		message.Workout = &models.WorkoutShareCard{}
+		_ = json.Unmarshal(data, message.Workout)
+	case models.MessageTypePlan:
+		message.Plan = &models.PlanShareCard{}
+		_ = json.Unmarshal(data, message.Plan)
+	}
+}
+
+// messagePreview 消息摘要，文本截断到 MessagePreviewLength 个字符
+func messagePreview(message models.Message) *models.MessagePreview {
+	content := message.Content
+	if label, ok := models.MessageTypeLabels[message.Type]; ok && content == "" {
+		content = label
+	}
+	if message.RecalledAt != nil {
+		content = models.RecalledMessageText
+	}
+	if utf8.RuneCountInString(content) > models.MessagePreviewLength {
+		content = string([]rune(content)[:models.MessagePreviewLength]) + "…"
+	}
+	return &models.MessagePreview{
+		ID:         message.ID,
+		SenderID:   message.SenderID,
+		SenderName: message.Sender.Name,
+		Type:       message.Type,
+		Content:    content,
+	}
+}
+
+// messageReactions 按消息汇总表情回应
+func messageReactions(messageIDs []uint, userID uint) map[uint][]models.ReactionSummary {
+	result := map[uint][]models.ReactionSummary{}
+	if len(messageIDs) == 0 {
+		return result
+	}
+
+	var reactions []models.MessageReaction
+	config.DB.Where("message_id IN ?", messageIDs).Order("created_at, id").Find(&reactions)
+	for _, reaction := range reactions {
+		summaries := result[reaction.MessageID]
+		index := -1
+		for i := range summaries {
+			if summaries[i].Emoji == reaction.Emoji {
+				index = i
+				break
+			}
+		}
+		if index < 0 {
+			summaries = append(summaries, models.ReactionSummary{Emoji: reaction.Emoji, UserIDs: []uint{}})
+			index = len(summaries) - 1
+		}
+		summaries[index].Count++
+		summaries[index].UserIDs = append(summaries[index].UserIDs, reaction.UserID)
+		if reaction.UserID == userID {
+			summaries[index].Reacted = true
+		}
+		result[reaction.MessageID] = summaries
+	}
+	return result
+}
+
+// reactionsFor 复制表情回应汇总，Reacted 改为 userID 的视角
+func reactionsFor(summaries []models.ReactionSummary, userID uint) []models.ReactionSummary {
+	result := make([]models.ReactionSummary, len(summaries))
+	for i, summary := range summaries {
+		summary.Reacted = false
+		for _, id := range summary.UserIDs {
+			if id == userID {
+				summary.Reacted = true
+				break
+			}
+		}
+		result[i] = summary
+	}
+	return result
+}
+
+// validEmoji 表情为1到8个字符，不能包含空白、字母或数字
+func validEmoji(emoji string) bool {
+	count := utf8.RuneCountInString(emoji)
+	if count == 0 || count > 8 {
+		return false
+	}
+	for _, r := range emoji {
+		if unicode.IsSpace(r) || unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// hiddenMessages 用户仅对自己删除的消息ID子查询
+func hiddenMessages(userID uint) *gorm.DB {
+	return config.DB.Model(&models.MessageDeletion{}).Select("message_id").Where("user_id = ?", userID)
+}
@@ -0,0 +1,256 @@
+package controllers
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"gymates-backend/config"
+	"gymates-backend/models"
+	"gymates-backend/services/search"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// 搜索结果的片段长度和上下文条数
+const (
+	searchSnippetRadius = 30
+	searchContextSize   = 1
+)
+
+// messageSearchState 聊天记录全文索引，补建完已有消息的索引前 ready 为 false
+var messageSearchState struct {
+	sync.Mutex
+	index    *search.Index
+	building bool
+	ready    bool
+}
+
+// StartMessageSearch 创建聊天记录全文索引，并在后台为已有消息补建索引，在启动时调用
+func StartMessageSearch() {
+	messageSearch()
+}
+
+// messageSearch 聊天记录全文索引，ready 表示已有消息的索引已经补建完成
+//
+// 还没有开始补建或上次补建失败时在后台重新补建，正在补建时直接返回。
+func messageSearch() (*search.Index, bool) {
+	messageSearchState.Lock()
+	defer messageSearchState.Unlock()
+	if messageSearchState.index == nil {
+		messageSearchState.index = search.New(config.DB, config.GetDatabaseConfig().Type)
+	}
+	index := messageSearchState.index
+	if messageSearchState.building || messageSearchState.ready {
+		return index, messageSearchState.ready
+	}
+
+	messageSearchState.building = true
+	go func() {
+		err := index.Backfill()
+		messageSearchState.Lock()
+		defer messageSearchState.Unlock()
+		messageSearchState.building = false
+		if err != nil {
+			log.Printf("⚠️  Failed to build message search index: %v", err)
+			return
+		}
+		messageSearchState.ready = true
+		log.Printf("🔍 Message search ready: %s", index.Backend())
+	}()
+	return index, false
+}
+
+// indexMessage 更新消息的全文索引，失败只记录日志，不影响消息本身
+func indexMessage(message models.Message) {
+	index, _ := messageSearch()
+	if err := index.Put(message); err != nil {
+		log.Printf("⚠️  Failed to index message %d: %v", message.ID, err)
+	}
+}
+
+// unindexMessage 删除消息的全文索引
+func unindexMessage(messageID uint) {
+	index, _ := messageSearch()
+	if err := index.Delete(messageID); err != nil {
+		log.Printf("⚠️  Failed to remove message %d from index: %v", messageID, err)
+	}
+}
+
+// SearchMessages 在当前用户参与的聊天中搜索消息
+// GET /api/messages/search?q=&chat_id=&before=&limit=
+//
+// 多个搜索词用空格分隔，结果必须包含每个词；返回高亮片段和前后各一条消息作为上下文。
+func (mc *MessagesController) SearchMessages(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Success: false,
+			Message: "用户未认证",
+			Error:   "User not authenticated",
+			Code:    http.StatusUnauthorized,
+		})
+		return
+	}
+	currentUser := user.(*models.User)
+
+	q := strings.TrimSpace(c.Query("q"))
+	if q == "" || utf8.RuneCountInString(q) > search.MaxQueryLength || len(search.QueryTokens(q)) == 0 {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Message: "请输入1到100个字符的搜索内容",
+			Error:   "Invalid search query",
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if limit < 1 || limit > 50 {
+		limit = 20
+	}
+	before, errBefore := strconv.ParseUint(c.DefaultQuery("before", "0"), 10, 32)
+	chatID, errChat := strconv.ParseUint(c.DefaultQuery("chat_id", "0"), 10, 32)
+	if errBefore != nil || errChat != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Message: "请求参数错误",
+			Error:   "before and chat_id must be IDs",
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	var chatIDs []uint
+	query := config.DB.Model(&models.ChatParticipant{}).Where("user_id = ?", currentUser.ID)
+	if chatID > 0 {
+		query = query.Where("chat_id = ?", uint(chatID))
+	}
+	query.Pluck("chat_id", &chatIDs)
+	if chatID > 0 && len(chatIDs) == 0 {
+		c.JSON(http.StatusForbidden, models.ErrorResponse{
+			Success: false,
+			Message: "无权访问此聊天",
+			Error:   "Access denied",
+			Code:    http.StatusForbidden,
+		})
+		return
+	}
+
+	index, ready := messageSearch()
+	if !ready {
+		c.Header("Retry-After", "30")
+		c.JSON(http.StatusServiceUnavailable, models.ErrorResponse{
+			Success: false,
+			Message: "搜索索引正在建立，请稍后再试",
+			Error:   "Message search index is building",
+			Code:    http.StatusServiceUnavailable,
+		})
+		return
+	}
+	messages, hasMore, err := index.Search(search.Query{
+		Text:    q,
+		UserID:  currentUser.ID,
+		ChatIDs: chatIDs,
+		Before:  uint(before),
+		Limit:   limit,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Success: false,
+			Message: "搜索消息失败",
+			Error:   err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	results := make([]models.MessageSearchResult, len(messages))
+	names := chatDisplayNames(messages, currentUser.ID)
+	for i, message := range messages {
+		snippet, highlights := search.Snippet(search.MessageText(message), q, searchSnippetRadius)
+		results[i] = models.MessageSearchResult{
+			ChatID:     message.ChatID,
+			ChatType:   names[message.ChatID].Type,
+			ChatName:   names[message.ChatID].Name,
+			Snippet:    snippet,
+			Highlights: highlights,
+		}
+		results[i].Before, results[i].After = messageContext(message, currentUser.ID)
+	}
+	renderMessages(messages, currentUser.ID)
+	for i := range messages {
+		results[i].Message = messages[i]
+	}
+
+	cursor := models.MessageCursor{Before: uint(before), HasMore: hasMore}
+	if len(messages) > 0 {
+		cursor.After = messages[0].ID
+		cursor.Before = messages[len(messages)-1].ID
+	}
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "搜索消息成功",
+		Data: models.MessageSearchResponse{
+			Query:   q,
+			Results: results,
+			Cursor:  cursor,
+		},
+	})
+}
+
+// chatDisplayNames 结果所在聊天的类型和显示名称，单聊显示对方的名字
+func chatDisplayNames(messages []models.Message, userID uint) map[uint]models.Chat {
+	ids := []uint{}
+	seen := map[uint]bool{}
+	for _, message := range messages {
+		if !seen[message.ChatID] {
+			seen[message.ChatID] = true
+			ids = append(ids, message.ChatID)
+		}
+	}
+
+	result := map[uint]models.Chat{}
+	if len(ids) == 0 {
+		return result
+	}
+	var chats []models.Chat
+	config.DB.Preload("Participants").Where("id IN ?", ids).Find(&chats)
+	for _, chat := range chats {
+		if chat.Type != models.ChatTypeGroup {
+			for _, participant := range chat.Participants {
+				if participant.ID != userID {
+					chat.Name = participant.Name
+					break
+				}
+			}
+		}
+		result[chat.ID] = chat
+	}
+	return result
+}
+
+// messageContext 消息前后的几条消息（排除当前用户删除的），按时间正序
+func messageContext(message models.Message, userID uint) ([]models.MessagePreview, []models.MessagePreview) {
+	base := func() *gorm.DB {
+		return config.DB.Preload("Sender").Where("chat_id = ? AND id NOT IN (?)", message.ChatID, hiddenMessages(userID)).
+			Limit(searchContextSize)
+	}
+
+	var earlier, later []models.Message
+	base().Where("id < ?", message.ID).Order("id DESC").Find(&earlier)
+	base().Where("id > ?", message.ID).Order("id ASC").Find(&later)
+
+	before := make([]models.MessagePreview, 0, len(earlier))
+	for i := len(earlier) - 1; i >= 0; i-- {
+		before = append(before, *messagePreview(earlier[i]))
+	}
+	after := make([]models.MessagePreview, 0, len(later))
+	for _, m := range later {
+		after = append(after, *messagePreview(m))
+	}
+	return before, after
+}
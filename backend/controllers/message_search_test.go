@@ -0,0 +1,207 @@
+package controllers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"gymates-backend/config"
+	"gymates-backend/models"
+	"gymates-backend/services/search"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestMessageSearchTokens 测试中文 n-gram 分词、匹配和高亮片段
+func TestMessageSearchTokens(t *testing.T) {
+	t.Run("分词", func(t *testing.T) {
+		tests := []struct {
+			name  string
+			text  string
+			doc   []string
+			query []string
+		}{
+			{"汉字输出单字和两字组合", "卧推计划", []string{"卧", "卧推", "推", "推计", "计", "计划", "划"}, []string{"卧推", "推计", "计划"}},
+			{"单个汉字查询", "练", []string{"练"}, []string{"练"}},
+			{"英文按词并转小写", "Push Pull LEGS", []string{"push", "pull", "legs"}, []string{"push", "pull", "legs"}},
+			{"全角转半角，标点分隔", "ＰＰＬ，三分化！", []string{"ppl", "三", "三分", "分", "分化", "化"}, []string{"ppl", "三分", "分化"}},
+			{"中英混排", "5x5深蹲", []string{"5x5", "深", "深蹲", "蹲"}, []string{"5x5", "深蹲"}},
+			{"只有标点", "？！", nil, nil},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				assert.Equal(t, tt.doc, search.Tokens(tt.text))
+				assert.Equal(t, tt.query, search.QueryTokens(tt.text))
+			})
+		}
+		assert.Equal(t, " 深 深蹲 蹲 ", search.Document("深蹲"))
+	})
+
+	t.Run("按原文过滤误匹配", func(t *testing.T) {
+		// "计划" 和 "卧推" 的 bigram 都在，但没有连续的 "推计划"
+		assert.False(t, search.Matches("计划卧推", "推计划"))
+		assert.True(t, search.Matches("我的卧推计划", "推计划"))
+		assert.True(t, search.Matches("周一 PPL 训练", "ppl 周一"))
+		assert.False(t, search.Matches("周一 PPL 训练", "ppl 周二"))
+	})
+
+	t.Run("高亮片段", func(t *testing.T) {
+		snippet, highlights := search.Snippet("分享一下我的卧推计划", "卧推", 3)
+		assert.Equal(t, "…下我的卧推计划", snippet)
+		assert.Equal(t, []models.HighlightRange{{Start: 4, End: 6}}, highlights)
+
+		snippet, highlights = search.Snippet("PPL计划：推、拉、腿", "ppl 推", 10)
+		assert.Equal(t, "PPL计划：推、拉、腿", snippet)
+		assert.Equal(t, []models.HighlightRange{{Start: 0, End: 3}, {Start: 6, End: 7}}, highlights)
+	})
+}
+
+// TestMessageSearch 测试聊天记录搜索的范围、分页和上下文
+func TestMessageSearch(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	setupTestDB(t)
+
+	alice := models.User{Name: "搜索Alice", Email: "search-alice@gymates.com", Password: "x"}
+	bob := models.User{Name: "搜索Bob", Email: "search-bob@gymates.com", Password: "x"}
+	eve := models.User{Name: "搜索Eve", Email: "search-eve@gymates.com", Password: "x"}
+	for _, user := range []*models.User{&alice, &bob, &eve} {
+		require.NoError(t, config.DB.Create(user).Error)
+	}
+	direct := models.Chat{Type: models.ChatTypeDirect}
+	private := models.Chat{Type: models.ChatTypeDirect}
+	require.NoError(t, config.DB.Create(&direct).Error)
+	require.NoError(t, config.DB.Create(&private).Error)
+	for _, participant := range []models.ChatParticipant{
+		{ChatID: direct.ID, UserID: alice.ID}, {ChatID: direct.ID, UserID: bob.ID},
+		{ChatID: private.ID, UserID: bob.ID}, {ChatID: private.ID, UserID: eve.ID},
+	} {
+		require.NoError(t, config.DB.Create(&participant).Error)
+	}
+	weekly := models.WeeklyTrainingPlan{UserID: bob.ID, Name: "上下肢分化", Description: "适合新手的四天计划", IsPublic: true}
+	require.NoError(t, config.DB.Create(&weekly).Error)
+
+	controller := NewMessagesController()
+	router := gin.New()
+	for name, user := range map[string]*models.User{"alice": &alice, "bob": &bob} {
+		group := router.Group("/"+name, withTestUser(user))
+		group.GET("/search", controller.SearchMessages)
+		group.POST("/chats/:id/messages", controller.SendMessage)
+		group.PUT("/chats/:id/messages/:messageId", controller.EditMessage)
+		group.DELETE("/chats/:id/messages/:messageId", controller.DeleteMessage)
+		group.POST("/chats/:id/messages/:messageId/recall", controller.RecallMessage)
+	}
+
+	say := func(t *testing.T, from string, chat models.Chat, request map[string]interface{}) models.Message {
+		var message models.Message
+		request["chat_id"] = chat.ID
+		require.Equal(t, http.StatusCreated, sendJSON(t, router, "POST", "/"+from+"/chats/"+uintToString(chat.ID)+"/messages", request, &message))
+		return message
+	}
+	find := func(t *testing.T, as, query string, extra string) models.MessageSearchResponse {
+		var response models.MessageSearchResponse
+		require.Equal(t, http.StatusOK, sendJSON(t, router, "GET", "/"+as+"/search?q="+url.QueryEscape(query)+extra, nil, &response))
+		return response
+	}
+	ids := func(response models.MessageSearchResponse) []uint {
+		result := []uint{}
+		for _, r := range response.Results {
+			result = append(result, r.Message.ID)
+		}
+		return result
+	}
+
+	t.Run("索引补建完成前提示稍后再试", func(t *testing.T) {
+		messageSearchState.Lock()
+		ready := messageSearchState.ready
+		messageSearchState.building, messageSearchState.ready = true, false
+		messageSearchState.Unlock()
+		defer func() {
+			messageSearchState.Lock()
+			messageSearchState.building, messageSearchState.ready = false, ready
+			messageSearchState.Unlock()
+		}()
+
+		req, _ := http.NewRequest("GET", "/alice/search?q="+url.QueryEscape("卧推"), nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+		assert.Equal(t, "30", w.Header().Get("Retry-After"))
+		assert.Contains(t, w.Body.String(), "Message search index is building")
+	})
+
+	// 启动后在后台补建索引，等补建完成再搜索
+	StartMessageSearch()
+	require.Eventually(t, func() bool {
+		_, ready := messageSearch()
+		return ready
+	}, 5*time.Second, 10*time.Millisecond)
+
+	greeting := say(t, "alice", direct, map[string]interface{}{"content": "周末一起练腿吗"})
+	program := say(t, "bob", direct, map[string]interface{}{"content": "这是我最近在用的卧推计划，每周三练"})
+	reply := say(t, "alice", direct, map[string]interface{}{"content": "收到，谢谢"})
+	shared := say(t, "bob", direct, map[string]interface{}{"type": "plan", "payload": map[string]interface{}{"plan_id": weekly.ID}})
+	say(t, "bob", private, map[string]interface{}{"content": "别告诉别人我的卧推计划"})
+	mixed := say(t, "bob", direct, map[string]interface{}{"content": "计划卧推都别停"})
+
+	t.Run("只搜索参与的聊天，返回片段和上下文", func(t *testing.T) {
+		response := find(t, "alice", "卧推计划", "")
+		require.Equal(t, []uint{program.ID}, ids(response))
+		result := response.Results[0]
+		assert.Equal(t, "搜索Bob", result.ChatName)
+		assert.Equal(t, models.ChatTypeDirect, result.ChatType)
+		assert.Equal(t, "这是我最近在用的卧推计划，每周三练", result.Snippet)
+		assert.Equal(t, []models.HighlightRange{{Start: 8, End: 12}}, result.Highlights)
+		require.Len(t, result.Before, 1)
+		assert.Equal(t, greeting.ID, result.Before[0].ID)
+		require.Len(t, result.After, 1)
+		assert.Equal(t, reply.ID, result.After[0].ID)
+
+		assert.Len(t, find(t, "bob", "卧推计划", "").Results, 2)
+		assert.Len(t, find(t, "bob", "卧推计划", "&chat_id="+uintToString(private.ID)).Results, 1)
+		assert.Equal(t, http.StatusForbidden, sendJSON(t, router, "GET", "/alice/search?q=x&chat_id="+uintToString(private.ID), nil, nil))
+	})
+
+	t.Run("分享卡片按计划名搜索", func(t *testing.T) {
+		response := find(t, "alice", "上下肢", "")
+		require.Equal(t, []uint{shared.ID}, ids(response))
+		assert.Equal(t, []uint{shared.ID}, ids(find(t, "alice", "新手", "")))
+	})
+
+	t.Run("多个词和单字", func(t *testing.T) {
+		assert.ElementsMatch(t, []uint{program.ID, mixed.ID}, ids(find(t, "alice", "卧推 计划", "")))
+		assert.ElementsMatch(t, []uint{greeting.ID, program.ID}, ids(find(t, "alice", "练", "")))
+	})
+
+	t.Run("游标分页", func(t *testing.T) {
+		first := find(t, "alice", "卧推 计划", "&limit=1")
+		require.Equal(t, []uint{mixed.ID}, ids(first))
+		assert.True(t, first.Cursor.HasMore)
+		second := find(t, "alice", "卧推 计划", "&limit=1&before="+uintToString(first.Cursor.Before))
+		assert.Equal(t, []uint{program.ID}, ids(second))
+		assert.False(t, second.Cursor.HasMore)
+	})
+
+	t.Run("编辑、撤回和删除后更新索引", func(t *testing.T) {
+		path := "/alice/chats/" + uintToString(direct.ID) + "/messages/" + uintToString(greeting.ID)
+		require.Equal(t, http.StatusOK, sendJSON(t, router, "PUT", path, models.EditMessageRequest{Content: "周末一起练背吗"}, nil))
+		assert.Empty(t, find(t, "alice", "练腿", "").Results)
+		assert.Equal(t, []uint{greeting.ID}, ids(find(t, "alice", "练背", "")))
+
+		require.Equal(t, http.StatusOK, sendJSON(t, router, "DELETE", "/alice/chats/"+uintToString(direct.ID)+"/messages/"+uintToString(mixed.ID), nil, nil))
+		assert.Equal(t, []uint{program.ID}, ids(find(t, "alice", "卧推", "")))
+		assert.Len(t, find(t, "bob", "都别停", "").Results, 1)
+
+		require.Equal(t, http.StatusOK, sendJSON(t, router, "POST", path+"/recall", nil, nil))
+		assert.Empty(t, find(t, "alice", "练背", "").Results)
+	})
+
+	t.Run("无效的搜索内容", func(t *testing.T) {
+		for _, q := range []string{"", "  ", "？！"} {
+			assert.Equal(t, http.StatusBadRequest, sendJSON(t, router, "GET", "/alice/search?q="+url.QueryEscape(q), nil, nil), q)
+		}
+	})
+}
@@ -1,13 +1,17 @@
 package controllers
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"gymates-backend/config"
 	"gymates-backend/models"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 // MessagesController 消息控制器
@@ -40,7 +44,7 @@ func (mc *MessagesController) GetChats(c *gin.Context) {
 	var total int64
 
 	// 获取用户参与的聊天
-	query := config.DB.Table("chats").
+	query := config.DB.Model(&models.Chat{}).
 		Joins("JOIN chat_participants ON chats.id = chat_participants.chat_id").
 		Where("chat_participants.user_id = ?", currentUser.ID)
 
@@ -49,8 +53,8 @@ func (mc *MessagesController) GetChats(c *gin.Context) {
 
 	// 分页查询
 	offset := (page - 1) * limit
-	if err := query.Preload("Participants").Preload("LastMessage").
-		Offset(offset).Limit(limit).Order("updated_at DESC").Find(&chats).Error; err != nil {
+	if err := query.Preload("Participants").
+		Offset(offset).Limit(limit).Order("chats.updated_at DESC").Find(&chats).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
 			Success: false,
 			Message: "获取聊天列表失败",
@@ -60,6 +64,8 @@ func (mc *MessagesController) GetChats(c *gin.Context) {
 		return
 	}
 
+	attachChatState(chats, currentUser.ID)
+
 	pagination := models.Pagination{
 		Page:       page,
 		Limit:      limit,
@@ -106,7 +112,7 @@ func (mc *MessagesController) GetChat(c *gin.Context) {
 	currentUser := user.(*models.User)
 
 	var chat models.Chat
-	if err := config.DB.Preload("Participants").Preload("LastMessage").
+	if err := config.DB.Preload("Participants").
 		First(&chat, uint(chatID)).Error; err != nil {
 		c.JSON(http.StatusNotFound, models.ErrorResponse{
 			Success: false,
@@ -130,6 +136,10 @@ func (mc *MessagesController) GetChat(c *gin.Context) {
 		return
 	}
 
+	chats := []models.Chat{chat}
+	attachChatState(chats, currentUser.ID)
+	chat = chats[0]
+
 	c.JSON(http.StatusOK, models.SuccessResponse{
 		Success: true,
 		Message: "获取聊天成功",
@@ -138,6 +148,10 @@ func (mc *MessagesController) GetChat(c *gin.Context) {
 }
 
 // GetMessages 获取聊天消息
+// GET /api/messages/chats/:id/messages?before=<消息ID>&after=<消息ID>&limit=20
+//
+// 消息按ID倒序返回（最新的在前）。不带游标时返回最新的一页；before 加载更早的消息，
+// after 加载更新的消息（从紧挨着游标的消息开始，不会漏掉）。新消息不会影响已加载页的游标。
 func (mc *MessagesController) GetMessages(c *gin.Context) {
 	chatIDStr := c.Param("id")
 	chatID, err := strconv.ParseUint(chatIDStr, 10, 32)
@@ -151,8 +165,21 @@ func (mc *MessagesController) GetMessages(c *gin.Context) {
 		return
 	}
 
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+	before, errBefore := strconv.ParseUint(c.DefaultQuery("before", "0"), 10, 32)
+	after, errAfter := strconv.ParseUint(c.DefaultQuery("after", "0"), 10, 32)
+	if errBefore != nil || errAfter != nil || (before > 0 && after > 0) {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Message: "无效的消息游标",
+			Error:   "before and after must be message IDs and cannot be combined",
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
 
 	user, exists := c.Get("user")
 	if !exists {
@@ -180,17 +207,20 @@ func (mc *MessagesController) GetMessages(c *gin.Context) {
 		return
 	}
 
-	var messages []models.Message
-	var total int64
-
-	// 获取总数
-	config.DB.Model(&models.Message{}).Where("chat_id = ?", uint(chatID)).Count(&total)
+	// 多取一条判断请求方向上是否还有消息
+	query := config.DB.Where("chat_id = ? AND id NOT IN (?)", uint(chatID), hiddenMessages(currentUser.ID)).
+		Preload("Sender").Limit(limit + 1)
+	switch {
+	case after > 0:
+		query = query.Where("id > ?", uint(after)).Order("id ASC")
+	case before > 0:
+		query = query.Where("id < ?", uint(before)).Order("id DESC")
+	default:
+		query = query.Order("id DESC")
+	}
 
-	// 分页查询
-	offset := (page - 1) * limit
-	if err := config.DB.Where("chat_id = ?", uint(chatID)).
-		Preload("Sender").
-		Offset(offset).Limit(limit).Order("created_at ASC").Find(&messages).Error; err != nil {
+	messages := []models.Message{}
+	if err := query.Find(&messages).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
 			Success: false,
 			Message: "获取消息失败",
@@ -200,20 +230,29 @@ func (mc *MessagesController) GetMessages(c *gin.Context) {
 		return
 	}
 
-	pagination := models.Pagination{
-		Page:       page,
-		Limit:      limit,
-		Total:      total,
-		TotalPages: int((total + int64(limit) - 1) / int64(limit)),
-		HasMore:    int64(page*limit) < total,
+	cursor := models.MessageCursor{Before: uint(before), After: uint(after), HasMore: len(messages) > limit}
+	if cursor.HasMore {
+		messages = messages[:limit]
 	}
+	if after > 0 {
+		for i, j := 0, len(messages)-1; i < j; i, j = i+1, j-1 {
+			messages[i], messages[j] = messages[j], messages[i]
+		}
+	}
+	if len(messages) > 0 {
+		cursor.After = messages[0].ID
+		cursor.Before = messages[len(messages)-1].ID
+	}
+	renderMessages(messages, currentUser.ID)
 
 	c.JSON(http.StatusOK, models.SuccessResponse{
 		Success: true,
 		Message: "获取消息成功",
 		Data: models.MessagesResponse{
-			Messages:   messages,
-			Pagination: pagination,
+			Messages:          messages,
+			Cursor:            cursor,
+			LastReadMessageID: participant.LastReadMessageID,
+			UnreadCount:       unreadCounts(currentUser.ID, []uint{uint(chatID)})[uint(chatID)],
 		},
 	})
 }
@@ -269,15 +308,43 @@ func (mc *MessagesController) SendMessage(c *gin.Context) {
 		return
 	}
 
-	// 创建消息
-	message := models.Message{
-		ChatID:   uint(chatID),
-		SenderID: currentUser.ID,
-		Content:  req.Content,
-		Type:     req.Type,
-		IsRead:   false,
+	// 单聊中双方有拉黑关系或对方不接收私信时不能发送
+	var chat models.Chat
+	if err := config.DB.Select("id", "type").First(&chat, uint(chatID)).Error; err == nil && chat.Type == models.ChatTypeDirect {
+		for _, otherID := range chatParticipantIDs(chat.ID) {
+			if otherID == currentUser.ID {
+				continue
+			}
+			if err := checkCanMessage(currentUser.ID, otherID); err != nil {
+				writePrivacyError(c, "发送消息失败", err)
+				return
+			}
+		}
 	}
 
+	// 按类型校验内容，系统消息只能由服务端生成
+	message, err := buildMessageContent(currentUser, uint(chatID), req)
+	if err != nil {
+		var contentErr *messageContentError
+		if !errors.As(err, &contentErr) {
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+				Success: false,
+				Message: "发送消息失败",
+				Error:   err.Error(),
+				Code:    http.StatusInternalServerError,
+			})
+			return
+		}
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Message: contentErr.Message,
+			Error:   err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	// 创建消息
 	if err := config.DB.Create(&message).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
 			Success: false,
@@ -288,15 +355,23 @@ func (mc *MessagesController) SendMessage(c *gin.Context) {
 		return
 	}
 
-	// 更新聊天的最后消息和更新时间
-	config.DB.Model(&models.Chat{}).Where("id = ?", uint(chatID)).
-		Updates(map[string]interface{}{
-			"last_message_id": message.ID,
-			"updated_at":      "NOW()",
-		})
+	// 更新聊天的更新时间，发送者自己已读到这条消息
+	config.DB.Model(&models.Chat{}).Where("id = ?", uint(chatID)).Update("updated_at", time.Now())
+	config.DB.Model(&participant).Updates(map[string]interface{}{
+		"last_read_message_id": message.ID,
+		"last_read_at":         time.Now(),
+	})
 
 	// 重新加载数据
 	config.DB.Preload("Sender").First(&message, message.ID)
+	indexMessage(message)
+	rendered := []models.Message{message}
+	renderMessages(rendered, currentUser.ID)
+	message = rendered[0]
+
+	// 推送给在线的参与者（包括发送者的其他设备），静音了发送者的用户不推送
+	realtimeBroker.Publish(models.NewRealtimeEvent(models.RealtimeEventMessage, message.ChatID, currentUser.ID, message),
+		unmutedRecipients(currentUser.ID, chatParticipantIDs(message.ChatID))...)
 
 	c.JSON(http.StatusCreated, models.SuccessResponse{
 		Success: true,
@@ -334,9 +409,28 @@ func (mc *MessagesController) CreateChat(c *gin.Context) {
 
 	currentUser := user.(*models.User)
 
+	// 去重并去掉自己
+	otherIDs := []uint{}
+	seen := map[uint]bool{currentUser.ID: true}
+	for _, id := range req.ParticipantIDs {
+		if !seen[id] {
+			seen[id] = true
+			otherIDs = append(otherIDs, id)
+		}
+	}
+	if len(otherIDs) == 0 {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Message: "请至少选择一位其他参与者",
+			Error:   "No other participants",
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
 	// 检查参与者是否存在
 	var participants []models.User
-	if err := config.DB.Where("id IN ?", req.ParticipantIDs).Find(&participants).Error; err != nil {
+	if err := config.DB.Where("id IN ?", otherIDs).Order("id").Find(&participants).Error; err != nil || len(participants) != len(otherIDs) {
 		c.JSON(http.StatusBadRequest, models.ErrorResponse{
 			Success: false,
 			Message: "部分参与者不存在",
@@ -346,10 +440,31 @@ func (mc *MessagesController) CreateChat(c *gin.Context) {
 		return
 	}
 
+	// 有拉黑关系或对方不接收私信时不能发起聊天
+	if err := checkCanMessageAll(currentUser.ID, otherIDs); err != nil {
+		writePrivacyError(c, "创建聊天失败", err)
+		return
+	}
+
+	// 多位参与者时创建群聊，群名默认为成员名
+	if len(otherIDs) > 1 {
+		chat := models.Chat{Name: defaultGroupName(currentUser, participants)}
+		if err := createGroupChat(&chat, currentUser, otherIDs); err != nil {
+			writeGroupError(c, "创建聊天失败", err)
+			return
+		}
+		c.JSON(http.StatusCreated, models.SuccessResponse{
+			Success: true,
+			Message: "创建聊天成功",
+			Data:    chat,
+		})
+		return
+	}
+
 	// 添加当前用户到参与者列表
-	participantIDs := append(req.ParticipantIDs, currentUser.ID)
+	participantIDs := []uint{otherIDs[0], currentUser.ID}
 
-	// 检查是否已存在相同的聊天
+	// 检查是否已存在相同的单聊
 	var existingChat models.Chat
 	subQuery := config.DB.Table("chat_participants").
 		Select("chat_id").
@@ -357,7 +472,7 @@ func (mc *MessagesController) CreateChat(c *gin.Context) {
 		Group("chat_id").
 		Having("COUNT(DISTINCT user_id) = ?", len(participantIDs))
 
-	if err := config.DB.Where("id IN (?)", subQuery).First(&existingChat).Error; err == nil {
+	if err := config.DB.Where("id IN (?) AND type = ?", subQuery, models.ChatTypeDirect).First(&existingChat).Error; err == nil {
 		c.JSON(http.StatusConflict, models.ErrorResponse{
 			Success: false,
 			Message: "聊天已存在",
@@ -368,7 +483,7 @@ func (mc *MessagesController) CreateChat(c *gin.Context) {
 	}
 
 	// 创建聊天
-	chat := models.Chat{}
+	chat := models.Chat{Type: models.ChatTypeDirect}
 	if err := config.DB.Create(&chat).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
 			Success: false,
@@ -380,10 +495,13 @@ func (mc *MessagesController) CreateChat(c *gin.Context) {
 	}
 
 	// 添加参与者
+	now := time.Now()
 	for _, participantID := range participantIDs {
 		participant := models.ChatParticipant{
-			ChatID: chat.ID,
-			UserID: participantID,
+			ChatID:   chat.ID,
+			UserID:   participantID,
+			Role:     models.ChatRoleMember,
+			JoinedAt: &now,
 		}
 		config.DB.Create(&participant)
 	}
@@ -438,10 +556,23 @@ func (mc *MessagesController) MarkAsRead(c *gin.Context) {
 		return
 	}
 
-	// 标记该聊天中所有消息为已读
-	if err := config.DB.Model(&models.Message{}).
-		Where("chat_id = ? AND sender_id != ?", uint(chatID), currentUser.ID).
-		Update("is_read", true).Error; err != nil {
+	// 请求体可选，为空时标记到最新一条消息
+	var req models.MarkAsReadRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Success: false,
+				Message: "请求参数错误",
+				Error:   err.Error(),
+				Code:    http.StatusBadRequest,
+			})
+			return
+		}
+	}
+
+	// 推进已读位置，并推送已读回执
+	receipt, err := markChatRead(uint(chatID), currentUser.ID, req.MessageID, chatParticipantIDs(uint(chatID)))
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
 			Success: false,
 			Message: "标记已读失败",
@@ -454,9 +585,417 @@ func (mc *MessagesController) MarkAsRead(c *gin.Context) {
 	c.JSON(http.StatusOK, models.SuccessResponse{
 		Success: true,
 		Message: "标记已读成功",
+		Data:    receipt,
+	})
+}
+
+// AddReaction 对消息添加表情回应，重复添加不报错
+// POST /api/messages/chats/:id/messages/:messageId/reactions
+func (mc *MessagesController) AddReaction(c *gin.Context) {
+	currentUser, _, message, ok := participantMessage(c)
+	if !ok {
+		return
+	}
+
+	var req models.AddReactionRequest
+	if err := c.ShouldBindJSON(&req); err != nil || !validEmoji(req.Emoji) {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Message: "无效的表情",
+			Error:   "Emoji must be 1-8 non-alphanumeric characters",
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	var kinds int64
+	config.DB.Model(&models.MessageReaction{}).Where("message_id = ? AND emoji <> ?", message.ID, req.Emoji).
+		Distinct("emoji").Count(&kinds)
+	if kinds >= models.MaxReactionKinds {
+		c.JSON(http.StatusConflict, models.ErrorResponse{
+			Success: false,
+			Message: "这条消息的表情种类已达上限",
+			Error:   "Too many reaction kinds",
+			Code:    http.StatusConflict,
+		})
+		return
+	}
+
+	reaction := models.MessageReaction{MessageID: message.ID, UserID: currentUser.ID, Emoji: req.Emoji}
+	if err := config.DB.Where(reaction).FirstOrCreate(&reaction).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Success: false,
+			Message: "添加表情回应失败",
+			Error:   err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "添加表情回应成功",
+		Data:    publishReactions(message, currentUser.ID),
+	})
+}
+
+// RemoveReaction 取消自己的表情回应
+// DELETE /api/messages/chats/:id/messages/:messageId/reactions/:emoji
+func (mc *MessagesController) RemoveReaction(c *gin.Context) {
+	currentUser, _, message, ok := participantMessage(c)
+	if !ok {
+		return
+	}
+
+	if err := config.DB.Where("message_id = ? AND user_id = ? AND emoji = ?", message.ID, currentUser.ID, c.Param("emoji")).
+		Delete(&models.MessageReaction{}).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Success: false,
+			Message: "取消表情回应失败",
+			Error:   err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "取消表情回应成功",
+		Data:    publishReactions(message, currentUser.ID),
+	})
+}
+
+// EditMessage 编辑自己发送的消息，保留编辑前的内容
+// PUT /api/messages/chats/:id/messages/:messageId
+func (mc *MessagesController) EditMessage(c *gin.Context) {
+	currentUser, _, message, ok := participantMessage(c)
+	if !ok {
+		return
+	}
+
+	var req models.EditMessageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Message: "请求参数错误",
+			Error:   err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+	if message.SenderID != currentUser.ID || message.Type == models.MessageTypeSystem {
+		c.JSON(http.StatusForbidden, models.ErrorResponse{
+			Success: false,
+			Message: "只能编辑自己发送的消息",
+			Error:   "Only the sender can edit this message",
+			Code:    http.StatusForbidden,
+		})
+		return
+	}
+	if message.RecalledAt != nil {
+		c.JSON(http.StatusConflict, models.ErrorResponse{
+			Success: false,
+			Message: "消息已撤回",
+			Error:   "Message has been recalled",
+			Code:    http.StatusConflict,
+		})
+		return
+	}
+	content := strings.TrimSpace(req.Content)
+	if content == "" && message.Type == models.MessageTypeText {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Message: "消息内容不能为空",
+			Error:   "content is required for text messages",
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	if content != message.Content {
+		now := time.Now()
+		err := config.DB.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Create(&models.MessageEdit{MessageID: message.ID, Content: message.Content, EditedBy: currentUser.ID}).Error; err != nil {
+				return err
+			}
+			return tx.Model(&message).Updates(map[string]interface{}{"content": content, "edited_at": now}).Error
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+				Success: false,
+				Message: "编辑消息失败",
+				Error:   err.Error(),
+				Code:    http.StatusInternalServerError,
+			})
+			return
+		}
+	}
+
+	message = publishMessageChange(message.ID, currentUser.ID, models.RealtimeEventEdited)
+	indexMessage(message)
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "编辑消息成功",
+		Data:    message,
+	})
+}
+
+// GetMessageEdits 获取消息的编辑历史
+// GET /api/messages/chats/:id/messages/:messageId/edits
+func (mc *MessagesController) GetMessageEdits(c *gin.Context) {
+	_, _, message, ok := participantMessage(c)
+	if !ok {
+		return
+	}
+
+	edits := []models.MessageEdit{}
+	config.DB.Where("message_id = ?", message.ID).Order("id DESC").Find(&edits)
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "获取编辑历史成功",
+		Data: models.MessageEditsResponse{
+			MessageID: message.ID,
+			Current:   message.Content,
+			EditedAt:  message.EditedAt,
+			Edits:     edits,
+		},
+	})
+}
+
+// RecallMessage 撤回消息，其他人看到"消息已撤回"
+// POST /api/messages/chats/:id/messages/:messageId/recall
+//
+// 发送者可以在撤回时限内撤回；群主和管理员可以随时撤回角色低于自己的成员的消息。
+// 撤回会清空内容、编辑历史和表情回应。
+func (mc *MessagesController) RecallMessage(c *gin.Context) {
+	currentUser, participant, message, ok := participantMessage(c)
+	if !ok {
+		return
+	}
+	if message.Type == models.MessageTypeSystem {
+		c.JSON(http.StatusForbidden, models.ErrorResponse{
+			Success: false,
+			Message: "系统消息不能撤回",
+			Error:   "System messages cannot be recalled",
+			Code:    http.StatusForbidden,
+		})
+		return
+	}
+	if message.RecalledAt != nil {
+		c.JSON(http.StatusConflict, models.ErrorResponse{
+			Success: false,
+			Message: "消息已撤回",
+			Error:   "Message has been recalled",
+			Code:    http.StatusConflict,
+		})
+		return
+	}
+	if message.SenderID == currentUser.ID {
+		if window := config.GetMessageRecallWindow(); window > 0 && time.Since(message.CreatedAt) > window {
+			c.JSON(http.StatusForbidden, models.ErrorResponse{
+				Success: false,
+				Message: "已超过撤回时限",
+				Error:   "Recall window has passed",
+				Code:    http.StatusForbidden,
+			})
+			return
+		}
+	} else if !canModerateMessage(participant, message) {
+		c.JSON(http.StatusForbidden, models.ErrorResponse{
+			Success: false,
+			Message: "只能撤回自己发送的消息",
+			Error:   "Access denied",
+			Code:    http.StatusForbidden,
+		})
+		return
+	}
+
+	now := time.Now()
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("message_id = ?", message.ID).Delete(&models.MessageEdit{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("message_id = ?", message.ID).Delete(&models.MessageReaction{}).Error; err != nil {
+			return err
+		}
+		return tx.Model(&message).Updates(map[string]interface{}{
+			"content":     "",
+			"payload":     "",
+			"recalled_at": now,
+			"recalled_by": currentUser.ID,
+		}).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Success: false,
+			Message: "撤回消息失败",
+			Error:   err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	unindexMessage(message.ID)
+	message = publishMessageChange(message.ID, currentUser.ID, models.RealtimeEventRecalled)
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "撤回消息成功",
+		Data:    message,
+	})
+}
+
+// DeleteMessage 删除消息，scope=me 仅对自己隐藏，scope=everyone 对所有人删除
+// DELETE /api/messages/chats/:id/messages/:messageId?scope=me
+//
+// 对所有人删除需要是发送者，或是角色高于发送者的群主、管理员。
+func (mc *MessagesController) DeleteMessage(c *gin.Context) {
+	currentUser, participant, message, ok := participantMessage(c)
+	if !ok {
+		return
+	}
+
+	switch c.DefaultQuery("scope", models.DeleteScopeMe) {
+	case models.DeleteScopeMe:
+		deletion := models.MessageDeletion{MessageID: message.ID, UserID: currentUser.ID}
+		if err := config.DB.Where(deletion).FirstOrCreate(&deletion).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+				Success: false,
+				Message: "删除消息失败",
+				Error:   err.Error(),
+				Code:    http.StatusInternalServerError,
+			})
+			return
+		}
+		// 只同步给自己的其他设备
+		realtimeBroker.Publish(models.NewRealtimeEvent(models.RealtimeEventDeleted, message.ChatID, currentUser.ID,
+			models.MessageDeletedEvent{MessageID: message.ID, Scope: models.DeleteScopeMe}), currentUser.ID)
+
+	case models.DeleteScopeEveryone:
+		if message.Type == models.MessageTypeSystem ||
+			(message.SenderID != currentUser.ID && !canModerateMessage(participant, message)) {
+			c.JSON(http.StatusForbidden, models.ErrorResponse{
+				Success: false,
+				Message: "无权对所有人删除此消息",
+				Error:   "Access denied",
+				Code:    http.StatusForbidden,
+			})
+			return
+		}
+		err := config.DB.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Where("message_id = ?", message.ID).Delete(&models.MessageReaction{}).Error; err != nil {
+				return err
+			}
+			return tx.Delete(&message).Error
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+				Success: false,
+				Message: "删除消息失败",
+				Error:   err.Error(),
+				Code:    http.StatusInternalServerError,
+			})
+			return
+		}
+		unindexMessage(message.ID)
+		realtimeBroker.Publish(models.NewRealtimeEvent(models.RealtimeEventDeleted, message.ChatID, currentUser.ID,
+			models.MessageDeletedEvent{MessageID: message.ID, Scope: models.DeleteScopeEveryone}),
+			chatParticipantIDs(message.ChatID)...)
+
+	default:
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Message: "无效的删除范围",
+			Error:   "scope must be me or everyone",
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "删除消息成功",
 	})
 }
 
+// canModerateMessage 群主和管理员可以管理角色低于自己的成员的消息，已退群成员的消息视为最低角色
+func canModerateMessage(actor models.ChatParticipant, message models.Message) bool {
+	var chat models.Chat
+	if err := config.DB.Where("id = ? AND type = ?", message.ChatID, models.ChatTypeGroup).First(&chat).Error; err != nil {
+		return false
+	}
+	var sender models.ChatParticipant
+	config.DB.Where("chat_id = ? AND user_id = ?", message.ChatID, message.SenderID).First(&sender)
+	return chatRoleRank[actor.Role] >= chatRoleRank[models.ChatRoleAdmin] &&
+		chatRoleRank[actor.Role] > chatRoleRank[sender.Role]
+}
+
+// publishMessageChange 重新加载消息并按各参与者的视角推送，返回当前用户视角的消息
+func publishMessageChange(messageID, userID uint, eventType string) models.Message {
+	var message models.Message
+	config.DB.Preload("Sender").First(&message, messageID)
+	rendered := []models.Message{message}
+	renderMessages(rendered, userID)
+	for _, participantID := range chatParticipantIDs(rendered[0].ChatID) {
+		view := rendered[0]
+		view.Reactions = reactionsFor(rendered[0].Reactions, participantID)
+		realtimeBroker.Publish(models.NewRealtimeEvent(eventType, view.ChatID, userID, view), participantID)
+	}
+	return rendered[0]
+}
+
+// participantMessage 获取路径中的消息和当前用户的参与记录，失败时已写入响应
+func participantMessage(c *gin.Context) (*models.User, models.ChatParticipant, models.Message, bool) {
+	var message models.Message
+	var participant models.ChatParticipant
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Success: false,
+			Message: "用户未认证",
+			Error:   "User not authenticated",
+			Code:    http.StatusUnauthorized,
+		})
+		return nil, participant, message, false
+	}
+	currentUser := user.(*models.User)
+
+	if err := config.DB.Where("chat_id = ? AND user_id = ?", c.Param("id"), currentUser.ID).First(&participant).Error; err != nil {
+		c.JSON(http.StatusForbidden, models.ErrorResponse{
+			Success: false,
+			Message: "无权访问此聊天",
+			Error:   "Access denied",
+			Code:    http.StatusForbidden,
+		})
+		return currentUser, participant, message, false
+	}
+
+	if err := config.DB.Where("id = ? AND chat_id = ?", c.Param("messageId"), participant.ChatID).First(&message).Error; err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Success: false,
+			Message: "消息不存在",
+			Error:   "Message not found",
+			Code:    http.StatusNotFound,
+		})
+		return currentUser, participant, message, false
+	}
+	return currentUser, participant, message, true
+}
+
+// publishReactions 按各参与者的视角推送消息最新的表情回应，返回当前用户视角的汇总
+func publishReactions(message models.Message, userID uint) []models.ReactionSummary {
+	reactions := messageReactions([]uint{message.ID}, userID)[message.ID]
+	if reactions == nil {
+		reactions = []models.ReactionSummary{}
+	}
+	for _, participantID := range chatParticipantIDs(message.ChatID) {
+		realtimeBroker.Publish(models.NewRealtimeEvent(models.RealtimeEventReaction, message.ChatID, userID,
+			models.MessageReactionsEvent{MessageID: message.ID, Reactions: reactionsFor(reactions, participantID)}),
+			participantID)
+	}
+	return reactions
+}
+
 // GetUnreadCount 获取未读消息数量
 func (mc *MessagesController) GetUnreadCount(c *gin.Context) {
 	user, exists := c.Get("user")
@@ -472,27 +1011,15 @@ func (mc *MessagesController) GetUnreadCount(c *gin.Context) {
 
 	currentUser := user.(*models.User)
 
-	var result struct {
-		Total  int64          `json:"total"`
-		ByChat map[uint]int64 `json:"by_chat"`
-	}
-
-	result.ByChat = make(map[uint]int64)
-
 	// 获取用户参与的聊天
 	var chatIDs []uint
-	config.DB.Table("chat_participants").
-		Select("chat_id").
+	config.DB.Model(&models.ChatParticipant{}).
 		Where("user_id = ?", currentUser.ID).
 		Pluck("chat_id", &chatIDs)
 
-	// 统计每个聊天的未读消息数
-	for _, chatID := range chatIDs {
-		var count int64
-		config.DB.Model(&models.Message{}).
-			Where("chat_id = ? AND sender_id != ? AND is_read = ?", chatID, currentUser.ID, false).
-			Count(&count)
-		result.ByChat[chatID] = count
+	// 每个聊天中已读位置之后其他人发送的消息为未读
+	result := models.UnreadCountResponse{ByChat: unreadCounts(currentUser.ID, chatIDs)}
+	for _, count := range result.ByChat {
 		result.Total += count
 	}
 
@@ -502,3 +1029,63 @@ func (mc *MessagesController) GetUnreadCount(c *gin.Context) {
 		Data:    result,
 	})
 }
+
+// unreadCounts 统计用户在各聊天中的未读数：已读位置之后其他人发送的消息
+func unreadCounts(userID uint, chatIDs []uint) map[uint]int64 {
+	counts := make(map[uint]int64, len(chatIDs))
+	for _, chatID := range chatIDs {
+		counts[chatID] = 0
+	}
+	if len(chatIDs) == 0 {
+		return counts
+	}
+
+	var rows []struct {
+		ChatID uint
+		Count  int64
+	}
+	config.DB.Model(&models.Message{}).
+		Select("messages.chat_id, COUNT(*) AS count").
+		Joins("JOIN chat_participants ON chat_participants.chat_id = messages.chat_id AND chat_participants.user_id = ?", userID).
+		Where("messages.chat_id IN ? AND messages.sender_id != ? AND messages.id > chat_participants.last_read_message_id", chatIDs, userID).
+		Where("messages.id NOT IN (?)", hiddenMessages(userID)).
+		Group("messages.chat_id").
+		Scan(&rows)
+	for _, row := range rows {
+		counts[row.ChatID] = row.Count
+	}
+	return counts
+}
+
+// attachChatState 填充聊天的最新消息和当前用户的未读数
+func attachChatState(chats []models.Chat, userID uint) {
+	if len(chats) == 0 {
+		return
+	}
+	chatIDs := make([]uint, len(chats))
+	for i, chat := range chats {
+		chatIDs[i] = chat.ID
+	}
+
+	var lastIDs []uint
+	config.DB.Model(&models.Message{}).
+		Select("MAX(id)").
+		Where("chat_id IN ? AND id NOT IN (?)", chatIDs, hiddenMessages(userID)).
+		Group("chat_id").
+		Pluck("MAX(id)", &lastIDs)
+	var lastMessages []models.Message
+	if len(lastIDs) > 0 {
+		config.DB.Preload("Sender").Where("id IN ?", lastIDs).Find(&lastMessages)
+	}
+	renderMessages(lastMessages, userID)
+	byChat := make(map[uint]*models.Message, len(lastMessages))
+	for i := range lastMessages {
+		byChat[lastMessages[i].ChatID] = &lastMessages[i]
+	}
+
+	unread := unreadCounts(userID, chatIDs)
+	for i := range chats {
+		chats[i].LastMessage = byChat[chats[i].ID]
+		chats[i].UnreadCount = unread[chats[i].ID]
+	}
+}
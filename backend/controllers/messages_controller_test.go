@@ -0,0 +1,558 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"gymates-backend/config"
+	"gymates-backend/models"
+	"gymates-backend/services/realtime"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// TestMessageHistory 测试消息的游标分页和按参与者计算的未读数
+func TestMessageHistory(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	setupTestDB(t)
+
+	alice := models.User{Name: "游标Alice", Email: "cursor-alice@gymates.com", Password: "x"}
+	bob := models.User{Name: "游标Bob", Email: "cursor-bob@gymates.com", Password: "x"}
+	carol := models.User{Name: "游标Carol", Email: "cursor-carol@gymates.com", Password: "x"}
+	for _, user := range []*models.User{&alice, &bob, &carol} {
+		require.NoError(t, config.DB.Create(user).Error)
+	}
+	chat := models.Chat{}
+	require.NoError(t, config.DB.Create(&chat).Error)
+	for _, user := range []models.User{alice, bob, carol} {
+		require.NoError(t, config.DB.Create(&models.ChatParticipant{ChatID: chat.ID, UserID: user.ID}).Error)
+	}
+
+	controller := NewMessagesController()
+	router := gin.New()
+	for name, user := range map[string]*models.User{"alice": &alice, "bob": &bob, "carol": &carol} {
+		group := router.Group("/"+name, withTestUser(user))
+		group.GET("/chats", controller.GetChats)
+		group.GET("/chats/:id", controller.GetChat)
+		group.GET("/chats/:id/messages", controller.GetMessages)
+		group.POST("/chats/:id/messages", controller.SendMessage)
+		group.PUT("/chats/:id/read", controller.MarkAsRead)
+		group.GET("/unread", controller.GetUnreadCount)
+	}
+
+	chatPath := "/chats/" + uintToString(chat.ID)
+	say := func(from, content string) models.Message {
+		var message models.Message
+		require.Equal(t, http.StatusCreated, sendJSON(t, router, "POST", "/"+from+chatPath+"/messages", models.SendMessageRequest{ChatID: chat.ID, Content: content}, &message))
+		return message
+	}
+
+	var sent []models.Message
+	for i := 0; i < 25; i++ {
+		from := "alice"
+		if i%5 == 4 {
+			from = "bob"
+		}
+		sent = append(sent, say(from, "消息"+uintToString(uint(i+1))))
+	}
+	ids := func(messages []models.Message) []uint {
+		result := []uint{}
+		for _, message := range messages {
+			result = append(result, message.ID)
+		}
+		return result
+	}
+	// want 返回 sent 中 [from, to) 的消息ID，按最新在前
+	want := func(from, to int) []uint {
+		result := []uint{}
+		for i := to - 1; i >= from; i-- {
+			result = append(result, sent[i].ID)
+		}
+		return result
+	}
+
+	t.Run("最新的一页在前，before加载更早的消息", func(t *testing.T) {
+		var page models.MessagesResponse
+		require.Equal(t, http.StatusOK, sendJSON(t, router, "GET", "/bob"+chatPath+"/messages?limit=10", nil, &page))
+		assert.Equal(t, want(15, 25), ids(page.Messages))
+		assert.True(t, page.Cursor.HasMore)
+		assert.Equal(t, sent[15].ID, page.Cursor.Before)
+		assert.Equal(t, sent[24].ID, page.Cursor.After)
+
+		// 新消息不影响更早一页的内容
+		sent = append(sent, say("alice", "消息26"))
+		var older models.MessagesResponse
+		require.Equal(t, http.StatusOK, sendJSON(t, router, "GET", "/bob"+chatPath+"/messages?limit=10&before="+uintToString(page.Cursor.Before), nil, &older))
+		assert.Equal(t, want(5, 15), ids(older.Messages))
+		assert.True(t, older.Cursor.HasMore)
+
+		var oldest models.MessagesResponse
+		require.Equal(t, http.StatusOK, sendJSON(t, router, "GET", "/bob"+chatPath+"/messages?limit=10&before="+uintToString(older.Cursor.Before), nil, &oldest))
+		assert.Equal(t, want(0, 5), ids(oldest.Messages))
+		assert.False(t, oldest.Cursor.HasMore)
+	})
+
+	t.Run("after加载更新的消息", func(t *testing.T) {
+		var newer models.MessagesResponse
+		require.Equal(t, http.StatusOK, sendJSON(t, router, "GET", "/bob"+chatPath+"/messages?limit=3&after="+uintToString(sent[20].ID), nil, &newer))
+		assert.Equal(t, want(21, 24), ids(newer.Messages))
+		assert.True(t, newer.Cursor.HasMore)
+		assert.Equal(t, sent[23].ID, newer.Cursor.After)
+
+		require.Equal(t, http.StatusOK, sendJSON(t, router, "GET", "/bob"+chatPath+"/messages?limit=3&after="+uintToString(newer.Cursor.After), nil, &newer))
+		assert.Equal(t, want(24, 26), ids(newer.Messages))
+		assert.False(t, newer.Cursor.HasMore)
+
+		var empty models.MessagesResponse
+		require.Equal(t, http.StatusOK, sendJSON(t, router, "GET", "/bob"+chatPath+"/messages?after="+uintToString(sent[25].ID), nil, &empty))
+		assert.Empty(t, empty.Messages)
+		assert.Equal(t, sent[25].ID, empty.Cursor.After)
+	})
+
+	t.Run("无效的游标", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, sendJSON(t, router, "GET", "/bob"+chatPath+"/messages?before=abc", nil, nil))
+		assert.Equal(t, http.StatusBadRequest, sendJSON(t, router, "GET", "/bob"+chatPath+"/messages?before=5&after=3", nil, nil))
+	})
+
+	unread := func(name string) models.UnreadCountResponse {
+		var result models.UnreadCountResponse
+		require.Equal(t, http.StatusOK, sendJSON(t, router, "GET", "/"+name+"/unread", nil, &result))
+		return result
+	}
+
+	t.Run("每个参与者的未读数独立计算", func(t *testing.T) {
+		// 发送消息即已读到自己的消息：Bob 最后一条是第25条，Alice 发了最后一条
+		assert.Equal(t, int64(1), unread("bob").ByChat[chat.ID])
+		assert.Equal(t, int64(26), unread("carol").ByChat[chat.ID])
+		assert.Equal(t, int64(0), unread("alice").ByChat[chat.ID])
+
+		var chats models.ChatsResponse
+		require.Equal(t, http.StatusOK, sendJSON(t, router, "GET", "/carol/chats", nil, &chats))
+		require.Len(t, chats.Chats, 1)
+		assert.Equal(t, int64(26), chats.Chats[0].UnreadCount)
+		require.NotNil(t, chats.Chats[0].LastMessage)
+		assert.Equal(t, sent[25].ID, chats.Chats[0].LastMessage.ID)
+		assert.Equal(t, alice.Name, chats.Chats[0].LastMessage.Sender.Name)
+	})
+
+	t.Run("标记已读到指定消息", func(t *testing.T) {
+		var receipt models.ReadReceipt
+		require.Equal(t, http.StatusOK, sendJSON(t, router, "PUT", "/carol"+chatPath+"/read", models.MarkAsReadRequest{MessageID: sent[9].ID}, &receipt))
+		assert.Equal(t, sent[9].ID, receipt.LastReadMessageID)
+		assert.Equal(t, int64(10), receipt.Count)
+		assert.Equal(t, int64(16), unread("carol").ByChat[chat.ID])
+		assert.Equal(t, int64(1), unread("bob").ByChat[chat.ID])
+
+		// 已读位置不会后退
+		require.Equal(t, http.StatusOK, sendJSON(t, router, "PUT", "/carol"+chatPath+"/read", models.MarkAsReadRequest{MessageID: sent[2].ID}, &receipt))
+		assert.Equal(t, sent[9].ID, receipt.LastReadMessageID)
+		assert.Equal(t, int64(0), receipt.Count)
+
+		var page models.MessagesResponse
+		require.Equal(t, http.StatusOK, sendJSON(t, router, "GET", "/carol"+chatPath+"/messages?limit=1", nil, &page))
+		assert.Equal(t, sent[9].ID, page.LastReadMessageID)
+		assert.Equal(t, int64(16), page.UnreadCount)
+
+		// 不带请求体时标记到最新一条
+		require.Equal(t, http.StatusOK, sendJSON(t, router, "PUT", "/carol"+chatPath+"/read", nil, &receipt))
+		assert.Equal(t, sent[25].ID, receipt.LastReadMessageID)
+		assert.Equal(t, int64(16), receipt.Count)
+		assert.Equal(t, int64(0), unread("carol").Total)
+	})
+
+	t.Run("发送消息后其他人的未读数增加", func(t *testing.T) {
+		say("carol", "我也来")
+		assert.Equal(t, int64(2), unread("bob").ByChat[chat.ID])
+		assert.Equal(t, int64(1), unread("alice").ByChat[chat.ID])
+		assert.Equal(t, int64(0), unread("carol").ByChat[chat.ID])
+
+		var detail models.Chat
+		require.Equal(t, http.StatusOK, sendJSON(t, router, "GET", "/alice"+chatPath, nil, &detail))
+		assert.Equal(t, int64(1), detail.UnreadCount)
+		assert.Equal(t, "我也来", detail.LastMessage.Content)
+	})
+}
+
+// TestRichMessages 测试按类型校验的消息内容、回复和表情回应
+func TestRichMessages(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	setupTestDB(t)
+
+	alice := models.User{Name: "富文本Alice", Email: "rich-alice@gymates.com", Password: "x"}
+	bob := models.User{Name: "富文本Bob", Email: "rich-bob@gymates.com", Password: "x"}
+	for _, user := range []*models.User{&alice, &bob} {
+		require.NoError(t, config.DB.Create(user).Error)
+	}
+	chat := models.Chat{}
+	require.NoError(t, config.DB.Create(&chat).Error)
+	for _, user := range []models.User{alice, bob} {
+		require.NoError(t, config.DB.Create(&models.ChatParticipant{ChatID: chat.ID, UserID: user.ID}).Error)
+	}
+
+	plan := models.TrainingPlan{UserID: alice.ID, Name: "胸背日", Duration: 60, CaloriesBurned: 300,
+		Exercises: []models.Exercise{{Name: "卧推", Sets: 4, Reps: 8}, {Name: "划船", Sets: 4, Reps: 10}}}
+	require.NoError(t, config.DB.Create(&plan).Error)
+	start := time.Now().Add(-time.Hour)
+	end := start.Add(45 * time.Minute)
+	session := models.WorkoutSession{UserID: alice.ID, TrainingPlanID: plan.ID, StartTime: start, EndTime: &end,
+		Status: "completed", Progress: 100, TotalCalories: 320}
+	require.NoError(t, config.DB.Create(&session).Error)
+	bobSession := models.WorkoutSession{UserID: bob.ID, TrainingPlanID: plan.ID, StartTime: start}
+	require.NoError(t, config.DB.Create(&bobSession).Error)
+
+	weekly := models.WeeklyTrainingPlan{UserID: bob.ID, Name: "三分化", IsPublic: true, Days: []models.TrainingDay{
+		{DayOfWeek: 1, DayName: "周一", Parts: []models.TrainingPart{{MuscleGroup: "chest", MuscleGroupName: "胸部", Order: 1,
+			Exercises: []models.Exercise{{Name: "卧推", Sets: 4, Reps: 8}}}}},
+		{DayOfWeek: 2, DayName: "周二", IsRestDay: true},
+		{DayOfWeek: 3, DayName: "周三", Parts: []models.TrainingPart{{MuscleGroup: "back", MuscleGroupName: "背部", Order: 1,
+			Exercises: []models.Exercise{{Name: "引体向上", Sets: 4, Reps: 6}, {Name: "划船", Sets: 4, Reps: 10}}}}},
+	}}
+	require.NoError(t, config.DB.Create(&weekly).Error)
+	private := models.WeeklyTrainingPlan{UserID: bob.ID, Name: "私有计划"}
+	require.NoError(t, config.DB.Create(&private).Error)
+	require.NoError(t, config.DB.Model(&private).Update("is_public", false).Error)
+
+	controller := NewMessagesController()
+	router := gin.New()
+	for name, user := range map[string]*models.User{"alice": &alice, "bob": &bob} {
+		group := router.Group("/"+name, withTestUser(user))
+		group.GET("/chats/:id/messages", controller.GetMessages)
+		group.POST("/chats/:id/messages", controller.SendMessage)
+		group.POST("/chats/:id/messages/:messageId/reactions", controller.AddReaction)
+		group.DELETE("/chats/:id/messages/:messageId/reactions/:emoji", controller.RemoveReaction)
+	}
+
+	chatPath := "/chats/" + uintToString(chat.ID) + "/messages"
+	post := func(t *testing.T, from string, request map[string]interface{}, data interface{}) int {
+		request["chat_id"] = chat.ID
+		return sendJSON(t, router, "POST", "/"+from+chatPath, request, data)
+	}
+
+	tests := []struct {
+		name    string
+		from    string
+		request map[string]interface{}
+		code    int
+	}{
+		{"空文本", "alice", map[string]interface{}{"content": "  "}, http.StatusBadRequest},
+		{"未知类型", "alice", map[string]interface{}{"type": "video", "content": "x"}, http.StatusBadRequest},
+		{"系统消息", "alice", map[string]interface{}{"type": "system", "content": "x"}, http.StatusBadRequest},
+		{"图片缺少内容", "alice", map[string]interface{}{"type": "image"}, http.StatusBadRequest},
+		{"图片地址无效", "alice", map[string]interface{}{"type": "image", "payload": map[string]interface{}{"url": "not-a-url"}}, http.StatusBadRequest},
+		{"图片格式不支持", "alice", map[string]interface{}{"type": "image", "payload": map[string]interface{}{"url": "https://cdn.gymates.com/a.bmp", "mime_type": "image/bmp"}}, http.StatusBadRequest},
+		{"纬度越界", "alice", map[string]interface{}{"type": "location", "payload": map[string]interface{}{"latitude": 91, "longitude": 0}}, http.StatusBadRequest},
+		{"分享别人的训练", "alice", map[string]interface{}{"type": "workout", "payload": map[string]interface{}{"session_id": bobSession.ID}}, http.StatusBadRequest},
+		{"分享别人的私有计划", "alice", map[string]interface{}{"type": "plan", "payload": map[string]interface{}{"plan_id": private.ID}}, http.StatusBadRequest},
+		{"回复不存在的消息", "alice", map[string]interface{}{"content": "hi", "reply_to_id": 999999}, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.code, post(t, tt.from, tt.request, nil))
+		})
+	}
+
+	var text, image, workout, weeklyShare, location, reply models.Message
+	require.Equal(t, http.StatusCreated, post(t, "bob", map[string]interface{}{"content": "今晚练什么？"}, &text))
+	require.Equal(t, http.StatusCreated, post(t, "alice", map[string]interface{}{"type": "image", "content": "看看这个姿势",
+		"payload": map[string]interface{}{"url": "https://cdn.gymates.com/squat.jpg", "width": 1080, "height": 1440, "mime_type": "image/jpeg"}}, &image))
+	require.Equal(t, http.StatusCreated, post(t, "alice", map[string]interface{}{"type": "workout",
+		"payload": map[string]interface{}{"session_id": session.ID}}, &workout))
+	require.Equal(t, http.StatusCreated, post(t, "alice", map[string]interface{}{"type": "plan",
+		"payload": map[string]interface{}{"plan_id": weekly.ID}}, &weeklyShare))
+	require.Equal(t, http.StatusCreated, post(t, "bob", map[string]interface{}{"type": "location",
+		"payload": map[string]interface{}{"latitude": 31.2304, "longitude": 121.4737, "name": "人民广场健身房"}}, &location))
+	require.Equal(t, http.StatusCreated, post(t, "bob", map[string]interface{}{"content": "好的", "reply_to_id": image.ID}, &reply))
+
+	t.Run("发送时生成卡片快照", func(t *testing.T) {
+		require.NotNil(t, image.Image)
+		assert.Equal(t, 1080, image.Image.Width)
+
+		require.NotNil(t, workout.Workout)
+		assert.Equal(t, "胸背日", workout.Workout.PlanName)
+		assert.Equal(t, 45, workout.Workout.DurationMinutes)
+		assert.Equal(t, 320, workout.Workout.Calories)
+		// 共享测试库中可能残留同一计划ID的动作，按实际数量比较
+		var exercises int64
+		config.DB.Model(&models.Exercise{}).Where("training_plan_id = ?", plan.ID).Count(&exercises)
+		assert.EqualValues(t, exercises, workout.Workout.ExerciseCount)
+		assert.GreaterOrEqual(t, workout.Workout.ExerciseCount, 2)
+
+		require.NotNil(t, weeklyShare.Plan)
+		assert.Equal(t, 2, weeklyShare.Plan.TrainingDays)
+		assert.Equal(t, 3, weeklyShare.Plan.ExerciseCount)
+		assert.Equal(t, []string{"胸部", "背部"}, weeklyShare.Plan.MuscleGroups)
+
+		// 修改训练记录不影响已发送的卡片
+		require.NoError(t, config.DB.Model(&session).Update("total_calories", 999).Error)
+	})
+
+	t.Run("表情回应", func(t *testing.T) {
+		path := "/chats/" + uintToString(chat.ID) + "/messages/" + uintToString(text.ID) + "/reactions"
+		var reactions []models.ReactionSummary
+		require.Equal(t, http.StatusOK, sendJSON(t, router, "POST", "/alice"+path, models.AddReactionRequest{Emoji: "💪"}, &reactions))
+		require.Equal(t, http.StatusOK, sendJSON(t, router, "POST", "/alice"+path, models.AddReactionRequest{Emoji: "💪"}, &reactions))
+		require.Equal(t, http.StatusOK, sendJSON(t, router, "POST", "/bob"+path, models.AddReactionRequest{Emoji: "💪"}, &reactions))
+
+		aliceSub, err := realtimeBroker.Subscribe(alice.ID)
+		require.NoError(t, err)
+		defer aliceSub.Close()
+		require.Equal(t, http.StatusOK, sendJSON(t, router, "POST", "/bob"+path, models.AddReactionRequest{Emoji: "🔥"}, &reactions))
+		require.Len(t, reactions, 2)
+		assert.Equal(t, models.ReactionSummary{Emoji: "💪", Count: 2, UserIDs: []uint{alice.ID, bob.ID}, Reacted: true}, reactions[0])
+		assert.True(t, reactions[1].Reacted)
+
+		// 推送给 Alice 的汇总按 Alice 的视角标记是否回应过
+		var event models.RealtimeEvent
+		for len(aliceSub.Events()) > 0 && event.Type != models.RealtimeEventReaction {
+			event = <-aliceSub.Events()
+		}
+		require.Equal(t, models.RealtimeEventReaction, event.Type)
+		var pushed models.MessageReactionsEvent
+		require.NoError(t, json.Unmarshal(event.Data, &pushed))
+		require.Len(t, pushed.Reactions, 2)
+		assert.True(t, pushed.Reactions[0].Reacted)
+		assert.False(t, pushed.Reactions[1].Reacted)
+
+		assert.Equal(t, http.StatusBadRequest, sendJSON(t, router, "POST", "/bob"+path, models.AddReactionRequest{Emoji: "ok"}, nil))
+		assert.Equal(t, http.StatusNotFound, sendJSON(t, router, "POST", "/bob/chats/"+uintToString(chat.ID)+"/messages/999999/reactions", models.AddReactionRequest{Emoji: "🔥"}, nil))
+
+		require.Equal(t, http.StatusOK, sendJSON(t, router, "DELETE", "/bob"+path+"/🔥", nil, &reactions))
+		assert.Len(t, reactions, 1)
+	})
+
+	t.Run("历史消息按类型展开内容", func(t *testing.T) {
+		var page models.MessagesResponse
+		require.Equal(t, http.StatusOK, sendJSON(t, router, "GET", "/alice"+chatPath, nil, &page))
+		byID := map[uint]models.Message{}
+		for _, message := range page.Messages {
+			byID[message.ID] = message
+		}
+
+		assert.Equal(t, 320, byID[workout.ID].Workout.Calories)
+		assert.Equal(t, "人民广场健身房", byID[location.ID].Location.Name)
+		require.NotNil(t, byID[reply.ID].ReplyTo)
+		assert.Equal(t, models.MessagePreview{ID: image.ID, SenderID: alice.ID, SenderName: alice.Name, Type: models.MessageTypeImage, Content: "看看这个姿势"}, *byID[reply.ID].ReplyTo)
+		require.Len(t, byID[text.ID].Reactions, 1)
+		assert.Equal(t, 2, byID[text.ID].Reactions[0].Count)
+		assert.NotNil(t, byID[image.ID].Reactions)
+		assert.Nil(t, byID[text.ID].Image)
+	})
+}
+
+// TestMessageLifecycle 测试消息的编辑、撤回和删除
+func TestMessageLifecycle(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	setupTestDB(t)
+	t.Setenv("MESSAGE_RECALL_MINUTES", "2")
+
+	owner := models.User{Name: "撤回Owner", Email: "recall-owner@gymates.com", Password: "x"}
+	admin := models.User{Name: "撤回Admin", Email: "recall-admin@gymates.com", Password: "x"}
+	member := models.User{Name: "撤回Member", Email: "recall-member@gymates.com", Password: "x"}
+	for _, user := range []*models.User{&owner, &admin, &member} {
+		require.NoError(t, config.DB.Create(user).Error)
+	}
+	chat := models.Chat{Type: models.ChatTypeGroup, Name: "撤回测试群", OwnerID: &owner.ID}
+	require.NoError(t, config.DB.Create(&chat).Error)
+	for user, role := range map[*models.User]string{&owner: models.ChatRoleOwner, &admin: models.ChatRoleAdmin, &member: models.ChatRoleMember} {
+		require.NoError(t, config.DB.Create(&models.ChatParticipant{ChatID: chat.ID, UserID: user.ID, Role: role}).Error)
+	}
+
+	controller := NewMessagesController()
+	router := gin.New()
+	for name, user := range map[string]*models.User{"owner": &owner, "admin": &admin, "member": &member} {
+		group := router.Group("/"+name, withTestUser(user))
+		group.GET("/chats/:id/messages", controller.GetMessages)
+		group.POST("/chats/:id/messages", controller.SendMessage)
+		group.PUT("/chats/:id/messages/:messageId", controller.EditMessage)
+		group.DELETE("/chats/:id/messages/:messageId", controller.DeleteMessage)
+		group.GET("/chats/:id/messages/:messageId/edits", controller.GetMessageEdits)
+		group.POST("/chats/:id/messages/:messageId/recall", controller.RecallMessage)
+		group.POST("/chats/:id/messages/:messageId/reactions", controller.AddReaction)
+	}
+
+	chatPath := "/chats/" + uintToString(chat.ID) + "/messages"
+	say := func(t *testing.T, from, content string) models.Message {
+		var message models.Message
+		require.Equal(t, http.StatusCreated, sendJSON(t, router, "POST", "/"+from+chatPath, models.SendMessageRequest{ChatID: chat.ID, Content: content}, &message))
+		return message
+	}
+	history := func(t *testing.T, as string) map[uint]models.Message {
+		var page models.MessagesResponse
+		require.Equal(t, http.StatusOK, sendJSON(t, router, "GET", "/"+as+chatPath, nil, &page))
+		result := map[uint]models.Message{}
+		for _, message := range page.Messages {
+			result[message.ID] = message
+		}
+		return result
+	}
+	messagePath := func(message models.Message) string {
+		return chatPath + "/" + uintToString(message.ID)
+	}
+
+	t.Run("编辑消息保留历史", func(t *testing.T) {
+		message := say(t, "member", "今晚七点")
+		assert.Equal(t, http.StatusForbidden, sendJSON(t, router, "PUT", "/admin"+messagePath(message), models.EditMessageRequest{Content: "改掉"}, nil))
+		assert.Equal(t, http.StatusBadRequest, sendJSON(t, router, "PUT", "/member"+messagePath(message), models.EditMessageRequest{Content: " "}, nil))
+
+		var edited models.Message
+		require.Equal(t, http.StatusOK, sendJSON(t, router, "PUT", "/member"+messagePath(message), models.EditMessageRequest{Content: "今晚八点"}, &edited))
+		require.Equal(t, http.StatusOK, sendJSON(t, router, "PUT", "/member"+messagePath(message), models.EditMessageRequest{Content: "今晚八点半"}, &edited))
+		assert.Equal(t, "今晚八点半", edited.Content)
+		assert.NotNil(t, edited.EditedAt)
+
+		var edits models.MessageEditsResponse
+		require.Equal(t, http.StatusOK, sendJSON(t, router, "GET", "/owner"+messagePath(message)+"/edits", nil, &edits))
+		require.Len(t, edits.Edits, 2)
+		assert.Equal(t, "今晚八点", edits.Edits[0].Content)
+		assert.Equal(t, "今晚七点", edits.Edits[1].Content)
+		assert.Equal(t, "今晚八点半", history(t, "owner")[message.ID].Content)
+	})
+
+	t.Run("编辑推送按接收者视角显示表情回应", func(t *testing.T) {
+		message := say(t, "member", "明早练腿")
+		require.Equal(t, http.StatusOK, sendJSON(t, router, "POST", "/owner"+messagePath(message)+"/reactions", models.AddReactionRequest{Emoji: "💪"}, nil))
+
+		ownerSub, err := realtimeBroker.Subscribe(owner.ID)
+		require.NoError(t, err)
+		defer ownerSub.Close()
+		memberSub, err := realtimeBroker.Subscribe(member.ID)
+		require.NoError(t, err)
+		defer memberSub.Close()
+		require.Equal(t, http.StatusOK, sendJSON(t, router, "PUT", "/member"+messagePath(message), models.EditMessageRequest{Content: "明早八点练腿"}, nil))
+
+		for sub, wantReacted := range map[realtime.Subscription]bool{ownerSub: true, memberSub: false} {
+			// 订阅时还会收到其他参与者的上线通知
+			var event models.RealtimeEvent
+			for len(sub.Events()) > 0 && event.Type != models.RealtimeEventEdited {
+				event = <-sub.Events()
+			}
+			require.Equal(t, models.RealtimeEventEdited, event.Type)
+			var pushed models.Message
+			require.NoError(t, json.Unmarshal(event.Data, &pushed))
+			assert.Equal(t, "明早八点练腿", pushed.Content)
+			require.Len(t, pushed.Reactions, 1)
+			assert.Equal(t, wantReacted, pushed.Reactions[0].Reacted)
+		}
+	})
+
+	t.Run("发送者在时限内撤回", func(t *testing.T) {
+		message := say(t, "member", "发错群了")
+		require.Equal(t, http.StatusOK, sendJSON(t, router, "POST", "/owner"+messagePath(message)+"/reactions", models.AddReactionRequest{Emoji: "😂"}, nil))
+		require.Equal(t, http.StatusOK, sendJSON(t, router, "PUT", "/member"+messagePath(message), models.EditMessageRequest{Content: "发错了"}, nil))
+
+		var recalled models.Message
+		require.Equal(t, http.StatusOK, sendJSON(t, router, "POST", "/member"+messagePath(message)+"/recall", nil, &recalled))
+		assert.Equal(t, models.RecalledMessageText, recalled.Content)
+		assert.Equal(t, member.ID, *recalled.RecalledBy)
+		assert.Empty(t, recalled.Reactions)
+
+		seen := history(t, "admin")[message.ID]
+		assert.Equal(t, models.RecalledMessageText, seen.Content)
+		assert.NotNil(t, seen.RecalledAt)
+
+		var stored models.Message
+		require.NoError(t, config.DB.First(&stored, message.ID).Error)
+		assert.Empty(t, stored.Content)
+		var edits int64
+		config.DB.Model(&models.MessageEdit{}).Where("message_id = ?", message.ID).Count(&edits)
+		assert.Zero(t, edits)
+
+		assert.Equal(t, http.StatusConflict, sendJSON(t, router, "POST", "/member"+messagePath(message)+"/recall", nil, nil))
+		assert.Equal(t, http.StatusConflict, sendJSON(t, router, "PUT", "/member"+messagePath(message), models.EditMessageRequest{Content: "再改"}, nil))
+	})
+
+	t.Run("撤回权限", func(t *testing.T) {
+		old := say(t, "member", "很早的消息")
+		require.NoError(t, config.DB.Model(&old).Update("created_at", time.Now().Add(-10*time.Minute)).Error)
+		assert.Equal(t, http.StatusForbidden, sendJSON(t, router, "POST", "/member"+messagePath(old)+"/recall", nil, nil))
+		// 管理员可以随时撤回成员的消息
+		assert.Equal(t, http.StatusOK, sendJSON(t, router, "POST", "/admin"+messagePath(old)+"/recall", nil, nil))
+
+		ownerMessage := say(t, "owner", "群主的消息")
+		assert.Equal(t, http.StatusForbidden, sendJSON(t, router, "POST", "/admin"+messagePath(ownerMessage)+"/recall", nil, nil))
+		assert.Equal(t, http.StatusForbidden, sendJSON(t, router, "POST", "/member"+messagePath(ownerMessage)+"/recall", nil, nil))
+
+		t.Setenv("MESSAGE_RECALL_MINUTES", "0")
+		assert.Equal(t, http.StatusOK, sendJSON(t, router, "POST", "/member"+messagePath(say(t, "member", "不限时"))+"/recall", nil, nil))
+	})
+
+	t.Run("仅对自己删除", func(t *testing.T) {
+		message := say(t, "admin", "只对我删除")
+		require.Equal(t, http.StatusOK, sendJSON(t, router, "DELETE", "/member"+messagePath(message), nil, nil))
+		require.Equal(t, http.StatusOK, sendJSON(t, router, "DELETE", "/member"+messagePath(message)+"?scope=me", nil, nil))
+		assert.NotContains(t, history(t, "member"), message.ID)
+		assert.Contains(t, history(t, "owner"), message.ID)
+		assert.Equal(t, http.StatusBadRequest, sendJSON(t, router, "DELETE", "/member"+messagePath(message)+"?scope=all", nil, nil))
+	})
+
+	t.Run("对所有人删除", func(t *testing.T) {
+		adminMessage := say(t, "admin", "管理员的消息")
+		assert.Equal(t, http.StatusForbidden, sendJSON(t, router, "DELETE", "/member"+messagePath(adminMessage)+"?scope=everyone", nil, nil))
+		assert.Equal(t, http.StatusOK, sendJSON(t, router, "DELETE", "/owner"+messagePath(adminMessage)+"?scope=everyone", nil, nil))
+
+		own := say(t, "member", "自己删")
+		assert.Equal(t, http.StatusOK, sendJSON(t, router, "DELETE", "/member"+messagePath(own)+"?scope=everyone", nil, nil))
+		for _, as := range []string{"owner", "member"} {
+			messages := history(t, as)
+			assert.NotContains(t, messages, adminMessage.ID)
+			assert.NotContains(t, messages, own.ID)
+		}
+		assert.Equal(t, http.StatusNotFound, sendJSON(t, router, "PUT", "/member"+messagePath(own), models.EditMessageRequest{Content: "x"}, nil))
+	})
+}
+
+// TestBackfillLastRead 测试按旧版 is_read 标记初始化已读位置
+func TestBackfillLastRead(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file:backfill_last_read?mode=memory"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.Chat{}, &models.Message{}, &models.ChatParticipant{}))
+
+	// 聊天1：用户1读完了全部消息，用户2还有两条未读；聊天2：用户1的第一条消息就未读
+	messages := []models.Message{
+		{ChatID: 1, SenderID: 2, Content: "a", IsRead: true},
+		{ChatID: 1, SenderID: 1, Content: "b"},
+		{ChatID: 1, SenderID: 2, Content: "c", IsRead: true},
+		{ChatID: 2, SenderID: 3, Content: "d"},
+		{ChatID: 1, SenderID: 1, Content: "e"},
+	}
+	for i := range messages {
+		require.NoError(t, db.Create(&messages[i]).Error)
+	}
+	participants := []models.ChatParticipant{
+		{ChatID: 1, UserID: 1},
+		{ChatID: 1, UserID: 2},
+		{ChatID: 2, UserID: 1},
+		{ChatID: 2, UserID: 3},
+		{ChatID: 3, UserID: 1},
+		{ChatID: 1, UserID: 4, LastReadMessageID: messages[0].ID},
+	}
+	require.NoError(t, db.Create(&participants).Error)
+
+	require.NoError(t, config.BackfillLastRead(db))
+	// 迁移之后加入的参与者保持新版的已读位置，重复执行不会再按旧版标记改写
+	require.NoError(t, db.Create(&models.ChatParticipant{ChatID: 1, UserID: 5}).Error)
+	require.NoError(t, config.BackfillLastRead(db))
+
+	expected := map[[2]uint]uint{
+		{1, 1}: messages[4].ID, // 全部已读，取最新一条
+		{1, 2}: messages[0].ID, // 第一条未读是b
+		{2, 1}: 0,              // 第一条消息就未读
+		{2, 3}: messages[3].ID, // 自己发的消息
+		{3, 1}: 0,              // 没有消息
+		{1, 4}: messages[0].ID, // 已有的已读位置不变
+		{1, 5}: 0,              // 迁移之后加入
+	}
+	var got []models.ChatParticipant
+	require.NoError(t, db.Find(&got).Error)
+	require.Len(t, got, len(expected))
+	for _, participant := range got {
+		assert.Equal(t, expected[[2]uint{participant.ChatID, participant.UserID}], participant.LastReadMessageID,
+			"chat %d user %d", participant.ChatID, participant.UserID)
+	}
+}
@@ -0,0 +1,362 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"gymates-backend/config"
+	"gymates-backend/models"
+	"gymates-backend/services"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// MuscleRecoveryController 肌肉恢复与训练量控制器
+type MuscleRecoveryController struct{}
+
+// NewMuscleRecoveryController 创建肌肉恢复控制器
+func NewMuscleRecoveryController() *MuscleRecoveryController {
+	return &MuscleRecoveryController{}
+}
+
+// GetHeatmap 获取各肌肉的恢复度和最近7天训练量热力图
+// GET /api/training/muscles/heatmap
+func (mrc *MuscleRecoveryController) GetHeatmap(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Success: false,
+			Message: "用户未认证",
+			Error:   "User not authenticated",
+			Code:    http.StatusUnauthorized,
+		})
+		return
+	}
+	currentUser := user.(*models.User)
+
+	now := time.Now()
+	statuses, err := loadMuscleRecovery(config.DB, currentUser.ID, now)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Success: false,
+			Message: "获取肌肉恢复状态失败",
+			Error:   err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "获取肌肉恢复状态成功",
+		Data: models.MuscleHeatmapResponse{
+			GeneratedAt: now,
+			WindowStart: now.Add(-services.VolumeWindow),
+			Muscles:     statuses,
+		},
+	})
+}
+
+// GetVolumeTargets 获取各肌肉的每周训练量目标（MEV/MRV）
+// GET /api/training/muscles/volume-targets
+func (mrc *MuscleRecoveryController) GetVolumeTargets(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Success: false,
+			Message: "用户未认证",
+			Error:   "User not authenticated",
+			Code:    http.StatusUnauthorized,
+		})
+		return
+	}
+	currentUser := user.(*models.User)
+
+	targets, err := volumeTargetResponses(config.DB, currentUser.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Success: false,
+			Message: "获取训练量目标失败",
+			Error:   err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "获取训练量目标成功",
+		Data:    targets,
+	})
+}
+
+// SaveVolumeTargets 设置部分肌肉的每周训练量目标，未提交的肌肉保持不变
+// PUT /api/training/muscles/volume-targets
+func (mrc *MuscleRecoveryController) SaveVolumeTargets(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Success: false,
+			Message: "用户未认证",
+			Error:   "User not authenticated",
+			Code:    http.StatusUnauthorized,
+		})
+		return
+	}
+
+	var req models.SaveVolumeTargetsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Message: "请求参数错误",
+			Error:   err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+	for _, item := range req.Targets {
+		var err error
+		if _, ok := models.LookupMuscle(item.Muscle); !ok {
+			err = fmt.Errorf("unknown muscle: %s", item.Muscle)
+		} else if item.MEV < 0 || item.MEV >= item.MRV {
+			err = fmt.Errorf("%s: mev must be between 0 and mrv", item.Muscle)
+		}
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Success: false,
+				Message: "训练量目标无效",
+				Error:   err.Error(),
+				Code:    http.StatusBadRequest,
+			})
+			return
+		}
+	}
+	currentUser := user.(*models.User)
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		for _, item := range req.Targets {
+			var target models.MuscleVolumeTarget
+			if err := tx.Where("user_id = ? AND muscle = ?", currentUser.ID, item.Muscle).
+				First(&target).Error; err != nil {
+				target = models.MuscleVolumeTarget{
+					UserID: currentUser.ID,
+					Muscle: item.Muscle,
+				}
+			}
+			target.MEV = item.MEV
+			target.MRV = item.MRV
+			if err := tx.Save(&target).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Success: false,
+			Message: "保存训练量目标失败",
+			Error:   err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	targets, err := volumeTargetResponses(config.DB, currentUser.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Success: false,
+			Message: "获取训练量目标失败",
+			Error:   err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "保存训练量目标成功",
+		Data:    targets,
+	})
+}
+
+// ResetVolumeTargets 清除自定义训练量目标，恢复默认值
+// DELETE /api/training/muscles/volume-targets
+func (mrc *MuscleRecoveryController) ResetVolumeTargets(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Success: false,
+			Message: "用户未认证",
+			Error:   "User not authenticated",
+			Code:    http.StatusUnauthorized,
+		})
+		return
+	}
+	currentUser := user.(*models.User)
+
+	if err := config.DB.Unscoped().Where("user_id = ?", currentUser.ID).
+		Delete(&models.MuscleVolumeTarget{}).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Success: false,
+			Message: "重置训练量目标失败",
+			Error:   err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "重置训练量目标成功",
+	})
+}
+
+// userVolumeTargets 获取用户的训练量目标，未自定义的肌肉使用默认值
+func userVolumeTargets(db *gorm.DB, userID uint) (map[string]models.VolumeTarget, error) {
+	targets := make(map[string]models.VolumeTarget, len(models.DefaultVolumeTargets))
+	for muscle, target := range models.DefaultVolumeTargets {
+		targets[muscle] = target
+	}
+
+	var custom []models.MuscleVolumeTarget
+	if err := db.Where("user_id = ?", userID).Find(&custom).Error; err != nil {
+		return nil, err
+	}
+	for _, target := range custom {
+		targets[target.Muscle] = models.VolumeTarget{MEV: target.MEV, MRV: target.MRV}
+	}
+	return targets, nil
+}
+
+// volumeTargetResponses 按肌肉分类顺序组装训练量目标
+func volumeTargetResponses(db *gorm.DB, userID uint) ([]models.MuscleVolumeTargetResponse, error) {
+	targets, err := userVolumeTargets(db, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]models.MuscleVolumeTargetResponse, 0, len(models.Muscles))
+	for _, muscle := range models.Muscles {
+		target := targets[muscle.Key]
+		responses = append(responses, models.MuscleVolumeTargetResponse{
+			Muscle:    muscle.Key,
+			Name:      muscle.Name,
+			MEV:       target.MEV,
+			MRV:       target.MRV,
+			IsDefault: target == models.DefaultVolumeTargets[muscle.Key],
+		})
+	}
+	return responses, nil
+}
+
+// partMuscles 无法关联动作库时，按训练部位下的全部肌肉计入训练量
+func partMuscles(part string) []string {
+	var muscles []string
+	for _, muscle := range models.Muscles {
+		if muscle.Part == part {
+			muscles = append(muscles, muscle.Key)
+		}
+	}
+	return muscles
+}
+
+// loadMuscleRecovery 汇总最近7天的组记录和训练历史，计算各肌肉的恢复状态
+func loadMuscleRecovery(db *gorm.DB, userID uint, now time.Time) ([]models.MuscleRecoveryStatus, error) {
+	since := now.Add(-services.VolumeWindow)
+
+	var library []models.ExerciseLibrary
+	if err := db.Find(&library).Error; err != nil {
+		return nil, err
+	}
+	entries := make(map[uint]models.ExerciseLibrary, len(library))
+	for _, entry := range library {
+		entries[entry.ID] = entry
+	}
+
+	var records []services.MuscleSetRecord
+	addRecords := func(libraryID *uint, part string, sets int, volume float64, at time.Time) {
+		if libraryID != nil {
+			if entry, ok := entries[*libraryID]; ok {
+				records = append(records, services.ExerciseMuscleRecords(entry, sets, volume, at)...)
+				return
+			}
+		}
+		for _, muscle := range partMuscles(part) {
+			records = append(records, services.MuscleSetRecord{Muscle: muscle, Sets: float64(sets), Volume: volume, CompletedAt: at})
+		}
+	}
+
+	// 训练会话中逐组记录，每条记录为一组
+	var setLogs []struct {
+		Reps              int
+		Weight            float64
+		CompletedAt       time.Time
+		ExerciseLibraryID *uint
+		MuscleGroup       string
+	}
+	if err := db.Table("workout_set_logs").
+		Select("workout_set_logs.reps, workout_set_logs.weight, workout_set_logs.completed_at, exercises.exercise_library_id, exercises.muscle_group").
+		Joins("JOIN exercises ON exercises.id = workout_set_logs.exercise_id").
+		Where("workout_set_logs.user_id = ? AND workout_set_logs.completed_at > ? AND workout_set_logs.deleted_at IS NULL", userID, since).
+		Scan(&setLogs).Error; err != nil {
+		return nil, err
+	}
+	for _, log := range setLogs {
+		addRecords(log.ExerciseLibraryID, log.MuscleGroup, 1, float64(log.Reps)*log.Weight, log.CompletedAt)
+	}
+
+	// AI训练会话保存的训练历史，每条记录包含多组
+	var histories []models.UserTrainingHistory
+	if err := db.Where("user_id = ? AND completed_at > ?", userID, since).Find(&histories).Error; err != nil {
+		return nil, err
+	}
+	for _, history := range histories {
+		libraryID := history.ExerciseID
+		addRecords(&libraryID, history.MuscleGroup, history.Sets,
+			float64(history.Sets*history.Reps)*history.Weight, history.CompletedAt)
+	}
+
+	targets, err := userVolumeTargets(db, userID)
+	if err != nil {
+		return nil, err
+	}
+	return services.ComputeMuscleRecovery(records, targets, now), nil
+}
+
+// rankTargetParts 按肌肉恢复和训练量调整目标部位顺序，去掉尚未恢复的部位；
+// 分化表中的部位都未恢复时改练全身优先级最高的部位
+func rankTargetParts(parts []string, statuses []models.MuscleRecoveryStatus) []string {
+	if ranked := services.RankParts(parts, statuses); len(ranked) > 0 {
+		return ranked
+	}
+	if ranked := services.RankParts([]string{"chest", "back", "legs", "shoulders", "arms", "core"}, statuses); len(ranked) > 0 {
+		return ranked[:1]
+	}
+	return parts
+}
+
+// priorityMuscles 目标部位中已恢复且本周训练量低于 MEV 的肌肉，按优先级排列
+func priorityMuscles(parts []string, statuses []models.MuscleRecoveryStatus) []string {
+	targeted := map[string]bool{}
+	for _, part := range parts {
+		targeted[part] = true
+	}
+
+	var candidates []models.MuscleRecoveryStatus
+	for _, status := range statuses {
+		if targeted[status.Part] && status.Recovered && status.VolumeStatus == models.VolumeStatusUnder {
+			candidates = append(candidates, status)
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Priority > candidates[j].Priority
+	})
+	muscles := make([]string, 0, len(candidates))
+	for _, status := range candidates {
+		muscles = append(muscles, status.Muscle)
+	}
+	return muscles
+}
@@ -0,0 +1,130 @@
+package controllers
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"gymates-backend/config"
+	"gymates-backend/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestMuscleRecovery 测试热力图接口、训练量目标以及推荐对未恢复肌肉的回避
+func TestMuscleRecovery(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	setupTestDB(t)
+
+	var user models.User
+	require.NoError(t, config.DB.First(&user, 2).Error)
+	var bench, squat models.ExerciseLibrary
+	require.NoError(t, config.DB.Where("name = ?", "平板卧推").First(&bench).Error)
+	require.NoError(t, config.DB.Where("name = ?", "深蹲").First(&squat).Error)
+
+	// 两小时前练了12组卧推（训练历史），30小时前在训练会话中记录了3组深蹲
+	require.NoError(t, config.DB.Create(&models.UserTrainingHistory{
+		UserID: user.ID, ExerciseID: bench.ID, MuscleGroup: "chest",
+		Sets: 12, Reps: 10, Weight: 60, CompletedAt: time.Now().Add(-2 * time.Hour),
+	}).Error)
+	plan := createTestWeeklyPlan(t, user.ID, false)
+	exercise := models.Exercise{TrainingPlanID: plan.ID, Name: "深蹲", ExerciseLibraryID: &squat.ID, Sets: 3, Reps: 5}
+	require.NoError(t, config.DB.Create(&exercise).Error)
+	for set := 1; set <= 3; set++ {
+		require.NoError(t, config.DB.Create(&models.WorkoutSetLog{
+			UserID: user.ID, ExerciseID: exercise.ID, SetNumber: set, Reps: 5, Weight: 100,
+			CompletedAt: time.Now().Add(-30 * time.Hour),
+		}).Error)
+	}
+
+	controller := NewMuscleRecoveryController()
+	router := gin.New()
+	router.GET("/api/training/muscles/heatmap", withTestUser(&user), controller.GetHeatmap)
+	router.GET("/api/training/muscles/volume-targets", withTestUser(&user), controller.GetVolumeTargets)
+	router.PUT("/api/training/muscles/volume-targets", withTestUser(&user), controller.SaveVolumeTargets)
+	router.DELETE("/api/training/muscles/volume-targets", withTestUser(&user), controller.ResetVolumeTargets)
+	router.GET("/api/training/ai/recommend", NewAIRecommendationController().GetAIRecommendation)
+
+	heatmap := func() map[string]models.MuscleRecoveryStatus {
+		var response models.MuscleHeatmapResponse
+		require.Equal(t, http.StatusOK, sendJSON(t, router, "GET", "/api/training/muscles/heatmap", nil, &response))
+		require.Len(t, response.Muscles, len(models.Muscles))
+		result := map[string]models.MuscleRecoveryStatus{}
+		for _, status := range response.Muscles {
+			result[status.Muscle] = status
+		}
+		return result
+	}
+
+	t.Run("热力图", func(t *testing.T) {
+		muscles := heatmap()
+
+		chest := muscles[models.MuscleChest]
+		assert.Equal(t, 12.0, chest.WeeklySets)
+		assert.Equal(t, 7200.0, chest.WeeklyVolume)
+		assert.False(t, chest.Recovered)
+		assert.Zero(t, chest.Priority)
+		require.NotNil(t, chest.HoursSinceTrained)
+		assert.InDelta(t, 2, *chest.HoursSinceTrained, 0.2)
+
+		assert.Equal(t, 6.0, muscles[models.MuscleTriceps].WeeklySets)
+
+		quads := muscles[models.MuscleQuads]
+		assert.Equal(t, 3.0, quads.WeeklySets)
+		assert.Equal(t, 1500.0, quads.WeeklyVolume)
+		assert.Equal(t, models.VolumeStatusUnder, quads.VolumeStatus)
+		assert.True(t, quads.Recovered)
+		assert.Greater(t, quads.Priority, 0.0)
+
+		calves := muscles[models.MuscleCalves]
+		assert.Nil(t, calves.LastTrainedAt)
+		assert.Equal(t, 100.0, calves.Recovery)
+	})
+
+	t.Run("推荐避开未恢复的肌肉", func(t *testing.T) {
+		var recommendation models.AIRecommendationResponse
+		require.Equal(t, http.StatusOK, sendJSON(t, router, "GET", "/api/training/ai/recommend?user_id=2&day=Monday", nil, &recommendation))
+		require.NotEmpty(t, recommendation.Parts)
+		for _, part := range recommendation.Parts {
+			assert.NotEqual(t, "Chest", part.PartName)
+		}
+		assert.NotContains(t, recommendation.PriorityMuscles, models.MuscleChest)
+	})
+
+	t.Run("训练量目标", func(t *testing.T) {
+		tests := []struct {
+			name     string
+			targets  []models.VolumeTargetItem
+			wantCode int
+		}{
+			{name: "未知肌肉", targets: []models.VolumeTargetItem{{Muscle: "wings", MEV: 2, MRV: 10}}, wantCode: http.StatusBadRequest},
+			{name: "MEV不小于MRV", targets: []models.VolumeTargetItem{{Muscle: models.MuscleChest, MEV: 10, MRV: 10}}, wantCode: http.StatusBadRequest},
+			{name: "保存", targets: []models.VolumeTargetItem{{Muscle: models.MuscleChest, MEV: 4, MRV: 10}, {Muscle: models.MuscleCalves, MEV: 0, MRV: 8}}, wantCode: http.StatusOK},
+			{name: "重复保存覆盖原值", targets: []models.VolumeTargetItem{{Muscle: models.MuscleChest, MEV: 4, MRV: 11}}, wantCode: http.StatusOK},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				assert.Equal(t, tt.wantCode, sendJSON(t, router, "PUT", "/api/training/muscles/volume-targets", models.SaveVolumeTargetsRequest{Targets: tt.targets}, nil))
+			})
+		}
+
+		var targets []models.MuscleVolumeTargetResponse
+		require.Equal(t, http.StatusOK, sendJSON(t, router, "GET", "/api/training/muscles/volume-targets", nil, &targets))
+		byMuscle := map[string]models.MuscleVolumeTargetResponse{}
+		for _, target := range targets {
+			byMuscle[target.Muscle] = target
+		}
+		assert.Equal(t, models.MuscleVolumeTargetResponse{Muscle: models.MuscleChest, Name: "胸大肌", MEV: 4, MRV: 11}, byMuscle[models.MuscleChest])
+		assert.Equal(t, 0, byMuscle[models.MuscleCalves].MEV)
+		assert.True(t, byMuscle[models.MuscleQuads].IsDefault)
+
+		chest := heatmap()[models.MuscleChest]
+		assert.Equal(t, 11, chest.MRV)
+		assert.Equal(t, models.VolumeStatusOver, chest.VolumeStatus)
+
+		require.Equal(t, http.StatusOK, sendJSON(t, router, "DELETE", "/api/training/muscles/volume-targets", nil, nil))
+		assert.Equal(t, models.DefaultVolumeTargets[models.MuscleChest].MRV, heatmap()[models.MuscleChest].MRV)
+	})
+}
@@ -0,0 +1,451 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"gymates-backend/config"
+	"gymates-backend/models"
+	"gymates-backend/services"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// NutritionController 饮食与营养控制器
+type NutritionController struct{}
+
+// NewNutritionController 创建饮食与营养控制器
+func NewNutritionController() *NutritionController {
+	return &NutritionController{}
+}
+
+// GetFoods 搜索食物（内置食物和自己创建的食物）
+// GET /api/nutrition/foods?q=鸡
+func (nc *NutritionController) GetFoods(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Success: false,
+			Message: "用户未认证",
+			Error:   "User not authenticated",
+			Code:    http.StatusUnauthorized,
+		})
+		return
+	}
+	currentUser := user.(*models.User)
+
+	query := config.DB.Where("user_id IS NULL OR user_id = ?", currentUser.ID)
+	if keyword := c.Query("q"); keyword != "" {
+		query = query.Where("name LIKE ?", "%"+keyword+"%")
+	}
+
+	var foods []models.Food
+	if err := query.Order("id").Limit(50).Find(&foods).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Success: false,
+			Message: "获取食物失败",
+			Error:   err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "获取食物成功",
+		Data:    foods,
+	})
+}
+
+// CreateFood 创建自定义食物
+// POST /api/nutrition/foods
+func (nc *NutritionController) CreateFood(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Success: false,
+			Message: "用户未认证",
+			Error:   "User not authenticated",
+			Code:    http.StatusUnauthorized,
+		})
+		return
+	}
+
+	var req models.CreateFoodRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Message: "请求参数错误",
+			Error:   err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+	currentUser := user.(*models.User)
+
+	food := models.Food{
+		UserID:      &currentUser.ID,
+		Name:        req.Name,
+		ServingSize: req.ServingSize,
+		ServingUnit: req.ServingUnit,
+		Calories:    req.Calories,
+		Protein:     req.Protein,
+		Carbs:       req.Carbs,
+		Fat:         req.Fat,
+	}
+	if food.ServingUnit == "" {
+		food.ServingUnit = "g"
+	}
+	if err := config.DB.Create(&food).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Success: false,
+			Message: "创建食物失败",
+			Error:   err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.SuccessResponse{
+		Success: true,
+		Message: "创建食物成功",
+		Data:    food,
+	})
+}
+
+// GetMeals 获取某天的饮食记录
+// GET /api/nutrition/meals?date=2024-01-01，date 为空时取用户时区的今天
+func (nc *NutritionController) GetMeals(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Success: false,
+			Message: "用户未认证",
+			Error:   "User not authenticated",
+			Code:    http.StatusUnauthorized,
+		})
+		return
+	}
+	currentUser := user.(*models.User)
+
+	date, ok := nutritionDate(c, currentUser, c.Query("date"))
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "获取饮食记录成功",
+		Data:    userMeals(config.DB, currentUser.ID, date, date),
+	})
+}
+
+// CreateMeal 记录一餐
+// POST /api/nutrition/meals
+func (nc *NutritionController) CreateMeal(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Success: false,
+			Message: "用户未认证",
+			Error:   "User not authenticated",
+			Code:    http.StatusUnauthorized,
+		})
+		return
+	}
+
+	var req models.CreateMealRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Message: "请求参数错误",
+			Error:   err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+	currentUser := user.(*models.User)
+
+	date, ok := nutritionDate(c, currentUser, req.Date)
+	if !ok {
+		return
+	}
+
+	meal := models.Meal{
+		UserID:   currentUser.ID,
+		Date:     date,
+		MealType: req.MealType,
+		Notes:    req.Notes,
+	}
+	for _, item := range req.Items {
+		var food models.Food
+		if err := config.DB.Where("id = ? AND (user_id IS NULL OR user_id = ?)", item.FoodID, currentUser.ID).
+			First(&food).Error; err != nil {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Success: false,
+				Message: "食物不存在",
+				Error:   "Food not found: " + strconv.FormatUint(uint64(item.FoodID), 10),
+				Code:    http.StatusBadRequest,
+			})
+			return
+		}
+		meal.Items = append(meal.Items, services.ScaleFood(food, item.Servings))
+	}
+
+	if err := config.DB.Create(&meal).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Success: false,
+			Message: "记录饮食失败",
+			Error:   err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.SuccessResponse{
+		Success: true,
+		Message: "记录饮食成功",
+		Data:    meal,
+	})
+}
+
+// DeleteMeal 删除一餐记录
+// DELETE /api/nutrition/meals/:id
+func (nc *NutritionController) DeleteMeal(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Success: false,
+			Message: "用户未认证",
+			Error:   "User not authenticated",
+			Code:    http.StatusUnauthorized,
+		})
+		return
+	}
+	currentUser := user.(*models.User)
+
+	mealID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Message: "无效的饮食记录ID",
+			Error:   err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	var meal models.Meal
+	if err := config.DB.Where("id = ? AND user_id = ?", uint(mealID), currentUser.ID).First(&meal).Error; err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Success: false,
+			Message: "饮食记录不存在或无权限",
+			Error:   "Meal not found or no permission",
+			Code:    http.StatusNotFound,
+		})
+		return
+	}
+
+	if err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("meal_id = ?", meal.ID).Delete(&models.MealItem{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&meal).Error
+	}); err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Success: false,
+			Message: "删除饮食记录失败",
+			Error:   err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "删除饮食记录成功",
+	})
+}
+
+// GetTargets 获取每日热量和营养素目标
+// GET /api/nutrition/targets
+func (nc *NutritionController) GetTargets(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Success: false,
+			Message: "用户未认证",
+			Error:   "User not authenticated",
+			Code:    http.StatusUnauthorized,
+		})
+		return
+	}
+	currentUser := user.(*models.User)
+
+	target, err := userNutritionTarget(config.DB, currentUser)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Message: "请先完善身高、体重和年龄",
+			Error:   err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "获取营养目标成功",
+		Data:    target,
+	})
+}
+
+// GetDailySummary 获取每日摄入汇总
+// GET /api/nutrition/summary/daily?date=2024-01-01
+func (nc *NutritionController) GetDailySummary(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Success: false,
+			Message: "用户未认证",
+			Error:   "User not authenticated",
+			Code:    http.StatusUnauthorized,
+		})
+		return
+	}
+	currentUser := user.(*models.User)
+
+	date, ok := nutritionDate(c, currentUser, c.Query("date"))
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "获取每日汇总成功",
+		Data:    dailyNutritionSummary(config.DB, currentUser, date),
+	})
+}
+
+// GetWeeklySummary 获取每周摄入汇总（date 所在的周一至周日）
+// GET /api/nutrition/summary/weekly?date=2024-01-01
+func (nc *NutritionController) GetWeeklySummary(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Success: false,
+			Message: "用户未认证",
+			Error:   "User not authenticated",
+			Code:    http.StatusUnauthorized,
+		})
+		return
+	}
+	currentUser := user.(*models.User)
+
+	date, ok := nutritionDate(c, currentUser, c.Query("date"))
+	if !ok {
+		return
+	}
+	day, _ := time.ParseInLocation(models.DateLayout, date, currentUser.TimeLocation())
+	weekStart := models.StartOfWeek(day, currentUser.TimeLocation())
+	weekEnd := weekStart.AddDate(0, 0, 6)
+
+	target := optionalNutritionTarget(config.DB, currentUser)
+	mealsByDate := map[string][]models.Meal{}
+	for _, meal := range userMeals(config.DB, currentUser.ID, weekStart.Format(models.DateLayout), weekEnd.Format(models.DateLayout)) {
+		mealsByDate[meal.Date] = append(mealsByDate[meal.Date], meal)
+	}
+	days := make([]models.DailyNutritionSummary, 0, 7)
+	for i := 0; i < 7; i++ {
+		date := weekStart.AddDate(0, 0, i).Format(models.DateLayout)
+		days = append(days, services.SummarizeDay(date, mealsByDate[date], target))
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "获取每周汇总成功",
+		Data:    services.SummarizeWeek(days, target),
+	})
+}
+
+// nutritionDate 校验日期参数，为空时取用户时区的今天；无效时写入400响应并返回 false
+func nutritionDate(c *gin.Context, user *models.User, date string) (string, bool) {
+	if date == "" {
+		return models.LocalDate(time.Now(), user.TimeLocation()), true
+	}
+	if _, err := time.Parse(models.DateLayout, date); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Message: "日期格式错误，应为YYYY-MM-DD",
+			Error:   err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return "", false
+	}
+	return date, true
+}
+
+// userMeals 获取用户在日期范围内（含首尾）的饮食记录
+func userMeals(db *gorm.DB, userID uint, from, to string) []models.Meal {
+	meals := []models.Meal{}
+	db.Preload("Items").
+		Where("user_id = ? AND date >= ? AND date <= ?", userID, from, to).
+		Order("date, created_at").
+		Find(&meals)
+	return meals
+}
+
+// userNutritionTarget 按用户资料和最近4周完成的训练及有氧运动计算营养目标
+//
+// 训练目标优先取训练偏好中的设置，没有时取用户资料中的目标。
+func userNutritionTarget(db *gorm.DB, user *models.User) (models.NutritionTarget, error) {
+	goal := user.Goal
+	var preferences models.UserTrainingPreferences
+	if err := db.Where("user_id = ?", user.ID).First(&preferences).Error; err == nil && preferences.Goal != "" {
+		goal = preferences.Goal
+	}
+
+	var recent struct {
+		Sessions int64
+		Calories float64
+	}
+	db.Model(&models.WorkoutSession{}).
+		Select("COUNT(*) AS sessions, COALESCE(SUM(total_calories), 0) AS calories").
+		Where("user_id = ? AND status = ? AND start_time >= ?", user.ID, "completed", time.Now().AddDate(0, 0, -28)).
+		Scan(&recent)
+	var cardio struct {
+		Activities int64
+		Calories   float64
+	}
+	db.Model(&models.CardioActivity{}).
+		Select("COUNT(*) AS activities, COALESCE(SUM(calories), 0) AS calories").
+		Where("user_id = ? AND start_time >= ?", user.ID, time.Now().AddDate(0, 0, -28)).
+		Scan(&cardio)
+
+	return services.NutritionTargets(services.NutritionProfile{
+		Gender:         user.Gender,
+		Age:            user.Age,
+		Height:         user.Height,
+		Weight:         user.Weight,
+		Goal:           goal,
+		WeeklySessions: float64(recent.Sessions+cardio.Activities) / 4,
+		// 最近4周的训练和有氧消耗平摊到每天
+		DailyExerciseCalories: (recent.Calories + cardio.Calories) / 28,
+	})
+}
+
+// optionalNutritionTarget 用户资料不完整时返回 nil
+func optionalNutritionTarget(db *gorm.DB, user *models.User) *models.NutritionTarget {
+	target, err := userNutritionTarget(db, user)
+	if err != nil {
+		return nil
+	}
+	return &target
+}
+
+// dailyNutritionSummary 汇总用户某天的摄入，资料不完整时不含目标
+func dailyNutritionSummary(db *gorm.DB, user *models.User, date string) models.DailyNutritionSummary {
+	return services.SummarizeDay(date, userMeals(db, user.ID, date, date), optionalNutritionTarget(db, user))
+}
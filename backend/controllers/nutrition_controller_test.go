@@ -0,0 +1,173 @@
+package controllers
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"gymates-backend/config"
+	"gymates-backend/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestNutrition 测试食物、饮食记录、营养目标和每日/每周汇总接口
+func TestNutrition(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	setupTestDB(t)
+
+	user := models.User{
+		Name: "营养测试", Email: "nutrition@gymates.com", Password: "x",
+		Age: 30, Height: 180, Weight: 80, Gender: models.GenderMale, Goal: "增肌", Timezone: "Asia/Shanghai",
+	}
+	require.NoError(t, config.DB.Create(&user).Error)
+	incomplete := models.User{Name: "资料不全", Email: "nutrition-incomplete@gymates.com", Password: "x"}
+	require.NoError(t, config.DB.Create(&incomplete).Error)
+	// 最近4周完成8次训练：平均每周2次，活动系数1.375
+	for i := 0; i < 8; i++ {
+		require.NoError(t, config.DB.Create(&models.WorkoutSession{
+			UserID: user.ID, TrainingPlanID: 1, StartTime: time.Now().AddDate(0, 0, -3*i-1), Status: "completed",
+		}).Error)
+	}
+	require.NoError(t, config.DB.Create(&models.WorkoutSession{
+		UserID: user.ID, TrainingPlanID: 1, StartTime: time.Now().AddDate(0, 0, -40), Status: "completed",
+	}).Error)
+
+	controller := NewNutritionController()
+	router := gin.New()
+	routes := func(prefix string, u *models.User) {
+		router.GET(prefix+"/foods", withTestUser(u), controller.GetFoods)
+		router.POST(prefix+"/foods", withTestUser(u), controller.CreateFood)
+		router.GET(prefix+"/meals", withTestUser(u), controller.GetMeals)
+		router.POST(prefix+"/meals", withTestUser(u), controller.CreateMeal)
+		router.DELETE(prefix+"/meals/:id", withTestUser(u), controller.DeleteMeal)
+		router.GET(prefix+"/targets", withTestUser(u), controller.GetTargets)
+		router.GET(prefix+"/summary/daily", withTestUser(u), controller.GetDailySummary)
+		router.GET(prefix+"/summary/weekly", withTestUser(u), controller.GetWeeklySummary)
+	}
+	routes("/api/nutrition", &user)
+	routes("/other/nutrition", &incomplete)
+
+	var chicken, egg models.Food
+	require.NoError(t, config.DB.Where("name = ? AND user_id IS NULL", "鸡胸肉").First(&chicken).Error)
+	require.NoError(t, config.DB.Where("name = ? AND user_id IS NULL", "鸡蛋").First(&egg).Error)
+
+	t.Run("营养目标", func(t *testing.T) {
+		var target models.NutritionTarget
+		require.Equal(t, http.StatusOK, sendJSON(t, router, "GET", "/api/nutrition/targets", nil, &target))
+		assert.Equal(t, 1780.0, target.BMR)
+		assert.Equal(t, 2.0, target.WeeklySessions)
+		assert.Equal(t, 1.375, target.ActivityFactor)
+		assert.Equal(t, 2748.0, target.Calories)
+		assert.Equal(t, 160.0, target.Protein)
+
+		assert.Equal(t, http.StatusBadRequest, sendJSON(t, router, "GET", "/other/nutrition/targets", nil, nil))
+	})
+
+	var shake models.Food
+	t.Run("自定义食物", func(t *testing.T) {
+		require.Equal(t, http.StatusBadRequest, sendJSON(t, router, "POST", "/api/nutrition/foods", models.CreateFoodRequest{Name: "无份量"}, nil))
+		require.Equal(t, http.StatusCreated, sendJSON(t, router, "POST", "/api/nutrition/foods", models.CreateFoodRequest{
+			Name: "自制蛋白奶昔", ServingSize: 1, ServingUnit: "杯", Calories: 300, Protein: 40, Carbs: 25, Fat: 5,
+		}, &shake))
+
+		var foods []models.Food
+		require.Equal(t, http.StatusOK, sendJSON(t, router, "GET", "/api/nutrition/foods?q=奶昔", nil, &foods))
+		require.Len(t, foods, 1)
+		assert.Equal(t, shake.ID, foods[0].ID)
+
+		// 其他用户看不到也不能使用
+		require.Equal(t, http.StatusOK, sendJSON(t, router, "GET", "/other/nutrition/foods?q=奶昔", nil, &foods))
+		assert.Empty(t, foods)
+		assert.Equal(t, http.StatusBadRequest, sendJSON(t, router, "POST", "/other/nutrition/meals", models.CreateMealRequest{
+			MealType: models.MealSnack, Items: []models.MealItemRequest{{FoodID: shake.ID, Servings: 1}},
+		}, nil))
+	})
+
+	date := "2025-03-12" // 周三
+	t.Run("记录饮食", func(t *testing.T) {
+		tests := []struct {
+			name     string
+			req      models.CreateMealRequest
+			wantCode int
+		}{
+			{name: "未知餐次", req: models.CreateMealRequest{Date: date, MealType: "brunch", Items: []models.MealItemRequest{{FoodID: egg.ID, Servings: 1}}}, wantCode: http.StatusBadRequest},
+			{name: "份数必须大于0", req: models.CreateMealRequest{Date: date, MealType: models.MealBreakfast, Items: []models.MealItemRequest{{FoodID: egg.ID, Servings: 0}}}, wantCode: http.StatusBadRequest},
+			{name: "日期格式错误", req: models.CreateMealRequest{Date: "2025/03/12", MealType: models.MealBreakfast, Items: []models.MealItemRequest{{FoodID: egg.ID, Servings: 1}}}, wantCode: http.StatusBadRequest},
+			{name: "早餐", req: models.CreateMealRequest{Date: date, MealType: models.MealBreakfast, Items: []models.MealItemRequest{{FoodID: egg.ID, Servings: 3}}}, wantCode: http.StatusCreated},
+			{name: "午餐", req: models.CreateMealRequest{Date: date, MealType: models.MealLunch, Items: []models.MealItemRequest{{FoodID: chicken.ID, Servings: 2}, {FoodID: shake.ID, Servings: 1}}}, wantCode: http.StatusCreated},
+			{name: "第二天", req: models.CreateMealRequest{Date: "2025-03-13", MealType: models.MealDinner, Items: []models.MealItemRequest{{FoodID: chicken.ID, Servings: 1}}}, wantCode: http.StatusCreated},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				assert.Equal(t, tt.wantCode, sendJSON(t, router, "POST", "/api/nutrition/meals", tt.req, nil))
+			})
+		}
+
+		var meals []models.Meal
+		require.Equal(t, http.StatusOK, sendJSON(t, router, "GET", "/api/nutrition/meals?date="+date, nil, &meals))
+		require.Len(t, meals, 2)
+		assert.Equal(t, 216.0, meals[0].Items[0].Calories)
+		assert.Equal(t, "鸡胸肉", meals[1].Items[0].FoodName)
+	})
+
+	t.Run("每日汇总", func(t *testing.T) {
+		var summary models.DailyNutritionSummary
+		require.Equal(t, http.StatusOK, sendJSON(t, router, "GET", "/api/nutrition/summary/daily?date="+date, nil, &summary))
+		assert.Equal(t, models.Macros{Calories: 782, Protein: 108.1, Carbs: 26.2, Fat: 26.6}, summary.Intake)
+		assert.Equal(t, 566.0, summary.ByMeal[models.MealLunch].Calories)
+		require.NotNil(t, summary.Remaining)
+		assert.Equal(t, 1966.0, summary.Remaining.Calories)
+
+		// 资料不完整时仍可汇总摄入，只是没有目标
+		var other models.DailyNutritionSummary
+		require.Equal(t, http.StatusOK, sendJSON(t, router, "GET", "/other/nutrition/summary/daily?date="+date, nil, &other))
+		assert.Nil(t, other.Target)
+		assert.Empty(t, other.Meals)
+	})
+
+	t.Run("每周汇总", func(t *testing.T) {
+		var summary models.WeeklyNutritionSummary
+		require.Equal(t, http.StatusOK, sendJSON(t, router, "GET", "/api/nutrition/summary/weekly?date=2025-03-16", nil, &summary))
+		assert.Equal(t, "2025-03-10", summary.WeekStart)
+		assert.Equal(t, "2025-03-16", summary.WeekEnd)
+		require.Len(t, summary.Days, 7)
+		assert.Equal(t, 2, summary.LoggedDays)
+		assert.Equal(t, 915.0, summary.Total.Calories)
+		assert.Equal(t, 457.5, summary.DailyAvg.Calories)
+		assert.Zero(t, summary.DaysOnTrack)
+	})
+
+	t.Run("AI教练参考当天摄入", func(t *testing.T) {
+		today := models.LocalDate(time.Now(), user.TimeLocation())
+		require.Equal(t, http.StatusCreated, sendJSON(t, router, "POST", "/api/nutrition/meals", models.CreateMealRequest{
+			Date: today, MealType: models.MealBreakfast, Items: []models.MealItemRequest{{FoodID: egg.ID, Servings: 2}},
+		}, nil))
+
+		prompt := NewAICoachController().buildSystemPrompt(user.ID, nil)
+		assert.Contains(t, prompt, "用户今日饮食（"+today+"）：已摄入热量144kcal")
+		assert.Contains(t, prompt, "还剩热量2604kcal")
+
+		reply := NewAITrainingController().generateAIResponse("今天还能吃多少", user.ID)
+		assert.Contains(t, reply, "已摄入144kcal")
+		assert.Contains(t, reply, "蛋白质还差147g")
+	})
+
+	t.Run("删除饮食记录", func(t *testing.T) {
+		var meals []models.Meal
+		require.Equal(t, http.StatusOK, sendJSON(t, router, "GET", "/api/nutrition/meals?date=2025-03-13", nil, &meals))
+		require.Len(t, meals, 1)
+		mealID := meals[0].ID
+		path := "/api/nutrition/meals/" + uintToString(mealID)
+		assert.Equal(t, http.StatusNotFound, sendJSON(t, router, "DELETE", "/other/nutrition/meals/"+uintToString(mealID), nil, nil))
+		require.Equal(t, http.StatusOK, sendJSON(t, router, "DELETE", path, nil, nil))
+		require.Equal(t, http.StatusOK, sendJSON(t, router, "GET", "/api/nutrition/meals?date=2025-03-13", nil, &meals))
+		assert.Empty(t, meals)
+
+		var items int64
+		config.DB.Model(&models.MealItem{}).Where("meal_id = ?", mealID).Count(&items)
+		assert.Zero(t, items)
+	})
+}
@@ -0,0 +1,707 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gymates-backend/config"
+	"gymates-backend/models"
+	"gymates-backend/services"
+)
+
+// maxPlanImportSize 导入文件大小上限
+const maxPlanImportSize = 1 << 20
+
+var weekdayNamesCN = []string{"", "周一", "周二", "周三", "周四", "周五", "周六", "周日"}
+
+// exerciseRows 记录快照中每个动作（训练日、部位、动作下标）在导入文件中的行号
+type exerciseRows map[[3]int]int
+
+// PlanIOController 训练计划导入导出控制器
+type PlanIOController struct{}
+
+// NewPlanIOController 创建训练计划导入导出控制器
+func NewPlanIOController() *PlanIOController {
+	return &PlanIOController{}
+}
+
+// ExportPlan 导出一周训练计划
+// GET /api/training/weekly-plans/:id/export?format=json|csv|md|html
+func (pic *PlanIOController) ExportPlan(c *gin.Context) {
+	planID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Message: "无效的一周训练计划ID",
+			Error:   err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	var plan models.WeeklyTrainingPlan
+	if err := config.DB.Preload("Days.Parts.Exercises").Preload("Days.Parts.Groups.Exercises").
+		First(&plan, uint(planID)).Error; err != nil || !canViewPlan(c, plan) {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Success: false,
+			Message: "一周训练计划不存在",
+			Error:   "Weekly training plan not found",
+			Code:    http.StatusNotFound,
+		})
+		return
+	}
+
+	snapshot := buildPlanSnapshot(plan)
+	filename := fmt.Sprintf("weekly-plan-%d", plan.ID)
+
+	switch format := strings.ToLower(c.DefaultQuery("format", "json")); format {
+	case "json":
+		data, err := json.MarshalIndent(models.PlanExportDocument{
+			Schema:     models.PlanExportSchema,
+			ExportedAt: time.Now().UTC(),
+			Plan:       snapshot,
+		}, "", "  ")
+		if err != nil {
+			pic.exportFailed(c, err)
+			return
+		}
+		pic.sendFile(c, filename+".json", "application/json; charset=utf-8", data)
+	case "csv":
+		data, err := renderPlanCSV(snapshot)
+		if err != nil {
+			pic.exportFailed(c, err)
+			return
+		}
+		pic.sendFile(c, filename+".csv", "text/csv; charset=utf-8", data)
+	case "md", "markdown":
+		pic.sendFile(c, filename+".md", "text/markdown; charset=utf-8", renderPlanMarkdown(snapshot))
+	case "html":
+		data, err := renderPlanHTML(snapshot)
+		if err != nil {
+			pic.exportFailed(c, err)
+			return
+		}
+		c.Data(http.StatusOK, "text/html; charset=utf-8", data)
+	default:
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Message: "不支持的导出格式",
+			Error:   fmt.Sprintf("unsupported format: %s", format),
+			Code:    http.StatusBadRequest,
+		})
+	}
+}
+
+// ImportPlan 导入一周训练计划（JSON 或 CSV），动作名称会匹配到动作库
+// POST /api/training/weekly-plans/import?format=json|csv&name=
+func (pic *PlanIOController) ImportPlan(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Success: false,
+			Message: "用户未认证",
+			Error:   "User not authenticated",
+			Code:    http.StatusUnauthorized,
+		})
+		return
+	}
+
+	currentUser := user.(*models.User)
+
+	data, format, err := readImportPayload(c, maxPlanImportSize)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Message: "读取导入文件失败",
+			Error:   err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	var snapshot models.PlanSnapshot
+	var rows exerciseRows
+	var skipped []models.ImportRowIssue
+	switch format {
+	case "json":
+		snapshot, rows, skipped, err = parseJSONPlan(data)
+	case "csv":
+		snapshot, rows, skipped, err = parseCSVPlan(bytes.NewReader(data))
+	default:
+		err = fmt.Errorf("unsupported format: %s", format)
+	}
+	if err == nil && len(snapshot.Days) == 0 {
+		err = errors.New("no valid training days found")
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Message: "导入文件格式错误",
+			Error:   err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	if name := c.Query("name"); name != "" {
+		snapshot.Name = name
+	}
+	if snapshot.Name == "" {
+		snapshot.Name = "导入的训练计划"
+	}
+
+	var library []models.ExerciseLibrary
+	config.DB.Find(&library)
+	matched, unmatched := applyLibraryMatches(&snapshot, rows, services.NewExerciseMatcher(library))
+
+	var plan models.WeeklyTrainingPlan
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		plan = models.WeeklyTrainingPlan{
+			UserID:      currentUser.ID,
+			Name:        snapshot.Name,
+			Description: snapshot.Description,
+		}
+		if err := tx.Create(&plan).Error; err != nil {
+			return err
+		}
+		// 导入的计划默认不启用，由用户手动激活
+		if err := tx.Model(&plan).Update("is_active", false).Error; err != nil {
+			return err
+		}
+		if err := copyTrainingDays(tx, plan.ID, snapshotToTrainingDays(snapshot)); err != nil {
+			return err
+		}
+		return savePlanRevision(tx, plan.ID, currentUser.ID, "import")
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Success: false,
+			Message: "导入训练计划失败",
+			Error:   err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	// 重新加载数据
+	config.DB.Preload("User").Preload("Days.Parts.Exercises").Preload("Days.Parts.Groups.Exercises").First(&plan, plan.ID)
+
+	c.JSON(http.StatusCreated, models.SuccessResponse{
+		Success: true,
+		Message: "导入训练计划成功",
+		Data: models.PlanImportReport{
+			Plan:      plan,
+			Matched:   matched,
+			Unmatched: unmatched,
+			Skipped:   skipped,
+		},
+	})
+}
+
+func (pic *PlanIOController) sendFile(c *gin.Context, filename, contentType string, data []byte) {
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Data(http.StatusOK, contentType, data)
+}
+
+func (pic *PlanIOController) exportFailed(c *gin.Context, err error) {
+	c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+		Success: false,
+		Message: "导出训练计划失败",
+		Error:   err.Error(),
+		Code:    http.StatusInternalServerError,
+	})
+}
+
+// canViewPlan 公开计划或本人的计划可以查看
+func canViewPlan(c *gin.Context, plan models.WeeklyTrainingPlan) bool {
+	if plan.IsPublic {
+		return true
+	}
+	user, exists := c.Get("user")
+	return exists && user.(*models.User).ID == plan.UserID
+}
+
+// readImportPayload 读取上传文件（multipart 的 file 字段）或请求体，并确定格式
+func readImportPayload(c *gin.Context, maxSize int) ([]byte, string, error) {
+	format := strings.ToLower(c.Query("format"))
+
+	var reader io.Reader = c.Request.Body
+	if c.ContentType() == "multipart/form-data" {
+		file, header, err := c.Request.FormFile("file")
+		if err != nil {
+			return nil, format, err
+		}
+		defer file.Close()
+		reader = file
+		if format == "" {
+			format = strings.TrimPrefix(strings.ToLower(filepath.Ext(header.Filename)), ".")
+		}
+	}
+	if format == "" {
+		format = "json"
+		if strings.Contains(c.ContentType(), "csv") {
+			format = "csv"
+		}
+	}
+
+	data, err := io.ReadAll(io.LimitReader(reader, int64(maxSize)+1))
+	if err != nil {
+		return nil, format, err
+	}
+	if len(data) > maxSize {
+		return nil, format, errors.New("import file is too large")
+	}
+	if len(data) == 0 {
+		return nil, format, errors.New("import file is empty")
+	}
+	return data, format, nil
+}
+
+// parseJSONPlan 解析JSON导入文档，无效的训练日和动作会被跳过
+func parseJSONPlan(data []byte) (models.PlanSnapshot, exerciseRows, []models.ImportRowIssue, error) {
+	var document models.PlanExportDocument
+	if err := json.Unmarshal(data, &document); err != nil {
+		return models.PlanSnapshot{}, nil, nil, err
+	}
+	if document.Schema != models.PlanExportSchema {
+		return models.PlanSnapshot{}, nil, nil, fmt.Errorf("unsupported schema %q, expected %q", document.Schema, models.PlanExportSchema)
+	}
+
+	snapshot := document.Plan
+	snapshot.IsPublic = false
+	rows := exerciseRows{}
+	var skipped []models.ImportRowIssue
+
+	row := 0
+	days := make([]models.DaySnapshot, 0, len(snapshot.Days))
+	for _, day := range snapshot.Days {
+		if day.DayOfWeek < 1 || day.DayOfWeek > 7 {
+			skipped = append(skipped, models.ImportRowIssue{Input: day.DayName, Reason: "day_of_week 必须在1-7之间"})
+			continue
+		}
+		for p := range day.Parts {
+			part := &day.Parts[p]
+			exercises := make([]models.ExerciseSnapshot, 0, len(part.Exercises))
+			for _, exercise := range part.Exercises {
+				row++
+				if reason := invalidExerciseReason(exercise, part.Groups); reason != "" {
+					skipped = append(skipped, models.ImportRowIssue{Row: row, Input: exercise.Name, Reason: reason})
+					continue
+				}
+				rows[[3]int{len(days), p, len(exercises)}] = row
+				exercises = append(exercises, exercise)
+			}
+			part.Exercises = exercises
+		}
+		days = append(days, day)
+	}
+	snapshot.Days = days
+
+	return snapshot, rows, skipped, nil
+}
+
+func invalidExerciseReason(exercise models.ExerciseSnapshot, groups []models.GroupSnapshot) string {
+	switch {
+	case strings.TrimSpace(exercise.Name) == "":
+		return "动作名称不能为空"
+	case exercise.Sets < 1:
+		return "组数至少为1"
+	case exercise.Reps < 0 || exercise.Weight < 0 || exercise.Duration < 0 || exercise.RestSeconds < 0:
+		return "次数、重量和时间不能为负数"
+	case exercise.Group < 0 || exercise.Group > len(groups):
+		return "动作组序号无效"
+	case exercise.Group > 0 && !isValidBlockType(groups[exercise.Group-1].BlockType):
+		return "不支持的动作组类型"
+	}
+	return ""
+}
+
+func isValidBlockType(blockType string) bool {
+	switch blockType {
+	case models.BlockTypeSuperset, models.BlockTypeGiantSet, models.BlockTypeCircuit,
+		models.BlockTypeEMOM, models.BlockTypeAMRAP:
+		return true
+	}
+	return false
+}
+
+// parseCSVPlan 解析CSV导入文件（列定义见 models.PlanCSVHeader），无效的行会被跳过
+func parseCSVPlan(r io.Reader) (models.PlanSnapshot, exerciseRows, []models.ImportRowIssue, error) {
+	var snapshot models.PlanSnapshot
+	rows := exerciseRows{}
+	var skipped []models.ImportRowIssue
+
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return snapshot, nil, nil, err
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+	for _, required := range []string{"day_of_week", "exercise"} {
+		if _, ok := columns[required]; !ok {
+			return snapshot, nil, nil, fmt.Errorf("missing column %q", required)
+		}
+	}
+
+	dayIndex := map[int]int{}
+	partIndex := map[string]int{}
+	groupIndex := map[string]int{}
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return snapshot, nil, nil, err
+		}
+		line, _ := reader.FieldPos(0)
+		get := func(column string) string {
+			if i, ok := columns[column]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		name := get("exercise")
+
+		dayOfWeek, err := strconv.Atoi(get("day_of_week"))
+		if err != nil || dayOfWeek < 1 || dayOfWeek > 7 {
+			skipped = append(skipped, models.ImportRowIssue{Row: line, Input: name, Reason: "day_of_week 必须在1-7之间"})
+			continue
+		}
+
+		exercise := models.ExerciseSnapshot{Name: name, Notes: get("notes")}
+		var group models.GroupSnapshot
+		groupNumber := 0
+		if name != "" {
+			var parseErr error
+			parseInt := func(column string, target *int) {
+				if value := get(column); value != "" && parseErr == nil {
+					*target, parseErr = strconv.Atoi(value)
+				}
+			}
+			parseInt("sets", &exercise.Sets)
+			parseInt("reps", &exercise.Reps)
+			parseInt("duration", &exercise.Duration)
+			parseInt("rest_seconds", &exercise.RestSeconds)
+			parseInt("group", &groupNumber)
+			if value := get("weight"); value != "" && parseErr == nil {
+				exercise.Weight, parseErr = strconv.ParseFloat(value, 64)
+			}
+			if groupNumber > 0 {
+				group.BlockType = strings.ToLower(get("block_type"))
+				parseInt("rounds", &group.Rounds)
+				parseInt("work_seconds", &group.WorkSeconds)
+				parseInt("rest_between_rounds", &group.RoundRestSeconds)
+				parseInt("time_cap_seconds", &group.TimeCapSeconds)
+			}
+			if parseErr != nil {
+				skipped = append(skipped, models.ImportRowIssue{Row: line, Input: name, Reason: "数值格式错误"})
+				continue
+			}
+		}
+
+		// 训练日
+		di, ok := dayIndex[dayOfWeek]
+		if !ok {
+			dayName := get("day_name")
+			if dayName == "" {
+				dayName = weekdayNamesCN[dayOfWeek]
+			}
+			snapshot.Days = append(snapshot.Days, models.DaySnapshot{DayOfWeek: dayOfWeek, DayName: dayName})
+			di = len(snapshot.Days) - 1
+			dayIndex[dayOfWeek] = di
+		}
+		day := &snapshot.Days[di]
+		if isRest, err := strconv.ParseBool(get("is_rest_day")); err == nil && isRest {
+			day.IsRestDay = true
+		}
+
+		// 训练部位
+		muscleGroup, muscleGroupName := get("muscle_group"), get("muscle_group_name")
+		if muscleGroup == "" && muscleGroupName == "" {
+			if name == "" {
+				continue
+			}
+			muscleGroup, muscleGroupName = "other", "其他"
+		}
+		if muscleGroup == "" {
+			muscleGroup = muscleGroupName
+		}
+		if muscleGroupName == "" {
+			muscleGroupName = muscleGroup
+		}
+		partKey := fmt.Sprintf("%d|%s", dayOfWeek, muscleGroup)
+		pi, ok := partIndex[partKey]
+		if !ok {
+			day.Parts = append(day.Parts, models.PartSnapshot{
+				MuscleGroup:     muscleGroup,
+				MuscleGroupName: muscleGroupName,
+				Order:           len(day.Parts) + 1,
+			})
+			pi = len(day.Parts) - 1
+			partIndex[partKey] = pi
+		}
+		part := &day.Parts[pi]
+
+		if name == "" {
+			continue
+		}
+
+		// 动作组：同一部位内按 group 列编号，取首行的配置
+		if groupNumber > 0 {
+			groupKey := fmt.Sprintf("%s|%d", partKey, groupNumber)
+			gi, ok := groupIndex[groupKey]
+			if !ok {
+				if !isValidBlockType(group.BlockType) {
+					skipped = append(skipped, models.ImportRowIssue{Row: line, Input: name, Reason: "不支持的动作组类型"})
+					continue
+				}
+				group.Order = len(part.Groups) + 1
+				part.Groups = append(part.Groups, group)
+				gi = len(part.Groups)
+				groupIndex[groupKey] = gi
+			}
+			exercise.Group = gi
+		}
+
+		exercise.Order = len(part.Exercises) + 1
+		if reason := invalidExerciseReason(exercise, part.Groups); reason != "" {
+			skipped = append(skipped, models.ImportRowIssue{Row: line, Input: name, Reason: reason})
+			continue
+		}
+		rows[[3]int{di, pi, len(part.Exercises)}] = line
+		part.Exercises = append(part.Exercises, exercise)
+	}
+
+	return snapshot, rows, skipped, nil
+}
+
+// applyLibraryMatches 将快照中的动作匹配到动作库，匹配成功时使用标准名称并补全空缺信息
+func applyLibraryMatches(snapshot *models.PlanSnapshot, rows exerciseRows, matcher *services.ExerciseMatcher) ([]models.ImportedExerciseMatch, []models.ImportRowIssue) {
+	matched := []models.ImportedExerciseMatch{}
+	unmatched := []models.ImportRowIssue{}
+
+	for di := range snapshot.Days {
+		for pi := range snapshot.Days[di].Parts {
+			part := &snapshot.Days[di].Parts[pi]
+			for ei := range part.Exercises {
+				exercise := &part.Exercises[ei]
+				row := rows[[3]int{di, pi, ei}]
+				// 导入文件中的动作库ID来自其他环境，不可信，一律按名称重新匹配
+				exercise.ExerciseLibraryID = nil
+
+				match, ok := matcher.Match(exercise.Name)
+				if !ok {
+					unmatched = append(unmatched, models.ImportRowIssue{Row: row, Input: exercise.Name, Reason: "动作库中未找到匹配的动作"})
+					continue
+				}
+
+				matched = append(matched, models.ImportedExerciseMatch{
+					Row:         row,
+					Input:       exercise.Name,
+					LibraryID:   match.Entry.ID,
+					MatchedName: match.Entry.Name,
+					MatchedBy:   match.MatchedBy,
+					Score:       match.Score,
+				})
+				libraryID := match.Entry.ID
+				exercise.ExerciseLibraryID = &libraryID
+				exercise.Name = match.Entry.Name
+				if exercise.MuscleGroup == "" {
+					exercise.MuscleGroup = match.Entry.Part
+				}
+				if exercise.Equipment == "" {
+					exercise.Equipment = match.Entry.Equipment
+				}
+				if exercise.Description == "" {
+					exercise.Description = match.Entry.Description
+				}
+				if exercise.Instructions == "" {
+					exercise.Instructions = match.Entry.Instructions
+				}
+				if exercise.ImageURL == "" {
+					exercise.ImageURL = match.Entry.ImageURL
+				}
+				if exercise.VideoURL == "" {
+					exercise.VideoURL = match.Entry.VideoURL
+				}
+			}
+		}
+	}
+	return matched, unmatched
+}
+
+// renderPlanCSV 导出为CSV，每行一个动作
+func renderPlanCSV(snapshot models.PlanSnapshot) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	if err := writer.Write(models.PlanCSVHeader); err != nil {
+		return nil, err
+	}
+
+	itoa := func(v int) string {
+		if v == 0 {
+			return ""
+		}
+		return strconv.Itoa(v)
+	}
+
+	for _, day := range snapshot.Days {
+		dayColumns := []string{strconv.Itoa(day.DayOfWeek), day.DayName, strconv.FormatBool(day.IsRestDay)}
+		if len(day.Parts) == 0 {
+			if err := writer.Write(append(dayColumns, make([]string, len(models.PlanCSVHeader)-len(dayColumns))...)); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		for _, part := range day.Parts {
+			prefix := append(append([]string{}, dayColumns...), part.MuscleGroup, part.MuscleGroupName)
+			if len(part.Exercises) == 0 {
+				if err := writer.Write(append(prefix, make([]string, len(models.PlanCSVHeader)-len(prefix))...)); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			for _, e := range part.Exercises {
+				record := append(append([]string{}, prefix...),
+					e.Name, strconv.Itoa(e.Sets), strconv.Itoa(e.Reps),
+					strconv.FormatFloat(e.Weight, 'f', -1, 64), itoa(e.Duration), itoa(e.RestSeconds), e.Notes)
+				if e.Group > 0 && e.Group <= len(part.Groups) {
+					g := part.Groups[e.Group-1]
+					record = append(record, strconv.Itoa(e.Group), g.BlockType, itoa(g.Rounds),
+						itoa(g.WorkSeconds), itoa(g.RoundRestSeconds), itoa(g.TimeCapSeconds))
+				} else {
+					record = append(record, "", "", "", "", "", "")
+				}
+				if err := writer.Write(record); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	writer.Flush()
+	return buf.Bytes(), writer.Error()
+}
+
+// groupLabel 动作组的简短说明，如 "A 超级组 ×3"
+func groupLabel(part models.PartSnapshot, group int) string {
+	if group < 1 || group > len(part.Groups) {
+		return ""
+	}
+	g := part.Groups[group-1]
+	names := map[string]string{
+		models.BlockTypeSuperset: "超级组",
+		models.BlockTypeGiantSet: "巨型组",
+		models.BlockTypeCircuit:  "循环",
+		models.BlockTypeEMOM:     "EMOM",
+		models.BlockTypeAMRAP:    "AMRAP",
+	}
+	label := fmt.Sprintf("%c %s", 'A'+rune(group-1), names[g.BlockType])
+	if g.Rounds > 0 {
+		label += fmt.Sprintf(" ×%d", g.Rounds)
+	}
+	if g.TimeCapSeconds > 0 {
+		label += fmt.Sprintf(" %d分钟", (g.TimeCapSeconds+59)/60)
+	}
+	return label
+}
+
+// renderPlanMarkdown 导出为可打印的Markdown训练表
+func renderPlanMarkdown(snapshot models.PlanSnapshot) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", snapshot.Name)
+	if snapshot.Description != "" {
+		fmt.Fprintf(&b, "%s\n\n", snapshot.Description)
+	}
+
+	cell := func(s string) string {
+		return strings.ReplaceAll(strings.ReplaceAll(s, "|", "\\|"), "\n", " ")
+	}
+
+	for _, day := range snapshot.Days {
+		fmt.Fprintf(&b, "## %s %s", weekdayNamesCN[day.DayOfWeek], day.DayName)
+		if day.IsRestDay {
+			b.WriteString("（休息日）")
+		}
+		b.WriteString("\n\n")
+		if day.Notes != "" {
+			fmt.Fprintf(&b, "%s\n\n", day.Notes)
+		}
+		for _, part := range day.Parts {
+			fmt.Fprintf(&b, "### %s\n\n", part.MuscleGroupName)
+			if len(part.Exercises) == 0 {
+				continue
+			}
+			b.WriteString("| 动作 | 动作组 | 组数 | 次数 | 重量(kg) | 休息(秒) | 备注 |\n")
+			b.WriteString("| --- | --- | --- | --- | --- | --- | --- |\n")
+			for _, e := range part.Exercises {
+				fmt.Fprintf(&b, "| %s | %s | %d | %d | %s | %d | %s |\n",
+					cell(e.Name), groupLabel(part, e.Group), e.Sets, e.Reps,
+					strconv.FormatFloat(e.Weight, 'f', -1, 64), e.RestSeconds, cell(e.Notes))
+			}
+			b.WriteString("\n")
+		}
+	}
+	return []byte(b.String())
+}
+
+var planHTMLTemplate = template.Must(template.New("plan").Funcs(template.FuncMap{
+	"weekday":    func(day int) string { return weekdayNamesCN[day] },
+	"groupLabel": groupLabel,
+}).Parse(`<!DOCTYPE html>
+<html lang="zh-CN">
+<head>
+<meta charset="utf-8">
+<title>{{.Name}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; width: 100%; margin-bottom: 1em; }
+th, td { border: 1px solid #999; padding: 4px 8px; text-align: left; }
+@media print { h2 { page-break-before: auto; } }
+</style>
+</head>
+<body>
+<h1>{{.Name}}</h1>
+{{if .Description}}<p>{{.Description}}</p>{{end}}
+{{range .Days}}{{$day := .}}
+<h2>{{weekday .DayOfWeek}} {{.DayName}}{{if .IsRestDay}}（休息日）{{end}}</h2>
+{{if .Notes}}<p>{{.Notes}}</p>{{end}}
+{{range .Parts}}{{$part := .}}
+<h3>{{.MuscleGroupName}}</h3>
+{{if .Exercises}}<table>
+<tr><th>动作</th><th>动作组</th><th>组数</th><th>次数</th><th>重量(kg)</th><th>休息(秒)</th><th>完成</th><th>备注</th></tr>
+{{range .Exercises}}<tr><td>{{.Name}}</td><td>{{groupLabel $part .Group}}</td><td>{{.Sets}}</td><td>{{.Reps}}</td><td>{{.Weight}}</td><td>{{.RestSeconds}}</td><td>☐</td><td>{{.Notes}}</td></tr>
+{{end}}</table>{{end}}
+{{end}}{{end}}
+</body>
+</html>
+`))
+
+// renderPlanHTML 导出为可打印的HTML训练表
+func renderPlanHTML(snapshot models.PlanSnapshot) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := planHTMLTemplate.Execute(&buf, snapshot); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
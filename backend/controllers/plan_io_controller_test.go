@@ -0,0 +1,166 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"gymates-backend/config"
+	"gymates-backend/models"
+	"gymates-backend/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestExerciseMatcher 测试动作名称匹配
+func TestExerciseMatcher(t *testing.T) {
+	matcher := services.NewExerciseMatcher([]models.ExerciseLibrary{
+		{ID: 1, Name: "杠铃卧推", Aliases: `["Bench Press", "平板卧推"]`},
+		{ID: 2, Name: "深蹲", Aliases: `["Back Squat"]`},
+	})
+
+	tests := []struct {
+		name      string
+		input     string
+		wantID    uint
+		matchedBy string
+	}{
+		{name: "名称完全匹配", input: "杠铃卧推", wantID: 1, matchedBy: "name"},
+		{name: "别名忽略大小写和空格", input: "bench-press", wantID: 1, matchedBy: "alias"},
+		{name: "拼写错误模糊匹配", input: "Back Sqaut", wantID: 2, matchedBy: "fuzzy"},
+		{name: "无法匹配", input: "哑铃飞鸟"},
+		{name: "空名称", input: "  "},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			match, ok := matcher.Match(tt.input)
+			if tt.wantID == 0 {
+				assert.False(t, ok)
+				return
+			}
+			require.True(t, ok)
+			assert.Equal(t, tt.wantID, match.Entry.ID)
+			assert.Equal(t, tt.matchedBy, match.MatchedBy)
+		})
+	}
+}
+
+// TestImportExportPlan 测试训练计划导入导出
+func TestImportExportPlan(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	setupTestDB(t)
+
+	var owner, other models.User
+	require.NoError(t, config.DB.First(&owner, 1).Error)
+	require.NoError(t, config.DB.First(&other, 2).Error)
+
+	plan := createTestWeeklyPlan(t, owner.ID, false)
+
+	controller := NewPlanIOController()
+	newRouter := func(user *models.User) *gin.Engine {
+		router := gin.New()
+		router.GET("/api/training/weekly-plans/:id/export", withTestUser(user), controller.ExportPlan)
+		router.POST("/api/training/weekly-plans/import", withTestUser(user), controller.ImportPlan)
+		return router
+	}
+	exportPath := "/api/training/weekly-plans/" + uintToString(plan.ID) + "/export"
+
+	export := func(user *models.User, format string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("GET", exportPath+"?format="+format, nil)
+		w := httptest.NewRecorder()
+		newRouter(user).ServeHTTP(w, req)
+		return w
+	}
+	importPlan := func(format, contentType string, body []byte) (int, models.PlanImportReport) {
+		req, _ := http.NewRequest("POST", "/api/training/weekly-plans/import?format="+format, bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", contentType)
+		w := httptest.NewRecorder()
+		newRouter(&owner).ServeHTTP(w, req)
+
+		var response struct {
+			Data models.PlanImportReport `json:"data"`
+		}
+		json.Unmarshal(w.Body.Bytes(), &response)
+		return w.Code, response.Data
+	}
+
+	t.Run("导出格式", func(t *testing.T) {
+		w := export(&owner, "csv")
+		require.Equal(t, http.StatusOK, w.Code)
+		lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+		assert.Equal(t, strings.Join(models.PlanCSVHeader, ","), lines[0])
+		assert.Len(t, lines, 3)
+
+		w = export(&owner, "md")
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "# 推拉腿模板")
+		assert.Contains(t, w.Body.String(), "| 平板卧推 |")
+
+		w = export(&owner, "html")
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "<td>哑铃飞鸟</td>")
+
+		assert.Equal(t, http.StatusBadRequest, export(&owner, "xml").Code)
+		// 私有计划其他用户不能导出
+		assert.Equal(t, http.StatusNotFound, export(&other, "json").Code)
+	})
+
+	t.Run("JSON导出后重新导入", func(t *testing.T) {
+		w := export(&owner, "json")
+		require.Equal(t, http.StatusOK, w.Code)
+
+		// 把一个动作改成别名，验证导入时统一为标准名称
+		body := strings.Replace(w.Body.String(), `"name": "哑铃飞鸟"`, `"name": "Dumbbell Flyes"`, 1)
+		code, report := importPlan("json", "application/json", []byte(body))
+		require.Equal(t, http.StatusCreated, code)
+		require.Len(t, report.Matched, 2)
+		assert.Empty(t, report.Unmatched)
+		matchedBy := map[string]string{}
+		for _, match := range report.Matched {
+			matchedBy[match.MatchedName] = match.MatchedBy
+		}
+		assert.Equal(t, map[string]string{"平板卧推": "name", "哑铃飞鸟": "alias"}, matchedBy)
+
+		var imported models.WeeklyTrainingPlan
+		require.NoError(t, config.DB.Preload("Days.Parts.Exercises").First(&imported, report.Plan.ID).Error)
+		assert.False(t, imported.IsActive)
+		require.Len(t, imported.Days, 1)
+		names := []string{}
+		for _, exercise := range imported.Days[0].Parts[0].Exercises {
+			names = append(names, exercise.Name)
+			assert.NotNil(t, exercise.ExerciseLibraryID)
+		}
+		assert.ElementsMatch(t, []string{"平板卧推", "哑铃飞鸟"}, names)
+	})
+
+	t.Run("CSV导入报告无效行", func(t *testing.T) {
+		csvData := strings.Join([]string{
+			"day_of_week,day_name,muscle_group,muscle_group_name,exercise,sets,reps,weight",
+			"1,Monday,chest,胸部,bench pres,4,8,60",
+			"1,Monday,chest,胸部,哑铃飞鸟,abc,12,",
+			"9,Someday,legs,腿部,深蹲,4,8,100",
+			"3,Wednesday,,,,,,",
+		}, "\n")
+
+		code, report := importPlan("csv", "text/csv", []byte(csvData))
+		require.Equal(t, http.StatusCreated, code)
+		require.Len(t, report.Matched, 1)
+		assert.Equal(t, 2, report.Matched[0].Row)
+		assert.Equal(t, "fuzzy", report.Matched[0].MatchedBy)
+		require.Len(t, report.Skipped, 2)
+		assert.Equal(t, 3, report.Skipped[0].Row)
+		assert.Equal(t, 4, report.Skipped[1].Row)
+		assert.Len(t, report.Plan.Days, 2)
+	})
+
+	t.Run("JSON格式版本不符", func(t *testing.T) {
+		code, _ := importPlan("json", "application/json", []byte(`{"schema":"other","plan":{"days":[]}}`))
+		assert.Equal(t, http.StatusBadRequest, code)
+	})
+}
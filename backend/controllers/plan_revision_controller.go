@@ -0,0 +1,587 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gymates-backend/config"
+	"gymates-backend/models"
+)
+
+// PlanRevisionController 一周训练计划版本控制器
+type PlanRevisionController struct{}
+
+// NewPlanRevisionController 创建训练计划版本控制器
+func NewPlanRevisionController() *PlanRevisionController {
+	return &PlanRevisionController{}
+}
+
+// GetRevisions 获取计划修订历史
+// GET /api/training/weekly-plans/:id/revisions
+func (prc *PlanRevisionController) GetRevisions(c *gin.Context) {
+	plan, ok := prc.findOwnedPlan(c)
+	if !ok {
+		return
+	}
+
+	var revisions []models.WeeklyTrainingPlanRevision
+	if err := config.DB.Select("id", "weekly_training_plan_id", "version", "author_id", "summary", "created_at").
+		Where("weekly_training_plan_id = ?", plan.ID).
+		Preload("Author").Order("version DESC").Find(&revisions).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Success: false,
+			Message: "获取修订历史失败",
+			Error:   err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "获取修订历史成功",
+		Data:    revisions,
+	})
+}
+
+// DiffRevisions 对比两个修订版本（动作级别）
+// GET /api/training/weekly-plans/:id/revisions/diff?from=1&to=2
+func (prc *PlanRevisionController) DiffRevisions(c *gin.Context) {
+	plan, ok := prc.findOwnedPlan(c)
+	if !ok {
+		return
+	}
+
+	fromVersion, errFrom := strconv.Atoi(c.Query("from"))
+	toVersion, errTo := strconv.Atoi(c.Query("to"))
+	if errFrom != nil || errTo != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Message: "请指定要对比的版本",
+			Error:   "from and to versions are required",
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	from, err := loadPlanSnapshot(plan.ID, fromVersion)
+	if err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Success: false,
+			Message: "修订版本不存在",
+			Error:   fmt.Sprintf("revision %d not found", fromVersion),
+			Code:    http.StatusNotFound,
+		})
+		return
+	}
+	to, err := loadPlanSnapshot(plan.ID, toVersion)
+	if err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Success: false,
+			Message: "修订版本不存在",
+			Error:   fmt.Sprintf("revision %d not found", toVersion),
+			Code:    http.StatusNotFound,
+		})
+		return
+	}
+
+	diff := diffPlanSnapshots(from, to)
+	diff.FromVersion = fromVersion
+	diff.ToVersion = toVersion
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "对比修订版本成功",
+		Data:    diff,
+	})
+}
+
+// RollbackRevision 回滚到指定修订版本（回滚本身会生成一个新版本）
+// POST /api/training/weekly-plans/:id/revisions/:version/rollback
+func (prc *PlanRevisionController) RollbackRevision(c *gin.Context) {
+	plan, ok := prc.findOwnedPlan(c)
+	if !ok {
+		return
+	}
+	currentUser := c.MustGet("user").(*models.User)
+
+	version, err := strconv.Atoi(c.Param("version"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Message: "无效的版本号",
+			Error:   err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	snapshot, err := loadPlanSnapshot(plan.ID, version)
+	if err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Success: false,
+			Message: "修订版本不存在",
+			Error:   fmt.Sprintf("revision %d not found", version),
+			Code:    http.StatusNotFound,
+		})
+		return
+	}
+
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&plan).Updates(map[string]interface{}{
+			"name":        snapshot.Name,
+			"description": snapshot.Description,
+			"is_public":   snapshot.IsPublic,
+		}).Error; err != nil {
+			return err
+		}
+		if err := replacePlanDays(tx, plan.ID, snapshotToTrainingDays(snapshot)); err != nil {
+			return err
+		}
+		return savePlanRevision(tx, plan.ID, currentUser.ID, fmt.Sprintf("rollback to v%d", version))
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Success: false,
+			Message: "回滚训练计划失败",
+			Error:   err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	// 重新加载数据
+	config.DB.Preload("User").Preload("Days.Parts.Exercises").Preload("Days.Parts.Groups.Exercises").First(&plan, plan.ID)
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "回滚训练计划成功",
+		Data: models.WeeklyTrainingPlanResponse{
+			Plan: plan,
+		},
+	})
+}
+
+// findOwnedPlan 查找属于当前用户的计划，失败时直接写入响应
+func (prc *PlanRevisionController) findOwnedPlan(c *gin.Context) (models.WeeklyTrainingPlan, bool) {
+	var plan models.WeeklyTrainingPlan
+
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Success: false,
+			Message: "用户未认证",
+			Error:   "User not authenticated",
+			Code:    http.StatusUnauthorized,
+		})
+		return plan, false
+	}
+
+	planID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Message: "无效的一周训练计划ID",
+			Error:   err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return plan, false
+	}
+
+	currentUser := user.(*models.User)
+	if err := config.DB.Where("id = ? AND user_id = ?", uint(planID), currentUser.ID).
+		First(&plan).Error; err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Success: false,
+			Message: "一周训练计划不存在或无权限",
+			Error:   "Weekly training plan not found or no permission",
+			Code:    http.StatusNotFound,
+		})
+		return plan, false
+	}
+
+	return plan, true
+}
+
+// savePlanRevision 为计划当前状态保存一个新的修订版本
+//
+// 先锁住计划行，让同一计划的修订串行取版本号；数据库不支持行锁时，并发保存可能撞上
+// (计划, 版本) 唯一索引，此时在保存点内重新取版本号重试一次，其他错误直接返回。
+func savePlanRevision(tx *gorm.DB, planID, authorID uint, summary string) error {
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").
+		First(&models.WeeklyTrainingPlan{}, planID).Error; err != nil {
+		return err
+	}
+
+	var plan models.WeeklyTrainingPlan
+	if err := tx.Preload("Days.Parts.Exercises").Preload("Days.Parts.Groups.Exercises").First(&plan, planID).Error; err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(buildPlanSnapshot(plan))
+	if err != nil {
+		return err
+	}
+
+	insert := func() error {
+		return tx.Transaction(func(tx *gorm.DB) error {
+			var latest int
+			if err := tx.Model(&models.WeeklyTrainingPlanRevision{}).
+				Where("weekly_training_plan_id = ?", planID).
+				Select("COALESCE(MAX(version), 0)").Scan(&latest).Error; err != nil {
+				return err
+			}
+
+			revision := models.WeeklyTrainingPlanRevision{
+				WeeklyTrainingPlanID: planID,
+				Version:              latest + 1,
+				AuthorID:             authorID,
+				Summary:              summary,
+				Snapshot:             string(data),
+			}
+			return tx.Create(&revision).Error
+		})
+	}
+	if err := insert(); err != nil {
+		if !isUniqueViolation(tx, err) {
+			return err
+		}
+		return insert()
+	}
+	return nil
+}
+
+// isUniqueViolation 判断错误是否为唯一索引冲突
+func isUniqueViolation(db *gorm.DB, err error) bool {
+	if translator, ok := db.Dialector.(gorm.ErrorTranslator); ok {
+		err = translator.Translate(err)
+	}
+	return errors.Is(err, gorm.ErrDuplicatedKey)
+}
+
+// ensureBaselineRevision 计划还没有修订版本时（修订功能上线前创建的计划），
+// 在修改前先把当前状态保存为 v1，保证修改前的状态可以回滚
+func ensureBaselineRevision(tx *gorm.DB, planID, authorID uint) error {
+	var count int64
+	if err := tx.Model(&models.WeeklyTrainingPlanRevision{}).
+		Where("weekly_training_plan_id = ?", planID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+	return savePlanRevision(tx, planID, authorID, "baseline")
+}
+
+// loadPlanSnapshot 读取指定版本的快照
+func loadPlanSnapshot(planID uint, version int) (models.PlanSnapshot, error) {
+	var snapshot models.PlanSnapshot
+
+	var revision models.WeeklyTrainingPlanRevision
+	if err := config.DB.Where("weekly_training_plan_id = ? AND version = ?", planID, version).
+		First(&revision).Error; err != nil {
+		return snapshot, err
+	}
+
+	err := json.Unmarshal([]byte(revision.Snapshot), &snapshot)
+	return snapshot, err
+}
+
+// buildPlanSnapshot 根据计划构建快照
+func buildPlanSnapshot(plan models.WeeklyTrainingPlan) models.PlanSnapshot {
+	snapshot := models.PlanSnapshot{
+		Name:        plan.Name,
+		Description: plan.Description,
+		IsPublic:    plan.IsPublic,
+		Days:        []models.DaySnapshot{},
+	}
+
+	for _, day := range plan.Days {
+		daySnapshot := models.DaySnapshot{
+			DayOfWeek: day.DayOfWeek,
+			DayName:   day.DayName,
+			IsRestDay: day.IsRestDay,
+			Notes:     day.Notes,
+			Parts:     []models.PartSnapshot{},
+		}
+		for _, part := range day.Parts {
+			partSnapshot := models.PartSnapshot{
+				MuscleGroup:     part.MuscleGroup,
+				MuscleGroupName: part.MuscleGroupName,
+				Order:           part.Order,
+				Exercises:       []models.ExerciseSnapshot{},
+			}
+			groupIndex := make(map[uint]int, len(part.Groups))
+			for i, group := range part.Groups {
+				groupIndex[group.ID] = i + 1
+				partSnapshot.Groups = append(partSnapshot.Groups, models.GroupSnapshot{
+					BlockType:        group.BlockType,
+					Name:             group.Name,
+					Rounds:           group.Rounds,
+					WorkSeconds:      group.WorkSeconds,
+					RestSeconds:      group.RestSeconds,
+					RoundRestSeconds: group.RoundRestSeconds,
+					TimeCapSeconds:   group.TimeCapSeconds,
+					Order:            group.Order,
+				})
+			}
+			for _, exercise := range part.Exercises {
+				group := 0
+				if exercise.ExerciseGroupID != nil {
+					group = groupIndex[*exercise.ExerciseGroupID]
+				}
+				partSnapshot.Exercises = append(partSnapshot.Exercises, models.ExerciseSnapshot{
+					ExerciseLibraryID: exercise.ExerciseLibraryID,
+					Name:              exercise.Name,
+					Description:       exercise.Description,
+					MuscleGroup:       exercise.MuscleGroup,
+					Difficulty:        exercise.Difficulty,
+					Equipment:         exercise.Equipment,
+					Sets:              exercise.Sets,
+					Reps:              exercise.Reps,
+					Weight:            exercise.Weight,
+					Duration:          exercise.Duration,
+					RestTime:          exercise.RestTime,
+					RestSeconds:       exercise.RestSeconds,
+					Instructions:      exercise.Instructions,
+					ImageURL:          exercise.ImageURL,
+					VideoURL:          exercise.VideoURL,
+					Calories:          exercise.Calories,
+					Notes:             exercise.Notes,
+					Order:             exercise.Order,
+					Group:             group,
+				})
+			}
+			daySnapshot.Parts = append(daySnapshot.Parts, partSnapshot)
+		}
+		snapshot.Days = append(snapshot.Days, daySnapshot)
+	}
+
+	return snapshot
+}
+
+// snapshotToTrainingDays 将快照转换为待创建的训练日结构
+func snapshotToTrainingDays(snapshot models.PlanSnapshot) []models.TrainingDay {
+	days := make([]models.TrainingDay, 0, len(snapshot.Days))
+	for _, daySnapshot := range snapshot.Days {
+		day := models.TrainingDay{
+			DayOfWeek: daySnapshot.DayOfWeek,
+			DayName:   daySnapshot.DayName,
+			IsRestDay: daySnapshot.IsRestDay,
+			Notes:     daySnapshot.Notes,
+		}
+		for _, partSnapshot := range daySnapshot.Parts {
+			part := models.TrainingPart{
+				MuscleGroup:     partSnapshot.MuscleGroup,
+				MuscleGroupName: partSnapshot.MuscleGroupName,
+				Order:           partSnapshot.Order,
+			}
+			// 动作组ID仅用于在 copyTrainingDays 中关联组内动作
+			for i, g := range partSnapshot.Groups {
+				part.Groups = append(part.Groups, models.ExerciseGroup{
+					ID:               uint(i + 1),
+					BlockType:        g.BlockType,
+					Name:             g.Name,
+					Rounds:           g.Rounds,
+					WorkSeconds:      g.WorkSeconds,
+					RestSeconds:      g.RestSeconds,
+					RoundRestSeconds: g.RoundRestSeconds,
+					TimeCapSeconds:   g.TimeCapSeconds,
+					Order:            g.Order,
+				})
+			}
+			for _, e := range partSnapshot.Exercises {
+				var groupID *uint
+				if e.Group > 0 {
+					id := uint(e.Group)
+					groupID = &id
+				}
+				part.Exercises = append(part.Exercises, models.Exercise{
+					ExerciseGroupID:   groupID,
+					ExerciseLibraryID: e.ExerciseLibraryID,
+					Name:              e.Name,
+					Description:       e.Description,
+					MuscleGroup:       e.MuscleGroup,
+					Difficulty:        e.Difficulty,
+					Equipment:         e.Equipment,
+					Sets:              e.Sets,
+					Reps:              e.Reps,
+					Weight:            e.Weight,
+					Duration:          e.Duration,
+					RestTime:          e.RestTime,
+					RestSeconds:       e.RestSeconds,
+					Instructions:      e.Instructions,
+					ImageURL:          e.ImageURL,
+					VideoURL:          e.VideoURL,
+					Calories:          e.Calories,
+					Notes:             e.Notes,
+					Order:             e.Order,
+				})
+			}
+			day.Parts = append(day.Parts, part)
+		}
+		days = append(days, day)
+	}
+	return days
+}
+
+// replacePlanDays 删除计划现有训练日并按给定结构重建
+func replacePlanDays(tx *gorm.DB, planID uint, days []models.TrainingDay) error {
+	if err := tx.Where("weekly_training_plan_id = ?", planID).Delete(&models.TrainingDay{}).Error; err != nil {
+		return err
+	}
+	return copyTrainingDays(tx, planID, days)
+}
+
+// diffPlanSnapshots 计算两个快照之间的差异
+func diffPlanSnapshots(from, to models.PlanSnapshot) models.PlanRevisionDiff {
+	diff := models.PlanRevisionDiff{
+		PlanChanges: []models.FieldChange{},
+		Days:        []models.DayDiff{},
+		Exercises:   []models.ExerciseDiff{},
+	}
+
+	diff.PlanChanges = appendChange(diff.PlanChanges, "name", from.Name, to.Name)
+	diff.PlanChanges = appendChange(diff.PlanChanges, "description", from.Description, to.Description)
+	diff.PlanChanges = appendChange(diff.PlanChanges, "is_public", from.IsPublic, to.IsPublic)
+
+	// 训练日对比
+	fromDays := indexDaySnapshots(from.Days)
+	toDays := indexDaySnapshots(to.Days)
+	for _, dayOfWeek := range unionDayKeys(fromDays, toDays) {
+		fromDay, inFrom := fromDays[dayOfWeek]
+		toDay, inTo := toDays[dayOfWeek]
+		switch {
+		case !inFrom:
+			diff.Days = append(diff.Days, models.DayDiff{DayOfWeek: dayOfWeek, ChangeType: "added"})
+		case !inTo:
+			diff.Days = append(diff.Days, models.DayDiff{DayOfWeek: dayOfWeek, ChangeType: "removed"})
+		default:
+			var changes []models.FieldChange
+			changes = appendChange(changes, "day_name", fromDay.DayName, toDay.DayName)
+			changes = appendChange(changes, "is_rest_day", fromDay.IsRestDay, toDay.IsRestDay)
+			changes = appendChange(changes, "notes", fromDay.Notes, toDay.Notes)
+			if len(changes) > 0 {
+				diff.Days = append(diff.Days, models.DayDiff{DayOfWeek: dayOfWeek, ChangeType: "modified", Changes: changes})
+			}
+		}
+	}
+
+	// 动作对比：按 训练日+部位+动作名 匹配
+	fromExercises, fromKeys := indexExerciseSnapshots(from.Days)
+	toExercises, toKeys := indexExerciseSnapshots(to.Days)
+	for _, key := range fromKeys {
+		fromExercise := fromExercises[key]
+		toExercise, exists := toExercises[key]
+		if !exists {
+			diff.Exercises = append(diff.Exercises, exerciseDiff(fromExercise, "removed", nil))
+			continue
+		}
+		if changes := exerciseChanges(fromExercise.ExerciseSnapshot, toExercise.ExerciseSnapshot); len(changes) > 0 {
+			diff.Exercises = append(diff.Exercises, exerciseDiff(toExercise, "modified", changes))
+		}
+	}
+	for _, key := range toKeys {
+		if _, exists := fromExercises[key]; !exists {
+			diff.Exercises = append(diff.Exercises, exerciseDiff(toExercises[key], "added", nil))
+		}
+	}
+
+	sort.SliceStable(diff.Exercises, func(i, j int) bool {
+		return diff.Exercises[i].DayOfWeek < diff.Exercises[j].DayOfWeek
+	})
+
+	return diff
+}
+
+// locatedExercise 带所在训练日和部位的动作快照
+type locatedExercise struct {
+	models.ExerciseSnapshot
+	DayOfWeek   int
+	MuscleGroup string
+}
+
+func indexDaySnapshots(days []models.DaySnapshot) map[int]models.DaySnapshot {
+	index := make(map[int]models.DaySnapshot, len(days))
+	for _, day := range days {
+		index[day.DayOfWeek] = day
+	}
+	return index
+}
+
+func unionDayKeys(a, b map[int]models.DaySnapshot) []int {
+	seen := make(map[int]bool)
+	var keys []int
+	for _, index := range []map[int]models.DaySnapshot{a, b} {
+		for key := range index {
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	sort.Ints(keys)
+	return keys
+}
+
+// indexExerciseSnapshots 为动作建立索引，同一部位下重名动作按出现顺序区分
+func indexExerciseSnapshots(days []models.DaySnapshot) (map[string]locatedExercise, []string) {
+	index := make(map[string]locatedExercise)
+	var keys []string
+	for _, day := range days {
+		for _, part := range day.Parts {
+			occurrences := make(map[string]int)
+			for _, exercise := range part.Exercises {
+				occurrences[exercise.Name]++
+				key := fmt.Sprintf("%d|%s|%s|%d", day.DayOfWeek, part.MuscleGroup, exercise.Name, occurrences[exercise.Name])
+				index[key] = locatedExercise{
+					ExerciseSnapshot: exercise,
+					DayOfWeek:        day.DayOfWeek,
+					MuscleGroup:      part.MuscleGroup,
+				}
+				keys = append(keys, key)
+			}
+		}
+	}
+	return index, keys
+}
+
+func exerciseDiff(exercise locatedExercise, changeType string, changes []models.FieldChange) models.ExerciseDiff {
+	return models.ExerciseDiff{
+		DayOfWeek:   exercise.DayOfWeek,
+		MuscleGroup: exercise.MuscleGroup,
+		Name:        exercise.Name,
+		ChangeType:  changeType,
+		Changes:     changes,
+	}
+}
+
+func exerciseChanges(from, to models.ExerciseSnapshot) []models.FieldChange {
+	var changes []models.FieldChange
+	changes = appendChange(changes, "sets", from.Sets, to.Sets)
+	changes = appendChange(changes, "reps", from.Reps, to.Reps)
+	changes = appendChange(changes, "weight", from.Weight, to.Weight)
+	changes = appendChange(changes, "duration", from.Duration, to.Duration)
+	changes = appendChange(changes, "rest_time", from.RestTime, to.RestTime)
+	changes = appendChange(changes, "rest_seconds", from.RestSeconds, to.RestSeconds)
+	changes = appendChange(changes, "order", from.Order, to.Order)
+	changes = appendChange(changes, "group", from.Group, to.Group)
+	changes = appendChange(changes, "description", from.Description, to.Description)
+	changes = appendChange(changes, "instructions", from.Instructions, to.Instructions)
+	changes = appendChange(changes, "notes", from.Notes, to.Notes)
+	return changes
+}
+
+func appendChange(changes []models.FieldChange, field string, from, to interface{}) []models.FieldChange {
+	if from != to {
+		changes = append(changes, models.FieldChange{Field: field, From: from, To: to})
+	}
+	return changes
+}
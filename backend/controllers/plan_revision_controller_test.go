@@ -0,0 +1,265 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"gymates-backend/config"
+	"gymates-backend/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// TestDiffPlanSnapshots 测试修订版本对比
+func TestDiffPlanSnapshots(t *testing.T) {
+	base := models.PlanSnapshot{
+		Name: "推拉腿",
+		Days: []models.DaySnapshot{
+			{
+				DayOfWeek: 1,
+				DayName:   "Monday",
+				Parts: []models.PartSnapshot{
+					{
+						MuscleGroup: "chest",
+						Exercises: []models.ExerciseSnapshot{
+							{Name: "平板卧推", Sets: 4, Reps: 10, Weight: 60},
+							{Name: "哑铃飞鸟", Sets: 3, Reps: 12},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	t.Run("相同快照无差异", func(t *testing.T) {
+		diff := diffPlanSnapshots(base, base)
+		assert.Empty(t, diff.PlanChanges)
+		assert.Empty(t, diff.Days)
+		assert.Empty(t, diff.Exercises)
+	})
+
+	t.Run("动作增删改", func(t *testing.T) {
+		changed := models.PlanSnapshot{
+			Name: "推拉腿 v2",
+			Days: []models.DaySnapshot{
+				{
+					DayOfWeek: 1,
+					DayName:   "Monday",
+					Parts: []models.PartSnapshot{
+						{
+							MuscleGroup: "chest",
+							Exercises: []models.ExerciseSnapshot{
+								{Name: "平板卧推", Sets: 5, Reps: 5, Weight: 80},
+								{Name: "上斜卧推", Sets: 3, Reps: 10},
+							},
+						},
+					},
+				},
+				{DayOfWeek: 3, DayName: "Wednesday", IsRestDay: true},
+			},
+		}
+
+		diff := diffPlanSnapshots(base, changed)
+		require.Len(t, diff.PlanChanges, 1)
+		assert.Equal(t, "name", diff.PlanChanges[0].Field)
+
+		require.Len(t, diff.Days, 1)
+		assert.Equal(t, 3, diff.Days[0].DayOfWeek)
+		assert.Equal(t, "added", diff.Days[0].ChangeType)
+
+		changeTypes := map[string]string{}
+		for _, exercise := range diff.Exercises {
+			changeTypes[exercise.Name] = exercise.ChangeType
+		}
+		assert.Equal(t, map[string]string{
+			"平板卧推": "modified",
+			"哑铃飞鸟": "removed",
+			"上斜卧推": "added",
+		}, changeTypes)
+
+		for _, exercise := range diff.Exercises {
+			if exercise.Name == "平板卧推" {
+				fields := []string{}
+				for _, change := range exercise.Changes {
+					fields = append(fields, change.Field)
+				}
+				assert.Equal(t, []string{"sets", "reps", "weight"}, fields)
+			}
+		}
+	})
+}
+
+// TestRollbackRevision 测试回滚到历史版本
+func TestRollbackRevision(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	setupTestDB(t)
+
+	var owner, other models.User
+	require.NoError(t, config.DB.First(&owner, 1).Error)
+	require.NoError(t, config.DB.First(&other, 2).Error)
+
+	plan := createTestWeeklyPlan(t, owner.ID, false)
+	require.NoError(t, savePlanRevision(config.DB, plan.ID, owner.ID, "create"))
+
+	// 修改计划：删除一个动作并改名，生成第二个版本
+	require.NoError(t, config.DB.Model(&plan).Update("name", "改过的计划").Error)
+	require.NoError(t, config.DB.Where("training_plan_id = ? AND name = ?", plan.ID, "哑铃飞鸟").
+		Delete(&models.Exercise{}).Error)
+	require.NoError(t, savePlanRevision(config.DB, plan.ID, owner.ID, "update"))
+
+	controller := NewPlanRevisionController()
+	newRouter := func(user *models.User) *gin.Engine {
+		router := gin.New()
+		router.GET("/api/training/weekly-plans/:id/revisions", withTestUser(user), controller.GetRevisions)
+		router.GET("/api/training/weekly-plans/:id/revisions/diff", withTestUser(user), controller.DiffRevisions)
+		router.POST("/api/training/weekly-plans/:id/revisions/:version/rollback", withTestUser(user), controller.RollbackRevision)
+		return router
+	}
+	planPath := "/api/training/weekly-plans/" + uintToString(plan.ID) + "/revisions"
+
+	t.Run("对比版本", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", planPath+"/diff?from=1&to=2", nil)
+		w := httptest.NewRecorder()
+		newRouter(&owner).ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var response struct {
+			Data models.PlanRevisionDiff `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		require.Len(t, response.Data.Exercises, 1)
+		assert.Equal(t, "哑铃飞鸟", response.Data.Exercises[0].Name)
+		assert.Equal(t, "removed", response.Data.Exercises[0].ChangeType)
+	})
+
+	t.Run("非所有者无权访问", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", planPath, nil)
+		w := httptest.NewRecorder()
+		newRouter(&other).ServeHTTP(w, req)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("回滚到版本1", func(t *testing.T) {
+		req, _ := http.NewRequest("POST", planPath+"/1/rollback", nil)
+		w := httptest.NewRecorder()
+		newRouter(&owner).ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var restored models.WeeklyTrainingPlan
+		require.NoError(t, config.DB.Preload("Days.Parts.Exercises").First(&restored, plan.ID).Error)
+		assert.Equal(t, "推拉腿模板", restored.Name)
+		require.Len(t, restored.Days, 1)
+		require.Len(t, restored.Days[0].Parts, 1)
+		assert.Len(t, restored.Days[0].Parts[0].Exercises, 2)
+
+		var revisions []models.WeeklyTrainingPlanRevision
+		config.DB.Where("weekly_training_plan_id = ?", plan.ID).Order("version").Find(&revisions)
+		require.Len(t, revisions, 3)
+		assert.Equal(t, "rollback to v1", revisions[2].Summary)
+
+		// 回滚后的快照与版本1内容一致
+		current, err := loadPlanSnapshot(plan.ID, 3)
+		require.NoError(t, err)
+		first, err := loadPlanSnapshot(plan.ID, 1)
+		require.NoError(t, err)
+		diff := diffPlanSnapshots(first, current)
+		assert.Empty(t, diff.Exercises)
+		assert.Empty(t, diff.PlanChanges)
+	})
+
+	t.Run("版本不存在", func(t *testing.T) {
+		req, _ := http.NewRequest("POST", planPath+"/99/rollback", nil)
+		w := httptest.NewRecorder()
+		newRouter(&owner).ServeHTTP(w, req)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+// TestPlanRevisionBaseline 测试没有修订版本的旧计划首次修改前保存原始状态，以及版本号冲突时重试
+func TestPlanRevisionBaseline(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	setupTestDB(t)
+
+	var owner models.User
+	require.NoError(t, config.DB.First(&owner, 1).Error)
+
+	// 修订功能上线前创建的计划没有任何修订版本
+	plan := createTestWeeklyPlan(t, owner.ID, false)
+
+	controller := NewWeeklyTrainingPlanController()
+	router := gin.New()
+	router.PUT("/weekly-plans/:id", withTestUser(&owner), controller.UpdateWeeklyTrainingPlan)
+	rename := func(t *testing.T, name string) {
+		body, _ := json.Marshal(models.UpdateWeeklyTrainingPlanRequest{Name: name})
+		req, _ := http.NewRequest("PUT", "/weekly-plans/"+uintToString(plan.ID), bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	}
+	revisions := func(t *testing.T) []models.WeeklyTrainingPlanRevision {
+		var list []models.WeeklyTrainingPlanRevision
+		require.NoError(t, config.DB.Where("weekly_training_plan_id = ?", plan.ID).Order("version").Find(&list).Error)
+		return list
+	}
+
+	rename(t, "改名一次")
+	list := revisions(t)
+	require.Len(t, list, 2)
+	assert.Equal(t, []string{"baseline", "update"}, []string{list[0].Summary, list[1].Summary})
+	original, err := loadPlanSnapshot(plan.ID, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "推拉腿模板", original.Name)
+	require.Len(t, original.Days, 1)
+	assert.Len(t, original.Days[0].Parts[0].Exercises, 2)
+
+	// 已有修订版本时不再保存 baseline
+	rename(t, "改名两次")
+	assert.Len(t, revisions(t), 3)
+
+	t.Run("版本号冲突时重试", func(t *testing.T) {
+		// 模拟另一个请求在取版本号之后抢先写入了同一个版本
+		injected := false
+		require.NoError(t, config.DB.Callback().Create().Before("gorm:create").Register("test:revision_race", func(db *gorm.DB) {
+			revision, ok := db.Statement.Dest.(*models.WeeklyTrainingPlanRevision)
+			if !ok || injected {
+				return
+			}
+			injected = true
+			db.Session(&gorm.Session{NewDB: true}).Exec(
+				"INSERT INTO weekly_training_plan_revisions (weekly_training_plan_id, version, author_id, summary, snapshot, created_at) VALUES (?, ?, ?, ?, ?, ?)",
+				revision.WeeklyTrainingPlanID, revision.Version, owner.ID, "concurrent", "{}", time.Now())
+		}))
+		defer config.DB.Callback().Create().Remove("test:revision_race")
+
+		require.NoError(t, savePlanRevision(config.DB, plan.ID, owner.ID, "update"))
+		assert.True(t, injected)
+		list := revisions(t)
+		require.Len(t, list, 4)
+		assert.Equal(t, 4, list[3].Version)
+		assert.Equal(t, "update", list[3].Summary)
+	})
+
+	t.Run("其他错误不重试", func(t *testing.T) {
+		attempts := 0
+		require.NoError(t, config.DB.Callback().Create().Before("gorm:create").Register("test:revision_failure", func(db *gorm.DB) {
+			if _, ok := db.Statement.Dest.(*models.WeeklyTrainingPlanRevision); ok {
+				attempts++
+				db.AddError(errors.New("disk I/O error"))
+			}
+		}))
+		defer config.DB.Callback().Create().Remove("test:revision_failure")
+
+		assert.EqualError(t, savePlanRevision(config.DB, plan.ID, owner.ID, "update"), "disk I/O error")
+		assert.Equal(t, 1, attempts)
+		assert.Len(t, revisions(t), 4)
+	})
+}
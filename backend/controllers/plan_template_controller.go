@@ -0,0 +1,489 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gymates-backend/config"
+	"gymates-backend/models"
+)
+
+// PlanTemplateController 训练计划模板市场控制器
+// 公开的一周训练计划即为模板，可浏览、评分和复制到自己的账户
+type PlanTemplateController struct{}
+
+// NewPlanTemplateController 创建训练计划模板控制器
+func NewPlanTemplateController() *PlanTemplateController {
+	return &PlanTemplateController{}
+}
+
+// GetTemplates 浏览公开模板
+// GET /api/training/templates?q=&sort=popular|rating|newest
+func (ptc *PlanTemplateController) GetTemplates(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if page < 1 {
+		page = 1
+	}
+	limit := parseLimit(c, 10, 50)
+	keyword := c.Query("q")
+	sort := c.DefaultQuery("sort", "popular")
+
+	var plans []models.WeeklyTrainingPlan
+	var total int64
+
+	query := config.DB.Model(&models.WeeklyTrainingPlan{}).Where("is_public = ?", true)
+	if keyword != "" {
+		query = query.Where("name LIKE ? OR description LIKE ?", "%"+keyword+"%", "%"+keyword+"%")
+	}
+
+	// 获取总数
+	query.Count(&total)
+
+	order := "fork_count DESC, rating_average DESC"
+	switch sort {
+	case "rating":
+		order = "rating_average DESC, rating_count DESC"
+	case "newest":
+		order = "created_at DESC"
+	}
+
+	// 分页查询
+	offset := (page - 1) * limit
+	if err := query.Preload("User").Preload("Days.Parts.Exercises").Preload("Days.Parts.Groups.Exercises").
+		Offset(offset).Limit(limit).Order(order).Find(&plans).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Success: false,
+			Message: "获取训练计划模板失败",
+			Error:   err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	pagination := models.Pagination{
+		Page:       page,
+		Limit:      limit,
+		Total:      total,
+		TotalPages: int((total + int64(limit) - 1) / int64(limit)),
+		HasMore:    int64(page*limit) < total,
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "获取训练计划模板成功",
+		Data: models.WeeklyTrainingPlansResponse{
+			Plans:      plans,
+			Pagination: pagination,
+		},
+	})
+}
+
+// GetTemplate 获取模板详情
+// GET /api/training/templates/:id
+func (ptc *PlanTemplateController) GetTemplate(c *gin.Context) {
+	planID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Message: "无效的模板ID",
+			Error:   err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	var plan models.WeeklyTrainingPlan
+	if err := config.DB.Where("is_public = ?", true).
+		Preload("User").Preload("Days.Parts.Exercises").Preload("Days.Parts.Groups.Exercises").
+		First(&plan, uint(planID)).Error; err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Success: false,
+			Message: "训练计划模板不存在",
+			Error:   "Plan template not found",
+			Code:    http.StatusNotFound,
+		})
+		return
+	}
+
+	var ratings []models.PlanTemplateRating
+	config.DB.Where("weekly_training_plan_id = ?", plan.ID).
+		Preload("User").Order("created_at DESC").Limit(20).Find(&ratings)
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "获取训练计划模板成功",
+		Data: gin.H{
+			"plan":    plan,
+			"ratings": ratings,
+		},
+	})
+}
+
+// RateTemplate 为模板评分（每个用户一条，重复评分会覆盖）
+// POST /api/training/templates/:id/rate
+func (ptc *PlanTemplateController) RateTemplate(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Success: false,
+			Message: "用户未认证",
+			Error:   "User not authenticated",
+			Code:    http.StatusUnauthorized,
+		})
+		return
+	}
+
+	planID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Message: "无效的模板ID",
+			Error:   err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	var req models.RatePlanTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Message: "请求参数错误",
+			Error:   err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	currentUser := user.(*models.User)
+
+	var plan models.WeeklyTrainingPlan
+	if err := config.DB.Where("is_public = ?", true).First(&plan, uint(planID)).Error; err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Success: false,
+			Message: "训练计划模板不存在",
+			Error:   "Plan template not found",
+			Code:    http.StatusNotFound,
+		})
+		return
+	}
+
+	if plan.UserID == currentUser.ID {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Message: "不能为自己的模板评分",
+			Error:   "Cannot rate your own template",
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	var rating models.PlanTemplateRating
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("weekly_training_plan_id = ? AND user_id = ?", plan.ID, currentUser.ID).
+			First(&rating).Error; err != nil {
+			rating = models.PlanTemplateRating{
+				WeeklyTrainingPlanID: plan.ID,
+				UserID:               currentUser.ID,
+			}
+		}
+		rating.Rating = req.Rating
+		rating.Comment = req.Comment
+		if err := tx.Save(&rating).Error; err != nil {
+			return err
+		}
+
+		// 重新计算平均分
+		var summary struct {
+			Average float64
+			Count   int
+		}
+		if err := tx.Model(&models.PlanTemplateRating{}).
+			Select("COALESCE(AVG(rating), 0) AS average, COUNT(*) AS count").
+			Where("weekly_training_plan_id = ?", plan.ID).
+			Scan(&summary).Error; err != nil {
+			return err
+		}
+		return tx.Model(&plan).Updates(map[string]interface{}{
+			"rating_average": summary.Average,
+			"rating_count":   summary.Count,
+		}).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Success: false,
+			Message: "模板评分失败",
+			Error:   err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	// 重新加载数据
+	config.DB.First(&plan, plan.ID)
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "模板评分成功",
+		Data: gin.H{
+			"rating":         rating,
+			"rating_average": plan.RatingAverage,
+			"rating_count":   plan.RatingCount,
+		},
+	})
+}
+
+// ForkTemplate 复制模板到自己的账户（深拷贝训练日、训练部位和训练动作）
+// POST /api/training/templates/:id/fork
+func (ptc *PlanTemplateController) ForkTemplate(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Success: false,
+			Message: "用户未认证",
+			Error:   "User not authenticated",
+			Code:    http.StatusUnauthorized,
+		})
+		return
+	}
+
+	planID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Message: "无效的模板ID",
+			Error:   err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	var req models.ForkPlanTemplateRequest
+	// 请求体可选
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Success: false,
+				Message: "请求参数错误",
+				Error:   err.Error(),
+				Code:    http.StatusBadRequest,
+			})
+			return
+		}
+	}
+
+	currentUser := user.(*models.User)
+
+	var template models.WeeklyTrainingPlan
+	if err := config.DB.Where("is_public = ?", true).
+		Preload("Days.Parts.Exercises").Preload("Days.Parts.Groups.Exercises").
+		First(&template, uint(planID)).Error; err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Success: false,
+			Message: "训练计划模板不存在",
+			Error:   "Plan template not found",
+			Code:    http.StatusNotFound,
+		})
+		return
+	}
+
+	var fork models.WeeklyTrainingPlan
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		name := req.Name
+		if name == "" {
+			name = template.Name
+		}
+
+		fork = models.WeeklyTrainingPlan{
+			UserID:           currentUser.ID,
+			Name:             name,
+			Description:      template.Description,
+			SourceTemplateID: &template.ID,
+		}
+		if err := tx.Create(&fork).Error; err != nil {
+			return err
+		}
+		if err := copyTrainingDays(tx, fork.ID, template.Days); err != nil {
+			return err
+		}
+
+		// 复制的计划默认私有；激活时停用用户的其他计划
+		if req.Activate {
+			if err := tx.Model(&models.WeeklyTrainingPlan{}).
+				Where("user_id = ? AND id != ?", currentUser.ID, fork.ID).
+				Update("is_active", false).Error; err != nil {
+				return err
+			}
+		} else if err := tx.Model(&fork).Update("is_active", false).Error; err != nil {
+			return err
+		}
+
+		if err := savePlanRevision(tx, fork.ID, currentUser.ID, "fork"); err != nil {
+			return err
+		}
+
+		return tx.Model(&template).UpdateColumn("fork_count", gorm.Expr("fork_count + ?", 1)).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Success: false,
+			Message: "复制训练计划模板失败",
+			Error:   err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	// 重新加载数据
+	config.DB.Preload("User").Preload("Days.Parts.Exercises").Preload("Days.Parts.Groups.Exercises").First(&fork, fork.ID)
+
+	c.JSON(http.StatusCreated, models.SuccessResponse{
+		Success: true,
+		Message: "复制训练计划模板成功",
+		Data: models.WeeklyTrainingPlanResponse{
+			Plan: fork,
+		},
+	})
+}
+
+// GetMyTemplates 获取当前用户发布的模板及复制、使用统计
+// GET /api/training/my-templates
+func (ptc *PlanTemplateController) GetMyTemplates(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Success: false,
+			Message: "用户未认证",
+			Error:   "User not authenticated",
+			Code:    http.StatusUnauthorized,
+		})
+		return
+	}
+
+	currentUser := user.(*models.User)
+
+	var plans []models.WeeklyTrainingPlan
+	if err := config.DB.Where("user_id = ? AND is_public = ?", currentUser.ID, true).
+		Order("created_at DESC").Find(&plans).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Success: false,
+			Message: "获取模板统计失败",
+			Error:   err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	stats := make([]models.PlanTemplateStats, 0, len(plans))
+	for _, plan := range plans {
+		var usageCount int64
+		config.DB.Model(&models.WeeklyTrainingPlan{}).
+			Where("source_template_id = ? AND is_active = ?", plan.ID, true).
+			Count(&usageCount)
+
+		stats = append(stats, models.PlanTemplateStats{
+			PlanID:        plan.ID,
+			Name:          plan.Name,
+			ForkCount:     plan.ForkCount,
+			UsageCount:    usageCount,
+			RatingAverage: plan.RatingAverage,
+			RatingCount:   plan.RatingCount,
+		})
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "获取模板统计成功",
+		Data:    stats,
+	})
+}
+
+// copyTrainingDays 将训练日及其训练部位、动作组、训练动作深拷贝到指定计划
+func copyTrainingDays(tx *gorm.DB, planID uint, days []models.TrainingDay) error {
+	for _, srcDay := range days {
+		day := models.TrainingDay{
+			WeeklyTrainingPlanID: planID,
+			DayOfWeek:            srcDay.DayOfWeek,
+			DayName:              srcDay.DayName,
+			IsRestDay:            srcDay.IsRestDay,
+			Notes:                srcDay.Notes,
+		}
+		if err := tx.Create(&day).Error; err != nil {
+			return err
+		}
+
+		for _, srcPart := range srcDay.Parts {
+			part := models.TrainingPart{
+				TrainingDayID:   day.ID,
+				MuscleGroup:     srcPart.MuscleGroup,
+				MuscleGroupName: srcPart.MuscleGroupName,
+				Order:           srcPart.Order,
+			}
+			if err := tx.Create(&part).Error; err != nil {
+				return err
+			}
+
+			groupIDs := make(map[uint]uint, len(srcPart.Groups))
+			for _, srcGroup := range srcPart.Groups {
+				group := models.ExerciseGroup{
+					TrainingPartID:   part.ID,
+					BlockType:        srcGroup.BlockType,
+					Name:             srcGroup.Name,
+					Rounds:           srcGroup.Rounds,
+					WorkSeconds:      srcGroup.WorkSeconds,
+					RestSeconds:      srcGroup.RestSeconds,
+					RoundRestSeconds: srcGroup.RoundRestSeconds,
+					TimeCapSeconds:   srcGroup.TimeCapSeconds,
+					Order:            srcGroup.Order,
+				}
+				if err := tx.Create(&group).Error; err != nil {
+					return err
+				}
+				if srcGroup.Rounds == 0 {
+					if err := tx.Model(&group).Update("rounds", 0).Error; err != nil {
+						return err
+					}
+				}
+				groupIDs[srcGroup.ID] = group.ID
+			}
+
+			for _, srcExercise := range srcPart.Exercises {
+				var groupID *uint
+				if srcExercise.ExerciseGroupID != nil {
+					if id, ok := groupIDs[*srcExercise.ExerciseGroupID]; ok {
+						groupID = &id
+					}
+				}
+				exercise := models.Exercise{
+					TrainingPlanID:    planID,
+					TrainingPartID:    &part.ID,
+					ExerciseGroupID:   groupID,
+					ExerciseLibraryID: srcExercise.ExerciseLibraryID,
+					Name:              srcExercise.Name,
+					Description:       srcExercise.Description,
+					MuscleGroup:       srcExercise.MuscleGroup,
+					Difficulty:        srcExercise.Difficulty,
+					Equipment:         srcExercise.Equipment,
+					Sets:              srcExercise.Sets,
+					Reps:              srcExercise.Reps,
+					Weight:            srcExercise.Weight,
+					Duration:          srcExercise.Duration,
+					RestTime:          srcExercise.RestTime,
+					RestSeconds:       srcExercise.RestSeconds,
+					Instructions:      srcExercise.Instructions,
+					ImageURL:          srcExercise.ImageURL,
+					VideoURL:          srcExercise.VideoURL,
+					Calories:          srcExercise.Calories,
+					Notes:             srcExercise.Notes,
+					Order:             srcExercise.Order,
+				}
+				if err := tx.Create(&exercise).Error; err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,184 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"gymates-backend/config"
+	"gymates-backend/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// withTestUser 模拟认证中间件，将指定用户写入上下文
+func withTestUser(user *models.User) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set("user", user)
+		c.Set("user_id", user.ID)
+		c.Next()
+	}
+}
+
+// createTestWeeklyPlan 创建包含一个训练日、一个部位和两个动作的一周训练计划
+func createTestWeeklyPlan(t *testing.T, userID uint, isPublic bool) models.WeeklyTrainingPlan {
+	plan := models.WeeklyTrainingPlan{
+		UserID:   userID,
+		Name:     "推拉腿模板",
+		IsPublic: isPublic,
+		Days: []models.TrainingDay{
+			{
+				DayOfWeek: 1,
+				DayName:   "Monday",
+				Parts: []models.TrainingPart{
+					{
+						MuscleGroup:     "chest",
+						MuscleGroupName: "Chest",
+						Order:           1,
+					},
+				},
+			},
+		},
+	}
+	require.NoError(t, config.DB.Create(&plan).Error)
+
+	part := plan.Days[0].Parts[0]
+	for i, name := range []string{"平板卧推", "哑铃飞鸟"} {
+		exercise := models.Exercise{
+			TrainingPlanID: plan.ID,
+			TrainingPartID: &part.ID,
+			Name:           name,
+			Sets:           4,
+			Reps:           10,
+			Order:          i + 1,
+		}
+		require.NoError(t, config.DB.Create(&exercise).Error)
+	}
+	return plan
+}
+
+// TestGetTemplatesPagination 测试模板列表的分页参数越界时取默认值或上限
+func TestGetTemplatesPagination(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	setupTestDB(t)
+
+	var author models.User
+	require.NoError(t, config.DB.First(&author, 1).Error)
+	createTestWeeklyPlan(t, author.ID, true)
+
+	router := gin.New()
+	router.GET("/api/training/templates", NewPlanTemplateController().GetTemplates)
+
+	tests := []struct {
+		name      string
+		query     string
+		wantPage  int
+		wantLimit int
+	}{
+		{name: "limit为0", query: "?limit=0", wantPage: 1, wantLimit: 10},
+		{name: "limit为负数", query: "?limit=-5", wantPage: 1, wantLimit: 10},
+		{name: "limit超过上限", query: "?limit=1000", wantPage: 1, wantLimit: 50},
+		{name: "page为负数", query: "?page=-2&limit=5", wantPage: 1, wantLimit: 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var response models.WeeklyTrainingPlansResponse
+			require.Equal(t, http.StatusOK, sendJSON(t, router, "GET", "/api/training/templates"+tt.query, nil, &response))
+			assert.Equal(t, tt.wantPage, response.Pagination.Page)
+			assert.Equal(t, tt.wantLimit, response.Pagination.Limit)
+			assert.Positive(t, response.Pagination.TotalPages)
+			assert.NotEmpty(t, response.Plans)
+		})
+	}
+}
+
+// TestForkTemplate 测试复制模板
+func TestForkTemplate(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	setupTestDB(t)
+
+	var author, forker models.User
+	require.NoError(t, config.DB.First(&author, 1).Error)
+	require.NoError(t, config.DB.First(&forker, 2).Error)
+
+	template := createTestWeeklyPlan(t, author.ID, true)
+	private := createTestWeeklyPlan(t, author.ID, false)
+
+	router := gin.New()
+	router.POST("/api/training/templates/:id/fork", withTestUser(&forker), NewPlanTemplateController().ForkTemplate)
+
+	t.Run("复制公开模板", func(t *testing.T) {
+		body, _ := json.Marshal(models.ForkPlanTemplateRequest{Name: "我的推拉腿"})
+		req, _ := http.NewRequest("POST", "/api/training/templates/"+uintToString(template.ID)+"/fork", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusCreated, w.Code)
+
+		var fork models.WeeklyTrainingPlan
+		require.NoError(t, config.DB.Where("user_id = ? AND source_template_id = ?", forker.ID, template.ID).
+			Preload("Days.Parts.Exercises").First(&fork).Error)
+		assert.Equal(t, "我的推拉腿", fork.Name)
+		assert.False(t, fork.IsPublic)
+		assert.False(t, fork.IsActive)
+		require.Len(t, fork.Days, 1)
+		require.Len(t, fork.Days[0].Parts, 1)
+		assert.Len(t, fork.Days[0].Parts[0].Exercises, 2)
+		assert.NotEqual(t, template.Days[0].ID, fork.Days[0].ID)
+
+		var updated models.WeeklyTrainingPlan
+		config.DB.First(&updated, template.ID)
+		assert.Equal(t, 1, updated.ForkCount)
+	})
+
+	t.Run("私有计划不能复制", func(t *testing.T) {
+		req, _ := http.NewRequest("POST", "/api/training/templates/"+uintToString(private.ID)+"/fork", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+// TestRateTemplate 测试模板评分
+func TestRateTemplate(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	setupTestDB(t)
+
+	var author, rater models.User
+	require.NoError(t, config.DB.First(&author, 1).Error)
+	require.NoError(t, config.DB.First(&rater, 3).Error)
+
+	template := createTestWeeklyPlan(t, author.ID, true)
+
+	rate := func(user *models.User, rating int) int {
+		router := gin.New()
+		router.POST("/api/training/templates/:id/rate", withTestUser(user), NewPlanTemplateController().RateTemplate)
+		body, _ := json.Marshal(models.RatePlanTemplateRequest{Rating: rating})
+		req, _ := http.NewRequest("POST", "/api/training/templates/"+uintToString(template.ID)+"/rate", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusOK, rate(&rater, 3))
+	// 重复评分覆盖之前的评分
+	assert.Equal(t, http.StatusOK, rate(&rater, 5))
+	assert.Equal(t, http.StatusBadRequest, rate(&author, 5))
+	assert.Equal(t, http.StatusBadRequest, rate(&rater, 6))
+
+	var updated models.WeeklyTrainingPlan
+	config.DB.First(&updated, template.ID)
+	assert.Equal(t, 1, updated.RatingCount)
+	assert.Equal(t, 5.0, updated.RatingAverage)
+}
+
+func uintToString(id uint) string {
+	return strconv.FormatUint(uint64(id), 10)
+}
@@ -0,0 +1,374 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gymates-backend/config"
+	"gymates-backend/models"
+)
+
+// 拉黑和隐私设置拒绝的操作
+var (
+	errUserBlocked       = errors.New("user is blocked")
+	errMessageNotAllowed = errors.New("recipient does not accept messages from you")
+)
+
+// writePrivacyError 拉黑或隐私设置拒绝时返回403
+func writePrivacyError(c *gin.Context, message string, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, errUserBlocked):
+		status = http.StatusForbidden
+	case errors.Is(err, errMessageNotAllowed):
+		status, message = http.StatusForbidden, "对方设置了不接收你的私信"
+	}
+	c.JSON(status, models.ErrorResponse{
+		Success: false,
+		Message: message,
+		Error:   err.Error(),
+		Code:    status,
+	})
+}
+
+// viewerID 当前登录用户的ID，未登录时为0
+func viewerID(c *gin.Context) uint {
+	if user, exists := c.Get("user"); exists {
+		return user.(*models.User).ID
+	}
+	return 0
+}
+
+// blockedUserIDs 与 userID 之间有拉黑关系的用户，不论是谁拉黑了谁
+func blockedUserIDs(userID uint) []uint {
+	ids, _ := relatedUserIDs(userID, models.MateStatusBlocked)
+	return ids
+}
+
+// mutedUserIDs userID 静音的用户
+func mutedUserIDs(userID uint) []uint {
+	var ids []uint
+	config.DB.Model(&models.UserMute{}).Where("user_id = ?", userID).Pluck("muted_id", &ids)
+	return ids
+}
+
+// hiddenUserIDs 对 userID 隐藏内容的用户：拉黑关系的另一方和自己静音的用户
+//
+// 结果至少包含0，可以直接用于 NOT IN，避免空列表。
+func hiddenUserIDs(userID uint) []uint {
+	if userID == 0 {
+		return []uint{0}
+	}
+	return append(append(blockedUserIDs(userID), mutedUserIDs(userID)...), 0)
+}
+
+// isBlockedBetween 两人之间是否有拉黑关系
+func isBlockedBetween(a, b uint) bool {
+	low, high := models.MatePair(a, b)
+	var count int64
+	config.DB.Model(&models.MateRelation{}).
+		Where("user_low_id = ? AND user_high_id = ? AND status = ?", low, high, models.MateStatusBlocked).
+		Count(&count)
+	return count > 0
+}
+
+// areMates 两人是否是搭子
+func areMates(a, b uint) bool {
+	low, high := models.MatePair(a, b)
+	var count int64
+	config.DB.Model(&models.MateRelation{}).
+		Where("user_low_id = ? AND user_high_id = ? AND status = ?", low, high, models.MateStatusAccepted).
+		Count(&count)
+	return count > 0
+}
+
+// loadPrivacySettings 用户的隐私设置，没有设置过时返回默认值
+func loadPrivacySettings(userID uint) models.PrivacySettings {
+	settings := models.DefaultPrivacySettings(userID)
+	config.DB.Where("user_id = ?", userID).Limit(1).Find(&settings)
+	return settings
+}
+
+// visibleTo 按可见范围判断 viewerID 能否看到 ownerID 的内容
+func visibleTo(visibility string, ownerID, viewerID uint) bool {
+	switch {
+	case viewerID != 0 && ownerID == viewerID:
+		return true
+	case visibility == models.VisibilityNobody:
+		return false
+	case visibility == models.VisibilityMates:
+		return viewerID != 0 && areMates(ownerID, viewerID)
+	default:
+		return true
+	}
+}
+
+// privacyHiddenUsers 按隐私设置的某一项对 viewerID 不可见的用户，返回子查询
+//
+// column 为 PrivacySettings 的列名，如 profile_visibility、workout_visibility。
+func privacyHiddenUsers(column string, viewerID uint) *gorm.DB {
+	mateIDs := []uint{0}
+	if viewerID != 0 {
+		ids, _ := relatedUserIDs(viewerID, models.MateStatusAccepted)
+		mateIDs = append(mateIDs, ids...)
+	}
+	return config.DB.Model(&models.PrivacySettings{}).Select("user_id").
+		Where("user_id <> ?", viewerID).
+		Where("("+column+" = ? OR ("+column+" = ? AND user_id NOT IN ?))",
+			models.VisibilityNobody, models.VisibilityMates, mateIDs)
+}
+
+// visiblePosts 对 viewerID 可见的帖子：去掉拉黑、静音用户的帖子，以及按训练可见范围隐藏的训练动态
+func visiblePosts(viewerID uint) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("posts.user_id NOT IN ?", hiddenUserIDs(viewerID)).
+			Where("NOT (posts.type IN ? AND posts.user_id IN (?))", models.WorkoutPostTypes, privacyHiddenUsers("workout_visibility", viewerID))
+	}
+}
+
+// postVisibleTo 单个帖子对 viewerID 是否可见，规则同 visiblePosts
+func postVisibleTo(post models.Post, viewerID uint) bool {
+	if viewerID != 0 && post.UserID != viewerID {
+		for _, id := range hiddenUserIDs(viewerID) {
+			if id == post.UserID {
+				return false
+			}
+		}
+	}
+	for _, postType := range models.WorkoutPostTypes {
+		if post.Type == postType {
+			return visibleTo(loadPrivacySettings(post.UserID).WorkoutVisibility, post.UserID, viewerID)
+		}
+	}
+	return true
+}
+
+// checkCanMessage 发送方能否给接收方发私信：不能有拉黑关系，并且符合接收方的私信设置
+func checkCanMessage(senderID, recipientID uint) error {
+	if isBlockedBetween(senderID, recipientID) {
+		return errUserBlocked
+	}
+	if !visibleTo(loadPrivacySettings(recipientID).MessagePermission, recipientID, senderID) {
+		return errMessageNotAllowed
+	}
+	return nil
+}
+
+// checkCanMessageAll 发送方能否把这些用户拉进聊天，规则同 checkCanMessage
+func checkCanMessageAll(senderID uint, recipientIDs []uint) error {
+	for _, recipientID := range recipientIDs {
+		if recipientID == senderID {
+			continue
+		}
+		if err := checkCanMessage(senderID, recipientID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// unmutedRecipients 去掉静音了发送方的用户，用于实时推送
+func unmutedRecipients(senderID uint, userIDs []uint) []uint {
+	var muters []uint
+	config.DB.Model(&models.UserMute{}).Where("muted_id = ? AND user_id IN ?", senderID, append(userIDs, 0)).
+		Pluck("user_id", &muters)
+	if len(muters) == 0 {
+		return userIDs
+	}
+	muted := make(map[uint]bool, len(muters))
+	for _, id := range muters {
+		muted[id] = true
+	}
+	recipients := make([]uint, 0, len(userIDs))
+	for _, id := range userIDs {
+		if !muted[id] {
+			recipients = append(recipients, id)
+		}
+	}
+	return recipients
+}
+
+// restrictProfile 资料不可见时只保留昵称和头像
+func restrictProfile(user models.User) models.User {
+	return models.User{
+		ID:                user.ID,
+		Name:              user.Name,
+		Avatar:            user.Avatar,
+		CreatedAt:         user.CreatedAt,
+		ProfileRestricted: true,
+	}
+}
+
+// MuteUser 静音用户：不再看到对方的帖子和评论，也不再收到对方消息的推送，对方不会知道
+func (mc *MatesController) MuteUser(c *gin.Context) {
+	updateMute(c, true)
+}
+
+// UnmuteUser 取消静音
+func (mc *MatesController) UnmuteUser(c *gin.Context) {
+	updateMute(c, false)
+}
+
+// updateMute 静音和取消静音都是幂等的
+func updateMute(c *gin.Context, mute bool) {
+	mutedID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Message: "无效的用户ID",
+			Error:   err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Success: false,
+			Message: "用户未认证",
+			Error:   "User not authenticated",
+			Code:    http.StatusUnauthorized,
+		})
+		return
+	}
+
+	currentUser := user.(*models.User)
+
+	if !mute {
+		if err := config.DB.Where("user_id = ? AND muted_id = ?", currentUser.ID, uint(mutedID)).
+			Delete(&models.UserMute{}).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+				Success: false,
+				Message: "取消静音失败",
+				Error:   err.Error(),
+				Code:    http.StatusInternalServerError,
+			})
+			return
+		}
+		c.JSON(http.StatusOK, models.SuccessResponse{
+			Success: true,
+			Message: "已取消静音",
+		})
+		return
+	}
+
+	if uint(mutedID) == currentUser.ID {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Message: "不能静音自己",
+			Error:   "Cannot mute yourself",
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	var target models.User
+	if err := config.DB.Select("id").First(&target, uint(mutedID)).Error; err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Success: false,
+			Message: "目标用户不存在",
+			Error:   "Target user not found",
+			Code:    http.StatusNotFound,
+		})
+		return
+	}
+
+	record := models.UserMute{UserID: currentUser.ID, MutedID: target.ID}
+	if err := config.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&record).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Success: false,
+			Message: "静音失败",
+			Error:   err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "已静音该用户",
+	})
+}
+
+// GetBlockedUsers 自己拉黑的用户，不包含拉黑了自己的用户
+func (mc *MatesController) GetBlockedUsers(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Success: false,
+			Message: "用户未认证",
+			Error:   "User not authenticated",
+			Code:    http.StatusUnauthorized,
+		})
+		return
+	}
+
+	currentUser := user.(*models.User)
+
+	var relations []models.MateRelation
+	users := []models.User{}
+	err := mateRelationsQuery(currentUser.ID, models.MateStatusBlocked).Find(&relations).Error
+	if err == nil {
+		ids := []uint{0}
+		for _, relation := range relations {
+			if relation.BlockedBy(currentUser.ID) {
+				ids = append(ids, relation.Other(currentUser.ID))
+			}
+		}
+		err = config.DB.Where("id IN ?", ids).Order("id").Find(&users).Error
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Success: false,
+			Message: "获取黑名单失败",
+			Error:   err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "获取黑名单成功",
+		Data:    users,
+	})
+}
+
+// GetMutedUsers 自己静音的用户
+func (mc *MatesController) GetMutedUsers(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Success: false,
+			Message: "用户未认证",
+			Error:   "User not authenticated",
+			Code:    http.StatusUnauthorized,
+		})
+		return
+	}
+
+	currentUser := user.(*models.User)
+
+	users := []models.User{}
+	if err := config.DB.Where("id IN ?", append(mutedUserIDs(currentUser.ID), 0)).
+		Order("id").Find(&users).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Success: false,
+			Message: "获取静音列表失败",
+			Error:   err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "获取静音列表成功",
+		Data:    users,
+	})
+}
@@ -0,0 +1,351 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gymates-backend/config"
+	"gymates-backend/models"
+	"gymates-backend/services/geo"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// privacyRouter 为每个用户挂一组带登录态的路由，anon 组没有登录用户
+func privacyRouter(users map[string]*models.User) *gin.Engine {
+	auth := NewAuthController()
+	mates := NewMatesController()
+	community := NewCommunityController()
+	messages := NewMessagesController()
+
+	router := gin.New()
+	register := func(group *gin.RouterGroup) {
+		group.GET("/users/:id", auth.GetUserProfile)
+		group.GET("/profile/privacy", auth.GetPrivacySettings)
+		group.PUT("/profile/privacy", auth.UpdatePrivacySettings)
+		group.GET("/mates/search", mates.SearchMates)
+		group.POST("/mates/requests", mates.SendMateRequest)
+		group.POST("/mates/:id/block", mates.BlockUser)
+		group.POST("/mates/:id/mute", mates.MuteUser)
+		group.DELETE("/mates/:id/mute", mates.UnmuteUser)
+		group.GET("/mates/blocks", mates.GetBlockedUsers)
+		group.GET("/mates/mutes", mates.GetMutedUsers)
+		group.GET("/search", community.SearchPosts)
+		group.POST("/posts", community.CreatePost)
+		group.GET("/posts/:id", community.GetPost)
+		group.GET("/posts/:id/comments", community.GetComments)
+		group.POST("/posts/:id/comments", community.CreateComment)
+		group.POST("/chats", messages.CreateChat)
+		group.POST("/chats/:id/messages", messages.SendMessage)
+	}
+	register(router.Group("/anon"))
+	for name, user := range users {
+		register(router.Group("/"+name, withTestUser(user)))
+	}
+	return router
+}
+
+// TestPrivacy 测试资料、私信和训练动态的可见范围
+func TestPrivacy(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	setupTestDB(t)
+
+	users := map[string]*models.User{}
+	for _, name := range []string{"pia", "max", "sam"} {
+		user := &models.User{Name: "隐私" + name, Email: "privacy-" + name + "@gymates.com", Password: "x", Bio: "练腿爱好者"}
+		require.NoError(t, config.DB.Create(user).Error)
+		users[name] = user
+	}
+	pia, max := users["pia"], users["max"]
+	createMateRelation(t, pia.ID, max.ID, models.MateStatusAccepted)
+
+	router := privacyRouter(users)
+
+	t.Run("默认对所有人公开", func(t *testing.T) {
+		var settings models.PrivacySettings
+		require.Equal(t, http.StatusOK, sendJSON(t, router, "GET", "/pia/profile/privacy", nil, &settings))
+		assert.Equal(t, models.DefaultPrivacySettings(pia.ID), settings)
+	})
+
+	t.Run("更新隐私设置", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, sendJSON(t, router, "PUT", "/pia/profile/privacy", map[string]string{"profile_visibility": "friends"}, nil))
+
+		var settings models.PrivacySettings
+		require.Equal(t, http.StatusOK, sendJSON(t, router, "PUT", "/pia/profile/privacy", map[string]string{
+			"profile_visibility": models.VisibilityMates,
+			"message_permission": models.VisibilityMates,
+		}, &settings))
+		assert.Equal(t, models.VisibilityMates, settings.ProfileVisibility)
+		assert.Equal(t, models.VisibilityEveryone, settings.WorkoutVisibility)
+
+		// 未提供的字段保持不变
+		require.Equal(t, http.StatusOK, sendJSON(t, router, "PUT", "/pia/profile/privacy", map[string]string{"workout_visibility": models.VisibilityNobody}, &settings))
+		assert.Equal(t, models.VisibilityMates, settings.MessagePermission)
+		assert.Equal(t, models.VisibilityNobody, settings.WorkoutVisibility)
+	})
+
+	t.Run("资料可见范围", func(t *testing.T) {
+		tests := []struct {
+			name       string
+			as         string
+			restricted bool
+		}{
+			{"自己", "pia", false},
+			{"搭子", "max", false},
+			{"陌生人", "sam", true},
+			{"未登录", "anon", true},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				var profile models.User
+				require.Equal(t, http.StatusOK, sendJSON(t, router, "GET", "/"+tt.as+"/users/"+uintToString(pia.ID), nil, &profile))
+				assert.Equal(t, pia.Name, profile.Name)
+				assert.Equal(t, tt.restricted, profile.ProfileRestricted)
+				if tt.restricted {
+					assert.Empty(t, profile.Bio)
+				} else {
+					assert.Equal(t, pia.Bio, profile.Bio)
+				}
+			})
+		}
+	})
+
+	t.Run("资料不公开时搜索不到", func(t *testing.T) {
+		search := func(t *testing.T, as string) []string {
+			var response models.MatesResponse
+			require.Equal(t, http.StatusOK, sendJSON(t, router, "GET", "/"+as+"/mates/search?q=隐私&limit=50", nil, &response))
+			names := []string{}
+			for _, user := range response.Mates {
+				names = append(names, user.Name)
+			}
+			return names
+		}
+		assert.ElementsMatch(t, []string{"隐私max"}, search(t, "sam"))
+		assert.ElementsMatch(t, []string{"隐私pia", "隐私sam"}, search(t, "max"))
+		assert.ElementsMatch(t, []string{"隐私max", "隐私sam"}, search(t, "pia"))
+	})
+
+	t.Run("私信权限", func(t *testing.T) {
+		payload := map[string]interface{}{"participant_ids": []uint{pia.ID}}
+		assert.Equal(t, http.StatusForbidden, sendJSON(t, router, "POST", "/sam/chats", payload, nil))
+		assert.Equal(t, http.StatusForbidden, sendJSON(t, router, "POST", "/sam/chats", map[string]interface{}{"participant_ids": []uint{pia.ID, max.ID}}, nil))
+		assert.Equal(t, http.StatusCreated, sendJSON(t, router, "POST", "/max/chats", payload, nil))
+	})
+
+	t.Run("训练动态可见范围", func(t *testing.T) {
+		workout := models.Post{UserID: pia.ID, Content: "隐私动态 今天练腿", Type: "training", IsPublic: true}
+		text := models.Post{UserID: pia.ID, Content: "隐私动态 周末去爬山", Type: "text", IsPublic: true}
+		require.NoError(t, config.DB.Create(&workout).Error)
+		require.NoError(t, config.DB.Create(&text).Error)
+
+		feed := func(t *testing.T, as string) []uint {
+			var response models.PostsResponse
+			require.Equal(t, http.StatusOK, sendJSON(t, router, "GET", "/"+as+"/search?q=隐私动态&limit=50", nil, &response))
+			ids := []uint{}
+			for _, post := range response.Posts {
+				ids = append(ids, post.ID)
+			}
+			return ids
+		}
+		assert.ElementsMatch(t, []uint{workout.ID, text.ID}, feed(t, "pia"))
+		assert.ElementsMatch(t, []uint{text.ID}, feed(t, "max"))
+		assert.ElementsMatch(t, []uint{text.ID}, feed(t, "anon"))
+
+		assert.Equal(t, http.StatusNotFound, sendJSON(t, router, "GET", "/sam/posts/"+uintToString(workout.ID), nil, nil))
+		assert.Equal(t, http.StatusNotFound, sendJSON(t, router, "GET", "/sam/posts/"+uintToString(workout.ID)+"/comments", nil, nil))
+		assert.Equal(t, http.StatusOK, sendJSON(t, router, "GET", "/pia/posts/"+uintToString(workout.ID), nil, nil))
+
+		// 改为仅搭子可见后搭子能看到
+		require.Equal(t, http.StatusOK, sendJSON(t, router, "PUT", "/pia/profile/privacy", map[string]string{"workout_visibility": models.VisibilityMates}, nil))
+		assert.ElementsMatch(t, []uint{workout.ID, text.ID}, feed(t, "max"))
+		assert.ElementsMatch(t, []uint{text.ID}, feed(t, "sam"))
+
+		// 帖子类型只能是定义过的类型，训练动态不能换个名字绕过可见范围
+		post := func(postType string) models.CreatePostRequest {
+			return models.CreatePostRequest{Content: "隐私动态 新类型", Type: postType}
+		}
+		assert.Equal(t, http.StatusBadRequest, sendJSON(t, router, "POST", "/pia/posts", post("workout_log"), nil))
+		var created models.Post
+		require.Equal(t, http.StatusCreated, sendJSON(t, router, "POST", "/pia/posts", post(models.PostTypeTraining), &created))
+		assert.ElementsMatch(t, []uint{text.ID}, feed(t, "sam"))
+		assert.ElementsMatch(t, []uint{workout.ID, text.ID, created.ID}, feed(t, "max"))
+	})
+}
+
+// TestMateDiscoveryPrivacy 测试搭子推荐和附近的人遵守资料可见范围和静音
+func TestMateDiscoveryPrivacy(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	setupTestDB(t)
+
+	users := map[string]*models.User{}
+	for i, name := range []string{"viewer", "open", "nobody", "mate", "stranger", "muted"} {
+		lat, lon, hash := geo.CoarsenUser(45.5+float64(i)*0.005, 10.5)
+		user := &models.User{Name: "发现" + name, Email: "discover-" + name + "@gymates.com", Password: "x",
+			Goal: "增肌", Experience: "中级", TrainingTimes: "evening", Latitude: &lat, Longitude: &lon, Geohash: hash}
+		require.NoError(t, config.DB.Create(user).Error)
+		users[name] = user
+	}
+	viewer := users["viewer"]
+	createMateRelation(t, viewer.ID, users["mate"].ID, models.MateStatusAccepted)
+	require.NoError(t, config.DB.Create(&models.UserMute{UserID: viewer.ID, MutedID: users["muted"].ID}).Error)
+	for name, visibility := range map[string]string{
+		"nobody": models.VisibilityNobody, "mate": models.VisibilityMates, "stranger": models.VisibilityMates,
+	} {
+		settings := models.DefaultPrivacySettings(users[name].ID)
+		settings.ProfileVisibility = visibility
+		require.NoError(t, config.DB.Create(&settings).Error)
+	}
+
+	mates := NewMatesController()
+	router := gin.New()
+	group := router.Group("/", withTestUser(viewer))
+	group.GET("/mates/suggestions", mates.GetSuggestions)
+	group.GET("/mates/nearby", mates.GetNearbyMates)
+	get := func(t *testing.T, path string, data interface{}) {
+		req, _ := http.NewRequest("GET", path, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+		response := struct {
+			Data interface{} `json:"data"`
+		}{Data: data}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	}
+
+	t.Run("推荐", func(t *testing.T) {
+		var response models.MateSuggestionsResponse
+		get(t, "/mates/suggestions?limit=50", &response)
+		names := []string{}
+		for _, suggestion := range response.Suggestions {
+			names = append(names, suggestion.User.Name)
+		}
+		assert.ElementsMatch(t, []string{"发现open"}, names)
+	})
+
+	t.Run("附近的人", func(t *testing.T) {
+		var response models.NearbyMatesResponse
+		get(t, "/mates/nearby?limit=50", &response)
+		names := []string{}
+		for _, nearby := range response.Mates {
+			names = append(names, nearby.User.Name)
+		}
+		// 资料仅搭子可见时搭子仍能看到
+		assert.ElementsMatch(t, []string{"发现open", "发现mate"}, names)
+	})
+}
+
+// TestBlockAndMute 测试拉黑和静音在搭子、社区和私信中的效果
+func TestBlockAndMute(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	setupTestDB(t)
+
+	users := map[string]*models.User{}
+	for _, name := range []string{"bo", "ki", "lu"} {
+		user := &models.User{Name: "黑名单" + name, Email: "block-" + name + "@gymates.com", Password: "x"}
+		require.NoError(t, config.DB.Create(user).Error)
+		users[name] = user
+	}
+	bo, ki, lu := users["bo"], users["ki"], users["lu"]
+
+	post := models.Post{UserID: bo.ID, Content: "黑名单动态 新的卧推记录", Type: "text", IsPublic: true}
+	require.NoError(t, config.DB.Create(&post).Error)
+	chat := models.Chat{Type: models.ChatTypeDirect}
+	require.NoError(t, config.DB.Create(&chat).Error)
+	for _, user := range []*models.User{bo, lu} {
+		require.NoError(t, config.DB.Create(&models.ChatParticipant{ChatID: chat.ID, UserID: user.ID}).Error)
+	}
+
+	router := privacyRouter(users)
+	postPath := "/posts/" + uintToString(post.ID)
+	commenters := func(t *testing.T, as string) []uint {
+		var response models.CommentsResponse
+		require.Equal(t, http.StatusOK, sendJSON(t, router, "GET", "/"+as+postPath+"/comments", nil, &response))
+		ids := []uint{}
+		for _, comment := range response.Comments {
+			ids = append(ids, comment.UserID)
+		}
+		assert.Equal(t, int64(len(ids)), response.Pagination.Total)
+		return ids
+	}
+	feed := func(t *testing.T, as string) int {
+		var response models.PostsResponse
+		require.Equal(t, http.StatusOK, sendJSON(t, router, "GET", "/"+as+"/search?q=黑名单动态", nil, &response))
+		return len(response.Posts)
+	}
+	userIDs := func(t *testing.T, path string) []uint {
+		var list []models.User
+		require.Equal(t, http.StatusOK, sendJSON(t, router, "GET", path, nil, &list))
+		ids := []uint{}
+		for _, user := range list {
+			ids = append(ids, user.ID)
+		}
+		return ids
+	}
+	comment := map[string]string{"content": "加油"}
+
+	require.Equal(t, http.StatusCreated, sendJSON(t, router, "POST", "/ki"+postPath+"/comments", comment, nil))
+	require.Equal(t, http.StatusCreated, sendJSON(t, router, "POST", "/lu"+postPath+"/comments", comment, nil))
+	require.ElementsMatch(t, []uint{ki.ID, lu.ID}, commenters(t, "bo"))
+
+	t.Run("拉黑", func(t *testing.T) {
+		require.Equal(t, http.StatusOK, sendJSON(t, router, "POST", "/bo/mates/"+uintToString(ki.ID)+"/block", nil, nil))
+
+		assert.Equal(t, []uint{ki.ID}, userIDs(t, "/bo/mates/blocks"))
+		assert.Empty(t, userIDs(t, "/ki/mates/blocks"))
+
+		// 双方互相看不到
+		assert.Equal(t, []uint{lu.ID}, commenters(t, "bo"))
+		assert.Equal(t, 0, feed(t, "ki"))
+		assert.Equal(t, 1, feed(t, "lu"))
+		assert.Equal(t, http.StatusNotFound, sendJSON(t, router, "GET", "/ki"+postPath, nil, nil))
+		assert.Equal(t, http.StatusNotFound, sendJSON(t, router, "GET", "/ki"+postPath+"/comments", nil, nil))
+		assert.Equal(t, http.StatusNotFound, sendJSON(t, router, "GET", "/ki/users/"+uintToString(bo.ID), nil, nil))
+		assert.Equal(t, http.StatusNotFound, sendJSON(t, router, "GET", "/bo/users/"+uintToString(ki.ID), nil, nil))
+
+		var found models.MatesResponse
+		require.Equal(t, http.StatusOK, sendJSON(t, router, "GET", "/ki/mates/search?q=黑名单&limit=50", nil, &found))
+		for _, user := range found.Mates {
+			assert.NotEqual(t, bo.ID, user.ID)
+			assert.NotEqual(t, ki.ID, user.ID, "搜索结果不包含自己")
+		}
+
+		// 双方都不能发起互动
+		assert.Equal(t, http.StatusForbidden, sendJSON(t, router, "POST", "/ki"+postPath+"/comments", comment, nil))
+		assert.Equal(t, http.StatusForbidden, sendJSON(t, router, "POST", "/ki/mates/requests", map[string]uint{"mate_id": bo.ID}, nil))
+		assert.Equal(t, http.StatusForbidden, sendJSON(t, router, "POST", "/bo/mates/requests", map[string]uint{"mate_id": ki.ID}, nil))
+		assert.Equal(t, http.StatusForbidden, sendJSON(t, router, "POST", "/ki/chats", map[string]interface{}{"participant_ids": []uint{bo.ID}}, nil))
+	})
+
+	t.Run("静音", func(t *testing.T) {
+		muteBo := "/lu/mates/" + uintToString(bo.ID) + "/mute"
+		assert.Equal(t, http.StatusBadRequest, sendJSON(t, router, "POST", "/lu/mates/"+uintToString(lu.ID)+"/mute", nil, nil))
+		assert.Equal(t, http.StatusNotFound, sendJSON(t, router, "POST", "/lu/mates/999999/mute", nil, nil))
+		require.Equal(t, http.StatusOK, sendJSON(t, router, "POST", muteBo, nil, nil))
+		require.Equal(t, http.StatusOK, sendJSON(t, router, "POST", muteBo, nil, nil), "重复静音")
+		assert.Equal(t, []uint{bo.ID}, userIDs(t, "/lu/mates/mutes"))
+
+		// 只影响静音的一方
+		assert.Equal(t, 0, feed(t, "lu"))
+		assert.Equal(t, 1, feed(t, "bo"))
+		assert.Equal(t, []uint{lu.ID}, commenters(t, "bo"))
+
+		// 仍然可以收发消息，但不再推送
+		chatPath := "/chats/" + uintToString(chat.ID) + "/messages"
+		assert.Equal(t, http.StatusCreated, sendJSON(t, router, "POST", "/bo"+chatPath, models.SendMessageRequest{ChatID: chat.ID, Content: "周六练背？"}, nil))
+		assert.Equal(t, []uint{bo.ID}, unmutedRecipients(bo.ID, []uint{bo.ID, lu.ID}))
+		assert.Equal(t, []uint{bo.ID, lu.ID}, unmutedRecipients(lu.ID, []uint{bo.ID, lu.ID}))
+
+		require.Equal(t, http.StatusOK, sendJSON(t, router, "DELETE", muteBo, nil, nil))
+		require.Equal(t, http.StatusOK, sendJSON(t, router, "DELETE", muteBo, nil, nil), "重复取消静音")
+		assert.Empty(t, userIDs(t, "/lu/mates/mutes"))
+		assert.Equal(t, 1, feed(t, "lu"))
+
+		// 拉黑后已有的单聊也不能再发消息
+		require.Equal(t, http.StatusOK, sendJSON(t, router, "POST", "/lu/mates/"+uintToString(bo.ID)+"/block", nil, nil))
+		assert.Equal(t, http.StatusForbidden, sendJSON(t, router, "POST", "/bo"+chatPath, models.SendMessageRequest{ChatID: chat.ID, Content: "在吗"}, nil))
+	})
+}
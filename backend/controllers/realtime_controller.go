@@ -0,0 +1,344 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"gymates-backend/config"
+	"gymates-backend/middleware"
+	"gymates-backend/models"
+	"gymates-backend/services/realtime"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/net/websocket"
+	"gorm.io/gorm"
+)
+
+const (
+	// realtimeReadTimeout 超过该时长没有收到客户端的帧（包括 ping）视为断线
+	realtimeReadTimeout  = 90 * time.Second
+	realtimeWriteTimeout = 10 * time.Second
+)
+
+// realtimeBroker 聊天事件的投递方式，默认为进程内 Hub
+var realtimeBroker realtime.Broker
+
+// realtimeTickets 建立连接用的一次性票据
+var realtimeTickets = realtime.NewTicketStore()
+
+func init() {
+	realtimeBroker = realtime.NewHub(broadcastPresence)
+}
+
+// SetRealtimeBroker 替换事件投递方式，例如多实例部署时使用外部 pub/sub
+func SetRealtimeBroker(broker realtime.Broker) {
+	realtimeBroker = broker
+}
+
+// RealtimeController 实时消息控制器
+type RealtimeController struct{}
+
+// NewRealtimeController 创建实时消息控制器
+func NewRealtimeController() *RealtimeController {
+	return &RealtimeController{}
+}
+
+// IssueTicket 签发建立 WebSocket 连接用的一次性票据，有效期30秒
+// POST /api/ws/ticket
+func (rc *RealtimeController) IssueTicket(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Success: false,
+			Message: "用户未认证",
+			Error:   "User not authenticated",
+			Code:    http.StatusUnauthorized,
+		})
+		return
+	}
+
+	currentUser := user.(*models.User)
+
+	ticket, expiresAt, err := realtimeTickets.Issue(currentUser.ID, time.Now())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Success: false,
+			Message: "签发连接票据失败",
+			Error:   err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "签发连接票据成功",
+		Data:    models.RealtimeTicket{Ticket: ticket, ExpiresAt: expiresAt},
+	})
+}
+
+// Connect 建立 WebSocket 连接
+// GET /api/ws?ticket=<ticket>
+//
+// 浏览器无法为 WebSocket 设置请求头，先用 POST /api/ws/ticket 换取一次性票据再通过 ticket 参数连接；
+// 能设置请求头的客户端也可以直接使用 Authorization 头。长期有效的 JWT 不接受放在 URL 中。
+// 连接建立后推送 ready 事件，之后推送新消息、正在输入、已读回执和联系人在线状态；
+// 客户端可以发送 typing/read/ping 帧。
+func (rc *RealtimeController) Connect(c *gin.Context) {
+	userID, ok := realtimeUserID(c)
+	if !ok {
+		return
+	}
+
+	var user models.User
+	if err := config.DB.First(&user, userID).Error; err != nil {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Success: false,
+			Message: "用户不存在",
+			Error:   "User not found",
+			Code:    http.StatusUnauthorized,
+		})
+		return
+	}
+
+	server := websocket.Server{
+		Handshake: checkRealtimeOrigin,
+		Handler:   func(ws *websocket.Conn) { rc.serve(ws, user.ID) },
+	}
+	server.ServeHTTP(c.Writer, c.Request)
+}
+
+// realtimeUserID 从 Authorization 头或一次性票据中取得用户，失败时已写入响应
+func realtimeUserID(c *gin.Context) (uint, bool) {
+	if tokenString := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer "); tokenString != "" {
+		claims, err := middleware.ValidateToken(tokenString)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+				Success: false,
+				Message: "无效的token",
+				Error:   err.Error(),
+				Code:    http.StatusUnauthorized,
+			})
+			return 0, false
+		}
+		return claims.UserID, true
+	}
+
+	ticket := c.Query("ticket")
+	if ticket == "" {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Success: false,
+			Message: "缺少连接票据",
+			Error:   "Ticket is required",
+			Code:    http.StatusUnauthorized,
+		})
+		return 0, false
+	}
+	userID, ok := realtimeTickets.Redeem(ticket, time.Now())
+	if !ok {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Success: false,
+			Message: "连接票据无效或已过期",
+			Error:   "Invalid or expired ticket",
+			Code:    http.StatusUnauthorized,
+		})
+		return 0, false
+	}
+	return userID, true
+}
+
+// checkRealtimeOrigin 校验握手请求的 Origin，防止跨站 WebSocket 劫持
+//
+// 浏览器总会带上 Origin，只允许同源和 WS_ALLOWED_ORIGINS 中的页面；
+// 原生客户端不带 Origin，不受限制。
+func checkRealtimeOrigin(cfg *websocket.Config, req *http.Request) error {
+	origin := req.Header.Get("Origin")
+	if origin == "" {
+		return nil
+	}
+	parsed, err := url.Parse(origin)
+	if err != nil {
+		return err
+	}
+	if parsed.Host == req.Host {
+		return nil
+	}
+	for _, allowed := range config.GetWebSocketOrigins() {
+		if allowed == "*" || strings.EqualFold(allowed, parsed.Scheme+"://"+parsed.Host) {
+			return nil
+		}
+	}
+	return fmt.Errorf("origin %s is not allowed", origin)
+}
+
+// serve 转发订阅到的事件，并处理客户端发送的帧
+func (rc *RealtimeController) serve(ws *websocket.Conn, userID uint) {
+	defer ws.Close()
+
+	sub, err := realtimeBroker.Subscribe(userID)
+	if err != nil {
+		return
+	}
+	defer sub.Close()
+
+	replies := make(chan models.RealtimeEvent, 8)
+	done := make(chan struct{})
+	defer close(done)
+
+	// 所有写操作都在这个 goroutine 中完成
+	go func() {
+		defer ws.Close()
+		for {
+			var event models.RealtimeEvent
+			var ok bool
+			select {
+			case event, ok = <-sub.Events():
+				if !ok {
+					return
+				}
+			case event = <-replies:
+			case <-done:
+				return
+			}
+			ws.SetWriteDeadline(time.Now().Add(realtimeWriteTimeout))
+			if err := websocket.JSON.Send(ws, event); err != nil {
+				return
+			}
+		}
+	}()
+
+	reply := func(event models.RealtimeEvent) {
+		select {
+		case replies <- event:
+		case <-done:
+		}
+	}
+	reply(models.NewRealtimeEvent(models.RealtimeEventReady, 0, userID, models.RealtimeReady{
+		UserID: userID,
+		Online: realtimeBroker.Online(chatContactIDs(userID)...),
+	}))
+
+	for {
+		ws.SetReadDeadline(time.Now().Add(realtimeReadTimeout))
+		var frame models.RealtimeClientFrame
+		if err := websocket.JSON.Receive(ws, &frame); err != nil {
+			return
+		}
+		if event, ok := handleRealtimeFrame(userID, frame); ok {
+			reply(event)
+		}
+	}
+}
+
+// handleRealtimeFrame 处理客户端帧，需要回复时返回回复的事件
+func handleRealtimeFrame(userID uint, frame models.RealtimeClientFrame) (models.RealtimeEvent, bool) {
+	switch frame.Type {
+	case models.RealtimeFramePing:
+		return models.NewRealtimeEvent(models.RealtimeEventPong, 0, userID, nil), true
+	case models.RealtimeFrameTyping, models.RealtimeFrameRead:
+		participants := chatParticipantIDs(frame.ChatID)
+		if !containsUint(participants, userID) {
+			return models.NewRealtimeEvent(models.RealtimeEventError, frame.ChatID, userID, gin.H{"error": "Access denied"}), true
+		}
+		if frame.Type == models.RealtimeFrameRead {
+			if _, err := markChatRead(frame.ChatID, userID, frame.MessageID, participants); err != nil {
+				return models.NewRealtimeEvent(models.RealtimeEventError, frame.ChatID, userID, gin.H{"error": err.Error()}), true
+			}
+			return models.RealtimeEvent{}, false
+		}
+		typing := frame.Typing == nil || *frame.Typing
+		others := make([]uint, 0, len(participants))
+		for _, id := range participants {
+			if id != userID {
+				others = append(others, id)
+			}
+		}
+		realtimeBroker.Publish(models.NewRealtimeEvent(models.RealtimeEventTyping, frame.ChatID, userID, models.TypingStatus{Typing: typing}), others...)
+		return models.RealtimeEvent{}, false
+	}
+	return models.NewRealtimeEvent(models.RealtimeEventError, frame.ChatID, userID, gin.H{"error": "Unknown frame type: " + frame.Type}), true
+}
+
+// markChatRead 将用户在聊天中的已读位置推进到 upTo（为0时为最新一条消息），并向参与者推送已读回执
+//
+// 已读位置只前进不后退；同时更新消息的 is_read 以兼容旧客户端。
+func markChatRead(chatID, userID, upTo uint, participants []uint) (models.ReadReceipt, error) {
+	receipt := models.ReadReceipt{ReaderID: userID, ReadAt: time.Now()}
+
+	var participant models.ChatParticipant
+	if err := config.DB.Where("chat_id = ? AND user_id = ?", chatID, userID).First(&participant).Error; err != nil {
+		return receipt, err
+	}
+	var latest uint
+	config.DB.Model(&models.Message{}).Where("chat_id = ?", chatID).Select("COALESCE(MAX(id), 0)").Scan(&latest)
+	if upTo == 0 || upTo > latest {
+		upTo = latest
+	}
+	receipt.LastReadMessageID = participant.LastReadMessageID
+	if upTo <= participant.LastReadMessageID {
+		return receipt, nil
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		newlyRead := tx.Model(&models.Message{}).
+			Where("chat_id = ? AND sender_id != ? AND id > ? AND id <= ?", chatID, userID, participant.LastReadMessageID, upTo).
+			Session(&gorm.Session{})
+		if err := newlyRead.Count(&receipt.Count).Error; err != nil {
+			return err
+		}
+		if err := newlyRead.Update("is_read", true).Error; err != nil {
+			return err
+		}
+		return tx.Model(&participant).Updates(map[string]interface{}{
+			"last_read_message_id": upTo,
+			"last_read_at":         receipt.ReadAt,
+		}).Error
+	})
+	if err != nil {
+		return receipt, err
+	}
+	receipt.LastReadMessageID = upTo
+
+	realtimeBroker.Publish(models.NewRealtimeEvent(models.RealtimeEventRead, chatID, userID, receipt), participants...)
+	return receipt, nil
+}
+
+// broadcastPresence 用户上线或下线时通知与其有共同聊天的用户
+func broadcastPresence(userID uint, online bool) {
+	if config.DB == nil {
+		return
+	}
+	realtimeBroker.Publish(models.NewRealtimeEvent(models.RealtimeEventPresence, 0, userID, models.PresenceStatus{
+		UserID: userID,
+		Online: online,
+	}), chatContactIDs(userID)...)
+}
+
+// chatParticipantIDs 聊天的参与者
+func chatParticipantIDs(chatID uint) []uint {
+	var userIDs []uint
+	config.DB.Model(&models.ChatParticipant{}).Where("chat_id = ?", chatID).Pluck("user_id", &userIDs)
+	return userIDs
+}
+
+// chatContactIDs 与用户有共同聊天的其他用户
+func chatContactIDs(userID uint) []uint {
+	var userIDs []uint
+	config.DB.Model(&models.ChatParticipant{}).
+		Where("chat_id IN (?) AND user_id != ?",
+			config.DB.Model(&models.ChatParticipant{}).Select("chat_id").Where("user_id = ?", userID), userID).
+		Distinct().Pluck("user_id", &userIDs)
+	return userIDs
+}
+
+func containsUint(values []uint, target uint) bool {
+	for _, value := range values {
+		if value == target {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,294 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"gymates-backend/config"
+	"gymates-backend/middleware"
+	"gymates-backend/models"
+	"gymates-backend/services/realtime"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/websocket"
+)
+
+// TestRealtimeHub 测试进程内 Hub 的投递、在线状态和慢连接处理
+func TestRealtimeHub(t *testing.T) {
+	type presence struct {
+		userID uint
+		online bool
+	}
+	var changes []presence
+	hub := realtime.NewHub(func(userID uint, online bool) {
+		changes = append(changes, presence{userID, online})
+	})
+
+	first, err := hub.Subscribe(1)
+	require.NoError(t, err)
+	second, err := hub.Subscribe(1)
+	require.NoError(t, err)
+	other, err := hub.Subscribe(2)
+	require.NoError(t, err)
+	assert.Equal(t, []presence{{1, true}, {2, true}}, changes)
+	assert.Equal(t, []uint{1, 2}, hub.Online(1, 2, 3))
+
+	t.Run("投递给用户的所有连接且不重复", func(t *testing.T) {
+		event := models.NewRealtimeEvent(models.RealtimeEventTyping, 7, 2, models.TypingStatus{Typing: true})
+		require.NoError(t, hub.Publish(event, 1, 1, 3))
+		for _, sub := range []realtime.Subscription{first, second} {
+			require.Len(t, sub.Events(), 1)
+			received := <-sub.Events()
+			assert.Equal(t, uint(7), received.ChatID)
+			assert.JSONEq(t, `{"typing":true}`, string(received.Data))
+		}
+		assert.Len(t, other.Events(), 0)
+	})
+
+	t.Run("最后一个连接断开时下线", func(t *testing.T) {
+		first.Close()
+		first.Close()
+		_, open := <-first.Events()
+		assert.False(t, open)
+		assert.Equal(t, []uint{1}, hub.Online(1))
+
+		second.Close()
+		assert.Empty(t, hub.Online(1))
+		assert.Equal(t, presence{1, false}, changes[len(changes)-1])
+	})
+
+	t.Run("缓冲写满的连接被断开", func(t *testing.T) {
+		for i := 0; i < 100; i++ {
+			require.NoError(t, hub.Publish(models.NewRealtimeEvent(models.RealtimeEventPong, 0, 0, nil), 2))
+		}
+		count := 0
+		for range other.Events() {
+			count++
+		}
+		assert.Equal(t, 64, count)
+		assert.Empty(t, hub.Online(2))
+		assert.Equal(t, presence{2, false}, changes[len(changes)-1])
+	})
+
+	t.Run("关闭后拒绝订阅", func(t *testing.T) {
+		sub, err := hub.Subscribe(3)
+		require.NoError(t, err)
+		hub.Close()
+		_, open := <-sub.Events()
+		assert.False(t, open)
+		_, err = hub.Subscribe(3)
+		assert.ErrorIs(t, err, realtime.ErrClosed)
+		assert.ErrorIs(t, hub.Publish(models.RealtimeEvent{}, 3), realtime.ErrClosed)
+	})
+}
+
+// TestRealtimeGateway 测试 WebSocket 推送新消息、正在输入、已读回执和在线状态
+func TestRealtimeGateway(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	setupTestDB(t)
+
+	alice := models.User{Name: "实时Alice", Email: "realtime-alice@gymates.com", Password: "x"}
+	bob := models.User{Name: "实时Bob", Email: "realtime-bob@gymates.com", Password: "x"}
+	stranger := models.User{Name: "实时路人", Email: "realtime-stranger@gymates.com", Password: "x"}
+	for _, user := range []*models.User{&alice, &bob, &stranger} {
+		require.NoError(t, config.DB.Create(user).Error)
+	}
+	chat := models.Chat{}
+	require.NoError(t, config.DB.Create(&chat).Error)
+	for _, userID := range []uint{alice.ID, bob.ID} {
+		require.NoError(t, config.DB.Create(&models.ChatParticipant{ChatID: chat.ID, UserID: userID}).Error)
+	}
+
+	router := gin.New()
+	realtimeController := NewRealtimeController()
+	router.GET("/api/ws", realtimeController.Connect)
+	for _, user := range []*models.User{&alice, &bob, &stranger} {
+		router.POST("/"+uintToString(user.ID)+"/ws/ticket", withTestUser(user), realtimeController.IssueTicket)
+	}
+	messages := NewMessagesController()
+	router.POST("/alice/chats/:id/messages", withTestUser(&alice), messages.SendMessage)
+	router.PUT("/bob/chats/:id/read", withTestUser(&bob), messages.MarkAsRead)
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	wsURL := "ws" + strings.TrimPrefix(server.URL, "http") + "/api/ws"
+	ticket := func(user *models.User) string {
+		req, _ := http.NewRequest("POST", "/"+uintToString(user.ID)+"/ws/ticket", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+		var response struct {
+			Data models.RealtimeTicket `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		require.NotEmpty(t, response.Data.Ticket)
+		return response.Data.Ticket
+	}
+	connect := func(user *models.User) *websocket.Conn {
+		ws, err := websocket.Dial(wsURL+"?ticket="+ticket(user), "", server.URL)
+		require.NoError(t, err)
+		return ws
+	}
+	// next 读取下一个指定类型的事件，跳过其他事件
+	next := func(t *testing.T, ws *websocket.Conn, eventType string) models.RealtimeEvent {
+		t.Helper()
+		ws.SetReadDeadline(time.Now().Add(2 * time.Second))
+		for {
+			var event models.RealtimeEvent
+			require.NoError(t, websocket.JSON.Receive(ws, &event), "waiting for %s", eventType)
+			if event.Type == eventType {
+				return event
+			}
+		}
+	}
+	data := func(t *testing.T, event models.RealtimeEvent, target interface{}) {
+		t.Helper()
+		require.NoError(t, json.Unmarshal(event.Data, target))
+	}
+
+	t.Run("缺少或无效的token", func(t *testing.T) {
+		resp, err := http.Get(server.URL + "/api/ws")
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+		_, err = websocket.Dial(wsURL+"?ticket=invalid", "", server.URL)
+		assert.Error(t, err)
+
+		// 长期有效的 JWT 不能放在 URL 中
+		token, err := middleware.GenerateToken(&stranger)
+		require.NoError(t, err)
+		_, err = websocket.Dial(wsURL+"?token="+token, "", server.URL)
+		assert.Error(t, err)
+
+		// 票据只能使用一次
+		once := ticket(&stranger)
+		ws, err := websocket.Dial(wsURL+"?ticket="+once, "", server.URL)
+		require.NoError(t, err)
+		ws.Close()
+		_, err = websocket.Dial(wsURL+"?ticket="+once, "", server.URL)
+		assert.Error(t, err)
+	})
+
+	t.Run("校验Origin", func(t *testing.T) {
+		dial := func(t *testing.T, origin string) error {
+			ws, err := websocket.Dial(wsURL+"?ticket="+ticket(&stranger), "", origin)
+			if err == nil {
+				ws.Close()
+			}
+			return err
+		}
+
+		assert.Error(t, dial(t, "https://evil.example.com"))
+		t.Setenv("WS_ALLOWED_ORIGINS", "https://app.gymates.com, https://admin.gymates.com/")
+		assert.NoError(t, dial(t, "https://app.gymates.com"))
+		assert.NoError(t, dial(t, "https://admin.gymates.com"))
+		assert.Error(t, dial(t, "https://evil.example.com"))
+
+		// 能设置请求头的客户端可以直接使用 Authorization 头
+		token, err := middleware.GenerateToken(&stranger)
+		require.NoError(t, err)
+		cfg, err := websocket.NewConfig(wsURL, server.URL)
+		require.NoError(t, err)
+		cfg.Header = http.Header{"Authorization": {"Bearer " + token}}
+		ws, err := websocket.DialConfig(cfg)
+		require.NoError(t, err)
+		ws.Close()
+
+		// 原生客户端不带 Origin
+		req, _ := http.NewRequest("GET", wsURL, nil)
+		assert.NoError(t, checkRealtimeOrigin(nil, req))
+	})
+
+	bobWS := connect(&bob)
+	defer bobWS.Close()
+	var ready models.RealtimeReady
+	data(t, next(t, bobWS, models.RealtimeEventReady), &ready)
+	assert.Equal(t, bob.ID, ready.UserID)
+	assert.Empty(t, ready.Online)
+
+	aliceWS := connect(&alice)
+	t.Run("上线通知和在线联系人", func(t *testing.T) {
+		var status models.PresenceStatus
+		data(t, next(t, bobWS, models.RealtimeEventPresence), &status)
+		assert.Equal(t, models.PresenceStatus{UserID: alice.ID, Online: true}, status)
+
+		data(t, next(t, aliceWS, models.RealtimeEventReady), &ready)
+		assert.Equal(t, []uint{bob.ID}, ready.Online)
+	})
+
+	t.Run("正在输入", func(t *testing.T) {
+		require.NoError(t, websocket.JSON.Send(aliceWS, models.RealtimeClientFrame{Type: models.RealtimeFrameTyping, ChatID: chat.ID}))
+		event := next(t, bobWS, models.RealtimeEventTyping)
+		assert.Equal(t, chat.ID, event.ChatID)
+		assert.Equal(t, alice.ID, event.UserID)
+		var typing models.TypingStatus
+		data(t, event, &typing)
+		assert.True(t, typing.Typing)
+
+		stopped := false
+		require.NoError(t, websocket.JSON.Send(aliceWS, models.RealtimeClientFrame{Type: models.RealtimeFrameTyping, ChatID: chat.ID, Typing: &stopped}))
+		data(t, next(t, bobWS, models.RealtimeEventTyping), &typing)
+		assert.False(t, typing.Typing)
+	})
+
+	t.Run("新消息推送给所有参与者", func(t *testing.T) {
+		body, _ := json.Marshal(models.SendMessageRequest{ChatID: chat.ID, Content: "今晚练腿吗", Type: "text"})
+		req, _ := http.NewRequest("POST", "/alice/chats/"+uintToString(chat.ID)+"/messages", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusCreated, w.Code)
+
+		for _, ws := range []*websocket.Conn{bobWS, aliceWS} {
+			var message models.Message
+			data(t, next(t, ws, models.RealtimeEventMessage), &message)
+			assert.Equal(t, "今晚练腿吗", message.Content)
+			assert.Equal(t, alice.ID, message.SenderID)
+			assert.Equal(t, alice.Name, message.Sender.Name)
+		}
+	})
+
+	t.Run("已读回执", func(t *testing.T) {
+		require.NoError(t, websocket.JSON.Send(bobWS, models.RealtimeClientFrame{Type: models.RealtimeFrameRead, ChatID: chat.ID}))
+		var receipt models.ReadReceipt
+		data(t, next(t, aliceWS, models.RealtimeEventRead), &receipt)
+		assert.Equal(t, bob.ID, receipt.ReaderID)
+		assert.Equal(t, int64(1), receipt.Count)
+
+		// 没有新的未读消息时不推送回执，REST 接口同样适用
+		req, _ := http.NewRequest("PUT", "/bob/chats/"+uintToString(chat.ID)+"/read", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+		var unread int64
+		config.DB.Model(&models.Message{}).Where("chat_id = ? AND is_read = ?", chat.ID, false).Count(&unread)
+		assert.Zero(t, unread)
+	})
+
+	t.Run("ping和无效的帧", func(t *testing.T) {
+		require.NoError(t, websocket.JSON.Send(bobWS, models.RealtimeClientFrame{Type: models.RealtimeFramePing}))
+		next(t, bobWS, models.RealtimeEventPong)
+
+		strangerWS := connect(&stranger)
+		defer strangerWS.Close()
+		require.NoError(t, websocket.JSON.Send(strangerWS, models.RealtimeClientFrame{Type: models.RealtimeFrameTyping, ChatID: chat.ID}))
+		assert.Equal(t, chat.ID, next(t, strangerWS, models.RealtimeEventError).ChatID)
+		require.NoError(t, websocket.JSON.Send(strangerWS, models.RealtimeClientFrame{Type: "dance"}))
+		next(t, strangerWS, models.RealtimeEventError)
+	})
+
+	t.Run("下线通知", func(t *testing.T) {
+		aliceWS.Close()
+		var status models.PresenceStatus
+		data(t, next(t, bobWS, models.RealtimeEventPresence), &status)
+		assert.Equal(t, models.PresenceStatus{UserID: alice.ID, Online: false}, status)
+	})
+}
@@ -0,0 +1,78 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"gymates-backend/config"
+	"gymates-backend/models"
+	"gymates-backend/services/recommendation"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRecommendationSeed 测试推荐接口按种子复现、查询不依赖数据库专有的随机函数
+func TestRecommendationSeed(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	setupTestDB(t)
+
+	t.Run("查询与数据库方言无关", func(t *testing.T) {
+		sql := recommendation.NewGormStore(config.DB).ExerciseQuerySQL(recommendation.ExerciseFilter{
+			Parts: []string{"chest"}, Levels: recommendation.LevelsFor("初级"), Equipment: []string{models.EquipmentDumbbell},
+		})
+		assert.NotContains(t, strings.ToUpper(sql), "RANDOM")
+		assert.NotContains(t, strings.ToUpper(sql), "RAND(")
+		assert.Contains(t, sql, "ORDER BY id")
+	})
+
+	router := gin.New()
+	router.GET("/api/training/ai/recommend", NewAIRecommendationController().GetAIRecommendation)
+	router.GET("/api/training/ai/training", NewAITrainingController().GetAIRecommendation)
+
+	get := func(path string) (int, map[string]interface{}) {
+		req, _ := http.NewRequest("GET", path, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		var response struct {
+			Data map[string]interface{} `json:"data"`
+		}
+		json.Unmarshal(w.Body.Bytes(), &response)
+		return w.Code, response.Data
+	}
+
+	tests := []struct {
+		name string
+		path string
+		key  string
+	}{
+		{name: "分化训练日推荐", path: "/api/training/ai/recommend?user_id=2&day=Monday", key: "parts"},
+		{name: "训练偏好推荐", path: "/api/training/ai/training?user_id=2", key: "exercises"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, first := get(tt.path + "&seed=20240601")
+			require.Equal(t, http.StatusOK, code)
+			assert.EqualValues(t, 20240601, first["seed"])
+			require.NotEmpty(t, first[tt.key])
+
+			_, second := get(tt.path + "&seed=20240601")
+			assert.Equal(t, first[tt.key], second[tt.key])
+
+			// 未传种子时生成新种子并随结果返回，传回即可复现
+			_, random := get(tt.path)
+			seed := int64(random["seed"].(float64))
+			require.NotZero(t, seed)
+			_, replay := get(tt.path + "&seed=" + strconv.FormatInt(seed, 10))
+			assert.Equal(t, random[tt.key], replay[tt.key])
+
+			code, _ = get(tt.path + "&seed=abc")
+			assert.Equal(t, http.StatusBadRequest, code)
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"gymates-backend/config"
+
+	"github.com/stretchr/testify/require"
+)
+
+// setupTestDB 初始化测试数据库（共享内存SQLite，包含模拟数据）
+// test_helpers.go 中的 TestMain 不在 _test 文件里，不会被 go test 执行，这里按需初始化
+func setupTestDB(t *testing.T) {
+	t.Helper()
+	if config.DB != nil {
+		return
+	}
+
+	os.Setenv("DB_TYPE", "sqlite")
+	os.Setenv("DB_PATH", "file::memory:?cache=shared")
+	os.Setenv("GIN_MODE", "test")
+	os.Setenv("JWT_SECRET", "test-secret-key")
+
+	require.NoError(t, config.InitDB())
+}
+
+// sendJSON 以JSON请求体调用路由并返回状态码，data 不为空时把响应中的 data 字段解析到 data
+func sendJSON(t *testing.T, router http.Handler, method, path string, payload interface{}, data interface{}) int {
+	t.Helper()
+	var body []byte
+	if payload != nil {
+		body, _ = json.Marshal(payload)
+	}
+	req, _ := http.NewRequest(method, path, bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	decodeData(t, w, data)
+	return w.Code
+}
+
+// decodeData 把响应中的 data 字段解析到 data，data 为空时忽略
+func decodeData(t *testing.T, w *httptest.ResponseRecorder, data interface{}) {
+	t.Helper()
+	if data != nil {
+		response := struct {
+			Data interface{} `json:"data"`
+		}{Data: data}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	}
+}
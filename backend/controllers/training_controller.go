@@ -1,12 +1,15 @@
 package controllers
 
 import (
+	"fmt"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"gymates-backend/config"
 	"gymates-backend/models"
+	"gymates-backend/services"
 )
 
 // TrainingController 训练控制器
@@ -135,7 +138,6 @@ func (tc *TrainingController) CreateTrainingPlan(c *gin.Context) {
 		Name:           req.Name,
 		Description:    req.Description,
 		Duration:       req.Duration,
-		CaloriesBurned: req.CaloriesBurned,
 		Difficulty:     req.Difficulty,
 		IsPublic:       req.IsPublic,
 	}
@@ -150,7 +152,20 @@ func (tc *TrainingController) CreateTrainingPlan(c *gin.Context) {
 		return
 	}
 
-	// 创建训练动作
+	// 创建训练动作，能匹配到的关联标准动作库
+	library, err := newExerciseLibraryResolver(config.DB)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Success: false,
+			Message: "加载动作库失败",
+			Error:   err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+	// 消耗热量按动作 MET 和创建者体重估算，不使用客户端传入的数值
+	bodyWeight := userBodyWeight(config.DB, currentUser.ID)
+	var energySets []services.EnergySet
 	for i, exerciseReq := range req.Exercises {
 		exercise := models.Exercise{
 			TrainingPlanID: plan.ID,
@@ -164,6 +179,10 @@ func (tc *TrainingController) CreateTrainingPlan(c *gin.Context) {
 			ImageURL:       exerciseReq.ImageURL,
 			Order:          i + 1,
 		}
+		library.apply(&exercise, exerciseReq.ExerciseLibraryID)
+		sets := plannedEnergySets(exercise, libraryMET(library.lookup(exercise.ExerciseLibraryID)))
+		exercise.Calories = services.EstimateSessionEnergy(services.SessionEnergyInput{BodyWeight: bodyWeight, Sets: sets}).Calories
+		energySets = append(energySets, sets...)
 		if err := config.DB.Create(&exercise).Error; err != nil {
 			c.JSON(http.StatusInternalServerError, models.ErrorResponse{
 				Success: false,
@@ -175,6 +194,13 @@ func (tc *TrainingController) CreateTrainingPlan(c *gin.Context) {
 		}
 	}
 
+	estimate := services.EstimateSessionEnergy(services.SessionEnergyInput{
+		BodyWeight: bodyWeight,
+		Minutes:    float64(plan.Duration),
+		Sets:       energySets,
+	})
+	config.DB.Model(&plan).Update("calories_burned", estimate.Calories)
+
 	// 重新加载数据
 	config.DB.Preload("User").Preload("Exercises").First(&plan, plan.ID)
 
@@ -227,8 +253,10 @@ func (tc *TrainingController) StartWorkoutSession(c *gin.Context) {
 	session := models.WorkoutSession{
 		UserID:         currentUser.ID,
 		TrainingPlanID: plan.ID,
+		StartTime:      time.Now(),
 		Status:         "ongoing",
 		Progress:       0,
+		CaloriesSource: models.CaloriesSourceEstimated,
 	}
 
 	if err := config.DB.Create(&session).Error; err != nil {
@@ -308,10 +336,21 @@ func (tc *TrainingController) UpdateWorkoutProgress(c *gin.Context) {
 	})
 }
 
-// CompleteWorkoutSession 完成训练会话
-func (tc *TrainingController) CompleteWorkoutSession(c *gin.Context) {
-	sessionIDStr := c.Param("id")
-	sessionID, err := strconv.ParseUint(sessionIDStr, 10, 32)
+// LogWorkoutSet 记录训练会话中完成的一组（支持动作组的轮次）
+// POST /api/training/sessions/:id/sets
+func (tc *TrainingController) LogWorkoutSet(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Success: false,
+			Message: "用户未认证",
+			Error:   "User not authenticated",
+			Code:    http.StatusUnauthorized,
+		})
+		return
+	}
+
+	sessionID, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, models.ErrorResponse{
 			Success: false,
@@ -322,8 +361,22 @@ func (tc *TrainingController) CompleteWorkoutSession(c *gin.Context) {
 		return
 	}
 
+	var req models.LogWorkoutSetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Message: "请求参数错误",
+			Error:   err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	currentUser := user.(*models.User)
+
 	var session models.WorkoutSession
-	if err := config.DB.First(&session, uint(sessionID)).Error; err != nil {
+	if err := config.DB.Where("id = ? AND user_id = ?", uint(sessionID), currentUser.ID).
+		First(&session).Error; err != nil {
 		c.JSON(http.StatusNotFound, models.ErrorResponse{
 			Success: false,
 			Message: "训练会话不存在",
@@ -333,11 +386,180 @@ func (tc *TrainingController) CompleteWorkoutSession(c *gin.Context) {
 		return
 	}
 
+	if session.Status != "ongoing" {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Message: "训练会话已结束",
+			Error:   "Workout session is not ongoing",
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	// 只能记录本次训练计划里的动作，或自己的一周训练计划里的动作
+	var exercise models.Exercise
+	ownWeeklyPlans := config.DB.Model(&models.WeeklyTrainingPlan{}).Select("id").Where("user_id = ?", currentUser.ID)
+	if err := config.DB.Where("id = ?", req.ExerciseID).
+		Where(config.DB.Where("training_part_id IS NULL AND training_plan_id = ?", session.TrainingPlanID).
+			Or("training_part_id IS NOT NULL AND training_plan_id IN (?)", ownWeeklyPlans)).
+		First(&exercise).Error; err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Success: false,
+			Message: "训练动作不存在",
+			Error:   "Exercise not found",
+			Code:    http.StatusNotFound,
+		})
+		return
+	}
+
+	setLog := models.WorkoutSetLog{
+		WorkoutSessionID: session.ID,
+		UserID:           currentUser.ID,
+		ExerciseID:       exercise.ID,
+		ExerciseGroupID:  exercise.ExerciseGroupID,
+		SetNumber:        req.SetNumber,
+		Reps:             req.Reps,
+		Weight:           req.Weight,
+		DurationSeconds:  req.DurationSeconds,
+		CompletedAt:      time.Now(),
+	}
+
+	if exercise.ExerciseGroupID != nil {
+		// 动作组内的记录按轮次计，AMRAP 不限制轮数
+		var group models.ExerciseGroup
+		if err := config.DB.First(&group, *exercise.ExerciseGroupID).Error; err != nil {
+			c.JSON(http.StatusNotFound, models.ErrorResponse{
+				Success: false,
+				Message: "动作组不存在",
+				Error:   "Exercise group not found",
+				Code:    http.StatusNotFound,
+			})
+			return
+		}
+		if req.Round < 1 || (group.BlockType != models.BlockTypeAMRAP && req.Round > group.Rounds) {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Success: false,
+				Message: "无效的轮次",
+				Error:   fmt.Sprintf("round must be between 1 and %d", group.Rounds),
+				Code:    http.StatusBadRequest,
+			})
+			return
+		}
+		setLog.Round = req.Round
+		if setLog.SetNumber == 0 {
+			setLog.SetNumber = req.Round
+		}
+	} else if req.SetNumber < 1 {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Message: "请指定组号",
+			Error:   "set_number is required",
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	if err := config.DB.Create(&setLog).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Success: false,
+			Message: "记录训练组失败",
+			Error:   err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	// 每记录一组更新一次会话的估算消耗
+	if _, err := updateSessionCalories(config.DB, &session); err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Success: false,
+			Message: "更新训练消耗失败",
+			Error:   err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.SuccessResponse{
+		Success: true,
+		Message: "记录训练组成功",
+		Data:    setLog,
+	})
+}
+
+// GetWorkoutSets 获取训练会话的所有组记录
+// GET /api/training/sessions/:id/sets
+func (tc *TrainingController) GetWorkoutSets(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Success: false,
+			Message: "用户未认证",
+			Error:   "User not authenticated",
+			Code:    http.StatusUnauthorized,
+		})
+		return
+	}
+
+	sessionID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Message: "无效的训练会话ID",
+			Error:   err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	currentUser := user.(*models.User)
+
+	var setLogs []models.WorkoutSetLog
+	if err := config.DB.Where("workout_session_id = ? AND user_id = ?", uint(sessionID), currentUser.ID).
+		Order("completed_at ASC").Find(&setLogs).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Success: false,
+			Message: "获取训练组记录失败",
+			Error:   err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "获取训练组记录成功",
+		Data:    setLogs,
+	})
+}
+
+// CompleteWorkoutSession 完成训练会话，可选提交穿戴设备记录的消耗
+func (tc *TrainingController) CompleteWorkoutSession(c *gin.Context) {
+	session, ok := userWorkoutSession(c)
+	if !ok {
+		return
+	}
+
+	// 请求体可省略
+	var req models.SessionCaloriesRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Success: false,
+				Message: "请求参数错误",
+				Error:   err.Error(),
+				Code:    http.StatusBadRequest,
+			})
+			return
+		}
+	}
+
 	// 更新会话状态
+	endTime := time.Now()
 	updates := map[string]interface{}{
 		"status":     "completed",
 		"progress":   100,
-		"end_time":   "NOW()",
+		"end_time":   endTime,
 	}
 
 	if err := config.DB.Model(&session).Updates(updates).Error; err != nil {
@@ -350,6 +572,22 @@ func (tc *TrainingController) CompleteWorkoutSession(c *gin.Context) {
 		return
 	}
 
+	// 按实际训练时长估算消耗
+	session.EndTime = &endTime
+	if req.Calories != nil {
+		session.TotalCalories = *req.Calories
+		session.CaloriesSource = models.CaloriesSourceWearable
+	}
+	if _, err := updateSessionCalories(config.DB, &session); err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Success: false,
+			Message: "更新训练消耗失败",
+			Error:   err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
 	// 重新加载数据
 	config.DB.Preload("User").Preload("TrainingPlan").First(&session, session.ID)
 
@@ -360,47 +598,106 @@ func (tc *TrainingController) CompleteWorkoutSession(c *gin.Context) {
 	})
 }
 
-// SearchExercises 搜索训练动作
-func (tc *TrainingController) SearchExercises(c *gin.Context) {
-	query := c.Query("q")
-	muscleGroup := c.Query("muscle_group")
-	difficulty := c.Query("difficulty")
-	equipment := c.Query("equipment")
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+// GetSessionCalories 获取训练会话的消耗估算明细
+// GET /api/training/sessions/:id/calories
+func (tc *TrainingController) GetSessionCalories(c *gin.Context) {
+	session, ok := userWorkoutSession(c)
+	if !ok {
+		return
+	}
 
-	var exercises []models.Exercise
-	var total int64
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "获取训练消耗成功",
+		Data:    sessionEnergy(config.DB, session),
+	})
+}
 
-	// 构建查询条件
-	dbQuery := config.DB.Model(&models.Exercise{})
+// SetSessionCalories 用穿戴设备记录的消耗覆盖估算值，calories 为空时恢复估算值
+// PUT /api/training/sessions/:id/calories
+func (tc *TrainingController) SetSessionCalories(c *gin.Context) {
+	var req models.SessionCaloriesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Message: "请求参数错误",
+			Error:   err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
 
-	// 如果有搜索关键词，进行模糊搜索
-	if query != "" {
-		dbQuery = dbQuery.Where("name LIKE ? OR instructions LIKE ?", "%"+query+"%", "%"+query+"%")
+	session, ok := userWorkoutSession(c)
+	if !ok {
+		return
 	}
 
-	// 如果指定了肌群
-	if muscleGroup != "" {
-		dbQuery = dbQuery.Where("muscle_group = ?", muscleGroup)
+	session.CaloriesSource = models.CaloriesSourceEstimated
+	if req.Calories != nil {
+		session.TotalCalories = *req.Calories
+		session.CaloriesSource = models.CaloriesSourceWearable
+	}
+	estimate, err := updateSessionCalories(config.DB, &session)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Success: false,
+			Message: "更新训练消耗失败",
+			Error:   err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
 	}
 
-	// 如果指定了难度
-	if difficulty != "" {
-		dbQuery = dbQuery.Where("difficulty = ?", difficulty)
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "更新训练消耗成功",
+		Data:    estimate,
+	})
+}
+
+// userWorkoutSession 获取路径中当前用户的训练会话，失败时已写入响应
+func userWorkoutSession(c *gin.Context) (models.WorkoutSession, bool) {
+	var session models.WorkoutSession
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Success: false,
+			Message: "用户未认证",
+			Error:   "User not authenticated",
+			Code:    http.StatusUnauthorized,
+		})
+		return session, false
 	}
+	currentUser := user.(*models.User)
 
-	// 如果指定了器械
-	if equipment != "" {
-		dbQuery = dbQuery.Where("equipment = ?", equipment)
+	sessionID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Message: "无效的训练会话ID",
+			Error:   err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return session, false
 	}
 
-	// 获取总数
-	dbQuery.Count(&total)
+	if err := config.DB.Where("id = ? AND user_id = ?", uint(sessionID), currentUser.ID).First(&session).Error; err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Success: false,
+			Message: "训练会话不存在",
+			Error:   "Workout session not found",
+			Code:    http.StatusNotFound,
+		})
+		return session, false
+	}
+	return session, true
+}
 
-	// 分页查询
-	offset := (page - 1) * limit
-	if err := dbQuery.Offset(offset).Limit(limit).Order("name ASC").Find(&exercises).Error; err != nil {
+// SearchExercises 搜索标准动作库
+// GET /api/training/exercises/search?q=&muscle_group=&muscle=&pattern=&difficulty=&equipment=
+func (tc *TrainingController) SearchExercises(c *gin.Context) {
+	result, err := queryExerciseLibrary(c, 20)
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
 			Success: false,
 			Message: "搜索训练动作失败",
@@ -410,51 +707,18 @@ func (tc *TrainingController) SearchExercises(c *gin.Context) {
 		return
 	}
 
-	pagination := models.Pagination{
-		Page:       page,
-		Limit:      limit,
-		Total:      total,
-		TotalPages: int((total + int64(limit) - 1) / int64(limit)),
-		HasMore:    int64(page*limit) < total,
-	}
-
 	c.JSON(http.StatusOK, models.SuccessResponse{
 		Success: true,
 		Message: "搜索训练动作成功",
-		Data: models.ExercisesResponse{
-			Exercises:  exercises,
-			Pagination: pagination,
-		},
+		Data:    result,
 	})
 }
 
-// GetAllExercises 获取所有训练动作
+// GetAllExercises 获取标准动作库
+// GET /api/training/exercises?muscle_group=&difficulty=
 func (tc *TrainingController) GetAllExercises(c *gin.Context) {
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
-	muscleGroup := c.Query("muscle_group")
-	difficulty := c.Query("difficulty")
-
-	var exercises []models.Exercise
-	var total int64
-
-	// 构建查询条件
-	dbQuery := config.DB.Model(&models.Exercise{})
-
-	if muscleGroup != "" {
-		dbQuery = dbQuery.Where("muscle_group = ?", muscleGroup)
-	}
-
-	if difficulty != "" {
-		dbQuery = dbQuery.Where("difficulty = ?", difficulty)
-	}
-
-	// 获取总数
-	dbQuery.Count(&total)
-
-	// 分页查询
-	offset := (page - 1) * limit
-	if err := dbQuery.Offset(offset).Limit(limit).Order("name ASC").Find(&exercises).Error; err != nil {
+	result, err := queryExerciseLibrary(c, 50)
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
 			Success: false,
 			Message: "获取训练动作失败",
@@ -464,21 +728,10 @@ func (tc *TrainingController) GetAllExercises(c *gin.Context) {
 		return
 	}
 
-	pagination := models.Pagination{
-		Page:       page,
-		Limit:      limit,
-		Total:      total,
-		TotalPages: int((total + int64(limit) - 1) / int64(limit)),
-		HasMore:    int64(page*limit) < total,
-	}
-
 	c.JSON(http.StatusOK, models.SuccessResponse{
 		Success: true,
 		Message: "获取训练动作成功",
-		Data: models.ExercisesResponse{
-			Exercises:  exercises,
-			Pagination: pagination,
-		},
+		Data:    result,
 	})
 }
 
@@ -497,20 +750,39 @@ func (tc *TrainingController) GetWorkoutHistory(c *gin.Context) {
 
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 10
+	}
 
 	currentUser := user.(*models.User)
 
 	var sessions []models.WorkoutSession
-	var total int64
+	var activities []models.CardioActivity
+	var sessionTotal, cardioTotal int64
 
 	// 获取总数
-	config.DB.Model(&models.WorkoutSession{}).Where("user_id = ?", currentUser.ID).Count(&total)
+	config.DB.Model(&models.WorkoutSession{}).Where("user_id = ?", currentUser.ID).Count(&sessionTotal)
+	config.DB.Model(&models.CardioActivity{}).Where("user_id = ?", currentUser.ID).Count(&cardioTotal)
+	total := sessionTotal + cardioTotal
 
-	// 分页查询
+	// 两类记录各取前 offset+limit 条，合并后再截取当前页
 	offset := (page - 1) * limit
 	if err := config.DB.Where("user_id = ?", currentUser.ID).
 		Preload("User").Preload("TrainingPlan").
-		Offset(offset).Limit(limit).Order("created_at DESC").Find(&sessions).Error; err != nil {
+		Limit(offset + limit).Order("created_at DESC").Find(&sessions).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Success: false,
+			Message: "获取训练历史失败",
+			Error:   err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+	if err := config.DB.Where("user_id = ?", currentUser.ID).
+		Limit(offset + limit).Order("start_time DESC").Find(&activities).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
 			Success: false,
 			Message: "获取训练历史失败",
@@ -520,6 +792,19 @@ func (tc *TrainingController) GetWorkoutHistory(c *gin.Context) {
 		return
 	}
 
+	items := mergeWorkoutHistory(sessions, activities)
+	if offset < len(items) {
+		items = items[offset:min(offset+limit, len(items))]
+	} else {
+		items = []models.WorkoutHistoryItem{}
+	}
+	sessions = []models.WorkoutSession{}
+	for _, item := range items {
+		if item.Session != nil {
+			sessions = append(sessions, *item.Session)
+		}
+	}
+
 	pagination := models.Pagination{
 		Page:       page,
 		Limit:      limit,
@@ -533,6 +818,7 @@ func (tc *TrainingController) GetWorkoutHistory(c *gin.Context) {
 		Message: "获取训练历史成功",
 		Data: models.WorkoutSessionsResponse{
 			Sessions:   sessions,
+			Items:      items,
 			Pagination: pagination,
 		},
 	})
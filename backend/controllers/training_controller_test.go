@@ -6,11 +6,14 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
+	"gymates-backend/config"
 	"gymates-backend/models"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // TestGetTrainingPlans 测试获取训练计划列表
@@ -317,31 +320,45 @@ func TestUpdateWorkoutProgress(t *testing.T) {
 // TestCompleteWorkoutSession 测试完成训练会话
 func TestCompleteWorkoutSession(t *testing.T) {
 	gin.SetMode(gin.TestMode)
+	setupTestDB(t)
+
+	user := models.User{Name: "完成训练测试", Email: "complete-session@gymates.com", Password: "x"}
+	require.NoError(t, config.DB.Create(&user).Error)
+	other := models.User{Name: "完成训练其他用户", Email: "complete-session-other@gymates.com", Password: "x"}
+	require.NoError(t, config.DB.Create(&other).Error)
+	session := models.WorkoutSession{UserID: user.ID, StartTime: time.Now().Add(-30 * time.Minute)}
+	require.NoError(t, config.DB.Create(&session).Error)
 
 	router := gin.Default()
 	trainingController := NewTrainingController()
-	router.POST("/api/training/sessions/:id/complete", trainingController.CompleteWorkoutSession)
+	router.POST("/api/training/sessions/:id/complete", withTestUser(&user), trainingController.CompleteWorkoutSession)
+	router.POST("/other/training/sessions/:id/complete", withTestUser(&other), trainingController.CompleteWorkoutSession)
 
 	tests := []struct {
 		name           string
-		sessionID      string
+		path           string
 		expectedStatus int
 	}{
+		{
+			name:           "其他用户的会话",
+			path:           "/other/training/sessions/" + uintToString(session.ID) + "/complete",
+			expectedStatus: http.StatusNotFound,
+		},
 		{
 			name:           "正常完成训练",
-			sessionID:      "1",
+			path:           "/api/training/sessions/" + uintToString(session.ID) + "/complete",
 			expectedStatus: http.StatusOK,
 		},
 		{
 			name:           "无效会话ID",
-			sessionID:      "invalid",
+			path:           "/api/training/sessions/invalid/complete",
 			expectedStatus: http.StatusBadRequest,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			req, err := http.NewRequest("POST", "/api/training/sessions/"+tt.sessionID+"/complete", nil)
+			req, err := http.NewRequest("POST", tt.path, nil)
 			assert.NoError(t, err)
 
 			w := httptest.NewRecorder()
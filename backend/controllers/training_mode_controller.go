@@ -0,0 +1,368 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"gymates-backend/config"
+	"gymates-backend/models"
+	"gymates-backend/services"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// TrainingModeController 训练模式与分化控制器
+type TrainingModeController struct{}
+
+// NewTrainingModeController 创建训练模式控制器
+func NewTrainingModeController() *TrainingModeController {
+	return &TrainingModeController{}
+}
+
+// GetSplits 获取内置分化定义
+// GET /api/training/splits
+func (tmc *TrainingModeController) GetSplits(c *gin.Context) {
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "获取分化定义成功",
+		Data:    models.SplitDefinitions,
+	})
+}
+
+// GetTrainingMode 获取当前训练模式及一周安排，未设置时返回默认的推拉腿
+// GET /api/training/mode
+func (tmc *TrainingModeController) GetTrainingMode(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Success: false,
+			Message: "用户未认证",
+			Error:   "User not authenticated",
+			Code:    http.StatusUnauthorized,
+		})
+		return
+	}
+	currentUser := user.(*models.User)
+
+	mode := userTrainingMode(config.DB, currentUser.ID)
+	split, schedule := resolveTrainingSchedule(config.DB, mode)
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "获取训练模式成功",
+		Data:    models.TrainingModeResponse{Mode: mode, Split: split, Schedule: schedule},
+	})
+}
+
+// SaveTrainingMode 设置训练模式
+// PUT /api/training/mode
+func (tmc *TrainingModeController) SaveTrainingMode(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Success: false,
+			Message: "用户未认证",
+			Error:   "User not authenticated",
+			Code:    http.StatusUnauthorized,
+		})
+		return
+	}
+
+	var req models.SaveTrainingModeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Message: "请求参数错误",
+			Error:   err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+	currentUser := user.(*models.User)
+
+	split, err := validateTrainingMode(config.DB, currentUser.ID, req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Message: "训练模式无效",
+			Error:   err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	var mode models.TrainingMode
+	if err := config.DB.Where("user_id = ? AND is_active = ?", currentUser.ID, true).First(&mode).Error; err != nil {
+		mode = models.TrainingMode{UserID: currentUser.ID, IsActive: true}
+	}
+	mode.Mode = split.Key
+	mode.TrainDays = req.TrainDays
+	mode.SplitTemplateID = nil
+	if split.Key == models.SplitCustom {
+		mode.SplitTemplateID = req.SplitTemplateID
+	}
+	mode.Target = req.Target
+	if mode.Target == "" {
+		mode.Target = "增肌"
+	}
+	mode.Level = req.Level
+	if mode.Level == "" {
+		mode.Level = "中级"
+	}
+
+	if err := config.DB.Save(&mode).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Success: false,
+			Message: "保存训练模式失败",
+			Error:   err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	split, schedule := resolveTrainingSchedule(config.DB, mode)
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "保存训练模式成功",
+		Data:    models.TrainingModeResponse{Mode: mode, Split: split, Schedule: schedule},
+	})
+}
+
+// GetSplitTemplates 获取当前用户的自定义分化模板
+// GET /api/training/split-templates
+func (tmc *TrainingModeController) GetSplitTemplates(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Success: false,
+			Message: "用户未认证",
+			Error:   "User not authenticated",
+			Code:    http.StatusUnauthorized,
+		})
+		return
+	}
+	currentUser := user.(*models.User)
+
+	var templates []models.SplitTemplate
+	if err := config.DB.Where("user_id = ?", currentUser.ID).Order("created_at DESC").Find(&templates).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Success: false,
+			Message: "获取分化模板失败",
+			Error:   err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "获取分化模板成功",
+		Data:    templates,
+	})
+}
+
+// CreateSplitTemplate 创建自定义分化模板
+// POST /api/training/split-templates
+func (tmc *TrainingModeController) CreateSplitTemplate(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Success: false,
+			Message: "用户未认证",
+			Error:   "User not authenticated",
+			Code:    http.StatusUnauthorized,
+		})
+		return
+	}
+
+	var req models.CreateSplitTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Message: "请求参数错误",
+			Error:   err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	// binding 不会校验切片内的元素，逐个检查训练课
+	var err error
+	for i, session := range req.Sessions {
+		if session.Name == "" || len(session.Parts) == 0 {
+			err = fmt.Errorf("sessions[%d]: name and parts are required", i)
+			break
+		}
+		for _, part := range session.Parts {
+			if !models.IsTrainingPart(part) {
+				err = fmt.Errorf("sessions[%d]: unknown part %s", i, part)
+				break
+			}
+		}
+		if err != nil {
+			break
+		}
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Message: "训练课无效",
+			Error:   err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	sessions, _ := json.Marshal(req.Sessions)
+	currentUser := user.(*models.User)
+	template := models.SplitTemplate{
+		UserID:   currentUser.ID,
+		Name:     req.Name,
+		Sessions: string(sessions),
+	}
+	if err := config.DB.Create(&template).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Success: false,
+			Message: "创建分化模板失败",
+			Error:   err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.SuccessResponse{
+		Success: true,
+		Message: "创建分化模板成功",
+		Data:    template,
+	})
+}
+
+// DeleteSplitTemplate 删除自定义分化模板，正在使用的模板不能删除
+// DELETE /api/training/split-templates/:id
+func (tmc *TrainingModeController) DeleteSplitTemplate(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Success: false,
+			Message: "用户未认证",
+			Error:   "User not authenticated",
+			Code:    http.StatusUnauthorized,
+		})
+		return
+	}
+	currentUser := user.(*models.User)
+
+	templateID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Message: "无效的分化模板ID",
+			Error:   err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	var template models.SplitTemplate
+	if err := config.DB.Where("id = ? AND user_id = ?", uint(templateID), currentUser.ID).First(&template).Error; err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Success: false,
+			Message: "分化模板不存在或无权限",
+			Error:   "Split template not found or no permission",
+			Code:    http.StatusNotFound,
+		})
+		return
+	}
+
+	var inUse int64
+	config.DB.Model(&models.TrainingMode{}).
+		Where("user_id = ? AND is_active = ? AND mode = ? AND split_template_id = ?", currentUser.ID, true, models.SplitCustom, template.ID).
+		Count(&inUse)
+	if inUse > 0 {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Success: false,
+			Message: "分化模板正在使用中",
+			Error:   "Split template is used by the active training mode",
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	if err := config.DB.Delete(&template).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Success: false,
+			Message: "删除分化模板失败",
+			Error:   err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{
+		Success: true,
+		Message: "删除分化模板成功",
+	})
+}
+
+// validateTrainingMode 校验模式、每周训练天数以及自定义模板归属
+func validateTrainingMode(db *gorm.DB, userID uint, req models.SaveTrainingModeRequest) (models.SplitDefinition, error) {
+	split, ok := models.LookupSplit(req.Mode)
+	if !ok {
+		return split, fmt.Errorf("unknown mode: %s", req.Mode)
+	}
+	if req.TrainDays < split.MinDays || req.TrainDays > split.MaxDays {
+		return split, fmt.Errorf("%s requires %d-%d training days per week", split.Key, split.MinDays, split.MaxDays)
+	}
+	if split.Key != models.SplitCustom {
+		return split, nil
+	}
+
+	if req.SplitTemplateID == nil {
+		return split, fmt.Errorf("split_template_id is required for custom mode")
+	}
+	var template models.SplitTemplate
+	if err := db.Where("id = ? AND user_id = ?", *req.SplitTemplateID, userID).First(&template).Error; err != nil {
+		return split, fmt.Errorf("split template %d not found", *req.SplitTemplateID)
+	}
+	return split, nil
+}
+
+// userTrainingMode 获取用户生效中的训练模式，未设置时默认每周3练的推拉腿
+func userTrainingMode(db *gorm.DB, userID uint) models.TrainingMode {
+	var mode models.TrainingMode
+	if err := db.Where("user_id = ? AND is_active = ?", userID, true).First(&mode).Error; err != nil {
+		mode = models.TrainingMode{
+			UserID:    userID,
+			Mode:      models.SplitPPL,
+			TrainDays: 3,
+			Target:    "增肌",
+			Level:     "中级",
+			IsActive:  true,
+		}
+	}
+	return mode
+}
+
+// resolveTrainingSchedule 解析训练模式对应的分化定义和一周安排
+//
+// 兼容历史中文模式名；未知模式或自定义模板已不存在时按推拉腿安排。
+func resolveTrainingSchedule(db *gorm.DB, mode models.TrainingMode) (models.SplitDefinition, []models.SplitScheduleDay) {
+	split, ok := models.LookupSplit(mode.Mode)
+	if ok && split.Key == models.SplitCustom {
+		var template models.SplitTemplate
+		ok = mode.SplitTemplateID != nil &&
+			db.Where("id = ? AND user_id = ?", *mode.SplitTemplateID, mode.UserID).First(&template).Error == nil
+		if ok {
+			split.Name = template.Name
+			split.Sessions = template.SessionList()
+			ok = len(split.Sessions) > 0
+		}
+	}
+	if !ok {
+		split, _ = models.LookupSplit(models.SplitPPL)
+	}
+	return split, services.BuildSplitSchedule(split.Sessions, mode.TrainDays)
+}
@@ -0,0 +1,170 @@
+package controllers
+
+import (
+	"net/http"
+	"testing"
+
+	"gymates-backend/config"
+	"gymates-backend/models"
+	"gymates-backend/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestTrainingMode 测试训练模式设置、自定义分化模板以及推荐按分化安排部位
+func TestTrainingMode(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	setupTestDB(t)
+
+	var user, other models.User
+	require.NoError(t, config.DB.First(&user, 1).Error)
+	require.NoError(t, config.DB.First(&other, 2).Error)
+	// 测试库在用例间共享，结束后恢复默认训练模式
+	t.Cleanup(func() {
+		config.DB.Unscoped().Where("user_id = ?", user.ID).Delete(&models.TrainingMode{})
+	})
+
+	controller := NewTrainingModeController()
+	router := gin.New()
+	router.GET("/api/training/splits", controller.GetSplits)
+	router.GET("/api/training/mode", withTestUser(&user), controller.GetTrainingMode)
+	router.PUT("/api/training/mode", withTestUser(&user), controller.SaveTrainingMode)
+	router.GET("/api/training/split-templates", withTestUser(&user), controller.GetSplitTemplates)
+	router.POST("/api/training/split-templates", withTestUser(&user), controller.CreateSplitTemplate)
+	router.DELETE("/api/training/split-templates/:id", withTestUser(&user), controller.DeleteSplitTemplate)
+	router.POST("/other/split-templates", withTestUser(&other), controller.CreateSplitTemplate)
+	router.GET("/api/training/ai/recommend", NewAIRecommendationController().GetAIRecommendation)
+
+	createTemplate := func(path string, req models.CreateSplitTemplateRequest) (models.SplitTemplate, int) {
+		var template models.SplitTemplate
+		code := sendJSON(t, router, "POST", path, req, &template)
+		return template, code
+	}
+
+	t.Run("内置分化列表", func(t *testing.T) {
+		var splits []models.SplitDefinition
+		require.Equal(t, http.StatusOK, sendJSON(t, router, "GET", "/api/training/splits", nil, &splits))
+		assert.Len(t, splits, len(models.SplitDefinitions))
+	})
+
+	t.Run("未设置时默认推拉腿", func(t *testing.T) {
+		var response models.TrainingModeResponse
+		require.Equal(t, http.StatusOK, sendJSON(t, router, "GET", "/api/training/mode", nil, &response))
+		assert.Equal(t, models.SplitPPL, response.Split.Key)
+		assert.Equal(t, 3, response.Mode.TrainDays)
+		assert.Len(t, restDays(response.Schedule), 4)
+	})
+
+	legDay := models.CreateSplitTemplateRequest{Name: "腿日加练", Sessions: []models.SplitSession{
+		{Name: "上肢", Parts: []string{"chest", "back"}},
+		{Name: "腿", Parts: []string{"legs"}},
+	}}
+	template, code := createTemplate("/api/training/split-templates", legDay)
+	require.Equal(t, http.StatusCreated, code)
+	otherTemplate, code := createTemplate("/other/split-templates", legDay)
+	require.Equal(t, http.StatusCreated, code)
+
+	t.Run("创建模板校验部位", func(t *testing.T) {
+		_, code := createTemplate("/api/training/split-templates", models.CreateSplitTemplateRequest{
+			Name: "无效", Sessions: []models.SplitSession{{Name: "翅膀", Parts: []string{"wings"}}},
+		})
+		assert.Equal(t, http.StatusBadRequest, code)
+
+		var templates []models.SplitTemplate
+		require.Equal(t, http.StatusOK, sendJSON(t, router, "GET", "/api/training/split-templates", nil, &templates))
+		require.Len(t, templates, 1)
+		assert.Equal(t, legDay.Sessions, templates[0].SessionList())
+	})
+
+	t.Run("保存训练模式", func(t *testing.T) {
+		tests := []struct {
+			name       string
+			req        models.SaveTrainingModeRequest
+			wantCode   int
+			wantKey    string
+			wantMonday string
+		}{
+			{name: "未知模式", req: models.SaveTrainingModeRequest{Mode: "Wednesday", TrainDays: 3}, wantCode: http.StatusBadRequest},
+			{name: "五分化只能每周5练", req: models.SaveTrainingModeRequest{Mode: models.SplitBro5, TrainDays: 3}, wantCode: http.StatusBadRequest},
+			{name: "自定义缺少模板", req: models.SaveTrainingModeRequest{Mode: models.SplitCustom, TrainDays: 3}, wantCode: http.StatusBadRequest},
+			{name: "自定义使用他人模板", req: models.SaveTrainingModeRequest{Mode: models.SplitCustom, TrainDays: 3, SplitTemplateID: &otherTemplate.ID}, wantCode: http.StatusBadRequest},
+			{name: "中文模式名", req: models.SaveTrainingModeRequest{Mode: "四分化", TrainDays: 4}, wantCode: http.StatusOK, wantKey: models.SplitBro4, wantMonday: "胸"},
+			{name: "自定义模板", req: models.SaveTrainingModeRequest{Mode: models.SplitCustom, TrainDays: 4, SplitTemplateID: &template.ID}, wantCode: http.StatusOK, wantKey: models.SplitCustom, wantMonday: "上肢"},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				var response models.TrainingModeResponse
+				require.Equal(t, tt.wantCode, sendJSON(t, router, "PUT", "/api/training/mode", tt.req, &response))
+				if tt.wantCode != http.StatusOK {
+					return
+				}
+				assert.Equal(t, tt.wantKey, response.Mode.Mode)
+				assert.Equal(t, tt.req.TrainDays, response.Mode.TrainDays)
+				assert.Equal(t, "增肌", response.Mode.Target)
+				monday, ok := services.ScheduleDay(response.Schedule, "Monday")
+				require.True(t, ok)
+				assert.Equal(t, tt.wantMonday, monday.Session)
+			})
+		}
+
+		var count int64
+		config.DB.Model(&models.TrainingMode{}).Where("user_id = ? AND is_active = ?", user.ID, true).Count(&count)
+		assert.Equal(t, int64(1), count)
+	})
+
+	t.Run("推荐按自定义分化安排部位", func(t *testing.T) {
+		// 每周4练：周一上肢、周二腿、周四上肢、周五腿
+		tests := []struct {
+			day         string
+			wantSession string
+			wantRest    bool
+			wantParts   []string
+		}{
+			{day: "Monday", wantSession: "上肢", wantParts: []string{"Chest", "Back"}},
+			{day: "Tuesday", wantSession: "腿", wantParts: []string{"Legs"}},
+			{day: "Wednesday", wantRest: true},
+			{day: "Friday", wantSession: "腿", wantParts: []string{"Legs"}},
+		}
+		for _, tt := range tests {
+			t.Run(tt.day, func(t *testing.T) {
+				var recommendation models.AIRecommendationResponse
+				require.Equal(t, http.StatusOK, sendJSON(t, router, "GET", "/api/training/ai/recommend?user_id=1&day="+tt.day, nil, &recommendation))
+				assert.Equal(t, tt.wantSession, recommendation.Session)
+				assert.Equal(t, tt.wantRest, recommendation.RestDay)
+				if tt.wantRest {
+					assert.Empty(t, recommendation.Parts)
+				}
+				for _, part := range recommendation.Parts {
+					assert.Contains(t, tt.wantParts, part.PartName)
+				}
+			})
+		}
+
+		assert.Equal(t, http.StatusBadRequest, sendJSON(t, router, "GET", "/api/training/ai/recommend?user_id=1&day=Funday", nil, nil))
+
+		var recommendation models.AIRecommendationResponse
+		require.Equal(t, http.StatusOK, sendJSON(t, router, "GET", "/api/training/ai/recommend?user_id=1&day=Wednesday&muscle_group=core", nil, &recommendation))
+		assert.False(t, recommendation.RestDay)
+	})
+
+	t.Run("使用中的模板不能删除", func(t *testing.T) {
+		path := "/api/training/split-templates/" + uintToString(template.ID)
+		assert.Equal(t, http.StatusBadRequest, sendJSON(t, router, "DELETE", path, nil, nil))
+		assert.Equal(t, http.StatusNotFound, sendJSON(t, router, "DELETE", "/api/training/split-templates/"+uintToString(otherTemplate.ID), nil, nil))
+
+		require.Equal(t, http.StatusOK, sendJSON(t, router, "PUT", "/api/training/mode", models.SaveTrainingModeRequest{Mode: models.SplitFullBody, TrainDays: 2}, nil))
+		assert.Equal(t, http.StatusOK, sendJSON(t, router, "DELETE", path, nil, nil))
+	})
+}
+
+func restDays(schedule []models.SplitScheduleDay) []string {
+	var days []string
+	for _, day := range schedule {
+		if day.RestDay {
+			days = append(days, day.DayName)
+		}
+	}
+	return days
+}
@@ -83,6 +83,33 @@ func (tpc *TrainingPlanController) UpdateTrainingPlan(c *gin.Context) {
 		return
 	}
 
+	// 加载动作库并校验引用的动作
+	library, err := newExerciseLibraryResolver(config.DB)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Success: false,
+			Message: "加载动作库失败",
+			Error:   err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+	for _, dayReq := range req.Plan {
+		for _, partReq := range dayReq.Parts {
+			for _, exerciseReq := range partReq.Exercises {
+				if err := library.check([]exerciseRef{{exerciseReq.Name, exerciseReq.ExerciseLibraryID}}); err != nil {
+					c.JSON(http.StatusBadRequest, models.ErrorResponse{
+						Success: false,
+						Message: "训练动作参数错误",
+						Error:   err.Error(),
+						Code:    http.StatusBadRequest,
+					})
+					return
+				}
+			}
+		}
+	}
+
 	// 开始事务
 	tx := config.DB.Begin()
 	defer func() {
@@ -112,6 +139,16 @@ func (tpc *TrainingPlanController) UpdateTrainingPlan(c *gin.Context) {
 			})
 			return
 		}
+	} else if err := ensureBaselineRevision(tx, plan.ID, req.UserID); err != nil {
+		// 修订功能上线前的计划先保存修改前的状态
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Success: false,
+			Message: "保存修订版本失败",
+			Error:   err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
 	}
 
 	// 删除现有的训练日
@@ -182,6 +219,7 @@ func (tpc *TrainingPlanController) UpdateTrainingPlan(c *gin.Context) {
 					Notes:           exerciseReq.Notes,
 					Order:           0,
 				}
+				library.apply(&exercise, exerciseReq.ExerciseLibraryID)
 
 				if err := tx.Create(&exercise).Error; err != nil {
 					tx.Rollback()
@@ -197,6 +235,18 @@ func (tpc *TrainingPlanController) UpdateTrainingPlan(c *gin.Context) {
 		}
 	}
 
+	// 记录修订版本
+	if err := savePlanRevision(tx, plan.ID, req.UserID, "update"); err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Success: false,
+			Message: "保存修订版本失败",
+			Error:   err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
 	// 提交事务
 	if err := tx.Commit().Error; err != nil {
 		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
@@ -230,6 +280,7 @@ type TrainingPartRequest struct {
 }
 
 type TrainingExerciseRequest struct {
+	ExerciseLibraryID *uint `json:"exercise_library_id"`
 	Name        string  `json:"name"`
 	Sets        int     `json:"sets"`
 	Reps        int     `json:"reps"`
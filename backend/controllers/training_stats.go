@@ -0,0 +1,111 @@
+package controllers
+
+import (
+	"strconv"
+	"time"
+
+	"gymates-backend/config"
+	"gymates-backend/models"
+)
+
+// 训练统计辅助函数：所有日期边界均按用户时区计算
+
+// loadUserLocation 获取用户时区，用户不存在时使用默认时区
+func loadUserLocation(userID uint) *time.Location {
+	var user models.User
+	if err := config.DB.Select("id", "timezone").First(&user, userID).Error; err != nil {
+		return models.LoadLocation("")
+	}
+	return user.TimeLocation()
+}
+
+// getWorkoutTimes 获取用户所有训练完成时间（进度上报 + 已完成的训练会话）
+func getWorkoutTimes(userID uint) []time.Time {
+	var times []time.Time
+
+	var reports []models.TrainingProgressReport
+	config.DB.Select("completed_at").Where("user_id = ?", userID).Find(&reports)
+	for _, report := range reports {
+		times = append(times, report.CompletedAt)
+	}
+
+	var sessions []models.WorkoutSession
+	config.DB.Select("end_time", "updated_at").
+		Where("user_id = ? AND status = ?", userID, "completed").
+		Find(&sessions)
+	for _, session := range sessions {
+		if session.EndTime != nil {
+			times = append(times, *session.EndTime)
+		} else {
+			times = append(times, session.UpdatedAt)
+		}
+	}
+
+	return times
+}
+
+// countTotalWorkouts 统计用户累计训练次数
+//
+// 同一次训练可能既作为训练会话完成、又通过进度接口上报，
+// 与已完成会话在同一天（用户时区）、同一计划的进度上报不重复计数。
+func countTotalWorkouts(userID uint) int {
+	loc := loadUserLocation(userID)
+
+	var sessions []models.WorkoutSession
+	config.DB.Select("training_plan_id", "end_time", "updated_at").
+		Where("user_id = ? AND status = ?", userID, "completed").
+		Find(&sessions)
+	sessionKeys := make(map[string]bool, len(sessions))
+	for _, session := range sessions {
+		finishedAt := session.UpdatedAt
+		if session.EndTime != nil {
+			finishedAt = *session.EndTime
+		}
+		sessionKeys[workoutKey(models.LocalDate(finishedAt, loc), strconv.FormatUint(uint64(session.TrainingPlanID), 10))] = true
+	}
+
+	var reports []models.TrainingProgressReport
+	config.DB.Select("plan_id", "completed_at", "local_date").Where("user_id = ?", userID).Find(&reports)
+	total := len(sessions)
+	for _, report := range reports {
+		localDate := report.LocalDate
+		if localDate == "" {
+			localDate = models.LocalDate(report.CompletedAt, loc)
+		}
+		if !sessionKeys[workoutKey(localDate, report.PlanID)] {
+			total++
+		}
+	}
+	return total
+}
+
+// workoutKey 按训练日期和计划标识一次训练
+func workoutKey(localDate, planID string) string {
+	return localDate + "|" + planID
+}
+
+// calculateStreak 计算连续训练天数
+// 从今天开始往前数；今天还没训练时从昨天开始，不会因为当天未训练而中断连续记录
+func calculateStreak(workoutTimes []time.Time, now time.Time, loc *time.Location) int {
+	trainedDays := make(map[string]bool, len(workoutTimes))
+	for _, t := range workoutTimes {
+		trainedDays[models.LocalDate(t, loc)] = true
+	}
+
+	day := models.StartOfDay(now, loc)
+	if !trainedDays[day.Format(models.DateLayout)] {
+		day = day.AddDate(0, 0, -1)
+	}
+
+	streak := 0
+	for trainedDays[day.Format(models.DateLayout)] {
+		streak++
+		day = day.AddDate(0, 0, -1)
+	}
+	return streak
+}
+
+// calculateLevel 根据累计训练次数计算等级（每10次升一级）
+func calculateLevel(totalWorkouts int) int {
+	return (totalWorkouts / 10) + 1
+}
@@ -0,0 +1,163 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"gymates-backend/config"
+	"gymates-backend/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestCalculateStreak 测试按用户时区计算连续训练天数
+func TestCalculateStreak(t *testing.T) {
+	shanghai := models.LoadLocation("Asia/Shanghai")
+	newYork := models.LoadLocation("America/New_York")
+
+	// 上海时间 2024-03-10 09:00
+	now := time.Date(2024, 3, 10, 1, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		times    []time.Time
+		loc      *time.Location
+		expected int
+	}{
+		{
+			name:     "无训练记录",
+			times:    nil,
+			loc:      shanghai,
+			expected: 0,
+		},
+		{
+			name: "连续三天包含今天",
+			times: []time.Time{
+				time.Date(2024, 3, 10, 0, 30, 0, 0, time.UTC),
+				time.Date(2024, 3, 9, 0, 30, 0, 0, time.UTC),
+				time.Date(2024, 3, 8, 0, 30, 0, 0, time.UTC),
+			},
+			loc:      shanghai,
+			expected: 3,
+		},
+		{
+			name: "今天未训练时从昨天开始计算",
+			times: []time.Time{
+				time.Date(2024, 3, 9, 2, 0, 0, 0, time.UTC),
+				time.Date(2024, 3, 8, 2, 0, 0, 0, time.UTC),
+			},
+			loc:      shanghai,
+			expected: 2,
+		},
+		{
+			name: "中断后不计入",
+			times: []time.Time{
+				time.Date(2024, 3, 10, 0, 30, 0, 0, time.UTC),
+				time.Date(2024, 3, 7, 0, 30, 0, 0, time.UTC),
+			},
+			loc:      shanghai,
+			expected: 1,
+		},
+		{
+			// UTC 3月9日 17:00 与 3月10日 00:30 在上海均为3月10日，在纽约均为3月9日
+			name: "同一UTC时间在不同时区落在不同日期",
+			times: []time.Time{
+				time.Date(2024, 3, 9, 17, 0, 0, 0, time.UTC),
+				time.Date(2024, 3, 10, 0, 30, 0, 0, time.UTC),
+			},
+			loc:      newYork,
+			expected: 1,
+		},
+		{
+			name: "同一天多次训练只算一天",
+			times: []time.Time{
+				time.Date(2024, 3, 9, 23, 0, 0, 0, time.UTC),
+				time.Date(2024, 3, 10, 0, 30, 0, 0, time.UTC),
+			},
+			loc:      shanghai,
+			expected: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, calculateStreak(tt.times, now, tt.loc))
+		})
+	}
+}
+
+// TestCalculateLevel 测试等级计算
+func TestCalculateLevel(t *testing.T) {
+	assert.Equal(t, 1, calculateLevel(0))
+	assert.Equal(t, 1, calculateLevel(9))
+	assert.Equal(t, 2, calculateLevel(10))
+	assert.Equal(t, 4, calculateLevel(35))
+}
+
+// TestCountTotalWorkouts 测试会话和进度上报重叠时不重复计数
+func TestCountTotalWorkouts(t *testing.T) {
+	setupTestDB(t)
+
+	user := models.User{Name: "计数用户", Email: "count-workouts@gymates.com", Password: "x", Timezone: "Asia/Shanghai"}
+	require.NoError(t, config.DB.Create(&user).Error)
+
+	// 上海时间 2024-03-10 00:30 完成计划7
+	endTime := time.Date(2024, 3, 9, 16, 30, 0, 0, time.UTC)
+	require.NoError(t, config.DB.Create(&models.WorkoutSession{
+		UserID: user.ID, TrainingPlanID: 7, StartTime: endTime.Add(-time.Hour), EndTime: &endTime, Status: "completed",
+	}).Error)
+	require.NoError(t, config.DB.Create(&models.WorkoutSession{
+		UserID: user.ID, TrainingPlanID: 7, StartTime: endTime, Status: "ongoing",
+	}).Error)
+	assert.Equal(t, 1, countTotalWorkouts(user.ID))
+
+	reports := []models.TrainingProgressReport{
+		{PlanID: "7", CompletedAt: endTime.Add(time.Hour), LocalDate: "2024-03-10"},       // 同一天同一计划，与会话重复
+		{PlanID: "7", CompletedAt: endTime.Add(2 * time.Hour)},                            // 旧数据没有 local_date，按用户时区补算后重复
+		{PlanID: "7", CompletedAt: endTime.Add(-24 * time.Hour), LocalDate: "2024-03-09"}, // 前一天
+		{PlanID: "8", CompletedAt: endTime.Add(time.Hour), LocalDate: "2024-03-10"},       // 同一天另一个计划
+	}
+	for i := range reports {
+		reports[i].UserID = user.ID
+		require.NoError(t, config.DB.Create(&reports[i]).Error)
+	}
+	assert.Equal(t, 3, countTotalWorkouts(user.ID))
+}
+
+// TestTrainingProgressReport 测试训练进度只能上报到当前登录用户
+func TestTrainingProgressReport(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	setupTestDB(t)
+
+	owner := models.User{Name: "进度上报", Email: "progress-owner@gymates.com", Password: "x"}
+	victim := models.User{Name: "进度他人", Email: "progress-victim@gymates.com", Password: "x"}
+	require.NoError(t, config.DB.Create(&owner).Error)
+	require.NoError(t, config.DB.Create(&victim).Error)
+
+	controller := NewAICoachController()
+	router := gin.New()
+	router.POST("/anon/ai/progress", controller.TrainingProgress)
+	router.POST("/owner/ai/progress", withTestUser(&owner), controller.TrainingProgress)
+	report := func(path string) int {
+		body, _ := json.Marshal(map[string]interface{}{"user_id": victim.ID, "plan_id": "p1", "duration": 30})
+		req, _ := http.NewRequest("POST", path, bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, report("/anon/ai/progress"))
+	assert.Equal(t, http.StatusOK, report("/owner/ai/progress"))
+
+	var reports []models.TrainingProgressReport
+	require.NoError(t, config.DB.Where("user_id IN ?", []uint{owner.ID, victim.ID}).Find(&reports).Error)
+	require.Len(t, reports, 1)
+	assert.Equal(t, owner.ID, reports[0].UserID)
+}
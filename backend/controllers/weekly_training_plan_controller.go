@@ -42,7 +42,7 @@ func (wtc *WeeklyTrainingPlanController) GetWeeklyTrainingPlans(c *gin.Context)
 
 	// 分页查询
 	offset := (page - 1) * limit
-	if err := query.Preload("User").Preload("Days.Parts.Exercises").
+	if err := query.Preload("User").Preload("Days.Parts.Exercises").Preload("Days.Parts.Groups.Exercises").
 		Offset(offset).Limit(limit).Order("created_at DESC").Find(&plans).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
 			Success: false,
@@ -86,7 +86,7 @@ func (wtc *WeeklyTrainingPlanController) GetWeeklyTrainingPlan(c *gin.Context) {
 	}
 
 	var plan models.WeeklyTrainingPlan
-	if err := config.DB.Preload("User").Preload("Days.Parts.Exercises").
+	if err := config.DB.Preload("User").Preload("Days.Parts.Exercises").Preload("Days.Parts.Groups.Exercises").
 		First(&plan, uint(planID)).Error; err != nil {
 		c.JSON(http.StatusNotFound, models.ErrorResponse{
 			Success: false,
@@ -130,6 +130,42 @@ func (wtc *WeeklyTrainingPlanController) CreateWeeklyTrainingPlan(c *gin.Context
 		return
 	}
 
+	// 加载动作库，用于校验和关联计划动作
+	library, err := newExerciseLibraryResolver(config.DB)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Success: false,
+			Message: "加载动作库失败",
+			Error:   err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	// 校验动作组结构和引用的动作库条目
+	for _, dayReq := range req.Days {
+		for _, partReq := range dayReq.Parts {
+			if err := validateExerciseGroups(partReq.Groups); err != nil {
+				c.JSON(http.StatusBadRequest, models.ErrorResponse{
+					Success: false,
+					Message: "动作组参数错误",
+					Error:   err.Error(),
+					Code:    http.StatusBadRequest,
+				})
+				return
+			}
+			if err := library.check(createExerciseRefs(partReq.Exercises, partReq.Groups)); err != nil {
+				c.JSON(http.StatusBadRequest, models.ErrorResponse{
+					Success: false,
+					Message: "训练动作参数错误",
+					Error:   err.Error(),
+					Code:    http.StatusBadRequest,
+				})
+				return
+			}
+		}
+	}
+
 	currentUser := user.(*models.User)
 
 	// 开始事务
@@ -220,6 +256,7 @@ func (wtc *WeeklyTrainingPlanController) CreateWeeklyTrainingPlan(c *gin.Context
 					Notes:           exerciseReq.Notes,
 					Order:           exerciseReq.Order,
 				}
+				library.apply(&exercise, exerciseReq.ExerciseLibraryID)
 
 				if err := tx.Create(&exercise).Error; err != nil {
 					tx.Rollback()
@@ -232,9 +269,33 @@ func (wtc *WeeklyTrainingPlanController) CreateWeeklyTrainingPlan(c *gin.Context
 					return
 				}
 			}
+
+			// 创建动作组
+			if err := createExerciseGroups(tx, library, plan.ID, part.ID, partReq.Groups); err != nil {
+				tx.Rollback()
+				c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+					Success: false,
+					Message: "创建动作组失败",
+					Error:   err.Error(),
+					Code:    http.StatusInternalServerError,
+				})
+				return
+			}
 		}
 	}
 
+	// 记录修订版本
+	if err := savePlanRevision(tx, plan.ID, currentUser.ID, "create"); err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Success: false,
+			Message: "保存修订版本失败",
+			Error:   err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
 	// 提交事务
 	if err := tx.Commit().Error; err != nil {
 		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
@@ -247,7 +308,7 @@ func (wtc *WeeklyTrainingPlanController) CreateWeeklyTrainingPlan(c *gin.Context
 	}
 
 	// 重新加载数据
-	config.DB.Preload("User").Preload("Days.Parts.Exercises").First(&plan, plan.ID)
+	config.DB.Preload("User").Preload("Days.Parts.Exercises").Preload("Days.Parts.Groups.Exercises").First(&plan, plan.ID)
 
 	c.JSON(http.StatusCreated, models.SuccessResponse{
 		Success: true,
@@ -294,6 +355,42 @@ func (wtc *WeeklyTrainingPlanController) UpdateWeeklyTrainingPlan(c *gin.Context
 		return
 	}
 
+	// 加载动作库，用于校验和关联计划动作
+	library, err := newExerciseLibraryResolver(config.DB)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Success: false,
+			Message: "加载动作库失败",
+			Error:   err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	// 校验动作组结构和引用的动作库条目
+	for _, dayReq := range req.Days {
+		for _, partReq := range dayReq.Parts {
+			if err := validateExerciseGroups(partReq.Groups); err != nil {
+				c.JSON(http.StatusBadRequest, models.ErrorResponse{
+					Success: false,
+					Message: "动作组参数错误",
+					Error:   err.Error(),
+					Code:    http.StatusBadRequest,
+				})
+				return
+			}
+			if err := library.check(updateExerciseRefs(partReq.Exercises, partReq.Groups)); err != nil {
+				c.JSON(http.StatusBadRequest, models.ErrorResponse{
+					Success: false,
+					Message: "训练动作参数错误",
+					Error:   err.Error(),
+					Code:    http.StatusBadRequest,
+				})
+				return
+			}
+		}
+	}
+
 	currentUser := user.(*models.User)
 
 	// 检查计划是否存在且属于当前用户
@@ -317,6 +414,18 @@ func (wtc *WeeklyTrainingPlanController) UpdateWeeklyTrainingPlan(c *gin.Context
 		}
 	}()
 
+	// 修订功能上线前的计划先保存修改前的状态
+	if err := ensureBaselineRevision(tx, plan.ID, currentUser.ID); err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Success: false,
+			Message: "保存修订版本失败",
+			Error:   err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
 	// 更新计划基本信息
 	updates := map[string]interface{}{
 		"updated_at": time.Now(),
@@ -419,6 +528,7 @@ func (wtc *WeeklyTrainingPlanController) UpdateWeeklyTrainingPlan(c *gin.Context
 						IsCompleted:     exerciseReq.IsCompleted != nil && *exerciseReq.IsCompleted,
 						Order:           exerciseReq.Order,
 					}
+					library.apply(&exercise, exerciseReq.ExerciseLibraryID)
 
 					if err := tx.Create(&exercise).Error; err != nil {
 						tx.Rollback()
@@ -431,10 +541,34 @@ func (wtc *WeeklyTrainingPlanController) UpdateWeeklyTrainingPlan(c *gin.Context
 						return
 					}
 				}
+
+				// 创建动作组
+				if err := createExerciseGroups(tx, library, plan.ID, part.ID, partReq.Groups); err != nil {
+					tx.Rollback()
+					c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+						Success: false,
+						Message: "创建动作组失败",
+						Error:   err.Error(),
+						Code:    http.StatusInternalServerError,
+					})
+					return
+				}
 			}
 		}
 	}
 
+	// 记录修订版本
+	if err := savePlanRevision(tx, plan.ID, currentUser.ID, "update"); err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Success: false,
+			Message: "保存修订版本失败",
+			Error:   err.Error(),
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
 	// 提交事务
 	if err := tx.Commit().Error; err != nil {
 		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
@@ -447,7 +581,7 @@ func (wtc *WeeklyTrainingPlanController) UpdateWeeklyTrainingPlan(c *gin.Context
 	}
 
 	// 重新加载数据
-	config.DB.Preload("User").Preload("Days.Parts.Exercises").First(&plan, plan.ID)
+	config.DB.Preload("User").Preload("Days.Parts.Exercises").Preload("Days.Parts.Groups.Exercises").First(&plan, plan.ID)
 
 	c.JSON(http.StatusOK, models.SuccessResponse{
 		Success: true,
@@ -531,17 +665,14 @@ func (wtc *WeeklyTrainingPlanController) GetTodayTraining(c *gin.Context) {
 
 	currentUser := user.(*models.User)
 
-	// 获取今日是星期几 (1-7, 1=周一)
-	today := int(time.Now().Weekday())
-	if today == 0 {
-		today = 7 // 周日
-	}
+	// 按用户时区获取今日是星期几 (1-7, 1=周一)
+	today := models.ISOWeekday(time.Now().In(currentUser.TimeLocation()))
 
 	// 查找用户的活动训练计划
 	var plan models.WeeklyTrainingPlan
 	if err := config.DB.Where("user_id = ? AND is_active = ?", currentUser.ID, true).
 		Preload("Days", "day_of_week = ?", today).
-		Preload("Days.Parts.Exercises").
+		Preload("Days.Parts.Exercises").Preload("Days.Parts.Groups.Exercises").
 		First(&plan).Error; err != nil {
 		c.JSON(http.StatusNotFound, models.ErrorResponse{
 			Success: false,
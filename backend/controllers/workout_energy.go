@@ -0,0 +1,115 @@
+package controllers
+
+import (
+	"gymates-backend/models"
+	"gymates-backend/services"
+
+	"gorm.io/gorm"
+)
+
+// 训练消耗估算辅助函数：按动作 MET、用户体重和实际训练时长或组数计算
+
+// userBodyWeight 用户当前体重（与最近一次体重记录同步），未填写时为0
+func userBodyWeight(db *gorm.DB, userID uint) float64 {
+	var user models.User
+	if err := db.Select("id", "weight").First(&user, userID).Error; err != nil {
+		return 0
+	}
+	return user.Weight
+}
+
+// libraryMET 动作库条目的 MET，未设置时按动作类型取默认值
+func libraryMET(entry *models.ExerciseLibrary) float64 {
+	if entry == nil {
+		return models.DefaultMET
+	}
+	if entry.MET > 0 {
+		return entry.MET
+	}
+	return models.METForType(entry.Type)
+}
+
+// exerciseRestSeconds 动作的组间休息（秒），兼容旧的 rest_time 字段
+func exerciseRestSeconds(exercise models.Exercise) int {
+	if exercise.RestSeconds > 0 {
+		return exercise.RestSeconds
+	}
+	return exercise.RestTime
+}
+
+// plannedEnergySets 按计划的组数和次数展开为估算用的组
+func plannedEnergySets(exercise models.Exercise, met float64) []services.EnergySet {
+	sets := make([]services.EnergySet, 0, exercise.Sets)
+	for i := 0; i < exercise.Sets; i++ {
+		sets = append(sets, services.EnergySet{
+			MET:             met,
+			Reps:            exercise.Reps,
+			DurationSeconds: exercise.Duration,
+			RestSeconds:     exerciseRestSeconds(exercise),
+		})
+	}
+	return sets
+}
+
+// sessionEnergyInput 收集训练会话的已完成组、实际时长和用户体重
+//
+// 没有组记录时按训练计划中动作的平均 MET 和实际时长估算。
+func sessionEnergyInput(db *gorm.DB, session models.WorkoutSession) services.SessionEnergyInput {
+	input := services.SessionEnergyInput{BodyWeight: userBodyWeight(db, session.UserID)}
+	if session.EndTime != nil && !session.StartTime.IsZero() {
+		input.Minutes = session.EndTime.Sub(session.StartTime).Minutes()
+	}
+
+	var planExercises []models.Exercise
+	db.Preload("Library").Where("training_plan_id = ?", session.TrainingPlanID).Find(&planExercises)
+	exercises := make(map[uint]models.Exercise, len(planExercises))
+	var metSum float64
+	for _, exercise := range planExercises {
+		exercises[exercise.ID] = exercise
+		metSum += libraryMET(exercise.Library)
+	}
+	if len(planExercises) > 0 {
+		input.FallbackMET = metSum / float64(len(planExercises))
+	}
+
+	var setLogs []models.WorkoutSetLog
+	db.Where("workout_session_id = ?", session.ID).Find(&setLogs)
+	for _, setLog := range setLogs {
+		exercise, ok := exercises[setLog.ExerciseID]
+		if !ok {
+			db.Preload("Library").First(&exercise, setLog.ExerciseID)
+			exercises[setLog.ExerciseID] = exercise
+		}
+		input.Sets = append(input.Sets, services.EnergySet{
+			MET:             libraryMET(exercise.Library),
+			Reps:            setLog.Reps,
+			DurationSeconds: setLog.DurationSeconds,
+			RestSeconds:     exerciseRestSeconds(exercise),
+		})
+	}
+	return input
+}
+
+// sessionEnergy 估算训练会话的消耗，已有穿戴设备数值时以其为准
+func sessionEnergy(db *gorm.DB, session models.WorkoutSession) models.EnergyEstimate {
+	estimate := services.EstimateSessionEnergy(sessionEnergyInput(db, session))
+	if session.CaloriesSource == models.CaloriesSourceWearable {
+		estimate.Calories = session.TotalCalories
+		estimate.Source = models.CaloriesSourceWearable
+	}
+	return estimate
+}
+
+// updateSessionCalories 重新估算并保存训练会话的消耗
+func updateSessionCalories(db *gorm.DB, session *models.WorkoutSession) (models.EnergyEstimate, error) {
+	estimate := sessionEnergy(db, *session)
+	session.EstimatedCalories = estimate.EstimatedCalories
+	session.TotalCalories = estimate.Calories
+	session.CaloriesSource = estimate.Source
+	err := db.Model(session).Updates(map[string]interface{}{
+		"estimated_calories": session.EstimatedCalories,
+		"total_calories":     session.TotalCalories,
+		"calories_source":    session.CaloriesSource,
+	}).Error
+	return estimate, err
+}
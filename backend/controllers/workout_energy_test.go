@@ -0,0 +1,136 @@
+package controllers
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"gymates-backend/config"
+	"gymates-backend/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSessionCalories 测试计划和训练会话的消耗估算以及穿戴设备覆盖
+func TestSessionCalories(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	setupTestDB(t)
+
+	user := models.User{
+		Name: "消耗测试", Email: "energy@gymates.com", Password: "x",
+		Age: 25, Height: 175.5, Weight: 80, Gender: models.GenderMale, Goal: "维持",
+	}
+	require.NoError(t, config.DB.Create(&user).Error)
+	other := models.User{Name: "消耗其他用户", Email: "energy-other@gymates.com", Password: "x"}
+	require.NoError(t, config.DB.Create(&other).Error)
+
+	controller := NewTrainingController()
+	router := gin.New()
+	router.POST("/api/training/plans", withTestUser(&user), controller.CreateTrainingPlan)
+	router.POST("/api/training/sessions", withTestUser(&user), controller.StartWorkoutSession)
+	router.POST("/api/training/sessions/:id/sets", withTestUser(&user), controller.LogWorkoutSet)
+	router.POST("/api/training/sessions/:id/complete", withTestUser(&user), controller.CompleteWorkoutSession)
+	router.GET("/api/training/sessions/:id/calories", withTestUser(&user), controller.GetSessionCalories)
+	router.PUT("/api/training/sessions/:id/calories", withTestUser(&user), controller.SetSessionCalories)
+	router.GET("/other/training/sessions/:id/calories", withTestUser(&other), controller.GetSessionCalories)
+	router.POST("/other/training/sessions/:id/complete", withTestUser(&other), controller.CompleteWorkoutSession)
+	router.GET("/api/profile/stats", withTestUser(&user), NewAuthController().GetUserStats)
+
+	calories := func(v int) *int { return &v }
+
+	var plan models.TrainingPlan
+	require.Equal(t, http.StatusCreated, sendJSON(t, router, "POST", "/api/training/plans", models.CreateTrainingPlanRequest{
+		Name:           "胸部消耗测试",
+		Duration:       45,
+		CaloriesBurned: 999, // 客户端传入的数值会被忽略
+		Exercises: []models.Exercise{
+			{Name: "平板卧推", Sets: 3, Reps: 10, RestTime: 60},
+			{Name: "哑铃飞鸟", Sets: 2, Reps: 12, RestTime: 60},
+		},
+	}, &plan))
+
+	// 共享的测试库中可能残留其他用例以相同计划ID创建的动作，只取本计划创建的两个
+	var exercises []models.Exercise
+	for _, exercise := range plan.Exercises {
+		if exercise.Name == "平板卧推" || exercise.Name == "哑铃飞鸟" {
+			exercises = append(exercises, exercise)
+		}
+	}
+	require.Len(t, exercises, 2)
+
+	t.Run("创建计划时估算消耗", func(t *testing.T) {
+		assert.Equal(t, 297, plan.CaloriesBurned)
+		assert.Equal(t, 40, exercises[0].Calories)
+		assert.Equal(t, 17, exercises[1].Calories)
+	})
+
+	var session models.WorkoutSession
+	require.Equal(t, http.StatusCreated, sendJSON(t, router, "POST", "/api/training/sessions", models.StartWorkoutSessionRequest{TrainingPlanID: plan.ID}, &session))
+	assert.False(t, session.StartTime.IsZero())
+	sessionPath := "/api/training/sessions/" + uintToString(session.ID)
+
+	t.Run("每记录一组更新估算", func(t *testing.T) {
+		for _, exercise := range exercises {
+			for set := 1; set <= exercise.Sets; set++ {
+				require.Equal(t, http.StatusCreated, sendJSON(t, router, "POST", sessionPath+"/sets", models.LogWorkoutSetRequest{
+					ExerciseID: exercise.ID, SetNumber: set, Reps: exercise.Reps, Weight: 40,
+				}, nil))
+			}
+		}
+
+		var estimate models.EnergyEstimate
+		require.Equal(t, http.StatusOK, sendJSON(t, router, "GET", sessionPath+"/calories", nil, &estimate))
+		assert.Equal(t, 57, estimate.Calories)
+		assert.Equal(t, 4.95, estimate.MET)
+		assert.Equal(t, 5, estimate.Sets)
+		assert.Equal(t, 80.0, estimate.BodyWeight)
+
+		assert.Equal(t, http.StatusNotFound, sendJSON(t, router, "GET", "/other/training/sessions/"+uintToString(session.ID)+"/calories", nil, nil))
+	})
+
+	t.Run("不能完成其他用户的训练会话", func(t *testing.T) {
+		assert.Equal(t, http.StatusNotFound, sendJSON(t, router, "POST", "/other/training/sessions/"+uintToString(session.ID)+"/complete", nil, nil))
+		var reloaded models.WorkoutSession
+		require.NoError(t, config.DB.First(&reloaded, session.ID).Error)
+		assert.NotEqual(t, "completed", reloaded.Status)
+	})
+
+	t.Run("完成时按实际时长估算并使用穿戴设备数值", func(t *testing.T) {
+		require.NoError(t, config.DB.Model(&session).Update("start_time", time.Now().Add(-45*time.Minute)).Error)
+
+		assert.Equal(t, http.StatusBadRequest, sendJSON(t, router, "POST", sessionPath+"/complete", models.SessionCaloriesRequest{Calories: calories(-1)}, nil))
+		var completed models.WorkoutSession
+		require.Equal(t, http.StatusOK, sendJSON(t, router, "POST", sessionPath+"/complete", models.SessionCaloriesRequest{Calories: calories(350)}, &completed))
+		assert.Equal(t, 350, completed.TotalCalories)
+		assert.Equal(t, 297, completed.EstimatedCalories)
+		assert.Equal(t, models.CaloriesSourceWearable, completed.CaloriesSource)
+	})
+
+	t.Run("取消和重新设置覆盖", func(t *testing.T) {
+		var estimate models.EnergyEstimate
+		require.Equal(t, http.StatusOK, sendJSON(t, router, "PUT", sessionPath+"/calories", models.SessionCaloriesRequest{}, &estimate))
+		assert.Equal(t, 297, estimate.Calories)
+		assert.Equal(t, models.CaloriesSourceEstimated, estimate.Source)
+
+		var wearable models.EnergyEstimate
+		require.Equal(t, http.StatusOK, sendJSON(t, router, "PUT", sessionPath+"/calories", models.SessionCaloriesRequest{Calories: calories(420)}, &wearable))
+		assert.Equal(t, 420, wearable.Calories)
+		assert.Equal(t, 297, wearable.EstimatedCalories)
+		assert.Equal(t, models.CaloriesSourceWearable, wearable.Source)
+	})
+
+	t.Run("统计和营养目标使用生效的消耗", func(t *testing.T) {
+		var stats struct {
+			TotalCalories int `json:"total_calories"`
+		}
+		require.Equal(t, http.StatusOK, sendJSON(t, router, "GET", "/api/profile/stats", nil, &stats))
+		assert.Equal(t, 420, stats.TotalCalories)
+
+		target, err := userNutritionTarget(config.DB, &user)
+		require.NoError(t, err)
+		assert.Equal(t, 15.0, target.ExerciseCalories) // 420 / 28
+		assert.Equal(t, 2147.0, target.TDEE)           // 1777 × 1.2 + 15
+	})
+}
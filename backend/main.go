@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"gymates-backend/config"
+	"gymates-backend/controllers"
 	"gymates-backend/middleware"
 	"gymates-backend/routes"
 	"gymates-backend/services"
@@ -28,6 +29,9 @@ func main() {
 		log.Fatal("Failed to initialize database:", err)
 	}
 
+	// 在后台补建聊天记录的全文索引
+	controllers.StartMessageSearch()
+
 	// 初始化AI服务
 	services.InitAIServices()
 	log.Println("🤖 AI Services initialized")
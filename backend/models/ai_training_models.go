@@ -27,6 +27,8 @@ type AITrainingRecommendation struct {
 	Overview  TrainingOverview    `json:"overview"`
 	Exercises []RecommendedExercise `json:"exercises"`
 	Generated time.Time           `json:"generated"`
+	Restrictions []ExerciseRestriction `json:"restrictions,omitempty"` // 因伤病排除或降权的动作及原因
+	Seed         int64                 `json:"seed"`                   // 随机种子，传回 seed 参数可复现本次推荐
 }
 
 // TrainingOverview 训练概览
@@ -103,4 +105,20 @@ type VoiceSettings struct {
 	CreatedAt time.Time      `json:"created_at"`
 	UpdatedAt time.Time      `json:"updated_at"`
 	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
-}
\ No newline at end of file
+}
+// TrainingProgressReport 训练进度上报记录
+type TrainingProgressReport struct {
+	ID                 uint           `json:"id" gorm:"primaryKey"`
+	UserID             uint           `json:"user_id" gorm:"not null;index"`
+	User               User           `json:"user" gorm:"foreignKey:UserID"`
+	PlanID             string         `json:"plan_id" gorm:"size:50"`
+	CompletedAt        time.Time      `json:"completed_at" gorm:"index"`
+	LocalDate          string         `json:"local_date" gorm:"size:10;index"` // 用户时区下的训练日期 YYYY-MM-DD
+	ExercisesCompleted int            `json:"exercises_completed"`
+	Duration           int            `json:"duration"` // 训练时长（分钟）
+	CaloriesBurned     int            `json:"calories_burned"`
+	Notes              string         `json:"notes" gorm:"type:text"`
+	CreatedAt          time.Time      `json:"created_at"`
+	UpdatedAt          time.Time      `json:"updated_at"`
+	DeletedAt          gorm.DeletedAt `json:"-" gorm:"index"`
+}
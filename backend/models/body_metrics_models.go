@@ -0,0 +1,133 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 身体指标
+const (
+	MetricWeight  = "weight"   // 体重（kg）
+	MetricBodyFat = "body_fat" // 体脂率（%）
+	MetricChest   = "chest"    // 胸围（cm）
+	MetricWaist   = "waist"    // 腰围（cm）
+	MetricHips    = "hips"     // 臀围（cm）
+	MetricArm     = "arm"      // 臂围（cm）
+	MetricThigh   = "thigh"    // 大腿围（cm）
+)
+
+// BodyMetricNames 身体指标中文名
+var BodyMetricNames = map[string]string{
+	MetricWeight:  "体重",
+	MetricBodyFat: "体脂率",
+	MetricChest:   "胸围",
+	MetricWaist:   "腰围",
+	MetricHips:    "臀围",
+	MetricArm:     "臂围",
+	MetricThigh:   "大腿围",
+}
+
+// 目标体重预测状态
+const (
+	ProjectionReached          = "reached"           // 已达到目标
+	ProjectionOnTrack          = "on_track"          // 按当前趋势可达到
+	ProjectionWrongDirection   = "wrong_direction"   // 趋势与目标方向相反或停滞
+	ProjectionInsufficientData = "insufficient_data" // 记录太少，无法预测
+)
+
+// BodyMeasurement 身体测量记录，每位用户每天一条，未测量的指标为空
+type BodyMeasurement struct {
+	ID        uint           `json:"id" gorm:"primaryKey"`
+	UserID    uint           `json:"user_id" gorm:"not null;uniqueIndex:idx_body_user_date"`
+	Date      string         `json:"date" gorm:"size:10;not null;uniqueIndex:idx_body_user_date"` // YYYY-MM-DD，用户时区
+	Weight    *float64       `json:"weight"`
+	BodyFat   *float64       `json:"body_fat"`
+	Chest     *float64       `json:"chest"`
+	Waist     *float64       `json:"waist"`
+	Hips      *float64       `json:"hips"`
+	Arm       *float64       `json:"arm"`
+	Thigh     *float64       `json:"thigh"`
+	PhotoURLs string         `json:"photo_urls" gorm:"type:text"` // JSON字符串存储进度照片地址
+	Notes     string         `json:"notes" gorm:"type:text"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// PhotoList 进度照片地址列表
+func (m BodyMeasurement) PhotoList() []string {
+	return decodeStringList(m.PhotoURLs)
+}
+
+// Metric 按指标键取值，未测量时返回 false
+func (m BodyMeasurement) Metric(metric string) (float64, bool) {
+	var value *float64
+	switch metric {
+	case MetricWeight:
+		value = m.Weight
+	case MetricBodyFat:
+		value = m.BodyFat
+	case MetricChest:
+		value = m.Chest
+	case MetricWaist:
+		value = m.Waist
+	case MetricHips:
+		value = m.Hips
+	case MetricArm:
+		value = m.Arm
+	case MetricThigh:
+		value = m.Thigh
+	}
+	if value == nil {
+		return 0, false
+	}
+	return *value, true
+}
+
+// 请求DTO结构
+
+// SaveBodyMeasurementRequest 记录身体测量请求，同一天重复提交时只覆盖本次提交的指标
+type SaveBodyMeasurementRequest struct {
+	Date      string   `json:"date"` // 为空时取用户时区的今天
+	Weight    *float64 `json:"weight" binding:"omitempty,gt=0,lt=500"`
+	BodyFat   *float64 `json:"body_fat" binding:"omitempty,gt=0,lt=100"`
+	Chest     *float64 `json:"chest" binding:"omitempty,gt=0,lt=300"`
+	Waist     *float64 `json:"waist" binding:"omitempty,gt=0,lt=300"`
+	Hips      *float64 `json:"hips" binding:"omitempty,gt=0,lt=300"`
+	Arm       *float64 `json:"arm" binding:"omitempty,gt=0,lt=150"`
+	Thigh     *float64 `json:"thigh" binding:"omitempty,gt=0,lt=200"`
+	PhotoURLs []string `json:"photo_urls" binding:"max=9,dive,url"`
+	Notes     string   `json:"notes" binding:"max=500"`
+}
+
+// 响应DTO结构
+
+// BodyTrendPoint 趋势中的一个数据点
+type BodyTrendPoint struct {
+	Date          string  `json:"date"`
+	Value         float64 `json:"value"`
+	Smoothed      float64 `json:"smoothed"`       // 指数加权移动平均（EWMA）
+	MovingAverage float64 `json:"moving_average"` // 最近7天记录的简单平均
+}
+
+// GoalProjection 目标体重预测
+type GoalProjection struct {
+	Status        string  `json:"status"`
+	TargetWeight  float64 `json:"target_weight"`
+	Remaining     float64 `json:"remaining"`                // 目标减去当前趋势体重
+	RatePerWeek   float64 `json:"rate_per_week"`            // 当前趋势每周变化（kg）
+	DaysRemaining int     `json:"days_remaining,omitempty"` // 仅 on_track
+	ProjectedDate string  `json:"projected_date,omitempty"` // 仅 on_track
+}
+
+// BodyTrendResponse 身体指标趋势
+type BodyTrendResponse struct {
+	Metric      string           `json:"metric"`
+	Name        string           `json:"name"`
+	Points      []BodyTrendPoint `json:"points"`
+	Latest      *float64         `json:"latest,omitempty"`
+	Trend       *float64         `json:"trend,omitempty"` // 最新的平滑值
+	RatePerWeek float64          `json:"rate_per_week"`   // 最近4周平滑值的每周变化
+	Projection  *GoalProjection  `json:"projection,omitempty"`
+}
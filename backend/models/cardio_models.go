@@ -0,0 +1,175 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 有氧运动类型
+const (
+	CardioRun   = "run"
+	CardioCycle = "cycle"
+	CardioRow   = "row"
+	CardioSwim  = "swim"
+)
+
+// CardioTypeNames 有氧运动类型中文名
+var CardioTypeNames = map[string]string{
+	CardioRun:   "跑步",
+	CardioCycle: "骑行",
+	CardioRow:   "划船",
+	CardioSwim:  "游泳",
+}
+
+// 有氧记录来源
+const (
+	CardioSourceManual = "manual"
+	CardioSourceGPX    = "gpx"
+	CardioSourceTCX    = "tcx"
+	CardioSourceFIT    = "fit"
+)
+
+// 最佳成绩距离（米），仅统计跑步
+const (
+	Distance5K  = 5000
+	Distance10K = 10000
+)
+
+// CardioActivity 有氧运动记录，不依赖训练计划
+//
+// 导入的轨迹只保存计算结果（分段、心率区间、最佳成绩），不保存原始轨迹点。
+type CardioActivity struct {
+	ID                uint           `json:"id" gorm:"primaryKey"`
+	UserID            uint           `json:"user_id" gorm:"not null;index"`
+	Type              string         `json:"type" gorm:"size:20;not null"`
+	Name              string         `json:"name" gorm:"size:100"`
+	StartTime         time.Time      `json:"start_time" gorm:"not null;index"`
+	DurationSeconds   int            `json:"duration_seconds" gorm:"not null"`
+	Distance          float64        `json:"distance"`  // 米
+	AvgPace           float64        `json:"avg_pace"`  // 秒/公里，没有距离时为0
+	AvgSpeed          float64        `json:"avg_speed"` // 公里/小时
+	AvgHeartRate      int            `json:"avg_heart_rate"`
+	MaxHeartRate      int            `json:"max_heart_rate"`
+	ElevationGain     float64        `json:"elevation_gain"` // 累计爬升（米）
+	Calories          int            `json:"calories"`       // 生效的消耗，设备记录值优先于估算值
+	EstimatedCalories int            `json:"estimated_calories"`
+	CaloriesSource    string         `json:"calories_source" gorm:"size:20;default:'estimated'"`
+	Source            string         `json:"source" gorm:"size:10;default:'manual'"`
+	TrackPoints       int            `json:"track_points"`
+	Splits            string         `json:"splits" gorm:"type:text"`           // JSON字符串存储每公里分段
+	HeartRateZones    string         `json:"heart_rate_zones" gorm:"type:text"` // JSON字符串存储心率区间用时
+	Best5K            *int           `json:"best_5k"`                           // 活动中最快的5公里用时（秒）
+	Best10K           *int           `json:"best_10k"`
+	Notes             string         `json:"notes" gorm:"type:text"`
+	CreatedAt         time.Time      `json:"created_at"`
+	UpdatedAt         time.Time      `json:"updated_at"`
+	DeletedAt         gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// SplitList 每公里分段
+func (a CardioActivity) SplitList() []ActivitySplit {
+	var splits []ActivitySplit
+	if a.Splits != "" {
+		_ = json.Unmarshal([]byte(a.Splits), &splits)
+	}
+	return splits
+}
+
+// ZoneList 心率区间用时
+func (a CardioActivity) ZoneList() []HeartRateZone {
+	var zones []HeartRateZone
+	if a.HeartRateZones != "" {
+		_ = json.Unmarshal([]byte(a.HeartRateZones), &zones)
+	}
+	return zones
+}
+
+// ActivitySplit 分段（最后一段可能不足1公里）
+type ActivitySplit struct {
+	Index           int     `json:"index"`
+	Distance        float64 `json:"distance"` // 米
+	DurationSeconds float64 `json:"duration_seconds"`
+	Pace            float64 `json:"pace"` // 秒/公里
+	AvgHeartRate    int     `json:"avg_heart_rate,omitempty"`
+	ElevationGain   float64 `json:"elevation_gain"`
+}
+
+// HeartRateZone 心率区间及在该区间的用时
+type HeartRateZone struct {
+	Zone    int     `json:"zone"`
+	Name    string  `json:"name"`
+	MinBPM  int     `json:"min_bpm"`
+	MaxBPM  int     `json:"max_bpm"`
+	Seconds float64 `json:"seconds"`
+}
+
+// 请求DTO结构
+
+// CreateCardioActivityRequest 手动记录有氧运动请求，calories 为穿戴设备提供的消耗
+type CreateCardioActivityRequest struct {
+	Type            string     `json:"type" binding:"required,oneof=run cycle row swim"`
+	Name            string     `json:"name" binding:"max=100"`
+	StartTime       *time.Time `json:"start_time"` // 为空时取当前时间
+	DurationSeconds int        `json:"duration_seconds" binding:"required,min=1,max=86400"`
+	Distance        float64    `json:"distance" binding:"min=0,max=1000000"` // 米
+	AvgHeartRate    int        `json:"avg_heart_rate" binding:"min=0,max=250"`
+	MaxHeartRate    int        `json:"max_heart_rate" binding:"min=0,max=250"`
+	ElevationGain   float64    `json:"elevation_gain" binding:"min=0"`
+	Calories        *int       `json:"calories" binding:"omitempty,min=0,max=10000"`
+	Notes           string     `json:"notes" binding:"max=500"`
+}
+
+// 响应DTO结构
+
+// CardioActivityDetail 有氧运动详情
+type CardioActivityDetail struct {
+	CardioActivity
+	SplitList []ActivitySplit `json:"split_list"`
+	ZoneList  []HeartRateZone `json:"zone_list"`
+}
+
+// CardioActivitiesResponse 有氧运动列表响应
+type CardioActivitiesResponse struct {
+	Activities []CardioActivity `json:"activities"`
+	Pagination Pagination       `json:"pagination"`
+}
+
+// BestEffort 最佳成绩
+type BestEffort struct {
+	Distance   int       `json:"distance"` // 米
+	Seconds    int       `json:"seconds"`
+	Pace       float64   `json:"pace"` // 秒/公里
+	ActivityID uint      `json:"activity_id"`
+	StartTime  time.Time `json:"start_time"`
+}
+
+// CardioSummary 有氧运动汇总
+type CardioSummary struct {
+	Type            string      `json:"type,omitempty"` // 为空表示全部类型
+	Activities      int         `json:"activities"`
+	TotalDistance   float64     `json:"total_distance"` // 米
+	TotalSeconds    int         `json:"total_seconds"`
+	TotalCalories   int         `json:"total_calories"`
+	LongestDistance float64     `json:"longest_distance"`
+	Best5K          *BestEffort `json:"best_5k"`
+	Best10K         *BestEffort `json:"best_10k"`
+}
+
+// WorkoutHistoryItem 训练历史中的一条记录（力量训练会话或有氧运动）
+type WorkoutHistoryItem struct {
+	Kind     string          `json:"kind"` // session/cardio
+	Time     time.Time       `json:"time"`
+	Session  *WorkoutSession `json:"session,omitempty"`
+	Activity *CardioActivity `json:"activity,omitempty"`
+	Calories int             `json:"calories"`
+	Distance float64         `json:"distance,omitempty"`
+	Title    string          `json:"title"`
+}
+
+// 训练历史记录类型
+const (
+	HistoryKindSession = "session"
+	HistoryKindCardio  = "cardio"
+)
@@ -1,6 +1,9 @@
 package models
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
 
 // 请求DTO结构
 
@@ -25,8 +28,14 @@ type UpdateProfileRequest struct {
 	Age        int     `json:"age"`
 	Height     float64 `json:"height"`
 	Weight     float64 `json:"weight"`
+	Gender     string  `json:"gender" binding:"omitempty,oneof=male female"`
 	Goal       string  `json:"goal"`
 	Experience string  `json:"experience"`
+	Timezone   string  `json:"timezone"`
+	Latitude   *float64 `json:"latitude" binding:"omitempty,min=-90,max=90"`
+	Longitude  *float64 `json:"longitude" binding:"omitempty,min=-180,max=180"`
+	HomeGym    *string  `json:"home_gym" binding:"omitempty,max=100"`
+	TrainingTimes []string `json:"training_times" binding:"omitempty,max=6,dive,oneof=early_morning morning noon afternoon evening night"`
 }
 
 // CreateTrainingPlanRequest 创建训练计划请求
@@ -35,7 +44,7 @@ type CreateTrainingPlanRequest struct {
 	Description    string     `json:"description"`
 	Exercises      []Exercise `json:"exercises" binding:"required"`
 	Duration       int        `json:"duration" binding:"required,min=1"`
-	CaloriesBurned int        `json:"calories_burned" binding:"required,min=1"`
+	CaloriesBurned int        `json:"calories_burned" binding:"min=0"` // 已废弃，服务端按 MET 估算
 	Difficulty     string     `json:"difficulty"`
 	IsPublic       bool       `json:"is_public"`
 }
@@ -54,7 +63,7 @@ type UpdateWorkoutProgressRequest struct {
 type CreatePostRequest struct {
 	Content string   `json:"content" binding:"required"`
 	Images  []string `json:"images"`
-	Type    string   `json:"type"`
+	Type    string   `json:"type" binding:"omitempty,oneof=text image video training"` // 为空时为 text
 }
 
 // CreateCommentRequest 创建评论请求
@@ -67,11 +76,21 @@ type SendMateRequestRequest struct {
 	MateID uint `json:"mate_id" binding:"required"`
 }
 
+// MarkAsReadRequest 标记已读请求，message_id 为空时标记到最新一条消息
+type MarkAsReadRequest struct {
+	MessageID uint `json:"message_id"`
+}
+
 // SendMessageRequest 发送消息请求
+//
+// type 为空时为文本消息；image/location 的 payload 为 ImagePayload/LocationPayload，
+// workout/plan 的 payload 为 WorkoutSharePayload/PlanSharePayload。
 type SendMessageRequest struct {
-	ChatID  uint   `json:"chat_id" binding:"required"`
-	Content string `json:"content" binding:"required"`
-	Type    string `json:"type"`
+	ChatID    uint            `json:"chat_id" binding:"required"`
+	Content   string          `json:"content" binding:"max=5000"`
+	Type      string          `json:"type"`
+	Payload   json.RawMessage `json:"payload"`
+	ReplyToID *uint           `json:"reply_to_id"`
 }
 
 // 响应DTO结构
@@ -121,10 +140,25 @@ type ChatsResponse struct {
 	Pagination Pagination `json:"pagination"`
 }
 
-// MessagesResponse 消息列表响应
+// MessagesResponse 消息列表响应，消息按ID倒序（最新的在前）
 type MessagesResponse struct {
-	Messages   []Message  `json:"messages"`
-	Pagination Pagination `json:"pagination"`
+	Messages          []Message     `json:"messages"`
+	Cursor            MessageCursor `json:"cursor"`
+	LastReadMessageID uint          `json:"last_read_message_id"` // 当前用户已读到的消息ID
+	UnreadCount       int64         `json:"unread_count"`
+}
+
+// MessageCursor 消息游标，用 before 加载更早的消息，用 after 加载更新的消息
+type MessageCursor struct {
+	Before  uint `json:"before"`   // 本页最早一条消息的ID
+	After   uint `json:"after"`    // 本页最新一条消息的ID
+	HasMore bool `json:"has_more"` // 请求方向上是否还有消息
+}
+
+// UnreadCountResponse 未读消息数量
+type UnreadCountResponse struct {
+	Total  int64          `json:"total"`
+	ByChat map[uint]int64 `json:"by_chat"`
 }
 
 // TrainingPlansResponse 训练计划列表响应
@@ -135,14 +169,23 @@ type TrainingPlansResponse struct {
 
 // WorkoutSessionsResponse 训练会话列表响应
 type WorkoutSessionsResponse struct {
-	Sessions   []WorkoutSession `json:"sessions"`
-	Pagination Pagination       `json:"pagination"`
+	Sessions   []WorkoutSession     `json:"sessions"`
+	Items      []WorkoutHistoryItem `json:"items"` // 训练会话和有氧运动按时间倒序合并
+	Pagination Pagination           `json:"pagination"`
 }
 
-// ExercisesResponse 训练动作列表响应
-type ExercisesResponse struct {
-	Exercises  []Exercise  `json:"exercises"`
-	Pagination Pagination  `json:"pagination"`
+// ExerciseLibraryResponse 动作库列表响应
+type ExerciseLibraryResponse struct {
+	Exercises  []ExerciseLibrary `json:"exercises"`
+	Pagination Pagination        `json:"pagination"`
+}
+
+// ExerciseLibraryDetail 动作库条目详情
+type ExerciseLibraryDetail struct {
+	ExerciseLibrary
+	AliasList []string     `json:"alias_list"`
+	Primary   []MuscleInfo `json:"primary"`
+	Secondary []MuscleInfo `json:"secondary"`
 }
 
 // API响应结构
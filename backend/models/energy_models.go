@@ -0,0 +1,45 @@
+package models
+
+// 训练消耗热量来源
+const (
+	CaloriesSourceEstimated = "estimated" // 服务端按 MET 估算
+	CaloriesSourceWearable  = "wearable"  // 手表/手环等穿戴设备提供
+)
+
+// DefaultMET 动作库未设置 MET 且无法按类型判断时使用的值（一般力量训练）
+const DefaultMET = 5.0
+
+// ExerciseTypeMETs 按动作类型的默认 MET（参考 Compendium of Physical Activities）
+var ExerciseTypeMETs = map[string]float64{
+	"compound":  6.0, // 大重量复合动作
+	"isolation": 3.5, // 轻中等强度的孤立动作
+	"cardio":    8.0, // 高强度有氧/增强式
+}
+
+// METForType 获取动作类型的默认 MET
+func METForType(exerciseType string) float64 {
+	if met, ok := ExerciseTypeMETs[exerciseType]; ok {
+		return met
+	}
+	return DefaultMET
+}
+
+// 请求DTO结构
+
+// SessionCaloriesRequest 穿戴设备提供的训练消耗，calories 为空时取消覆盖、恢复估算值
+type SessionCaloriesRequest struct {
+	Calories *int `json:"calories" binding:"omitempty,min=0,max=10000"`
+}
+
+// 响应DTO结构
+
+// EnergyEstimate 训练消耗热量估算
+type EnergyEstimate struct {
+	Calories          int     `json:"calories"`           // 生效的消耗（穿戴设备值优先）
+	EstimatedCalories int     `json:"estimated_calories"` // MET × 体重 × 时长
+	Source            string  `json:"source"`
+	MET               float64 `json:"met"`         // 按各组时长加权的平均 MET
+	Minutes           float64 `json:"minutes"`     // 参与计算的时长
+	BodyWeight        float64 `json:"body_weight"` // 参与计算的体重（kg）
+	Sets              int     `json:"sets"`        // 参与计算的组数
+}
@@ -0,0 +1,114 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 器械配置预设
+const (
+	EquipmentPresetHome    = "home"     // 居家
+	EquipmentPresetHotel   = "hotel"    // 酒店健身房
+	EquipmentPresetFullGym = "full_gym" // 商业健身房
+	EquipmentPresetCustom  = "custom"   // 自定义器械列表
+)
+
+// EquipmentPresets 各预设包含的器械（自重动作始终可用）
+var EquipmentPresets = map[string][]string{
+	EquipmentPresetHome:  {EquipmentBodyweight, EquipmentDumbbell, EquipmentKettlebell, EquipmentPullUpBar},
+	EquipmentPresetHotel: {EquipmentBodyweight, EquipmentDumbbell, EquipmentMachine, EquipmentCable},
+	EquipmentPresetFullGym: {
+		EquipmentBodyweight, EquipmentBarbell, EquipmentDumbbell, EquipmentKettlebell, EquipmentCable,
+		EquipmentMachine, EquipmentPullUpBar, EquipmentDipBars, EquipmentBox,
+	},
+}
+
+// EquipmentProfile 用户器械配置（每个用户同一时间只有一个生效的配置）
+type EquipmentProfile struct {
+	ID        uint           `json:"id" gorm:"primaryKey"`
+	UserID    uint           `json:"user_id" gorm:"not null;index"`
+	Name      string         `json:"name" gorm:"size:50;not null"`
+	Preset    string         `json:"preset" gorm:"size:20;not null"`
+	Equipment string         `json:"equipment" gorm:"type:text"` // JSON字符串存储器械列表
+	IsActive  bool           `json:"is_active" gorm:"default:false"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// EquipmentList 可用器械列表
+func (p EquipmentProfile) EquipmentList() []string {
+	return decodeStringList(p.Equipment)
+}
+
+// Allows 判断器械是否可用
+func (p EquipmentProfile) Allows(equipment string) bool {
+	if equipment == "" || equipment == EquipmentBodyweight {
+		return true
+	}
+	for _, item := range p.EquipmentList() {
+		if item == equipment {
+			return true
+		}
+	}
+	return false
+}
+
+// ResolveEquipment 根据预设和自定义列表得到可用器械，自重始终包含在内
+func ResolveEquipment(preset string, custom []string) []string {
+	source := custom
+	if preset != EquipmentPresetCustom {
+		source = EquipmentPresets[preset]
+	}
+
+	list := []string{EquipmentBodyweight}
+	seen := map[string]bool{EquipmentBodyweight: true}
+	for _, item := range source {
+		if !seen[item] {
+			seen[item] = true
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+// IsKnownEquipment 判断是否为动作库中使用的器械
+func IsKnownEquipment(equipment string) bool {
+	for _, item := range EquipmentPresets[EquipmentPresetFullGym] {
+		if item == equipment {
+			return true
+		}
+	}
+	return false
+}
+
+// 请求DTO结构
+
+// SaveEquipmentProfileRequest 创建/更新器械配置请求
+type SaveEquipmentProfileRequest struct {
+	Name      string   `json:"name" binding:"required,max=50"`
+	Preset    string   `json:"preset" binding:"required,oneof=home hotel full_gym custom"`
+	Equipment []string `json:"equipment"` // preset 为 custom 时必填
+}
+
+// 响应DTO结构
+
+// PlanExerciseSubstitution 切换器械配置时计划动作的替换结果
+type PlanExerciseSubstitution struct {
+	PlanID        uint    `json:"plan_id"`
+	ExerciseID    uint    `json:"exercise_id"`
+	FromLibraryID uint    `json:"from_library_id"`
+	From          string  `json:"from"`
+	ToLibraryID   uint    `json:"to_library_id,omitempty"`
+	To            string  `json:"to,omitempty"`
+	Similarity    float64 `json:"similarity,omitempty"`
+	Reason        string  `json:"reason,omitempty"` // 未能替换时的原因
+}
+
+// ActivateEquipmentProfileResponse 切换器械配置响应
+type ActivateEquipmentProfileResponse struct {
+	Profile     EquipmentProfile           `json:"profile"`
+	Substituted []PlanExerciseSubstitution `json:"substituted"`
+	Unavailable []PlanExerciseSubstitution `json:"unavailable"` // 没有可用替换动作，保留原动作
+}
@@ -0,0 +1,118 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 动作组类型
+const (
+	BlockTypeSuperset = "superset"  // 超级组：2个动作交替进行
+	BlockTypeGiantSet = "giant_set" // 巨型组：3个及以上动作连续进行
+	BlockTypeCircuit  = "circuit"   // 循环训练：多个动作按站点循环
+	BlockTypeEMOM     = "emom"      // 每分钟开始（固定间隔内完成指定次数）
+	BlockTypeAMRAP    = "amrap"     // 限时内尽可能多轮
+)
+
+// ExerciseGroup 训练动作组（超级组、循环、EMOM/AMRAP 等）
+type ExerciseGroup struct {
+	ID               uint           `json:"id" gorm:"primaryKey"`
+	TrainingPartID   uint           `json:"training_part_id" gorm:"not null;index"`
+	BlockType        string         `json:"block_type" gorm:"size:20;not null"`
+	Name             string         `json:"name" gorm:"size:100"`
+	Rounds           int            `json:"rounds" gorm:"default:1"` // 轮数（AMRAP 为0，按实际完成计）
+	WorkSeconds      int            `json:"work_seconds"`            // 每个间隔/站点的工作时间（秒）
+	RestSeconds      int            `json:"rest_seconds"`            // 组内动作之间的休息（秒）
+	RoundRestSeconds int            `json:"round_rest_seconds"`      // 每轮之间的休息（秒）
+	TimeCapSeconds   int            `json:"time_cap_seconds"`        // 时间上限（秒）
+	Exercises        []Exercise     `json:"exercises" gorm:"foreignKey:ExerciseGroupID"`
+	Order            int            `json:"order" gorm:"not null"`
+	CreatedAt        time.Time      `json:"created_at"`
+	UpdatedAt        time.Time      `json:"updated_at"`
+	DeletedAt        gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// WorkoutSetLog 训练会话中的单组记录
+type WorkoutSetLog struct {
+	ID               uint           `json:"id" gorm:"primaryKey"`
+	WorkoutSessionID uint           `json:"workout_session_id" gorm:"not null;index"`
+	UserID           uint           `json:"user_id" gorm:"not null"`
+	ExerciseID       uint           `json:"exercise_id" gorm:"not null"`
+	ExerciseGroupID  *uint          `json:"exercise_group_id"`
+	Round            int            `json:"round"` // 动作组内的第几轮，未分组动作为0
+	SetNumber        int            `json:"set_number"`
+	Reps             int            `json:"reps"`
+	Weight           float64        `json:"weight"`
+	DurationSeconds  int            `json:"duration_seconds"`
+	CompletedAt      time.Time      `json:"completed_at"`
+	CreatedAt        time.Time      `json:"created_at"`
+	DeletedAt        gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// 请求DTO结构
+
+// CreateExerciseGroupRequest 创建训练动作组请求
+type CreateExerciseGroupRequest struct {
+	BlockType        string                  `json:"block_type" binding:"required"`
+	Name             string                  `json:"name"`
+	Rounds           int                     `json:"rounds"`
+	WorkSeconds      int                     `json:"work_seconds"`
+	RestSeconds      int                     `json:"rest_seconds"`
+	RoundRestSeconds int                     `json:"round_rest_seconds"`
+	TimeCapSeconds   int                     `json:"time_cap_seconds"`
+	Exercises        []CreateExerciseRequest `json:"exercises"`
+	Order            int                     `json:"order"`
+}
+
+// Validate 校验动作组结构是否符合其类型
+func (r CreateExerciseGroupRequest) Validate() error {
+	if r.Rounds < 0 || r.WorkSeconds < 0 || r.RestSeconds < 0 || r.RoundRestSeconds < 0 || r.TimeCapSeconds < 0 {
+		return errors.New("动作组的轮数和时间不能为负数")
+	}
+
+	count := len(r.Exercises)
+	switch r.BlockType {
+	case BlockTypeSuperset:
+		if count != 2 {
+			return errors.New("超级组必须包含2个动作")
+		}
+	case BlockTypeGiantSet:
+		if count < 3 {
+			return errors.New("巨型组至少需要3个动作")
+		}
+	case BlockTypeCircuit:
+		if count < 2 {
+			return errors.New("循环训练至少需要2个动作")
+		}
+	case BlockTypeEMOM:
+		if count == 0 {
+			return errors.New("EMOM至少需要1个动作")
+		}
+		if r.WorkSeconds == 0 || r.Rounds == 0 {
+			return errors.New("EMOM需要指定间隔时间和轮数")
+		}
+	case BlockTypeAMRAP:
+		if count == 0 {
+			return errors.New("AMRAP至少需要1个动作")
+		}
+		if r.TimeCapSeconds == 0 {
+			return errors.New("AMRAP需要指定时间上限")
+		}
+	default:
+		return fmt.Errorf("不支持的动作组类型: %s", r.BlockType)
+	}
+	return nil
+}
+
+// LogWorkoutSetRequest 记录训练组请求
+type LogWorkoutSetRequest struct {
+	ExerciseID      uint    `json:"exercise_id" binding:"required"`
+	Round           int     `json:"round" binding:"min=0"`
+	SetNumber       int     `json:"set_number" binding:"min=0"`
+	Reps            int     `json:"reps" binding:"min=0"`
+	Weight          float64 `json:"weight" binding:"min=0"`
+	DurationSeconds int     `json:"duration_seconds" binding:"min=0"`
+}
@@ -1,36 +1,120 @@
 package models
 
 import (
+	"encoding/json"
+	"math"
 	"time"
+
 	"gorm.io/gorm"
 )
 
 // ExerciseLibrary 动作库模型
 type ExerciseLibrary struct {
 	ID          uint           `json:"id" gorm:"primaryKey"`
-	Name        string         `json:"name" gorm:"size:100;not null"`
+	Name        string         `json:"name" gorm:"size:100;not null;index"`
+	NameEN      string         `json:"name_en" gorm:"size:100"`
 	Part        string         `json:"part" gorm:"size:50;not null"`
 	Level       string         `json:"level" gorm:"size:20;default:'intermediate'"`
 	Type        string         `json:"type" gorm:"size:30"`
 	Equipment   string         `json:"equipment" gorm:"size:50"`
 	Tags        string         `json:"tags" gorm:"type:text"` // JSON字符串存储标签
+	Aliases     string         `json:"aliases" gorm:"type:text"` // JSON字符串存储别名
 	Description string         `json:"description" gorm:"type:text"`
 	Instructions string        `json:"instructions" gorm:"type:text"`
 	ImageURL    string         `json:"image_url" gorm:"size:255"`
 	VideoURL    string         `json:"video_url" gorm:"size:255"`
 	MuscleGroups string        `json:"muscle_groups" gorm:"size:100"` // 主要肌群
+	PrimaryMuscles   string    `json:"primary_muscles" gorm:"type:text"`   // JSON字符串存储主要目标肌肉（见 muscle_taxonomy.go）
+	SecondaryMuscles string    `json:"secondary_muscles" gorm:"type:text"` // JSON字符串存储次要目标肌肉
+	MovementPattern  string    `json:"movement_pattern" gorm:"size:30"`
+	MET              float64   `json:"met"` // 代谢当量，用于估算消耗热量
+	Substitutions    []ExerciseSubstitution `json:"substitutions,omitempty" gorm:"foreignKey:ExerciseID"`
 	CreatedAt   time.Time      `json:"created_at"`
 	UpdatedAt   time.Time      `json:"updated_at"`
 	DeletedAt   gorm.DeletedAt `json:"-" gorm:"index"`
 }
 
+// PrimaryMuscleList 主要目标肌肉列表
+func (e ExerciseLibrary) PrimaryMuscleList() []string {
+	return decodeStringList(e.PrimaryMuscles)
+}
+
+// SecondaryMuscleList 次要目标肌肉列表
+func (e ExerciseLibrary) SecondaryMuscleList() []string {
+	return decodeStringList(e.SecondaryMuscles)
+}
+
+// AliasList 别名列表
+func (e ExerciseLibrary) AliasList() []string {
+	return decodeStringList(e.Aliases)
+}
+
+// ExerciseSubstitution 动作替换关系（有向边：ExerciseID 可被 SubstituteID 替换）
+type ExerciseSubstitution struct {
+	ID           uint            `json:"id" gorm:"primaryKey"`
+	ExerciseID   uint            `json:"exercise_id" gorm:"not null;uniqueIndex:idx_substitution_pair"`
+	SubstituteID uint            `json:"substitute_id" gorm:"not null;uniqueIndex:idx_substitution_pair"`
+	Substitute   ExerciseLibrary `json:"substitute" gorm:"foreignKey:SubstituteID"`
+	Similarity   float64         `json:"similarity"` // 0-1，越高越接近
+	CreatedAt    time.Time       `json:"created_at"`
+}
+
+// ExerciseSimilarity 根据动作模式和目标肌肉计算两个动作的相似度（0-1）
+func ExerciseSimilarity(a, b ExerciseLibrary) float64 {
+	score := 0.0
+	if a.MovementPattern != "" && a.MovementPattern == b.MovementPattern {
+		score += 0.4
+	}
+	score += 0.45 * jaccard(a.PrimaryMuscleList(), b.PrimaryMuscleList())
+	score += 0.15 * jaccard(a.SecondaryMuscleList(), b.SecondaryMuscleList())
+	return math.Round(score*100) / 100
+}
+
+func jaccard(a, b []string) float64 {
+	if len(a) == 0 && len(b) == 0 {
+		return 0
+	}
+	set := make(map[string]bool, len(a))
+	for _, item := range a {
+		set[item] = true
+	}
+	intersection := 0
+	union := len(set)
+	for _, item := range b {
+		if set[item] {
+			intersection++
+		} else {
+			union++
+		}
+	}
+	return float64(intersection) / float64(union)
+}
+
+func decodeStringList(data string) []string {
+	var list []string
+	if data != "" {
+		_ = json.Unmarshal([]byte(data), &list)
+	}
+	return list
+}
+
+// EncodeStringList 将字符串列表编码为JSON字符串存储
+func EncodeStringList(list []string) string {
+	if len(list) == 0 {
+		return "[]"
+	}
+	data, _ := json.Marshal(list)
+	return string(data)
+}
+
 // TrainingMode 训练模式
 type TrainingMode struct {
 	ID          uint           `json:"id" gorm:"primaryKey"`
 	UserID      uint           `json:"user_id" gorm:"not null"`
 	User        User           `json:"user" gorm:"foreignKey:UserID"`
-	Mode        string         `json:"mode" gorm:"size:20;not null"` // 三分化/五分化/推拉腿
+	Mode        string         `json:"mode" gorm:"size:20;not null"` // 分化模式，见 SplitDefinitions
 	TrainDays   int            `json:"train_days" gorm:"default:3"` // 每周训练天数
+	SplitTemplateID *uint      `json:"split_template_id"`           // mode 为 custom 时使用的自定义分化模板
 	Target      string         `json:"target" gorm:"size:20"` // 增肌/减脂/综合
 	Level       string         `json:"level" gorm:"size:20"` // 初级/中级/高级
 	IsActive    bool           `json:"is_active" gorm:"default:true"`
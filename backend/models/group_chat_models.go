@@ -0,0 +1,96 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 聊天类型
+const (
+	ChatTypeDirect = "direct"
+	ChatTypeGroup  = "group"
+)
+
+// 群聊角色
+const (
+	ChatRoleOwner  = "owner"
+	ChatRoleAdmin  = "admin"
+	ChatRoleMember = "member"
+)
+
+// 群聊限制
+const (
+	MaxGroupMembers         = 200
+	DefaultInviteExpiryHour = 24
+	MaxInviteExpiryHour     = 24 * 30
+)
+
+// ChatInvite 群聊邀请链接，撤销时软删除
+type ChatInvite struct {
+	ID        uint           `json:"id" gorm:"primaryKey"`
+	ChatID    uint           `json:"chat_id" gorm:"not null;index"`
+	Code      string         `json:"code" gorm:"size:32;not null;uniqueIndex"`
+	CreatedBy uint           `json:"created_by" gorm:"not null"`
+	ExpiresAt time.Time      `json:"expires_at"`
+	MaxUses   int            `json:"max_uses" gorm:"default:0"` // 0 表示不限次数
+	Uses      int            `json:"uses" gorm:"default:0"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// 请求DTO结构
+
+// CreateGroupChatRequest 创建群聊请求，创建者为群主
+type CreateGroupChatRequest struct {
+	Name        string `json:"name" binding:"required,max=50"`
+	Avatar      string `json:"avatar" binding:"max=255"`
+	Description string `json:"description" binding:"max=500"`
+	MemberIDs   []uint `json:"member_ids"`
+}
+
+// UpdateGroupChatRequest 更新群聊资料请求，为空的字段不修改
+type UpdateGroupChatRequest struct {
+	Name        *string `json:"name" binding:"omitempty,min=1,max=50"`
+	Avatar      *string `json:"avatar" binding:"omitempty,max=255"`
+	Description *string `json:"description" binding:"omitempty,max=500"`
+}
+
+// AddGroupMembersRequest 添加群成员请求
+type AddGroupMembersRequest struct {
+	UserIDs []uint `json:"user_ids" binding:"required,min=1"`
+}
+
+// UpdateMemberRoleRequest 设置群成员角色请求
+type UpdateMemberRoleRequest struct {
+	Role string `json:"role" binding:"required,oneof=admin member"`
+}
+
+// TransferGroupRequest 转让群主请求
+type TransferGroupRequest struct {
+	UserID uint `json:"user_id" binding:"required"`
+}
+
+// CreateChatInviteRequest 创建邀请链接请求
+type CreateChatInviteRequest struct {
+	ExpiresInHours int `json:"expires_in_hours" binding:"min=0,max=720"` // 0 表示默认24小时
+	MaxUses        int `json:"max_uses" binding:"min=0,max=1000"`
+}
+
+// 响应DTO结构
+
+// GroupMember 群成员
+type GroupMember struct {
+	User     User       `json:"user"`
+	Role     string     `json:"role"`
+	JoinedAt *time.Time `json:"joined_at"`
+}
+
+// GroupMembersResponse 群成员列表
+type GroupMembersResponse struct {
+	ChatID     uint          `json:"chat_id"`
+	Members    []GroupMember `json:"members"`
+	Total      int           `json:"total"`
+	MaxMembers int           `json:"max_members"`
+}
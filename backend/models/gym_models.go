@@ -0,0 +1,69 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Gym 健身房，用户可以绑定自己常去的健身房
+type Gym struct {
+	ID          uint           `json:"id" gorm:"primaryKey"`
+	Name        string         `json:"name" gorm:"size:100;not null"`
+	Address     string         `json:"address" gorm:"size:255"`
+	City        string         `json:"city" gorm:"size:50;index"`
+	Latitude    float64        `json:"latitude" gorm:"not null"`
+	Longitude   float64        `json:"longitude" gorm:"not null"`
+	Geohash     string         `json:"-" gorm:"size:12;index"`
+	CreatedBy   uint           `json:"created_by" gorm:"not null"`
+	MemberCount int64          `json:"member_count" gorm:"-"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// 同名健身房的去重距离（公里），以及附近健身房和附近的人的默认、最大范围
+const (
+	GymDuplicateRadiusKm  = 0.2
+	DefaultNearbyRadiusKm = 5
+	MaxNearbyRadiusKm     = 50
+)
+
+// 请求DTO结构
+
+// CreateGymRequest 创建健身房请求
+type CreateGymRequest struct {
+	Name      string   `json:"name" binding:"required,max=100"`
+	Address   string   `json:"address" binding:"max=255"`
+	City      string   `json:"city" binding:"max=50"`
+	Latitude  *float64 `json:"latitude" binding:"required,min=-90,max=90"`
+	Longitude *float64 `json:"longitude" binding:"required,min=-180,max=180"`
+}
+
+// 响应DTO结构
+
+// NearbyGym 附近的健身房
+type NearbyGym struct {
+	Gym        Gym     `json:"gym"`
+	DistanceKm float64 `json:"distance_km"`
+}
+
+// NearbyGymsResponse 附近健身房响应
+type NearbyGymsResponse struct {
+	Gyms     []NearbyGym `json:"gyms"`
+	RadiusKm float64     `json:"radius_km"`
+}
+
+// NearbyMate 附近的用户，距离取整公里
+type NearbyMate struct {
+	User       User    `json:"user"`
+	DistanceKm float64 `json:"distance_km"`
+	IsMate     bool    `json:"is_mate"`
+	SameGym    bool    `json:"same_gym"`
+}
+
+// NearbyMatesResponse 附近的人响应
+type NearbyMatesResponse struct {
+	Mates    []NearbyMate `json:"mates"`
+	RadiusKm float64      `json:"radius_km"`
+}
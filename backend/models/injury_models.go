@@ -0,0 +1,109 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 伤病部位
+const (
+	BodyRegionNeck      = "neck"
+	BodyRegionShoulder  = "shoulder"
+	BodyRegionElbow     = "elbow"
+	BodyRegionWrist     = "wrist"
+	BodyRegionLowerBack = "lower_back"
+	BodyRegionHip       = "hip"
+	BodyRegionKnee      = "knee"
+	BodyRegionAnkle     = "ankle"
+)
+
+// 伤病严重程度
+const (
+	InjurySeverityMild     = "mild"     // 轻度：相关动作降低强度
+	InjurySeverityModerate = "moderate" // 中度：排除禁忌动作模式，相关肌群降低强度
+	InjurySeveritySevere   = "severe"   // 重度：排除所有相关动作
+)
+
+// 伤病对动作的处理方式
+const (
+	RestrictionExclude    = "exclude"
+	RestrictionDownWeight = "down_weight"
+)
+
+// InjuryRule 部位伤病的禁忌规则
+type InjuryRule struct {
+	Region   string
+	Name     string
+	Keywords []string // 聊天中识别该部位的关键词
+	Patterns []string // 禁忌动作模式
+	Muscles  []string // 受牵连的主要发力肌群
+	Reason   string
+}
+
+// InjuryRules 各部位伤病的禁忌动作模式和受牵连肌群
+var InjuryRules = []InjuryRule{
+	{BodyRegionNeck, "颈部", []string{"颈", "脖子"}, nil, []string{MuscleTraps}, "耸肩和负重上背动作会牵拉颈部"},
+	{BodyRegionShoulder, "肩部", []string{"肩"}, []string{PatternVerticalPush, PatternHorizontalPush}, []string{MuscleFrontDelts, MuscleSideDelts, MuscleRearDelts}, "推举和卧推时肩关节在负重下大幅活动，容易加重损伤"},
+	{BodyRegionElbow, "肘部", []string{"肘"}, nil, []string{MuscleBiceps, MuscleTriceps, MuscleForearms}, "弯举和臂屈伸类动作集中给肘关节施压"},
+	{BodyRegionWrist, "手腕", []string{"手腕", "腕"}, []string{PatternHorizontalPush}, []string{MuscleForearms}, "撑地和卧推时手腕承受较大压力"},
+	{BodyRegionLowerBack, "腰部", []string{"腰", "下背"}, []string{PatternHinge, PatternSquat}, []string{MuscleLowerBack}, "硬拉、深蹲等动作使脊柱承受较大轴向负荷"},
+	{BodyRegionHip, "髋部", []string{"髋", "胯"}, []string{PatternHinge, PatternLunge}, []string{MuscleGlutes, MuscleAdductors}, "髋铰链和弓步动作需要髋关节大幅屈伸"},
+	{BodyRegionKnee, "膝盖", []string{"膝"}, []string{PatternSquat, PatternLunge, PatternPlyometric}, []string{MuscleQuads}, "深蹲、弓步和跳跃动作使膝关节在负重下屈伸或承受冲击"},
+	{BodyRegionAnkle, "脚踝", []string{"脚踝", "踝"}, []string{PatternPlyometric, PatternLunge}, []string{MuscleCalves}, "跳跃和弓步动作需要踝关节承重稳定"},
+}
+
+// LookupInjuryRule 按部位查找禁忌规则
+func LookupInjuryRule(region string) (InjuryRule, bool) {
+	for _, rule := range InjuryRules {
+		if rule.Region == region {
+			return rule, true
+		}
+	}
+	return InjuryRule{}, false
+}
+
+// InjurySeverityNames 严重程度中文名
+var InjurySeverityNames = map[string]string{
+	InjurySeverityMild:     "轻度",
+	InjurySeverityModerate: "中度",
+	InjurySeveritySevere:   "重度",
+}
+
+// Injury 用户伤病/身体限制
+type Injury struct {
+	ID         uint           `json:"id" gorm:"primaryKey"`
+	UserID     uint           `json:"user_id" gorm:"not null;index"`
+	BodyRegion string         `json:"body_region" gorm:"size:20;not null"`
+	Severity   string         `json:"severity" gorm:"size:20;not null"`
+	StartDate  string         `json:"start_date" gorm:"size:10;not null"` // YYYY-MM-DD
+	EndDate    string         `json:"end_date" gorm:"size:10"`            // 为空表示尚未恢复
+	Notes      string         `json:"notes" gorm:"type:text"`
+	CreatedAt  time.Time      `json:"created_at"`
+	UpdatedAt  time.Time      `json:"updated_at"`
+	DeletedAt  gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// 请求DTO结构
+
+// SaveInjuryRequest 创建/更新伤病请求
+type SaveInjuryRequest struct {
+	BodyRegion string `json:"body_region" binding:"required,oneof=neck shoulder elbow wrist lower_back hip knee ankle"`
+	Severity   string `json:"severity" binding:"required,oneof=mild moderate severe"`
+	StartDate  string `json:"start_date" binding:"required"`
+	EndDate    string `json:"end_date"`
+	Notes      string `json:"notes" binding:"max=500"`
+}
+
+// 响应DTO结构
+
+// ExerciseRestriction 伤病导致的动作排除/降权及原因
+type ExerciseRestriction struct {
+	ExerciseID uint   `json:"exercise_library_id"`
+	Name       string `json:"name"`
+	Action     string `json:"action"` // exclude/down_weight
+	InjuryID   uint   `json:"injury_id"`
+	BodyRegion string `json:"body_region"`
+	Severity   string `json:"severity"`
+	Reason     string `json:"reason"`
+}
@@ -0,0 +1,147 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// 训练时段
+const (
+	TrainingSlotEarlyMorning = "early_morning" // 5-8点
+	TrainingSlotMorning      = "morning"       // 8-12点
+	TrainingSlotNoon         = "noon"          // 12-14点
+	TrainingSlotAfternoon    = "afternoon"     // 14-18点
+	TrainingSlotEvening      = "evening"       // 18-21点
+	TrainingSlotNight        = "night"         // 21-24点
+)
+
+// TrainingSlotNames 训练时段中文名
+var TrainingSlotNames = map[string]string{
+	TrainingSlotEarlyMorning: "清晨",
+	TrainingSlotMorning:      "上午",
+	TrainingSlotNoon:         "中午",
+	TrainingSlotAfternoon:    "下午",
+	TrainingSlotEvening:      "晚上",
+	TrainingSlotNight:        "深夜",
+}
+
+// TrainingSlots 用户填写的训练时段
+func (u User) TrainingSlots() []string {
+	var slots []string
+	for _, slot := range strings.Split(u.TrainingTimes, ",") {
+		if slot = strings.TrimSpace(slot); slot != "" {
+			slots = append(slots, slot)
+		}
+	}
+	return slots
+}
+
+// 搭子关系状态
+const (
+	MateStatusPending   = "pending"   // 等待对方回应
+	MateStatusAccepted  = "accepted"  // 已是搭子
+	MateStatusDeclined  = "declined"  // 对方拒绝了请求
+	MateStatusBlocked   = "blocked"   // 至少一方拉黑了对方
+	MateStatusCancelled = "cancelled" // 请求被撤回、搭子关系被解除或拉黑被取消
+)
+
+// MateRelation 两个用户之间的搭子关系，每对用户只有一行
+//
+// UserLowID 固定为两人中ID较小的一方，避免 A→B、B→A 各有一行；
+// 状态只能通过 services.TransitionMate 变更。
+type MateRelation struct {
+	ID          uint       `json:"id" gorm:"primaryKey"`
+	UserLowID   uint       `json:"user_low_id" gorm:"not null;uniqueIndex:idx_mate_pair"`
+	UserHighID  uint       `json:"user_high_id" gorm:"not null;uniqueIndex:idx_mate_pair;index"`
+	RequesterID uint       `json:"requester_id" gorm:"not null"` // 最近一次发起请求的一方
+	Status      string     `json:"status" gorm:"size:20;not null;index"`
+	LowBlocked  bool       `json:"-" gorm:"not null;default:false"` // UserLowID 一方拉黑了对方
+	HighBlocked bool       `json:"-" gorm:"not null;default:false"`
+	ActorID     uint       `json:"actor_id"`     // 最近一次变更状态的一方
+	RequestedAt *time.Time `json:"requested_at"` // 最近一次发起请求的时间
+	RespondedAt *time.Time `json:"responded_at"` // 接受或拒绝的时间
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// MatePair 按ID大小排列一对用户
+func MatePair(a, b uint) (low, high uint) {
+	if a < b {
+		return a, b
+	}
+	return b, a
+}
+
+// Other 关系中的另一方
+func (r MateRelation) Other(userID uint) uint {
+	if r.UserLowID == userID {
+		return r.UserHighID
+	}
+	return r.UserLowID
+}
+
+// BlockedBy userID 是否拉黑了对方
+func (r MateRelation) BlockedBy(userID uint) bool {
+	if r.UserLowID == userID {
+		return r.LowBlocked
+	}
+	return r.HighBlocked
+}
+
+// 搭子匹配的评分维度
+const (
+	MatchFactorDistance = "distance"
+	MatchFactorGoal     = "goal"
+	MatchFactorLevel    = "level"
+	MatchFactorSchedule = "schedule"
+	MatchFactorGym      = "gym"
+)
+
+// 搭子推荐的距离范围（公里）
+const (
+	DefaultMateRadiusKm = 20
+	MaxMateRadiusKm     = 100
+)
+
+// 响应DTO结构
+
+// MateRequestView 搭子请求，user 为发起方，mate 为接收方
+type MateRequestView struct {
+	ID          uint       `json:"id"`
+	UserID      uint       `json:"user_id"`
+	User        User       `json:"user"`
+	MateID      uint       `json:"mate_id"`
+	Mate        User       `json:"mate"`
+	Status      string     `json:"status"`
+	RespondedAt *time.Time `json:"responded_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// MateStatsResponse 搭子统计
+type MateStatsResponse struct {
+	TotalMates      int64 `json:"total_mates"`
+	PendingRequests int64 `json:"pending_requests"`
+	SentRequests    int64 `json:"sent_requests"`
+}
+
+// MatchReason 推荐理由，score 为该维度的得分（0-1）
+type MatchReason struct {
+	Factor string  `json:"factor"`
+	Score  float64 `json:"score"`
+	Text   string  `json:"text"`
+}
+
+// MateSuggestion 推荐的搭子
+type MateSuggestion struct {
+	User       User          `json:"user"`
+	Score      float64       `json:"score"`       // 0-100
+	DistanceKm *float64      `json:"distance_km"` // 双方都有坐标时返回，保留一位小数
+	Reasons    []MatchReason `json:"reasons"`     // 按得分贡献从高到低
+}
+
+// MateSuggestionsResponse 搭子推荐响应
+type MateSuggestionsResponse struct {
+	Suggestions []MateSuggestion `json:"suggestions"`
+	RadiusKm    float64          `json:"radius_km"`
+}
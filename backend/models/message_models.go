@@ -0,0 +1,198 @@
+package models
+
+import "time"
+
+// 消息类型
+const (
+	MessageTypeText     = "text"
+	MessageTypeImage    = "image"
+	MessageTypeWorkout  = "workout"  // 分享训练记录
+	MessageTypePlan     = "plan"     // 分享一周训练计划
+	MessageTypeLocation = "location" // 分享位置
+	MessageTypeSystem   = "system"   // 成员变动等系统消息，只能由服务端生成
+)
+
+// MessageTypeLabels 消息类型在回复预览和会话列表中的占位文字
+var MessageTypeLabels = map[string]string{
+	MessageTypeImage:    "[图片]",
+	MessageTypeWorkout:  "[训练记录]",
+	MessageTypePlan:     "[训练计划]",
+	MessageTypeLocation: "[位置]",
+}
+
+// 消息限制
+const (
+	MaxMessageLength     = 5000
+	MaxImageSize         = 20 << 20
+	MaxReactionKinds     = 20 // 每条消息最多的表情种类
+	MessagePreviewLength = 100
+)
+
+// RecalledMessageText 撤回后显示的内容
+const RecalledMessageText = "消息已撤回"
+
+// 删除消息的范围
+const (
+	DeleteScopeMe       = "me"       // 仅对自己删除
+	DeleteScopeEveryone = "everyone" // 对所有人删除
+)
+
+// ImagePayload 图片消息，url 指向已上传的图片
+type ImagePayload struct {
+	URL      string `json:"url" binding:"required,url,max=500"`
+	Width    int    `json:"width" binding:"min=0,max=20000"`
+	Height   int    `json:"height" binding:"min=0,max=20000"`
+	Size     int64  `json:"size" binding:"min=0,max=20971520"`
+	MimeType string `json:"mime_type" binding:"omitempty,oneof=image/jpeg image/png image/gif image/webp image/heic"`
+}
+
+// LocationPayload 位置消息
+type LocationPayload struct {
+	Latitude  float64 `json:"latitude" binding:"min=-90,max=90"`
+	Longitude float64 `json:"longitude" binding:"min=-180,max=180"`
+	Name      string  `json:"name" binding:"max=100"`
+	Address   string  `json:"address" binding:"max=255"`
+}
+
+// WorkoutShareCard 训练记录分享卡片，发送时生成快照，之后修改或删除记录不影响已发送的消息
+type WorkoutShareCard struct {
+	SessionID       uint       `json:"session_id"`
+	PlanName        string     `json:"plan_name"`
+	Status          string     `json:"status"`
+	StartTime       time.Time  `json:"start_time"`
+	EndTime         *time.Time `json:"end_time"`
+	DurationMinutes int        `json:"duration_minutes"`
+	Calories        int        `json:"calories"`
+	Progress        int        `json:"progress"`
+	ExerciseCount   int        `json:"exercise_count"`
+}
+
+// PlanShareCard 一周训练计划分享卡片，发送时生成快照
+type PlanShareCard struct {
+	PlanID        uint     `json:"plan_id"`
+	OwnerID       uint     `json:"owner_id"`
+	Name          string   `json:"name"`
+	Description   string   `json:"description"`
+	TrainingDays  int      `json:"training_days"` // 非休息日天数
+	ExerciseCount int      `json:"exercise_count"`
+	MuscleGroups  []string `json:"muscle_groups"`
+	IsPublic      bool     `json:"is_public"`
+}
+
+// MessagePreview 被回复消息的摘要
+type MessagePreview struct {
+	ID         uint   `json:"id"`
+	SenderID   uint   `json:"sender_id"`
+	SenderName string `json:"sender_name"`
+	Type       string `json:"type"`
+	Content    string `json:"content"` // 截断后的文本，非文本消息为占位文字
+}
+
+// MessageReaction 消息表情回应
+type MessageReaction struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	MessageID uint      `json:"message_id" gorm:"not null;uniqueIndex:idx_message_reaction"`
+	UserID    uint      `json:"user_id" gorm:"not null;uniqueIndex:idx_message_reaction"`
+	Emoji     string    `json:"emoji" gorm:"size:32;not null;uniqueIndex:idx_message_reaction"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ReactionSummary 同一表情的回应汇总，按第一次回应的时间排序
+type ReactionSummary struct {
+	Emoji   string `json:"emoji"`
+	Count   int    `json:"count"`
+	UserIDs []uint `json:"user_ids"`
+	Reacted bool   `json:"reacted"` // 当前用户是否回应过
+}
+
+// MessageEdit 消息编辑历史，保存每次编辑前的内容
+type MessageEdit struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	MessageID uint      `json:"message_id" gorm:"not null;index"`
+	Content   string    `json:"content" gorm:"type:text"`
+	EditedBy  uint      `json:"edited_by"`
+	CreatedAt time.Time `json:"created_at"` // 被替换的时间
+}
+
+// MessageDeletion 用户仅对自己删除的消息
+type MessageDeletion struct {
+	MessageID uint      `json:"message_id" gorm:"primaryKey"`
+	UserID    uint      `json:"user_id" gorm:"primaryKey"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// MessageSearchDocument 消息全文索引，tokens 为空格分隔的索引词（汉字按单字和两字组合切分）
+type MessageSearchDocument struct {
+	MessageID uint   `json:"message_id" gorm:"primaryKey;autoIncrement:false"`
+	ChatID    uint   `json:"chat_id" gorm:"not null;index"`
+	Tokens    string `json:"tokens" gorm:"type:text"`
+}
+
+// 请求DTO结构
+
+// EditMessageRequest 编辑消息请求，文本消息内容不能为空，其他类型只修改说明文字
+type EditMessageRequest struct {
+	Content string `json:"content" binding:"max=5000"`
+}
+
+// WorkoutSharePayload 分享训练记录请求内容
+type WorkoutSharePayload struct {
+	SessionID uint `json:"session_id" binding:"required"`
+}
+
+// PlanSharePayload 分享训练计划请求内容
+type PlanSharePayload struct {
+	PlanID uint `json:"plan_id" binding:"required"`
+}
+
+// AddReactionRequest 添加表情回应请求
+type AddReactionRequest struct {
+	Emoji string `json:"emoji" binding:"required,max=32"`
+}
+
+// 响应DTO结构
+
+// MessageEditsResponse 消息编辑历史，按编辑时间倒序
+type MessageEditsResponse struct {
+	MessageID uint          `json:"message_id"`
+	Current   string        `json:"current"`
+	EditedAt  *time.Time    `json:"edited_at"`
+	Edits     []MessageEdit `json:"edits"`
+}
+
+// HighlightRange 片段中需要高亮的区间，按字符（rune）计算，不含 End
+type HighlightRange struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+// MessageSearchResult 一条搜索结果
+type MessageSearchResult struct {
+	Message    Message          `json:"message"`
+	ChatID     uint             `json:"chat_id"`
+	ChatType   string           `json:"chat_type"`
+	ChatName   string           `json:"chat_name"` // 单聊为对方的名字
+	Snippet    string           `json:"snippet"`
+	Highlights []HighlightRange `json:"highlights"`
+	Before     []MessagePreview `json:"before"` // 前面的消息，按时间正序
+	After      []MessagePreview `json:"after"`  // 后面的消息，按时间正序
+}
+
+// MessageSearchResponse 消息搜索响应，按消息ID倒序，用 cursor.before 加载更早的结果
+type MessageSearchResponse struct {
+	Query   string                `json:"query"`
+	Results []MessageSearchResult `json:"results"`
+	Cursor  MessageCursor         `json:"cursor"`
+}
+
+// MessageDeletedEvent 消息被删除的实时事件内容
+type MessageDeletedEvent struct {
+	MessageID uint   `json:"message_id"`
+	Scope     string `json:"scope"`
+}
+
+// MessageReactionsEvent 表情回应变化的实时事件内容
+type MessageReactionsEvent struct {
+	MessageID uint              `json:"message_id"`
+	Reactions []ReactionSummary `json:"reactions"`
+}
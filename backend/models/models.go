@@ -17,8 +17,17 @@ type User struct {
 	Age       int            `json:"age"`
 	Height    float64        `json:"height"`
 	Weight    float64        `json:"weight"`
+	Gender    string         `json:"gender" gorm:"size:10"` // male/female，用于计算基础代谢
 	Goal      string         `json:"goal" gorm:"size:50"`
 	Experience string        `json:"experience" gorm:"size:50"`
+	Timezone  string         `json:"timezone" gorm:"size:64;default:'Asia/Shanghai'"` // IANA时区，如 Asia/Shanghai
+	Latitude  *float64       `json:"-"` // 粗化到约1公里，只用于计算距离，不对外返回
+	Longitude *float64       `json:"-"`
+	Geohash   string         `json:"-" gorm:"size:12;index"`
+	GymID     *uint          `json:"gym_id" gorm:"index"`
+	HomeGym   string         `json:"home_gym" gorm:"size:100"`
+	TrainingTimes string     `json:"training_times" gorm:"size:100"` // 常训练的时段，逗号分隔，见 TrainingSlot*
+	ProfileRestricted bool   `json:"profile_restricted,omitempty" gorm:"-"` // 资料按隐私设置隐藏了详细信息
 	CreatedAt time.Time      `json:"created_at"`
 	UpdatedAt time.Time      `json:"updated_at"`
 	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
@@ -33,7 +42,7 @@ type TrainingPlan struct {
 	Description   string         `json:"description" gorm:"type:text"`
 	Exercises     []Exercise     `json:"exercises" gorm:"foreignKey:TrainingPlanID"`
 	Duration      int            `json:"duration" gorm:"not null"`
-	CaloriesBurned int           `json:"calories_burned" gorm:"not null"`
+	CaloriesBurned int           `json:"calories_burned" gorm:"not null"` // 按动作 MET 和创建者体重估算
 	Difficulty    string         `json:"difficulty" gorm:"size:20;default:'beginner'"`
 	IsPublic      bool           `json:"is_public" gorm:"default:false"`
 	CreatedAt     time.Time      `json:"created_at"`
@@ -48,6 +57,9 @@ type Exercise struct {
 	TrainingPlan    TrainingPlan   `json:"training_plan" gorm:"foreignKey:TrainingPlanID"`
 	TrainingPartID  *uint          `json:"training_part_id"` // 新增：关联到训练部位
 	TrainingPart    *TrainingPart  `json:"training_part" gorm:"foreignKey:TrainingPartID"`
+	ExerciseGroupID *uint          `json:"exercise_group_id" gorm:"index"` // 所属动作组（超级组/循环等），为空表示常规组
+	ExerciseLibraryID *uint          `json:"exercise_library_id" gorm:"index"` // 关联的标准动作，为空表示自定义动作
+	Library           *ExerciseLibrary `json:"library,omitempty" gorm:"foreignKey:ExerciseLibraryID"`
 	Name            string         `json:"name" gorm:"size:100;not null"`
 	Description     string         `json:"description" gorm:"type:text"`
 	MuscleGroup     string         `json:"muscle_group" gorm:"size:50"`
@@ -56,13 +68,13 @@ type Exercise struct {
 	Sets            int            `json:"sets" gorm:"not null"`
 	Reps            int            `json:"reps" gorm:"not null"`
 	Weight          float64        `json:"weight"`
-	Duration        int            `json:"duration"`
+	Duration        int            `json:"duration"` // 计时动作每组时长（秒）
 	RestTime        int            `json:"rest_time"`
 	RestSeconds     int            `json:"rest_seconds"` // 新增：休息时间（秒）
 	Instructions    string         `json:"instructions" gorm:"type:text"`
 	ImageURL        string         `json:"image_url" gorm:"size:255"`
 	VideoURL        string         `json:"video_url" gorm:"size:255"`
-	Calories        int            `json:"calories" gorm:"default:50"`
+	Calories        int            `json:"calories" gorm:"default:50"` // 创建计划时按 MET 估算
 	Notes           string         `json:"notes" gorm:"type:text"`
 	IsCompleted     bool           `json:"is_completed" gorm:"default:false"`
 	CompletedAt     *time.Time     `json:"completed_at"`
@@ -83,7 +95,9 @@ type WorkoutSession struct {
 	EndTime       *time.Time     `json:"end_time"`
 	Status        string         `json:"status" gorm:"size:20;default:'ongoing'"`
 	Progress      int            `json:"progress" gorm:"default:0"`
-	TotalCalories int            `json:"total_calories" gorm:"default:0"`
+	TotalCalories int            `json:"total_calories" gorm:"default:0"` // 生效的消耗，穿戴设备值优先于估算值
+	EstimatedCalories int        `json:"estimated_calories" gorm:"default:0"` // 按 MET、体重和训练时长估算
+	CaloriesSource string        `json:"calories_source" gorm:"size:20;default:'estimated'"`
 	Notes         string         `json:"notes" gorm:"type:text"`
 	CreatedAt     time.Time      `json:"created_at"`
 	UpdatedAt     time.Time      `json:"updated_at"`
@@ -107,6 +121,14 @@ type Post struct {
 	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
 }
 
+// 帖子类型，training 为训练动态，按发帖人的训练可见范围过滤
+const (
+	PostTypeText     = "text"
+	PostTypeImage    = "image"
+	PostTypeVideo    = "video"
+	PostTypeTraining = "training"
+)
+
 // Comment 评论模型
 type Comment struct {
 	ID        uint           `json:"id" gorm:"primaryKey"`
@@ -132,25 +154,17 @@ type PostLike struct {
 	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
 }
 
-// Mate 搭子关系模型
-type Mate struct {
-	ID        uint           `json:"id" gorm:"primaryKey"`
-	UserID    uint           `json:"user_id" gorm:"not null"`
-	User      User           `json:"user" gorm:"foreignKey:UserID"`
-	MateID    uint           `json:"mate_id" gorm:"not null"`
-	Mate      User           `json:"mate" gorm:"foreignKey:MateID"`
-	Status    string         `json:"status" gorm:"size:20;default:'pending'"`
-	CreatedAt time.Time      `json:"created_at"`
-	UpdatedAt time.Time      `json:"updated_at"`
-	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
-}
-
 // Chat 聊天模型
 type Chat struct {
 	ID           uint           `json:"id" gorm:"primaryKey"`
+	Type         string         `json:"type" gorm:"size:20;default:'direct'"` // direct/group
+	Name         string         `json:"name" gorm:"size:50"`                  // 群聊名称
+	Avatar       string         `json:"avatar" gorm:"size:255"`
+	Description  string         `json:"description" gorm:"size:500"`
+	OwnerID      *uint          `json:"owner_id"` // 群主
 	Participants []User         `json:"participants" gorm:"many2many:chat_participants"`
-	LastMessage  *Message       `json:"last_message"`
-	UnreadCount  int            `json:"unread_count" gorm:"default:0"`
+	LastMessage  *Message       `json:"last_message" gorm:"-"` // 聊天中最新的一条消息
+	UnreadCount  int64          `json:"unread_count" gorm:"-"`  // 当前用户的未读数，按 ChatParticipant.LastReadMessageID 计算
 	CreatedAt    time.Time      `json:"created_at"`
 	UpdatedAt    time.Time      `json:"updated_at"`
 	DeletedAt    gorm.DeletedAt `json:"-" gorm:"index"`
@@ -163,9 +177,20 @@ type Message struct {
 	Chat      Chat           `json:"chat" gorm:"foreignKey:ChatID"`
 	SenderID  uint           `json:"sender_id" gorm:"not null"`
 	Sender    User           `json:"sender" gorm:"foreignKey:SenderID"`
-	Content   string         `json:"content" gorm:"type:text;not null"`
+	Content   string         `json:"content" gorm:"type:text;not null"` // 文本内容，其他类型时为可选的说明文字
 	Type      string         `json:"type" gorm:"size:20;default:'text'"`
+	Payload   string         `json:"-" gorm:"type:text"` // JSON字符串存储类型相关的内容，按类型解析到下面的字段
+	ReplyToID *uint          `json:"reply_to_id" gorm:"index"`
 	IsRead    bool           `json:"is_read" gorm:"default:false"`
+	EditedAt   *time.Time    `json:"edited_at"`   // 最后一次编辑时间，为空表示未编辑
+	RecalledAt *time.Time    `json:"recalled_at"` // 撤回时间，撤回后内容被清空
+	RecalledBy *uint         `json:"recalled_by"` // 撤回人，群管理员可以撤回成员的消息
+	ReplyTo   *MessagePreview   `json:"reply_to,omitempty" gorm:"-"`
+	Image     *ImagePayload     `json:"image,omitempty" gorm:"-"`
+	Workout   *WorkoutShareCard `json:"workout,omitempty" gorm:"-"`
+	Plan      *PlanShareCard    `json:"plan,omitempty" gorm:"-"`
+	Location  *LocationPayload  `json:"location,omitempty" gorm:"-"`
+	Reactions []ReactionSummary `json:"reactions" gorm:"-"`
 	CreatedAt time.Time      `json:"created_at"`
 	UpdatedAt time.Time      `json:"updated_at"`
 	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
@@ -173,10 +198,14 @@ type Message struct {
 
 // ChatParticipant 聊天参与者模型
 type ChatParticipant struct {
-	ChatID uint `json:"chat_id" gorm:"primaryKey"`
-	UserID uint `json:"user_id" gorm:"primaryKey"`
-	Chat   Chat `json:"chat" gorm:"foreignKey:ChatID"`
-	User   User `json:"user" gorm:"foreignKey:UserID"`
+	ChatID            uint       `json:"chat_id" gorm:"primaryKey"`
+	UserID            uint       `json:"user_id" gorm:"primaryKey"`
+	Chat              Chat       `json:"chat" gorm:"foreignKey:ChatID"`
+	User              User       `json:"user" gorm:"foreignKey:UserID"`
+	LastReadMessageID uint       `json:"last_read_message_id" gorm:"default:0"` // 已读到的消息ID，之后其他人发送的消息为未读
+	LastReadAt        *time.Time `json:"last_read_at"`
+	Role              string     `json:"role" gorm:"size:20;default:'member'"` // 群聊角色：owner/admin/member
+	JoinedAt          *time.Time `json:"joined_at"`
 }
 
 // Achievement 成就模型
@@ -0,0 +1,128 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 每周训练量状态（相对 MEV/MRV）
+const (
+	VolumeStatusUnder   = "under"   // 低于最低有效训练量
+	VolumeStatusOptimal = "optimal" // 介于 MEV 和 MRV 之间
+	VolumeStatusOver    = "over"    // 超过最大可恢复训练量
+)
+
+// RecoveredThreshold 恢复度达到该值视为已恢复，可以再次训练
+const RecoveredThreshold = 0.8
+
+// VolumeTarget 每周硬组数目标
+type VolumeTarget struct {
+	MEV int `json:"mev"` // 最低有效训练量 Minimum Effective Volume
+	MRV int `json:"mrv"` // 最大可恢复训练量 Maximum Recoverable Volume
+}
+
+// DefaultVolumeTargets 各肌肉默认的每周硬组数目标
+var DefaultVolumeTargets = map[string]VolumeTarget{
+	MuscleChest:      {8, 22},
+	MuscleLats:       {8, 25},
+	MuscleUpperBack:  {8, 25},
+	MuscleTraps:      {4, 26},
+	MuscleLowerBack:  {2, 12},
+	MuscleFrontDelts: {2, 12},
+	MuscleSideDelts:  {8, 26},
+	MuscleRearDelts:  {6, 26},
+	MuscleBiceps:     {8, 26},
+	MuscleTriceps:    {6, 18},
+	MuscleForearms:   {2, 20},
+	MuscleAbs:        {4, 20},
+	MuscleObliques:   {2, 16},
+	MuscleQuads:      {8, 20},
+	MuscleHamstrings: {6, 20},
+	MuscleGlutes:     {4, 16},
+	MuscleAdductors:  {2, 16},
+	MuscleCalves:     {8, 20},
+}
+
+// MuscleRecoveryHours 各肌肉从一次高强度训练中基本恢复所需的小时数
+var MuscleRecoveryHours = map[string]float64{
+	MuscleChest:      72,
+	MuscleLats:       72,
+	MuscleUpperBack:  72,
+	MuscleTraps:      48,
+	MuscleLowerBack:  72,
+	MuscleFrontDelts: 48,
+	MuscleSideDelts:  48,
+	MuscleRearDelts:  48,
+	MuscleBiceps:     48,
+	MuscleTriceps:    48,
+	MuscleForearms:   36,
+	MuscleAbs:        36,
+	MuscleObliques:   36,
+	MuscleQuads:      72,
+	MuscleHamstrings: 72,
+	MuscleGlutes:     72,
+	MuscleAdductors:  48,
+	MuscleCalves:     36,
+}
+
+// MuscleVolumeTarget 用户自定义的每周训练量目标，未设置的肌肉使用默认值
+type MuscleVolumeTarget struct {
+	ID        uint           `json:"id" gorm:"primaryKey"`
+	UserID    uint           `json:"user_id" gorm:"not null;uniqueIndex:idx_volume_target_muscle"`
+	Muscle    string         `json:"muscle" gorm:"size:30;not null;uniqueIndex:idx_volume_target_muscle"`
+	MEV       int            `json:"mev"`
+	MRV       int            `json:"mrv"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// 请求DTO结构
+
+// VolumeTargetItem 单个肌肉的训练量目标
+type VolumeTargetItem struct {
+	Muscle string `json:"muscle" binding:"required"`
+	MEV    int    `json:"mev" binding:"min=0"`
+	MRV    int    `json:"mrv" binding:"required,min=1,max=60"`
+}
+
+// SaveVolumeTargetsRequest 保存训练量目标请求
+type SaveVolumeTargetsRequest struct {
+	Targets []VolumeTargetItem `json:"targets" binding:"required,min=1"`
+}
+
+// 响应DTO结构
+
+// MuscleVolumeTargetResponse 肌肉训练量目标（含是否为默认值）
+type MuscleVolumeTargetResponse struct {
+	Muscle    string `json:"muscle"`
+	Name      string `json:"name"`
+	MEV       int    `json:"mev"`
+	MRV       int    `json:"mrv"`
+	IsDefault bool   `json:"is_default"`
+}
+
+// MuscleRecoveryStatus 单个肌肉的恢复和训练量状态
+type MuscleRecoveryStatus struct {
+	Muscle            string     `json:"muscle"`
+	Name              string     `json:"name"`
+	Part              string     `json:"part"`
+	WeeklySets        float64    `json:"weekly_sets"`   // 最近7天硬组数（次要发力肌群按半组计）
+	WeeklyVolume      float64    `json:"weekly_volume"` // 最近7天作为主要发力肌群的训练容量（次数×重量，kg）
+	MEV               int        `json:"mev"`
+	MRV               int        `json:"mrv"`
+	VolumeStatus      string     `json:"volume_status"` // under/optimal/over
+	Recovery          float64    `json:"recovery"`      // 恢复度 0-100
+	Recovered         bool       `json:"recovered"`
+	LastTrainedAt     *time.Time `json:"last_trained_at"`
+	HoursSinceTrained *float64   `json:"hours_since_trained"`
+	Priority          float64    `json:"priority"` // 推荐优先级 0-1：越缺训练量且恢复越好越高
+}
+
+// MuscleHeatmapResponse 肌肉恢复热力图
+type MuscleHeatmapResponse struct {
+	GeneratedAt time.Time              `json:"generated_at"`
+	WindowStart time.Time              `json:"window_start"` // 训练量统计起点（最近7天）
+	Muscles     []MuscleRecoveryStatus `json:"muscles"`
+}
@@ -0,0 +1,119 @@
+package models
+
+// 肌肉分类（动作库 primary_muscles / secondary_muscles 中使用的键）
+const (
+	MuscleChest      = "chest"
+	MuscleLats       = "lats"
+	MuscleUpperBack  = "upper_back"
+	MuscleTraps      = "traps"
+	MuscleLowerBack  = "lower_back"
+	MuscleFrontDelts = "front_delts"
+	MuscleSideDelts  = "side_delts"
+	MuscleRearDelts  = "rear_delts"
+	MuscleBiceps     = "biceps"
+	MuscleTriceps    = "triceps"
+	MuscleForearms   = "forearms"
+	MuscleAbs        = "abs"
+	MuscleObliques   = "obliques"
+	MuscleQuads      = "quads"
+	MuscleHamstrings = "hamstrings"
+	MuscleGlutes     = "glutes"
+	MuscleAdductors  = "adductors"
+	MuscleCalves     = "calves"
+)
+
+// 动作模式
+const (
+	PatternHorizontalPush = "horizontal_push"
+	PatternVerticalPush   = "vertical_push"
+	PatternHorizontalPull = "horizontal_pull"
+	PatternVerticalPull   = "vertical_pull"
+	PatternSquat          = "squat"
+	PatternHinge          = "hinge"
+	PatternLunge          = "lunge"
+	PatternCarry          = "carry"
+	PatternCore           = "core"
+	PatternIsolation      = "isolation"
+	PatternPlyometric     = "plyometric"
+)
+
+// MovementPatternNames 动作模式中文名
+var MovementPatternNames = map[string]string{
+	PatternHorizontalPush: "水平推",
+	PatternVerticalPush:   "垂直推",
+	PatternHorizontalPull: "水平拉",
+	PatternVerticalPull:   "垂直拉",
+	PatternSquat:          "蹲",
+	PatternHinge:          "髋铰链",
+	PatternLunge:          "弓步",
+	PatternCarry:          "负重行走",
+	PatternCore:           "核心",
+	PatternIsolation:      "孤立",
+	PatternPlyometric:     "跳跃",
+}
+
+// 器械
+const (
+	EquipmentBodyweight = "bodyweight"
+	EquipmentBarbell    = "barbell"
+	EquipmentDumbbell   = "dumbbell"
+	EquipmentKettlebell = "kettlebell"
+	EquipmentCable      = "cable"
+	EquipmentMachine    = "machine"
+	EquipmentPullUpBar  = "pull_up_bar"
+	EquipmentDipBars    = "dip_bars"
+	EquipmentBox        = "box"
+)
+
+// EquipmentNames 器械中文名
+var EquipmentNames = map[string]string{
+	EquipmentBodyweight: "自重",
+	EquipmentBarbell:    "杠铃",
+	EquipmentDumbbell:   "哑铃",
+	EquipmentKettlebell: "壶铃",
+	EquipmentCable:      "绳索",
+	EquipmentMachine:    "器械",
+	EquipmentPullUpBar:  "单杠",
+	EquipmentDipBars:    "双杠",
+	EquipmentBox:        "跳箱",
+}
+
+// MuscleInfo 肌肉信息
+type MuscleInfo struct {
+	Key    string `json:"key"`
+	Name   string `json:"name"`
+	NameEN string `json:"name_en"`
+	Part   string `json:"part"` // 所属训练部位：chest/back/legs/shoulders/arms/core
+}
+
+// Muscles 全部肌肉分类，顺序即展示顺序
+var Muscles = []MuscleInfo{
+	{MuscleChest, "胸大肌", "Chest", "chest"},
+	{MuscleLats, "背阔肌", "Lats", "back"},
+	{MuscleUpperBack, "上背部", "Upper Back", "back"},
+	{MuscleTraps, "斜方肌", "Traps", "back"},
+	{MuscleLowerBack, "下背部", "Lower Back", "back"},
+	{MuscleFrontDelts, "三角肌前束", "Front Delts", "shoulders"},
+	{MuscleSideDelts, "三角肌中束", "Side Delts", "shoulders"},
+	{MuscleRearDelts, "三角肌后束", "Rear Delts", "shoulders"},
+	{MuscleBiceps, "肱二头肌", "Biceps", "arms"},
+	{MuscleTriceps, "肱三头肌", "Triceps", "arms"},
+	{MuscleForearms, "前臂", "Forearms", "arms"},
+	{MuscleAbs, "腹直肌", "Abs", "core"},
+	{MuscleObliques, "腹斜肌", "Obliques", "core"},
+	{MuscleQuads, "股四头肌", "Quads", "legs"},
+	{MuscleHamstrings, "腘绳肌", "Hamstrings", "legs"},
+	{MuscleGlutes, "臀肌", "Glutes", "legs"},
+	{MuscleAdductors, "内收肌", "Adductors", "legs"},
+	{MuscleCalves, "小腿", "Calves", "legs"},
+}
+
+// LookupMuscle 按键查找肌肉信息
+func LookupMuscle(key string) (MuscleInfo, bool) {
+	for _, muscle := range Muscles {
+		if muscle.Key == key {
+			return muscle, true
+		}
+	}
+	return MuscleInfo{}, false
+}
@@ -0,0 +1,155 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 性别（用于 Mifflin-St Jeor 基础代谢计算）
+const (
+	GenderMale   = "male"
+	GenderFemale = "female"
+)
+
+// 餐次
+const (
+	MealBreakfast = "breakfast"
+	MealLunch     = "lunch"
+	MealDinner    = "dinner"
+	MealSnack     = "snack"
+)
+
+// MealTypeNames 餐次中文名
+var MealTypeNames = map[string]string{
+	MealBreakfast: "早餐",
+	MealLunch:     "午餐",
+	MealDinner:    "晚餐",
+	MealSnack:     "加餐",
+}
+
+// Food 食物，UserID 为空的是系统内置食物，否则为用户自建
+type Food struct {
+	ID          uint           `json:"id" gorm:"primaryKey"`
+	UserID      *uint          `json:"user_id,omitempty" gorm:"index"`
+	Name        string         `json:"name" gorm:"size:100;not null;index"`
+	ServingSize float64        `json:"serving_size" gorm:"not null"`            // 每份的量
+	ServingUnit string         `json:"serving_unit" gorm:"size:20;default:'g'"` // g/ml/个/片...
+	Calories    float64        `json:"calories"`                                // 每份热量（kcal）
+	Protein     float64        `json:"protein"`                                 // 每份蛋白质（g）
+	Carbs       float64        `json:"carbs"`                                   // 每份碳水（g）
+	Fat         float64        `json:"fat"`                                     // 每份脂肪（g）
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// Meal 一餐记录，Date 为用户时区下的日期
+type Meal struct {
+	ID        uint           `json:"id" gorm:"primaryKey"`
+	UserID    uint           `json:"user_id" gorm:"not null;index:idx_meal_user_date"`
+	Date      string         `json:"date" gorm:"size:10;not null;index:idx_meal_user_date"` // YYYY-MM-DD
+	MealType  string         `json:"meal_type" gorm:"size:20;not null"`
+	Notes     string         `json:"notes" gorm:"type:text"`
+	Items     []MealItem     `json:"items" gorm:"foreignKey:MealID"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// MealItem 一餐中的一种食物，营养数值在记录时按份数计算并保存，之后修改食物不影响历史记录
+type MealItem struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	MealID    uint      `json:"meal_id" gorm:"not null;index"`
+	FoodID    uint      `json:"food_id" gorm:"not null"`
+	FoodName  string    `json:"food_name" gorm:"size:100"`
+	Servings  float64   `json:"servings" gorm:"not null"`
+	Calories  float64   `json:"calories"`
+	Protein   float64   `json:"protein"`
+	Carbs     float64   `json:"carbs"`
+	Fat       float64   `json:"fat"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Macros 热量与三大营养素
+type Macros struct {
+	Calories float64 `json:"calories"`
+	Protein  float64 `json:"protein"`
+	Carbs    float64 `json:"carbs"`
+	Fat      float64 `json:"fat"`
+}
+
+// Add 累加
+func (m *Macros) Add(other Macros) {
+	m.Calories += other.Calories
+	m.Protein += other.Protein
+	m.Carbs += other.Carbs
+	m.Fat += other.Fat
+}
+
+// Macros 一餐食物的营养数值
+func (i MealItem) Macros() Macros {
+	return Macros{Calories: i.Calories, Protein: i.Protein, Carbs: i.Carbs, Fat: i.Fat}
+}
+
+// 请求DTO结构
+
+// CreateFoodRequest 创建自定义食物请求
+type CreateFoodRequest struct {
+	Name        string  `json:"name" binding:"required,max=100"`
+	ServingSize float64 `json:"serving_size" binding:"required,gt=0"`
+	ServingUnit string  `json:"serving_unit" binding:"max=20"`
+	Calories    float64 `json:"calories" binding:"gte=0"`
+	Protein     float64 `json:"protein" binding:"gte=0"`
+	Carbs       float64 `json:"carbs" binding:"gte=0"`
+	Fat         float64 `json:"fat" binding:"gte=0"`
+}
+
+// MealItemRequest 记录一种食物
+type MealItemRequest struct {
+	FoodID   uint    `json:"food_id" binding:"required"`
+	Servings float64 `json:"servings" binding:"required,gt=0"`
+}
+
+// CreateMealRequest 记录一餐请求，Date 为空时取用户时区的今天
+type CreateMealRequest struct {
+	Date     string            `json:"date"`
+	MealType string            `json:"meal_type" binding:"required,oneof=breakfast lunch dinner snack"`
+	Notes    string            `json:"notes" binding:"max=500"`
+	Items    []MealItemRequest `json:"items" binding:"required,min=1,dive"`
+}
+
+// 响应DTO结构
+
+// NutritionTarget 每日热量与营养素目标
+type NutritionTarget struct {
+	BMR              float64 `json:"bmr"`                         // Mifflin-St Jeor 基础代谢
+	ActivityFactor   float64 `json:"activity_factor"`             // 按最近4周完成的训练次数或训练消耗估算
+	WeeklySessions   float64 `json:"weekly_sessions"`             // 最近4周平均每周完成的训练次数
+	ExerciseCalories float64 `json:"exercise_calories,omitempty"` // 最近4周平均每天的训练消耗
+	TDEE             float64 `json:"tdee"`                        // 每日总消耗
+	Goal             string  `json:"goal"`
+	Macros
+}
+
+// DailyNutritionSummary 每日摄入汇总
+type DailyNutritionSummary struct {
+	Date      string            `json:"date"`
+	Intake    Macros            `json:"intake"`
+	ByMeal    map[string]Macros `json:"by_meal"`
+	Target    *NutritionTarget  `json:"target,omitempty"`    // 资料不完整时为空
+	Remaining *Macros           `json:"remaining,omitempty"` // 目标减去已摄入
+	Meals     []Meal            `json:"meals"`
+}
+
+// WeeklyNutritionSummary 每周摄入汇总（周一至周日）
+type WeeklyNutritionSummary struct {
+	WeekStart   string                  `json:"week_start"`
+	WeekEnd     string                  `json:"week_end"`
+	Total       Macros                  `json:"total"`
+	DailyAvg    Macros                  `json:"daily_average"` // 按有记录的天数平均
+	LoggedDays  int                     `json:"logged_days"`
+	Target      *NutritionTarget        `json:"target,omitempty"`
+	DaysOnTrack int                     `json:"days_on_track"` // 热量在目标±10%以内的天数
+	Days        []DailyNutritionSummary `json:"days"`
+}
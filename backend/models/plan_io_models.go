@@ -0,0 +1,75 @@
+package models
+
+import "time"
+
+// PlanExportSchema 训练计划导入导出的JSON格式版本
+const PlanExportSchema = "gymates.weekly_plan.v1"
+
+// PlanCSVHeader 训练计划CSV的列定义（每行一个动作）
+//
+// 休息日或没有动作的训练部位用 exercise 列为空的行表示；
+// group 为同一训练部位内动作组的序号（从1开始），同组各行的 block_type、rounds 等取第一行的值。
+var PlanCSVHeader = []string{
+	"day_of_week", "day_name", "is_rest_day", "muscle_group", "muscle_group_name",
+	"exercise", "sets", "reps", "weight", "duration", "rest_seconds", "notes",
+	"group", "block_type", "rounds", "work_seconds", "rest_between_rounds", "time_cap_seconds",
+}
+
+// PlanExportDocument 训练计划导入导出的JSON文档
+//
+// 示例：
+//
+//	{
+//	  "schema": "gymates.weekly_plan.v1",
+//	  "exported_at": "2024-01-01T08:00:00Z",
+//	  "plan": {
+//	    "name": "推拉腿",
+//	    "description": "",
+//	    "is_public": false,
+//	    "days": [{
+//	      "day_of_week": 1, "day_name": "Monday", "is_rest_day": false, "notes": "",
+//	      "parts": [{
+//	        "muscle_group": "chest", "muscle_group_name": "胸部", "order": 1,
+//	        "groups": [{"block_type": "superset", "rounds": 3}],
+//	        "exercises": [
+//	          {"name": "杠铃卧推", "sets": 4, "reps": 8, "weight": 60, "rest_seconds": 90},
+//	          {"name": "哑铃飞鸟", "sets": 3, "reps": 12, "group": 1}
+//	        ]
+//	      }]
+//	    }]
+//	  }
+//	}
+//
+// 导入时 schema 必须一致，exported_at 可省略；day_of_week 取值1-7，动作的 sets 至少为1。
+type PlanExportDocument struct {
+	Schema     string       `json:"schema"`
+	ExportedAt time.Time    `json:"exported_at"`
+	Plan       PlanSnapshot `json:"plan"`
+}
+
+// 响应DTO结构
+
+// ImportedExerciseMatch 导入时动作与动作库的匹配结果
+type ImportedExerciseMatch struct {
+	Row         int     `json:"row"`
+	Input       string  `json:"input"`
+	LibraryID   uint    `json:"library_id"`
+	MatchedName string  `json:"matched_name"`
+	MatchedBy   string  `json:"matched_by"` // name/alias/fuzzy
+	Score       float64 `json:"score"`
+}
+
+// ImportRowIssue 导入时未匹配或被跳过的行
+type ImportRowIssue struct {
+	Row    int    `json:"row"`
+	Input  string `json:"input"`
+	Reason string `json:"reason"`
+}
+
+// PlanImportReport 训练计划导入报告
+type PlanImportReport struct {
+	Plan      WeeklyTrainingPlan      `json:"plan"`
+	Matched   []ImportedExerciseMatch `json:"matched"`
+	Unmatched []ImportRowIssue        `json:"unmatched"` // 未匹配到动作库，按原名称导入
+	Skipped   []ImportRowIssue        `json:"skipped"`   // 数据无效，未导入
+}
@@ -0,0 +1,115 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// WeeklyTrainingPlanRevision 一周训练计划修订版本（保存完整JSON快照）
+type WeeklyTrainingPlanRevision struct {
+	ID                   uint           `json:"id" gorm:"primaryKey"`
+	WeeklyTrainingPlanID uint           `json:"weekly_training_plan_id" gorm:"not null;uniqueIndex:idx_plan_revision_version"`
+	Version              int            `json:"version" gorm:"not null;uniqueIndex:idx_plan_revision_version"`
+	AuthorID             uint           `json:"author_id" gorm:"not null"`
+	Author               User           `json:"author" gorm:"foreignKey:AuthorID"`
+	Summary              string         `json:"summary" gorm:"size:100"` // create/update/rollback/fork 等说明
+	Snapshot             string         `json:"snapshot,omitempty" gorm:"type:text;not null"`
+	CreatedAt            time.Time      `json:"created_at"`
+	DeletedAt            gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// PlanSnapshot 训练计划快照
+type PlanSnapshot struct {
+	Name        string        `json:"name"`
+	Description string        `json:"description"`
+	IsPublic    bool          `json:"is_public"`
+	Days        []DaySnapshot `json:"days"`
+}
+
+// DaySnapshot 训练日快照
+type DaySnapshot struct {
+	DayOfWeek int            `json:"day_of_week"`
+	DayName   string         `json:"day_name"`
+	IsRestDay bool           `json:"is_rest_day"`
+	Notes     string         `json:"notes"`
+	Parts     []PartSnapshot `json:"parts"`
+}
+
+// PartSnapshot 训练部位快照
+type PartSnapshot struct {
+	MuscleGroup     string             `json:"muscle_group"`
+	MuscleGroupName string             `json:"muscle_group_name"`
+	Order           int                `json:"order"`
+	Exercises       []ExerciseSnapshot `json:"exercises"`
+	Groups          []GroupSnapshot    `json:"groups,omitempty"`
+}
+
+// GroupSnapshot 动作组快照
+type GroupSnapshot struct {
+	BlockType        string `json:"block_type"`
+	Name             string `json:"name"`
+	Rounds           int    `json:"rounds"`
+	WorkSeconds      int    `json:"work_seconds"`
+	RestSeconds      int    `json:"rest_seconds"`
+	RoundRestSeconds int    `json:"round_rest_seconds"`
+	TimeCapSeconds   int    `json:"time_cap_seconds"`
+	Order            int    `json:"order"`
+}
+
+// ExerciseSnapshot 训练动作快照
+type ExerciseSnapshot struct {
+	ExerciseLibraryID *uint   `json:"exercise_library_id,omitempty"`
+	Name              string  `json:"name"`
+	Description       string  `json:"description"`
+	MuscleGroup       string  `json:"muscle_group"`
+	Difficulty        string  `json:"difficulty"`
+	Equipment         string  `json:"equipment"`
+	Sets              int     `json:"sets"`
+	Reps              int     `json:"reps"`
+	Weight            float64 `json:"weight"`
+	Duration          int     `json:"duration"`
+	RestTime          int     `json:"rest_time"`
+	RestSeconds       int     `json:"rest_seconds"`
+	Instructions      string  `json:"instructions"`
+	ImageURL          string  `json:"image_url"`
+	VideoURL          string  `json:"video_url"`
+	Calories          int     `json:"calories"`
+	Notes             string  `json:"notes"`
+	Order             int     `json:"order"`
+	Group             int     `json:"group,omitempty"` // 所属动作组在 PartSnapshot.Groups 中的序号（从1开始），0表示未分组
+}
+
+// 响应DTO结构
+
+// FieldChange 字段变更
+type FieldChange struct {
+	Field string      `json:"field"`
+	From  interface{} `json:"from"`
+	To    interface{} `json:"to"`
+}
+
+// DayDiff 训练日变更
+type DayDiff struct {
+	DayOfWeek  int           `json:"day_of_week"`
+	ChangeType string        `json:"change_type"` // added/removed/modified
+	Changes    []FieldChange `json:"changes,omitempty"`
+}
+
+// ExerciseDiff 训练动作变更
+type ExerciseDiff struct {
+	DayOfWeek   int           `json:"day_of_week"`
+	MuscleGroup string        `json:"muscle_group"`
+	Name        string        `json:"name"`
+	ChangeType  string        `json:"change_type"` // added/removed/modified
+	Changes     []FieldChange `json:"changes,omitempty"`
+}
+
+// PlanRevisionDiff 两个修订版本之间的差异
+type PlanRevisionDiff struct {
+	FromVersion int            `json:"from_version"`
+	ToVersion   int            `json:"to_version"`
+	PlanChanges []FieldChange  `json:"plan_changes"`
+	Days        []DayDiff      `json:"days"`
+	Exercises   []ExerciseDiff `json:"exercises"`
+}
@@ -0,0 +1,47 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// PlanTemplateRating 训练计划模板评分
+type PlanTemplateRating struct {
+	ID                   uint               `json:"id" gorm:"primaryKey"`
+	WeeklyTrainingPlanID uint               `json:"weekly_training_plan_id" gorm:"not null;uniqueIndex:idx_template_rating_user"`
+	WeeklyTrainingPlan   WeeklyTrainingPlan `json:"-" gorm:"foreignKey:WeeklyTrainingPlanID"`
+	UserID               uint               `json:"user_id" gorm:"not null;uniqueIndex:idx_template_rating_user"`
+	User                 User               `json:"user" gorm:"foreignKey:UserID"`
+	Rating               int                `json:"rating" gorm:"not null"` // 1-5
+	Comment              string             `json:"comment" gorm:"type:text"`
+	CreatedAt            time.Time          `json:"created_at"`
+	UpdatedAt            time.Time          `json:"updated_at"`
+	DeletedAt            gorm.DeletedAt     `json:"-" gorm:"index"`
+}
+
+// 请求DTO结构
+
+// RatePlanTemplateRequest 模板评分请求
+type RatePlanTemplateRequest struct {
+	Rating  int    `json:"rating" binding:"required,min=1,max=5"`
+	Comment string `json:"comment"`
+}
+
+// ForkPlanTemplateRequest 复制模板请求
+type ForkPlanTemplateRequest struct {
+	Name     string `json:"name"`     // 为空时沿用模板名称
+	Activate bool   `json:"activate"` // 是否设为当前使用的计划
+}
+
+// 响应DTO结构
+
+// PlanTemplateStats 作者模板统计
+type PlanTemplateStats struct {
+	PlanID        uint    `json:"plan_id"`
+	Name          string  `json:"name"`
+	ForkCount     int     `json:"fork_count"`
+	UsageCount    int64   `json:"usage_count"` // 正在使用（已激活）的复制计划数
+	RatingAverage float64 `json:"rating_average"`
+	RatingCount   int     `json:"rating_count"`
+}
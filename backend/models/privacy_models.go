@@ -0,0 +1,48 @@
+package models
+
+import "time"
+
+// 可见范围，用于资料、私信和训练动态
+const (
+	VisibilityEveryone = "everyone" // 所有人
+	VisibilityMates    = "mates"    // 仅搭子
+	VisibilityNobody   = "nobody"   // 仅自己
+)
+
+// WorkoutPostTypes 属于训练动态的帖子类型，按训练可见范围过滤；workout 是校验帖子类型之前写入的旧类型
+var WorkoutPostTypes = []string{PostTypeTraining, "workout"}
+
+// PrivacySettings 用户隐私设置，没有记录时使用 DefaultPrivacySettings
+type PrivacySettings struct {
+	UserID            uint      `json:"user_id" gorm:"primaryKey"`
+	ProfileVisibility string    `json:"profile_visibility" gorm:"size:20;not null;default:'everyone'"` // 谁能看完整资料
+	MessagePermission string    `json:"message_permission" gorm:"size:20;not null;default:'everyone'"` // 谁能发起私信
+	WorkoutVisibility string    `json:"workout_visibility" gorm:"size:20;not null;default:'everyone'"` // 谁能看训练动态
+	UpdatedAt         time.Time `json:"updated_at"`
+}
+
+// DefaultPrivacySettings 默认对所有人公开
+func DefaultPrivacySettings(userID uint) PrivacySettings {
+	return PrivacySettings{
+		UserID:            userID,
+		ProfileVisibility: VisibilityEveryone,
+		MessagePermission: VisibilityEveryone,
+		WorkoutVisibility: VisibilityEveryone,
+	}
+}
+
+// UserMute 静音：不再看到对方的帖子、评论和消息推送，对方不会收到通知
+type UserMute struct {
+	UserID    uint      `json:"user_id" gorm:"primaryKey;autoIncrement:false"`
+	MutedID   uint      `json:"muted_id" gorm:"primaryKey;autoIncrement:false;index"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// 请求DTO结构
+
+// UpdatePrivacyRequest 更新隐私设置，未提供的字段保持不变
+type UpdatePrivacyRequest struct {
+	ProfileVisibility *string `json:"profile_visibility" binding:"omitempty,oneof=everyone mates nobody"`
+	MessagePermission *string `json:"message_permission" binding:"omitempty,oneof=everyone mates nobody"`
+	WorkoutVisibility *string `json:"workout_visibility" binding:"omitempty,oneof=everyone mates nobody"`
+}
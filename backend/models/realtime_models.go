@@ -0,0 +1,87 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// 实时推送事件类型
+const (
+	RealtimeEventReady    = "ready"            // 连接建立，附带在线的联系人
+	RealtimeEventMessage  = "message.new"      // 新消息
+	RealtimeEventReaction = "message.reaction" // 表情回应变化
+	RealtimeEventEdited   = "message.edited"   // 消息被编辑
+	RealtimeEventRecalled = "message.recalled" // 消息被撤回
+	RealtimeEventDeleted  = "message.deleted"  // 消息被删除
+	RealtimeEventTyping   = "typing"           // 正在输入
+	RealtimeEventRead     = "read"             // 已读回执
+	RealtimeEventPresence = "presence"         // 联系人上线/下线
+	RealtimeEventPong     = "pong"
+	RealtimeEventError    = "error"
+)
+
+// 客户端通过 WebSocket 发送的帧类型
+const (
+	RealtimeFrameTyping = "typing"
+	RealtimeFrameRead   = "read"
+	RealtimeFramePing   = "ping"
+)
+
+// RealtimeEvent 推送给客户端的事件
+//
+// Data 是已编码的 JSON，事件可以原样经外部 pub/sub 转发到其他实例。
+type RealtimeEvent struct {
+	Type   string          `json:"type"`
+	ChatID uint            `json:"chat_id,omitempty"`
+	UserID uint            `json:"user_id,omitempty"` // 触发事件的用户
+	Data   json.RawMessage `json:"data,omitempty"`
+	Time   time.Time       `json:"time"`
+}
+
+// NewRealtimeEvent 创建事件并编码数据
+func NewRealtimeEvent(eventType string, chatID, userID uint, data interface{}) RealtimeEvent {
+	event := RealtimeEvent{Type: eventType, ChatID: chatID, UserID: userID, Time: time.Now()}
+	if data != nil {
+		event.Data, _ = json.Marshal(data)
+	}
+	return event
+}
+
+// RealtimeClientFrame 客户端发送的帧
+type RealtimeClientFrame struct {
+	Type      string `json:"type"` // typing/read/ping
+	ChatID    uint   `json:"chat_id"`
+	Typing    *bool  `json:"typing"`     // 为空表示开始输入
+	MessageID uint   `json:"message_id"` // read 帧已读到的消息ID，为空表示最新一条
+}
+
+// RealtimeTicket 建立 WebSocket 连接用的一次性票据
+type RealtimeTicket struct {
+	Ticket    string    `json:"ticket"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// RealtimeReady 连接建立时推送的内容
+type RealtimeReady struct {
+	UserID uint   `json:"user_id"`
+	Online []uint `json:"online"` // 在线的联系人
+}
+
+// TypingStatus 正在输入事件内容
+type TypingStatus struct {
+	Typing bool `json:"typing"`
+}
+
+// ReadReceipt 已读回执事件内容
+type ReadReceipt struct {
+	ReaderID          uint      `json:"reader_id"`
+	LastReadMessageID uint      `json:"last_read_message_id"`
+	Count             int64     `json:"count"` // 本次标记为已读的消息数
+	ReadAt            time.Time `json:"read_at"`
+}
+
+// PresenceStatus 在线状态事件内容
+type PresenceStatus struct {
+	UserID uint `json:"user_id"`
+	Online bool `json:"online"`
+}
@@ -0,0 +1,175 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 训练分化模式
+const (
+	SplitFullBody   = "full_body"   // 全身训练
+	SplitUpperLower = "upper_lower" // 上下肢分化
+	SplitPPL        = "ppl"         // 推拉腿
+	SplitBro3       = "bro_3"       // 胸背腿三分化（胸+三头/背+二头/腿+肩）
+	SplitBro4       = "bro_4"       // 四分化
+	SplitBro5       = "bro_5"       // 五分化（每天一个部位）
+	SplitCustom     = "custom"      // 用户自定义分化模板
+)
+
+// SplitSession 分化中的一次训练课
+type SplitSession struct {
+	Name  string   `json:"name" binding:"required,max=30"`
+	Parts []string `json:"parts" binding:"required,min=1"` // chest/back/legs/shoulders/arms/core
+}
+
+// SplitDefinition 分化定义：训练课按顺序轮流分配到每周的训练日
+type SplitDefinition struct {
+	Key      string         `json:"key"`
+	Name     string         `json:"name"`
+	MinDays  int            `json:"min_days"`
+	MaxDays  int            `json:"max_days"`
+	Sessions []SplitSession `json:"sessions"`
+}
+
+// SplitDefinitions 内置分化定义（custom 的训练课来自用户模板）
+var SplitDefinitions = []SplitDefinition{
+	{SplitFullBody, "全身训练", 1, 4, []SplitSession{
+		{"全身", []string{"chest", "back", "legs", "shoulders", "core"}},
+	}},
+	{SplitUpperLower, "上下肢分化", 2, 6, []SplitSession{
+		{"上肢", []string{"chest", "back", "shoulders", "arms"}},
+		{"下肢", []string{"legs", "core"}},
+	}},
+	{SplitPPL, "推拉腿", 3, 6, []SplitSession{
+		{"推", []string{"chest", "shoulders", "arms"}},
+		{"拉", []string{"back", "arms"}},
+		{"腿", []string{"legs", "core"}},
+	}},
+	{SplitBro3, "胸背腿三分化", 3, 6, []SplitSession{
+		{"胸+三头", []string{"chest", "arms"}},
+		{"背+二头", []string{"back", "arms"}},
+		{"腿+肩", []string{"legs", "shoulders"}},
+	}},
+	{SplitBro4, "四分化", 4, 4, []SplitSession{
+		{"胸", []string{"chest", "arms"}},
+		{"背", []string{"back", "arms"}},
+		{"腿", []string{"legs", "core"}},
+		{"肩", []string{"shoulders", "core"}},
+	}},
+	{SplitBro5, "五分化", 5, 5, []SplitSession{
+		{"胸", []string{"chest"}},
+		{"背", []string{"back"}},
+		{"腿", []string{"legs"}},
+		{"肩", []string{"shoulders"}},
+		{"手臂+核心", []string{"arms", "core"}},
+	}},
+	{SplitCustom, "自定义", 1, 7, nil},
+}
+
+// SplitModeAliases 历史数据中的中文模式名，与内置分化同名的别名必须指向该分化
+var SplitModeAliases = map[string]string{
+	"全身":  SplitFullBody,
+	"上下肢": SplitUpperLower,
+	"三分化": SplitPPL, // 早期的三分化按推/拉/腿安排
+	"推拉腿": SplitPPL,
+	"四分化": SplitBro4,
+	"五分化": SplitBro5,
+	"自定义": SplitCustom,
+}
+
+// TrainingParts 可用于分化的训练部位
+var TrainingParts = []string{"chest", "back", "legs", "shoulders", "arms", "core"}
+
+// WeeklyTrainingDays 每周训练天数对应的训练日（1=周一），尽量让训练日间隔均匀
+var WeeklyTrainingDays = map[int][]int{
+	1: {1},
+	2: {1, 4},
+	3: {1, 3, 5},
+	4: {1, 2, 4, 5},
+	5: {1, 2, 3, 4, 5},
+	6: {1, 2, 3, 4, 5, 6},
+	7: {1, 2, 3, 4, 5, 6, 7},
+}
+
+// LookupSplit 按模式键或历史中文名查找分化定义
+func LookupSplit(mode string) (SplitDefinition, bool) {
+	if key, ok := SplitModeAliases[mode]; ok {
+		mode = key
+	}
+	for _, split := range SplitDefinitions {
+		if split.Key == mode {
+			return split, true
+		}
+	}
+	return SplitDefinition{}, false
+}
+
+// IsTrainingPart 判断是否为可用于分化的训练部位
+func IsTrainingPart(part string) bool {
+	for _, item := range TrainingParts {
+		if item == part {
+			return true
+		}
+	}
+	return false
+}
+
+// SplitTemplate 用户自定义分化模板
+type SplitTemplate struct {
+	ID        uint           `json:"id" gorm:"primaryKey"`
+	UserID    uint           `json:"user_id" gorm:"not null;index"`
+	Name      string         `json:"name" gorm:"size:50;not null"`
+	Sessions  string         `json:"sessions" gorm:"type:text"` // JSON字符串存储训练课列表
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// SessionList 解析训练课列表
+func (t SplitTemplate) SessionList() []SplitSession {
+	var sessions []SplitSession
+	if t.Sessions == "" {
+		return sessions
+	}
+	if err := json.Unmarshal([]byte(t.Sessions), &sessions); err != nil {
+		return nil
+	}
+	return sessions
+}
+
+// 请求DTO结构
+
+// SaveTrainingModeRequest 设置训练模式请求
+type SaveTrainingModeRequest struct {
+	Mode            string `json:"mode" binding:"required"`
+	TrainDays       int    `json:"train_days" binding:"required,min=1,max=7"`
+	Target          string `json:"target" binding:"omitempty,oneof=增肌 减脂 综合"`
+	Level           string `json:"level" binding:"omitempty,oneof=初级 中级 高级"`
+	SplitTemplateID *uint  `json:"split_template_id"` // mode 为 custom 时必填
+}
+
+// CreateSplitTemplateRequest 创建自定义分化模板请求
+type CreateSplitTemplateRequest struct {
+	Name     string         `json:"name" binding:"required,max=50"`
+	Sessions []SplitSession `json:"sessions" binding:"required,min=1,max=7"`
+}
+
+// 响应DTO结构
+
+// SplitScheduleDay 一周中某天的训练安排
+type SplitScheduleDay struct {
+	DayOfWeek int      `json:"day_of_week"` // 1-7，1=周一
+	DayName   string   `json:"day_name"`    // Monday...Sunday
+	Session   string   `json:"session"`     // 训练课名称，休息日为空
+	Parts     []string `json:"parts"`       // 休息日为空
+	RestDay   bool     `json:"rest_day"`
+}
+
+// TrainingModeResponse 训练模式及一周安排
+type TrainingModeResponse struct {
+	Mode     TrainingMode       `json:"mode"`
+	Split    SplitDefinition    `json:"split"`
+	Schedule []SplitScheduleDay `json:"schedule"`
+}
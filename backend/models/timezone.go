@@ -0,0 +1,67 @@
+package models
+
+import (
+	"time"
+	_ "time/tzdata" // 内置时区数据，避免容器中缺少 zoneinfo
+)
+
+// DefaultTimezone 默认用户时区
+const DefaultTimezone = "Asia/Shanghai"
+
+// DateLayout 本地日期格式
+const DateLayout = "2006-01-02"
+
+// TimeLocation 获取用户时区，未设置或无效时回退到默认时区
+func (u *User) TimeLocation() *time.Location {
+	return LoadLocation(u.Timezone)
+}
+
+// LoadLocation 解析IANA时区名称，无效时回退到默认时区
+func LoadLocation(name string) *time.Location {
+	if name == "" {
+		name = DefaultTimezone
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		loc, err = time.LoadLocation(DefaultTimezone)
+		if err != nil {
+			return time.UTC
+		}
+	}
+	return loc
+}
+
+// IsValidTimezone 判断时区名称是否有效
+func IsValidTimezone(name string) bool {
+	if name == "" {
+		return false
+	}
+	_, err := time.LoadLocation(name)
+	return err == nil
+}
+
+// StartOfDay 获取t在loc时区下当天零点
+func StartOfDay(t time.Time, loc *time.Location) time.Time {
+	local := t.In(loc)
+	return time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc)
+}
+
+// StartOfWeek 获取t在loc时区下所在周的周一零点
+func StartOfWeek(t time.Time, loc *time.Location) time.Time {
+	day := StartOfDay(t, loc)
+	return day.AddDate(0, 0, -(ISOWeekday(day) - 1))
+}
+
+// ISOWeekday 获取星期几 (1-7, 1=周一)，与 TrainingDay.DayOfWeek 一致
+func ISOWeekday(t time.Time) int {
+	weekday := int(t.Weekday())
+	if weekday == 0 {
+		return 7
+	}
+	return weekday
+}
+
+// LocalDate 获取t在loc时区下的日期字符串 (YYYY-MM-DD)
+func LocalDate(t time.Time, loc *time.Location) string {
+	return t.In(loc).Format(DateLayout)
+}
@@ -15,6 +15,10 @@ type WeeklyTrainingPlan struct {
 	Days        []TrainingDay  `json:"days" gorm:"foreignKey:WeeklyTrainingPlanID"`
 	IsActive    bool           `json:"is_active" gorm:"default:true"`
 	IsPublic    bool           `json:"is_public" gorm:"default:false"`
+	SourceTemplateID *uint     `json:"source_template_id" gorm:"index"` // 复制来源模板ID
+	ForkCount        int       `json:"fork_count" gorm:"default:0"`
+	RatingAverage    float64   `json:"rating_average" gorm:"default:0"`
+	RatingCount      int       `json:"rating_count" gorm:"default:0"`
 	CreatedAt   time.Time      `json:"created_at"`
 	UpdatedAt   time.Time      `json:"updated_at"`
 	DeletedAt   gorm.DeletedAt `json:"-" gorm:"index"`
@@ -43,6 +47,7 @@ type TrainingPart struct {
 	MuscleGroup  string         `json:"muscle_group" gorm:"size:50;not null"`
 	MuscleGroupName string      `json:"muscle_group_name" gorm:"size:50;not null"`
 	Exercises    []Exercise     `json:"exercises" gorm:"foreignKey:TrainingPartID"`
+	Groups       []ExerciseGroup `json:"groups" gorm:"foreignKey:TrainingPartID"` // 动作组，组内动作同时出现在 Exercises 中
 	Order        int            `json:"order" gorm:"not null"`
 	CreatedAt    time.Time      `json:"created_at"`
 	UpdatedAt    time.Time      `json:"updated_at"`
@@ -73,12 +78,14 @@ type CreateTrainingPartRequest struct {
 	MuscleGroup     string                   `json:"muscle_group" binding:"required"`
 	MuscleGroupName string                   `json:"muscle_group_name" binding:"required"`
 	Exercises       []CreateExerciseRequest   `json:"exercises"`
+	Groups          []CreateExerciseGroupRequest `json:"groups"`
 	Order           int                      `json:"order"`
 }
 
 // CreateExerciseRequest 创建训练动作请求 (扩展)
 type CreateExerciseRequest struct {
-	Name         string  `json:"name" binding:"required"`
+	ExerciseLibraryID *uint `json:"exercise_library_id"` // 引用标准动作，指定后名称以动作库为准
+	Name         string  `json:"name" binding:"required_without=ExerciseLibraryID"`
 	Description  string  `json:"description"`
 	MuscleGroup  string  `json:"muscle_group"`
 	Sets         int     `json:"sets" binding:"required,min=1"`
@@ -118,12 +125,14 @@ type UpdateTrainingPartRequest struct {
 	MuscleGroup     string                    `json:"muscle_group"`
 	MuscleGroupName string                    `json:"muscle_group_name"`
 	Exercises       []UpdateExerciseRequest    `json:"exercises"`
+	Groups          []CreateExerciseGroupRequest `json:"groups"` // 更新时动作组整体重建
 	Order           int                       `json:"order"`
 }
 
 // UpdateExerciseRequest 更新训练动作请求
 type UpdateExerciseRequest struct {
 	ID           uint     `json:"id"`
+	ExerciseLibraryID *uint `json:"exercise_library_id"`
 	Name         string   `json:"name"`
 	Description  string   `json:"description"`
 	MuscleGroup  string   `json:"muscle_group"`
@@ -168,6 +177,12 @@ type AIRecommendationResponse struct {
 	Parts  []RecommendedPart       `json:"parts"`
 	Mode   string                  `json:"mode"`
 	Target string                  `json:"target"`
+	Session string                 `json:"session,omitempty"` // 当天的分化训练课名称
+	RestDay bool                   `json:"rest_day"`          // 当天为分化中的休息日且未指定肌群
+	Seed    int64                  `json:"seed"`              // 随机种子，传回 seed 参数可复现本次推荐
+	EquipmentProfile string        `json:"equipment_profile,omitempty"` // 生效中的器械配置名称
+	Restrictions     []ExerciseRestriction `json:"restrictions,omitempty"` // 因伤病排除或降权的动作及原因
+	PriorityMuscles  []string              `json:"priority_muscles,omitempty"` // 已恢复且本周训练量不足的肌肉
 }
 
 // RecommendedPart 推荐部位
@@ -178,6 +193,7 @@ type RecommendedPart struct {
 
 // RecommendedExercise 推荐动作（扩展版）
 type RecommendedExercise struct {
+	ExerciseID  uint    `json:"exercise_library_id,omitempty"` // 动作库条目ID
 	Name        string  `json:"name"`
 	Sets        int     `json:"sets"`
 	Reps        int     `json:"reps"`
@@ -187,4 +203,21 @@ type RecommendedExercise struct {
 	Description string  `json:"description"`
 	VideoURL    string  `json:"video_url"`
 	Notes       string  `json:"notes"`
+	Trace       []RecommendationTrace `json:"trace,omitempty"` // 入选原因
+}
+
+// 推荐解释因素
+const (
+	TraceMuscleDeficit = "muscle_deficit" // 主要发力肌群已恢复且本周训练量不足
+	TraceEquipment     = "equipment"      // 当前器械可完成
+	TraceNovelty       = "novelty"        // 最近是否练过
+	TraceLevel         = "level"          // 动作难度与训练水平
+	TraceInjury        = "injury"         // 因伤病降低强度
+)
+
+// RecommendationTrace 推荐动作的一条入选原因
+type RecommendationTrace struct {
+	Factor string  `json:"factor"`
+	Detail string  `json:"detail"`
+	Score  float64 `json:"score,omitempty"` // 影响排序的分值，仅 muscle_deficit 有
 }
\ No newline at end of file
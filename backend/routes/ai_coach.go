@@ -18,7 +18,7 @@ func SetupAICoachRoutes(router *gin.RouterGroup) {
 		aiGroup.POST("/coach", middleware.OptionalAuthMiddleware(), aiCoachController.CoachChat)
 
 		// 训练进度上报接口
-		aiGroup.POST("/progress", middleware.OptionalAuthMiddleware(), aiCoachController.TrainingProgress)
+		aiGroup.POST("/progress", middleware.AuthMiddleware(), aiCoachController.TrainingProgress)
 
 		// 获取AI服务状态
 		aiGroup.GET("/status", aiCoachController.GetServiceStatus)
@@ -37,6 +37,14 @@ func SetupTrainingRoutes(r *gin.RouterGroup) {
 	trainingPlanController := controllers.NewTrainingPlanController()
 	aiRecommendationController := controllers.NewAIRecommendationController()
 	aiTrainingController := controllers.NewAITrainingController()
+	planTemplateController := controllers.NewPlanTemplateController()
+	planRevisionController := controllers.NewPlanRevisionController()
+	planIOController := controllers.NewPlanIOController()
+	exerciseLibraryController := controllers.NewExerciseLibraryController()
+	equipmentProfileController := controllers.NewEquipmentProfileController()
+	injuryController := controllers.NewInjuryController()
+	muscleRecoveryController := controllers.NewMuscleRecoveryController()
+	trainingModeController := controllers.NewTrainingModeController()
 
 	training := r.Group("/training")
 	{
@@ -45,10 +53,19 @@ func SetupTrainingRoutes(r *gin.RouterGroup) {
 		training.GET("/plans/:id", middleware.OptionalAuthMiddleware(), trainingController.GetTrainingPlan)
 		training.GET("/exercises", trainingController.GetAllExercises)
 		training.GET("/exercises/search", trainingController.SearchExercises)
+		training.GET("/exercises/:id", exerciseLibraryController.GetExercise)
+		training.GET("/exercises/:id/substitutions", exerciseLibraryController.GetSubstitutions) // ?equipment=dumbbell
+		training.GET("/muscles", exerciseLibraryController.GetMuscles)
+		training.GET("/splits", trainingModeController.GetSplits)
 
 		// 一周训练计划公开接口
 		training.GET("/weekly-plans", middleware.OptionalAuthMiddleware(), weeklyTrainingController.GetWeeklyTrainingPlans)
 		training.GET("/weekly-plans/:id", middleware.OptionalAuthMiddleware(), weeklyTrainingController.GetWeeklyTrainingPlan)
+		training.GET("/weekly-plans/:id/export", middleware.OptionalAuthMiddleware(), planIOController.ExportPlan) // ?format=json|csv|md|html
+
+		// 训练计划模板市场公开接口
+		training.GET("/templates", planTemplateController.GetTemplates)
+		training.GET("/templates/:id", planTemplateController.GetTemplate)
 
 		// 新的训练计划接口（按需求实现）
 		training.GET("/plan", trainingPlanController.GetTrainingPlan)                    // GET /api/training/plan?user_id={uid}
@@ -69,14 +86,56 @@ func SetupTrainingRoutes(r *gin.RouterGroup) {
 			trainingAuth.POST("/sessions", trainingController.StartWorkoutSession)
 			trainingAuth.PUT("/sessions/:id/progress", trainingController.UpdateWorkoutProgress)
 			trainingAuth.POST("/sessions/:id/complete", trainingController.CompleteWorkoutSession)
+			trainingAuth.POST("/sessions/:id/sets", trainingController.LogWorkoutSet)
+			trainingAuth.GET("/sessions/:id/sets", trainingController.GetWorkoutSets)
+			trainingAuth.GET("/sessions/:id/calories", trainingController.GetSessionCalories)
+			trainingAuth.PUT("/sessions/:id/calories", trainingController.SetSessionCalories) // 穿戴设备数值覆盖估算值
 			trainingAuth.GET("/history", trainingController.GetWorkoutHistory)
 
 			// 一周训练计划认证接口
 			trainingAuth.POST("/weekly-plans", weeklyTrainingController.CreateWeeklyTrainingPlan)
+			trainingAuth.POST("/weekly-plans/import", planIOController.ImportPlan) // ?format=json|csv
 			trainingAuth.PUT("/weekly-plans/:id", weeklyTrainingController.UpdateWeeklyTrainingPlan)
 			trainingAuth.DELETE("/weekly-plans/:id", weeklyTrainingController.DeleteWeeklyTrainingPlan)
 			trainingAuth.GET("/today", weeklyTrainingController.GetTodayTraining)
 			trainingAuth.POST("/ai-recommendations", weeklyTrainingController.GetAIRecommendations)
+
+			// 训练计划模板市场认证接口
+			trainingAuth.POST("/templates/:id/rate", planTemplateController.RateTemplate)
+			trainingAuth.POST("/templates/:id/fork", planTemplateController.ForkTemplate)
+			trainingAuth.GET("/my-templates", planTemplateController.GetMyTemplates)
+
+			// 一周训练计划版本接口
+			trainingAuth.GET("/weekly-plans/:id/revisions", planRevisionController.GetRevisions)
+			trainingAuth.GET("/weekly-plans/:id/revisions/diff", planRevisionController.DiffRevisions)
+			trainingAuth.POST("/weekly-plans/:id/revisions/:version/rollback", planRevisionController.RollbackRevision)
+
+			// 器械配置接口
+			trainingAuth.GET("/equipment-profiles", equipmentProfileController.GetProfiles)
+			trainingAuth.POST("/equipment-profiles", equipmentProfileController.CreateProfile)
+			trainingAuth.PUT("/equipment-profiles/:id", equipmentProfileController.UpdateProfile)
+			trainingAuth.DELETE("/equipment-profiles/:id", equipmentProfileController.DeleteProfile)
+			trainingAuth.POST("/equipment-profiles/:id/activate", equipmentProfileController.ActivateProfile)
+
+			// 伤病/身体限制接口
+			trainingAuth.GET("/injuries", injuryController.GetInjuries) // ?active=true
+			trainingAuth.POST("/injuries", injuryController.CreateInjury)
+			trainingAuth.GET("/injuries/restrictions", injuryController.GetRestrictions)
+			trainingAuth.PUT("/injuries/:id", injuryController.UpdateInjury)
+			trainingAuth.DELETE("/injuries/:id", injuryController.DeleteInjury)
+
+			// 肌肉恢复与训练量接口
+			trainingAuth.GET("/muscles/heatmap", muscleRecoveryController.GetHeatmap)
+			trainingAuth.GET("/muscles/volume-targets", muscleRecoveryController.GetVolumeTargets)
+			trainingAuth.PUT("/muscles/volume-targets", muscleRecoveryController.SaveVolumeTargets)
+			trainingAuth.DELETE("/muscles/volume-targets", muscleRecoveryController.ResetVolumeTargets)
+
+			// 训练模式与分化
+			trainingAuth.GET("/mode", trainingModeController.GetTrainingMode)
+			trainingAuth.PUT("/mode", trainingModeController.SaveTrainingMode)
+			trainingAuth.GET("/split-templates", trainingModeController.GetSplitTemplates)
+			trainingAuth.POST("/split-templates", trainingModeController.CreateSplitTemplate)
+			trainingAuth.DELETE("/split-templates/:id", trainingModeController.DeleteSplitTemplate)
 		}
 	}
 }
@@ -117,15 +176,40 @@ func SetupMatesRoutes(r *gin.RouterGroup) {
 		mates.POST("/requests", matesController.SendMateRequest)
 		mates.POST("/requests/:id/accept", matesController.AcceptMateRequest)
 		mates.POST("/requests/:id/reject", matesController.RejectMateRequest)
+		mates.POST("/requests/:id/cancel", matesController.CancelMateRequest)
 		mates.DELETE("/:id", matesController.RemoveMate)
+		mates.POST("/:id/block", matesController.BlockUser)
+		mates.DELETE("/:id/block", matesController.UnblockUser)
+		mates.POST("/:id/mute", matesController.MuteUser)
+		mates.DELETE("/:id/mute", matesController.UnmuteUser)
+		mates.GET("/blocks", matesController.GetBlockedUsers)
+		mates.GET("/mutes", matesController.GetMutedUsers)
 		mates.GET("/search", matesController.SearchMates)
 		mates.GET("/stats", matesController.GetMateStats)
+		mates.GET("/suggestions", matesController.GetSuggestions)
+		mates.GET("/nearby", matesController.GetNearbyMates)
+	}
+}
+
+// SetupGymRoutes 设置健身房相关路由
+func SetupGymRoutes(r *gin.RouterGroup) {
+	gymController := controllers.NewGymController()
+
+	gyms := r.Group("/gyms")
+	gyms.Use(middleware.AuthMiddleware())
+	{
+		gyms.POST("", gymController.CreateGym)
+		gyms.GET("/nearby", gymController.GetNearbyGyms) // ?lat=&lon=&radius_km=5
+		gyms.GET("/:id", gymController.GetGym)
+		gyms.POST("/:id/join", gymController.JoinGym)
+		gyms.POST("/:id/leave", gymController.LeaveGym)
 	}
 }
 
 // SetupMessagesRoutes 设置消息相关路由
 func SetupMessagesRoutes(r *gin.RouterGroup) {
 	messagesController := controllers.NewMessagesController()
+	groupChatController := controllers.NewGroupChatController()
 
 	messages := r.Group("/messages")
 	messages.Use(middleware.AuthMiddleware())
@@ -136,7 +220,29 @@ func SetupMessagesRoutes(r *gin.RouterGroup) {
 		messages.GET("/chats/:id/messages", messagesController.GetMessages)
 		messages.POST("/chats/:id/messages", messagesController.SendMessage)
 		messages.PUT("/chats/:id/read", messagesController.MarkAsRead)
+		messages.PUT("/chats/:id/messages/:messageId", messagesController.EditMessage)
+		messages.DELETE("/chats/:id/messages/:messageId", messagesController.DeleteMessage)
+		messages.GET("/chats/:id/messages/:messageId/edits", messagesController.GetMessageEdits)
+		messages.POST("/chats/:id/messages/:messageId/recall", messagesController.RecallMessage)
+		messages.POST("/chats/:id/messages/:messageId/reactions", messagesController.AddReaction)
+		messages.DELETE("/chats/:id/messages/:messageId/reactions/:emoji", messagesController.RemoveReaction)
 		messages.GET("/unread", messagesController.GetUnreadCount)
+		messages.GET("/search", messagesController.SearchMessages)
+
+		// 群聊
+		messages.POST("/groups", groupChatController.CreateGroup)
+		messages.PUT("/groups/:id", groupChatController.UpdateGroup)
+		messages.GET("/groups/:id/members", groupChatController.GetMembers)
+		messages.POST("/groups/:id/members", groupChatController.AddMembers)
+		messages.DELETE("/groups/:id/members/:userId", groupChatController.RemoveMember)
+		messages.PUT("/groups/:id/members/:userId/role", groupChatController.UpdateMemberRole)
+		messages.POST("/groups/:id/transfer", groupChatController.TransferOwnership)
+		messages.POST("/groups/:id/leave", groupChatController.LeaveGroup)
+		messages.POST("/groups/:id/invites", groupChatController.CreateInvite)
+		messages.GET("/groups/:id/invites", groupChatController.GetInvites)
+		messages.DELETE("/groups/:id/invites/:inviteId", groupChatController.RevokeInvite)
+		messages.GET("/invites/:code", groupChatController.PreviewInvite)
+		messages.POST("/invites/:code/join", groupChatController.JoinByInvite)
 	}
 }
 
@@ -150,5 +256,63 @@ func SetupProfileRoutes(r *gin.RouterGroup) {
 		profile.GET("/me", authController.GetCurrentUser)
 		profile.PUT("/update", authController.UpdateProfile)
 		profile.GET("/stats", authController.GetUserStats)
+		profile.GET("/privacy", authController.GetPrivacySettings)
+		profile.PUT("/privacy", authController.UpdatePrivacySettings)
 	}
 }
+
+// SetupNutritionRoutes 设置饮食与营养相关路由
+func SetupNutritionRoutes(r *gin.RouterGroup) {
+	nutritionController := controllers.NewNutritionController()
+
+	nutrition := r.Group("/nutrition")
+	nutrition.Use(middleware.AuthMiddleware())
+	{
+		nutrition.GET("/foods", nutritionController.GetFoods) // ?q=鸡
+		nutrition.POST("/foods", nutritionController.CreateFood)
+		nutrition.GET("/meals", nutritionController.GetMeals) // ?date=2024-01-01
+		nutrition.POST("/meals", nutritionController.CreateMeal)
+		nutrition.DELETE("/meals/:id", nutritionController.DeleteMeal)
+		nutrition.GET("/targets", nutritionController.GetTargets)
+		nutrition.GET("/summary/daily", nutritionController.GetDailySummary)   // ?date=2024-01-01
+		nutrition.GET("/summary/weekly", nutritionController.GetWeeklySummary) // ?date=2024-01-01
+	}
+}
+
+// SetupBodyRoutes 设置身体指标相关路由
+func SetupBodyRoutes(r *gin.RouterGroup) {
+	bodyMetricsController := controllers.NewBodyMetricsController()
+
+	body := r.Group("/body")
+	body.Use(middleware.AuthMiddleware())
+	{
+		body.GET("/measurements", bodyMetricsController.GetMeasurements) // ?from=2024-01-01&to=2024-03-31
+		body.POST("/measurements", bodyMetricsController.SaveMeasurement)
+		body.DELETE("/measurements/:id", bodyMetricsController.DeleteMeasurement)
+		body.GET("/trend", bodyMetricsController.GetTrend) // ?metric=weight&days=90&target=65
+	}
+}
+
+// SetupCardioRoutes 设置有氧运动相关路由
+func SetupCardioRoutes(r *gin.RouterGroup) {
+	cardioController := controllers.NewCardioController()
+
+	cardio := r.Group("/cardio")
+	cardio.Use(middleware.AuthMiddleware())
+	{
+		cardio.GET("/activities", cardioController.GetActivities) // ?type=run&page=1&limit=20
+		cardio.POST("/activities", cardioController.CreateActivity)
+		cardio.POST("/activities/import", cardioController.ImportActivity) // ?format=gpx|tcx|fit&type=run
+		cardio.GET("/activities/:id", cardioController.GetActivity)
+		cardio.DELETE("/activities/:id", cardioController.DeleteActivity)
+		cardio.GET("/summary", cardioController.GetSummary) // ?type=run
+	}
+}
+
+// SetupRealtimeRoutes 设置实时消息路由，WebSocket 握手时自行校验票据或 Authorization 头
+func SetupRealtimeRoutes(r *gin.RouterGroup) {
+	realtimeController := controllers.NewRealtimeController()
+
+	r.POST("/ws/ticket", middleware.AuthMiddleware(), realtimeController.IssueTicket)
+	r.GET("/ws", realtimeController.Connect) // ?ticket=<ticket>
+}
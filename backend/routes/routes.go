@@ -38,7 +38,7 @@ func SetupRoutes(r *gin.Engine) {
 		users := api.Group("/users")
 		{
 			authController := controllers.NewAuthController()
-			users.GET("/:id", authController.GetUserProfile)
+			users.GET("/:id", middleware.OptionalAuthMiddleware(), authController.GetUserProfile)
 		}
 
 		// 首页路由
@@ -53,12 +53,27 @@ func SetupRoutes(r *gin.Engine) {
 		// 搭子路由
 		SetupMatesRoutes(api)
 
+		// 健身房路由
+		SetupGymRoutes(api)
+
 		// 消息路由
 		SetupMessagesRoutes(api)
 
+		// 实时消息路由
+		SetupRealtimeRoutes(api)
+
 		// 用户资料路由
 		SetupProfileRoutes(api)
 
+		// 饮食与营养路由
+		SetupNutritionRoutes(api)
+
+		// 身体指标路由
+		SetupBodyRoutes(api)
+
+		// 有氧运动路由
+		SetupCardioRoutes(api)
+
 		// 详情路由
 		SetupDetailRoutes(api)
 		SetupPostDetailRoutes(api)
@@ -0,0 +1,145 @@
+package services
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"gymates-backend/models"
+)
+
+// 身体指标趋势参数
+const (
+	TrendAlpha         = 0.1  // EWMA 每天的平滑系数，间隔 n 天时为 1-(1-α)^n
+	MovingAverageDays  = 7    // 简单移动平均的窗口天数
+	TrendWindowDays    = 28   // 计算变化速度所用的最近天数
+	MinTrendSpanDays   = 7    // 计算变化速度至少需要跨越的天数
+	ReachedToleranceKg = 0.2  // 趋势体重与目标相差在此范围内视为已达到
+	StalledRatePerWeek = 0.05 // 每周变化小于该值视为停滞
+)
+
+// DatedValue 某天的指标值
+type DatedValue struct {
+	Date  string // YYYY-MM-DD
+	Value float64
+}
+
+// SmoothTrend 按日期排序并计算 EWMA 平滑值和7天简单移动平均
+//
+// 记录不连续时按间隔天数放大平滑系数，避免长时间未记录后趋势反应过慢。
+func SmoothTrend(series []DatedValue) []models.BodyTrendPoint {
+	sorted := append([]DatedValue(nil), series...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Date < sorted[j].Date })
+
+	points := make([]models.BodyTrendPoint, 0, len(sorted))
+	var smoothed float64
+	var previous time.Time
+	for i, item := range sorted {
+		day, err := time.Parse(models.DateLayout, item.Date)
+		if err != nil {
+			continue
+		}
+		if len(points) == 0 {
+			smoothed = item.Value
+		} else {
+			gap := math.Max(day.Sub(previous).Hours()/24, 1)
+			alpha := 1 - math.Pow(1-TrendAlpha, gap)
+			smoothed += alpha * (item.Value - smoothed)
+		}
+		previous = day
+
+		windowStart := day.AddDate(0, 0, -MovingAverageDays).Format(models.DateLayout)
+		sum, count := 0.0, 0
+		for j := i; j >= 0 && sorted[j].Date > windowStart; j-- {
+			sum += sorted[j].Value
+			count++
+		}
+
+		points = append(points, models.BodyTrendPoint{
+			Date:          item.Date,
+			Value:         item.Value,
+			Smoothed:      roundHundredth(smoothed),
+			MovingAverage: roundHundredth(sum / float64(count)),
+		})
+	}
+	return points
+}
+
+// TrendRatePerWeek 用最近4周平滑值的线性回归斜率估算每周变化，数据不足时返回 false
+func TrendRatePerWeek(points []models.BodyTrendPoint) (float64, bool) {
+	if len(points) < 2 {
+		return 0, false
+	}
+	last, err := time.Parse(models.DateLayout, points[len(points)-1].Date)
+	if err != nil {
+		return 0, false
+	}
+	windowStart := last.AddDate(0, 0, -TrendWindowDays)
+
+	var xs, ys []float64
+	for _, point := range points {
+		day, err := time.Parse(models.DateLayout, point.Date)
+		if err != nil || day.Before(windowStart) {
+			continue
+		}
+		xs = append(xs, day.Sub(windowStart).Hours()/24)
+		ys = append(ys, point.Smoothed)
+	}
+	if len(xs) < 2 || xs[len(xs)-1]-xs[0] < MinTrendSpanDays {
+		return 0, false
+	}
+
+	n := float64(len(xs))
+	var sumX, sumY, sumXY, sumXX float64
+	for i := range xs {
+		sumX += xs[i]
+		sumY += ys[i]
+		sumXY += xs[i] * ys[i]
+		sumXX += xs[i] * xs[i]
+	}
+	slope := (n*sumXY - sumX*sumY) / (n*sumXX - sumX*sumX)
+	return roundHundredth(slope * 7), true
+}
+
+// ProjectGoal 按当前趋势预测达到目标体重的日期，预测日期从最后一次记录开始推算
+func ProjectGoal(points []models.BodyTrendPoint, targetWeight float64, today time.Time) models.GoalProjection {
+	projection := models.GoalProjection{
+		Status:       models.ProjectionInsufficientData,
+		TargetWeight: targetWeight,
+	}
+	if len(points) == 0 {
+		return projection
+	}
+
+	latest := points[len(points)-1]
+	remaining := targetWeight - latest.Smoothed
+	projection.Remaining = roundHundredth(remaining)
+	rate, ok := TrendRatePerWeek(points)
+	projection.RatePerWeek = rate
+
+	if math.Abs(remaining) <= ReachedToleranceKg {
+		projection.Status = models.ProjectionReached
+		return projection
+	}
+	if !ok {
+		return projection
+	}
+	if math.Abs(rate) < StalledRatePerWeek || (rate > 0) != (remaining > 0) {
+		projection.Status = models.ProjectionWrongDirection
+		return projection
+	}
+
+	lastDay, _ := time.Parse(models.DateLayout, latest.Date)
+	// 减去极小值，避免浮点误差让整数天数多算一天
+	projected := lastDay.AddDate(0, 0, int(math.Ceil(remaining/(rate/7)-1e-9)))
+	todayDate, _ := time.Parse(models.DateLayout, today.Format(models.DateLayout))
+
+	projection.Status = models.ProjectionOnTrack
+	projection.ProjectedDate = projected.Format(models.DateLayout)
+	projection.DaysRemaining = int(math.Max(projected.Sub(todayDate).Hours()/24, 0))
+	return projection
+}
+
+func roundHundredth(value float64) float64 {
+	return math.Round(value*100) / 100
+}
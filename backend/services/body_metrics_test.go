@@ -0,0 +1,102 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"gymates-backend/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// linearTrend 从 start 开始每天一个点，平滑值每天变化 perDay
+func linearTrend(start string, days int, first, perDay float64) []models.BodyTrendPoint {
+	day, _ := time.Parse(models.DateLayout, start)
+	points := make([]models.BodyTrendPoint, 0, days)
+	for i := 0; i < days; i++ {
+		value := first + perDay*float64(i)
+		points = append(points, models.BodyTrendPoint{
+			Date:     day.AddDate(0, 0, i).Format(models.DateLayout),
+			Value:    value,
+			Smoothed: value,
+		})
+	}
+	return points
+}
+
+// TestBodyTrend 测试 EWMA 平滑、移动平均和目标体重预测
+func TestBodyTrend(t *testing.T) {
+	t.Run("按间隔天数平滑", func(t *testing.T) {
+		points := SmoothTrend([]DatedValue{
+			{Date: "2025-03-09", Value: 77},
+			{Date: "2025-03-01", Value: 80},
+			{Date: "2025-03-02", Value: 79},
+			{Date: "2025-03-04", Value: 78},
+		})
+		require.Len(t, points, 4)
+		assert.Equal(t, "2025-03-01", points[0].Date)
+		assert.Equal(t, 80.0, points[0].Smoothed)
+		assert.Equal(t, 79.9, points[1].Smoothed)
+		// 间隔2天，系数为 1-0.9²=0.19
+		assert.Equal(t, 79.54, points[2].Smoothed)
+		assert.Equal(t, 79.5, points[1].MovingAverage)
+		assert.Equal(t, 79.0, points[2].MovingAverage)
+		// 7天窗口只包含 03-04 和 03-09
+		assert.Equal(t, 77.5, points[3].MovingAverage)
+	})
+
+	today, _ := time.Parse(models.DateLayout, "2025-03-20")
+	tests := []struct {
+		name          string
+		points        []models.BodyTrendPoint
+		target        float64
+		wantStatus    string
+		wantRate      float64
+		wantDate      string
+		wantDaysLeft  int
+		wantRemaining float64
+	}{
+		{
+			name:   "按趋势可达到",
+			points: linearTrend("2025-03-01", 15, 80, -0.1), target: 75.05,
+			wantStatus: models.ProjectionOnTrack, wantRate: -0.7, wantDate: "2025-04-20", wantDaysLeft: 31, wantRemaining: -3.55,
+		},
+		{
+			name:   "已达到目标",
+			points: linearTrend("2025-03-01", 15, 80, -0.1), target: 78.7,
+			wantStatus: models.ProjectionReached, wantRate: -0.7, wantRemaining: 0.1,
+		},
+		{
+			name:   "趋势与目标方向相反",
+			points: linearTrend("2025-03-01", 15, 80, -0.1), target: 82,
+			wantStatus: models.ProjectionWrongDirection, wantRate: -0.7, wantRemaining: 3.4,
+		},
+		{
+			name:   "体重停滞",
+			points: linearTrend("2025-03-01", 15, 80, 0), target: 75,
+			wantStatus: models.ProjectionWrongDirection, wantRemaining: -5,
+		},
+		{
+			name:   "记录跨度不足一周",
+			points: linearTrend("2025-03-01", 4, 80, -0.1), target: 75,
+			wantStatus: models.ProjectionInsufficientData, wantRemaining: -4.7,
+		},
+		{
+			name:       "没有记录",
+			target:     75,
+			wantStatus: models.ProjectionInsufficientData,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			projection := ProjectGoal(tt.points, tt.target, today)
+			assert.Equal(t, tt.wantStatus, projection.Status)
+			assert.Equal(t, tt.wantRate, projection.RatePerWeek)
+			assert.Equal(t, tt.wantDate, projection.ProjectedDate)
+			assert.Equal(t, tt.wantDaysLeft, projection.DaysRemaining)
+			assert.Equal(t, tt.wantRemaining, projection.Remaining)
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package services
+
+import (
+	"math"
+
+	"gymates-backend/models"
+)
+
+// DefaultMaxHeartRate 未填写年龄时使用的最大心率
+const DefaultMaxHeartRate = 190
+
+// MaxHeartRate 按 220-年龄 估算最大心率
+func MaxHeartRate(age int) int {
+	if age <= 0 || age >= 120 {
+		return DefaultMaxHeartRate
+	}
+	return 220 - age
+}
+
+// CardioMET 有氧运动的 MET（参考 Compendium of Physical Activities）
+//
+// 跑步和骑行按平均速度取值，没有距离时取中等强度；划船和游泳取中等强度。
+func CardioMET(activityType string, speedKmh float64) float64 {
+	switch activityType {
+	case models.CardioRun:
+		if speedKmh <= 0 {
+			return 9.8
+		}
+		// 6.4km/h 快走约6.0，之后每 km/h 约增加1
+		return math.Max(6.0, math.Round(speedKmh*10)/10)
+	case models.CardioCycle:
+		switch {
+		case speedKmh <= 0:
+			return 8.0
+		case speedKmh < 16:
+			return 4.0
+		case speedKmh < 19:
+			return 6.8
+		case speedKmh < 22:
+			return 8.0
+		case speedKmh < 25:
+			return 10.0
+		default:
+			return 12.0
+		}
+	case models.CardioRow:
+		return 7.0
+	case models.CardioSwim:
+		return 5.8
+	}
+	return models.DefaultMET
+}
+
+// Pace 配速（秒/公里），没有距离时为0
+func Pace(seconds, meters float64) float64 {
+	if meters <= 0 {
+		return 0
+	}
+	return math.Round(seconds / (meters / 1000))
+}
+
+// SpeedKmh 平均速度（公里/小时）
+func SpeedKmh(seconds, meters float64) float64 {
+	if seconds <= 0 {
+		return 0
+	}
+	return math.Round(meters/seconds*3.6*10) / 10
+}
+
+// EvenPaceEffort 没有轨迹时按平均配速估算 meters 距离的用时
+//
+// 匀速是最保守的估计：真实的最快一段只会更快或相同。
+func EvenPaceEffort(seconds, meters, target float64) (float64, bool) {
+	if target <= 0 || meters < target || seconds <= 0 {
+		return 0, false
+	}
+	return math.Round(seconds * target / meters), true
+}
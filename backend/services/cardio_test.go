@@ -0,0 +1,23 @@
+package services
+
+import (
+	"testing"
+
+	"gymates-backend/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestCardioMetrics 测试配速、速度、MET 和没有轨迹时按平均配速估算的最佳成绩
+func TestCardioMetrics(t *testing.T) {
+	seconds, ok := EvenPaceEffort(3000, 10000, 5000)
+	assert.True(t, ok)
+	assert.Equal(t, 1500.0, seconds)
+	_, ok = EvenPaceEffort(1200, 4000, 5000)
+	assert.False(t, ok)
+
+	assert.Equal(t, 300.0, Pace(1500, 5000))
+	assert.Equal(t, 12.0, SpeedKmh(1500, 5000))
+	assert.Equal(t, 12.0, CardioMET(models.CardioRun, 12))
+	assert.Equal(t, 190, MaxHeartRate(0))
+}
@@ -0,0 +1,106 @@
+package services
+
+import (
+	"math"
+
+	"gymates-backend/models"
+)
+
+// 热量估算参数
+const (
+	DefaultBodyWeightKg = 70.0 // 用户未填写体重时使用
+	SecondsPerRep       = 4    // 每次动作的估算用时
+	DefaultSetSeconds   = 45   // 既没有次数也没有时长的组
+	DefaultRestSeconds  = 90   // 未设置组间休息时
+	MaxSessionMinutes   = 360  // 超过该时长的会话视为忘记结束，有组记录时改按组数估算，否则按该时长封顶
+)
+
+// EnergySet 参与估算的一组训练
+type EnergySet struct {
+	MET             float64
+	Reps            int
+	DurationSeconds int
+	RestSeconds     int
+}
+
+// Seconds 一组的用时（动作时间 + 组间休息）
+func (s EnergySet) Seconds() float64 {
+	active := s.DurationSeconds
+	if active <= 0 {
+		active = s.Reps * SecondsPerRep
+	}
+	if active <= 0 {
+		active = DefaultSetSeconds
+	}
+	rest := s.RestSeconds
+	if rest <= 0 {
+		rest = DefaultRestSeconds
+	}
+	return float64(active + rest)
+}
+
+// SessionEnergyInput 估算一次训练消耗所需的数据
+type SessionEnergyInput struct {
+	BodyWeight  float64     // kg，为0时使用默认体重
+	Minutes     float64     // 实际训练时长，为0时按各组用时累计
+	Sets        []EnergySet // 已完成的组，没有时按 FallbackMET 和时长估算
+	FallbackMET float64
+}
+
+// ActivityCalories 按 MET 计算消耗：kcal = MET × 体重(kg) × 时长(小时)
+func ActivityCalories(met, weightKg, minutes float64) float64 {
+	return met * weightKg * minutes / 60
+}
+
+// EstimateSessionEnergy 估算一次训练的消耗热量
+//
+// MET 取各组按用时加权的平均值（组间休息已包含在 Compendium 力量训练的 MET 中），
+// 有实际时长时按实际时长计算，否则按各组用时累计。
+func EstimateSessionEnergy(input SessionEnergyInput) models.EnergyEstimate {
+	weight := input.BodyWeight
+	if weight <= 0 {
+		weight = DefaultBodyWeightKg
+	}
+
+	met := input.FallbackMET
+	if met <= 0 {
+		met = models.DefaultMET
+	}
+	var setSeconds, weighted float64
+	for _, set := range input.Sets {
+		setMET := set.MET
+		if setMET <= 0 {
+			setMET = models.DefaultMET
+		}
+		seconds := set.Seconds()
+		setSeconds += seconds
+		weighted += setMET * seconds
+	}
+	if setSeconds > 0 {
+		met = weighted / setSeconds
+	}
+
+	// 实际时长比已完成各组的用时还短时（如训练后补录），以各组用时为准
+	minutes := input.Minutes
+	if minutes > MaxSessionMinutes {
+		if setSeconds > 0 {
+			minutes = setSeconds / 60
+		} else {
+			minutes = MaxSessionMinutes
+		}
+	}
+	if minutes < setSeconds/60 {
+		minutes = setSeconds / 60
+	}
+
+	calories := int(math.Round(ActivityCalories(met, weight, minutes)))
+	return models.EnergyEstimate{
+		Calories:          calories,
+		EstimatedCalories: calories,
+		Source:            models.CaloriesSourceEstimated,
+		MET:               roundHundredth(met),
+		Minutes:           roundTenth(minutes),
+		BodyWeight:        weight,
+		Sets:              len(input.Sets),
+	}
+}
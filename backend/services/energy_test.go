@@ -0,0 +1,66 @@
+package services
+
+import (
+	"testing"
+
+	"gymates-backend/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestEnergyEstimate 测试按 MET、体重和时长估算训练消耗
+func TestEnergyEstimate(t *testing.T) {
+	assert.Equal(t, 480.0, ActivityCalories(6, 80, 60))
+	assert.Equal(t, 100.0, EnergySet{Reps: 10, RestSeconds: 60}.Seconds())
+	assert.Equal(t, 120.0, EnergySet{DurationSeconds: 30}.Seconds())
+	assert.Equal(t, 135.0, EnergySet{}.Seconds())
+
+	// 卧推3组（MET 6.0，每组100秒）+ 飞鸟2组（MET 3.5，每组108秒）
+	var sets []EnergySet
+	for i := 0; i < 3; i++ {
+		sets = append(sets, EnergySet{MET: 6, Reps: 10, RestSeconds: 60})
+	}
+	for i := 0; i < 2; i++ {
+		sets = append(sets, EnergySet{MET: 3.5, Reps: 12, RestSeconds: 60})
+	}
+
+	tests := []struct {
+		name         string
+		input        SessionEnergyInput
+		wantCalories int
+		wantMET      float64
+		wantMinutes  float64
+	}{
+		{name: "按各组用时累计", input: SessionEnergyInput{BodyWeight: 80, Sets: sets}, wantCalories: 57, wantMET: 4.95, wantMinutes: 8.6},
+		{name: "按实际训练时长", input: SessionEnergyInput{BodyWeight: 80, Minutes: 45, Sets: sets}, wantCalories: 297, wantMET: 4.95, wantMinutes: 45},
+		{name: "实际时长短于各组用时", input: SessionEnergyInput{BodyWeight: 80, Minutes: 2, Sets: sets}, wantCalories: 57, wantMET: 4.95, wantMinutes: 8.6},
+		{name: "忘记结束的会话", input: SessionEnergyInput{BodyWeight: 80, Minutes: 600, Sets: sets}, wantCalories: 57, wantMET: 4.95, wantMinutes: 8.6},
+		{name: "没有组记录且忘记结束时按最长时长封顶", input: SessionEnergyInput{BodyWeight: 60, Minutes: 600, FallbackMET: 5}, wantCalories: 1800, wantMET: 5, wantMinutes: 360},
+		{name: "没有组记录时按计划MET", input: SessionEnergyInput{BodyWeight: 60, Minutes: 30, FallbackMET: 8}, wantCalories: 240, wantMET: 8, wantMinutes: 30},
+		{name: "未填体重用默认值", input: SessionEnergyInput{Minutes: 30}, wantCalories: 175, wantMET: models.DefaultMET, wantMinutes: 30},
+		{name: "没有任何记录", input: SessionEnergyInput{BodyWeight: 80}, wantCalories: 0, wantMET: models.DefaultMET, wantMinutes: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			estimate := EstimateSessionEnergy(tt.input)
+			assert.Equal(t, tt.wantCalories, estimate.Calories)
+			assert.Equal(t, tt.wantCalories, estimate.EstimatedCalories)
+			assert.Equal(t, tt.wantMET, estimate.MET)
+			assert.Equal(t, tt.wantMinutes, estimate.Minutes)
+			assert.Equal(t, models.CaloriesSourceEstimated, estimate.Source)
+		})
+	}
+
+	t.Run("训练消耗计入营养目标", func(t *testing.T) {
+		target, err := NutritionTargets(NutritionProfile{
+			Gender: models.GenderMale, Age: 25, Height: 175.5, Weight: 70, Goal: "维持", DailyExerciseCalories: 300,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 1677.0, target.BMR)
+		assert.Equal(t, 300.0, target.ExerciseCalories)
+		assert.Equal(t, 2312.0, target.TDEE)
+		assert.Equal(t, 1.379, target.ActivityFactor)
+	})
+}
@@ -0,0 +1,125 @@
+package services
+
+import (
+	"strings"
+	"unicode"
+
+	"gymates-backend/models"
+)
+
+// DefaultMatchThreshold 模糊匹配的最低相似度
+const DefaultMatchThreshold = 0.75
+
+// ExerciseMatch 动作名称匹配结果
+type ExerciseMatch struct {
+	Entry     models.ExerciseLibrary
+	Score     float64
+	MatchedBy string // name/alias/fuzzy
+}
+
+// ExerciseMatcher 将自由输入的动作名称匹配到动作库条目
+type ExerciseMatcher struct {
+	entries   []matcherEntry
+	Threshold float64
+}
+
+type matcherEntry struct {
+	entry   models.ExerciseLibrary
+	name    string
+	aliases []string
+}
+
+// NewExerciseMatcher 基于动作库条目创建匹配器
+func NewExerciseMatcher(library []models.ExerciseLibrary) *ExerciseMatcher {
+	matcher := &ExerciseMatcher{Threshold: DefaultMatchThreshold}
+	for _, entry := range library {
+		item := matcherEntry{entry: entry, name: NormalizeExerciseName(entry.Name)}
+
+		// 英文名按别名处理
+		for _, alias := range append([]string{entry.NameEN}, entry.AliasList()...) {
+			if normalized := NormalizeExerciseName(alias); normalized != "" {
+				item.aliases = append(item.aliases, normalized)
+			}
+		}
+		matcher.entries = append(matcher.entries, item)
+	}
+	return matcher
+}
+
+// Match 按 名称完全匹配 > 别名完全匹配 > 模糊匹配 的顺序查找动作
+func (m *ExerciseMatcher) Match(name string) (ExerciseMatch, bool) {
+	normalized := NormalizeExerciseName(name)
+	if normalized == "" {
+		return ExerciseMatch{}, false
+	}
+
+	for _, item := range m.entries {
+		if item.name == normalized {
+			return ExerciseMatch{Entry: item.entry, Score: 1, MatchedBy: "name"}, true
+		}
+	}
+	for _, item := range m.entries {
+		for _, alias := range item.aliases {
+			if alias == normalized {
+				return ExerciseMatch{Entry: item.entry, Score: 1, MatchedBy: "alias"}, true
+			}
+		}
+	}
+
+	var best ExerciseMatch
+	for _, item := range m.entries {
+		for _, candidate := range append([]string{item.name}, item.aliases...) {
+			if score := similarity(normalized, candidate); score > best.Score {
+				best = ExerciseMatch{Entry: item.entry, Score: score, MatchedBy: "fuzzy"}
+			}
+		}
+	}
+	if best.Score >= m.Threshold {
+		return best, true
+	}
+	return ExerciseMatch{}, false
+}
+
+// NormalizeExerciseName 统一大小写并去掉空格和标点，便于比较
+func NormalizeExerciseName(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(name) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// similarity 基于编辑距离的相似度，取值0-1
+func similarity(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	longest := len(ra)
+	if len(rb) > longest {
+		longest = len(rb)
+	}
+	if longest == 0 {
+		return 0
+	}
+	return 1 - float64(levenshtein(ra, rb))/float64(longest)
+}
+
+func levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,39 @@
+package geo
+
+import (
+	"math"
+
+	"gymates-backend/services/track"
+)
+
+// userCoordinateDecimals 用户坐标保留的小数位，两位约为1公里
+const userCoordinateDecimals = 2
+
+// CoarsenUser 粗化用户坐标，返回保存用的经纬度和 geohash，精确位置不落库
+func CoarsenUser(lat, lon float64) (float64, float64, string) {
+	p := math.Pow(10, userCoordinateDecimals)
+	lat = math.Round(lat*p) / p
+	lon = math.Round(lon*p) / p
+	return lat, lon, Encode(lat, lon, UserPrecision)
+}
+
+// DistanceKm 两点之间的距离（公里）
+func DistanceKm(lat1, lon1, lat2, lon2 float64) float64 {
+	return track.Haversine(lat1, lon1, lat2, lon2) / 1000
+}
+
+// ApproxKm 对外展示的用户间距离：取整公里，最少1公里，避免通过距离反推精确位置
+func ApproxKm(km float64) float64 {
+	return math.Max(1, math.Round(km))
+}
+
+// BoundingBox 以 (lat, lon) 为中心、radiusKm 为半径的经纬度范围
+func BoundingBox(lat, lon, radiusKm float64) (minLat, maxLat, minLon, maxLon float64) {
+	const kmPerDegree = 111.32
+	dLat := radiusKm / kmPerDegree
+	dLon := 180.0
+	if cos := math.Cos(lat * math.Pi / 180); cos > 1e-6 {
+		dLon = math.Min(radiusKm/(kmPerDegree*cos), 180)
+	}
+	return math.Max(lat-dLat, -90), math.Min(lat+dLat, 90), lon - dLon, lon + dLon
+}
@@ -0,0 +1,138 @@
+package geo
+
+import (
+	"math"
+	"strings"
+)
+
+const base32 = "0123456789bcdefghjkmnpqrstuvwxyz"
+
+// 地理哈希精度：用户坐标已经过粗化，6位足够；健身房保存9位；查询时最多覆盖 maxCoverCells 个前缀
+const (
+	UserPrecision = 6
+	GymPrecision  = 9
+	maxCoverCells = 16
+)
+
+// Encode 把经纬度编码为指定长度的 geohash
+func Encode(lat, lon float64, precision int) string {
+	minLat, maxLat := -90.0, 90.0
+	minLon, maxLon := -180.0, 180.0
+	var sb strings.Builder
+	bit, ch, even := 0, 0, true
+	for sb.Len() < precision {
+		if even {
+			mid := (minLon + maxLon) / 2
+			if lon >= mid {
+				ch = ch<<1 | 1
+				minLon = mid
+			} else {
+				ch <<= 1
+				maxLon = mid
+			}
+		} else {
+			mid := (minLat + maxLat) / 2
+			if lat >= mid {
+				ch = ch<<1 | 1
+				minLat = mid
+			} else {
+				ch <<= 1
+				maxLat = mid
+			}
+		}
+		even = !even
+		if bit++; bit == 5 {
+			sb.WriteByte(base32[ch])
+			bit, ch = 0, 0
+		}
+	}
+	return sb.String()
+}
+
+// Bounds geohash 对应格子的经纬度范围，包含非法字符时返回全球范围
+func Bounds(hash string) (minLat, maxLat, minLon, maxLon float64) {
+	minLat, maxLat, minLon, maxLon = -90, 90, -180, 180
+	even := true
+	for _, c := range hash {
+		idx := strings.IndexRune(base32, c)
+		if idx < 0 {
+			return -90, 90, -180, 180
+		}
+		for mask := 16; mask > 0; mask >>= 1 {
+			if even {
+				mid := (minLon + maxLon) / 2
+				if idx&mask != 0 {
+					minLon = mid
+				} else {
+					maxLon = mid
+				}
+			} else {
+				mid := (minLat + maxLat) / 2
+				if idx&mask != 0 {
+					minLat = mid
+				} else {
+					maxLat = mid
+				}
+			}
+			even = !even
+		}
+	}
+	return minLat, maxLat, minLon, maxLon
+}
+
+// CellSize 指定精度下一个格子的高度和宽度（度）
+func CellSize(precision int) (latDeg, lonDeg float64) {
+	bits := 5 * precision
+	lonBits := (bits + 1) / 2
+	latBits := bits / 2
+	return 180 / math.Pow(2, float64(latBits)), 360 / math.Pow(2, float64(lonBits))
+}
+
+// Cover 覆盖经纬度范围的 geohash 前缀
+//
+// 选择格子数不超过16的最高精度（不超过 UserPrecision），
+// 数据库里对前缀做 LIKE 'xxx%' 查询即可用上普通索引，候选结果再按实际距离过滤。
+// 经度超出 ±180 时绕回另一侧。
+func Cover(minLat, maxLat, minLon, maxLon float64) []string {
+	precision := UserPrecision
+	for ; precision > 1; precision-- {
+		h, w := CellSize(precision)
+		rows := math.Floor((maxLat-minLat)/h) + 2
+		cols := math.Floor((maxLon-minLon)/w) + 2
+		if rows*cols <= maxCoverCells {
+			break
+		}
+	}
+	h, w := CellSize(precision)
+
+	seen := make(map[string]bool)
+	var prefixes []string
+	for lat := minLat; ; lat += h {
+		lat = math.Min(lat, maxLat)
+		for lon := minLon; ; lon += w {
+			lon = math.Min(lon, maxLon)
+			if hash := Encode(lat, wrapLon(lon), precision); !seen[hash] {
+				seen[hash] = true
+				prefixes = append(prefixes, hash)
+			}
+			if lon >= maxLon {
+				break
+			}
+		}
+		if lat >= maxLat {
+			break
+		}
+	}
+	return prefixes
+}
+
+// wrapLon 把经度规整到 [-180, 180)
+func wrapLon(lon float64) float64 {
+	for lon >= 180 {
+		lon -= 360
+	}
+	for lon < -180 {
+		lon += 360
+	}
+	return lon
+}
@@ -0,0 +1,74 @@
+package geo
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestGeohash 测试 geohash 编码、覆盖前缀和坐标粗化
+func TestGeohash(t *testing.T) {
+	t.Run("编码和格子范围", func(t *testing.T) {
+		assert.Equal(t, "u4pruydqqvj", Encode(57.64911, 10.40744, 11))
+		assert.Equal(t, "u4pru", Encode(57.64911, 10.40744, 5))
+
+		minLat, maxLat, minLon, maxLon := Bounds("u4pru")
+		assert.True(t, minLat <= 57.64911 && 57.64911 <= maxLat)
+		assert.True(t, minLon <= 10.40744 && 10.40744 <= maxLon)
+		h, w := CellSize(5)
+		assert.InDelta(t, h, maxLat-minLat, 1e-9)
+		assert.InDelta(t, w, maxLon-minLon, 1e-9)
+	})
+
+	t.Run("覆盖前缀包含范围内的所有点", func(t *testing.T) {
+		tests := []struct {
+			name     string
+			lat, lon float64
+			radiusKm float64
+		}{
+			{"城市内", 39.9042, 116.4074, 5},
+			{"较大范围", 31.23, 121.47, 50},
+			{"跨越180度经线", -16.5, 179.99, 20},
+			{"靠近赤道和本初子午线", 0.01, -0.01, 10},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				prefixes := Cover(BoundingBox(tt.lat, tt.lon, tt.radiusKm))
+				assert.LessOrEqual(t, len(prefixes), 16)
+				covered := func(lat, lon float64) bool {
+					hash := Encode(lat, lon, UserPrecision)
+					for _, prefix := range prefixes {
+						if strings.HasPrefix(hash, prefix) {
+							return true
+						}
+					}
+					return false
+				}
+				// 在圆周和中心附近取点
+				for i := 0; i < 36; i++ {
+					angle := float64(i) * 10 * math.Pi / 180
+					for _, r := range []float64{0, 0.5, 0.99} {
+						dLat := tt.radiusKm * r / 111.32 * math.Cos(angle)
+						dLon := tt.radiusKm * r / (111.32 * math.Cos(tt.lat*math.Pi/180)) * math.Sin(angle)
+						lon := tt.lon + dLon
+						if lon >= 180 {
+							lon -= 360
+						}
+						assert.True(t, covered(tt.lat+dLat, lon), "%.4f,%.4f", tt.lat+dLat, lon)
+					}
+				}
+			})
+		}
+	})
+
+	t.Run("用户坐标粗化", func(t *testing.T) {
+		lat, lon, hash := CoarsenUser(39.90423, 116.40739)
+		assert.Equal(t, 39.9, lat)
+		assert.Equal(t, 116.41, lon)
+		assert.Equal(t, Encode(39.9, 116.41, 6), hash)
+		assert.Equal(t, 1.0, ApproxKm(0.2))
+		assert.Equal(t, 3.0, ApproxKm(2.6))
+	})
+}
@@ -0,0 +1,206 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"gymates-backend/models"
+)
+
+// InjuryDownWeightFactor 降权动作的建议重量系数
+const InjuryDownWeightFactor = 0.7
+
+// InjuryFilter 根据用户生效中的伤病排除或降权禁忌动作
+type InjuryFilter struct {
+	injuries []models.Injury
+}
+
+// NewInjuryFilter 基于生效中的伤病创建过滤器
+func NewInjuryFilter(injuries []models.Injury) *InjuryFilter {
+	return &InjuryFilter{injuries: injuries}
+}
+
+// Injuries 参与过滤的伤病
+func (f *InjuryFilter) Injuries() []models.Injury {
+	return f.injuries
+}
+
+// Assess 判断动作是否受伤病限制，多处伤病时排除优先于降权
+//
+// 轻度伤病只降低相关动作强度；中度排除禁忌动作模式，仅涉及受牵连肌群的动作降权；
+// 重度排除所有相关动作。
+func (f *InjuryFilter) Assess(entry models.ExerciseLibrary) (models.ExerciseRestriction, bool) {
+	var result models.ExerciseRestriction
+	found := false
+	for _, injury := range f.injuries {
+		rule, ok := models.LookupInjuryRule(injury.BodyRegion)
+		if !ok {
+			continue
+		}
+
+		matched := ""
+		patternHit := containsString(rule.Patterns, entry.MovementPattern)
+		if patternHit {
+			matched = "动作模式为" + models.MovementPatternNames[entry.MovementPattern]
+		} else {
+			for _, muscle := range entry.PrimaryMuscleList() {
+				if containsString(rule.Muscles, muscle) {
+					info, _ := models.LookupMuscle(muscle)
+					matched = "主要发力肌群为" + info.Name
+					break
+				}
+			}
+		}
+		if matched == "" {
+			continue
+		}
+
+		action := models.RestrictionDownWeight
+		switch injury.Severity {
+		case models.InjurySeveritySevere:
+			action = models.RestrictionExclude
+		case models.InjurySeverityModerate:
+			if patternHit {
+				action = models.RestrictionExclude
+			}
+		}
+		if found && (result.Action == models.RestrictionExclude || action == models.RestrictionDownWeight) {
+			continue
+		}
+
+		found = true
+		result = models.ExerciseRestriction{
+			ExerciseID: entry.ID,
+			Name:       entry.Name,
+			Action:     action,
+			InjuryID:   injury.ID,
+			BodyRegion: injury.BodyRegion,
+			Severity:   injury.Severity,
+			Reason: fmt.Sprintf("%s%s伤病：%s%s，%s",
+				rule.Name, models.InjurySeverityNames[injury.Severity], entry.Name, matched, rule.Reason),
+		}
+	}
+	return result, found
+}
+
+// InjuryFilterResult 伤病过滤结果
+type InjuryFilterResult struct {
+	Exercises    []models.ExerciseLibrary            // 保留的动作，未受限在前、降权在后
+	DownWeighted map[uint]models.ExerciseRestriction // 保留但需降低强度的动作
+	Restrictions []models.ExerciseRestriction        // 被排除以及保留但降权的动作说明
+}
+
+// Filter 过滤候选动作：排除禁忌动作，降权动作排到未受限动作之后，再取前 limit 个（limit<=0 不限制）
+func (f *InjuryFilter) Filter(entries []models.ExerciseLibrary, limit int) InjuryFilterResult {
+	result := InjuryFilterResult{
+		Exercises:    []models.ExerciseLibrary{},
+		DownWeighted: map[uint]models.ExerciseRestriction{},
+		Restrictions: []models.ExerciseRestriction{},
+	}
+
+	var downWeighted []models.ExerciseLibrary
+	for _, entry := range entries {
+		restriction, ok := f.Assess(entry)
+		switch {
+		case !ok:
+			result.Exercises = append(result.Exercises, entry)
+		case restriction.Action == models.RestrictionExclude:
+			result.Restrictions = append(result.Restrictions, restriction)
+		default:
+			result.DownWeighted[entry.ID] = restriction
+			downWeighted = append(downWeighted, entry)
+		}
+	}
+	result.Exercises = append(result.Exercises, downWeighted...)
+	if limit > 0 && len(result.Exercises) > limit {
+		result.Exercises = result.Exercises[:limit]
+	}
+
+	// 只说明实际保留下来的降权动作
+	for _, entry := range result.Exercises {
+		if restriction, ok := result.DownWeighted[entry.ID]; ok {
+			result.Restrictions = append(result.Restrictions, restriction)
+		}
+	}
+	return result
+}
+
+// DownWeight 降低受伤病牵连动作的强度：少一组（至少2组）、重量打折，并写明原因
+func DownWeight(exercise *models.RecommendedExercise, restriction models.ExerciseRestriction) {
+	if exercise.Sets > 2 {
+		exercise.Sets--
+	}
+	exercise.Weight = math.Round(exercise.Weight*InjuryDownWeightFactor*10) / 10
+	note := "已降低强度：" + restriction.Reason
+	if exercise.Notes != "" {
+		note = exercise.Notes + "；" + note
+	}
+	exercise.Notes = note
+}
+
+// PromptSection 生成供AI教练系统提示词使用的伤病说明，没有伤病时返回空字符串
+func (f *InjuryFilter) PromptSection() string {
+	if len(f.injuries) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString("用户当前的伤病与身体限制（给出任何动作建议前必须遵守）：")
+	for _, injury := range f.injuries {
+		rule, ok := models.LookupInjuryRule(injury.BodyRegion)
+		if !ok {
+			continue
+		}
+		period := "自" + injury.StartDate + "起"
+		if injury.EndDate != "" {
+			period = injury.StartDate + "至" + injury.EndDate
+		}
+		fmt.Fprintf(&b, "\n- %s%s伤病（%s）", rule.Name, models.InjurySeverityNames[injury.Severity], period)
+		if injury.Notes != "" {
+			fmt.Fprintf(&b, "，备注：%s", injury.Notes)
+		}
+		b.WriteString("。" + InjuryAdvice(rule, injury.Severity))
+	}
+	return b.String()
+}
+
+// InjuryAdvice 按部位规则和严重程度生成避开/减量建议
+func InjuryAdvice(rule models.InjuryRule, severity string) string {
+	var patterns, muscles []string
+	for _, pattern := range rule.Patterns {
+		patterns = append(patterns, models.MovementPatternNames[pattern])
+	}
+	for _, key := range rule.Muscles {
+		if muscle, ok := models.LookupMuscle(key); ok {
+			muscles = append(muscles, muscle.Name)
+		}
+	}
+
+	var advice []string
+	switch severity {
+	case models.InjurySeveritySevere:
+		if len(patterns) > 0 {
+			advice = append(advice, "不要安排"+strings.Join(patterns, "、")+"类动作")
+		}
+		advice = append(advice, "不要安排以"+strings.Join(muscles, "、")+"为主要发力肌群的动作")
+	case models.InjurySeverityModerate:
+		if len(patterns) > 0 {
+			advice = append(advice, "不要安排"+strings.Join(patterns, "、")+"类动作")
+		}
+		advice = append(advice, "以"+strings.Join(muscles, "、")+"为主要发力肌群的动作需降低重量和组数")
+	default:
+		targets := append(patterns, muscles...)
+		advice = append(advice, strings.Join(targets, "、")+"相关动作需降低重量和组数")
+	}
+	return strings.Join(advice, "；") + "。原因：" + rule.Reason + "。"
+}
+
+func containsString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,189 @@
+package matching
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"gymates-backend/models"
+)
+
+// slotOrder 训练时段的固定顺序
+var slotOrder = []string{
+	models.TrainingSlotEarlyMorning,
+	models.TrainingSlotMorning,
+	models.TrainingSlotNoon,
+	models.TrainingSlotAfternoon,
+	models.TrainingSlotEvening,
+	models.TrainingSlotNight,
+}
+
+// 推断训练时段时，一个时段至少要占训练次数的比例和最少次数
+const (
+	inferMinShare    = 0.25
+	inferMinSessions = 2
+)
+
+var yearsPattern = regexp.MustCompile(`(\d+(?:\.\d+)?)\s*年`)
+
+// Profile 参与匹配的用户画像
+type Profile struct {
+	Latitude  *float64
+	Longitude *float64
+	City      string
+	Goal      string // 归一后的目标，见 GoalCategory
+	Level     int    // 0 未知，1 初级，2 中级，3 高级
+	Slots     []string
+	GymID     uint
+	Gym       string
+}
+
+// NewProfile 由用户资料生成画像，用户没填训练时段时使用 inferred
+func NewProfile(user models.User, inferred []string) Profile {
+	slots := NormalizeSlots(user.TrainingSlots())
+	if len(slots) == 0 {
+		slots = NormalizeSlots(inferred)
+	}
+	var gymID uint
+	if user.GymID != nil {
+		gymID = *user.GymID
+	}
+	return Profile{
+		Latitude:  user.Latitude,
+		Longitude: user.Longitude,
+		City:      strings.TrimSpace(user.Location),
+		Goal:      GoalCategory(user.Goal),
+		Level:     ExperienceLevel(user.Experience),
+		Slots:     slots,
+		Gym:       strings.TrimSpace(user.HomeGym),
+		GymID:     gymID,
+	}
+}
+
+// HasLocation 是否有坐标
+func (p Profile) HasLocation() bool {
+	return p.Latitude != nil && p.Longitude != nil
+}
+
+// GoalCategory 把自由填写的健身目标归到 增肌/减脂/塑形/力量/耐力/健康，无法识别时返回空
+func GoalCategory(goal string) string {
+	switch {
+	case goal == "":
+		return ""
+	case strings.Contains(goal, "增肌"), strings.Contains(goal, "增重"):
+		return "增肌"
+	case strings.Contains(goal, "减脂"), strings.Contains(goal, "减重"), strings.Contains(goal, "减肥"):
+		return "减脂"
+	case strings.Contains(goal, "塑形"), strings.Contains(goal, "体态"):
+		return "塑形"
+	case strings.Contains(goal, "力量"):
+		return "力量"
+	case strings.Contains(goal, "耐力"), strings.Contains(goal, "有氧"), strings.Contains(goal, "跑"):
+		return "耐力"
+	case strings.Contains(goal, "健康"), strings.Contains(goal, "维持"), strings.Contains(goal, "保持"):
+		return "健康"
+	default:
+		return ""
+	}
+}
+
+// ExperienceLevel 训练水平，支持 初级/中级/高级 和 "3年" 这样的训练年限
+//
+// 年限不足1年为初级，1-3年为中级，3年以上为高级；无法识别时返回0。
+func ExperienceLevel(experience string) int {
+	switch {
+	case strings.Contains(experience, "初级"), strings.Contains(experience, "新手"):
+		return 1
+	case strings.Contains(experience, "中级"):
+		return 2
+	case strings.Contains(experience, "高级"), strings.Contains(experience, "专业"):
+		return 3
+	}
+	match := yearsPattern.FindStringSubmatch(experience)
+	if match == nil {
+		return 0
+	}
+	years, err := strconv.ParseFloat(match[1], 64)
+	if err != nil {
+		return 0
+	}
+	switch {
+	case years < 1:
+		return 1
+	case years <= 3:
+		return 2
+	default:
+		return 3
+	}
+}
+
+// LevelName 训练水平中文名
+func LevelName(level int) string {
+	switch level {
+	case 1:
+		return "初级"
+	case 2:
+		return "中级"
+	case 3:
+		return "高级"
+	default:
+		return ""
+	}
+}
+
+// NormalizeSlots 去重、丢弃未知时段并按时间先后排序
+func NormalizeSlots(slots []string) []string {
+	seen := make(map[string]bool, len(slots))
+	for _, slot := range slots {
+		seen[strings.TrimSpace(slot)] = true
+	}
+	var result []string
+	for _, slot := range slotOrder {
+		if seen[slot] {
+			result = append(result, slot)
+		}
+	}
+	return result
+}
+
+// SlotOf 某个小时所在的训练时段，0-5点不属于任何时段
+func SlotOf(hour int) string {
+	switch {
+	case hour >= 5 && hour < 8:
+		return models.TrainingSlotEarlyMorning
+	case hour >= 8 && hour < 12:
+		return models.TrainingSlotMorning
+	case hour >= 12 && hour < 14:
+		return models.TrainingSlotNoon
+	case hour >= 14 && hour < 18:
+		return models.TrainingSlotAfternoon
+	case hour >= 18 && hour < 21:
+		return models.TrainingSlotEvening
+	case hour >= 21:
+		return models.TrainingSlotNight
+	default:
+		return ""
+	}
+}
+
+// InferSlots 由训练开始时间推断常训练的时段
+func InferSlots(starts []time.Time, loc *time.Location) []string {
+	if loc == nil {
+		loc = time.UTC
+	}
+	counts := make(map[string]int)
+	for _, start := range starts {
+		if slot := SlotOf(start.In(loc).Hour()); slot != "" {
+			counts[slot]++
+		}
+	}
+	var slots []string
+	for _, slot := range slotOrder {
+		n := counts[slot]
+		if n >= inferMinSessions && float64(n) >= inferMinShare*float64(len(starts)) {
+			slots = append(slots, slot)
+		}
+	}
+	return slots
+}
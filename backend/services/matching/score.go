@@ -0,0 +1,148 @@
+package matching
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"gymates-backend/models"
+	"gymates-backend/services/geo"
+)
+
+// 各维度权重，合计为1
+const (
+	WeightDistance = 0.30
+	WeightGoal     = 0.25
+	WeightSchedule = 0.20
+	WeightLevel    = 0.15
+	WeightGym      = 0.10
+)
+
+// sameCityScore 没有坐标时同城的距离得分
+const sameCityScore = 0.5
+
+// relatedGoals 相近的健身目标
+var relatedGoals = map[[2]string]bool{
+	{"增肌", "力量"}: true,
+	{"减脂", "塑形"}: true,
+	{"减脂", "耐力"}: true,
+	{"增肌", "塑形"}: true,
+	{"塑形", "健康"}: true,
+	{"耐力", "健康"}: true,
+}
+
+// Match 一个候选人的匹配结果
+type Match struct {
+	Score      float64  // 0-100
+	DistanceKm *float64 // 双方都有坐标时的距离，见 geo.ApproxKm
+	InRange    bool     // 是否在距离范围内，没有坐标时视为在范围内
+	Reasons    []models.MatchReason
+}
+
+// Score 计算 other 与 me 的匹配度，radiusKm 为距离范围
+func Score(me, other Profile, radiusKm float64) Match {
+	match := Match{InRange: true}
+	var reasons []models.MatchReason
+	var weights []float64
+	add := func(factor string, weight, score float64, text string) {
+		if score <= 0 {
+			return
+		}
+		match.Score += weight * score
+		reasons = append(reasons, models.MatchReason{Factor: factor, Score: round(score, 2), Text: text})
+		weights = append(weights, weight*score)
+	}
+
+	if me.HasLocation() && other.HasLocation() {
+		km := geo.DistanceKm(*me.Latitude, *me.Longitude, *other.Latitude, *other.Longitude)
+		approx := geo.ApproxKm(km)
+		match.DistanceKm = &approx
+		match.InRange = km <= radiusKm
+		if match.InRange && radiusKm > 0 {
+			add(models.MatchFactorDistance, WeightDistance, 1-km/radiusKm, distanceText(km))
+		}
+	} else if me.City != "" && strings.EqualFold(me.City, other.City) {
+		add(models.MatchFactorDistance, WeightDistance, sameCityScore, "同在"+other.City)
+	}
+
+	if me.Goal != "" && other.Goal != "" {
+		if me.Goal == other.Goal {
+			add(models.MatchFactorGoal, WeightGoal, 1, "健身目标都是"+me.Goal)
+		} else if relatedGoals[[2]string{me.Goal, other.Goal}] || relatedGoals[[2]string{other.Goal, me.Goal}] {
+			add(models.MatchFactorGoal, WeightGoal, 0.5, fmt.Sprintf("健身目标相近（%s / %s）", me.Goal, other.Goal))
+		}
+	}
+
+	if common := commonSlots(me.Slots, other.Slots); len(common) > 0 {
+		union := len(me.Slots) + len(other.Slots) - len(common)
+		names := make([]string, len(common))
+		for i, slot := range common {
+			names[i] = models.TrainingSlotNames[slot]
+		}
+		add(models.MatchFactorSchedule, WeightSchedule, float64(len(common))/float64(union),
+			"都常在"+strings.Join(names, "、")+"训练")
+	}
+
+	if me.Level > 0 && other.Level > 0 {
+		switch diff := me.Level - other.Level; {
+		case diff == 0:
+			add(models.MatchFactorLevel, WeightLevel, 1, "训练水平相同（"+LevelName(me.Level)+"）")
+		case diff == 1 || diff == -1:
+			add(models.MatchFactorLevel, WeightLevel, 0.5, "训练水平相近（"+LevelName(other.Level)+"）")
+		}
+	}
+
+	if sameGym(me, other) {
+		add(models.MatchFactorGym, WeightGym, 1, "在同一家健身房："+other.Gym)
+	}
+
+	// 理由按对总分的贡献从高到低
+	order := make([]int, len(reasons))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return weights[order[a]] > weights[order[b]] })
+	match.Reasons = make([]models.MatchReason, len(reasons))
+	for i, idx := range order {
+		match.Reasons[i] = reasons[idx]
+	}
+	match.Score = round(match.Score*100, 1)
+	return match
+}
+
+// sameGym 双方都绑定了健身房时按健身房判断，否则比较填写的健身房名称
+func sameGym(me, other Profile) bool {
+	if me.GymID != 0 && other.GymID != 0 {
+		return me.GymID == other.GymID
+	}
+	return me.Gym != "" && strings.EqualFold(me.Gym, other.Gym)
+}
+
+// distanceText 距离的描述
+func distanceText(km float64) string {
+	if km < 1 {
+		return "距离不到1公里"
+	}
+	return fmt.Sprintf("相距约%.0f公里", geo.ApproxKm(km))
+}
+
+// commonSlots 两组时段的交集，保持 a 的顺序
+func commonSlots(a, b []string) []string {
+	set := make(map[string]bool, len(b))
+	for _, slot := range b {
+		set[slot] = true
+	}
+	var common []string
+	for _, slot := range a {
+		if set[slot] {
+			common = append(common, slot)
+		}
+	}
+	return common
+}
+
+func round(v float64, places int) float64 {
+	p := math.Pow(10, float64(places))
+	return math.Round(v*p) / p
+}
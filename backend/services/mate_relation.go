@@ -0,0 +1,144 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"gymates-backend/models"
+)
+
+// MateAction 对搭子关系的操作
+type MateAction string
+
+// 搭子关系操作
+const (
+	MateActionRequest MateAction = "request" // 发送请求
+	MateActionAccept  MateAction = "accept"  // 接受对方的请求
+	MateActionDecline MateAction = "decline" // 拒绝对方的请求
+	MateActionCancel  MateAction = "cancel"  // 撤回自己的请求
+	MateActionRemove  MateAction = "remove"  // 解除搭子关系
+	MateActionBlock   MateAction = "block"   // 拉黑对方
+	MateActionUnblock MateAction = "unblock" // 取消拉黑
+)
+
+// MateRequestCooldown 请求被拒绝后，发起方需要等待多久才能再次发送
+const MateRequestCooldown = 72 * time.Hour
+
+// 搭子关系状态变更的错误
+var (
+	ErrMateSelf             = errors.New("cannot add yourself as mate")
+	ErrMateAlreadyRequested = errors.New("mate request already sent")
+	ErrMateAlreadyMates     = errors.New("already mates")
+	ErrMateBlocked          = errors.New("mate relation is blocked")
+	ErrMateRequestCooldown  = errors.New("mate request was declined recently")
+	ErrMateNoPendingRequest = errors.New("no pending mate request")
+	ErrMateNotMates         = errors.New("not mates")
+	ErrMateNotBlocked       = errors.New("user is not blocked")
+	ErrMateUnknownAction    = errors.New("unknown mate action")
+)
+
+// TransitionMate 由 actorID 对与 otherID 的关系执行操作，成功时原地修改 rel
+//
+// rel.ID 为0表示两人之间还没有关系。状态转换：
+//
+//	无关系/declined/cancelled --request--> pending（对方也在等自己回应时直接 accepted）
+//	pending --accept/decline（接收方）--> accepted/declined
+//	pending --cancel（发起方）--> cancelled
+//	accepted --remove--> cancelled
+//	任意状态 --block--> blocked，双方都取消拉黑后 --unblock--> cancelled
+//
+// blocked 状态下除 block/unblock 外的操作都返回 ErrMateBlocked。
+func TransitionMate(rel *models.MateRelation, actorID, otherID uint, action MateAction, now time.Time) error {
+	if actorID == otherID {
+		return ErrMateSelf
+	}
+	if rel.ID == 0 {
+		rel.UserLowID, rel.UserHighID = models.MatePair(actorID, otherID)
+	}
+	requestedByActor := rel.RequesterID == actorID
+
+	switch action {
+	case MateActionBlock:
+		setMateBlocked(rel, actorID, true)
+		if rel.RequesterID == 0 {
+			rel.RequesterID = actorID
+		}
+		rel.Status = models.MateStatusBlocked
+	case MateActionUnblock:
+		if !rel.BlockedBy(actorID) {
+			return ErrMateNotBlocked
+		}
+		setMateBlocked(rel, actorID, false)
+		if !rel.BlockedBy(otherID) {
+			rel.Status = models.MateStatusCancelled
+		}
+	default:
+		if rel.Status == models.MateStatusBlocked {
+			return ErrMateBlocked
+		}
+		if err := transitionMateRequest(rel, actorID, requestedByActor, action, now); err != nil {
+			return err
+		}
+	}
+
+	rel.ActorID = actorID
+	return nil
+}
+
+// transitionMateRequest 请求、回应和解除
+func transitionMateRequest(rel *models.MateRelation, actorID uint, requestedByActor bool, action MateAction, now time.Time) error {
+	switch action {
+	case MateActionRequest:
+		switch rel.Status {
+		case models.MateStatusAccepted:
+			return ErrMateAlreadyMates
+		case models.MateStatusPending:
+			if requestedByActor {
+				return ErrMateAlreadyRequested
+			}
+			// 双方互相发送请求，视为同意
+			rel.Status = models.MateStatusAccepted
+			rel.RespondedAt = &now
+			return nil
+		case models.MateStatusDeclined:
+			if requestedByActor && rel.RespondedAt != nil && now.Sub(*rel.RespondedAt) < MateRequestCooldown {
+				return ErrMateRequestCooldown
+			}
+		}
+		rel.Status = models.MateStatusPending
+		rel.RequesterID = actorID
+		rel.RequestedAt = &now
+		rel.RespondedAt = nil
+	case MateActionAccept, MateActionDecline:
+		if rel.Status != models.MateStatusPending || requestedByActor {
+			return ErrMateNoPendingRequest
+		}
+		rel.Status = models.MateStatusAccepted
+		if action == MateActionDecline {
+			rel.Status = models.MateStatusDeclined
+		}
+		rel.RespondedAt = &now
+	case MateActionCancel:
+		if rel.Status != models.MateStatusPending || !requestedByActor {
+			return ErrMateNoPendingRequest
+		}
+		rel.Status = models.MateStatusCancelled
+	case MateActionRemove:
+		if rel.Status != models.MateStatusAccepted {
+			return ErrMateNotMates
+		}
+		rel.Status = models.MateStatusCancelled
+	default:
+		return ErrMateUnknownAction
+	}
+	return nil
+}
+
+// setMateBlocked 设置 userID 一方的拉黑标记
+func setMateBlocked(rel *models.MateRelation, userID uint, blocked bool) {
+	if rel.UserLowID == userID {
+		rel.LowBlocked = blocked
+	} else {
+		rel.HighBlocked = blocked
+	}
+}
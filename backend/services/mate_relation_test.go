@@ -0,0 +1,103 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"gymates-backend/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestMateTransitions 测试搭子关系状态机的每种转换
+func TestMateTransitions(t *testing.T) {
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	recently := now.Add(-time.Hour)
+	longAgo := now.Add(-MateRequestCooldown - time.Minute)
+	const a, b uint = 1, 2
+	relation := func(status string, requester uint) models.MateRelation {
+		return models.MateRelation{ID: 9, UserLowID: a, UserHighID: b, Status: status, RequesterID: requester}
+	}
+	declined := func(requester uint, at time.Time) models.MateRelation {
+		r := relation(models.MateStatusDeclined, requester)
+		r.RespondedAt = &at
+		return r
+	}
+	blocked := func(low, high bool) models.MateRelation {
+		r := relation(models.MateStatusBlocked, a)
+		r.LowBlocked, r.HighBlocked = low, high
+		return r
+	}
+
+	tests := []struct {
+		name      string
+		relation  models.MateRelation
+		actor     uint
+		action    MateAction
+		status    string
+		requester uint
+		err       error
+	}{
+		{"没有关系时发送请求", models.MateRelation{}, b, MateActionRequest, models.MateStatusPending, b, nil},
+		{"重复发送请求", relation(models.MateStatusPending, a), a, MateActionRequest, "", 0, ErrMateAlreadyRequested},
+		{"对方也发送请求视为同意", relation(models.MateStatusPending, a), b, MateActionRequest, models.MateStatusAccepted, a, nil},
+		{"已是搭子时发送请求", relation(models.MateStatusAccepted, a), b, MateActionRequest, "", 0, ErrMateAlreadyMates},
+		{"接收方接受", relation(models.MateStatusPending, a), b, MateActionAccept, models.MateStatusAccepted, a, nil},
+		{"发起方不能接受自己的请求", relation(models.MateStatusPending, a), a, MateActionAccept, "", 0, ErrMateNoPendingRequest},
+		{"接收方拒绝", relation(models.MateStatusPending, a), b, MateActionDecline, models.MateStatusDeclined, a, nil},
+		{"没有待处理请求时拒绝", relation(models.MateStatusAccepted, a), b, MateActionDecline, "", 0, ErrMateNoPendingRequest},
+		{"被拒绝后冷却期内再次请求", declined(a, recently), a, MateActionRequest, "", 0, ErrMateRequestCooldown},
+		{"被拒绝后冷却期外再次请求", declined(a, longAgo), a, MateActionRequest, models.MateStatusPending, a, nil},
+		{"拒绝的一方可以主动发送请求", declined(a, recently), b, MateActionRequest, models.MateStatusPending, b, nil},
+		{"发起方撤回请求", relation(models.MateStatusPending, a), a, MateActionCancel, models.MateStatusCancelled, a, nil},
+		{"接收方不能撤回请求", relation(models.MateStatusPending, a), b, MateActionCancel, "", 0, ErrMateNoPendingRequest},
+		{"撤回后可以再次请求", relation(models.MateStatusCancelled, a), a, MateActionRequest, models.MateStatusPending, a, nil},
+		{"任一方解除搭子", relation(models.MateStatusAccepted, a), b, MateActionRemove, models.MateStatusCancelled, a, nil},
+		{"不是搭子时解除", relation(models.MateStatusPending, a), a, MateActionRemove, "", 0, ErrMateNotMates},
+		{"拉黑搭子", relation(models.MateStatusAccepted, a), b, MateActionBlock, models.MateStatusBlocked, a, nil},
+		{"拉黑陌生人", models.MateRelation{}, b, MateActionBlock, models.MateStatusBlocked, b, nil},
+		{"被拉黑后发送请求", blocked(true, false), b, MateActionRequest, "", 0, ErrMateBlocked},
+		{"拉黑方也不能发送请求", blocked(true, false), a, MateActionRequest, "", 0, ErrMateBlocked},
+		{"被拉黑方不能取消拉黑", blocked(true, false), b, MateActionUnblock, "", 0, ErrMateNotBlocked},
+		{"被拉黑方也可以拉黑对方", blocked(true, false), b, MateActionBlock, models.MateStatusBlocked, a, nil},
+		{"取消拉黑后恢复为无关系", blocked(true, false), a, MateActionUnblock, models.MateStatusCancelled, a, nil},
+		{"互相拉黑时一方取消仍为拉黑", blocked(true, true), a, MateActionUnblock, models.MateStatusBlocked, a, nil},
+		{"不能添加自己", models.MateRelation{}, a, MateActionRequest, "", 0, ErrMateSelf},
+		{"未知操作", relation(models.MateStatusPending, a), b, "poke", "", 0, ErrMateUnknownAction},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rel := tt.relation
+			other := a
+			if tt.actor == a {
+				other = b
+			}
+			if tt.err == ErrMateSelf {
+				other = tt.actor
+			}
+			before := rel
+			err := TransitionMate(&rel, tt.actor, other, tt.action, now)
+			if tt.err != nil {
+				assert.ErrorIs(t, err, tt.err)
+				assert.Equal(t, before, rel, "失败时不修改关系")
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.status, rel.Status)
+			assert.Equal(t, tt.requester, rel.RequesterID)
+			assert.Equal(t, tt.actor, rel.ActorID)
+			assert.Equal(t, a, rel.UserLowID)
+			assert.Equal(t, b, rel.UserHighID)
+		})
+	}
+
+	t.Run("拉黑标记", func(t *testing.T) {
+		rel := blocked(true, true)
+		require.NoError(t, TransitionMate(&rel, b, a, MateActionUnblock, now))
+		assert.True(t, rel.BlockedBy(a))
+		assert.False(t, rel.BlockedBy(b))
+		require.NoError(t, TransitionMate(&rel, a, b, MateActionUnblock, now))
+		assert.Equal(t, models.MateStatusCancelled, rel.Status)
+	})
+}
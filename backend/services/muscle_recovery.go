@@ -0,0 +1,169 @@
+package services
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"gymates-backend/models"
+)
+
+// VolumeWindow 统计每周训练量的时间窗口（最近7天）
+const VolumeWindow = 7 * 24 * time.Hour
+
+// FatigueCapacity 刚完成该数量的硬组时恢复度为0
+const FatigueCapacity = 10.0
+
+// SecondaryMuscleFactor 次要发力肌群按半组计入训练量
+const SecondaryMuscleFactor = 0.5
+
+// MuscleSetRecord 一次训练对某块肌肉的刺激
+type MuscleSetRecord struct {
+	Muscle      string
+	Sets        float64
+	Volume      float64 // 次数×重量，只记在主要发力肌群上
+	CompletedAt time.Time
+}
+
+// ExerciseMuscleRecords 将一次动作记录拆分到动作库中的主要/次要发力肌群
+func ExerciseMuscleRecords(entry models.ExerciseLibrary, sets int, volume float64, at time.Time) []MuscleSetRecord {
+	var records []MuscleSetRecord
+	for _, muscle := range entry.PrimaryMuscleList() {
+		records = append(records, MuscleSetRecord{Muscle: muscle, Sets: float64(sets), Volume: volume, CompletedAt: at})
+	}
+	for _, muscle := range entry.SecondaryMuscleList() {
+		records = append(records, MuscleSetRecord{Muscle: muscle, Sets: float64(sets) * SecondaryMuscleFactor, CompletedAt: at})
+	}
+	return records
+}
+
+// ComputeMuscleRecovery 计算各肌肉的恢复度、最近7天训练量和推荐优先级
+//
+// 疲劳按 组数×exp(-3×距今小时数/恢复小时数) 累加，经过一个恢复周期后约剩5%；
+// 恢复度 = 1 - 疲劳/FatigueCapacity。优先级 = 距 MEV/MRV 中点的训练量缺口 × 恢复度，
+// 达到 MRV 的肌肉优先级为0。
+func ComputeMuscleRecovery(records []MuscleSetRecord, targets map[string]models.VolumeTarget, now time.Time) []models.MuscleRecoveryStatus {
+	windowStart := now.Add(-VolumeWindow)
+	byMuscle := map[string][]MuscleSetRecord{}
+	for _, record := range records {
+		if record.CompletedAt.After(now) {
+			continue
+		}
+		byMuscle[record.Muscle] = append(byMuscle[record.Muscle], record)
+	}
+
+	statuses := make([]models.MuscleRecoveryStatus, 0, len(models.Muscles))
+	for _, muscle := range models.Muscles {
+		target, ok := targets[muscle.Key]
+		if !ok {
+			target = models.DefaultVolumeTargets[muscle.Key]
+		}
+		status := models.MuscleRecoveryStatus{
+			Muscle: muscle.Key,
+			Name:   muscle.Name,
+			Part:   muscle.Part,
+			MEV:    target.MEV,
+			MRV:    target.MRV,
+		}
+
+		recoveryHours := models.MuscleRecoveryHours[muscle.Key]
+		if recoveryHours <= 0 {
+			recoveryHours = 48
+		}
+		fatigue := 0.0
+		for _, record := range byMuscle[muscle.Key] {
+			if record.CompletedAt.After(windowStart) {
+				status.WeeklySets += record.Sets
+				status.WeeklyVolume += record.Volume
+			}
+			hours := now.Sub(record.CompletedAt).Hours()
+			fatigue += record.Sets * math.Exp(-3*hours/recoveryHours)
+			if status.LastTrainedAt == nil || record.CompletedAt.After(*status.LastTrainedAt) {
+				at := record.CompletedAt
+				status.LastTrainedAt = &at
+			}
+		}
+		if status.LastTrainedAt != nil {
+			hours := math.Round(now.Sub(*status.LastTrainedAt).Hours()*10) / 10
+			status.HoursSinceTrained = &hours
+		}
+
+		recovery := math.Max(0, 1-fatigue/FatigueCapacity)
+		status.Recovery = math.Round(recovery*1000) / 10
+		status.Recovered = recovery >= models.RecoveredThreshold
+		status.WeeklySets = math.Round(status.WeeklySets*10) / 10
+		status.WeeklyVolume = math.Round(status.WeeklyVolume*10) / 10
+
+		switch {
+		case status.WeeklySets < float64(target.MEV):
+			status.VolumeStatus = models.VolumeStatusUnder
+		case status.WeeklySets > float64(target.MRV):
+			status.VolumeStatus = models.VolumeStatusOver
+		default:
+			status.VolumeStatus = models.VolumeStatusOptimal
+		}
+
+		midpoint := float64(target.MEV+target.MRV) / 2
+		if midpoint > 0 && status.WeeklySets < float64(target.MRV) {
+			deficit := math.Min(1, math.Max(0, (midpoint-status.WeeklySets)/midpoint))
+			status.Priority = math.Round(deficit*recovery*1000) / 1000
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
+
+// MusclePriorities 按肌肉键索引推荐优先级
+func MusclePriorities(statuses []models.MuscleRecoveryStatus) map[string]float64 {
+	priorities := make(map[string]float64, len(statuses))
+	for _, status := range statuses {
+		priorities[status.Muscle] = status.Priority
+	}
+	return priorities
+}
+
+// RankParts 将训练部位按其中肌肉的最高优先级排序，去掉没有任何已恢复肌肉的部位
+func RankParts(parts []string, statuses []models.MuscleRecoveryStatus) []string {
+	priority := map[string]float64{}
+	recovered := map[string]bool{}
+	for _, status := range statuses {
+		if status.Priority > priority[status.Part] {
+			priority[status.Part] = status.Priority
+		}
+		if status.Recovered {
+			recovered[status.Part] = true
+		}
+	}
+
+	ranked := []string{}
+	for _, part := range parts {
+		if recovered[part] {
+			ranked = append(ranked, part)
+		}
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return priority[ranked[i]] > priority[ranked[j]]
+	})
+	return ranked
+}
+
+// PrioritizeExercises 按主要发力肌群的平均优先级对候选动作稳定排序，优先练缺训练量且已恢复的肌肉
+func PrioritizeExercises(entries []models.ExerciseLibrary, priorities map[string]float64) []models.ExerciseLibrary {
+	score := func(entry models.ExerciseLibrary) float64 {
+		muscles := entry.PrimaryMuscleList()
+		if len(muscles) == 0 {
+			return 0
+		}
+		total := 0.0
+		for _, muscle := range muscles {
+			total += priorities[muscle]
+		}
+		return total / float64(len(muscles))
+	}
+
+	sorted := append([]models.ExerciseLibrary(nil), entries...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return score(sorted[i]) > score(sorted[j])
+	})
+	return sorted
+}
@@ -0,0 +1,118 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"gymates-backend/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestComputeMuscleRecovery 测试恢复度、每周训练量状态和推荐优先级的计算
+func TestComputeMuscleRecovery(t *testing.T) {
+	now := time.Date(2025, 3, 10, 18, 0, 0, 0, time.UTC)
+	chest := func(sets float64, hoursAgo float64) MuscleSetRecord {
+		return MuscleSetRecord{
+			Muscle:      models.MuscleChest,
+			Sets:        sets,
+			CompletedAt: now.Add(-time.Duration(hoursAgo * float64(time.Hour))),
+		}
+	}
+
+	tests := []struct {
+		name          string
+		records       []MuscleSetRecord
+		targets       map[string]models.VolumeTarget
+		wantSets      float64
+		wantStatus    string
+		wantRecovered bool
+		minRecovery   float64
+		maxRecovery   float64
+		minPriority   float64
+		maxPriority   float64
+	}{
+		{
+			name:          "没有训练记录",
+			wantStatus:    models.VolumeStatusUnder,
+			wantRecovered: true,
+			minRecovery:   100, maxRecovery: 100,
+			minPriority: 1, maxPriority: 1,
+		},
+		{
+			name:        "刚练完10组",
+			records:     []MuscleSetRecord{chest(10, 0)},
+			wantSets:    10,
+			wantStatus:  models.VolumeStatusOptimal,
+			minRecovery: 0, maxRecovery: 0,
+			minPriority: 0, maxPriority: 0,
+		},
+		{
+			name:          "经过一个恢复周期",
+			records:       []MuscleSetRecord{chest(10, 72)},
+			wantSets:      10,
+			wantStatus:    models.VolumeStatusOptimal,
+			wantRecovered: true,
+			minRecovery:   94, maxRecovery: 96,
+			minPriority: 0.3, maxPriority: 0.33,
+		},
+		{
+			name:          "超过MRV",
+			records:       []MuscleSetRecord{chest(12, 150), chest(12, 100)},
+			wantSets:      24,
+			wantStatus:    models.VolumeStatusOver,
+			wantRecovered: true,
+			minRecovery:   80, maxRecovery: 100,
+			minPriority: 0, maxPriority: 0,
+		},
+		{
+			name:          "7天前的记录不计入训练量",
+			records:       []MuscleSetRecord{chest(10, 200)},
+			wantStatus:    models.VolumeStatusUnder,
+			wantRecovered: true,
+			minRecovery:   99, maxRecovery: 100,
+			minPriority: 0.99, maxPriority: 1,
+		},
+		{
+			name:          "自定义目标",
+			records:       []MuscleSetRecord{chest(6, 96)},
+			targets:       map[string]models.VolumeTarget{models.MuscleChest: {MEV: 2, MRV: 5}},
+			wantSets:      6,
+			wantStatus:    models.VolumeStatusOver,
+			wantRecovered: true,
+			minRecovery:   90, maxRecovery: 100,
+			minPriority: 0, maxPriority: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			statuses := ComputeMuscleRecovery(tt.records, tt.targets, now)
+			require.Len(t, statuses, len(models.Muscles))
+			var status models.MuscleRecoveryStatus
+			for _, item := range statuses {
+				if item.Muscle == models.MuscleChest {
+					status = item
+				}
+			}
+			assert.Equal(t, tt.wantSets, status.WeeklySets)
+			assert.Equal(t, tt.wantStatus, status.VolumeStatus)
+			assert.Equal(t, tt.wantRecovered, status.Recovered)
+			assert.GreaterOrEqual(t, status.Recovery, tt.minRecovery)
+			assert.LessOrEqual(t, status.Recovery, tt.maxRecovery)
+			assert.GreaterOrEqual(t, status.Priority, tt.minPriority)
+			assert.LessOrEqual(t, status.Priority, tt.maxPriority)
+		})
+	}
+
+	t.Run("次要发力肌群按半组计", func(t *testing.T) {
+		var bench models.ExerciseLibrary
+		bench.PrimaryMuscles = models.EncodeStringList([]string{models.MuscleChest})
+		bench.SecondaryMuscles = models.EncodeStringList([]string{models.MuscleTriceps})
+		records := ExerciseMuscleRecords(bench, 4, 2400, now)
+		require.Len(t, records, 2)
+		assert.Equal(t, MuscleSetRecord{Muscle: models.MuscleChest, Sets: 4, Volume: 2400, CompletedAt: now}, records[0])
+		assert.Equal(t, MuscleSetRecord{Muscle: models.MuscleTriceps, Sets: 2, CompletedAt: now}, records[1])
+	})
+}
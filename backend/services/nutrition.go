@@ -0,0 +1,230 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"gymates-backend/models"
+)
+
+// ErrIncompleteProfile 缺少计算基础代谢所需的身高、体重或年龄
+var ErrIncompleteProfile = errors.New("height, weight and age are required to compute nutrition targets")
+
+// 目标热量调整（kcal/天）
+const (
+	SurplusCalories = 300 // 增肌
+	DeficitCalories = 500 // 减脂，调整后不低于基础代谢
+)
+
+// NutritionProfile 计算营养目标所需的用户资料
+type NutritionProfile struct {
+	Gender         string
+	Age            int
+	Height         float64 // cm
+	Weight         float64 // kg
+	Goal           string
+	WeeklySessions float64 // 最近平均每周完成的训练次数
+	// 最近平均每天的训练消耗（kcal），大于0时代替按训练次数估算的活动系数
+	DailyExerciseCalories float64
+}
+
+// MifflinStJeor 按 Mifflin-St Jeor 公式计算基础代谢（kcal/天）
+//
+// 男性 +5、女性 -161，未填写性别时取两者的中间值 -78。
+func MifflinStJeor(gender string, weight, height float64, age int) float64 {
+	bmr := 10*weight + 6.25*height - 5*float64(age)
+	switch gender {
+	case models.GenderMale:
+		return bmr + 5
+	case models.GenderFemale:
+		return bmr - 161
+	default:
+		return bmr - 78
+	}
+}
+
+// SedentaryFactor 不含训练的日常活动系数
+const SedentaryFactor = 1.2
+
+// ActivityFactor 按每周训练次数估算活动系数
+func ActivityFactor(weeklySessions float64) float64 {
+	switch {
+	case weeklySessions < 1:
+		return SedentaryFactor // 久坐
+	case weeklySessions < 3:
+		return 1.375 // 轻度活动
+	case weeklySessions < 5:
+		return 1.55 // 中度活动
+	case weeklySessions < 7:
+		return 1.725 // 高度活动
+	default:
+		return 1.9
+	}
+}
+
+// NormalizeNutritionGoal 将训练目标归为 增肌/减脂/维持
+func NormalizeNutritionGoal(goal string) string {
+	switch {
+	case strings.Contains(goal, "增肌"), strings.Contains(goal, "增重"):
+		return "增肌"
+	case strings.Contains(goal, "减脂"), strings.Contains(goal, "减重"), strings.Contains(goal, "减肥"):
+		return "减脂"
+	default:
+		return "维持"
+	}
+}
+
+// NutritionTargets 计算每日热量和营养素目标
+//
+// 蛋白质按体重：增肌2.0g/kg、减脂2.2g/kg、维持1.6g/kg；脂肪占热量25%；其余为碳水。
+func NutritionTargets(profile NutritionProfile) (models.NutritionTarget, error) {
+	if profile.Height <= 0 || profile.Weight <= 0 || profile.Age <= 0 {
+		return models.NutritionTarget{}, ErrIncompleteProfile
+	}
+
+	target := models.NutritionTarget{
+		BMR:            math.Round(MifflinStJeor(profile.Gender, profile.Weight, profile.Height, profile.Age)),
+		ActivityFactor: ActivityFactor(profile.WeeklySessions),
+		WeeklySessions: roundTenth(profile.WeeklySessions),
+		Goal:           NormalizeNutritionGoal(profile.Goal),
+	}
+	target.TDEE = math.Round(target.BMR * target.ActivityFactor)
+	if profile.DailyExerciseCalories > 0 {
+		// 有训练消耗记录时：日常消耗 + 实际训练消耗
+		target.ExerciseCalories = math.Round(profile.DailyExerciseCalories)
+		target.TDEE = math.Round(target.BMR*SedentaryFactor + target.ExerciseCalories)
+		target.ActivityFactor = math.Round(target.TDEE/target.BMR*1000) / 1000
+	}
+
+	proteinPerKg := 1.6
+	target.Calories = target.TDEE
+	switch target.Goal {
+	case "增肌":
+		target.Calories += SurplusCalories
+		proteinPerKg = 2.0
+	case "减脂":
+		target.Calories = math.Max(target.Calories-DeficitCalories, target.BMR)
+		proteinPerKg = 2.2
+	}
+
+	target.Protein = roundTenth(profile.Weight * proteinPerKg)
+	target.Fat = roundTenth(target.Calories * 0.25 / 9)
+	target.Carbs = roundTenth(math.Max(target.Calories-target.Protein*4-target.Fat*9, 0) / 4)
+	return target, nil
+}
+
+// ScaleFood 按份数计算一种食物的营养数值
+func ScaleFood(food models.Food, servings float64) models.MealItem {
+	return models.MealItem{
+		FoodID:   food.ID,
+		FoodName: food.Name,
+		Servings: servings,
+		Calories: roundTenth(food.Calories * servings),
+		Protein:  roundTenth(food.Protein * servings),
+		Carbs:    roundTenth(food.Carbs * servings),
+		Fat:      roundTenth(food.Fat * servings),
+	}
+}
+
+// SummarizeDay 汇总一天的摄入，target 为空时不计算剩余量
+func SummarizeDay(date string, meals []models.Meal, target *models.NutritionTarget) models.DailyNutritionSummary {
+	summary := models.DailyNutritionSummary{
+		Date:   date,
+		ByMeal: map[string]models.Macros{},
+		Target: target,
+		Meals:  meals,
+	}
+	if summary.Meals == nil {
+		summary.Meals = []models.Meal{}
+	}
+	for _, meal := range meals {
+		var subtotal models.Macros
+		for _, item := range meal.Items {
+			subtotal.Add(item.Macros())
+		}
+		mealTotal := summary.ByMeal[meal.MealType]
+		mealTotal.Add(subtotal)
+		summary.ByMeal[meal.MealType] = roundMacros(mealTotal)
+		summary.Intake.Add(subtotal)
+	}
+	summary.Intake = roundMacros(summary.Intake)
+
+	if target != nil {
+		remaining := roundMacros(models.Macros{
+			Calories: target.Calories - summary.Intake.Calories,
+			Protein:  target.Protein - summary.Intake.Protein,
+			Carbs:    target.Carbs - summary.Intake.Carbs,
+			Fat:      target.Fat - summary.Intake.Fat,
+		})
+		summary.Remaining = &remaining
+	}
+	return summary
+}
+
+// SummarizeWeek 汇总一周的每日摄入，平均值只计算有记录的天数
+func SummarizeWeek(days []models.DailyNutritionSummary, target *models.NutritionTarget) models.WeeklyNutritionSummary {
+	summary := models.WeeklyNutritionSummary{Target: target, Days: days}
+	if len(days) > 0 {
+		summary.WeekStart = days[0].Date
+		summary.WeekEnd = days[len(days)-1].Date
+	}
+	for _, day := range days {
+		if len(day.Meals) == 0 {
+			continue
+		}
+		summary.LoggedDays++
+		summary.Total.Add(day.Intake)
+		if target != nil && math.Abs(day.Intake.Calories-target.Calories) <= target.Calories*0.1 {
+			summary.DaysOnTrack++
+		}
+	}
+	summary.Total = roundMacros(summary.Total)
+	if summary.LoggedDays > 0 {
+		n := float64(summary.LoggedDays)
+		summary.DailyAvg = roundMacros(models.Macros{
+			Calories: summary.Total.Calories / n,
+			Protein:  summary.Total.Protein / n,
+			Carbs:    summary.Total.Carbs / n,
+			Fat:      summary.Total.Fat / n,
+		})
+	}
+	return summary
+}
+
+// NutritionPromptSection 生成供AI教练系统提示词使用的今日饮食说明，没有记录也没有目标时返回空字符串
+func NutritionPromptSection(summary models.DailyNutritionSummary) string {
+	if len(summary.Meals) == 0 && summary.Target == nil {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString("用户今日饮食（" + summary.Date + "）：")
+	if len(summary.Meals) == 0 {
+		b.WriteString("尚未记录饮食。")
+	} else {
+		fmt.Fprintf(&b, "已摄入热量%.0fkcal，蛋白质%.1fg，碳水%.1fg，脂肪%.1fg。",
+			summary.Intake.Calories, summary.Intake.Protein, summary.Intake.Carbs, summary.Intake.Fat)
+	}
+	if summary.Target != nil && summary.Remaining != nil {
+		fmt.Fprintf(&b, "\n每日目标（%s）：热量%.0fkcal，蛋白质%.1fg，碳水%.1fg，脂肪%.1fg；今日还剩热量%.0fkcal，蛋白质%.1fg。",
+			summary.Target.Goal, summary.Target.Calories, summary.Target.Protein, summary.Target.Carbs, summary.Target.Fat,
+			summary.Remaining.Calories, summary.Remaining.Protein)
+	}
+	b.WriteString("\n给出饮食或训练建议时请参考以上数据。")
+	return b.String()
+}
+
+func roundTenth(value float64) float64 {
+	return math.Round(value*10) / 10
+}
+
+func roundMacros(m models.Macros) models.Macros {
+	return models.Macros{
+		Calories: roundTenth(m.Calories),
+		Protein:  roundTenth(m.Protein),
+		Carbs:    roundTenth(m.Carbs),
+		Fat:      roundTenth(m.Fat),
+	}
+}
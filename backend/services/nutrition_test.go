@@ -0,0 +1,90 @@
+package services
+
+import (
+	"testing"
+
+	"gymates-backend/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestNutritionTargets 测试 Mifflin-St Jeor 基础代谢、活动系数和目标热量的计算
+func TestNutritionTargets(t *testing.T) {
+	tests := []struct {
+		name         string
+		profile      NutritionProfile
+		wantErr      error
+		wantBMR      float64
+		wantFactor   float64
+		wantTDEE     float64
+		wantCalories float64
+		wantProtein  float64
+		wantGoal     string
+	}{
+		{
+			name:    "男性增肌",
+			profile: NutritionProfile{Gender: models.GenderMale, Age: 25, Height: 175.5, Weight: 70, Goal: "增肌"},
+			wantBMR: 1677, wantFactor: 1.2, wantTDEE: 2012, wantCalories: 2312, wantProtein: 140, wantGoal: "增肌",
+		},
+		{
+			name:    "女性减脂每周3练",
+			profile: NutritionProfile{Gender: models.GenderFemale, Age: 28, Height: 165, Weight: 55, Goal: "减脂", WeeklySessions: 3},
+			wantBMR: 1280, wantFactor: 1.55, wantTDEE: 1984, wantCalories: 1484, wantProtein: 121, wantGoal: "减脂",
+		},
+		{
+			name:    "减脂热量不低于基础代谢",
+			profile: NutritionProfile{Gender: models.GenderFemale, Age: 60, Height: 150, Weight: 45, Goal: "减脂"},
+			wantBMR: 927, wantFactor: 1.2, wantTDEE: 1112, wantCalories: 927, wantProtein: 99, wantGoal: "减脂",
+		},
+		{
+			name:    "未填性别按中间值维持",
+			profile: NutritionProfile{Age: 30, Height: 170, Weight: 60, Goal: "塑形", WeeklySessions: 7},
+			wantBMR: 1435, wantFactor: 1.9, wantTDEE: 2727, wantCalories: 2727, wantProtein: 96, wantGoal: "维持",
+		},
+		{
+			name:    "资料不完整",
+			profile: NutritionProfile{Gender: models.GenderMale, Height: 175, Weight: 70},
+			wantErr: ErrIncompleteProfile,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target, err := NutritionTargets(tt.profile)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantBMR, target.BMR)
+			assert.Equal(t, tt.wantFactor, target.ActivityFactor)
+			assert.Equal(t, tt.wantTDEE, target.TDEE)
+			assert.Equal(t, tt.wantCalories, target.Calories)
+			assert.Equal(t, tt.wantProtein, target.Protein)
+			assert.Equal(t, tt.wantGoal, target.Goal)
+			// 三大营养素的热量之和与目标热量一致
+			assert.InDelta(t, target.Calories, target.Protein*4+target.Carbs*4+target.Fat*9, 2)
+		})
+	}
+
+	t.Run("按份数计算并汇总", func(t *testing.T) {
+		egg := models.Food{ID: 1, Name: "鸡蛋", Calories: 72, Protein: 6.3, Carbs: 0.4, Fat: 4.8}
+		rice := models.Food{ID: 2, Name: "米饭", Calories: 116, Protein: 2.6, Carbs: 25.9, Fat: 0.3}
+		meals := []models.Meal{
+			{MealType: models.MealBreakfast, Items: []models.MealItem{ScaleFood(egg, 2)}},
+			{MealType: models.MealLunch, Items: []models.MealItem{ScaleFood(rice, 1.5), ScaleFood(egg, 1)}},
+		}
+		target := &models.NutritionTarget{Macros: models.Macros{Calories: 2000, Protein: 150, Carbs: 200, Fat: 60}}
+		summary := SummarizeDay("2025-03-10", meals, target)
+
+		assert.Equal(t, models.Macros{Calories: 144, Protein: 12.6, Carbs: 0.8, Fat: 9.6}, summary.ByMeal[models.MealBreakfast])
+		assert.Equal(t, models.Macros{Calories: 390, Protein: 22.8, Carbs: 40, Fat: 14.9}, summary.Intake)
+		assert.Equal(t, &models.Macros{Calories: 1610, Protein: 127.2, Carbs: 160, Fat: 45.1}, summary.Remaining)
+
+		prompt := NutritionPromptSection(summary)
+		assert.Contains(t, prompt, "已摄入热量390kcal")
+		assert.Contains(t, prompt, "还剩热量1610kcal")
+		assert.Empty(t, NutritionPromptSection(SummarizeDay("2025-03-10", nil, nil)))
+	})
+}
@@ -0,0 +1,168 @@
+// Package realtime 将聊天事件推送给在线用户的 WebSocket 连接
+package realtime
+
+import (
+	"errors"
+	"sync"
+
+	"gymates-backend/models"
+)
+
+// subscriptionBuffer 每个连接缓冲的事件数，写满说明客户端太慢，直接断开让其重连后用 REST 补齐
+const subscriptionBuffer = 64
+
+// ErrClosed Broker 已关闭
+var ErrClosed = errors.New("realtime broker is closed")
+
+// Subscription 一个连接对某个用户事件的订阅
+type Subscription interface {
+	// Events 推送给该用户的事件，订阅关闭或被丢弃时通道关闭
+	Events() <-chan models.RealtimeEvent
+	Close()
+}
+
+// Broker 按用户投递事件
+//
+// 单实例部署使用进程内的 Hub；多实例部署时可以换成基于 Redis 等 pub/sub 的实现，
+// 在线状态需要在所有实例间共享。
+type Broker interface {
+	Publish(event models.RealtimeEvent, userIDs ...uint) error
+	Subscribe(userID uint) (Subscription, error)
+	// Online 返回 userIDs 中至少有一个连接的用户
+	Online(userIDs ...uint) []uint
+}
+
+// PresenceFunc 用户的第一个连接建立（online=true）或最后一个连接断开时调用
+type PresenceFunc func(userID uint, online bool)
+
+// Hub 进程内的 Broker
+type Hub struct {
+	mu         sync.Mutex
+	subs       map[uint]map[*subscription]struct{}
+	onPresence PresenceFunc
+	closed     bool
+}
+
+// NewHub 创建进程内 Hub，onPresence 可以为空
+func NewHub(onPresence PresenceFunc) *Hub {
+	return &Hub{subs: map[uint]map[*subscription]struct{}{}, onPresence: onPresence}
+}
+
+type subscription struct {
+	hub    *Hub
+	userID uint
+	events chan models.RealtimeEvent
+	once   sync.Once
+}
+
+func (s *subscription) Events() <-chan models.RealtimeEvent {
+	return s.events
+}
+
+func (s *subscription) Close() {
+	s.hub.remove(s)
+}
+
+// Subscribe 订阅用户的事件
+func (h *Hub) Subscribe(userID uint) (Subscription, error) {
+	sub := &subscription{hub: h, userID: userID, events: make(chan models.RealtimeEvent, subscriptionBuffer)}
+
+	h.mu.Lock()
+	if h.closed {
+		h.mu.Unlock()
+		return nil, ErrClosed
+	}
+	first := len(h.subs[userID]) == 0
+	if first {
+		h.subs[userID] = map[*subscription]struct{}{}
+	}
+	h.subs[userID][sub] = struct{}{}
+	h.mu.Unlock()
+
+	if first && h.onPresence != nil {
+		h.onPresence(userID, true)
+	}
+	return sub, nil
+}
+
+// Publish 将事件投递给用户的所有连接，缓冲已满的连接会被断开
+func (h *Hub) Publish(event models.RealtimeEvent, userIDs ...uint) error {
+	var slow []*subscription
+
+	h.mu.Lock()
+	if h.closed {
+		h.mu.Unlock()
+		return ErrClosed
+	}
+	seen := map[uint]bool{}
+	for _, userID := range userIDs {
+		if seen[userID] {
+			continue
+		}
+		seen[userID] = true
+		for sub := range h.subs[userID] {
+			select {
+			case sub.events <- event:
+			default:
+				slow = append(slow, sub)
+			}
+		}
+	}
+	h.mu.Unlock()
+
+	for _, sub := range slow {
+		h.remove(sub)
+	}
+	return nil
+}
+
+// Online 返回有连接的用户
+func (h *Hub) Online(userIDs ...uint) []uint {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	online := []uint{}
+	for _, userID := range userIDs {
+		if len(h.subs[userID]) > 0 {
+			online = append(online, userID)
+		}
+	}
+	return online
+}
+
+// Close 关闭所有订阅，之后不再接受订阅和投递
+func (h *Hub) Close() {
+	h.mu.Lock()
+	h.closed = true
+	subs := h.subs
+	h.subs = map[uint]map[*subscription]struct{}{}
+	h.mu.Unlock()
+
+	for _, userSubs := range subs {
+		for sub := range userSubs {
+			sub.once.Do(func() { close(sub.events) })
+		}
+	}
+}
+
+// remove 移除订阅并关闭通道，用户没有其他连接时通知下线
+func (h *Hub) remove(sub *subscription) {
+	h.mu.Lock()
+	userSubs, ok := h.subs[sub.userID]
+	if _, subscribed := userSubs[sub]; !ok || !subscribed {
+		h.mu.Unlock()
+		sub.once.Do(func() { close(sub.events) })
+		return
+	}
+	delete(userSubs, sub)
+	last := len(userSubs) == 0
+	if last {
+		delete(h.subs, sub.userID)
+	}
+	h.mu.Unlock()
+
+	sub.once.Do(func() { close(sub.events) })
+	if last && h.onPresence != nil {
+		h.onPresence(sub.userID, false)
+	}
+}
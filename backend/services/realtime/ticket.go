@@ -0,0 +1,66 @@
+package realtime
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+	"time"
+)
+
+// TicketTTL 连接票据的有效期，客户端拿到票据后应立即建立连接
+const TicketTTL = 30 * time.Second
+
+// TicketStore 建立 WebSocket 连接用的一次性票据
+//
+// 浏览器无法为 WebSocket 设置请求头，用票据代替放在 URL 里的长期 JWT，
+// 即使出现在访问日志或代理中也很快失效。票据保存在进程内，多实例部署时需要换成共享存储。
+type TicketStore struct {
+	mu      sync.Mutex
+	tickets map[string]ticket
+}
+
+type ticket struct {
+	userID    uint
+	expiresAt time.Time
+}
+
+// NewTicketStore 创建票据存储
+func NewTicketStore() *TicketStore {
+	return &TicketStore{tickets: make(map[string]ticket)}
+}
+
+// Issue 为用户签发票据
+func (s *TicketStore) Issue(userID uint, now time.Time) (string, time.Time, error) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", time.Time{}, err
+	}
+	value := hex.EncodeToString(buf)
+	expiresAt := now.Add(TicketTTL)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// 顺便清理过期的票据
+	for key, t := range s.tickets {
+		if !now.Before(t.expiresAt) {
+			delete(s.tickets, key)
+		}
+	}
+	s.tickets[value] = ticket{userID: userID, expiresAt: expiresAt}
+	return value, expiresAt, nil
+}
+
+// Redeem 使用票据，每张票据只能使用一次，过期或不存在时返回 false
+func (s *TicketStore) Redeem(value string, now time.Time) (uint, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t, ok := s.tickets[value]
+	if !ok {
+		return 0, false
+	}
+	delete(s.tickets, value)
+	if !now.Before(t.expiresAt) {
+		return 0, false
+	}
+	return t.userID, true
+}
@@ -0,0 +1,50 @@
+package realtime
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestTicketStore 测试连接票据只能使用一次且会过期
+func TestTicketStore(t *testing.T) {
+	store := NewTicketStore()
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	first, expiresAt, err := store.Issue(7, now)
+	require.NoError(t, err)
+	assert.Equal(t, now.Add(TicketTTL), expiresAt)
+	second, _, err := store.Issue(7, now)
+	require.NoError(t, err)
+	assert.NotEqual(t, first, second)
+
+	tests := []struct {
+		name   string
+		ticket string
+		at     time.Time
+		userID uint
+		ok     bool
+	}{
+		{"有效期内使用", first, now.Add(10 * time.Second), 7, true},
+		{"不能重复使用", first, now.Add(11 * time.Second), 0, false},
+		{"过期后不能使用", second, now.Add(TicketTTL), 0, false},
+		{"不存在的票据", "unknown", now, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			userID, ok := store.Redeem(tt.ticket, tt.at)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.userID, userID)
+		})
+	}
+
+	t.Run("签发时清理过期票据", func(t *testing.T) {
+		_, _, err := store.Issue(8, now)
+		require.NoError(t, err)
+		_, _, err = store.Issue(9, now.Add(time.Hour))
+		require.NoError(t, err)
+		assert.Len(t, store.tickets, 1)
+	})
+}
@@ -0,0 +1,164 @@
+package recommendation
+
+import (
+	"fmt"
+	"strings"
+
+	"gymates-backend/models"
+	"gymates-backend/services"
+)
+
+// levelNames 动作难度中文名
+var levelNames = map[string]string{
+	"beginner":     "初级",
+	"intermediate": "中级",
+	"advanced":     "高级",
+}
+
+// Request 一次推荐的条件
+type Request struct {
+	Filter        ExerciseFilter
+	Limit         int                    // 最多推荐的动作数，<=0 不限制
+	Level         string                 // 用户训练水平（初级/中级/高级），用于解释
+	Equipment     string                 // 生效中的器械配置名称，用于解释
+	Priorities    map[string]float64     // 肌肉优先级，见 services.MusclePriorities
+	Recent        map[uint]bool          // 最近练过的动作
+	ExcludeRecent bool                   // 是否排除最近练过的动作
+	Injuries      *services.InjuryFilter // 为空时不按伤病过滤
+	Prescribe     Prescriber
+}
+
+// Result 推荐结果
+type Result struct {
+	Exercises    []models.RecommendedExercise
+	Restrictions []models.ExerciseRestriction
+}
+
+// Engine 动作推荐引擎
+//
+// 候选动作按ID稳定排序后用注入的 Rand 打乱，再按肌肉优先级稳定排序、按伤病过滤，
+// 最后生成训练参数；同一种子和数据得到同样的结果。
+type Engine struct {
+	store Store
+	rng   Rand
+}
+
+// NewEngine 创建推荐引擎
+func NewEngine(store Store, rng Rand) *Engine {
+	return &Engine{store: store, rng: rng}
+}
+
+// Recommend 按条件挑选动作，并为每个动作附上入选原因
+func (e *Engine) Recommend(req Request) (Result, error) {
+	result := Result{
+		Exercises:    []models.RecommendedExercise{},
+		Restrictions: []models.ExerciseRestriction{},
+	}
+
+	entries, err := e.store.Exercises(req.Filter)
+	if err != nil {
+		return result, err
+	}
+	if req.ExcludeRecent {
+		fresh := entries[:0]
+		for _, entry := range entries {
+			if !req.Recent[entry.ID] {
+				fresh = append(fresh, entry)
+			}
+		}
+		entries = fresh
+	}
+
+	e.rng.Shuffle(len(entries), func(i, j int) {
+		entries[i], entries[j] = entries[j], entries[i]
+	})
+	entries = services.PrioritizeExercises(entries, req.Priorities)
+
+	injuries := req.Injuries
+	if injuries == nil {
+		injuries = services.NewInjuryFilter(nil)
+	}
+	filtered := injuries.Filter(entries, req.Limit)
+	result.Restrictions = filtered.Restrictions
+
+	for _, entry := range filtered.Exercises {
+		exercise := req.Prescribe(e.rng, entry)
+		exercise.Trace = Explain(entry, req)
+		if restriction, ok := filtered.DownWeighted[entry.ID]; ok {
+			services.DownWeight(&exercise, restriction)
+			exercise.Trace = append(exercise.Trace, models.RecommendationTrace{
+				Factor: models.TraceInjury,
+				Detail: restriction.Reason,
+			})
+		}
+		result.Exercises = append(result.Exercises, exercise)
+	}
+	return result, nil
+}
+
+// Explain 说明动作入选的原因：肌肉训练量缺口、器械、新鲜度和难度
+func Explain(entry models.ExerciseLibrary, req Request) []models.RecommendationTrace {
+	var traces []models.RecommendationTrace
+
+	if trace, ok := muscleDeficitTrace(entry, req.Priorities); ok {
+		traces = append(traces, trace)
+	}
+
+	equipment := models.EquipmentNames[entry.Equipment]
+	if equipment == "" {
+		equipment = entry.Equipment
+	}
+	detail := "使用" + equipment
+	if req.Equipment != "" {
+		detail += "，当前器械配置「" + req.Equipment + "」可完成"
+	}
+	traces = append(traces, models.RecommendationTrace{Factor: models.TraceEquipment, Detail: detail})
+
+	detail = "最近7天未练过该动作"
+	if req.Recent[entry.ID] {
+		detail = "最近7天练过该动作"
+	}
+	traces = append(traces, models.RecommendationTrace{Factor: models.TraceNovelty, Detail: detail})
+
+	level := levelNames[entry.Level]
+	if level == "" {
+		level = entry.Level
+	}
+	detail = "动作难度为" + level
+	if req.Level != "" {
+		detail += "，适合" + req.Level + "训练者"
+	}
+	traces = append(traces, models.RecommendationTrace{Factor: models.TraceLevel, Detail: detail})
+
+	return traces
+}
+
+// muscleDeficitTrace 主要发力肌群中需要优先训练的肌肉，分值与 services.PrioritizeExercises 的排序依据一致
+func muscleDeficitTrace(entry models.ExerciseLibrary, priorities map[string]float64) (models.RecommendationTrace, bool) {
+	muscles := entry.PrimaryMuscleList()
+	if len(muscles) == 0 {
+		return models.RecommendationTrace{}, false
+	}
+
+	total := 0.0
+	var names []string
+	for _, muscle := range muscles {
+		priority := priorities[muscle]
+		total += priority
+		if priority > 0 {
+			name := muscle
+			if info, ok := models.LookupMuscle(muscle); ok {
+				name = info.Name
+			}
+			names = append(names, fmt.Sprintf("%s(%.2f)", name, priority))
+		}
+	}
+	if len(names) == 0 {
+		return models.RecommendationTrace{}, false
+	}
+	return models.RecommendationTrace{
+		Factor: models.TraceMuscleDeficit,
+		Detail: strings.Join(names, "、") + "已恢复且本周训练量不足",
+		Score:  total / float64(len(muscles)),
+	}, true
+}
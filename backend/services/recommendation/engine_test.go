@@ -0,0 +1,216 @@
+package recommendation_test
+
+import (
+	"strings"
+	"testing"
+
+	"gymates-backend/models"
+	"gymates-backend/services"
+	"gymates-backend/services/recommendation"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryStore 内存中的推荐数据，按ID顺序返回
+type memoryStore struct {
+	entries []models.ExerciseLibrary
+	weights map[uint]float64
+}
+
+func (s *memoryStore) Exercises(filter recommendation.ExerciseFilter) ([]models.ExerciseLibrary, error) {
+	var result []models.ExerciseLibrary
+	for _, entry := range s.entries {
+		if len(filter.Parts) > 0 && !containsPart(filter.Parts, entry.Part) {
+			continue
+		}
+		if filter.Equipment != nil && !containsPart(filter.Equipment, entry.Equipment) {
+			continue
+		}
+		result = append(result, entry)
+	}
+	return result, nil
+}
+
+func (s *memoryStore) LastWeight(userID, exerciseID uint) (float64, bool) {
+	weight, ok := s.weights[exerciseID]
+	return weight, ok
+}
+
+func containsPart(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+func libraryEntry(id uint, name, part, equipment, level, pattern string, muscles ...string) models.ExerciseLibrary {
+	return models.ExerciseLibrary{
+		ID: id, Name: name, Part: part, Equipment: equipment, Level: level,
+		MovementPattern: pattern, PrimaryMuscles: models.EncodeStringList(muscles),
+	}
+}
+
+func exerciseNames(exercises []models.RecommendedExercise) []string {
+	names := make([]string, 0, len(exercises))
+	for _, exercise := range exercises {
+		names = append(names, exercise.Name)
+	}
+	return names
+}
+
+func traceFactors(exercise models.RecommendedExercise) []string {
+	factors := make([]string, 0, len(exercise.Trace))
+	for _, trace := range exercise.Trace {
+		factors = append(factors, trace.Factor)
+	}
+	return factors
+}
+
+// TestRecommendationEngine 测试推荐引擎的可复现性、排序依据和入选原因
+func TestRecommendationEngine(t *testing.T) {
+	store := &memoryStore{
+		entries: []models.ExerciseLibrary{
+			libraryEntry(1, "平板卧推", "chest", models.EquipmentBarbell, "intermediate", models.PatternHorizontalPush, models.MuscleChest),
+			libraryEntry(2, "哑铃卧推", "chest", models.EquipmentDumbbell, "beginner", models.PatternHorizontalPush, models.MuscleChest),
+			libraryEntry(3, "俯卧撑", "chest", models.EquipmentBodyweight, "beginner", models.PatternHorizontalPush, models.MuscleChest),
+			libraryEntry(4, "窄距卧推", "chest", models.EquipmentBarbell, "intermediate", models.PatternHorizontalPush, models.MuscleTriceps),
+			libraryEntry(5, "深蹲", "legs", models.EquipmentBarbell, "intermediate", models.PatternSquat, models.MuscleQuads),
+			libraryEntry(6, "箭步蹲", "legs", models.EquipmentDumbbell, "beginner", models.PatternLunge, models.MuscleQuads),
+			libraryEntry(7, "腿弯举", "legs", models.EquipmentMachine, "beginner", models.PatternIsolation, models.MuscleHamstrings),
+			libraryEntry(8, "罗马尼亚硬拉", "legs", models.EquipmentBarbell, "intermediate", models.PatternHinge, models.MuscleHamstrings),
+		},
+		weights: map[uint]float64{1: 100},
+	}
+	prescribe := recommendation.TargetPrescriber("增肌", "中级", recommendation.HistoryWeight(store, 1))
+	recommend := func(seed int64, req recommendation.Request) recommendation.Result {
+		if req.Prescribe == nil {
+			req.Prescribe = prescribe
+		}
+		result, err := recommendation.NewEngine(store, recommendation.NewRand(seed)).Recommend(req)
+		require.NoError(t, err)
+		return result
+	}
+
+	t.Run("相同种子结果相同", func(t *testing.T) {
+		first := recommend(42, recommendation.Request{Limit: 5})
+		second := recommend(42, recommendation.Request{Limit: 5})
+		assert.Equal(t, first, second)
+		require.Len(t, first.Exercises, 5)
+	})
+
+	t.Run("不同种子打乱顺序", func(t *testing.T) {
+		orders := map[string]bool{}
+		for seed := int64(1); seed <= 20; seed++ {
+			orders[strings.Join(exerciseNames(recommend(seed, recommendation.Request{}).Exercises), ",")] = true
+		}
+		assert.Greater(t, len(orders), 1)
+	})
+
+	tests := []struct {
+		name      string
+		req       recommendation.Request
+		wantFirst []string // 结果开头必须是这些动作（顺序不限）
+		wantNames []string // 结果必须恰好是这些动作（顺序不限）
+		absent    []string
+	}{
+		{
+			name:      "优先训练量不足的肌肉",
+			req:       recommendation.Request{Priorities: map[string]float64{models.MuscleHamstrings: 0.8}},
+			wantFirst: []string{"腿弯举", "罗马尼亚硬拉"},
+		},
+		{
+			name:      "按部位筛选",
+			req:       recommendation.Request{Filter: recommendation.ExerciseFilter{Parts: []string{"legs"}}},
+			wantNames: []string{"深蹲", "箭步蹲", "腿弯举", "罗马尼亚硬拉"},
+		},
+		{
+			name:      "按器械筛选",
+			req:       recommendation.Request{Filter: recommendation.ExerciseFilter{Equipment: []string{models.EquipmentDumbbell}}},
+			wantNames: []string{"哑铃卧推", "箭步蹲"},
+		},
+		{
+			name:   "排除最近练过的动作",
+			req:    recommendation.Request{Recent: map[uint]bool{1: true, 5: true}, ExcludeRecent: true},
+			absent: []string{"平板卧推", "深蹲"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for seed := int64(1); seed <= 5; seed++ {
+				names := exerciseNames(recommend(seed, tt.req).Exercises)
+				if tt.wantFirst != nil {
+					require.GreaterOrEqual(t, len(names), len(tt.wantFirst))
+					assert.ElementsMatch(t, tt.wantFirst, names[:len(tt.wantFirst)])
+				}
+				if tt.wantNames != nil {
+					assert.ElementsMatch(t, tt.wantNames, names)
+				}
+				for _, name := range tt.absent {
+					assert.NotContains(t, names, name)
+				}
+			}
+		})
+	}
+
+	t.Run("入选原因", func(t *testing.T) {
+		result := recommend(7, recommendation.Request{
+			Filter:     recommendation.ExerciseFilter{Parts: []string{"chest"}},
+			Level:      "中级",
+			Equipment:  "健身房",
+			Priorities: map[string]float64{models.MuscleChest: 0.5},
+			Recent:     map[uint]bool{1: true},
+		})
+		require.NotEmpty(t, result.Exercises)
+		for _, exercise := range result.Exercises {
+			factors := traceFactors(exercise)
+			assert.Contains(t, factors, models.TraceEquipment, exercise.Name)
+			assert.Contains(t, factors, models.TraceNovelty, exercise.Name)
+			assert.Contains(t, factors, models.TraceLevel, exercise.Name)
+
+			switch exercise.Name {
+			case "平板卧推":
+				assert.Equal(t, []models.RecommendationTrace{
+					{Factor: models.TraceMuscleDeficit, Detail: "胸大肌(0.50)已恢复且本周训练量不足", Score: 0.5},
+					{Factor: models.TraceEquipment, Detail: "使用杠铃，当前器械配置「健身房」可完成"},
+					{Factor: models.TraceNovelty, Detail: "最近7天练过该动作"},
+					{Factor: models.TraceLevel, Detail: "动作难度为中级，适合中级训练者"},
+				}, exercise.Trace)
+				// 有历史重量时在其上下5%浮动
+				assert.Contains(t, []float64{95, 105}, exercise.Weight)
+			case "窄距卧推":
+				assert.NotContains(t, factors, models.TraceMuscleDeficit)
+			}
+		}
+	})
+
+	t.Run("伤病降权写入原因", func(t *testing.T) {
+		result := recommend(3, recommendation.Request{
+			Filter:   recommendation.ExerciseFilter{Parts: []string{"legs"}},
+			Injuries: services.NewInjuryFilter([]models.Injury{{ID: 1, BodyRegion: models.BodyRegionKnee, Severity: models.InjurySeverityMild}}),
+		})
+		names := exerciseNames(result.Exercises)
+		require.Len(t, names, 4)
+		// 降权动作排在未受限动作之后
+		assert.ElementsMatch(t, []string{"深蹲", "箭步蹲"}, names[2:])
+		for _, exercise := range result.Exercises[2:] {
+			assert.Contains(t, traceFactors(exercise), models.TraceInjury)
+		}
+		assert.Len(t, result.Restrictions, 2)
+	})
+
+	t.Run("按目标生成训练参数", func(t *testing.T) {
+		for seed := int64(1); seed <= 10; seed++ {
+			result := recommend(seed, recommendation.Request{Prescribe: recommendation.GoalPrescriber("减脂", 0.9, 70)})
+			for _, exercise := range result.Exercises {
+				assert.Equal(t, 3, exercise.Sets)
+				assert.GreaterOrEqual(t, exercise.Reps, 15)
+				assert.Less(t, exercise.Reps, 25)
+				assert.GreaterOrEqual(t, exercise.RestSeconds, 30)
+				assert.Less(t, exercise.RestSeconds, 45)
+			}
+		}
+	})
+}
@@ -0,0 +1,163 @@
+package recommendation
+
+import (
+	"fmt"
+
+	"gymates-backend/models"
+)
+
+// Prescriber 为入选动作生成组数、次数、重量和休息时间
+type Prescriber func(rng Rand, entry models.ExerciseLibrary) models.RecommendedExercise
+
+// WeightFunc 生成建议重量
+type WeightFunc func(rng Rand, entry models.ExerciseLibrary) float64
+
+// LevelsFor 用户训练水平可选的动作难度，中级及未设置时不限制
+func LevelsFor(level string) []string {
+	switch level {
+	case "初级":
+		return []string{"beginner", "intermediate"}
+	case "高级":
+		return []string{"intermediate", "advanced"}
+	default:
+		return nil
+	}
+}
+
+// TargetPrescriber 按训练目标（增肌/减脂/综合）和训练水平生成训练参数，用于分化训练日推荐
+func TargetPrescriber(target, level string, weight WeightFunc) Prescriber {
+	return func(rng Rand, entry models.ExerciseLibrary) models.RecommendedExercise {
+		return models.RecommendedExercise{
+			ExerciseID:  entry.ID,
+			Name:        entry.Name,
+			Sets:        targetSets(target, level),
+			Reps:        targetReps(rng, target, level),
+			Weight:      weight(rng, entry),
+			RestSeconds: targetRestTime(rng, target, level),
+			Part:        entry.Part,
+			Description: entry.Description,
+		}
+	}
+}
+
+// HistoryWeight 基于用户该动作最近一次的重量上下浮动5%，没有记录时在20-60kg之间取值
+func HistoryWeight(store Store, userID uint) WeightFunc {
+	return func(rng Rand, entry models.ExerciseLibrary) float64 {
+		if last, ok := store.LastWeight(userID, entry.ID); ok {
+			variation := 0.05
+			if rng.Float64() < 0.5 {
+				variation = -variation
+			}
+			return last * (1 + variation)
+		}
+		return 20.0 + rng.Float64()*40.0
+	}
+}
+
+// GoalPrescriber 按训练偏好目标生成训练参数，完成率高时增加强度、低时降低强度
+func GoalPrescriber(goal string, completionRate, bodyWeight float64) Prescriber {
+	intensityMultiplier := 1.0
+	if completionRate > 0.8 {
+		intensityMultiplier = 1.1
+	} else if completionRate < 0.5 {
+		intensityMultiplier = 0.9
+	}
+
+	return func(rng Rand, entry models.ExerciseLibrary) models.RecommendedExercise {
+		exercise := models.RecommendedExercise{
+			ExerciseID:  entry.ID,
+			Name:        entry.Name,
+			Part:        entry.Part,
+			Description: entry.Description,
+			VideoURL:    fmt.Sprintf("https://cdn.gymates.com/videos/%s.mp4", entry.Name),
+		}
+		switch goal {
+		case "增肌":
+			exercise.Sets = int(float64(3+rng.Intn(2)) * intensityMultiplier) // 3-5组
+			exercise.Reps = 8 + rng.Intn(5)                                   // 8-12次
+			exercise.Weight = BodyWeightLoad(entry.Name, bodyWeight)
+			exercise.RestSeconds = 90 + rng.Intn(30) // 90-120秒
+		case "减脂":
+			// 减脂训练：高次数、短休息、较轻重量
+			exercise.Sets = 3
+			exercise.Reps = 15 + rng.Intn(10) // 15-25次
+			exercise.Weight = BodyWeightLoad(entry.Name, bodyWeight) * 0.7
+			exercise.RestSeconds = 30 + rng.Intn(15) // 30-45秒
+			exercise.Notes = "减脂训练：保持高心率"
+		default:
+			exercise.Sets = 3
+			exercise.Reps = 10 + rng.Intn(5) // 10-15次
+			exercise.Weight = BodyWeightLoad(entry.Name, bodyWeight)
+			exercise.RestSeconds = 60 + rng.Intn(30) // 60-90秒
+			exercise.Notes = "维持训练：保持当前水平"
+		}
+		return exercise
+	}
+}
+
+// BodyWeightLoad 根据动作和用户体重计算建议重量
+func BodyWeightLoad(exerciseName string, bodyWeight float64) float64 {
+	baseWeight := bodyWeight * 0.6 // 基础重量为体重的60%
+
+	switch exerciseName {
+	case "Bench Press", "Squat":
+		return baseWeight * 1.2
+	case "Deadlift":
+		return baseWeight * 1.5
+	case "Pull-up", "Push-up":
+		return 0 // 自重训练
+	default:
+		return baseWeight * 0.8
+	}
+}
+
+// targetSets 组数
+func targetSets(target, level string) int {
+	switch target {
+	case "增肌":
+		if level == "初级" {
+			return 3
+		} else if level == "中级" {
+			return 4
+		}
+		return 5
+	case "减脂":
+		return 3
+	default: // 综合
+		return 4
+	}
+}
+
+// targetReps 次数
+func targetReps(rng Rand, target, level string) int {
+	switch target {
+	case "增肌":
+		if level == "初级" {
+			return 10 + rng.Intn(3) // 10-12
+		} else if level == "中级" {
+			return 8 + rng.Intn(5) // 8-12
+		}
+		return 6 + rng.Intn(7) // 6-12
+	case "减脂":
+		return 12 + rng.Intn(6) // 12-17
+	default: // 综合
+		return 10 + rng.Intn(5) // 10-14
+	}
+}
+
+// targetRestTime 休息时间（秒）
+func targetRestTime(rng Rand, target, level string) int {
+	switch target {
+	case "增肌":
+		if level == "初级" {
+			return 60 + rng.Intn(30) // 60-90秒
+		} else if level == "中级" {
+			return 90 + rng.Intn(30) // 90-120秒
+		}
+		return 120 + rng.Intn(60) // 120-180秒
+	case "减脂":
+		return 30 + rng.Intn(30) // 30-60秒
+	default: // 综合
+		return 60 + rng.Intn(60) // 60-120秒
+	}
+}
@@ -0,0 +1,26 @@
+package recommendation
+
+import (
+	"math/rand"
+	"time"
+)
+
+// Rand 推荐使用的随机数源，*rand.Rand 即满足该接口；测试中可注入固定种子或桩实现
+type Rand interface {
+	Intn(n int) int
+	Float64() float64
+	Shuffle(n int, swap func(i, j int))
+}
+
+// NewRand 按种子创建随机数源，相同种子得到相同的推荐结果
+func NewRand(seed int64) *rand.Rand {
+	return rand.New(rand.NewSource(seed))
+}
+
+// maxSeed 种子不超过 2^53，保证前端以 JSON 数字读回时不丢精度
+const maxSeed = 1<<53 - 1
+
+// NewSeed 生成新的随机种子，随推荐结果返回以便复现
+func NewSeed() int64 {
+	return time.Now().UnixNano() & maxSeed
+}
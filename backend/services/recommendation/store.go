@@ -0,0 +1,75 @@
+package recommendation
+
+import (
+	"gymates-backend/models"
+
+	"gorm.io/gorm"
+)
+
+// ExerciseFilter 候选动作的筛选条件，字段为空表示不限制
+type ExerciseFilter struct {
+	Parts     []string // 训练部位：chest/back/legs/shoulders/arms/core
+	Levels    []string // 动作难度：beginner/intermediate/advanced
+	Types     []string // 动作类型：compound/isolation/cardio...
+	Equipment []string // 可用器械；nil 表示不限制，空切片表示没有可用器械
+}
+
+// Store 推荐所需的数据访问
+//
+// 返回顺序必须稳定，随机性只来自 Engine 注入的 Rand，保证同一种子结果可复现。
+type Store interface {
+	Exercises(filter ExerciseFilter) ([]models.ExerciseLibrary, error)
+	LastWeight(userID, exerciseID uint) (float64, bool)
+}
+
+// GormStore 基于 gorm 的 Store 实现，只使用各数据库通用的查询（按ID排序，不依赖 RANDOM()/RAND()）
+type GormStore struct {
+	db *gorm.DB
+}
+
+// NewGormStore 创建 gorm 数据访问
+func NewGormStore(db *gorm.DB) *GormStore {
+	return &GormStore{db: db}
+}
+
+// Exercises 按条件查询动作库，按ID升序返回
+func (s *GormStore) Exercises(filter ExerciseFilter) ([]models.ExerciseLibrary, error) {
+	var entries []models.ExerciseLibrary
+	err := s.exerciseQuery(filter).Find(&entries).Error
+	return entries, err
+}
+
+// exerciseQuery 构造动作库查询
+func (s *GormStore) exerciseQuery(filter ExerciseFilter) *gorm.DB {
+	query := s.db.Model(&models.ExerciseLibrary{})
+	if len(filter.Parts) > 0 {
+		query = query.Where("part IN ?", filter.Parts)
+	}
+	if len(filter.Levels) > 0 {
+		query = query.Where("level IN ?", filter.Levels)
+	}
+	if len(filter.Types) > 0 {
+		query = query.Where("type IN ?", filter.Types)
+	}
+	if filter.Equipment != nil {
+		query = query.Where("equipment IN ?", filter.Equipment)
+	}
+	return query.Order("id")
+}
+
+// ExerciseQuerySQL 返回 Exercises 将执行的SQL，便于检查查询与数据库方言无关
+func (s *GormStore) ExerciseQuerySQL(filter ExerciseFilter) string {
+	stmt := s.exerciseQuery(filter).Session(&gorm.Session{DryRun: true}).Find(&[]models.ExerciseLibrary{}).Statement
+	return stmt.SQL.String()
+}
+
+// LastWeight 用户该动作最近一次训练的重量
+func (s *GormStore) LastWeight(userID, exerciseID uint) (float64, bool) {
+	var history models.UserTrainingHistory
+	if err := s.db.Where("user_id = ? AND exercise_id = ?", userID, exerciseID).
+		Order("completed_at DESC").
+		First(&history).Error; err != nil {
+		return 0, false
+	}
+	return history.Weight, true
+}
@@ -0,0 +1,301 @@
+package search
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+
+	"gymates-backend/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 搜索限制
+const (
+	MaxQueryLength = 100
+	candidateBatch = 200 // 每次从索引取出的候选数量，候选还要按原文过滤
+	backfillBatch  = 500
+)
+
+// backend 不同数据库的全文检索实现，候选按消息ID倒序返回
+type backend interface {
+	name() string
+	setup(db *gorm.DB) error
+	candidates(db *gorm.DB, tokens []string, chatIDs []uint, before uint, limit int) ([]uint, error)
+}
+
+// Index 聊天记录全文索引
+//
+// 索引词保存在 message_search_documents 表，按 DB_TYPE 使用 SQLite FTS5、MySQL FULLTEXT（ngram）
+// 或 PostgreSQL tsvector 检索；数据库不支持时退化为 LIKE 匹配索引词。
+type Index struct {
+	db      *gorm.DB
+	backend backend
+}
+
+// Query 搜索条件
+type Query struct {
+	Text    string
+	UserID  uint   // 排除该用户仅对自己删除的消息
+	ChatIDs []uint // 只搜索这些聊天
+	Before  uint   // 只返回ID小于 Before 的消息，0 表示从最新的开始
+	Limit   int
+}
+
+// New 按数据库类型创建索引，已有消息需要再调用 Backfill 补建索引
+func New(db *gorm.DB, dbType string) *Index {
+	var native backend
+	switch dbType {
+	case "sqlite":
+		native = sqliteFTS{}
+	case "mysql":
+		native = mysqlFulltext{}
+	case "postgres":
+		native = postgresTSVector{}
+	default:
+		native = likeBackend{}
+	}
+
+	index := &Index{db: db, backend: native}
+	if err := native.setup(db); err != nil {
+		log.Printf("⚠️  Full-text search (%s) unavailable, falling back to LIKE: %v", native.name(), err)
+		index.backend = likeBackend{}
+	}
+	return index
+}
+
+// Backend 当前使用的检索实现
+func (idx *Index) Backend() string {
+	return idx.backend.name()
+}
+
+// Put 建立或更新消息的索引，系统消息和撤回的消息不建立索引
+func (idx *Index) Put(message models.Message) error {
+	document := Document(MessageText(message))
+	if document == "" || message.Type == models.MessageTypeSystem || message.RecalledAt != nil {
+		return idx.Delete(message.ID)
+	}
+	return idx.db.Clauses(clause.OnConflict{UpdateAll: true}).Create(&models.MessageSearchDocument{
+		MessageID: message.ID,
+		ChatID:    message.ChatID,
+		Tokens:    document,
+	}).Error
+}
+
+// Delete 删除消息的索引
+func (idx *Index) Delete(messageID uint) error {
+	return idx.db.Where("message_id = ?", messageID).Delete(&models.MessageSearchDocument{}).Error
+}
+
+// Search 按消息ID倒序返回包含每个搜索词的消息，hasMore 表示是否还有更早的结果
+func (idx *Index) Search(q Query) ([]models.Message, bool, error) {
+	tokens := QueryTokens(q.Text)
+	if len(tokens) == 0 || len(q.ChatIDs) == 0 || q.Limit <= 0 {
+		return []models.Message{}, false, nil
+	}
+
+	results := []models.Message{}
+	before := q.Before
+	for len(results) <= q.Limit {
+		ids, err := idx.backend.candidates(idx.db, tokens, q.ChatIDs, before, candidateBatch)
+		if err != nil {
+			return nil, false, err
+		}
+		if len(ids) == 0 {
+			break
+		}
+
+		var messages []models.Message
+		query := idx.db.Preload("Sender").Where("id IN ?", ids).Order("id DESC")
+		if q.UserID != 0 {
+			query = query.Where("id NOT IN (?)", idx.db.Model(&models.MessageDeletion{}).Select("message_id").Where("user_id = ?", q.UserID))
+		}
+		if err := query.Find(&messages).Error; err != nil {
+			return nil, false, err
+		}
+		for _, message := range messages {
+			if message.RecalledAt == nil && Matches(MessageText(message), q.Text) {
+				results = append(results, message)
+			}
+		}
+
+		before = ids[len(ids)-1]
+		if len(ids) < candidateBatch {
+			break
+		}
+	}
+
+	hasMore := len(results) > q.Limit
+	if hasMore {
+		results = results[:q.Limit]
+	}
+	return results, hasMore, nil
+}
+
+// Backfill 为没有索引的消息建立索引，可以与 Put、Delete 同时进行，中途失败后可以重新执行
+func (idx *Index) Backfill() error {
+	var lastID uint
+	for {
+		var messages []models.Message
+		err := idx.db.Where("id > ? AND type <> ? AND recalled_at IS NULL", lastID, models.MessageTypeSystem).
+			Where("id NOT IN (?)", idx.db.Model(&models.MessageSearchDocument{}).Select("message_id")).
+			Order("id").Limit(backfillBatch).Find(&messages).Error
+		if err != nil {
+			return err
+		}
+		for _, message := range messages {
+			if err := idx.Put(message); err != nil {
+				return err
+			}
+		}
+		if len(messages) < backfillBatch {
+			return nil
+		}
+		lastID = messages[len(messages)-1].ID
+	}
+}
+
+// MessageText 消息中可搜索的文本：内容以及分享卡片的标题、描述和地点
+func MessageText(message models.Message) string {
+	parts := []string{message.Content}
+	if message.Payload != "" {
+		switch message.Type {
+		case models.MessageTypeWorkout:
+			var card models.WorkoutShareCard
+			if json.Unmarshal([]byte(message.Payload), &card) == nil {
+				parts = append(parts, card.PlanName)
+			}
+		case models.MessageTypePlan:
+			var card models.PlanShareCard
+			if json.Unmarshal([]byte(message.Payload), &card) == nil {
+				parts = append(parts, card.Name, card.Description)
+				parts = append(parts, card.MuscleGroups...)
+			}
+		case models.MessageTypeLocation:
+			var location models.LocationPayload
+			if json.Unmarshal([]byte(message.Payload), &location) == nil {
+				parts = append(parts, location.Name, location.Address)
+			}
+		}
+	}
+	return strings.TrimSpace(strings.Join(parts, "\n"))
+}
+
+// likeBackend 不依赖数据库扩展，逐个索引词 LIKE 匹配
+type likeBackend struct{}
+
+func (likeBackend) name() string { return "like" }
+
+func (likeBackend) setup(db *gorm.DB) error { return nil }
+
+func (likeBackend) candidates(db *gorm.DB, tokens []string, chatIDs []uint, before uint, limit int) ([]uint, error) {
+	query := documentQuery(db, "message_search_documents", chatIDs, before)
+	for _, token := range tokens {
+		query = query.Where("message_search_documents.tokens LIKE ?", "% "+token+" %")
+	}
+	var ids []uint
+	err := query.Limit(limit).Pluck("message_search_documents.message_id", &ids).Error
+	return ids, err
+}
+
+// sqliteFTS SQLite FTS5 外部内容表，通过触发器与索引表同步（需要使用 sqlite_fts5 构建标签）
+type sqliteFTS struct{}
+
+func (sqliteFTS) name() string { return "sqlite-fts5" }
+
+func (sqliteFTS) setup(db *gorm.DB) error {
+	var exists int64
+	db.Raw("SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'message_search_fts'").Scan(&exists)
+	statements := []string{
+		`CREATE VIRTUAL TABLE IF NOT EXISTS message_search_fts USING fts5(tokens, content='message_search_documents', content_rowid='message_id')`,
+		`CREATE TRIGGER IF NOT EXISTS message_search_ai AFTER INSERT ON message_search_documents BEGIN
+			INSERT INTO message_search_fts(rowid, tokens) VALUES (new.message_id, new.tokens);
+		END`,
+		`CREATE TRIGGER IF NOT EXISTS message_search_ad AFTER DELETE ON message_search_documents BEGIN
+			INSERT INTO message_search_fts(message_search_fts, rowid, tokens) VALUES ('delete', old.message_id, old.tokens);
+		END`,
+		`CREATE TRIGGER IF NOT EXISTS message_search_au AFTER UPDATE ON message_search_documents BEGIN
+			INSERT INTO message_search_fts(message_search_fts, rowid, tokens) VALUES ('delete', old.message_id, old.tokens);
+			INSERT INTO message_search_fts(rowid, tokens) VALUES (new.message_id, new.tokens);
+		END`,
+	}
+	if exists == 0 {
+		// 新建的全文表需要从已有的索引表重建
+		statements = append(statements, `INSERT INTO message_search_fts(message_search_fts) VALUES ('rebuild')`)
+	}
+	for _, statement := range statements {
+		if err := db.Exec(statement).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (sqliteFTS) candidates(db *gorm.DB, tokens []string, chatIDs []uint, before uint, limit int) ([]uint, error) {
+	var ids []uint
+	err := documentQuery(db, "message_search_documents", chatIDs, before).
+		Joins("JOIN message_search_fts ON message_search_fts.rowid = message_search_documents.message_id").
+		Where("message_search_fts MATCH ?", quotedTerms(tokens, "")).
+		Limit(limit).Pluck("message_search_documents.message_id", &ids).Error
+	return ids, err
+}
+
+// mysqlFulltext MySQL FULLTEXT 索引，使用内置的 ngram 分词器
+type mysqlFulltext struct{}
+
+func (mysqlFulltext) name() string { return "mysql-fulltext" }
+
+func (mysqlFulltext) setup(db *gorm.DB) error {
+	var exists int64
+	db.Raw(`SELECT COUNT(*) FROM information_schema.statistics
+		WHERE table_schema = DATABASE() AND table_name = 'message_search_documents' AND index_name = 'idx_message_search_tokens'`).Scan(&exists)
+	if exists > 0 {
+		return nil
+	}
+	return db.Exec("ALTER TABLE message_search_documents ADD FULLTEXT INDEX idx_message_search_tokens (tokens) WITH PARSER ngram").Error
+}
+
+func (mysqlFulltext) candidates(db *gorm.DB, tokens []string, chatIDs []uint, before uint, limit int) ([]uint, error) {
+	var ids []uint
+	err := documentQuery(db, "message_search_documents", chatIDs, before).
+		Where("MATCH(message_search_documents.tokens) AGAINST (? IN BOOLEAN MODE)", quotedTerms(tokens, "+")).
+		Limit(limit).Pluck("message_search_documents.message_id", &ids).Error
+	return ids, err
+}
+
+// postgresTSVector PostgreSQL tsvector 表达式索引，使用 simple 配置（不做词干处理）
+type postgresTSVector struct{}
+
+func (postgresTSVector) name() string { return "postgres-tsvector" }
+
+func (postgresTSVector) setup(db *gorm.DB) error {
+	return db.Exec("CREATE INDEX IF NOT EXISTS idx_message_search_tokens ON message_search_documents USING GIN (to_tsvector('simple', tokens))").Error
+}
+
+func (postgresTSVector) candidates(db *gorm.DB, tokens []string, chatIDs []uint, before uint, limit int) ([]uint, error) {
+	var ids []uint
+	err := documentQuery(db, "message_search_documents", chatIDs, before).
+		Where("to_tsvector('simple', message_search_documents.tokens) @@ plainto_tsquery('simple', ?)", strings.Join(tokens, " ")).
+		Limit(limit).Pluck("message_search_documents.message_id", &ids).Error
+	return ids, err
+}
+
+// documentQuery 索引表的公共条件：聊天范围、游标和倒序
+func documentQuery(db *gorm.DB, table string, chatIDs []uint, before uint) *gorm.DB {
+	query := db.Table(table).Where(table+".chat_id IN ?", chatIDs).Order(table + ".message_id DESC")
+	if before > 0 {
+		query = query.Where(table+".message_id < ?", before)
+	}
+	return query
+}
+
+// quotedTerms 将索引词转为带引号的短语，prefix 为每个短语前的运算符
+func quotedTerms(tokens []string, prefix string) string {
+	quoted := make([]string, len(tokens))
+	for i, token := range tokens {
+		quoted[i] = fmt.Sprintf(`%s"%s"`, prefix, strings.ReplaceAll(token, `"`, `""`))
+	}
+	return strings.Join(quoted, " ")
+}
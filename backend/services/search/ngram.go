@@ -0,0 +1,117 @@
+package search
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Normalize 转为小写并将全角字母数字转为半角，逐字符转换，不改变字符数
+func Normalize(text string) string {
+	runes := []rune(text)
+	for i, r := range runes {
+		runes[i] = normalizeRune(r)
+	}
+	return string(runes)
+}
+
+func normalizeRune(r rune) rune {
+	switch {
+	case r == '　':
+		return ' '
+	case r >= '！' && r <= '～':
+		r -= 0xFEE0
+	}
+	return unicode.ToLower(r)
+}
+
+// isCJK 中日韩文字按字切分，其他文字按词切分
+func isCJK(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul)
+}
+
+// Terms 将查询按空白切分为搜索词，每个词都必须出现在结果中
+func Terms(query string) []string {
+	return strings.Fields(Normalize(query))
+}
+
+// Tokens 文档的索引词：中日韩文字输出单字和相邻两字（bigram），其他文字输出整个单词
+//
+// 单字用于搜索只有一个汉字的查询，两字组合用于更长的查询，结果去重并保持出现顺序。
+func Tokens(text string) []string {
+	return tokenize(text, true)
+}
+
+// QueryTokens 查询的索引词：连续两个以上的汉字只用 bigram，单个汉字用单字
+func QueryTokens(query string) []string {
+	return tokenize(query, false)
+}
+
+func tokenize(text string, unigrams bool) []string {
+	var tokens []string
+	seen := map[string]bool{}
+	add := func(token string) {
+		if token != "" && !seen[token] {
+			seen[token] = true
+			tokens = append(tokens, token)
+		}
+	}
+
+	var word []rune
+	var cjk []rune
+	flushWord := func() {
+		add(string(word))
+		word = word[:0]
+	}
+	flushCJK := func() {
+		for i := range cjk {
+			if unigrams || len(cjk) == 1 {
+				add(string(cjk[i]))
+			}
+			if i+1 < len(cjk) {
+				add(string(cjk[i : i+2]))
+			}
+		}
+		cjk = cjk[:0]
+	}
+
+	for _, r := range Normalize(text) {
+		switch {
+		case isCJK(r):
+			flushWord()
+			cjk = append(cjk, r)
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			flushCJK()
+			word = append(word, r)
+		default:
+			flushWord()
+			flushCJK()
+		}
+	}
+	flushWord()
+	flushCJK()
+	return tokens
+}
+
+// Document 保存到索引表的内容：空格分隔的索引词，首尾补空格便于 LIKE 精确匹配单个词
+func Document(text string) string {
+	tokens := Tokens(text)
+	if len(tokens) == 0 {
+		return ""
+	}
+	return " " + strings.Join(tokens, " ") + " "
+}
+
+// Matches 文本是否包含查询的每个搜索词，用于过滤 bigram 组合带来的误匹配
+func Matches(text, query string) bool {
+	terms := Terms(query)
+	if len(terms) == 0 {
+		return false
+	}
+	normalized := Normalize(text)
+	for _, term := range terms {
+		if !strings.Contains(normalized, term) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,79 @@
+package search
+
+import (
+	"sort"
+	"strings"
+
+	"gymates-backend/models"
+)
+
+// Snippet 截取第一个命中位置附近的文本，返回片段和片段内的高亮区间（按字符计）
+//
+// 前后各保留 radius 个字符，被截断的一侧用"…"表示。
+func Snippet(text, query string, radius int) (string, []models.HighlightRange) {
+	runes := []rune(text)
+	normalized := []rune(Normalize(text))
+	terms := Terms(query)
+
+	var matches []models.HighlightRange
+	for _, term := range terms {
+		termRunes := []rune(term)
+		for i := 0; i+len(termRunes) <= len(normalized); i++ {
+			if string(normalized[i:i+len(termRunes)]) == term {
+				matches = append(matches, models.HighlightRange{Start: i, End: i + len(termRunes)})
+			}
+		}
+	}
+	if len(matches) == 0 {
+		if len(runes) > radius*2 {
+			return string(runes[:radius*2]) + "…", []models.HighlightRange{}
+		}
+		return text, []models.HighlightRange{}
+	}
+	matches = mergeRanges(matches)
+
+	start := matches[0].Start - radius
+	if start < 0 {
+		start = 0
+	}
+	end := matches[0].End + radius
+	if end > len(runes) {
+		end = len(runes)
+	}
+
+	var snippet strings.Builder
+	offset := -start
+	if start > 0 {
+		snippet.WriteString("…")
+		offset++
+	}
+	snippet.WriteString(string(runes[start:end]))
+	if end < len(runes) {
+		snippet.WriteString("…")
+	}
+
+	highlights := []models.HighlightRange{}
+	for _, match := range matches {
+		if match.Start >= start && match.End <= end {
+			highlights = append(highlights, models.HighlightRange{Start: match.Start + offset, End: match.End + offset})
+		}
+	}
+	return snippet.String(), highlights
+}
+
+// mergeRanges 合并重叠或相邻的区间
+func mergeRanges(ranges []models.HighlightRange) []models.HighlightRange {
+	sort.Slice(ranges, func(i, j int) bool { return ranges[i].Start < ranges[j].Start })
+	merged := []models.HighlightRange{ranges[0]}
+	for _, r := range ranges[1:] {
+		last := &merged[len(merged)-1]
+		if r.Start <= last.End {
+			if r.End > last.End {
+				last.End = r.End
+			}
+			continue
+		}
+		merged = append(merged, r)
+	}
+	return merged
+}
@@ -0,0 +1,53 @@
+package services
+
+import (
+	"time"
+
+	"gymates-backend/models"
+)
+
+// BuildSplitSchedule 将训练课按顺序轮流分配到每周的训练日，其余日期为休息日
+//
+// 训练日由 models.WeeklyTrainingDays 决定，trainDays 超出 1-7 时取边界值。
+func BuildSplitSchedule(sessions []models.SplitSession, trainDays int) []models.SplitScheduleDay {
+	if trainDays < 1 {
+		trainDays = 1
+	}
+	if trainDays > 7 {
+		trainDays = 7
+	}
+
+	sessionByDay := map[int]models.SplitSession{}
+	if len(sessions) > 0 {
+		for i, day := range models.WeeklyTrainingDays[trainDays] {
+			sessionByDay[day] = sessions[i%len(sessions)]
+		}
+	}
+
+	schedule := make([]models.SplitScheduleDay, 0, 7)
+	for day := 1; day <= 7; day++ {
+		item := models.SplitScheduleDay{
+			DayOfWeek: day,
+			DayName:   time.Weekday(day % 7).String(),
+			Parts:     []string{},
+		}
+		if session, ok := sessionByDay[day]; ok {
+			item.Session = session.Name
+			item.Parts = append(item.Parts, session.Parts...)
+		} else {
+			item.RestDay = true
+		}
+		schedule = append(schedule, item)
+	}
+	return schedule
+}
+
+// ScheduleDay 按英文星期名（Monday...Sunday）查找当天安排
+func ScheduleDay(schedule []models.SplitScheduleDay, dayName string) (models.SplitScheduleDay, bool) {
+	for _, day := range schedule {
+		if day.DayName == dayName {
+			return day, true
+		}
+	}
+	return models.SplitScheduleDay{}, false
+}
@@ -0,0 +1,126 @@
+package services
+
+import (
+	"strconv"
+	"testing"
+
+	"gymates-backend/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSplitSchedule 测试每种内置分化在允许的每周训练天数下的安排
+func TestSplitSchedule(t *testing.T) {
+	for _, split := range models.SplitDefinitions {
+		if split.Key == models.SplitCustom {
+			continue
+		}
+		for days := split.MinDays; days <= split.MaxDays; days++ {
+			t.Run(split.Name+"_"+strconv.Itoa(days)+"天", func(t *testing.T) {
+				schedule := BuildSplitSchedule(split.Sessions, days)
+				require.Len(t, schedule, 7)
+
+				trainingDays := 0
+				for i, day := range schedule {
+					assert.Equal(t, i+1, day.DayOfWeek)
+					if day.RestDay {
+						assert.Empty(t, day.Parts)
+						assert.Empty(t, day.Session)
+						continue
+					}
+					// 训练课按顺序轮流安排
+					session := split.Sessions[trainingDays%len(split.Sessions)]
+					assert.Equal(t, session.Name, day.Session)
+					assert.Equal(t, session.Parts, day.Parts)
+					for _, part := range day.Parts {
+						assert.True(t, models.IsTrainingPart(part), part)
+					}
+					trainingDays++
+				}
+				assert.Equal(t, days, trainingDays)
+
+				// 训练天数不少于训练课数量时，每个训练课每周至少安排一次
+				if days >= len(split.Sessions) {
+					seen := map[string]bool{}
+					for _, day := range schedule {
+						seen[day.Session] = true
+					}
+					for _, session := range split.Sessions {
+						assert.True(t, seen[session.Name], session.Name)
+					}
+				}
+			})
+		}
+	}
+
+	tests := []struct {
+		name     string
+		mode     string
+		wantKey  string
+		wantOK   bool
+		days     int
+		monday   string
+		wantRest []string
+	}{
+		{name: "推拉腿每周3练", mode: models.SplitPPL, wantKey: models.SplitPPL, wantOK: true, days: 3, monday: "推", wantRest: []string{"Tuesday", "Thursday", "Saturday", "Sunday"}},
+		{name: "历史模式名三分化", mode: "三分化", wantKey: models.SplitPPL, wantOK: true, days: 6, monday: "推", wantRest: []string{"Sunday"}},
+		{name: "历史模式名五分化", mode: "五分化", wantKey: models.SplitBro5, wantOK: true, days: 5, monday: "胸", wantRest: []string{"Saturday", "Sunday"}},
+		{name: "上下肢每周2练", mode: models.SplitUpperLower, wantKey: models.SplitUpperLower, wantOK: true, days: 2, monday: "上肢", wantRest: []string{"Tuesday", "Wednesday", "Friday", "Saturday", "Sunday"}},
+		{name: "未知模式", mode: "Wednesday", wantOK: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			split, ok := models.LookupSplit(tt.mode)
+			require.Equal(t, tt.wantOK, ok)
+			if !ok {
+				return
+			}
+			assert.Equal(t, tt.wantKey, split.Key)
+			schedule := BuildSplitSchedule(split.Sessions, tt.days)
+			monday, ok := ScheduleDay(schedule, "Monday")
+			require.True(t, ok)
+			assert.Equal(t, tt.monday, monday.Session)
+			var rest []string
+			for _, day := range schedule {
+				if day.RestDay {
+					rest = append(rest, day.DayName)
+				}
+			}
+			assert.Equal(t, tt.wantRest, rest)
+		})
+	}
+
+	t.Run("天数越界取边界值", func(t *testing.T) {
+		split, _ := models.LookupSplit(models.SplitFullBody)
+		restCount := func(schedule []models.SplitScheduleDay) int {
+			count := 0
+			for _, day := range schedule {
+				if day.RestDay {
+					count++
+				}
+			}
+			return count
+		}
+		assert.Equal(t, 6, restCount(BuildSplitSchedule(split.Sessions, 0)))
+		assert.Equal(t, 0, restCount(BuildSplitSchedule(split.Sessions, 9)))
+	})
+
+	t.Run("分化名称互不相同，同名的历史模式名指向该分化", func(t *testing.T) {
+		names := map[string]string{}
+		for _, split := range models.SplitDefinitions {
+			assert.NotContains(t, names, split.Name)
+			names[split.Name] = split.Key
+		}
+		for alias, key := range models.SplitModeAliases {
+			if named, ok := names[alias]; ok {
+				assert.Equal(t, named, key, alias)
+			}
+		}
+	})
+
+	t.Run("无效的星期名", func(t *testing.T) {
+		_, ok := ScheduleDay(BuildSplitSchedule(nil, 3), "Funday")
+		assert.False(t, ok)
+	})
+}
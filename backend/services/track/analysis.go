@@ -0,0 +1,226 @@
+package track
+
+import (
+	"math"
+	"time"
+
+	"gymates-backend/models"
+)
+
+const (
+	earthRadiusMeters = 6371000
+	// maxSampleGap 两个点间隔超过该时长视为暂停，不计入心率区间
+	maxSampleGap = 30 * time.Second
+)
+
+// heartRateZoneBounds 五个心率区间的下限（占最大心率的比例）
+var heartRateZoneBounds = []struct {
+	name  string
+	ratio float64
+}{
+	{"热身", 0.5},
+	{"燃脂", 0.6},
+	{"有氧", 0.7},
+	{"乳酸阈", 0.8},
+	{"无氧", 0.9},
+}
+
+// Haversine 两个经纬度之间的大圆距离（米）
+func Haversine(lat1, lon1, lat2, lon2 float64) float64 {
+	toRad := math.Pi / 180
+	dLat := (lat2 - lat1) * toRad
+	dLon := (lon2 - lon1) * toRad
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1*toRad)*math.Cos(lat2*toRad)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusMeters * math.Asin(math.Sqrt(a))
+}
+
+// Distance 总距离（米）
+func (t Track) Distance() float64 {
+	if len(t.Points) == 0 {
+		return 0
+	}
+	return t.Points[len(t.Points)-1].Distance
+}
+
+// Duration 从第一个点到最后一个点的用时
+func (t Track) Duration() time.Duration {
+	if len(t.Points) == 0 {
+		return 0
+	}
+	return t.Points[len(t.Points)-1].Time.Sub(t.Points[0].Time)
+}
+
+// StartTime 第一个点的时间
+func (t Track) StartTime() time.Time {
+	if len(t.Points) == 0 {
+		return time.Time{}
+	}
+	return t.Points[0].Time
+}
+
+// HeartRate 心率样本的平均值和最大值，没有心率时都为0
+func (t Track) HeartRate() (avg, peak int) {
+	sum, count := 0, 0
+	for _, p := range t.Points {
+		if p.HeartRate <= 0 {
+			continue
+		}
+		sum += p.HeartRate
+		count++
+		if p.HeartRate > peak {
+			peak = p.HeartRate
+		}
+	}
+	if count == 0 {
+		return 0, 0
+	}
+	return int(math.Round(float64(sum) / float64(count))), peak
+}
+
+// ElevationGain 累计爬升（米）
+func (t Track) ElevationGain() float64 {
+	return elevationGain(t.Points)
+}
+
+func elevationGain(points []Point) float64 {
+	var gain float64
+	for i := 1; i < len(points); i++ {
+		if delta := points[i].Elevation - points[i-1].Elevation; delta > 0 {
+			gain += delta
+		}
+	}
+	return math.Round(gain*10) / 10
+}
+
+// Splits 按 splitMeters 分段，分段边界的时间按相邻两点线性插值，最后不足一段的部分单独成段
+//
+// 分段数超过 MaxSplits 时返回 ErrTooManySplits。
+func (t Track) Splits(splitMeters float64) ([]models.ActivitySplit, error) {
+	if len(t.Points) < 2 || splitMeters <= 0 {
+		return nil, nil
+	}
+	if t.Distance()/splitMeters > MaxSplits {
+		return nil, ErrTooManySplits
+	}
+
+	var splits []models.ActivitySplit
+	segmentStart := t.Points[0].Time
+	segmentDistance := 0.0
+	segmentFirst := 0
+	boundary := splitMeters
+	for i := 1; i < len(t.Points); i++ {
+		prev, cur := t.Points[i-1], t.Points[i]
+		for cur.Distance >= boundary && cur.Distance > prev.Distance {
+			crossing := interpolateTime(prev, cur, boundary)
+			splits = append(splits, newSplit(len(splits)+1, boundary-segmentDistance, crossing.Sub(segmentStart), t.Points[segmentFirst:i+1]))
+			segmentStart, segmentDistance, segmentFirst = crossing, boundary, i
+			boundary += splitMeters
+		}
+	}
+
+	last := t.Points[len(t.Points)-1]
+	if remaining := last.Distance - segmentDistance; remaining >= 10 {
+		splits = append(splits, newSplit(len(splits)+1, remaining, last.Time.Sub(segmentStart), t.Points[segmentFirst:]))
+	}
+	return splits, nil
+}
+
+func newSplit(index int, distance float64, duration time.Duration, points []Point) models.ActivitySplit {
+	split := models.ActivitySplit{
+		Index:           index,
+		Distance:        math.Round(distance*10) / 10,
+		DurationSeconds: math.Round(duration.Seconds()*10) / 10,
+		ElevationGain:   elevationGain(points),
+	}
+	if distance > 0 {
+		split.Pace = math.Round(duration.Seconds() / (distance / 1000))
+	}
+	sum, count := 0, 0
+	for _, p := range points {
+		if p.HeartRate > 0 {
+			sum += p.HeartRate
+			count++
+		}
+	}
+	if count > 0 {
+		split.AvgHeartRate = int(math.Round(float64(sum) / float64(count)))
+	}
+	return split
+}
+
+// BestEffort 轨迹中最快完成 meters 距离的用时（秒），轨迹距离不足时返回 false
+//
+// 对每个起点找到第一个覆盖该距离的终点，终点时间按线性插值计算。
+func (t Track) BestEffort(meters float64) (float64, bool) {
+	if meters <= 0 || t.Distance() < meters {
+		return 0, false
+	}
+
+	best := math.Inf(1)
+	j := 0
+	for i := range t.Points {
+		target := t.Points[i].Distance + meters
+		for j < len(t.Points) && t.Points[j].Distance < target {
+			j++
+		}
+		if j == len(t.Points) {
+			break
+		}
+		end := t.Points[j].Time
+		if j > 0 && t.Points[j].Distance > t.Points[j-1].Distance {
+			end = interpolateTime(t.Points[j-1], t.Points[j], target)
+		}
+		if elapsed := end.Sub(t.Points[i].Time).Seconds(); elapsed < best {
+			best = elapsed
+		}
+	}
+	return math.Round(best), !math.IsInf(best, 1)
+}
+
+// HeartRateZones 按最大心率的50/60/70/80/90%划分五个区间，统计每个区间的用时
+//
+// 两点之间的时长计入前一个点的心率所在区间，超过30秒的间隔视为暂停。
+func (t Track) HeartRateZones(maxHeartRate int) []models.HeartRateZone {
+	if maxHeartRate <= 0 {
+		return nil
+	}
+	zones := make([]models.HeartRateZone, len(heartRateZoneBounds))
+	for i, bound := range heartRateZoneBounds {
+		zones[i] = models.HeartRateZone{
+			Zone:   i + 1,
+			Name:   bound.name,
+			MinBPM: int(math.Round(bound.ratio * float64(maxHeartRate))),
+			MaxBPM: maxHeartRate,
+		}
+		if i > 0 {
+			zones[i-1].MaxBPM = zones[i].MinBPM - 1
+		}
+	}
+
+	hasHeartRate := false
+	for i := 1; i < len(t.Points); i++ {
+		prev := t.Points[i-1]
+		gap := t.Points[i].Time.Sub(prev.Time)
+		if prev.HeartRate <= 0 || gap <= 0 || gap > maxSampleGap {
+			continue
+		}
+		for z := len(zones) - 1; z >= 0; z-- {
+			if prev.HeartRate >= zones[z].MinBPM {
+				zones[z].Seconds += gap.Seconds()
+				hasHeartRate = true
+				break
+			}
+		}
+	}
+	if !hasHeartRate {
+		return nil
+	}
+	return zones
+}
+
+// interpolateTime 按距离线性插值到达 distance 的时间
+func interpolateTime(prev, cur Point, distance float64) time.Time {
+	ratio := (distance - prev.Distance) / (cur.Distance - prev.Distance)
+	return prev.Time.Add(time.Duration(ratio * float64(cur.Time.Sub(prev.Time))))
+}
@@ -0,0 +1,210 @@
+package track
+
+import (
+	"encoding/binary"
+	"errors"
+	"time"
+)
+
+// FIT 协议中用到的消息和字段（参考 Garmin FIT SDK Profile）
+const (
+	fitMesgSession = 18
+	fitMesgRecord  = 20
+
+	fitFieldTimestamp = 253
+
+	fitRecordLat              = 0
+	fitRecordLong             = 1
+	fitRecordAltitude         = 2
+	fitRecordHeartRate        = 3
+	fitRecordDistance         = 5
+	fitRecordEnhancedAltitude = 78
+
+	fitSessionSport         = 5
+	fitSessionTotalCalories = 11
+)
+
+// fitEpoch FIT 时间戳的起点 1989-12-31 00:00:00 UTC
+var fitEpoch = time.Date(1989, 12, 31, 0, 0, 0, 0, time.UTC)
+
+// fitSports FIT sport 枚举
+var fitSports = map[uint64]string{1: "run", 2: "cycle", 5: "swim", 15: "row"}
+
+var errFITTruncated = errors.New("fit file is truncated")
+
+type fitField struct {
+	num  byte
+	size int
+}
+
+type fitDefinition struct {
+	global    uint16
+	order     binary.ByteOrder
+	fields    []fitField
+	devFields int // 开发者字段的总字节数，直接跳过
+}
+
+// parseFIT 解析 FIT 二进制文件中的 record（轨迹点）和 session（运动类型、消耗）消息
+//
+// 只解码用到的字段，其他消息按定义的长度跳过；支持压缩时间戳记录头和开发者字段。
+func parseFIT(data []byte) (Track, error) {
+	if len(data) < 12 || string(data[8:12]) != ".FIT" {
+		return Track{}, errors.New("missing .FIT header")
+	}
+	headerSize := int(data[0])
+	end := headerSize + int(binary.LittleEndian.Uint32(data[4:8]))
+	if headerSize < 12 || end > len(data) {
+		return Track{}, errFITTruncated
+	}
+
+	var t Track
+	definitions := map[byte]*fitDefinition{}
+	var lastTimestamp uint32
+	pos := headerSize
+	for pos < end {
+		header := data[pos]
+		pos++
+
+		var local byte
+		var compressedOffset = -1
+		switch {
+		case header&0x80 != 0: // 压缩时间戳的数据记录
+			local = (header >> 5) & 0x03
+			compressedOffset = int(header & 0x1F)
+		case header&0x40 != 0: // 定义记录
+			def, n, err := readFITDefinition(data[pos:end], header&0x20 != 0)
+			if err != nil {
+				return Track{}, err
+			}
+			definitions[header&0x0F] = def
+			pos += n
+			continue
+		default:
+			local = header & 0x0F
+		}
+
+		def, ok := definitions[local]
+		if !ok {
+			return Track{}, errors.New("fit data record without definition")
+		}
+		values := map[byte]uint64{}
+		for _, field := range def.fields {
+			if pos+field.size > end {
+				return Track{}, errFITTruncated
+			}
+			if value, ok := readFITValue(data[pos:pos+field.size], def.order); ok {
+				values[field.num] = value
+			}
+			pos += field.size
+		}
+		pos += def.devFields
+		if pos > end {
+			return Track{}, errFITTruncated
+		}
+
+		if timestamp, ok := values[fitFieldTimestamp]; ok {
+			lastTimestamp = uint32(timestamp)
+		} else if compressedOffset >= 0 {
+			delta := (uint32(compressedOffset) - lastTimestamp&0x1F) & 0x1F
+			lastTimestamp += delta
+			values[fitFieldTimestamp] = uint64(lastTimestamp)
+		}
+
+		switch def.global {
+		case fitMesgRecord:
+			t.Points = append(t.Points, fitRecordPoint(values))
+		case fitMesgSession:
+			if sport, ok := values[fitSessionSport]; ok && t.Sport == "" {
+				t.Sport = fitSports[sport]
+			}
+			if calories, ok := values[fitSessionTotalCalories]; ok {
+				t.Calories += int(calories)
+			}
+		}
+	}
+	return t, nil
+}
+
+// readFITDefinition 读取定义记录，返回定义和占用的字节数
+func readFITDefinition(data []byte, hasDevFields bool) (*fitDefinition, int, error) {
+	if len(data) < 5 {
+		return nil, 0, errFITTruncated
+	}
+	def := &fitDefinition{order: binary.LittleEndian}
+	if data[1] == 1 {
+		def.order = binary.BigEndian
+	}
+	def.global = def.order.Uint16(data[2:4])
+	count := int(data[4])
+	pos := 5
+	if len(data) < pos+count*3 {
+		return nil, 0, errFITTruncated
+	}
+	for i := 0; i < count; i++ {
+		def.fields = append(def.fields, fitField{num: data[pos], size: int(data[pos+1])})
+		pos += 3
+	}
+	if hasDevFields {
+		if len(data) < pos+1 {
+			return nil, 0, errFITTruncated
+		}
+		devCount := int(data[pos])
+		pos++
+		if len(data) < pos+devCount*3 {
+			return nil, 0, errFITTruncated
+		}
+		for i := 0; i < devCount; i++ {
+			def.devFields += int(data[pos+1])
+			pos += 3
+		}
+	}
+	return def, pos, nil
+}
+
+// readFITValue 读取1/2/4字节的整数字段，全1为 FIT 的无效值
+func readFITValue(data []byte, order binary.ByteOrder) (uint64, bool) {
+	switch len(data) {
+	case 1:
+		return uint64(data[0]), data[0] != 0xFF
+	case 2:
+		value := order.Uint16(data)
+		return uint64(value), value != 0xFFFF
+	case 4:
+		value := order.Uint32(data)
+		return uint64(value), value != 0xFFFFFFFF && value != 0x7FFFFFFF
+	}
+	return 0, false
+}
+
+// fitRecordPoint 将 record 消息转换为轨迹点
+func fitRecordPoint(values map[byte]uint64) Point {
+	var point Point
+	if timestamp, ok := values[fitFieldTimestamp]; ok {
+		point.Time = fitEpoch.Add(time.Duration(timestamp) * time.Second)
+	}
+	lat, hasLat := values[fitRecordLat]
+	long, hasLong := values[fitRecordLong]
+	if hasLat && hasLong {
+		point.Lat = semicirclesToDegrees(lat)
+		point.Lon = semicirclesToDegrees(long)
+		point.HasPosition = true
+	}
+	if altitude, ok := values[fitRecordEnhancedAltitude]; ok {
+		point.Elevation = float64(altitude)/5 - 500
+	} else if altitude, ok := values[fitRecordAltitude]; ok {
+		point.Elevation = float64(altitude)/5 - 500
+	}
+	if heartRate, ok := values[fitRecordHeartRate]; ok {
+		point.HeartRate = int(heartRate)
+	}
+	if distance, ok := values[fitRecordDistance]; ok {
+		point.Distance = float64(distance) / 100
+		point.HasDistance = true
+	}
+	return point
+}
+
+// semicirclesToDegrees FIT 经纬度单位为 sint32 半圆
+func semicirclesToDegrees(value uint64) float64 {
+	return float64(int32(uint32(value))) * 180 / (1 << 31)
+}
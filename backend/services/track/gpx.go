@@ -0,0 +1,68 @@
+package track
+
+import (
+	"encoding/xml"
+	"time"
+)
+
+// gpxDocument GPX 1.1，心率取自 Garmin TrackPointExtension
+type gpxDocument struct {
+	Metadata struct {
+		Name string `xml:"name"`
+	} `xml:"metadata"`
+	Tracks []struct {
+		Name     string `xml:"name"`
+		Type     string `xml:"type"`
+		Segments []struct {
+			Points []struct {
+				Lat       float64  `xml:"lat,attr"`
+				Lon       float64  `xml:"lon,attr"`
+				Elevation *float64 `xml:"ele"`
+				Time      string   `xml:"time"`
+				HeartRate int      `xml:"extensions>TrackPointExtension>hr"`
+			} `xml:"trkpt"`
+		} `xml:"trkseg"`
+	} `xml:"trk"`
+}
+
+func parseGPX(data []byte) (Track, error) {
+	var doc gpxDocument
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		return Track{}, err
+	}
+
+	t := Track{Name: doc.Metadata.Name}
+	for _, trk := range doc.Tracks {
+		if t.Name == "" {
+			t.Name = trk.Name
+		}
+		if t.Sport == "" {
+			t.Sport = mapSport(trk.Type)
+		}
+		for _, seg := range trk.Segments {
+			for _, pt := range seg.Points {
+				point := Point{
+					Time:        parseXMLTime(pt.Time),
+					Lat:         pt.Lat,
+					Lon:         pt.Lon,
+					HasPosition: true,
+					HeartRate:   pt.HeartRate,
+				}
+				if pt.Elevation != nil {
+					point.Elevation = *pt.Elevation
+				}
+				t.Points = append(t.Points, point)
+			}
+		}
+	}
+	return t, nil
+}
+
+// parseXMLTime 解析 ISO 8601 时间，无效时返回零值
+func parseXMLTime(value string) time.Time {
+	parsed, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return time.Time{}
+	}
+	return parsed
+}
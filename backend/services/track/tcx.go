@@ -0,0 +1,57 @@
+package track
+
+import "encoding/xml"
+
+// tcxDocument Garmin Training Center XML
+type tcxDocument struct {
+	Activities []struct {
+		Sport string `xml:"Sport,attr"`
+		Laps  []struct {
+			Calories int `xml:"Calories"`
+			Points   []struct {
+				Time      string   `xml:"Time"`
+				Lat       *float64 `xml:"Position>LatitudeDegrees"`
+				Lon       *float64 `xml:"Position>LongitudeDegrees"`
+				Altitude  float64  `xml:"AltitudeMeters"`
+				Distance  *float64 `xml:"DistanceMeters"`
+				HeartRate int      `xml:"HeartRateBpm>Value"`
+			} `xml:"Track>Trackpoint"`
+		} `xml:"Lap"`
+		Notes string `xml:"Notes"`
+	} `xml:"Activities>Activity"`
+}
+
+func parseTCX(data []byte) (Track, error) {
+	var doc tcxDocument
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		return Track{}, err
+	}
+
+	var t Track
+	for _, activity := range doc.Activities {
+		if t.Sport == "" {
+			t.Sport = mapSport(activity.Sport)
+		}
+		if t.Name == "" {
+			t.Name = activity.Notes
+		}
+		for _, lap := range activity.Laps {
+			t.Calories += lap.Calories
+			for _, pt := range lap.Points {
+				point := Point{
+					Time:      parseXMLTime(pt.Time),
+					Elevation: pt.Altitude,
+					HeartRate: pt.HeartRate,
+				}
+				if pt.Lat != nil && pt.Lon != nil {
+					point.Lat, point.Lon, point.HasPosition = *pt.Lat, *pt.Lon, true
+				}
+				if pt.Distance != nil {
+					point.Distance, point.HasDistance = *pt.Distance, true
+				}
+				t.Points = append(t.Points, point)
+			}
+		}
+	}
+	return t, nil
+}
@@ -0,0 +1,168 @@
+// Package track 解析 GPX/TCX/FIT 运动轨迹文件，并计算分段、最佳成绩和心率区间
+package track
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// 支持的文件格式
+const (
+	FormatGPX = "gpx"
+	FormatTCX = "tcx"
+	FormatFIT = "fit"
+)
+
+var (
+	// ErrUnsupportedFormat 无法识别的文件格式
+	ErrUnsupportedFormat = errors.New("unsupported track format, expected gpx, tcx or fit")
+	// ErrEmptyTrack 文件中没有带时间的轨迹点
+	ErrEmptyTrack = errors.New("track contains no timed points")
+	// ErrTooManyPoints 轨迹点超过 MaxPoints
+	ErrTooManyPoints = fmt.Errorf("track has more than %d points", MaxPoints)
+	// ErrTooManySplits 分段数超过 MaxSplits
+	ErrTooManySplits = fmt.Errorf("track has more than %d splits", MaxSplits)
+)
+
+// 轨迹限制：点数和分段数的上限，以及相邻两点之间可能的最高速度（米/秒），超过时视为 GPS 漂移
+const (
+	MaxPoints = 200000
+	MaxSplits = 1000
+	MaxSpeed  = 100.0
+)
+
+// Point 轨迹点
+type Point struct {
+	Time        time.Time
+	Lat         float64 // 度
+	Lon         float64
+	HasPosition bool
+	Elevation   float64 // 米
+	HeartRate   int     // 0 表示没有心率
+	Distance    float64 // 从起点开始的累计距离（米）
+	HasDistance bool    // 距离由设备记录；否则按经纬度计算
+}
+
+// Track 解析后的运动轨迹
+type Track struct {
+	Format   string
+	Sport    string // 已映射为 run/cycle/row/swim，无法识别时为空
+	Name     string
+	Calories int // 设备记录的消耗，0 表示没有
+	Points   []Point
+}
+
+// Parse 按格式解析轨迹文件，format 为空或无法识别时按内容判断
+func Parse(data []byte, format string) (Track, error) {
+	format = strings.ToLower(strings.TrimPrefix(format, "."))
+	if format != FormatGPX && format != FormatTCX && format != FormatFIT {
+		format = DetectFormat(data)
+	}
+
+	var t Track
+	var err error
+	switch format {
+	case FormatGPX:
+		t, err = parseGPX(data)
+	case FormatTCX:
+		t, err = parseTCX(data)
+	case FormatFIT:
+		t, err = parseFIT(data)
+	default:
+		return Track{}, ErrUnsupportedFormat
+	}
+	if err != nil {
+		return Track{}, fmt.Errorf("parse %s: %w", format, err)
+	}
+	t.Format = format
+	if len(t.Points) > MaxPoints {
+		return Track{}, ErrTooManyPoints
+	}
+	t.normalize()
+	if len(t.Points) == 0 {
+		return Track{}, ErrEmptyTrack
+	}
+	return t, nil
+}
+
+// DetectFormat 按文件内容判断格式，无法识别时返回空字符串
+func DetectFormat(data []byte) string {
+	if len(data) >= 12 && string(data[8:12]) == ".FIT" {
+		return FormatFIT
+	}
+	head := data
+	if len(head) > 1024 {
+		head = head[:1024]
+	}
+	switch {
+	case bytes.Contains(head, []byte("<gpx")):
+		return FormatGPX
+	case bytes.Contains(head, []byte("<TrainingCenterDatabase")):
+		return FormatTCX
+	}
+	return ""
+}
+
+// normalize 丢弃没有时间的点、按时间排序，并补全累计距离
+//
+// 文件没有记录距离时按相邻两点的大圆距离累加；设备记录的距离偶尔回退时保持不减。
+// 与上一个有效点之间的速度超过 MaxSpeed 的点视为漂移，不计距离，也不作为下一段的起点。
+func (t *Track) normalize() {
+	points := t.Points[:0]
+	for _, p := range t.Points {
+		if !p.Time.IsZero() {
+			points = append(points, p)
+		}
+	}
+	sort.SliceStable(points, func(i, j int) bool { return points[i].Time.Before(points[j].Time) })
+
+	var total float64
+	var last *Point
+	for i := range points {
+		p := &points[i]
+		step := 0.0
+		switch {
+		case p.HasDistance:
+			step = p.Distance - total
+		case last != nil && p.HasPosition && last.HasPosition:
+			step = Haversine(last.Lat, last.Lon, p.Lat, p.Lon)
+		}
+		if last != nil && step > MaxSpeed*p.Time.Sub(last.Time).Seconds() {
+			p.Distance = total
+			continue
+		}
+		total += maxFloat(step, 0)
+		p.Distance = total
+		if p.HasPosition || p.HasDistance {
+			last = p
+		}
+	}
+	t.Points = points
+}
+
+// mapSport 将文件中的运动类型映射为 run/cycle/row/swim
+func mapSport(sport string) string {
+	sport = strings.ToLower(sport)
+	switch {
+	case strings.Contains(sport, "run"), strings.Contains(sport, "walk"), strings.Contains(sport, "hik"):
+		return "run"
+	case strings.Contains(sport, "bik"), strings.Contains(sport, "cycl"), strings.Contains(sport, "ride"):
+		return "cycle"
+	case strings.Contains(sport, "row"):
+		return "row"
+	case strings.Contains(sport, "swim"):
+		return "swim"
+	}
+	return ""
+}
+
+func maxFloat(a, b float64) float64 {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,186 @@
+package track_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"gymates-backend/services/track"
+	"gymates-backend/services/track/tracktest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestTrackParsing 测试 GPX/TCX/FIT 解析和分段、最佳成绩、心率区间的计算
+func TestTrackParsing(t *testing.T) {
+	start := time.Date(2024, 5, 1, 6, 0, 0, 0, time.UTC)
+
+	t.Run("TCX使用设备记录的距离", func(t *testing.T) {
+		parsed, err := track.Parse([]byte(tracktest.IntervalRunTCX(start, 800)), "")
+		require.NoError(t, err)
+		assert.Equal(t, track.FormatTCX, parsed.Format)
+		assert.Equal(t, "run", parsed.Sport)
+		assert.Equal(t, "晨跑间歇", parsed.Name)
+		assert.Equal(t, 800, parsed.Calories)
+		assert.Equal(t, 13000.0, parsed.Distance())
+		assert.Equal(t, 4000*time.Second, parsed.Duration())
+		assert.True(t, parsed.StartTime().Equal(start))
+
+		avg, peak := parsed.HeartRate()
+		assert.Equal(t, 150, avg)
+		assert.Equal(t, 170, peak)
+	})
+
+	t.Run("GPX按经纬度累计距离", func(t *testing.T) {
+		parsed, err := track.Parse([]byte(tracktest.NorthboundGPX(start, "cycling", 11)), "gpx")
+		require.NoError(t, err)
+		assert.Equal(t, "cycle", parsed.Sport)
+		assert.Equal(t, "河边骑行", parsed.Name)
+		assert.InDelta(t, track.Haversine(39.9, 116.3, 39.909, 116.3), parsed.Distance(), 0.01)
+		assert.InDelta(t, 1000.8, parsed.Distance(), 0.5)
+		assert.Equal(t, 300*time.Second, parsed.Duration())
+		assert.Equal(t, 15.0, parsed.ElevationGain())
+
+		_, peak := parsed.HeartRate()
+		assert.Equal(t, 150, peak)
+	})
+
+	t.Run("FIT解析压缩时间戳和session消息", func(t *testing.T) {
+		parsed, err := track.Parse(tracktest.CyclingFIT(start, 61), "")
+		require.NoError(t, err)
+		assert.Equal(t, track.FormatFIT, parsed.Format)
+		assert.Equal(t, "cycle", parsed.Sport)
+		assert.Equal(t, 321, parsed.Calories)
+		require.Len(t, parsed.Points, 61)
+		assert.Equal(t, 6000.0, parsed.Distance())
+		assert.Equal(t, 600*time.Second, parsed.Duration())
+		assert.True(t, parsed.StartTime().Equal(start))
+		assert.InDelta(t, 31.2, parsed.Points[10].Lat, 1e-6)
+		assert.InDelta(t, 121.5, parsed.Points[10].Lon, 1e-6)
+		assert.Equal(t, 180, parsed.Points[60].HeartRate)
+	})
+
+	t.Run("无法识别的文件", func(t *testing.T) {
+		_, err := track.Parse([]byte(`{"plan":{}}`), "json")
+		assert.ErrorIs(t, err, track.ErrUnsupportedFormat)
+
+		_, err = track.Parse([]byte(`<gpx version="1.1"><trk><trkseg></trkseg></trk></gpx>`), "")
+		assert.ErrorIs(t, err, track.ErrEmptyTrack)
+
+		_, err = track.Parse([]byte("\x0e\x10\x08\x08\xff\x00\x00\x00.FIT"), "fit")
+		assert.Error(t, err)
+	})
+
+	parsed, err := track.Parse([]byte(tracktest.IntervalRunTCX(start, 0)), "tcx")
+	require.NoError(t, err)
+
+	t.Run("按公里分段", func(t *testing.T) {
+		splits, err := parsed.Splits(1000)
+		require.NoError(t, err)
+		require.Len(t, splits, 13)
+		assert.Equal(t, 400.0, splits[0].DurationSeconds)
+		assert.Equal(t, 400.0, splits[0].Pace)
+		assert.Equal(t, 130, splits[0].AvgHeartRate)
+		assert.Equal(t, 250.0, splits[5].DurationSeconds)
+		assert.Equal(t, 1000.0, splits[12].Distance)
+
+		short := track.Track{Points: parsed.Points[:41]} // 前400秒共1000米
+		splits, _ = short.Splits(1000)
+		assert.Len(t, splits, 1)
+		partial := track.Track{Points: parsed.Points[:49]} // 1200米，最后200米单独成段
+		splits, _ = partial.Splits(1000)
+		require.Len(t, splits, 2)
+		assert.Equal(t, 200.0, splits[1].Distance)
+	})
+
+	tests := []struct {
+		name   string
+		meters float64
+		want   float64
+		ok     bool
+	}{
+		{name: "最佳5公里全部在快段", meters: 5000, want: 1250, ok: true},
+		{name: "最佳10公里包含2公里慢段", meters: 10000, want: 2800, ok: true},
+		{name: "最佳1公里", meters: 1000, want: 250, ok: true},
+		{name: "距离不足", meters: 21097.5, ok: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			seconds, ok := parsed.BestEffort(tt.meters)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.want, seconds)
+		})
+	}
+
+	t.Run("心率区间", func(t *testing.T) {
+		zones := parsed.HeartRateZones(190)
+		require.Len(t, zones, 5)
+		assert.Equal(t, 95, zones[0].MinBPM)
+		assert.Equal(t, 113, zones[0].MaxBPM)
+		assert.Equal(t, 190, zones[4].MaxBPM)
+		assert.Equal(t, 2000.0, zones[1].Seconds)
+		assert.Equal(t, 2000.0, zones[3].Seconds)
+		assert.Equal(t, 0.0, zones[4].Seconds)
+
+		assert.Nil(t, track.Track{Points: []track.Point{{Time: start}, {Time: start.Add(time.Second)}}}.HeartRateZones(190))
+	})
+}
+
+// TestTrackLimits 测试 GPS 漂移点的过滤以及点数、分段数的上限
+func TestTrackLimits(t *testing.T) {
+	start := time.Date(2024, 5, 1, 6, 0, 0, 0, time.UTC)
+	gpx := func(lons ...float64) []byte {
+		var b strings.Builder
+		b.WriteString(`<gpx version="1.1"><trk><trkseg>`)
+		for i, lon := range lons {
+			fmt.Fprintf(&b, `<trkpt lat="0" lon="%.4f"><time>%s</time></trkpt>`, lon, start.Add(time.Duration(i)*10*time.Second).Format(time.RFC3339))
+		}
+		b.WriteString(`</trkseg></trk></gpx>`)
+		return []byte(b.String())
+	}
+
+	t.Run("速度不可能的点视为漂移", func(t *testing.T) {
+		// 0.001度约111米，每10秒一个点；中间跳到179.9度的点被跳过
+		parsed, err := track.Parse(gpx(0, 0.001, 179.9, 0.002, 0.003), "gpx")
+		require.NoError(t, err)
+		assert.InDelta(t, track.Haversine(0, 0, 0, 0.003), parsed.Distance(), 0.01)
+		assert.Equal(t, parsed.Points[1].Distance, parsed.Points[2].Distance)
+	})
+
+	t.Run("来回跳变的轨迹不会产生大量分段", func(t *testing.T) {
+		lons := make([]float64, 2000)
+		for i := range lons {
+			if i%2 == 1 {
+				lons[i] = 179.9
+			}
+		}
+		parsed, err := track.Parse(gpx(lons...), "gpx")
+		require.NoError(t, err)
+		assert.Equal(t, 0.0, parsed.Distance())
+		splits, err := parsed.Splits(1000)
+		require.NoError(t, err)
+		assert.Empty(t, splits)
+	})
+
+	t.Run("分段数超过上限", func(t *testing.T) {
+		points := []track.Point{
+			{Time: start},
+			{Time: start.Add(time.Hour), Distance: (track.MaxSplits + 1) * 1000},
+		}
+		_, err := track.Track{Points: points}.Splits(1000)
+		assert.ErrorIs(t, err, track.ErrTooManySplits)
+	})
+
+	t.Run("点数超过上限", func(t *testing.T) {
+		var b strings.Builder
+		b.WriteString(`<gpx version="1.1"><trk><trkseg>`)
+		for i := 0; i <= track.MaxPoints; i++ {
+			fmt.Fprintf(&b, `<trkpt lat="0" lon="0"><time>%s</time></trkpt>`, start.Add(time.Duration(i)*time.Second).Format(time.RFC3339))
+		}
+		b.WriteString(`</trkseg></trk></gpx>`)
+		_, err := track.Parse([]byte(b.String()), "gpx")
+		assert.ErrorIs(t, err, track.ErrTooManyPoints)
+	})
+}
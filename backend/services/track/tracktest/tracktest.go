@@ -0,0 +1,98 @@
+// Package tracktest 生成测试用的 GPX/TCX/FIT 轨迹文件
+package tracktest
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// IntervalRunTCX 前5公里每10秒25米（2.5m/s，心率130），后8公里每10秒40米（4m/s，心率170）
+func IntervalRunTCX(start time.Time, calories int) string {
+	var points strings.Builder
+	distance := 0.0
+	for i := 0; i <= 400; i++ {
+		heartRate := 130
+		if i >= 200 {
+			heartRate = 170
+		}
+		fmt.Fprintf(&points, `<Trackpoint><Time>%s</Time><DistanceMeters>%.1f</DistanceMeters><HeartRateBpm><Value>%d</Value></HeartRateBpm></Trackpoint>`,
+			start.Add(time.Duration(i)*10*time.Second).Format(time.RFC3339), distance, heartRate)
+		if i < 200 {
+			distance += 25
+		} else {
+			distance += 40
+		}
+	}
+	return fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<TrainingCenterDatabase xmlns="http://www.garmin.com/xmlschemas/TrainingCenterDatabase/v2">
+<Activities><Activity Sport="Running"><Id>%s</Id><Lap><Calories>%d</Calories><Track>%s</Track></Lap><Notes>晨跑间歇</Notes></Activity></Activities>
+</TrainingCenterDatabase>`, start.Format(time.RFC3339), calories, points.String())
+}
+
+// NorthboundGPX 沿经线向北，每30秒移动0.0009度（约100米），海拔先升后降
+func NorthboundGPX(start time.Time, activityType string, points int) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, `<?xml version="1.0"?><gpx version="1.1" creator="test" xmlns="http://www.topografix.com/GPX/1/1" xmlns:gpxtpx="http://www.garmin.com/xmlschemas/TrackPointExtension/v1">`)
+	fmt.Fprintf(&b, `<trk><name>河边骑行</name><type>%s</type><trkseg>`, activityType)
+	elevations := []float64{10, 12, 15, 14, 18, 18, 16, 20, 19, 19, 21}
+	for i := 0; i < points; i++ {
+		fmt.Fprintf(&b, `<trkpt lat="%.4f" lon="116.3000"><ele>%.1f</ele><time>%s</time><extensions><gpxtpx:TrackPointExtension><gpxtpx:hr>%d</gpxtpx:hr></gpxtpx:TrackPointExtension></extensions></trkpt>`,
+			39.9+float64(i)*0.0009, elevations[i%len(elevations)], start.Add(time.Duration(i)*30*time.Second).Format(time.RFC3339), 140+i)
+	}
+	b.WriteString(`</trkseg></trk></gpx>`)
+	return b.String()
+}
+
+// fitEncoder 按 FIT 协议生成测试文件（不计算 CRC，解析时不校验）
+type fitEncoder struct {
+	records bytes.Buffer
+}
+
+func (e *fitEncoder) define(local byte, global uint16, fields [][2]byte) {
+	e.records.WriteByte(0x40 | local)
+	e.records.Write([]byte{0, 0}) // 保留字节、小端
+	binary.Write(&e.records, binary.LittleEndian, global)
+	e.records.WriteByte(byte(len(fields)))
+	for _, field := range fields {
+		e.records.Write([]byte{field[0], field[1], 0})
+	}
+}
+
+func (e *fitEncoder) data(header byte, values ...interface{}) {
+	e.records.WriteByte(header)
+	for _, value := range values {
+		binary.Write(&e.records, binary.LittleEndian, value)
+	}
+}
+
+func (e *fitEncoder) bytes() []byte {
+	var file bytes.Buffer
+	file.Write([]byte{14, 0x10, 0x08, 0x08})
+	binary.Write(&file, binary.LittleEndian, uint32(e.records.Len()))
+	file.WriteString(".FIT")
+	file.Write([]byte{0, 0})
+	file.Write(e.records.Bytes())
+	file.Write([]byte{0, 0})
+	return file.Bytes()
+}
+
+// CyclingFIT 每10秒一个 record，距离每点增加100米；首个点带完整时间戳，之后用压缩时间戳
+func CyclingFIT(start time.Time, points int) []byte {
+	var e fitEncoder
+	timestamp := uint32(start.Sub(time.Date(1989, 12, 31, 0, 0, 0, 0, time.UTC)).Seconds())
+	semicircles := func(degrees float64) int32 { return int32(degrees * (1 << 31) / 180) }
+
+	e.define(0, 20, [][2]byte{{253, 4}, {0, 4}, {1, 4}, {3, 1}, {5, 4}})
+	e.define(1, 20, [][2]byte{{0, 4}, {1, 4}, {3, 1}, {5, 4}})
+	e.data(0x00, timestamp, semicircles(31.2), semicircles(121.5), uint8(120), uint32(0))
+	for i := 1; i < points; i++ {
+		ts := timestamp + uint32(i*10)
+		e.data(0x80|1<<5|byte(ts&0x1F), semicircles(31.2), semicircles(121.5), uint8(120+i), uint32(i*10000))
+	}
+	e.define(2, 18, [][2]byte{{5, 1}, {11, 2}})
+	e.data(0x02, uint8(2), uint16(321))
+	return e.bytes()
+}
@@ -63,4 +63,4 @@ echo ""
 echo "按 Ctrl+C 停止服务"
 echo "================================"
 
-go run main.go
+go run -tags sqlite_fts5 main.go
@@ -68,7 +68,9 @@ class ExerciseApiService {
         if (data is Map && data.containsKey('data')) {
           final responseData = data['data'];
           if (responseData is Map && responseData.containsKey('exercises')) {
-            return List<Map<String, dynamic>>.from(responseData['exercises']);
+            return (responseData['exercises'] as List)
+                .map((json) => _libraryExerciseToMap(json))
+                .toList();
           }
         }
       }
@@ -109,7 +111,7 @@ class ExerciseApiService {
 
       if (response.statusCode == 200) {
         final data = json.decode(response.body);
-        return _parseExerciseFromGoResponse(data);
+        return _parseExerciseFromGoResponse(data['data'] ?? data);
       } else {
         return MockDataProvider.exercises.firstWhere(
           (exercise) => exercise.id == id,
@@ -188,8 +190,17 @@ class ExerciseApiService {
     return MockDataProvider.exercises;
   }
 
+  /// 把动作库条目转换为动作字段（部位 part -> muscle_group，难度 level -> difficulty）
+  static Map<String, dynamic> _libraryExerciseToMap(dynamic json) {
+    final exercise = Map<String, dynamic>.from(json as Map);
+    exercise['muscle_group'] ??= exercise['part'];
+    exercise['difficulty'] ??= exercise['level'];
+    return exercise;
+  }
+
   /// 从Go后端响应解析单个动作
-  static MockExercise _parseExerciseFromGoResponse(dynamic json) {
+  static MockExercise _parseExerciseFromGoResponse(dynamic data) {
+    final json = _libraryExerciseToMap(data);
     return MockExercise(
       id: json['id']?.toString() ?? '',
       name: json['name']?.toString() ?? '',